@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/portworx/torpedo/drivers/backup/fakekeycloak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// claimsOf decodes the claims of a token
+func claimsOf(t *testing.T, token string) map[string]interface{} {
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	claims := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	return claims
+}
+
+func TestAuthHelpers(t *testing.T) {
+	server, err := fakekeycloak.New(fakekeycloak.PxCentralRealm("admin-password"))
+	require.NoError(t, err)
+	defer server.Close()
+	t.Setenv(PxCentralUIURL, server.URL)
+	defer func(pwd string) { PxCentralAdminPwd = pwd }(PxCentralAdminPwd)
+	PxCentralAdminPwd = "admin-password"
+
+	token, err := GetPxCentralAdminToken()
+	require.NoError(t, err)
+	assert.Equal(t, PxCentralAdminUser, claimsOf(t, token)["preferred_username"])
+
+	require.NoError(t, AddUser("testuser1", "test", "user1", "testuser1@cnbu.com", "password1"))
+	require.NoError(t, AddGroup("testgroup1"))
+	require.NoError(t, AddGroupToUser("testuser1", "testgroup1"))
+	members, err := GetMembersOfGroup("testgroup1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"testuser1"}, members)
+
+	require.NoError(t, AddRoleToUser("testuser1", ApplicationUser, "px-backup user"))
+	require.NoError(t, AddRoleToGroup("testgroup1", InfrastructureOwner, "px-backup infra admin"))
+	roles, err := GetRolesForUser("testuser1")
+	require.NoError(t, err)
+	var roleNames []string
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+	}
+	assert.Subset(t, roleNames, []string{ApplicationUser, InfrastructureOwner, DefaultRoles})
+
+	userID, err := FetchIDOfUser("testuser1")
+	require.NoError(t, err)
+	name, email, err := FetchUserDetailsFromID(userID)
+	require.NoError(t, err)
+	assert.Equal(t, "testuser1", name)
+	assert.Equal(t, "testuser1@cnbu.com", email)
+
+	token, err = GetToken("testuser1", "password1")
+	require.NoError(t, err)
+	claims := claimsOf(t, token)
+	assert.Equal(t, userID, claims["sub"], "backup shares refer to users by the sub of their tokens")
+	assert.Equal(t, []interface{}{"testgroup1"}, claims["groups"])
+	token, err = GetToken("testuser1", "wrong-password")
+	require.NoError(t, err, "the helpers ignore HTTP statuses")
+	assert.Empty(t, token)
+
+	require.NoError(t, DeleteRoleFromUser("testuser1", ApplicationUser, "px-backup user"))
+	require.NoError(t, DeleteGroup("testgroup1"))
+	roles, err = GetRolesForUser("testuser1")
+	require.NoError(t, err)
+	for _, role := range roles {
+		assert.NotContains(t, []string{ApplicationUser, InfrastructureOwner}, role.Name)
+	}
+	require.NoError(t, DeleteUser("testuser1"))
+	users, err := GetAllUsers()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, PxCentralAdminUser, users[0].Name)
+}
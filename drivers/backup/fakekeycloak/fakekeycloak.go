@@ -0,0 +1,415 @@
+// Package fakekeycloak is an in-memory Keycloak, so that the backup auth helpers and the multi user
+// backup tests can run without px-central. It serves the password grant of the OpenID Connect
+// token endpoint and the subset of the Keycloak admin REST API used by the helpers: realm roles,
+// users, groups, group members and role mappings of users and groups.
+//
+// Tokens are JWTs signed with HS256 by a key of the server. Their sub claim is the id of the user,
+// preferred_username its name, groups the names of its groups and roles its effective realm
+// roles, so that they are understood by the fake PX-Backup server too. Admin requests need the
+// token of a user with the admin role of the realm.
+package fakekeycloak
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pborman/uuid"
+)
+
+const (
+	// AdminRole is the role of the users allowed to use the admin API of a realm
+	AdminRole = "admin"
+	// DefaultRealm is the realm of px-central
+	DefaultRealm = "master"
+	// DefaultAdminUser is the px-central administrator
+	DefaultAdminUser = "px-central-admin"
+	// ClientID is the client tokens are issued to
+	ClientID = "pxcentral"
+	// tokenLifetime is how long tokens are valid
+	tokenLifetime = time.Hour
+)
+
+// DefaultRoles are the default realm roles of Keycloak. The first one, a composite of the others
+// in Keycloak, is mapped to all new users.
+var DefaultRoles = []string{"default-roles-master", "offline_access", "uma_authorization"}
+
+// PxBackupRoles are the PX-Backup roles of px-central
+var PxBackupRoles = []string{"px-backup-app.admin", "px-backup-app.user", "px-backup-infra.admin"}
+
+// User is a user seeded in a realm
+type User struct {
+	Name      string
+	Password  string
+	FirstName string
+	LastName  string
+	Email     string
+	// Roles are the realm roles mapped to the user, in addition to the default roles
+	Roles []string
+	// Groups are the names of the groups of the user
+	Groups []string
+}
+
+// Group is a group seeded in a realm
+type Group struct {
+	Name string
+	// Roles are the realm roles mapped to the group
+	Roles []string
+}
+
+// Realm is a realm seeded in the server
+type Realm struct {
+	Name   string
+	Roles  []string
+	Groups []Group
+	Users  []User
+}
+
+// PxCentralRealm returns the master realm of px-central, with its roles and its administrator
+func PxCentralRealm(adminPassword string) Realm {
+	return Realm{
+		Name:  DefaultRealm,
+		Roles: append(append([]string{AdminRole}, DefaultRoles...), PxBackupRoles...),
+		Users: []User{{
+			Name:     DefaultAdminUser,
+			Password: adminPassword,
+			Email:    DefaultAdminUser + "@portworx.com",
+			Roles:    []string{AdminRole, "px-backup-infra.admin"},
+		}},
+	}
+}
+
+type role struct {
+	id   string
+	name string
+}
+
+type user struct {
+	id       string
+	created  time.Time
+	name     string
+	password string
+	first    string
+	last     string
+	email    string
+	roles    map[string]bool
+	groups   map[string]bool
+}
+
+type group struct {
+	id    string
+	name  string
+	roles map[string]bool
+}
+
+type realm struct {
+	name string
+	// roles by name, users and groups by id
+	roles  map[string]*role
+	users  map[string]*user
+	groups map[string]*group
+}
+
+// Server is an in-memory Keycloak server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+	key []byte
+
+	sync.Mutex
+	realms map[string]*realm
+}
+
+// New starts a server with the realms, which must be closed with Close. Its URL is the px-central
+// UI URL of the backup auth helpers. Roles of users and groups missing from their realm are added
+// to it, as well as the default roles.
+func New(realms ...Realm) (*Server, error) {
+	s := &Server{realms: make(map[string]*realm), key: make([]byte, 32)}
+	if _, err := rand.Read(s.key); err != nil {
+		return nil, err
+	}
+	for _, seed := range realms {
+		if err := s.seed(seed); err != nil {
+			return nil, err
+		}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s, nil
+}
+
+func (s *Server) seed(seed Realm) error {
+	if _, ok := s.realms[seed.Name]; ok {
+		return fmt.Errorf("realm %s is seeded twice", seed.Name)
+	}
+	r := &realm{name: seed.Name, roles: make(map[string]*role), users: make(map[string]*user), groups: make(map[string]*group)}
+	s.realms[seed.Name] = r
+	for _, name := range append(append([]string{}, DefaultRoles...), seed.Roles...) {
+		r.addRole(name)
+	}
+	for _, g := range seed.Groups {
+		created, err := r.addGroup(g.Name)
+		if err != nil {
+			return err
+		}
+		for _, name := range g.Roles {
+			created.roles[r.addRole(name).id] = true
+		}
+	}
+	for _, u := range seed.Users {
+		created, err := r.addUser(u.Name, u.Password, u.FirstName, u.LastName, u.Email)
+		if err != nil {
+			return err
+		}
+		for _, name := range u.Roles {
+			created.roles[r.addRole(name).id] = true
+		}
+		for _, name := range u.Groups {
+			g := r.groupByName(name)
+			if g == nil {
+				if g, err = r.addGroup(name); err != nil {
+					return err
+				}
+			}
+			created.groups[g.id] = true
+		}
+	}
+	return nil
+}
+
+// Issuer returns the OIDC issuer URL of a realm
+func (s *Server) Issuer(realmName string) string {
+	return fmt.Sprintf("%s/auth/realms/%s", s.URL, realmName)
+}
+
+// Token returns a token of a user of a realm, without checking its password
+func (s *Server) Token(realmName, userName string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	r, ok := s.realms[realmName]
+	if !ok {
+		return "", fmt.Errorf("realm %s not found", realmName)
+	}
+	u := r.userByName(userName)
+	if u == nil {
+		return "", fmt.Errorf("user %s not found in realm %s", userName, realmName)
+	}
+	return s.token(r, u, time.Now())
+}
+
+func (r *realm) addRole(name string) *role {
+	if existing, ok := r.roles[name]; ok {
+		return existing
+	}
+	created := &role{id: uuid.New(), name: name}
+	r.roles[name] = created
+	return created
+}
+
+func (r *realm) addUser(name, password, first, last, email string) (*user, error) {
+	if name == "" {
+		return nil, fmt.Errorf("user name is required")
+	}
+	if r.userByName(name) != nil {
+		return nil, fmt.Errorf("User exists with same username")
+	}
+	u := &user{
+		id:       uuid.New(),
+		created:  time.Now(),
+		name:     strings.ToLower(name),
+		password: password,
+		first:    first,
+		last:     last,
+		email:    email,
+		roles:    make(map[string]bool),
+		groups:   make(map[string]bool),
+	}
+	u.roles[r.roles[DefaultRoles[0]].id] = true
+	r.users[u.id] = u
+	return u, nil
+}
+
+func (r *realm) addGroup(name string) (*group, error) {
+	if name == "" {
+		return nil, fmt.Errorf("group name is required")
+	}
+	if r.groupByName(name) != nil {
+		return nil, fmt.Errorf("Top level group named '%s' already exists.", name)
+	}
+	g := &group{id: uuid.New(), name: name, roles: make(map[string]bool)}
+	r.groups[g.id] = g
+	return g, nil
+}
+
+// userByName returns the user with the name, which is case insensitive like in Keycloak
+func (r *realm) userByName(name string) *user {
+	for _, u := range r.users {
+		if u.name == strings.ToLower(name) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (r *realm) groupByName(name string) *group {
+	for _, g := range r.groups {
+		if g.name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// roleOf returns the role of a representation, by id or by name
+func (r *realm) roleOf(rep roleRepresentation) *role {
+	for _, role := range r.roles {
+		if (rep.ID != "" && role.id == rep.ID) || (rep.ID == "" && role.name == rep.Name) {
+			return role
+		}
+	}
+	return nil
+}
+
+// effectiveRoles returns the names of the roles of a user and of its groups, with the default
+// roles expanded
+func (r *realm) effectiveRoles(u *user) []string {
+	ids := make(map[string]bool)
+	for id := range u.roles {
+		ids[id] = true
+	}
+	for gid := range u.groups {
+		if g, ok := r.groups[gid]; ok {
+			for id := range g.roles {
+				ids[id] = true
+			}
+		}
+	}
+	if ids[r.roles[DefaultRoles[0]].id] {
+		for _, name := range DefaultRoles {
+			ids[r.roles[name].id] = true
+		}
+	}
+	return r.roleNames(ids)
+}
+
+func (r *realm) roleNames(ids map[string]bool) []string {
+	var names []string
+	for _, role := range r.roles {
+		if ids[role.id] {
+			names = append(names, role.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *realm) sortedRoleNames() []string {
+	names := make([]string, 0, len(r.roles))
+	for name := range r.roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedUsers returns the users by name, like the Keycloak admin API
+func (r *realm) sortedUsers() []*user {
+	users := make([]*user, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].name < users[j].name })
+	return users
+}
+
+func sortGroups(groups []groupRepresentation) {
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+}
+
+func (r *realm) groupNames(u *user) []string {
+	var names []string
+	for id := range u.groups {
+		if g, ok := r.groups[id]; ok {
+			names = append(names, g.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// token returns a signed token of the user
+func (s *Server) token(r *realm, u *user, now time.Time) (string, error) {
+	roles := r.effectiveRoles(u)
+	claims := map[string]interface{}{
+		"iss":                s.Issuer(r.name),
+		"aud":                ClientID,
+		"azp":                ClientID,
+		"sub":                u.id,
+		"typ":                "Bearer",
+		"iat":                now.Unix(),
+		"exp":                now.Add(tokenLifetime).Unix(),
+		"preferred_username": u.name,
+		"given_name":         u.first,
+		"family_name":        u.last,
+		"name":               strings.TrimSpace(u.first + " " + u.last),
+		"email":              u.email,
+		"groups":             r.groupNames(u),
+		"roles":              roles,
+		"realm_access":       map[string][]string{"roles": roles},
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(s.sign(signed)), nil
+}
+
+func (s *Server) sign(signed string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+// verify returns the user of a valid token of the realm
+func (s *Server) verify(r *realm, token string, now time.Time) (*user, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, s.sign(parts[0]+"."+parts[1])) {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	var claims struct {
+		Issuer  string `json:"iss"`
+		Subject string `json:"sub"`
+		Expiry  int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, err
+	}
+	if claims.Issuer != s.Issuer(r.name) {
+		return nil, fmt.Errorf("token is not issued by realm %s", r.name)
+	}
+	if now.Unix() >= claims.Expiry {
+		return nil, fmt.Errorf("token is expired")
+	}
+	u, ok := r.users[claims.Subject]
+	if !ok {
+		return nil, fmt.Errorf("user of token not found")
+	}
+	return u, nil
+}
@@ -0,0 +1,372 @@
+package fakekeycloak
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type roleRepresentation struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Composite   bool   `json:"composite"`
+	ClientRole  bool   `json:"clientRole"`
+	ContainerID string `json:"containerId"`
+}
+
+type credentialRepresentation struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Temporary bool   `json:"temporary"`
+}
+
+type userRepresentation struct {
+	ID               string                     `json:"id,omitempty"`
+	CreatedTimestamp int64                      `json:"createdTimestamp,omitempty"`
+	Username         string                     `json:"username"`
+	FirstName        string                     `json:"firstName"`
+	LastName         string                     `json:"lastName"`
+	Email            string                     `json:"email"`
+	EmailVerified    bool                       `json:"emailVerified"`
+	Enabled          bool                       `json:"enabled"`
+	Credentials      []credentialRepresentation `json:"credentials,omitempty"`
+}
+
+type groupRepresentation struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Path      string   `json:"path"`
+	SubGroups []string `json:"subGroups"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error like the Keycloak admin API
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, map[string]string{"errorMessage": fmt.Sprintf(format, args...)})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) >= 3 && parts[0] == "auth" && parts[1] == "realms":
+		realm, ok := s.realms[parts[2]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Realm does not exist"})
+			return
+		}
+		s.serveRealm(w, r, realm, parts[3:])
+	case len(parts) >= 4 && parts[0] == "auth" && parts[1] == "admin" && parts[2] == "realms":
+		realm, ok := s.realms[parts[3]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Realm not found.")
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		admin, err := s.verify(realm, token, time.Now())
+		if err != nil {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "HTTP 401 Unauthorized", "error_description": err.Error()})
+			return
+		}
+		if !contains(realm.effectiveRoles(admin), AdminRole) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "HTTP 403 Forbidden"})
+			return
+		}
+		s.serveAdmin(w, r, realm, parts[4:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveRealm serves the OIDC endpoints of a realm
+func (s *Server) serveRealm(w http.ResponseWriter, r *http.Request, realm *realm, path []string) {
+	switch strings.Join(path, "/") {
+	case ".well-known/openid-configuration":
+		issuer := s.Issuer(realm.name)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"issuer":                                issuer,
+			"token_endpoint":                        issuer + "/protocol/openid-connect/token",
+			"grant_types_supported":                 []string{"password"},
+			"id_token_signing_alg_values_supported": []string{"HS256"},
+		})
+	case "protocol/openid-connect/token":
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": err.Error()})
+			return
+		}
+		if grant := r.PostForm.Get("grant_type"); grant != "password" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type", "error_description": "Unsupported grant_type " + grant})
+			return
+		}
+		u := realm.userByName(r.PostForm.Get("username"))
+		if u == nil || u.password != r.PostForm.Get("password") {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_grant", "error_description": "Invalid user credentials"})
+			return
+		}
+		token, err := s.token(realm, u, time.Now())
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": token,
+			"expires_in":   int(tokenLifetime.Seconds()),
+			"token_type":   "Bearer",
+			"scope":        "openid email profile",
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveAdmin serves the admin API of a realm
+func (s *Server) serveAdmin(w http.ResponseWriter, r *http.Request, realm *realm, path []string) {
+	route := func(pattern string, methods ...string) bool {
+		p := strings.Split(pattern, "/")
+		if len(p) != len(path) {
+			return false
+		}
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				return false
+			}
+		}
+		return contains(methods, r.Method)
+	}
+	switch {
+	case route("roles", http.MethodGet):
+		writeJSON(w, http.StatusOK, realm.roleRepresentations(nil))
+	case route("users", http.MethodGet):
+		s.listUsers(w, r, realm)
+	case route("users", http.MethodPost):
+		s.createUser(w, r, realm)
+	case route("users/*", http.MethodGet, http.MethodDelete):
+		u, ok := realm.users[path[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+		} else if r.Method == http.MethodGet {
+			writeJSON(w, http.StatusOK, userOf(u))
+		} else {
+			delete(realm.users, u.id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case route("users/*/groups", http.MethodGet):
+		u, ok := realm.users[path[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		groups := []groupRepresentation{}
+		for _, name := range realm.groupNames(u) {
+			groups = append(groups, groupOf(realm.groupByName(name)))
+		}
+		writeJSON(w, http.StatusOK, groups)
+	case route("users/*/groups/*", http.MethodPut, http.MethodDelete):
+		u, uok := realm.users[path[1]]
+		g, gok := realm.groups[path[3]]
+		if !uok || !gok {
+			writeError(w, http.StatusNotFound, "User or group not found")
+			return
+		}
+		if r.Method == http.MethodPut {
+			u.groups[g.id] = true
+		} else {
+			delete(u.groups, g.id)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case route("users/*/role-mappings/realm", http.MethodGet, http.MethodPost, http.MethodDelete):
+		u, ok := realm.users[path[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		s.roleMappings(w, r, realm, u.roles)
+	case route("users/*/role-mappings/realm/composite", http.MethodGet):
+		u, ok := realm.users[path[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		ids := make(map[string]bool)
+		for _, name := range realm.effectiveRoles(u) {
+			ids[realm.roles[name].id] = true
+		}
+		writeJSON(w, http.StatusOK, realm.roleRepresentations(ids))
+	case route("groups", http.MethodGet):
+		groups := []groupRepresentation{}
+		for _, g := range realm.groups {
+			groups = append(groups, groupOf(g))
+		}
+		sortGroups(groups)
+		writeJSON(w, http.StatusOK, groups)
+	case route("groups", http.MethodPost):
+		var rep groupRepresentation
+		if err := json.NewDecoder(r.Body).Decode(&rep); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid group: %v", err)
+			return
+		}
+		g, err := realm.addGroup(rep.Name)
+		if err != nil {
+			writeError(w, http.StatusConflict, "%v", err)
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("%s%s/%s", s.URL, r.URL.Path, g.id))
+		w.WriteHeader(http.StatusCreated)
+	case route("groups/*", http.MethodGet, http.MethodDelete):
+		g, ok := realm.groups[path[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Could not find group by id")
+		} else if r.Method == http.MethodGet {
+			writeJSON(w, http.StatusOK, groupOf(g))
+		} else {
+			delete(realm.groups, g.id)
+			for _, u := range realm.users {
+				delete(u.groups, g.id)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case route("groups/*/members", http.MethodGet):
+		g, ok := realm.groups[path[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Could not find group by id")
+			return
+		}
+		members := []userRepresentation{}
+		for _, u := range realm.sortedUsers() {
+			if u.groups[g.id] {
+				members = append(members, userOf(u))
+			}
+		}
+		writeJSON(w, http.StatusOK, members)
+	case route("groups/*/role-mappings/realm", http.MethodGet, http.MethodPost, http.MethodDelete):
+		g, ok := realm.groups[path[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Could not find group by id")
+			return
+		}
+		s.roleMappings(w, r, realm, g.roles)
+	default:
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+	}
+}
+
+// listUsers lists the users of a realm, filtered by exact username or by search like Keycloak
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request, realm *realm) {
+	query := r.URL.Query()
+	users := []userRepresentation{}
+	for _, u := range realm.sortedUsers() {
+		if name := query.Get("username"); name != "" && u.name != strings.ToLower(name) {
+			continue
+		}
+		if search := strings.ToLower(query.Get("search")); search != "" &&
+			!strings.Contains(u.name, search) && !strings.Contains(strings.ToLower(u.email), search) {
+			continue
+		}
+		users = append(users, userOf(u))
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request, realm *realm) {
+	var rep userRepresentation
+	if err := json.NewDecoder(r.Body).Decode(&rep); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user: %v", err)
+		return
+	}
+	var password string
+	for _, c := range rep.Credentials {
+		if c.Type == "password" {
+			password = c.Value
+		}
+	}
+	u, err := realm.addUser(rep.Username, password, rep.FirstName, rep.LastName, rep.Email)
+	if err != nil {
+		writeError(w, http.StatusConflict, "%v", err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s%s/%s", s.URL, r.URL.Path, u.id))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// roleMappings lists, adds or removes the realm roles of a user or a group
+func (s *Server) roleMappings(w http.ResponseWriter, r *http.Request, realm *realm, mapped map[string]bool) {
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, realm.roleRepresentations(mapped))
+		return
+	}
+	var reps []roleRepresentation
+	if err := json.NewDecoder(r.Body).Decode(&reps); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid roles: %v", err)
+		return
+	}
+	for _, rep := range reps {
+		role := realm.roleOf(rep)
+		if role == nil {
+			writeError(w, http.StatusNotFound, "Could not find role")
+			return
+		}
+		if r.Method == http.MethodPost {
+			mapped[role.id] = true
+		} else {
+			delete(mapped, role.id)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// roleRepresentations returns the roles with the ids, all roles if ids is nil, by name
+func (r *realm) roleRepresentations(ids map[string]bool) []roleRepresentation {
+	roles := []roleRepresentation{}
+	for _, name := range r.sortedRoleNames() {
+		role := r.roles[name]
+		if ids == nil || ids[role.id] {
+			roles = append(roles, roleRepresentation{
+				ID:          role.id,
+				Name:        role.name,
+				Description: "${role_" + role.name + "}",
+				Composite:   role.name == DefaultRoles[0],
+				ContainerID: r.name,
+			})
+		}
+	}
+	return roles
+}
+
+func userOf(u *user) userRepresentation {
+	return userRepresentation{
+		ID:               u.id,
+		CreatedTimestamp: u.created.UnixNano() / int64(time.Millisecond),
+		Username:         u.name,
+		FirstName:        u.first,
+		LastName:         u.last,
+		Email:            u.email,
+		Enabled:          true,
+	}
+}
+
+func groupOf(g *group) groupRepresentation {
+	return groupRepresentation{ID: g.id, Name: g.name, Path: "/" + g.name, SubGroups: []string{}}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,544 @@
+package fakepxb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backupAccess returns the access of a user to a backup through its ownership, its share and the
+// share of its cluster
+func (s *Server) backupAccess(u *user, obj *api.BackupObject) access {
+	return max(ownershipAccess(u, obj), shareAccess(u, obj.GetBackupShare()), shareAccess(u, s.clusterShares[obj.GetClusterRef().GetUid()]))
+}
+
+// shareAccessType returns the backup share access type matching an access
+func shareAccessType(a access) api.BackupShare_AccessType {
+	switch {
+	case a >= writeAccess:
+		return api.BackupShare_FullAccess
+	case a >= restoreAccess:
+		return api.BackupShare_Restorable
+	case a >= readAccess:
+		return api.BackupShare_View
+	}
+	return api.BackupShare_Invalid
+}
+
+// deleteBackup marks a backup as being deleted. It is removed after the delete delay.
+func (s *Server) deleteBackup(e *entry[*api.BackupObject]) {
+	if !e.deleted.IsZero() {
+		return
+	}
+	e.deleted = s.config.Now()
+	e.obj.Status = &api.BackupInfo_StatusInfo{Status: api.BackupInfo_StatusInfo_Deleting}
+}
+
+// policyInterval returns the kind of a schedule policy, like interval or daily, and the interval
+// between its backups. Daily, weekly and monthly policies run every 24 hours, 7 and 30 days.
+func (s *Server) policyInterval(policy *api.SchedulePolicyInfo) (string, time.Duration, error) {
+	var kind string
+	var interval time.Duration
+	switch {
+	case policy.GetInterval() != nil:
+		kind, interval = "interval", time.Duration(policy.GetInterval().GetMinutes())*time.Minute
+	case policy.GetDaily() != nil:
+		kind, interval = "daily", 24*time.Hour
+	case policy.GetWeekly() != nil:
+		kind, interval = "weekly", 7*24*time.Hour
+	case policy.GetMonthly() != nil:
+		kind, interval = "monthly", 30*24*time.Hour
+	default:
+		return "", 0, invalid("schedule policy has no interval, daily, weekly nor monthly policy")
+	}
+	if s.config.ScheduleInterval > 0 {
+		interval = s.config.ScheduleInterval
+	}
+	if interval <= 0 {
+		return "", 0, invalid("interval of schedule policy must be positive")
+	}
+	return kind, interval, nil
+}
+
+// policyRetain returns the number of backups a schedule policy retains, 0 for all
+func policyRetain(policy *api.SchedulePolicyInfo) int {
+	switch {
+	case policy.GetInterval() != nil:
+		return int(policy.GetInterval().GetRetain())
+	case policy.GetDaily() != nil:
+		return int(policy.GetDaily().GetRetain())
+	case policy.GetWeekly() != nil:
+		return int(policy.GetWeekly().GetRetain())
+	case policy.GetMonthly() != nil:
+		return int(policy.GetMonthly().GetRetain())
+	}
+	return 0
+}
+
+// runSchedules creates the backups of the schedules which were due since the last request, with
+// the creation times they were due at. The first backup of a schedule is created with it.
+func (s *Server) runSchedules(now time.Time) {
+	for _, e := range s.schedules.all() {
+		schedule := e.obj
+		policy, ok := s.policies.byUID(schedule.GetOrgId(), schedule.GetSchedulePolicyRef().GetUid())
+		if !ok || schedule.GetSuspend() {
+			continue
+		}
+		kind, interval, err := s.policyInterval(policy.obj.SchedulePolicyInfo)
+		if err != nil {
+			continue
+		}
+		last, ran := s.scheduleRuns[schedule.GetUid()]
+		next := e.created
+		if ran {
+			next = last.Add(interval)
+		}
+		for !next.After(now) {
+			s.scheduled[schedule.GetUid()]++
+			s.createScheduledBackup(schedule, kind, s.scheduled[schedule.GetUid()], next)
+			s.scheduleRuns[schedule.GetUid()] = next
+			next = next.Add(interval)
+		}
+		info := policy.obj.SchedulePolicyInfo
+		if info.GetForObjectLock() {
+			if info.GetAutoDelete() {
+				s.deleteUnlocked(schedule, policyIncrementalCount(info), now)
+			}
+			continue
+		}
+		if retain := policyRetain(info); retain > 0 {
+			backups := s.scheduleBackups(schedule)
+			for i := retain; i < len(backups); i++ {
+				s.deleteBackup(backups[i])
+			}
+		}
+	}
+}
+
+// policyIncrementalCount returns the number of incremental backups a schedule policy takes
+// after each full backup
+func policyIncrementalCount(policy *api.SchedulePolicyInfo) int {
+	switch {
+	case policy.GetInterval() != nil:
+		return int(policy.GetInterval().GetIncrementalCount().GetCount())
+	case policy.GetDaily() != nil:
+		return int(policy.GetDaily().GetIncrementalCount().GetCount())
+	case policy.GetWeekly() != nil:
+		return int(policy.GetWeekly().GetIncrementalCount().GetCount())
+	case policy.GetMonthly() != nil:
+		return int(policy.GetMonthly().GetIncrementalCount().GetCount())
+	}
+	return 0
+}
+
+// deleteUnlocked deletes the backups of a schedule whose objects are unlocked. Full backups stay
+// locked as long as the incremental backups of their chain.
+func (s *Server) deleteUnlocked(schedule *api.BackupScheduleObject, incrementalCount int, now time.Time) {
+	backups := s.scheduleBackups(schedule)
+	// chains are the unlock times of the chains of the backups, by the sequence number of their full backup
+	chains := make(map[int]time.Time)
+	full := func(e *entry[*api.BackupObject]) int {
+		n := s.sequence[e.obj.GetUid()] - 1
+		return n - n%(incrementalCount+1)
+	}
+	for _, e := range backups {
+		if unlock := e.created.Add(s.config.ObjectLockPeriod); unlock.After(chains[full(e)]) {
+			chains[full(e)] = unlock
+		}
+	}
+	for _, e := range backups {
+		unlock := e.created.Add(s.config.ObjectLockPeriod)
+		if n := s.sequence[e.obj.GetUid()] - 1; n == full(e) {
+			unlock = chains[n]
+		}
+		if !now.Before(unlock) {
+			s.deleteBackup(e)
+		}
+	}
+}
+
+// createScheduledBackup creates the nth backup of a schedule
+func (s *Server) createScheduledBackup(schedule *api.BackupScheduleObject, kind string, n int, at time.Time) {
+	m := s.metadata(&user{id: schedule.GetOwnership().GetOwner(), name: schedule.GetOwnership().GetOwner()},
+		&api.CreateMetadata{Name: fmt.Sprintf("%s-%s-%d", schedule.GetName(), kind, n), OrgId: schedule.GetOrgId(), Labels: schedule.GetLabels()}, at)
+	m.Ownership = proto.Clone(schedule.GetOwnership()).(*api.Ownership)
+	info := &api.BackupInfo{
+		BackupLocation:    schedule.GetBackupLocation(),
+		BackupLocationRef: schedule.GetBackupLocationRef(),
+		Namespaces:        schedule.GetNamespaces(),
+		LabelSelectors:    schedule.GetLabelSelectors(),
+		PreExecRule:       schedule.GetPreExecRule(),
+		PostExecRule:      schedule.GetPostExecRule(),
+		PreExecRuleRef:    schedule.GetPreExecRuleRef(),
+		PostExecRuleRef:   schedule.GetPostExecRuleRef(),
+		ResourceTypes:     schedule.GetResourceTypes(),
+		BackupSchedule:    &api.BackupInfo_BackupSchedule{Name: schedule.GetName(), Uid: schedule.GetUid()},
+	}
+	if cluster, err := s.clusters.get(schedule.GetOrgId(), schedule.GetCluster(), ""); err == nil {
+		info.Cluster = cluster.obj.GetName()
+		info.ClusterRef = &api.ObjectRef{Name: cluster.obj.GetName(), Uid: cluster.obj.GetUid()}
+	}
+	if err := s.addBackup(&api.BackupObject{Metadata: m, BackupInfo: info}, at); err == nil {
+		s.sequence[m.GetUid()] = n
+	}
+}
+
+// addBackup fills in the fields PX-Backup sets when it creates a backup and adds it
+func (s *Server) addBackup(obj *api.BackupObject, at time.Time) error {
+	obj.BackupPath = fmt.Sprintf("%s/%s-%s", obj.GetOrgId(), obj.GetName(), obj.GetUid())
+	obj.CrName = fmt.Sprintf("%s-%s", obj.GetName(), obj.GetUid()[:7])
+	obj.CrUid = uuid.New()
+	obj.Status = &api.BackupInfo_StatusInfo{Status: s.config.BackupTimeline[0].Status}
+	obj.Stage = s.config.BackupTimeline[0].Stage
+	_, err := s.backups.add(obj, at)
+	return err
+}
+
+// scheduleBackups returns the backups of a schedule which are not being deleted, from the newest
+func (s *Server) scheduleBackups(schedule *api.BackupScheduleObject) []*entry[*api.BackupObject] {
+	var backups []*entry[*api.BackupObject]
+	all := s.backups.all()
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if e.deleted.IsZero() && e.obj.GetOrgId() == schedule.GetOrgId() && e.obj.GetBackupSchedule().GetUid() == schedule.GetUid() {
+			backups = append(backups, e)
+		}
+	}
+	return backups
+}
+
+// updateScheduleStatus sets the statuses of the backups of the schedules, from the newest
+func (s *Server) updateScheduleStatus() {
+	for _, e := range s.schedules.all() {
+		schedule := e.obj
+		kind := "interval"
+		if policy, ok := s.policies.byUID(schedule.GetOrgId(), schedule.GetSchedulePolicyRef().GetUid()); ok {
+			kind, _, _ = s.policyInterval(policy.obj.SchedulePolicyInfo)
+		}
+		list := &api.BackupScheduleInfo_StatusInfoList{}
+		for _, b := range s.scheduleBackups(schedule) {
+			list.Status = append(list.Status, &api.BackupScheduleInfo_StatusInfo{
+				BackupName: b.obj.GetName(),
+				CreateTime: b.obj.GetCreateTime(),
+				Status:     api.BackupScheduleInfo_StatusInfo_Status(b.obj.GetStatus().GetStatus()),
+				Reason:     b.obj.GetStatus().GetReason(),
+			})
+		}
+		schedule.BackupStatus = map[string]*api.BackupScheduleInfo_StatusInfoList{kind: list}
+	}
+}
+
+type backupScheduleService struct {
+	api.UnimplementedBackupScheduleServer
+	*Server
+}
+
+// scheduleRefs resolves the schedule policy, backup location and cluster of a backup schedule
+func (b *backupScheduleService) scheduleRefs(ctx context.Context, org string, policyRef *api.ObjectRef, policy string,
+	locationRef *api.ObjectRef, location, cluster string) (*api.ObjectRef, *api.ObjectRef, error) {
+	p, err := b.policies.findRef(ctx, org, policyRef, policy, readAccess)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, err := b.locations.findRef(ctx, org, locationRef, location, readAccess)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := b.clusters.find(ctx, org, cluster, "", readAccess); err != nil {
+		return nil, nil, err
+	}
+	return &api.ObjectRef{Name: p.obj.GetName(), Uid: p.obj.GetUid()}, &api.ObjectRef{Name: l.obj.GetName(), Uid: l.obj.GetUid()}, nil
+}
+
+func (b *backupScheduleService) Create(ctx context.Context, req *api.BackupScheduleCreateRequest) (*api.BackupScheduleCreateResponse, error) {
+	m, err := b.newMetadata(ctx, req.CreateMetadata)
+	if err != nil {
+		return nil, err
+	}
+	policyRef, locationRef, err := b.scheduleRefs(ctx, req.GetOrgId(), req.GetSchedulePolicyRef(), req.GetSchedulePolicy(),
+		req.GetBackupLocationRef(), req.GetBackupLocation(), req.GetCluster())
+	if err != nil {
+		return nil, err
+	}
+	obj := &api.BackupScheduleObject{Metadata: m, BackupScheduleInfo: &api.BackupScheduleInfo{
+		SchedulePolicy:    policyRef.GetName(),
+		SchedulePolicyRef: policyRef,
+		ReclaimPolicy:     req.GetReclaimPolicy(),
+		BackupLocation:    locationRef.GetName(),
+		BackupLocationRef: locationRef,
+		Cluster:           req.GetCluster(),
+		Namespaces:        req.GetNamespaces(),
+		LabelSelectors:    req.GetLabelSelectors(),
+		PreExecRule:       req.GetPreExecRule(),
+		PostExecRule:      req.GetPostExecRule(),
+		PreExecRuleRef:    req.GetPreExecRuleRef(),
+		PostExecRuleRef:   req.GetPostExecRuleRef(),
+		ResourceTypes:     req.GetResourceTypes(),
+		Status:            &api.BackupScheduleInfo_StatusInfo{Status: api.BackupScheduleInfo_StatusInfo_Success},
+	}}
+	now := b.config.Now()
+	if _, err := b.schedules.add(obj, now); err != nil {
+		return nil, err
+	}
+	b.advance(now)
+	return &api.BackupScheduleCreateResponse{}, nil
+}
+
+func (b *backupScheduleService) Update(ctx context.Context, req *api.BackupScheduleUpdateRequest) (*api.BackupScheduleUpdateResponse, error) {
+	e, err := b.schedules.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	b.updated(e.obj.Metadata, req.CreateMetadata)
+	schedule := e.obj
+	if req.GetSchedulePolicy() != "" || req.GetSchedulePolicyRef() != nil {
+		p, err := b.policies.findRef(ctx, req.GetOrgId(), req.GetSchedulePolicyRef(), req.GetSchedulePolicy(), readAccess)
+		if err != nil {
+			return nil, err
+		}
+		schedule.SchedulePolicy = p.obj.GetName()
+		schedule.SchedulePolicyRef = &api.ObjectRef{Name: p.obj.GetName(), Uid: p.obj.GetUid()}
+	}
+	if req.GetNamespaces() != nil {
+		schedule.Namespaces = req.GetNamespaces()
+	}
+	if schedule.Suspend && !req.GetSuspend() {
+		// resumed schedules take a backup right away
+		delete(b.scheduleRuns, schedule.GetUid())
+		e.created = b.config.Now()
+	}
+	schedule.Suspend = req.GetSuspend()
+	b.advance(b.config.Now())
+	return &api.BackupScheduleUpdateResponse{}, nil
+}
+
+func (b *backupScheduleService) Enumerate(ctx context.Context, req *api.BackupScheduleEnumerateRequest) (*api.BackupScheduleEnumerateResponse, error) {
+	var schedules []*api.BackupScheduleObject
+	for _, obj := range b.schedules.visible(ctx, req.GetOrgId()) {
+		if !matchLabels(obj.GetLabels(), req.GetLabels()) {
+			continue
+		}
+		if location := req.GetBackupLocationRef().GetName(); location != "" && obj.GetBackupLocation() != location {
+			continue
+		}
+		if req.GetBackupLocation() != "" && obj.GetBackupLocation() != req.GetBackupLocation() {
+			continue
+		}
+		schedules = append(schedules, obj)
+	}
+	return &api.BackupScheduleEnumerateResponse{BackupSchedules: schedules}, nil
+}
+
+func (b *backupScheduleService) Inspect(ctx context.Context, req *api.BackupScheduleInspectRequest) (*api.BackupScheduleInspectResponse, error) {
+	e, err := b.schedules.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.BackupScheduleInspectResponse{BackupSchedule: clone(e.obj)}, nil
+}
+
+func (b *backupScheduleService) Delete(ctx context.Context, req *api.BackupScheduleDeleteRequest) (*api.BackupScheduleDeleteResponse, error) {
+	e, err := b.schedules.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetDeleteBackups() {
+		for _, bkp := range b.scheduleBackups(e.obj) {
+			b.deleteBackup(bkp)
+		}
+	}
+	delete(b.scheduleRuns, e.obj.GetUid())
+	delete(b.scheduled, e.obj.GetUid())
+	b.schedules.remove(req.GetOrgId(), req.GetName())
+	return &api.BackupScheduleDeleteResponse{}, nil
+}
+
+type backupService struct {
+	api.UnimplementedBackupServer
+	*Server
+}
+
+func (b *backupService) Create(ctx context.Context, req *api.BackupCreateRequest) (*api.BackupCreateResponse, error) {
+	m, err := b.newMetadata(ctx, req.CreateMetadata)
+	if err != nil {
+		return nil, err
+	}
+	l, err := b.locations.findRef(ctx, req.GetOrgId(), req.GetBackupLocationRef(), req.GetBackupLocation(), readAccess)
+	if err != nil {
+		return nil, err
+	}
+	c, err := b.clusters.findRef(ctx, req.GetOrgId(), req.GetClusterRef(), req.GetCluster(), readAccess)
+	if err != nil {
+		return nil, err
+	}
+	obj := &api.BackupObject{Metadata: m, BackupInfo: &api.BackupInfo{
+		BackupLocation:    l.obj.GetName(),
+		BackupLocationRef: &api.ObjectRef{Name: l.obj.GetName(), Uid: l.obj.GetUid()},
+		Cluster:           c.obj.GetName(),
+		ClusterRef:        &api.ObjectRef{Name: c.obj.GetName(), Uid: c.obj.GetUid()},
+		Namespaces:        req.GetNamespaces(),
+		LabelSelectors:    req.GetLabelSelectors(),
+		PreExecRule:       req.GetPreExecRule(),
+		PostExecRule:      req.GetPostExecRule(),
+		PreExecRuleRef:    req.GetPreExecRuleRef(),
+		PostExecRuleRef:   req.GetPostExecRuleRef(),
+		IncludeResources:  req.GetIncludeResources(),
+		ResourceTypes:     req.GetResourceTypes(),
+		NsLabelSelectors:  req.GetNsLabelSelectors(),
+	}}
+	if err := b.addBackup(obj, b.config.Now()); err != nil {
+		return nil, err
+	}
+	return &api.BackupCreateResponse{}, nil
+}
+
+// visibleBackup returns a copy of a backup with the access of the user to it
+func (b *backupService) visibleBackup(ctx context.Context, obj *api.BackupObject) *api.BackupObject {
+	obj = clone(obj)
+	obj.UserBackupshareAccess = shareAccessType(b.backupAccess(userOf(ctx), obj))
+	return obj
+}
+
+// Enumerate returns the backups the user can see from the newest, in pages of MaxObjects
+// backups from ObjectIndex
+func (b *backupService) Enumerate(ctx context.Context, req *api.BackupEnumerateRequest) (*api.BackupEnumerateResponse, error) {
+	opts := req.EnumerateOptions
+	var backups []*api.BackupObject
+	visible := b.backups.visible(ctx, req.GetOrgId())
+	for i := len(visible) - 1; i >= 0; i-- {
+		obj := visible[i]
+		if !matchLabels(obj.GetLabels(), opts.GetLabels()) ||
+			(opts.GetNameFilter() != "" && !strings.Contains(obj.GetName(), opts.GetNameFilter())) ||
+			(opts.GetClusterNameFilter() != "" && obj.GetCluster() != opts.GetClusterNameFilter()) ||
+			(opts.GetClusterUidFilter() != "" && obj.GetClusterRef().GetUid() != opts.GetClusterUidFilter()) {
+			continue
+		}
+		backups = append(backups, b.visibleBackup(ctx, obj))
+	}
+	page, complete := paginate(len(backups), opts)
+	return &api.BackupEnumerateResponse{Backups: backups[page[0]:page[1]], TotalCount: uint64(len(backups)), Complete: complete}, nil
+}
+
+// paginate returns the range of the page of objects of the enumerate options, and whether it is
+// the last page
+func paginate(total int, opts *api.EnumerateOptions) ([2]int, bool) {
+	start := int(opts.GetObjectIndex())
+	if start > total {
+		start = total
+	}
+	end := total
+	if n := int(opts.GetMaxObjects()); n > 0 && start+n < total {
+		end = start + n
+	}
+	return [2]int{start, end}, end == total
+}
+
+func (b *backupService) Inspect(ctx context.Context, req *api.BackupInspectRequest) (*api.BackupInspectResponse, error) {
+	e, err := b.backups.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.BackupInspectResponse{Backup: b.visibleBackup(ctx, e.obj)}, nil
+}
+
+func (b *backupService) Delete(ctx context.Context, req *api.BackupDeleteRequest) (*api.BackupDeleteResponse, error) {
+	e, err := b.backups.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	b.deleteBackup(e)
+	return &api.BackupDeleteResponse{}, nil
+}
+
+// UpdateBackupShare replaces the share of a backup
+func (b *backupService) UpdateBackupShare(ctx context.Context, req *api.BackupShareUpdateRequest) (*api.BackupShareUpdateResponse, error) {
+	e, err := b.backups.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), adminAccess)
+	if err != nil {
+		return nil, err
+	}
+	e.obj.BackupShare = proto.Clone(req.GetBackupshare()).(*api.BackupShare)
+	return &api.BackupShareUpdateResponse{}, nil
+}
+
+type restoreService struct {
+	api.UnimplementedRestoreServer
+	*Server
+}
+
+func (r *restoreService) Create(ctx context.Context, req *api.RestoreCreateRequest) (*api.RestoreCreateResponse, error) {
+	m, err := r.newMetadata(ctx, req.CreateMetadata)
+	if err != nil {
+		return nil, err
+	}
+	bkp, err := r.backups.findRef(ctx, req.GetOrgId(), req.GetBackupRef(), req.GetBackup(), restoreAccess)
+	if err != nil {
+		return nil, err
+	}
+	if s := bkp.obj.GetStatus().GetStatus(); s != api.BackupInfo_StatusInfo_Success && s != api.BackupInfo_StatusInfo_PartialSuccess {
+		return nil, status.Errorf(codes.FailedPrecondition, "backup %s is in %s state", bkp.obj.GetName(), s)
+	}
+	if _, err := r.clusters.find(ctx, req.GetOrgId(), req.GetCluster(), "", readAccess); err != nil {
+		return nil, err
+	}
+	obj := &api.RestoreObject{Metadata: m, RestoreInfo: &api.RestoreInfo{
+		Backup:              bkp.obj.GetName(),
+		BackupRef:           &api.ObjectRef{Name: bkp.obj.GetName(), Uid: bkp.obj.GetUid()},
+		BackupLocation:      bkp.obj.GetBackupLocation(),
+		BackupLocationRef:   bkp.obj.GetBackupLocationRef(),
+		Cluster:             req.GetCluster(),
+		NamespaceMapping:    req.GetNamespaceMapping(),
+		StorageClassMapping: req.GetStorageClassMapping(),
+		ReplacePolicy:       req.GetReplacePolicy(),
+		IncludeResources:    req.GetIncludeResources(),
+		Status:              &api.RestoreInfo_StatusInfo{Status: r.config.RestoreTimeline[0].Status},
+	}}
+	if _, err := r.restores.add(obj, r.config.Now()); err != nil {
+		return nil, err
+	}
+	return &api.RestoreCreateResponse{}, nil
+}
+
+// Enumerate returns the restores the user can see from the newest
+func (r *restoreService) Enumerate(ctx context.Context, req *api.RestoreEnumerateRequest) (*api.RestoreEnumerateResponse, error) {
+	opts := req.EnumerateOptions
+	var restores []*api.RestoreObject
+	visible := r.restores.visible(ctx, req.GetOrgId())
+	for i := len(visible) - 1; i >= 0; i-- {
+		obj := visible[i]
+		if !matchLabels(obj.GetLabels(), opts.GetLabels()) ||
+			(opts.GetNameFilter() != "" && !strings.Contains(obj.GetName(), opts.GetNameFilter())) ||
+			(opts.GetClusterNameFilter() != "" && obj.GetCluster() != opts.GetClusterNameFilter()) {
+			continue
+		}
+		restores = append(restores, obj)
+	}
+	page, complete := paginate(len(restores), opts)
+	return &api.RestoreEnumerateResponse{Restores: restores[page[0]:page[1]], TotalCount: uint64(len(restores)), Complete: complete}, nil
+}
+
+func (r *restoreService) Inspect(ctx context.Context, req *api.RestoreInspectRequest) (*api.RestoreInspectResponse, error) {
+	e, err := r.restores.find(ctx, req.GetOrgId(), req.GetName(), "", readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.RestoreInspectResponse{Restore: clone(e.obj)}, nil
+}
+
+func (r *restoreService) Delete(ctx context.Context, req *api.RestoreDeleteRequest) (*api.RestoreDeleteResponse, error) {
+	e, err := r.restores.find(ctx, req.GetOrgId(), req.GetName(), "", writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	if e.deleted.IsZero() {
+		e.deleted = r.config.Now()
+		e.obj.Status = &api.RestoreInfo_StatusInfo{Status: api.RestoreInfo_StatusInfo_Deleting}
+	}
+	return &api.RestoreDeleteResponse{}, nil
+}
@@ -0,0 +1,26 @@
+// Package pxbtest runs the portworx backup driver against a fake PX-Backup server in unit tests.
+package pxbtest
+
+import (
+	"testing"
+
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb"
+	"github.com/portworx/torpedo/drivers/backup/portworx"
+)
+
+// NewDriver starts a fake PX-Backup server with the config and returns the portworx backup
+// driver connected to it. The server is stopped when the test ends.
+func NewDriver(t testing.TB, config fakepxb.Config) (backup.Driver, *fakepxb.Server) {
+	t.Helper()
+	server, err := fakepxb.New(config)
+	if err != nil {
+		t.Fatalf("failed to start fake PX-Backup: %v", err)
+	}
+	t.Cleanup(server.Stop)
+	driver, err := portworx.NewWithEndpoint(server.Endpoint())
+	if err != nil {
+		t.Fatalf("failed to connect to fake PX-Backup at %s: %v", server.Endpoint(), err)
+	}
+	return driver, server
+}
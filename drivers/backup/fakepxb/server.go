@@ -0,0 +1,663 @@
+// Package fakepxb is an in-process PX-Backup gRPC server which keeps its state in memory, so that
+// the PX-Backup driver and the tests using it can run without px-central. Backups and restores
+// go through configurable timelines of statuses, schedules create backups as their policies say
+// and faults can be injected in any method.
+//
+// Users are taken from the bearer token of the requests, as a JWT whose sub claim is the id of
+// the user, preferred_username its name and groups its groups, or as the token itself, which is
+// then both the id and the name of the user. Objects are owned by the users who create them and
+// shared through their ownership, and backups through backup shares, like in PX-Backup.
+package fakepxb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/pborman/uuid"
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultAdminUser is the default administrator, who has access to all objects
+	DefaultAdminUser = "px-central-admin"
+	// DefaultOrg is the organization which exists when the server starts
+	DefaultOrg = "default"
+	// healthMethod is the only method which does not need a token
+	healthMethod = "/Health/Status"
+)
+
+// BackupPhase is a status a backup reaches some time after it is created, with its stage
+type BackupPhase struct {
+	After  time.Duration
+	Status api.BackupInfo_StatusInfo_Status
+	Stage  api.BackupInfo_Stage
+	Reason string
+}
+
+// RestorePhase is a status a restore reaches some time after it is created
+type RestorePhase struct {
+	After  time.Duration
+	Status api.RestoreInfo_StatusInfo_Status
+	Reason string
+}
+
+var (
+	// DefaultBackupTimeline is the timeline of backups which complete
+	DefaultBackupTimeline = []BackupPhase{
+		{Status: api.BackupInfo_StatusInfo_Pending, Stage: api.BackupInfo_Initial},
+		{After: 50 * time.Millisecond, Status: api.BackupInfo_StatusInfo_InProgress, Stage: api.BackupInfo_Volumes},
+		{After: 200 * time.Millisecond, Status: api.BackupInfo_StatusInfo_Success, Stage: api.BackupInfo_Final, Reason: "Backup completed successfully"},
+	}
+	// DefaultRestoreTimeline is the timeline of restores which complete
+	DefaultRestoreTimeline = []RestorePhase{
+		{Status: api.RestoreInfo_StatusInfo_Pending},
+		{After: 50 * time.Millisecond, Status: api.RestoreInfo_StatusInfo_InProgress},
+		{After: 200 * time.Millisecond, Status: api.RestoreInfo_StatusInfo_Success, Reason: "Restore completed successfully"},
+	}
+)
+
+// Config configures a server. The zero value is a valid configuration.
+type Config struct {
+	// BackupTimeline is the timeline of backups, DefaultBackupTimeline if empty
+	BackupTimeline []BackupPhase
+	// RestoreTimeline is the timeline of restores, DefaultRestoreTimeline if empty
+	RestoreTimeline []RestorePhase
+	// DeleteDelay is how long deleted backups and restores stay in the Deleting status
+	DeleteDelay time.Duration
+	// ScheduleInterval replaces the interval of all schedule policies, to run schedules faster
+	ScheduleInterval time.Duration
+	// ObjectLockPeriod is how long the objects of backups of schedules with policies for object
+	// lock are locked. These backups are not deleted past the retain count of their policy but,
+	// with auto delete, once their objects and those of their incremental chain are unlocked.
+	ObjectLockPeriod time.Duration
+	// AdminUser is the administrator, DefaultAdminUser if empty
+	AdminUser string
+	// Version is the version of PX-Backup reported by the server, 2.4.0 if empty
+	Version string
+	// Now returns the current time, time.Now if nil. Statuses only change when requests are
+	// served, so tests can move time forward between requests.
+	Now func() time.Time
+}
+
+// user is the user of a request
+type user struct {
+	id     string
+	name   string
+	groups []string
+	admin  bool
+}
+
+type userKey struct{}
+
+func userOf(ctx context.Context) *user {
+	u, _ := ctx.Value(userKey{}).(*user)
+	return u
+}
+
+type fault struct {
+	err   error
+	times int
+}
+
+// Server is a fake PX-Backup server
+type Server struct {
+	config   Config
+	grpc     *grpc.Server
+	listener net.Listener
+
+	// mutex serializes all requests
+	sync.Mutex
+	orgs             *collection[*api.OrganizationObject]
+	credentials      *collection[*api.CloudCredentialObject]
+	clusters         *collection[*api.ClusterObject]
+	locations        *collection[*api.BackupLocationObject]
+	policies         *collection[*api.SchedulePolicyObject]
+	rules            *collection[*api.RuleObject]
+	schedules        *collection[*api.BackupScheduleObject]
+	backups          *collection[*api.BackupObject]
+	restores         *collection[*api.RestoreObject]
+	licensed         map[string]bool
+	faults           map[string]*fault
+	backupTimelines  map[string][]BackupPhase
+	restoreTimelines map[string][]RestorePhase
+	// scheduleRuns are the times of the last runs of schedules, by uid
+	scheduleRuns map[string]time.Time
+	// scheduled are the numbers of backups created by schedules, by uid
+	scheduled map[string]int
+	// sequence are the numbers of the backups created by schedules in their schedule, by uid
+	sequence map[string]int
+	// clusterShares are the shares of all backups of clusters, by uid
+	clusterShares map[string]*api.BackupShare
+}
+
+// New starts a server on a local port. It must be stopped with Stop.
+func New(config Config) (*Server, error) {
+	if len(config.BackupTimeline) == 0 {
+		config.BackupTimeline = DefaultBackupTimeline
+	}
+	if len(config.RestoreTimeline) == 0 {
+		config.RestoreTimeline = DefaultRestoreTimeline
+	}
+	if config.AdminUser == "" {
+		config.AdminUser = DefaultAdminUser
+	}
+	if config.Version == "" {
+		config.Version = "2.4.0"
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	s := &Server{
+		config:           config,
+		orgs:             newCollection[*api.OrganizationObject]("organization"),
+		credentials:      newCollection[*api.CloudCredentialObject]("cloud credential"),
+		clusters:         newCollection[*api.ClusterObject]("cluster"),
+		locations:        newCollection[*api.BackupLocationObject]("backup location"),
+		policies:         newCollection[*api.SchedulePolicyObject]("schedule policy"),
+		rules:            newCollection[*api.RuleObject]("rule"),
+		schedules:        newCollection[*api.BackupScheduleObject]("backup schedule"),
+		backups:          newCollection[*api.BackupObject]("backup"),
+		restores:         newCollection[*api.RestoreObject]("restore"),
+		licensed:         make(map[string]bool),
+		faults:           make(map[string]*fault),
+		backupTimelines:  make(map[string][]BackupPhase),
+		restoreTimelines: make(map[string][]RestorePhase),
+		scheduleRuns:     make(map[string]time.Time),
+		scheduled:        make(map[string]int),
+		sequence:         make(map[string]int),
+		clusterShares:    make(map[string]*api.BackupShare),
+	}
+	s.backups.access = s.backupAccess
+	now := config.Now()
+	s.orgs.add(&api.OrganizationObject{Metadata: s.metadata(&user{id: config.AdminUser, name: config.AdminUser, admin: true}, &api.CreateMetadata{Name: DefaultOrg}, now)}, now)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s.listener = listener
+	s.grpc = grpc.NewServer(grpc.UnaryInterceptor(s.intercept))
+	api.RegisterHealthServer(s.grpc, &healthService{})
+	api.RegisterVersionServer(s.grpc, &versionService{Server: s})
+	api.RegisterLicenseServer(s.grpc, &licenseService{Server: s})
+	api.RegisterOrganizationServer(s.grpc, &organizationService{Server: s})
+	api.RegisterCloudCredentialServer(s.grpc, &cloudCredentialService{Server: s})
+	api.RegisterClusterServer(s.grpc, &clusterService{Server: s})
+	api.RegisterBackupLocationServer(s.grpc, &backupLocationService{Server: s})
+	api.RegisterSchedulePolicyServer(s.grpc, &schedulePolicyService{Server: s})
+	api.RegisterRulesServer(s.grpc, &rulesService{Server: s})
+	api.RegisterBackupScheduleServer(s.grpc, &backupScheduleService{Server: s})
+	api.RegisterBackupServer(s.grpc, &backupService{Server: s})
+	api.RegisterRestoreServer(s.grpc, &restoreService{Server: s})
+	go s.grpc.Serve(listener)
+	return s, nil
+}
+
+// Endpoint returns the host and port of the server, to be used as BACKUP_API_ENDPOINT
+func (s *Server) Endpoint() string {
+	return s.listener.Addr().String()
+}
+
+// Stop stops the server
+func (s *Server) Stop() {
+	s.grpc.Stop()
+}
+
+// InjectFault makes the next calls of a method fail with the error, all calls if times is
+// negative. Methods are full gRPC method names, like /Backup/Create.
+func (s *Server) InjectFault(method string, err error, times int) {
+	s.Lock()
+	defer s.Unlock()
+	s.faults[method] = &fault{err: err, times: times}
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.Lock()
+	defer s.Unlock()
+	s.faults = make(map[string]*fault)
+}
+
+// SetBackupTimeline sets the timeline of the backup with the given name, like a failure, in place
+// of the timeline of the configuration. It can be set before or after the backup is created.
+func (s *Server) SetBackupTimeline(name string, timeline []BackupPhase) {
+	s.Lock()
+	defer s.Unlock()
+	s.backupTimelines[name] = timeline
+}
+
+// SetRestoreTimeline sets the timeline of the restore with the given name
+func (s *Server) SetRestoreTimeline(name string, timeline []RestorePhase) {
+	s.Lock()
+	defer s.Unlock()
+	s.restoreTimelines[name] = timeline
+}
+
+// intercept serves requests one at a time, after injecting faults, authenticating the user and
+// moving objects along their timelines
+func (s *Server) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if f, ok := s.faults[info.FullMethod]; ok {
+		if f.times > 0 {
+			f.times--
+		}
+		if f.times == 0 {
+			delete(s.faults, info.FullMethod)
+		}
+		return nil, f.err
+	}
+	if info.FullMethod != healthMethod {
+		u, err := s.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, userKey{}, u)
+	}
+	s.advance(s.config.Now())
+	return handler(ctx, req)
+}
+
+// authenticate returns the user of the bearer token of the request
+func (s *Server) authenticate(ctx context.Context) (*user, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, v := range md.Get("authorization") {
+		if fields := strings.Fields(v); len(fields) == 2 && strings.EqualFold(fields[0], "bearer") {
+			token = fields[1]
+		}
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	u := &user{id: token, name: token}
+	if parts := strings.Split(token, "."); len(parts) == 3 {
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+		}
+		var claims struct {
+			Sub               string   `json:"sub"`
+			PreferredUsername string   `json:"preferred_username"`
+			Groups            []string `json:"groups"`
+			Roles             []string `json:"roles"`
+		}
+		if err := json.Unmarshal(payload, &claims); err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+		}
+		u.id, u.name, u.groups = claims.Sub, claims.PreferredUsername, claims.Groups
+		if u.name == "" {
+			u.name = u.id
+		}
+		for _, role := range claims.Roles {
+			u.admin = u.admin || role == "system.admin"
+		}
+	}
+	for _, g := range u.groups {
+		u.admin = u.admin || g == "*"
+	}
+	u.admin = u.admin || u.name == s.config.AdminUser
+	return u, nil
+}
+
+// metadata returns the metadata of a new object created by the user
+func (s *Server) metadata(u *user, cm *api.CreateMetadata, now time.Time) *api.Metadata {
+	uid := cm.GetUid()
+	if uid == "" {
+		uid = uuid.New()
+	}
+	ownership := &api.Ownership{}
+	if cm.GetOwnership() != nil {
+		ownership = proto.Clone(cm.GetOwnership()).(*api.Ownership)
+	}
+	if ownership.Owner == "" || !u.admin {
+		ownership.Owner = u.name
+	}
+	return &api.Metadata{
+		Name:            cm.GetName(),
+		Uid:             uid,
+		OrgId:           cm.GetOrgId(),
+		Owner:           ownership.Owner,
+		CreateTime:      timestamp(now),
+		LastUpdateTime:  timestamp(now),
+		Labels:          cm.GetLabels(),
+		CreateTimeInSec: now.Unix(),
+		Ownership:       ownership,
+	}
+}
+
+// newMetadata validates the creation of an object in an organization
+func (s *Server) newMetadata(ctx context.Context, cm *api.CreateMetadata) (*api.Metadata, error) {
+	if cm.GetName() == "" || cm.GetOrgId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and org id are required")
+	}
+	// organizations are not in any organization
+	if _, err := s.orgs.get("", cm.GetOrgId(), ""); err != nil {
+		return nil, err
+	}
+	return s.metadata(userOf(ctx), cm, s.config.Now()), nil
+}
+
+// updated updates the metadata of an object from the metadata of an update request
+func (s *Server) updated(m *api.Metadata, cm *api.CreateMetadata) {
+	if cm.GetLabels() != nil {
+		m.Labels = cm.GetLabels()
+	}
+	m.LastUpdateTime = timestamp(s.config.Now())
+}
+
+// advance moves backups and restores along their timelines, runs schedules and removes deleted
+// objects
+func (s *Server) advance(now time.Time) {
+	s.runSchedules(now)
+	for _, e := range s.backups.all() {
+		if !e.deleted.IsZero() {
+			if now.Sub(e.deleted) >= s.config.DeleteDelay {
+				s.backups.remove(e.obj.GetOrgId(), e.obj.GetName())
+			}
+			continue
+		}
+		timeline, ok := s.backupTimelines[e.obj.GetName()]
+		if !ok {
+			timeline = s.config.BackupTimeline
+		}
+		phase := timeline[0]
+		for _, p := range timeline {
+			if now.Sub(e.created) >= p.After {
+				phase = p
+			}
+		}
+		e.obj.Status = &api.BackupInfo_StatusInfo{Status: phase.Status, Reason: phase.Reason}
+		e.obj.Stage = phase.Stage
+	}
+	for _, e := range s.restores.all() {
+		if !e.deleted.IsZero() {
+			if now.Sub(e.deleted) >= s.config.DeleteDelay {
+				s.restores.remove(e.obj.GetOrgId(), e.obj.GetName())
+			}
+			continue
+		}
+		timeline, ok := s.restoreTimelines[e.obj.GetName()]
+		if !ok {
+			timeline = s.config.RestoreTimeline
+		}
+		phase := timeline[0]
+		for _, p := range timeline {
+			if now.Sub(e.created) >= p.After {
+				phase = p
+			}
+		}
+		e.obj.Status = &api.RestoreInfo_StatusInfo{Status: phase.Status, Reason: phase.Reason}
+	}
+	s.updateScheduleStatus()
+}
+
+func timestamp(t time.Time) *types.Timestamp {
+	ts, _ := types.TimestampProto(t)
+	return ts
+}
+
+// access is the access of a user to an object
+type access int
+
+const (
+	noAccess access = iota
+	readAccess
+	restoreAccess
+	writeAccess
+	adminAccess
+)
+
+func (a access) String() string {
+	return [...]string{"none", "read", "restore", "write", "admin"}[a]
+}
+
+func ownershipAccessOf(t api.Ownership_AccessType) access {
+	switch t {
+	case api.Ownership_Read:
+		return readAccess
+	case api.Ownership_Write:
+		return writeAccess
+	case api.Ownership_Admin:
+		return adminAccess
+	}
+	return noAccess
+}
+
+func shareAccessOf(t api.BackupShare_AccessType) access {
+	switch t {
+	case api.BackupShare_View:
+		return readAccess
+	case api.BackupShare_Restorable:
+		return restoreAccess
+	case api.BackupShare_FullAccess:
+		return writeAccess
+	}
+	return noAccess
+}
+
+// is returns true if the id or name identifies the user
+func (u *user) is(idOrName string) bool {
+	return idOrName == u.id || idOrName == u.name
+}
+
+// granted returns the highest access granted to the user by the entries of access configs
+func (u *user) granted(ids []string, accesses []access, isGroup bool) access {
+	a := noAccess
+	for i, id := range ids {
+		match := id == "*"
+		if isGroup {
+			for _, g := range u.groups {
+				match = match || g == id
+			}
+		} else {
+			match = match || u.is(id)
+		}
+		if match && accesses[i] > a {
+			a = accesses[i]
+		}
+	}
+	return a
+}
+
+func max(accesses ...access) access {
+	a := noAccess
+	for _, b := range accesses {
+		if b > a {
+			a = b
+		}
+	}
+	return a
+}
+
+// ownershipAccess returns the access of the user to an object through its ownership
+func ownershipAccess(u *user, o object) access {
+	ownership := o.GetOwnership()
+	if u.admin || u.is(ownership.GetOwner()) {
+		return adminAccess
+	}
+	var ids []string
+	var accesses []access
+	for _, c := range ownership.GetCollaborators() {
+		ids, accesses = append(ids, c.GetId()), append(accesses, ownershipAccessOf(c.GetAccess()))
+	}
+	collaborators := u.granted(ids, accesses, false)
+	ids, accesses = nil, nil
+	for _, g := range ownership.GetGroups() {
+		ids, accesses = append(ids, g.GetId()), append(accesses, ownershipAccessOf(g.GetAccess()))
+	}
+	return max(collaborators, u.granted(ids, accesses, true), ownershipAccessOf(ownership.GetPublic().GetType()))
+}
+
+// shareAccess returns the access of the user through a backup share
+func shareAccess(u *user, share *api.BackupShare) access {
+	var ids []string
+	var accesses []access
+	for _, c := range share.GetCollaborators() {
+		ids, accesses = append(ids, c.GetId()), append(accesses, shareAccessOf(c.GetAccess()))
+	}
+	collaborators := u.granted(ids, accesses, false)
+	ids, accesses = nil, nil
+	for _, g := range share.GetGroups() {
+		ids, accesses = append(ids, g.GetId()), append(accesses, shareAccessOf(g.GetAccess()))
+	}
+	return max(collaborators, u.granted(ids, accesses, true))
+}
+
+// object is implemented by all PX-Backup objects through their metadata
+type object interface {
+	proto.Message
+	GetName() string
+	GetUid() string
+	GetOrgId() string
+	GetOwnership() *api.Ownership
+}
+
+type entry[T object] struct {
+	obj     T
+	created time.Time
+	// deleted is when the object was deleted, zero if it was not
+	deleted time.Time
+}
+
+// collection holds the objects of a kind by organization and name
+type collection[T object] struct {
+	kind    string
+	entries map[string]*entry[T]
+	// access returns the access of a user to an object, ownershipAccess by default
+	access func(u *user, obj T) access
+}
+
+func newCollection[T object](kind string) *collection[T] {
+	return &collection[T]{
+		kind:    kind,
+		entries: make(map[string]*entry[T]),
+		access: func(u *user, obj T) access {
+			return ownershipAccess(u, obj)
+		},
+	}
+}
+
+func key(org, name string) string {
+	return org + "/" + name
+}
+
+func (c *collection[T]) add(obj T, now time.Time) (*entry[T], error) {
+	k := key(obj.GetOrgId(), obj.GetName())
+	if _, ok := c.entries[k]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "%s %s already exists", c.kind, obj.GetName())
+	}
+	e := &entry[T]{obj: obj, created: now}
+	c.entries[k] = e
+	return e, nil
+}
+
+// get returns the object with the given name, and uid if it is not empty
+func (c *collection[T]) get(org, name, uid string) (*entry[T], error) {
+	e, ok := c.entries[key(org, name)]
+	if !ok || (uid != "" && e.obj.GetUid() != uid) {
+		return nil, status.Errorf(codes.NotFound, "%s %s not found in org %s", c.kind, name, org)
+	}
+	return e, nil
+}
+
+// find returns the object with the given name if the user has the needed access, and a permission
+// denied error like PX-Backup if the user can't see the object or has less access
+func (c *collection[T]) find(ctx context.Context, org, name, uid string, need access) (*entry[T], error) {
+	e, err := c.get(org, name, uid)
+	if err != nil {
+		return nil, err
+	}
+	u := userOf(ctx)
+	a := c.access(u, e.obj)
+	if a == noAccess {
+		return nil, status.Errorf(codes.PermissionDenied, "user %s doesn't have permission to access %s %s in org %s", u.name, c.kind, name, org)
+	}
+	if a < need {
+		return nil, status.Errorf(codes.PermissionDenied, "user %s has %s access to %s %s, %s access is required", u.name, a, c.kind, name, need)
+	}
+	return e, nil
+}
+
+// findRef returns the object of a reference, or with the given name if the reference is empty
+func (c *collection[T]) findRef(ctx context.Context, org string, ref *api.ObjectRef, name string, need access) (*entry[T], error) {
+	if ref.GetName() != "" {
+		return c.find(ctx, org, ref.GetName(), ref.GetUid(), need)
+	}
+	if name == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "%s is required", c.kind)
+	}
+	return c.find(ctx, org, name, "", need)
+}
+
+// byUID returns the object with the given uid in the organization
+func (c *collection[T]) byUID(org, uid string) (*entry[T], bool) {
+	for _, e := range c.entries {
+		if e.obj.GetOrgId() == org && e.obj.GetUid() == uid {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func (c *collection[T]) remove(org, name string) {
+	delete(c.entries, key(org, name))
+}
+
+// all returns the objects of all organizations from the oldest
+func (c *collection[T]) all() []*entry[T] {
+	entries := make([]*entry[T], 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].created.Equal(entries[j].created) {
+			return entries[i].created.Before(entries[j].created)
+		}
+		return entries[i].obj.GetName() < entries[j].obj.GetName()
+	})
+	return entries
+}
+
+// visible returns copies of the objects of the organization which the user can see, from the oldest
+func (c *collection[T]) visible(ctx context.Context, org string) []T {
+	u := userOf(ctx)
+	var objects []T
+	for _, e := range c.all() {
+		if e.obj.GetOrgId() == org && c.access(u, e.obj) > noAccess {
+			objects = append(objects, clone(e.obj))
+		}
+	}
+	return objects
+}
+
+func clone[T object](obj T) T {
+	return proto.Clone(obj).(T)
+}
+
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func invalid(format string, args ...interface{}) error {
+	return status.Error(codes.InvalidArgument, fmt.Sprintf(format, args...))
+}
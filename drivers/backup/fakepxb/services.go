@@ -0,0 +1,541 @@
+package fakepxb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// licenseFeature is the feature reported by activated licenses
+const licenseFeature = "BackupNodeCount"
+
+type healthService struct {
+	api.UnimplementedHealthServer
+}
+
+func (h *healthService) Status(context.Context, *api.HealthStatusRequest) (*api.HealthStatusResponse, error) {
+	return &api.HealthStatusResponse{}, nil
+}
+
+type versionService struct {
+	api.UnimplementedVersionServer
+	*Server
+}
+
+func (v *versionService) Get(context.Context, *api.VersionGetRequest) (*api.VersionGetResponse, error) {
+	version := &api.VersionInfo{}
+	parts := strings.SplitN(v.config.Version, ".", 3)
+	for i, f := range []*string{&version.Major, &version.Minor, &version.Patch} {
+		if i < len(parts) {
+			*f = parts[i]
+		}
+	}
+	return &api.VersionGetResponse{Version: version}, nil
+}
+
+type licenseService struct {
+	api.UnimplementedLicenseServer
+	*Server
+}
+
+func (l *licenseService) Activate(ctx context.Context, req *api.LicenseActivateRequest) (*api.LicenseActivateResponse, error) {
+	if !userOf(ctx).admin {
+		return nil, status.Error(codes.PermissionDenied, "only administrators can activate licenses")
+	}
+	if _, err := l.orgs.get("", req.GetOrgId(), ""); err != nil {
+		return nil, err
+	}
+	l.licensed[req.GetOrgId()] = true
+	return &api.LicenseActivateResponse{}, nil
+}
+
+func (l *licenseService) Inspect(ctx context.Context, req *api.LicenseInspectRequest) (*api.LicenseInspectResponse, error) {
+	if _, err := l.orgs.get("", req.GetOrgId(), ""); err != nil {
+		return nil, err
+	}
+	info := &api.LicenseResponseInfo{Status: &api.LicenseResponseInfo_Status{Status: "Invalid", Reason: "license is not activated"}}
+	if l.licensed[req.GetOrgId()] {
+		info.FeatureInfo = []*api.LicenseResponseInfo_FeatureInfo{{Name: licenseFeature}}
+		info.Status = &api.LicenseResponseInfo_Status{Status: "Valid"}
+	}
+	return &api.LicenseInspectResponse{LicenseRespInfo: info}, nil
+}
+
+type organizationService struct {
+	api.UnimplementedOrganizationServer
+	*Server
+}
+
+func (o *organizationService) Create(ctx context.Context, req *api.OrganizationCreateRequest) (*api.OrganizationCreateResponse, error) {
+	if req.GetName() == "" {
+		return nil, invalid("name is required")
+	}
+	now := o.config.Now()
+	cm := proto.Clone(req.CreateMetadata).(*api.CreateMetadata)
+	cm.OrgId = ""
+	if _, err := o.orgs.add(&api.OrganizationObject{Metadata: o.metadata(userOf(ctx), cm, now)}, now); err != nil {
+		return nil, err
+	}
+	return &api.OrganizationCreateResponse{}, nil
+}
+
+func (o *organizationService) Enumerate(ctx context.Context, req *api.OrganizationEnumerateRequest) (*api.OrganizationEnumerateResponse, error) {
+	// all users are members of all organizations
+	var orgs []*api.OrganizationObject
+	for _, e := range o.orgs.all() {
+		orgs = append(orgs, clone(e.obj))
+	}
+	return &api.OrganizationEnumerateResponse{Organizations: orgs}, nil
+}
+
+func (o *organizationService) Inspect(ctx context.Context, req *api.OrganizationInspectRequest) (*api.OrganizationInspectResponse, error) {
+	e, err := o.orgs.get("", req.GetName(), "")
+	if err != nil {
+		return nil, err
+	}
+	return &api.OrganizationInspectResponse{Organization: clone(e.obj)}, nil
+}
+
+// updateOwnership replaces the ownership of an object, which needs admin access to it
+func updateOwnership[T object](ctx context.Context, c *collection[T], org, name, uid string, ownership *api.Ownership) error {
+	e, err := c.find(ctx, org, name, uid, adminAccess)
+	if err != nil {
+		return err
+	}
+	current := e.obj.GetOwnership()
+	owner := current.GetOwner()
+	if ownership.GetOwner() != "" && userOf(ctx).admin {
+		owner = ownership.GetOwner()
+	}
+	*current = *proto.Clone(ownership).(*api.Ownership)
+	current.Owner = owner
+	return nil
+}
+
+type cloudCredentialService struct {
+	api.UnimplementedCloudCredentialServer
+	*Server
+}
+
+func (c *cloudCredentialService) Create(ctx context.Context, req *api.CloudCredentialCreateRequest) (*api.CloudCredentialCreateResponse, error) {
+	m, err := c.newMetadata(ctx, req.CreateMetadata)
+	if err != nil {
+		return nil, err
+	}
+	obj := &api.CloudCredentialObject{Metadata: m, CloudCredentialInfo: req.GetCloudCredential()}
+	if _, err := c.credentials.add(obj, c.config.Now()); err != nil {
+		return nil, err
+	}
+	return &api.CloudCredentialCreateResponse{}, nil
+}
+
+func (c *cloudCredentialService) Update(ctx context.Context, req *api.CloudCredentialUpdateRequest) (*api.CloudCredentialUpdateResponse, error) {
+	e, err := c.credentials.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	c.updated(e.obj.Metadata, req.CreateMetadata)
+	e.obj.CloudCredentialInfo = req.GetCloudCredential()
+	return &api.CloudCredentialUpdateResponse{}, nil
+}
+
+// withoutSecrets returns a credential without its config, unless secrets are asked for
+func withoutSecrets(obj *api.CloudCredentialObject, includeSecrets bool) *api.CloudCredentialObject {
+	if !includeSecrets && obj.CloudCredentialInfo != nil {
+		obj.CloudCredentialInfo.Config = nil
+	}
+	return obj
+}
+
+func (c *cloudCredentialService) Enumerate(ctx context.Context, req *api.CloudCredentialEnumerateRequest) (*api.CloudCredentialEnumerateResponse, error) {
+	credentials := c.credentials.visible(ctx, req.GetOrgId())
+	for _, obj := range credentials {
+		withoutSecrets(obj, req.GetIncludeSecrets())
+	}
+	return &api.CloudCredentialEnumerateResponse{CloudCredentials: credentials}, nil
+}
+
+func (c *cloudCredentialService) Inspect(ctx context.Context, req *api.CloudCredentialInspectRequest) (*api.CloudCredentialInspectResponse, error) {
+	e, err := c.credentials.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.CloudCredentialInspectResponse{CloudCredential: withoutSecrets(clone(e.obj), req.GetIncludeSecrets())}, nil
+}
+
+func (c *cloudCredentialService) Delete(ctx context.Context, req *api.CloudCredentialDeleteRequest) (*api.CloudCredentialDeleteResponse, error) {
+	e, err := c.credentials.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range c.locations.all() {
+		if l.obj.GetOrgId() == req.GetOrgId() && l.obj.BackupLocationInfo.GetCloudCredentialRef().GetUid() == e.obj.GetUid() {
+			return nil, status.Errorf(codes.FailedPrecondition, "cloud credential %s is used by backup location %s", req.GetName(), l.obj.GetName())
+		}
+	}
+	c.credentials.remove(req.GetOrgId(), req.GetName())
+	return &api.CloudCredentialDeleteResponse{}, nil
+}
+
+func (c *cloudCredentialService) UpdateOwnership(ctx context.Context, req *api.CloudCredentialOwnershipUpdateRequest) (*api.CloudCredentialOwnershipUpdateResponse, error) {
+	if err := updateOwnership(ctx, c.credentials, req.GetOrgId(), req.GetName(), req.GetUid(), req.GetOwnership()); err != nil {
+		return nil, err
+	}
+	return &api.CloudCredentialOwnershipUpdateResponse{}, nil
+}
+
+// credentialRef returns the reference to the credential of a reference or name, which must exist
+// if either is set
+func (s *Server) credentialRef(ctx context.Context, org string, ref *api.ObjectRef, name string) (*api.ObjectRef, error) {
+	if ref.GetName() == "" && name == "" {
+		return nil, nil
+	}
+	e, err := s.credentials.findRef(ctx, org, ref, name, readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.ObjectRef{Name: e.obj.GetName(), Uid: e.obj.GetUid()}, nil
+}
+
+type clusterService struct {
+	api.UnimplementedClusterServer
+	*Server
+}
+
+func (c *clusterService) Create(ctx context.Context, req *api.ClusterCreateRequest) (*api.ClusterCreateResponse, error) {
+	m, err := c.newMetadata(ctx, req.CreateMetadata)
+	if err != nil {
+		return nil, err
+	}
+	credentialRef, err := c.credentialRef(ctx, req.GetOrgId(), req.GetCloudCredentialRef(), req.GetCloudCredential())
+	if err != nil {
+		return nil, err
+	}
+	obj := &api.ClusterObject{Metadata: m, ClusterInfo: &api.ClusterInfo{
+		PxConfig:              req.GetPxConfig(),
+		Kubeconfig:            req.GetKubeconfig(),
+		CloudCredential:       credentialRef.GetName(),
+		CloudCredentialRef:    credentialRef,
+		PlatformCredentialRef: req.GetPlatformCredentialRef(),
+		Status:                &api.ClusterInfo_StatusInfo{Status: api.ClusterInfo_StatusInfo_Online},
+	}}
+	if _, err := c.clusters.add(obj, c.config.Now()); err != nil {
+		return nil, err
+	}
+	return &api.ClusterCreateResponse{}, nil
+}
+
+func (c *clusterService) Update(ctx context.Context, req *api.ClusterUpdateRequest) (*api.ClusterUpdateResponse, error) {
+	e, err := c.clusters.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	credentialRef, err := c.credentialRef(ctx, req.GetOrgId(), req.GetCloudCredentialRef(), req.GetCloudCredential())
+	if err != nil {
+		return nil, err
+	}
+	c.updated(e.obj.Metadata, req.CreateMetadata)
+	if req.GetKubeconfig() != "" {
+		e.obj.Kubeconfig = req.GetKubeconfig()
+	}
+	if credentialRef != nil {
+		e.obj.CloudCredential, e.obj.CloudCredentialRef = credentialRef.GetName(), credentialRef
+	}
+	return &api.ClusterUpdateResponse{}, nil
+}
+
+// withoutKubeconfig returns a cluster without its kubeconfig, unless secrets are asked for
+func withoutKubeconfig(obj *api.ClusterObject, includeSecrets bool) *api.ClusterObject {
+	if !includeSecrets && obj.ClusterInfo != nil {
+		obj.Kubeconfig = ""
+	}
+	return obj
+}
+
+func (c *clusterService) Enumerate(ctx context.Context, req *api.ClusterEnumerateRequest) (*api.ClusterEnumerateResponse, error) {
+	var clusters []*api.ClusterObject
+	for _, obj := range c.clusters.visible(ctx, req.GetOrgId()) {
+		if matchLabels(obj.GetLabels(), req.GetLabels()) {
+			clusters = append(clusters, withoutKubeconfig(obj, req.GetIncludeSecrets()))
+		}
+	}
+	return &api.ClusterEnumerateResponse{Clusters: clusters}, nil
+}
+
+func (c *clusterService) Inspect(ctx context.Context, req *api.ClusterInspectRequest) (*api.ClusterInspectResponse, error) {
+	e, err := c.clusters.find(ctx, req.GetOrgId(), req.GetName(), "", readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.ClusterInspectResponse{Cluster: withoutKubeconfig(clone(e.obj), req.GetIncludeSecrets())}, nil
+}
+
+func (c *clusterService) Delete(ctx context.Context, req *api.ClusterDeleteRequest) (*api.ClusterDeleteResponse, error) {
+	e, err := c.clusters.find(ctx, req.GetOrgId(), req.GetName(), "", writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetDeleteBackups() {
+		for _, b := range c.backups.all() {
+			if b.obj.GetOrgId() == req.GetOrgId() && b.obj.GetClusterRef().GetUid() == e.obj.GetUid() {
+				c.deleteBackup(b)
+			}
+		}
+	}
+	delete(c.clusterShares, e.obj.GetUid())
+	c.clusters.remove(req.GetOrgId(), req.GetName())
+	return &api.ClusterDeleteResponse{}, nil
+}
+
+// UpdateBackupShare shares all backups of the cluster, current and future ones
+func (c *clusterService) UpdateBackupShare(ctx context.Context, req *api.ClusterBackupShareUpdateRequest) (*api.ClusterBackupShareUpdateResponse, error) {
+	e, err := c.clusters.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), adminAccess)
+	if err != nil {
+		return nil, err
+	}
+	share, ok := c.clusterShares[e.obj.GetUid()]
+	if !ok {
+		share = &api.BackupShare{}
+		c.clusterShares[e.obj.GetUid()] = share
+	}
+	share.Groups = mergeShare(share.Groups, req.GetAddBackupShare().GetGroups(), req.GetDelBackupShare().GetGroups())
+	share.Collaborators = mergeShare(share.Collaborators, req.GetAddBackupShare().GetCollaborators(), req.GetDelBackupShare().GetCollaborators())
+	return &api.ClusterBackupShareUpdateResponse{}, nil
+}
+
+// mergeShare adds and removes access configs by id
+func mergeShare(configs, add, del []*api.BackupShare_AccessConfig) []*api.BackupShare_AccessConfig {
+	removed := make(map[string]bool)
+	for _, c := range append(add, del...) {
+		removed[c.GetId()] = true
+	}
+	var merged []*api.BackupShare_AccessConfig
+	for _, c := range configs {
+		if !removed[c.GetId()] {
+			merged = append(merged, c)
+		}
+	}
+	return append(merged, add...)
+}
+
+type backupLocationService struct {
+	api.UnimplementedBackupLocationServer
+	*Server
+}
+
+func (b *backupLocationService) Create(ctx context.Context, req *api.BackupLocationCreateRequest) (*api.BackupLocationCreateResponse, error) {
+	m, err := b.newMetadata(ctx, req.CreateMetadata)
+	if err != nil {
+		return nil, err
+	}
+	info := req.GetBackupLocation()
+	if info.GetPath() == "" {
+		return nil, invalid("path of backup location %s is required", req.GetName())
+	}
+	credentialRef, err := b.credentialRef(ctx, req.GetOrgId(), info.GetCloudCredentialRef(), info.GetCloudCredential())
+	if err != nil {
+		return nil, err
+	}
+	info = proto.Clone(info).(*api.BackupLocationInfo)
+	info.CloudCredential, info.CloudCredentialRef = credentialRef.GetName(), credentialRef
+	info.Status = &api.BackupLocationInfo_StatusInfo{Status: api.BackupLocationInfo_StatusInfo_Valid}
+	if _, err := b.locations.add(&api.BackupLocationObject{Metadata: m, BackupLocationInfo: info}, b.config.Now()); err != nil {
+		return nil, err
+	}
+	return &api.BackupLocationCreateResponse{}, nil
+}
+
+func (b *backupLocationService) Update(ctx context.Context, req *api.BackupLocationUpdateRequest) (*api.BackupLocationUpdateResponse, error) {
+	e, err := b.locations.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	info := proto.Clone(req.GetBackupLocation()).(*api.BackupLocationInfo)
+	credentialRef, err := b.credentialRef(ctx, req.GetOrgId(), info.GetCloudCredentialRef(), info.GetCloudCredential())
+	if err != nil {
+		return nil, err
+	}
+	b.updated(e.obj.Metadata, req.CreateMetadata)
+	info.CloudCredential, info.CloudCredentialRef = credentialRef.GetName(), credentialRef
+	info.Status = e.obj.BackupLocationInfo.GetStatus()
+	e.obj.BackupLocationInfo = info
+	return &api.BackupLocationUpdateResponse{}, nil
+}
+
+func (b *backupLocationService) Enumerate(ctx context.Context, req *api.BackupLocationEnumerateRequest) (*api.BackupLocationEnumerateResponse, error) {
+	var locations []*api.BackupLocationObject
+	for _, obj := range b.locations.visible(ctx, req.GetOrgId()) {
+		if matchLabels(obj.GetLabels(), req.GetLabels()) {
+			locations = append(locations, obj)
+		}
+	}
+	return &api.BackupLocationEnumerateResponse{BackupLocations: locations}, nil
+}
+
+func (b *backupLocationService) Inspect(ctx context.Context, req *api.BackupLocationInspectRequest) (*api.BackupLocationInspectResponse, error) {
+	e, err := b.locations.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.BackupLocationInspectResponse{BackupLocation: clone(e.obj)}, nil
+}
+
+func (b *backupLocationService) Delete(ctx context.Context, req *api.BackupLocationDeleteRequest) (*api.BackupLocationDeleteResponse, error) {
+	e, err := b.locations.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	for _, bkp := range b.backups.all() {
+		if bkp.obj.GetOrgId() != req.GetOrgId() || bkp.obj.GetBackupLocationRef().GetUid() != e.obj.GetUid() {
+			continue
+		}
+		if !req.GetDeleteBackups() {
+			return nil, status.Errorf(codes.FailedPrecondition, "backup location %s has backups", req.GetName())
+		}
+		b.deleteBackup(bkp)
+	}
+	b.locations.remove(req.GetOrgId(), req.GetName())
+	return &api.BackupLocationDeleteResponse{}, nil
+}
+
+func (b *backupLocationService) Validate(ctx context.Context, req *api.BackupLocationValidateRequest) (*api.BackupLocationValidateResponse, error) {
+	if _, err := b.locations.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), readAccess); err != nil {
+		return nil, err
+	}
+	return &api.BackupLocationValidateResponse{}, nil
+}
+
+func (b *backupLocationService) UpdateOwnership(ctx context.Context, req *api.BackupLocationOwnershipUpdateRequest) (*api.BackupLocationOwnershipUpdateResponse, error) {
+	if err := updateOwnership(ctx, b.locations, req.GetOrgId(), req.GetName(), req.GetUid(), req.GetOwnership()); err != nil {
+		return nil, err
+	}
+	return &api.BackupLocationOwnershipUpdateResponse{}, nil
+}
+
+type schedulePolicyService struct {
+	api.UnimplementedSchedulePolicyServer
+	*Server
+}
+
+func (p *schedulePolicyService) Create(ctx context.Context, req *api.SchedulePolicyCreateRequest) (*api.SchedulePolicyCreateResponse, error) {
+	m, err := p.newMetadata(ctx, req.CreateMetadata)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := p.policyInterval(req.GetSchedulePolicy()); err != nil {
+		return nil, err
+	}
+	if _, err := p.policies.add(&api.SchedulePolicyObject{Metadata: m, SchedulePolicyInfo: req.GetSchedulePolicy()}, p.config.Now()); err != nil {
+		return nil, err
+	}
+	return &api.SchedulePolicyCreateResponse{}, nil
+}
+
+func (p *schedulePolicyService) Update(ctx context.Context, req *api.SchedulePolicyUpdateRequest) (*api.SchedulePolicyUpdateResponse, error) {
+	e, err := p.policies.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := p.policyInterval(req.GetSchedulePolicy()); err != nil {
+		return nil, err
+	}
+	p.updated(e.obj.Metadata, req.CreateMetadata)
+	e.obj.SchedulePolicyInfo = req.GetSchedulePolicy()
+	return &api.SchedulePolicyUpdateResponse{}, nil
+}
+
+func (p *schedulePolicyService) Enumerate(ctx context.Context, req *api.SchedulePolicyEnumerateRequest) (*api.SchedulePolicyEnumerateResponse, error) {
+	var policies []*api.SchedulePolicyObject
+	for _, obj := range p.policies.visible(ctx, req.GetOrgId()) {
+		if matchLabels(obj.GetLabels(), req.GetLabels()) {
+			policies = append(policies, obj)
+		}
+	}
+	return &api.SchedulePolicyEnumerateResponse{SchedulePolicies: policies}, nil
+}
+
+func (p *schedulePolicyService) Inspect(ctx context.Context, req *api.SchedulePolicyInspectRequest) (*api.SchedulePolicyInspectResponse, error) {
+	e, err := p.policies.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.SchedulePolicyInspectResponse{SchedulePolicy: clone(e.obj)}, nil
+}
+
+func (p *schedulePolicyService) Delete(ctx context.Context, req *api.SchedulePolicyDeleteRequest) (*api.SchedulePolicyDeleteResponse, error) {
+	e, err := p.policies.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range p.schedules.all() {
+		if s.obj.GetOrgId() == req.GetOrgId() && s.obj.GetSchedulePolicyRef().GetUid() == e.obj.GetUid() {
+			return nil, status.Errorf(codes.FailedPrecondition, "schedule policy %s is used by backup schedule %s", req.GetName(), s.obj.GetName())
+		}
+	}
+	p.policies.remove(req.GetOrgId(), req.GetName())
+	return &api.SchedulePolicyDeleteResponse{}, nil
+}
+
+func (p *schedulePolicyService) UpdateOwnership(ctx context.Context, req *api.SchedulePolicyOwnershipUpdateRequest) (*api.SchedulePolicyOwnershipUpdateResponse, error) {
+	if err := updateOwnership(ctx, p.policies, req.GetOrgId(), req.GetName(), req.GetUid(), req.GetOwnership()); err != nil {
+		return nil, err
+	}
+	return &api.SchedulePolicyOwnershipUpdateResponse{}, nil
+}
+
+type rulesService struct {
+	api.UnimplementedRulesServer
+	*Server
+}
+
+func (r *rulesService) Create(ctx context.Context, req *api.RuleCreateRequest) (*api.RuleCreateResponse, error) {
+	m, err := r.newMetadata(ctx, req.CreateMetadata)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.rules.add(&api.RuleObject{Metadata: m, RulesInfo: req.GetRulesInfo()}, r.config.Now()); err != nil {
+		return nil, err
+	}
+	return &api.RuleCreateResponse{}, nil
+}
+
+func (r *rulesService) Update(ctx context.Context, req *api.RuleUpdateRequest) (*api.RuleUpdateResponse, error) {
+	e, err := r.rules.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess)
+	if err != nil {
+		return nil, err
+	}
+	r.updated(e.obj.Metadata, req.CreateMetadata)
+	e.obj.RulesInfo = req.GetRulesInfo()
+	return &api.RuleUpdateResponse{}, nil
+}
+
+func (r *rulesService) Enumerate(ctx context.Context, req *api.RuleEnumerateRequest) (*api.RuleEnumerateResponse, error) {
+	return &api.RuleEnumerateResponse{Rules: r.rules.visible(ctx, req.GetOrgId())}, nil
+}
+
+func (r *rulesService) Inspect(ctx context.Context, req *api.RuleInspectRequest) (*api.RuleInspectResponse, error) {
+	e, err := r.rules.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), readAccess)
+	if err != nil {
+		return nil, err
+	}
+	return &api.RuleInspectResponse{Rule: clone(e.obj)}, nil
+}
+
+func (r *rulesService) Delete(ctx context.Context, req *api.RuleDeleteRequest) (*api.RuleDeleteResponse, error) {
+	if _, err := r.rules.find(ctx, req.GetOrgId(), req.GetName(), req.GetUid(), writeAccess); err != nil {
+		return nil, err
+	}
+	r.rules.remove(req.GetOrgId(), req.GetName())
+	return &api.RuleDeleteResponse{}, nil
+}
+
+func (r *rulesService) UpdateOwnership(ctx context.Context, req *api.RuleOwnershipUpdateRequest) (*api.RuleOwnershipUpdateResponse, error) {
+	if err := updateOwnership(ctx, r.rules, req.GetOrgId(), req.GetName(), req.GetUid(), req.GetOwnership()); err != nil {
+		return nil, err
+	}
+	return &api.RuleOwnershipUpdateResponse{}, nil
+}
@@ -159,6 +159,11 @@ func (p *portworx) testAndSetEndpoint(endpoint string) error {
 	if pxEndpoint == " " || len(pxEndpoint) == 0 {
 		pxEndpoint = p.constructURL(endpoint)
 	}
+	return p.connect(pxEndpoint)
+}
+
+// connect sets up the PX-Backup clients on a gRPC connection to the endpoint
+func (p *portworx) connect(pxEndpoint string) error {
 	conn, err := grpc.Dial(pxEndpoint, grpc.WithInsecure())
 	if err != nil {
 		log.Errorf("unable to get grpc connection: %v", err)
@@ -188,6 +193,16 @@ func (p *portworx) testAndSetEndpoint(endpoint string) error {
 	return err
 }
 
+// NewWithEndpoint returns a backup driver connected to the PX-Backup gRPC endpoint, without the
+// scheduler, node and volume drivers set up by Init. It is meant for unit tests against a fake PX-Backup.
+func NewWithEndpoint(endpoint string) (backup.Driver, error) {
+	p := &portworx{}
+	if err := p.connect(endpoint); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
 func (p *portworx) GetServiceEndpoint(serviceName string, namespace string) (string, error) {
 	svc, err := core.Instance().GetService(serviceName, namespace)
 	if err == nil {
@@ -446,7 +461,7 @@ func (p *portworx) GetVolumeBackupIDs(
 		return volumeBackupIDs, err
 	}
 
-	backupUUID, err := p.GetBackupUID(ctx, orgID, backupName)
+	backupUUID, err := p.GetBackupUID(ctx, backupName, orgID)
 	if err != nil {
 		return volumeBackupIDs, err
 	}
@@ -517,7 +532,7 @@ func (p *portworx) WaitForBackupCompletion(
 	timeBeforeRetry time.Duration,
 ) error {
 
-	backupUID, err := p.GetBackupUID(ctx, orgID, backupName)
+	backupUID, err := p.GetBackupUID(ctx, backupName, orgID)
 	if err != nil {
 		return err
 	}
@@ -679,7 +694,7 @@ func (p *portworx) WaitForDeletePending(
 	timeout time.Duration,
 	timeBeforeRetry time.Duration,
 ) error {
-	backupUID, err := p.GetBackupUID(ctx, orgID, backupName)
+	backupUID, err := p.GetBackupUID(ctx, backupName, orgID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,187 @@
+package portworx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	orgID         = fakepxb.DefaultOrg
+	clusterName   = "source-cluster"
+	locationName  = "s3-location"
+	waitTimeout   = 10 * time.Second
+	waitRetryTime = 20 * time.Millisecond
+)
+
+// jwt returns an unsigned token of a user, which the fake server accepts
+func jwt(t *testing.T, sub, name string, groups ...string) string {
+	payload, err := json.Marshal(map[string]interface{}{"sub": sub, "preferred_username": name, "groups": groups})
+	require.NoError(t, err)
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".c2ln"
+}
+
+// newDriver starts a fake PX-Backup server with a cluster and a backup location of the admin and
+// returns a driver connected to it
+func newDriver(t *testing.T, config fakepxb.Config) (*portworx, *fakepxb.Server, context.Context) {
+	server, err := fakepxb.New(config)
+	require.NoError(t, err)
+	t.Cleanup(server.Stop)
+	t.Setenv(backup_api_endpoint, server.Endpoint())
+	p := &portworx{}
+	require.NoError(t, p.testAndSetEndpoint(""))
+
+	ctx := backup.GetCtxWithToken(fakepxb.DefaultAdminUser)
+	_, err = p.CreateCloudCredential(ctx, &api.CloudCredentialCreateRequest{
+		CreateMetadata:  &api.CreateMetadata{Name: "aws-cred", OrgId: orgID},
+		CloudCredential: &api.CloudCredentialInfo{Type: api.CloudCredentialInfo_AWS},
+	})
+	require.NoError(t, err)
+	_, err = p.CreateCluster(ctx, &api.ClusterCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: clusterName, OrgId: orgID},
+		Kubeconfig:     "kubeconfig",
+	})
+	require.NoError(t, err)
+	_, err = p.CreateBackupLocation(ctx, &api.BackupLocationCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: locationName, OrgId: orgID},
+		BackupLocation: &api.BackupLocationInfo{
+			Type:               api.BackupLocationInfo_S3,
+			Path:               "bucket",
+			CloudCredentialRef: &api.ObjectRef{Name: "aws-cred"},
+		},
+	})
+	require.NoError(t, err)
+	return p, server, ctx
+}
+
+func createBackup(ctx context.Context, p *portworx, name string) error {
+	_, err := p.CreateBackup(ctx, &api.BackupCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: name, OrgId: orgID},
+		BackupLocation: locationName,
+		Cluster:        clusterName,
+		Namespaces:     []string{"mysql"},
+	})
+	return err
+}
+
+func TestWaitForBackupCompletion(t *testing.T) {
+	p, server, ctx := newDriver(t, fakepxb.Config{DeleteDelay: 100 * time.Millisecond})
+
+	require.NoError(t, createBackup(ctx, p, "backup-ok"))
+	server.InjectFault("/Backup/Inspect", status.Error(codes.Unavailable, "px-backup restarting"), 2)
+	assert.NoError(t, p.WaitForBackupCompletion(ctx, "backup-ok", orgID, waitTimeout, waitRetryTime))
+
+	server.SetBackupTimeline("backup-failed", []fakepxb.BackupPhase{
+		{Status: api.BackupInfo_StatusInfo_InProgress},
+		{After: 50 * time.Millisecond, Status: api.BackupInfo_StatusInfo_Failed, Reason: "volume snapshot failed"},
+	})
+	require.NoError(t, createBackup(ctx, p, "backup-failed"))
+	err := p.WaitForBackupCompletion(ctx, "backup-failed", orgID, waitTimeout, waitRetryTime)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "volume snapshot failed")
+
+	uid, err := p.GetBackupUID(ctx, "backup-ok", orgID)
+	require.NoError(t, err)
+	_, err = p.DeleteBackup(ctx, &api.BackupDeleteRequest{Name: "backup-ok", OrgId: orgID, Uid: uid})
+	require.NoError(t, err)
+	assert.NoError(t, p.WaitForBackupDeletion(ctx, "backup-ok", orgID, waitTimeout, waitRetryTime))
+
+	server.InjectFault("/Backup/Enumerate", status.Error(codes.Internal, "database unavailable"), -1)
+	_, err = p.GetBackupUID(ctx, "backup-failed", orgID)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestBackupScheduleWaitForNBackupsCompletion(t *testing.T) {
+	p, _, ctx := newDriver(t, fakepxb.Config{ScheduleInterval: 300 * time.Millisecond})
+
+	_, err := p.CreateSchedulePolicy(ctx, &api.SchedulePolicyCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: "every-minute", OrgId: orgID},
+		SchedulePolicy: &api.SchedulePolicyInfo{Interval: &api.SchedulePolicyInfo_IntervalPolicy{Minutes: 1, Retain: 3}},
+	})
+	require.NoError(t, err)
+	_, err = p.CreateBackupSchedule(ctx, &api.BackupScheduleCreateRequest{
+		CreateMetadata:    &api.CreateMetadata{Name: "mysql-schedule", OrgId: orgID},
+		SchedulePolicyRef: &api.ObjectRef{Name: "every-minute"},
+		BackupLocation:    locationName,
+		Cluster:           clusterName,
+		Namespaces:        []string{"mysql"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, p.BackupScheduleWaitForNBackupsCompletion(ctx, "mysql-schedule", orgID, 3, waitTimeout, waitRetryTime))
+	names, err := p.GetAllScheduleBackupNames(ctx, "mysql-schedule", orgID)
+	require.NoError(t, err)
+	assert.Len(t, names, 3, "schedule policy retains 3 backups")
+	uids, err := p.GetAllScheduleBackupUIDs(ctx, "mysql-schedule", orgID)
+	require.NoError(t, err)
+	assert.Len(t, uids, 3)
+}
+
+func TestBackupShare(t *testing.T) {
+	p, _, ctx := newDriver(t, fakepxb.Config{})
+	require.NoError(t, createBackup(ctx, p, "shared-backup"))
+	require.NoError(t, p.WaitForBackupCompletion(ctx, "shared-backup", orgID, waitTimeout, waitRetryTime))
+	uid, err := p.GetBackupUID(ctx, "shared-backup", orgID)
+	require.NoError(t, err)
+
+	alice := backup.GetCtxWithToken(jwt(t, "alice-id", "alice"))
+	bob := backup.GetCtxWithToken(jwt(t, "bob-id", "bob", "dba"))
+	restore := func(ctx context.Context, name string) error {
+		_, err := p.CreateRestore(ctx, &api.RestoreCreateRequest{
+			CreateMetadata: &api.CreateMetadata{Name: name, OrgId: orgID},
+			Backup:         "shared-backup",
+			Cluster:        "destination-cluster",
+		})
+		return err
+	}
+	_, err = p.CreateCluster(bob, &api.ClusterCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: "destination-cluster", OrgId: orgID},
+		Kubeconfig:     "kubeconfig",
+	})
+	require.NoError(t, err)
+
+	_, err = p.GetBackupUID(alice, "shared-backup", orgID)
+	assert.Error(t, err, "backups are not visible before they are shared")
+
+	_, err = p.UpdateBackupShare(ctx, &api.BackupShareUpdateRequest{
+		OrgId:       orgID,
+		Name:        "shared-backup",
+		Uid:         uid,
+		Backupshare: &api.BackupShare{Collaborators: []*api.BackupShare_AccessConfig{{Id: "alice-id", Access: api.BackupShare_View}}},
+	})
+	require.NoError(t, err)
+	resp, err := p.InspectBackup(alice, &api.BackupInspectRequest{Name: "shared-backup", OrgId: orgID, Uid: uid})
+	require.NoError(t, err)
+	assert.Equal(t, api.BackupShare_View, resp.GetBackup().GetUserBackupshareAccess())
+	assert.Equal(t, codes.PermissionDenied, status.Code(restore(alice, "alice-restore")))
+
+	_, err = p.ClusterUpdateBackupShare(ctx, &api.ClusterBackupShareUpdateRequest{
+		OrgId:          orgID,
+		Name:           clusterName,
+		AddBackupShare: &api.BackupShare{Groups: []*api.BackupShare_AccessConfig{{Id: "dba", Access: api.BackupShare_Restorable}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, restore(bob, "bob-restore"))
+	assert.NoError(t, p.WaitForRestoreCompletion(bob, "bob-restore", orgID, waitTimeout, waitRetryTime))
+	_, err = p.DeleteBackup(bob, &api.BackupDeleteRequest{Name: "shared-backup", OrgId: orgID, Uid: uid})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "restorable shares do not allow deletion")
+}
+
+func TestWaitForLicenseActivation(t *testing.T) {
+	p, _, ctx := newDriver(t, fakepxb.Config{})
+	req := &api.LicenseInspectRequest{OrgId: orgID}
+	assert.Error(t, p.WaitForLicenseActivation(ctx, req, waitTimeout, waitRetryTime))
+	_, err := p.ActivateLicense(ctx, &api.LicenseActivateRequest{CreateMetadata: &api.CreateMetadata{Name: "license", OrgId: orgID}})
+	require.NoError(t, err)
+	assert.NoError(t, p.WaitForLicenseActivation(ctx, req, waitTimeout, waitRetryTime))
+}
@@ -0,0 +1,413 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/portworx/sched-ops/task"
+	"github.com/portworx/torpedo/drivers/node"
+	"github.com/portworx/torpedo/pkg/log"
+)
+
+const (
+	// DriverName is the name of the container node driver
+	DriverName = "container"
+	// RuntimeEnvVar is the env var selecting what backs the nodes: docker (default), podman or netns
+	RuntimeEnvVar = "CONTAINER_NODE_RUNTIME"
+	// InterfaceEnvVar is the env var for the network interface used for network error injection
+	InterfaceEnvVar = "CONTAINER_NODE_INTERFACE"
+
+	runtimeDocker      = "docker"
+	runtimePodman      = "podman"
+	runtimeNetns       = "netns"
+	defaultInterface   = "eth0"
+	defaultStopTimeout = 10 * time.Second
+	poolLabelMarker    = "any:pwx"
+)
+
+// container node driver for nodes which are local containers (e.g. kind nodes) or network namespaces
+type container struct {
+	node.Driver
+	runtime runtime
+	hostRun executor
+	iface   string
+	specDir string
+}
+
+func (c *container) String() string {
+	return DriverName
+}
+
+// Init initializes the container node driver
+func (c *container) Init(nodeOpts node.InitOptions) error {
+	c.specDir = nodeOpts.SpecDir
+	c.hostRun = hostExec
+
+	c.iface = defaultInterface
+	if iface := os.Getenv(InterfaceEnvVar); iface != "" {
+		c.iface = iface
+	}
+
+	rt := os.Getenv(RuntimeEnvVar)
+	if rt == "" {
+		rt = runtimeDocker
+	}
+	switch rt {
+	case runtimeDocker, runtimePodman:
+		c.runtime = &cliRuntime{cli: rt, run: hostExec}
+	case runtimeNetns:
+		c.runtime = &netnsRuntime{run: hostExec}
+	default:
+		return fmt.Errorf("invalid value %q for %s, expected one of %s, %s or %s",
+			rt, RuntimeEnvVar, runtimeDocker, runtimePodman, runtimeNetns)
+	}
+	log.Infof("Using the container node driver with runtime: %s", rt)
+	return nil
+}
+
+// IsUsingSSH returns false as commands are run through the container runtime
+func (c *container) IsUsingSSH() bool {
+	return false
+}
+
+// TestConnection tests that commands can be run in the given node
+func (c *container) TestConnection(n node.Node, options node.ConnectionOpts) error {
+	if _, err := c.runtime.exec(n.Name, "hostname"); err != nil {
+		return &node.ErrFailedToTestConnection{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// RebootNode restarts the container of the given node
+func (c *container) RebootNode(n node.Node, options node.RebootNodeOpts) error {
+	log.Infof("Rebooting node %s", n.Name)
+	var err error
+	if options.Force {
+		if err = c.runtime.kill(n.Name); err == nil {
+			err = c.runtime.start(n.Name)
+		}
+	} else {
+		err = c.runtime.restart(n.Name, defaultStopTimeout)
+	}
+	if err != nil {
+		return &node.ErrFailedToRebootNode{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// CrashNode kills the container of the given node and starts it again, like a node rebooting after a panic
+func (c *container) CrashNode(n node.Node, options node.CrashNodeOpts) error {
+	log.Infof("Crashing node %s", n.Name)
+	err := c.runtime.kill(n.Name)
+	if err == nil {
+		err = c.runtime.start(n.Name)
+	}
+	if err != nil {
+		return &node.ErrFailedToCrashNode{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// ShutdownNode stops the container of the given node
+func (c *container) ShutdownNode(n node.Node, options node.ShutdownNodeOpts) error {
+	log.Infof("Shutting down node %s", n.Name)
+	var err error
+	if options.Force {
+		err = c.runtime.kill(n.Name)
+	} else {
+		err = c.runtime.stop(n.Name, defaultStopTimeout)
+	}
+	if err != nil {
+		return &node.ErrFailedToShutdownNode{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// PowerOffVM stops the container of the given node
+func (c *container) PowerOffVM(n node.Node) error {
+	return c.ShutdownNode(n, node.ShutdownNodeOpts{})
+}
+
+// PowerOnVM starts the container of the given node
+func (c *container) PowerOnVM(n node.Node) error {
+	return c.PowerOnVMByName(n.Name)
+}
+
+// PowerOnVMByName starts the container with the given name
+func (c *container) PowerOnVMByName(vmName string) error {
+	log.Infof("Starting node %s", vmName)
+	return c.runtime.start(vmName)
+}
+
+// DeleteNode removes the container of the given node
+func (c *container) DeleteNode(n node.Node, timeout time.Duration) error {
+	if err := c.runtime.remove(n.Name); err != nil {
+		return &node.ErrFailedToDeleteNode{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// IsNodeRebootedInGivenTimeRange returns true if the container of the node was started within the time range
+func (c *container) IsNodeRebootedInGivenTimeRange(n node.Node, timerange time.Duration) (bool, error) {
+	startedAt, err := c.runtime.startedAt(n.Name)
+	if err != nil {
+		return false, &node.ErrFailedToRunCommand{
+			Node:  n,
+			Cause: fmt.Sprintf("failed to get start time of node %v: %v", n.Name, err),
+		}
+	}
+	return time.Since(startedAt) <= timerange, nil
+}
+
+// RunCommand runs the given command in the node, retrying till the timeout
+func (c *container) RunCommand(n node.Node, command string, options node.ConnectionOpts) (string, error) {
+	t := func() (interface{}, bool, error) {
+		output, err := c.runtime.exec(n.Name, command)
+		if err != nil && !options.IgnoreError {
+			return "", true, &node.ErrFailedToRunCommand{
+				Addr:  n.Name,
+				Cause: fmt.Sprintf("unable to run cmd (%v): %v", command, err),
+			}
+		}
+		return output, false, nil
+	}
+
+	output, err := task.DoRetryWithTimeout(t, options.Timeout, options.TimeBeforeRetry)
+	if err != nil {
+		return "", err
+	}
+	return output.(string), nil
+}
+
+// RunCommandWithNoRetry runs the given command in the node once
+func (c *container) RunCommandWithNoRetry(n node.Node, command string, options node.ConnectionOpts) (string, error) {
+	output, err := c.runtime.exec(n.Name, command)
+	if err != nil && !options.IgnoreError {
+		return "", &node.ErrFailedToRunCommand{
+			Addr:  n.Name,
+			Cause: fmt.Sprintf("unable to run cmd (%v): %v", command, err),
+		}
+	}
+	return output, nil
+}
+
+// FindFiles finds files from the given path in the node
+func (c *container) FindFiles(path string, n node.Node, options node.FindOpts) (string, error) {
+	findCmd := "find " + path
+	if options.Name != "" {
+		findCmd += " -name " + options.Name
+	}
+	if options.MinDepth > 0 {
+		findCmd += " -mindepth " + strconv.Itoa(options.MinDepth)
+	}
+	if options.MaxDepth > 0 {
+		findCmd += " -maxdepth " + strconv.Itoa(options.MaxDepth)
+	}
+	if options.Type != "" {
+		findCmd += " -type " + string(options.Type)
+	}
+	if options.Empty {
+		findCmd += " -empty"
+	}
+
+	options.ConnectionOpts.IgnoreError = true
+	out, err := c.RunCommand(n, findCmd, options.ConnectionOpts)
+	if err != nil {
+		return "", &node.ErrFailedToFindFileOnNode{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return out, nil
+}
+
+// SystemCheck checks if any cores were generated in the node
+func (c *container) SystemCheck(n node.Node, options node.ConnectionOpts) (string, error) {
+	file, err := c.FindFiles("/var/cores/", n, node.FindOpts{
+		ConnectionOpts: options,
+		Name:           "core-px*",
+		Type:           node.File,
+	})
+	if err != nil {
+		return "", &node.ErrFailedToSystemCheck{
+			Node:  n,
+			Cause: fmt.Sprintf("failed to check for core files due to: %v", err),
+		}
+	}
+	return file, nil
+}
+
+// Systemctl runs systemctl for the given service inside the node container
+func (c *container) Systemctl(n node.Node, service string, options node.SystemctlOpts) error {
+	if _, ok := c.runtime.(*netnsRuntime); ok {
+		return notSupported("Systemctl")
+	}
+	systemctlCmd := fmt.Sprintf("systemctl %v %v", options.Action, service)
+	if _, err := c.RunCommand(n, systemctlCmd, options.ConnectionOpts); err != nil {
+		return &node.ErrFailedToRunSystemctlOnNode{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// SystemctlUnitExist checks if the given service exists inside the node container
+func (c *container) SystemctlUnitExist(n node.Node, service string, options node.SystemctlOpts) (bool, error) {
+	if _, ok := c.runtime.(*netnsRuntime); ok {
+		return false, notSupported("SystemctlUnitExist")
+	}
+	systemctlCmd := fmt.Sprintf("systemctl list-units --full --all | grep \"%s.service\" || true", service)
+	out, err := c.RunCommand(n, systemctlCmd, options.ConnectionOpts)
+	if err != nil {
+		return false, &node.ErrFailedToRunSystemctlOnNode{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return len(out) > 0, nil
+}
+
+// YankDrive detaches the loop device backing the given drive. It returns the backing file which
+// RecoverDrive needs to attach the device again.
+func (c *container) YankDrive(n node.Node, driveNameToFail string, options node.ConnectionOpts) (string, error) {
+	if !strings.HasPrefix(path.Base(driveNameToFail), "loop") {
+		return "", &node.ErrFailedToYankDrive{
+			Node:  n,
+			Cause: fmt.Sprintf("drive %v is not a loop device", driveNameToFail),
+		}
+	}
+	// loop devices are shared with the host, so they are managed from there
+	out, err := c.hostRun("losetup", "--noheadings", "--output", "BACK-FILE", driveNameToFail)
+	if err != nil {
+		return "", &node.ErrFailedToYankDrive{
+			Node:  n,
+			Cause: fmt.Sprintf("unable to find backing file of the drive %v due to: %v", driveNameToFail, err),
+		}
+	}
+	backingFile := strings.TrimSpace(out)
+	if _, err = c.hostRun("losetup", "--detach", driveNameToFail); err != nil {
+		return "", &node.ErrFailedToYankDrive{
+			Node:  n,
+			Cause: fmt.Sprintf("failed to yank drive %v due to: %v", driveNameToFail, err),
+		}
+	}
+	log.Infof("Yanked drive %s backed by %s on node %s", driveNameToFail, backingFile, n.Name)
+	return backingFile, nil
+}
+
+// RecoverDrive attaches the backing file returned by YankDrive to the loop device again
+func (c *container) RecoverDrive(n node.Node, driveNameToRecover string, driveUUIDToRecover string, options node.ConnectionOpts) error {
+	if _, err := c.hostRun("losetup", driveNameToRecover, driveUUIDToRecover); err != nil {
+		return &node.ErrFailedToRecoverDrive{
+			Node:  n,
+			Cause: fmt.Sprintf("unable to attach %v to drive %v: %v", driveUUIDToRecover, driveNameToRecover, err),
+		}
+	}
+	return nil
+}
+
+// InjectNetworkError drops or delays packets on the network interface of the given nodes using tc
+func (c *container) InjectNetworkError(nodes []node.Node, errorInjectionType string, operationType string, dropPercentage int, delayInMilliseconds int) error {
+	var netem string
+	switch errorInjectionType {
+	case "delay":
+		delay := strconv.Itoa(delayInMilliseconds) + "ms"
+		netem = fmt.Sprintf("delay %s %s", delay, delay)
+	case "drop":
+		netem = fmt.Sprintf("loss %d%%", dropPercentage)
+	default:
+		return fmt.Errorf("Invalid network error injection type %v", errorInjectionType)
+	}
+	cmd := fmt.Sprintf("tc qdisc %s dev %s root netem %s", operationType, c.iface, netem)
+
+	for _, n := range nodes {
+		log.Infof("Error injection on Node name : %s of type : %s ", n.Name, errorInjectionType)
+		if _, err := c.runtime.exec(n.Name, cmd); err != nil {
+			return &node.ErrFailedToSetNetworkErrorOnNode{
+				Node:  n,
+				Cause: err.Error(),
+			}
+		}
+	}
+	return nil
+}
+
+var lsblkPairRegex = regexp.MustCompile(`([A-Z]+)="([^"]*)"`)
+
+// GetBlockDrives returns the block drives visible in the node
+func (c *container) GetBlockDrives(n node.Node, options node.SystemctlOpts) (map[string]*node.BlockDrive, error) {
+	out, err := c.RunCommand(n, "lsblk -P -p -o NAME,LABEL,SIZE,MOUNTPOINT,FSTYPE,TYPE", options.ConnectionOpts)
+	if err != nil {
+		return nil, &node.ErrFailedToRunCommand{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	return parseBlockDrives(out), nil
+}
+
+// parseBlockDrives parses the pairs output of lsblk
+func parseBlockDrives(out string) map[string]*node.BlockDrive {
+	drives := make(map[string]*node.BlockDrive)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" || strings.Contains(line, "mapper") {
+			continue
+		}
+		drive := &node.BlockDrive{Labels: make(map[string]string)}
+		for _, match := range lsblkPairRegex.FindAllStringSubmatch(line, -1) {
+			key, val := match[1], match[2]
+			switch key {
+			case "NAME":
+				drive.Path = val
+			case "LABEL":
+				for _, label := range strings.Split(val, ",") {
+					if k, v, ok := strings.Cut(label, "="); ok {
+						drive.Labels[k] = v
+					} else if pos := strings.LastIndex(label, poolLabelMarker); pos != -1 {
+						// pool drives are labelled with the pool ID after the marker
+						drive.Labels["pxpool"] = label[pos+len(poolLabelMarker):]
+					} else if label != "" {
+						drive.Labels[label] = "true"
+					}
+				}
+			case "SIZE":
+				drive.Size = val
+			case "MOUNTPOINT":
+				drive.MountPoint = val
+			case "FSTYPE":
+				drive.FSType = val
+			case "TYPE":
+				drive.Type = val
+			}
+		}
+		drives[drive.Path] = drive
+	}
+	return drives
+}
+
+func init() {
+	node.Register(DriverName, &container{
+		Driver: node.NotSupportedDriver,
+	})
+}
@@ -0,0 +1,71 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/portworx/torpedo/drivers/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is an executor which records the commands it is asked to run
+type recorder struct {
+	cmds    []string
+	outputs map[string]string
+}
+
+func (r *recorder) run(name string, args ...string) (string, error) {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	r.cmds = append(r.cmds, cmd)
+	return r.outputs[cmd], nil
+}
+
+func TestLifecycleMapping(t *testing.T) {
+	r := &recorder{outputs: map[string]string{
+		"losetup --noheadings --output BACK-FILE /dev/loop3": "/var/lib/nodes/disk3.img\n",
+	}}
+	c := &container{
+		Driver:  node.NotSupportedDriver,
+		runtime: &cliRuntime{cli: runtimePodman, run: r.run},
+		hostRun: r.run,
+		iface:   defaultInterface,
+	}
+	n := node.Node{Name: "kind-worker"}
+
+	require.NoError(t, c.RebootNode(n, node.RebootNodeOpts{}))
+	require.NoError(t, c.CrashNode(n, node.CrashNodeOpts{}))
+	require.NoError(t, c.InjectNetworkError([]node.Node{n}, "drop", "add", 30, 0))
+	backingFile, err := c.YankDrive(n, "/dev/loop3", node.ConnectionOpts{})
+	require.NoError(t, err)
+	require.NoError(t, c.RecoverDrive(n, "/dev/loop3", backingFile, node.ConnectionOpts{}))
+
+	assert.Equal(t, []string{
+		"podman restart -t 10 kind-worker",
+		"podman kill kind-worker",
+		"podman start kind-worker",
+		"podman exec kind-worker sh -c tc qdisc add dev eth0 root netem loss 30%",
+		"losetup --noheadings --output BACK-FILE /dev/loop3",
+		"losetup --detach /dev/loop3",
+		"losetup /dev/loop3 /var/lib/nodes/disk3.img",
+	}, r.cmds)
+
+	_, err = c.YankDrive(n, "/dev/sda", node.ConnectionOpts{})
+	assert.IsType(t, &node.ErrFailedToYankDrive{}, err)
+
+	c.runtime = &netnsRuntime{run: r.run}
+	assert.Error(t, c.RebootNode(n, node.RebootNodeOpts{}))
+	assert.Error(t, c.Systemctl(n, "portworx", node.SystemctlOpts{}))
+}
+
+func TestParseBlockDrives(t *testing.T) {
+	out := `NAME="/dev/loop3" LABEL="" SIZE="10G" MOUNTPOINT="" FSTYPE="" TYPE="loop"
+NAME="/dev/loop4" LABEL="mpath-any:pwx1" SIZE="20G" MOUNTPOINT="" FSTYPE="xfs" TYPE="loop"
+NAME="/dev/mapper/pxd" LABEL="" SIZE="20G" MOUNTPOINT="" FSTYPE="" TYPE="dm"`
+
+	drives := parseBlockDrives(out)
+	require.Len(t, drives, 2)
+	assert.Equal(t, "10G", drives["/dev/loop3"].Size)
+	assert.Equal(t, "1", drives["/dev/loop4"].Labels["pxpool"])
+	assert.Equal(t, "xfs", drives["/dev/loop4"].FSType)
+}
@@ -0,0 +1,124 @@
+package container
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/portworx/torpedo/pkg/errors"
+)
+
+// executor runs a command on the host and returns its combined output
+type executor func(name string, args ...string) (string, error)
+
+func hostExec(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("%s %s failed: %v, output: %s", name, strings.Join(args, " "), err, out)
+	}
+	return string(out), nil
+}
+
+// runtime maps node lifecycle operations onto whatever backs a node on the local host
+type runtime interface {
+	// exec runs a shell command inside the node
+	exec(name, cmd string) (string, error)
+	// restart stops and starts the node, waiting up to timeout for a clean stop
+	restart(name string, timeout time.Duration) error
+	// kill stops the node without giving it a chance to shut down cleanly
+	kill(name string) error
+	// stop shuts the node down cleanly
+	stop(name string, timeout time.Duration) error
+	// start starts a stopped node
+	start(name string) error
+	// remove deletes the node
+	remove(name string) error
+	// startedAt returns when the node was last started
+	startedAt(name string) (time.Time, error)
+}
+
+// cliRuntime manages nodes which are containers of a docker compatible CLI such as docker or podman
+type cliRuntime struct {
+	cli string
+	run executor
+}
+
+func (r *cliRuntime) exec(name, cmd string) (string, error) {
+	return r.run(r.cli, "exec", name, "sh", "-c", cmd)
+}
+
+func (r *cliRuntime) restart(name string, timeout time.Duration) error {
+	_, err := r.run(r.cli, "restart", "-t", fmt.Sprint(int(timeout.Seconds())), name)
+	return err
+}
+
+func (r *cliRuntime) kill(name string) error {
+	_, err := r.run(r.cli, "kill", name)
+	return err
+}
+
+func (r *cliRuntime) stop(name string, timeout time.Duration) error {
+	_, err := r.run(r.cli, "stop", "-t", fmt.Sprint(int(timeout.Seconds())), name)
+	return err
+}
+
+func (r *cliRuntime) start(name string) error {
+	_, err := r.run(r.cli, "start", name)
+	return err
+}
+
+func (r *cliRuntime) remove(name string) error {
+	_, err := r.run(r.cli, "rm", "-f", name)
+	return err
+}
+
+func (r *cliRuntime) startedAt(name string) (time.Time, error) {
+	out, err := r.run(r.cli, "inspect", "-f", "{{.State.StartedAt}}", name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(out))
+}
+
+// netnsRuntime treats named network namespaces as nodes. Commands run inside the namespace but
+// share everything else with the host, so lifecycle operations are not supported.
+type netnsRuntime struct {
+	run executor
+}
+
+func (r *netnsRuntime) exec(name, cmd string) (string, error) {
+	return r.run("ip", "netns", "exec", name, "sh", "-c", cmd)
+}
+
+func (r *netnsRuntime) restart(name string, timeout time.Duration) error {
+	return notSupported("restart")
+}
+
+func (r *netnsRuntime) kill(name string) error {
+	return notSupported("kill")
+}
+
+func (r *netnsRuntime) stop(name string, timeout time.Duration) error {
+	return notSupported("stop")
+}
+
+func (r *netnsRuntime) start(name string) error {
+	return notSupported("start")
+}
+
+func (r *netnsRuntime) remove(name string) error {
+	_, err := r.run("ip", "netns", "delete", name)
+	return err
+}
+
+func (r *netnsRuntime) startedAt(name string) (time.Time, error) {
+	return time.Time{}, notSupported("startedAt")
+}
+
+func notSupported(operation string) error {
+	return &errors.ErrNotSupported{
+		Type:      "NetworkNamespace",
+		Operation: operation,
+	}
+}
@@ -0,0 +1,118 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/azureblob"
+)
+
+// azureDriver is the driver of Azure Blob Storage backup locations. Object locks are not
+// supported, like in stork.
+type azureDriver struct {
+	DefaultDriver
+}
+
+func (d *azureDriver) String() string {
+	return string(stork_api.BackupLocationAzure)
+}
+
+// envAzureBlobEndpoint is the env variable of the blob service endpoint of the storage accounts of
+// Azure backup locations, such as http://127.0.0.1:10000/devstoreaccount1 for Azurite or the
+// endpoint of a sovereign cloud. The endpoint of the account in the public cloud is used if it is
+// empty, like stork does.
+const envAzureBlobEndpoint = "AZURE_BLOB_ENDPOINT"
+
+// azureDefaultDomain is the blob service domain of the public cloud, which gocloud and stork target
+const azureDefaultDomain = "blob.core.windows.net"
+
+// ListBuckets lists the containers of the storage account of the backup location
+func (d *azureDriver) ListBuckets(backupLocation *stork_api.BackupLocation) ([]string, error) {
+	p, err := azurePipeline(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	config := backupLocation.Location.AzureConfig
+	u, err := url.Parse(fmt.Sprintf("https://%s.%s", config.StorageAccountName, azureDefaultDomain))
+	if err != nil {
+		return nil, err
+	}
+	service := azblob.NewServiceURL(*u, p)
+
+	containers := make([]string, 0)
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		resp, err := service.ListContainersSegment(context.Background(), marker, azblob.ListContainersSegmentOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers of account %s: %v", config.StorageAccountName, err)
+		}
+		for _, c := range resp.ContainerItems {
+			containers = append(containers, c.Name)
+		}
+		marker = resp.NextMarker
+	}
+	return containers, nil
+}
+
+// azureBucket opens the container of the backup location
+func azureBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+	p, err := azurePipeline(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	accountName := azureblob.AccountName(backupLocation.Location.AzureConfig.StorageAccountName)
+	return azureblob.OpenBucket(context.Background(), p, accountName, backupLocation.Location.Path, nil)
+}
+
+// azurePipeline returns the pipeline of the requests to the storage account of the backup location.
+// Requests are sent to the endpoint of envAzureBlobEndpoint if it is set.
+func azurePipeline(backupLocation *stork_api.BackupLocation) (pipeline.Pipeline, error) {
+	if backupLocation.Location.AzureConfig == nil {
+		return nil, fmt.Errorf("backup location %s has no Azure config", backupLocation.Name)
+	}
+	config := backupLocation.Location.AzureConfig
+	credential, err := azureblob.NewCredential(
+		azureblob.AccountName(config.StorageAccountName),
+		azureblob.AccountKey(config.StorageAccountKey))
+	if err != nil {
+		return nil, err
+	}
+	var options azblob.PipelineOptions
+	if endpoint := os.Getenv(envAzureBlobEndpoint); endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %v", envAzureBlobEndpoint, endpoint, err)
+		}
+		options.HTTPSender = azureEndpointSender(u)
+	}
+	return azureblob.NewPipeline(credential, options), nil
+}
+
+// azureEndpointSender sends the requests to the blob service of the public cloud, the only one
+// gocloud opens containers of, to the endpoint instead. The path of the endpoint prefixes the paths
+// of the requests, for path style endpoints like the ones of Azurite.
+func azureEndpointSender(endpoint *url.URL) pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			prefix := strings.TrimSuffix(endpoint.Path, "/")
+			request.URL.Scheme, request.URL.Host, request.Host = endpoint.Scheme, endpoint.Host, endpoint.Host
+			request.URL.Path = prefix + request.URL.Path
+			if request.URL.RawPath != "" {
+				request.URL.RawPath = prefix + request.URL.RawPath
+			}
+			resp, err := http.DefaultClient.Do(request.WithContext(ctx))
+			return pipeline.NewHTTPResponse(resp), err
+		}
+	})
+}
+
+func init() {
+	Register(string(stork_api.BackupLocationAzure), &azureDriver{DefaultDriver{openBucket: azureBucket}})
+}
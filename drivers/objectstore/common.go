@@ -2,17 +2,27 @@ package objectstore
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"strings"
 
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	stork_objectstore "github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/libopenstorage/stork/pkg/objectstore/common"
 	"github.com/portworx/sched-ops/task"
+	"github.com/portworx/torpedo/pkg/errors"
 	"gocloud.dev/blob"
 )
 
-// DefaultDriver implements defaults for Driver interface
+// DefaultDriver implements defaults for Driver interface. Objects are accessed through the
+// portable bucket of the backup location, provider specific attributes through lockOf.
 type DefaultDriver struct {
+	// lockOf returns the lock of an object from its attributes
+	lockOf func(attrs *blob.Attributes) (ObjectLock, error)
+	// openBucket opens the bucket of the backup location, the way stork opens it if nil
+	openBucket func(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error)
 }
 
 func (d *DefaultDriver) String() string {
@@ -22,10 +32,11 @@ func (d *DefaultDriver) String() string {
 // ValidateBackupsDeletedFromCloud checks it given backups are deleted from the cloud
 func (d *DefaultDriver) ValidateBackupsDeletedFromCloud(backupLocation *stork_api.BackupLocation, backupPath string) error {
 	t := func() (interface{}, bool, error) {
-		bucket, err := stork_objectstore.GetBucket(backupLocation)
+		bucket, err := d.bucket(backupLocation)
 		if err != nil {
 			return "", false, fmt.Errorf("failed to get bucket %s in namespace: %s.Bucket: %v is present", backupLocation.Location.Path, backupLocation.Namespace, bucket)
 		}
+		defer bucket.Close()
 		iterator := bucket.List(&blob.ListOptions{
 			Prefix:    backupPath,
 			Delimiter: "/",
@@ -54,3 +65,196 @@ func (d *DefaultDriver) ValidateBackupsDeletedFromCloud(backupLocation *stork_ap
 	}
 	return nil
 }
+
+// ListBuckets is not supported by default
+func (d *DefaultDriver) ListBuckets(backupLocation *stork_api.BackupLocation) ([]string, error) {
+	return nil, &errors.ErrNotSupported{
+		Type:      "Function",
+		Operation: "ListBuckets()",
+	}
+}
+
+// CheckConnection lists the first object of the bucket of the backup location
+func (d *DefaultDriver) CheckConnection(backupLocation *stork_api.BackupLocation) error {
+	bucket, err := d.bucket(backupLocation)
+	if err != nil {
+		return err
+	}
+	defer bucket.Close()
+	_, err = bucket.List(&blob.ListOptions{}).Next(context.TODO())
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to list bucket %s: %v", backupLocation.Location.Path, err)
+	}
+	return nil
+}
+
+// ListFilesInBucket lists the keys of the objects under the prefix in the bucket of the backup location
+func (d *DefaultDriver) ListFilesInBucket(backupLocation *stork_api.BackupLocation, prefix string) ([]string, error) {
+	objects, err := d.ListObjects(backupLocation, prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(objects))
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+	}
+	return keys, nil
+}
+
+// ListObjects lists the objects under the prefix in the bucket of the backup location, in all
+// sub directories
+func (d *DefaultDriver) ListObjects(backupLocation *stork_api.BackupLocation, prefix string) ([]*ObjectInfo, error) {
+	bucket, err := d.bucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	defer bucket.Close()
+
+	objects := make([]*ObjectInfo, 0)
+	iterator := bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		object, err := iterator.Next(context.TODO())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects under %s in bucket %s: %v", prefix, backupLocation.Location.Path, err)
+		}
+		objects = append(objects, &ObjectInfo{
+			Key:     object.Key,
+			Size:    object.Size,
+			ModTime: object.ModTime,
+			MD5:     object.MD5,
+		})
+	}
+	return objects, nil
+}
+
+// StatObject returns the object with the given key, with its lock if the driver supports locks
+func (d *DefaultDriver) StatObject(backupLocation *stork_api.BackupLocation, key string) (*ObjectInfo, error) {
+	bucket, err := d.bucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	defer bucket.Close()
+
+	attrs, err := bucket.Attributes(context.TODO(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s in bucket %s: %v", key, backupLocation.Location.Path, err)
+	}
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        attrs.Size,
+		ModTime:     attrs.ModTime,
+		ContentType: attrs.ContentType,
+		MD5:         attrs.MD5,
+		Metadata:    attrs.Metadata,
+	}
+	if d.lockOf != nil {
+		if info.Lock, err = d.lockOf(attrs); err != nil {
+			return nil, fmt.Errorf("failed to get lock of object %s in bucket %s: %v", key, backupLocation.Location.Path, err)
+		}
+	}
+	return info, nil
+}
+
+// ReadObject returns the content of the object with the given key
+func (d *DefaultDriver) ReadObject(backupLocation *stork_api.BackupLocation, key string) ([]byte, error) {
+	bucket, err := d.bucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	defer bucket.Close()
+
+	data, err := bucket.ReadAll(context.TODO(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s in bucket %s: %v", key, backupLocation.Location.Path, err)
+	}
+	return data, nil
+}
+
+// HashObjects returns the hex encoded SHA-256 of the objects under the prefix by key
+func (d *DefaultDriver) HashObjects(backupLocation *stork_api.BackupLocation, prefix string) (map[string]string, error) {
+	objects, err := d.ListObjects(backupLocation, prefix)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := d.bucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	defer bucket.Close()
+
+	hashes := make(map[string]string, len(objects))
+	for _, o := range objects {
+		// backups can be large, so objects are streamed through the hash rather than read at once
+		r, err := bucket.NewReader(context.TODO(), o.Key, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read object %s in bucket %s: %v", o.Key, backupLocation.Location.Path, err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read object %s in bucket %s: %v", o.Key, backupLocation.Location.Path, err)
+		}
+		hashes[o.Key] = hex.EncodeToString(h.Sum(nil))
+	}
+	return hashes, nil
+}
+
+// GetObjectLockInfo returns the object lock configuration of the bucket of the backup location
+func (d *DefaultDriver) GetObjectLockInfo(backupLocation *stork_api.BackupLocation) (*common.ObjLockInfo, error) {
+	return stork_objectstore.GetObjLockInfo(backupLocation)
+}
+
+// ValidateObjectLock validates the locks of the objects under the prefix against the expected lock
+func (d *DefaultDriver) ValidateObjectLock(backupLocation *stork_api.BackupLocation, prefix string, expected ObjectLock) error {
+	if d.lockOf == nil {
+		return &errors.ErrNotSupported{
+			Type:      string(backupLocation.Location.Type),
+			Operation: "ValidateObjectLock()",
+		}
+	}
+	objects, err := d.ListObjects(backupLocation, prefix)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return fmt.Errorf("no objects under %s in bucket %s", prefix, backupLocation.Location.Path)
+	}
+	var problems []string
+	for _, o := range objects {
+		info, err := d.StatObject(backupLocation, o.Key)
+		if err != nil {
+			return err
+		}
+		lock := info.Lock
+		if expected.Mode != "" && lock.Mode != expected.Mode {
+			problems = append(problems, fmt.Sprintf("%s is locked in mode [%s], expected [%s]", o.Key, lock.Mode, expected.Mode))
+		}
+		if !expected.RetainUntil.IsZero() && lock.RetainUntil.Before(expected.RetainUntil) {
+			problems = append(problems, fmt.Sprintf("%s is retained until [%v], expected at least [%v]", o.Key, lock.RetainUntil, expected.RetainUntil))
+		}
+		if expected.LegalHold && !lock.LegalHold {
+			problems = append(problems, fmt.Sprintf("%s is not under legal hold", o.Key))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("objects under %s in bucket %s are not locked as expected:\n%s",
+			prefix, backupLocation.Location.Path, strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+func (d *DefaultDriver) bucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+	openBucket := stork_objectstore.GetBucket
+	if d.openBucket != nil {
+		openBucket = d.openBucket
+	}
+	bucket, err := openBucket(backupLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket %s: %v", backupLocation.Location.Path, err)
+	}
+	return bucket, nil
+}
@@ -0,0 +1,386 @@
+// Package fakeazure is an in-memory Azure Blob Storage service, like a local Azurite, so that the
+// Azure objectstore driver can be verified without a storage account. It serves path style requests
+// of any account, like Azurite, without checking signatures, for the subset of the Blob service API
+// used by the objectstore drivers and stork: containers, blobs, ranges and listing.
+package fakeazure
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	metaPrefix        = "X-Ms-Meta-"
+	defaultMaxResults = 5000
+)
+
+type blob struct {
+	data        []byte
+	contentType string
+	metadata    map[string]string
+	modTime     time.Time
+}
+
+func (b *blob) etag() string {
+	sum := md5.Sum(b.data)
+	return `"0x` + strings.ToUpper(hex.EncodeToString(sum[:8])) + `"`
+}
+
+func (b *blob) md5() string {
+	sum := md5.Sum(b.data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+type container struct {
+	created time.Time
+	blobs   map[string]*blob
+}
+
+// Server is an in-memory Blob service. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	sync.Mutex
+	containers map[string]*container
+}
+
+// New starts a server, which must be closed with Close
+func New() *Server {
+	s := &Server{containers: make(map[string]*container)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Endpoint returns the blob service endpoint of the account, in the path style of Azurite
+func (s *Server) Endpoint(account string) string {
+	return s.URL + "/" + account
+}
+
+// CreateContainer creates a container if it does not exist yet
+func (s *Server) CreateContainer(name string) {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.containers[name]; !ok {
+		s.containers[name] = &container{created: time.Now(), blobs: make(map[string]*blob)}
+	}
+}
+
+// PutBlob puts a block blob in a container, creating the container if needed
+func (s *Server) PutBlob(containerName, name string, data []byte) {
+	s.CreateContainer(containerName)
+	s.Lock()
+	defer s.Unlock()
+	s.containers[containerName].blobs[name] = &blob{
+		data:        data,
+		contentType: "application/octet-stream",
+		modTime:     time.Now(),
+	}
+}
+
+func (c *container) names() []string {
+	names := make([]string, 0, len(c.blobs))
+	for name := range c.blobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type errorResponse struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	w.Header().Set("x-ms-error-code", code)
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		xml.NewEncoder(w).Encode(errorResponse{Code: code, Message: message})
+	}
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	// the first segment of the path is the account
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	containerName, name := "", ""
+	if len(path) > 1 {
+		containerName = path[1]
+	}
+	if len(path) > 2 {
+		name = path[2]
+	}
+	query := r.URL.Query()
+	if containerName == "" {
+		if r.Method != http.MethodGet || query.Get("comp") != "list" {
+			writeError(w, r, http.StatusBadRequest, "UnsupportedHttpVerb", r.Method+" is not supported on the service")
+			return
+		}
+		s.listContainers(w, query)
+		return
+	}
+
+	c, ok := s.containers[containerName]
+	if name == "" && query.Get("restype") == "container" && r.Method == http.MethodPut {
+		if ok {
+			writeError(w, r, http.StatusConflict, "ContainerAlreadyExists", "container "+containerName+" already exists")
+			return
+		}
+		s.containers[containerName] = &container{created: time.Now(), blobs: make(map[string]*blob)}
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "ContainerNotFound", "container "+containerName+" does not exist")
+		return
+	}
+
+	switch {
+	case name == "" && r.Method == http.MethodGet && query.Get("comp") == "list":
+		s.listBlobs(w, containerName, c, query)
+	case name == "":
+		writeError(w, r, http.StatusBadRequest, "UnsupportedHttpVerb", r.Method+" is not supported on containers")
+	case r.Method == http.MethodPut:
+		s.putBlob(w, r, c, name)
+	case r.Method == http.MethodDelete:
+		if _, ok := c.blobs[name]; !ok {
+			writeError(w, r, http.StatusNotFound, "BlobNotFound", "blob "+name+" does not exist")
+			return
+		}
+		delete(c.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s.getBlob(w, r, c, name)
+	default:
+		writeError(w, r, http.StatusBadRequest, "UnsupportedHttpVerb", r.Method+" is not supported on blobs")
+	}
+}
+
+type containerEntry struct {
+	Name         string `xml:"Name"`
+	LastModified string `xml:"Properties>Last-Modified"`
+	Etag         string `xml:"Properties>Etag"`
+}
+
+type listContainersResult struct {
+	XMLName         xml.Name         `xml:"EnumerationResults"`
+	ServiceEndpoint string           `xml:"ServiceEndpoint,attr"`
+	Prefix          string           `xml:"Prefix,omitempty"`
+	Containers      []containerEntry `xml:"Containers>Container"`
+	NextMarker      string           `xml:"NextMarker"`
+}
+
+func (s *Server) listContainers(w http.ResponseWriter, query url.Values) {
+	names := make([]string, 0, len(s.containers))
+	for name := range s.containers {
+		if strings.HasPrefix(name, query.Get("prefix")) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result := listContainersResult{ServiceEndpoint: s.URL, Prefix: query.Get("prefix")}
+	for _, name := range names {
+		created := s.containers[name].created
+		result.Containers = append(result.Containers, containerEntry{
+			Name:         name,
+			LastModified: created.UTC().Format(http.TimeFormat),
+			Etag:         fmt.Sprintf(`"0x%X"`, created.UnixNano()),
+		})
+	}
+	writeXML(w, result)
+}
+
+type blobProperties struct {
+	LastModified  string `xml:"Last-Modified"`
+	Etag          string `xml:"Etag"`
+	ContentLength int    `xml:"Content-Length"`
+	ContentType   string `xml:"Content-Type"`
+	ContentMD5    string `xml:"Content-MD5"`
+	BlobType      string `xml:"BlobType"`
+}
+
+type blobEntry struct {
+	Name       string         `xml:"Name"`
+	Properties blobProperties `xml:"Properties"`
+}
+
+type blobPrefix struct {
+	Name string `xml:"Name"`
+}
+
+type listBlobsResult struct {
+	XMLName         xml.Name     `xml:"EnumerationResults"`
+	ServiceEndpoint string       `xml:"ServiceEndpoint,attr"`
+	ContainerName   string       `xml:"ContainerName,attr"`
+	Prefix          string       `xml:"Prefix,omitempty"`
+	Marker          string       `xml:"Marker,omitempty"`
+	MaxResults      int          `xml:"MaxResults"`
+	Delimiter       string       `xml:"Delimiter,omitempty"`
+	Blobs           []blobEntry  `xml:"Blobs>Blob"`
+	BlobPrefixes    []blobPrefix `xml:"Blobs>BlobPrefix"`
+	NextMarker      string       `xml:"NextMarker"`
+}
+
+// listBlobs lists blobs like List Blobs, where the marker is the first blob or prefix not returned
+func (s *Server) listBlobs(w http.ResponseWriter, name string, c *container, query url.Values) {
+	result := listBlobsResult{
+		ServiceEndpoint: s.URL,
+		ContainerName:   name,
+		Prefix:          query.Get("prefix"),
+		Marker:          query.Get("marker"),
+		MaxResults:      defaultMaxResults,
+		Delimiter:       query.Get("delimiter"),
+	}
+	if maxResults, err := strconv.Atoi(query.Get("maxresults")); err == nil && maxResults > 0 {
+		result.MaxResults = maxResults
+	}
+
+	last, count := "", 0
+	for _, blobName := range c.names() {
+		if !strings.HasPrefix(blobName, result.Prefix) {
+			continue
+		}
+		entry := blobName
+		if result.Delimiter != "" {
+			if i := strings.Index(blobName[len(result.Prefix):], result.Delimiter); i >= 0 {
+				entry = blobName[:len(result.Prefix)+i+len(result.Delimiter)]
+			}
+		}
+		if entry == last || entry < result.Marker {
+			continue
+		}
+		if count == result.MaxResults {
+			result.NextMarker = entry
+			break
+		}
+		if entry == blobName {
+			b := c.blobs[blobName]
+			result.Blobs = append(result.Blobs, blobEntry{
+				Name: blobName,
+				Properties: blobProperties{
+					LastModified:  b.modTime.UTC().Format(http.TimeFormat),
+					Etag:          b.etag(),
+					ContentLength: len(b.data),
+					ContentType:   b.contentType,
+					ContentMD5:    b.md5(),
+					BlobType:      "BlockBlob",
+				},
+			})
+		} else {
+			result.BlobPrefixes = append(result.BlobPrefixes, blobPrefix{entry})
+		}
+		count++
+		last = entry
+	}
+	writeXML(w, result)
+}
+
+func (s *Server) putBlob(w http.ResponseWriter, r *http.Request, c *container, name string) {
+	if blobType := r.Header.Get("x-ms-blob-type"); blobType != "BlockBlob" {
+		writeError(w, r, http.StatusBadRequest, "InvalidHeaderValue", "blob type "+blobType+" is not supported")
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "InvalidInput", err.Error())
+		return
+	}
+	b := &blob{
+		data:        data,
+		contentType: r.Header.Get("x-ms-blob-content-type"),
+		metadata:    make(map[string]string),
+		modTime:     time.Now(),
+	}
+	if b.contentType == "" {
+		b.contentType = "application/octet-stream"
+	}
+	for header := range r.Header {
+		if strings.HasPrefix(header, metaPrefix) {
+			b.metadata[strings.ToLower(strings.TrimPrefix(header, metaPrefix))] = r.Header.Get(header)
+		}
+	}
+	c.blobs[name] = b
+	w.Header().Set("ETag", b.etag())
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) getBlob(w http.ResponseWriter, r *http.Request, c *container, name string) {
+	b, ok := c.blobs[name]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "BlobNotFound", "blob "+name+" does not exist")
+		return
+	}
+	header := w.Header()
+	header.Set("Content-Type", b.contentType)
+	header.Set("Content-MD5", b.md5())
+	header.Set("ETag", b.etag())
+	header.Set("Last-Modified", b.modTime.UTC().Format(http.TimeFormat))
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("x-ms-blob-type", "BlockBlob")
+	for k, v := range b.metadata {
+		header.Set(metaPrefix+k, v)
+	}
+
+	data, status := b.data, http.StatusOK
+	rng := r.Header.Get("x-ms-range")
+	if rng == "" {
+		rng = r.Header.Get("Range")
+	}
+	if rng != "" && r.Method == http.MethodGet {
+		start, end, err := parseRange(rng, len(b.data))
+		if err != nil {
+			writeError(w, r, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", err.Error())
+			return
+		}
+		data, status = b.data[start:end+1], http.StatusPartialContent
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(b.data)))
+	}
+	header.Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// parseRange parses a single byte range, returning its inclusive bounds
+func parseRange(rng string, size int) (int, int, error) {
+	bounds := strings.SplitN(strings.TrimPrefix(rng, "bytes="), "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %s", rng)
+	}
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil || start >= size {
+		return 0, 0, fmt.Errorf("invalid range %s of %d bytes", rng, size)
+	}
+	end := size - 1
+	if bounds[1] != "" {
+		if end, err = strconv.Atoi(bounds[1]); err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid range %s of %d bytes", rng, size)
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end, nil
+}
@@ -0,0 +1,432 @@
+// Package fakes3 is an in-memory S3 compatible object store, like a local MinIO, so that objectstore
+// drivers and backup tests can verify buckets without a cloud account. It serves path style
+// requests, without checking signatures, for the subset of the S3 API used by the objectstore
+// drivers and stork: buckets, objects, ranges, listing, object lock configuration and object locks.
+package fakes3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	iso8601        = "2006-01-02T15:04:05.000Z"
+	metaPrefix     = "X-Amz-Meta-"
+	defaultMaxKeys = 1000
+)
+
+// ObjectLock is the lock of an object
+type ObjectLock struct {
+	// Mode is GOVERNANCE or COMPLIANCE
+	Mode        string
+	RetainUntil time.Time
+	LegalHold   bool
+}
+
+// BucketLock is the object lock configuration of a bucket
+type BucketLock struct {
+	// Mode of the default retention, GOVERNANCE or COMPLIANCE
+	Mode string
+	// Days of the default retention
+	Days int64
+}
+
+type object struct {
+	data        []byte
+	contentType string
+	metadata    map[string]string
+	modTime     time.Time
+	lock        ObjectLock
+}
+
+func (o *object) etag() string {
+	sum := md5.Sum(o.data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+type bucket struct {
+	created time.Time
+	lock    *BucketLock
+	objects map[string]*object
+}
+
+// Server is an in-memory S3 server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	sync.Mutex
+	buckets map[string]*bucket
+}
+
+// New starts a server, which must be closed with Close
+func New() *Server {
+	s := &Server{buckets: make(map[string]*bucket)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Endpoint returns the host and port of the server, to be used as S3 endpoint with SSL disabled
+func (s *Server) Endpoint() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// CreateBucket creates a bucket if it does not exist yet. Objects of buckets with a lock are
+// locked with its default retention when they are put without lock.
+func (s *Server) CreateBucket(name string, lock *BucketLock) {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.buckets[name]; !ok {
+		s.buckets[name] = &bucket{created: time.Now(), lock: lock, objects: make(map[string]*object)}
+	}
+}
+
+// PutObject puts an object in a bucket, creating the bucket if needed
+func (s *Server) PutObject(bucketName, key string, data []byte, lock ObjectLock) {
+	s.CreateBucket(bucketName, nil)
+	s.Lock()
+	defer s.Unlock()
+	s.put(s.buckets[bucketName], key, &object{data: data, lock: lock})
+}
+
+// Keys returns the sorted keys of the objects of a bucket
+func (s *Server) Keys(bucketName string) []string {
+	s.Lock()
+	defer s.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil
+	}
+	return b.keys()
+}
+
+func (s *Server) put(b *bucket, key string, o *object) {
+	o.modTime = time.Now()
+	if o.contentType == "" {
+		o.contentType = "application/octet-stream"
+	}
+	if o.lock.Mode == "" && b.lock != nil {
+		o.lock.Mode = b.lock.Mode
+		o.lock.RetainUntil = o.modTime.AddDate(0, 0, int(b.lock.Days))
+	}
+	b.objects[key] = o
+}
+
+func (b *bucket) keys() []string {
+	keys := make([]string, 0, len(b.objects))
+	for k := range b.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type errorResponse struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		xml.NewEncoder(w).Encode(errorResponse{Code: code, Message: message})
+	}
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucketName := path[0]
+	key := ""
+	if len(path) > 1 {
+		key = path[1]
+	}
+	if bucketName == "" {
+		if r.Method != http.MethodGet {
+			writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method+" is not allowed on the service")
+			return
+		}
+		s.listBuckets(w)
+		return
+	}
+
+	b, ok := s.buckets[bucketName]
+	if r.Method == http.MethodPut && key == "" {
+		if !ok {
+			var lock *BucketLock
+			if r.Header.Get("X-Amz-Bucket-Object-Lock-Enabled") == "true" {
+				lock = &BucketLock{}
+			}
+			s.buckets[bucketName] = &bucket{created: time.Now(), lock: lock, objects: make(map[string]*object)}
+		}
+		return
+	}
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket", "bucket "+bucketName+" does not exist")
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case key == "" && r.Method == http.MethodGet && hasParam(query, "object-lock"):
+		s.objectLockConfiguration(w, r, b)
+	case key == "" && r.Method == http.MethodGet:
+		s.listObjects(w, bucketName, b, query)
+	case key == "" && r.Method == http.MethodHead:
+	case r.Method == http.MethodPut:
+		s.putObject(w, r, b, key)
+	case r.Method == http.MethodDelete:
+		o, ok := b.objects[key]
+		if ok && (o.lock.LegalHold || o.lock.RetainUntil.After(time.Now())) {
+			writeError(w, r, http.StatusForbidden, "AccessDenied", "object "+key+" is locked")
+			return
+		}
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s.getObject(w, r, b, key)
+	default:
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method+" is not allowed")
+	}
+}
+
+func hasParam(query url.Values, name string) bool {
+	_, ok := query[name]
+	return ok
+}
+
+type bucketEntry struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name      `xml:"ListAllMyBucketsResult"`
+	Buckets []bucketEntry `xml:"Buckets>Bucket"`
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) {
+	names := make([]string, 0, len(s.buckets))
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var result listAllMyBucketsResult
+	for _, name := range names {
+		result.Buckets = append(result.Buckets, bucketEntry{name, s.buckets[name].created.UTC().Format(iso8601)})
+	}
+	writeXML(w, result)
+}
+
+type listObjectsContent struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name             `xml:"ListBucketResult"`
+	Name                  string               `xml:"Name"`
+	Prefix                string               `xml:"Prefix"`
+	Delimiter             string               `xml:"Delimiter,omitempty"`
+	MaxKeys               int                  `xml:"MaxKeys"`
+	KeyCount              int                  `xml:"KeyCount"`
+	IsTruncated           bool                 `xml:"IsTruncated"`
+	ContinuationToken     string               `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string               `xml:"NextContinuationToken,omitempty"`
+	Contents              []listObjectsContent `xml:"Contents"`
+	CommonPrefixes        []commonPrefix       `xml:"CommonPrefixes"`
+}
+
+// listObjects lists objects like ListObjectsV2, where the continuation token is the last key or
+// common prefix returned
+func (s *Server) listObjects(w http.ResponseWriter, name string, b *bucket, query url.Values) {
+	result := listBucketResult{
+		Name:              name,
+		Prefix:            query.Get("prefix"),
+		Delimiter:         query.Get("delimiter"),
+		MaxKeys:           defaultMaxKeys,
+		ContinuationToken: query.Get("continuation-token"),
+	}
+	if maxKeys, err := strconv.Atoi(query.Get("max-keys")); err == nil && maxKeys > 0 {
+		result.MaxKeys = maxKeys
+	}
+	after := result.ContinuationToken
+	if after == "" {
+		after = query.Get("start-after")
+	}
+
+	last := ""
+	for _, key := range b.keys() {
+		if !strings.HasPrefix(key, result.Prefix) || key <= after {
+			continue
+		}
+		entry := key
+		if result.Delimiter != "" {
+			if i := strings.Index(key[len(result.Prefix):], result.Delimiter); i >= 0 {
+				entry = key[:len(result.Prefix)+i+len(result.Delimiter)]
+			}
+		}
+		if entry == last || (entry != key && strings.HasPrefix(after, entry)) {
+			continue
+		}
+		if result.KeyCount == result.MaxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = last
+			break
+		}
+		if entry == key {
+			o := b.objects[key]
+			result.Contents = append(result.Contents, listObjectsContent{
+				Key:          key,
+				LastModified: o.modTime.UTC().Format(iso8601),
+				ETag:         o.etag(),
+				Size:         len(o.data),
+				StorageClass: "STANDARD",
+			})
+		} else {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{entry})
+		}
+		result.KeyCount++
+		last = entry
+	}
+	writeXML(w, result)
+}
+
+type lockRule struct {
+	Mode string `xml:"DefaultRetention>Mode"`
+	Days int64  `xml:"DefaultRetention>Days"`
+}
+
+type objectLockConfiguration struct {
+	XMLName           xml.Name  `xml:"ObjectLockConfiguration"`
+	ObjectLockEnabled string    `xml:"ObjectLockEnabled"`
+	Rule              *lockRule `xml:"Rule,omitempty"`
+}
+
+func (s *Server) objectLockConfiguration(w http.ResponseWriter, r *http.Request, b *bucket) {
+	if b.lock == nil {
+		writeError(w, r, http.StatusNotFound, "ObjectLockConfigurationNotFoundError", "object lock configuration does not exist")
+		return
+	}
+	config := objectLockConfiguration{ObjectLockEnabled: "Enabled"}
+	if b.lock.Mode != "" {
+		config.Rule = &lockRule{b.lock.Mode, b.lock.Days}
+	}
+	writeXML(w, config)
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	o := &object{data: data, contentType: r.Header.Get("Content-Type"), metadata: make(map[string]string)}
+	for name := range r.Header {
+		if strings.HasPrefix(name, metaPrefix) {
+			o.metadata[strings.ToLower(strings.TrimPrefix(name, metaPrefix))] = r.Header.Get(name)
+		}
+	}
+	o.lock.Mode = r.Header.Get("X-Amz-Object-Lock-Mode")
+	if until := r.Header.Get("X-Amz-Object-Lock-Retain-Until-Date"); until != "" {
+		if o.lock.RetainUntil, err = time.Parse(time.RFC3339, until); err != nil {
+			writeError(w, r, http.StatusBadRequest, "InvalidArgument", err.Error())
+			return
+		}
+	}
+	o.lock.LegalHold = r.Header.Get("X-Amz-Object-Lock-Legal-Hold") == "ON"
+	if o.lock.Mode != "" && b.lock == nil {
+		writeError(w, r, http.StatusBadRequest, "InvalidRequest", "bucket is missing object lock configuration")
+		return
+	}
+	s.put(b, key, o)
+	w.Header().Set("ETag", o.etag())
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	o, ok := b.objects[key]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchKey", "object "+key+" does not exist")
+		return
+	}
+	header := w.Header()
+	header.Set("Content-Type", o.contentType)
+	header.Set("ETag", o.etag())
+	header.Set("Last-Modified", o.modTime.UTC().Format(http.TimeFormat))
+	header.Set("Accept-Ranges", "bytes")
+	for k, v := range o.metadata {
+		header.Set(metaPrefix+k, v)
+	}
+	if o.lock.Mode != "" {
+		header.Set("X-Amz-Object-Lock-Mode", o.lock.Mode)
+		header.Set("X-Amz-Object-Lock-Retain-Until-Date", o.lock.RetainUntil.UTC().Format(iso8601))
+	}
+	if o.lock.LegalHold {
+		header.Set("X-Amz-Object-Lock-Legal-Hold", "ON")
+	}
+
+	data, status := o.data, http.StatusOK
+	if rng := r.Header.Get("Range"); rng != "" {
+		start, end, err := parseRange(rng, len(o.data))
+		if err != nil {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", len(o.data)))
+			writeError(w, r, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", err.Error())
+			return
+		}
+		data, status = o.data[start:end+1], http.StatusPartialContent
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(o.data)))
+	}
+	header.Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// parseRange parses a single byte range, returning its inclusive bounds
+func parseRange(rng string, size int) (int, int, error) {
+	bounds := strings.SplitN(strings.TrimPrefix(rng, "bytes="), "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %s", rng)
+	}
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil || start >= size {
+		return 0, 0, fmt.Errorf("invalid range %s of %d bytes", rng, size)
+	}
+	end := size - 1
+	if bounds[1] != "" {
+		if end, err = strconv.Atoi(bounds[1]); err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid range %s of %d bytes", rng, size)
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end, nil
+}
@@ -0,0 +1,71 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"gocloud.dev/blob"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// googleDriver is the driver of Google Cloud Storage backup locations
+type googleDriver struct {
+	DefaultDriver
+}
+
+func (d *googleDriver) String() string {
+	return string(stork_api.BackupLocationGoogle)
+}
+
+// ListBuckets lists the buckets of the project of the backup location
+func (d *googleDriver) ListBuckets(backupLocation *stork_api.BackupLocation) ([]string, error) {
+	if backupLocation.Location.GoogleConfig == nil {
+		return nil, fmt.Errorf("backup location %s has no Google config", backupLocation.Name)
+	}
+	config := backupLocation.Location.GoogleConfig
+	conf, err := google.JWTConfigFromJSON([]byte(config.AccountKey), storage.ScopeFullControl)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx, option.WithTokenSource(conf.TokenSource(ctx)))
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	buckets := make([]string, 0)
+	it := client.Buckets(ctx, config.ProjectID)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list buckets of project %s: %v", config.ProjectID, err)
+		}
+		buckets = append(buckets, attrs.Name)
+	}
+	return buckets, nil
+}
+
+// googleLockOf returns the lock of an object from its retention and holds. Google Cloud Storage has
+// no lock modes, event based and temporary holds are both reported as legal holds.
+func googleLockOf(attrs *blob.Attributes) (ObjectLock, error) {
+	var objAttrs storage.ObjectAttrs
+	if !attrs.As(&objAttrs) {
+		return ObjectLock{}, fmt.Errorf("attributes are not Google Cloud Storage attributes")
+	}
+	return ObjectLock{
+		RetainUntil: objAttrs.RetentionExpirationTime,
+		LegalHold:   objAttrs.EventBasedHold || objAttrs.TemporaryHold,
+	}, nil
+}
+
+func init() {
+	Register(string(stork_api.BackupLocationGoogle), &googleDriver{DefaultDriver{lockOf: googleLockOf}})
+}
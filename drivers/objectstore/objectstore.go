@@ -5,6 +5,7 @@ import (
 	"time"
 
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore/common"
 	"github.com/portworx/sched-ops/k8s/stork"
 	"github.com/portworx/torpedo/pkg/errors"
 )
@@ -20,6 +21,30 @@ const (
 	defaultTimeout       = 2 * time.Minute
 )
 
+// ObjectLock is the lock of an object
+type ObjectLock struct {
+	// Mode of the lock, such as GOVERNANCE or COMPLIANCE for S3. Empty if the object is not
+	// locked or the provider has no lock modes.
+	Mode string
+	// RetainUntil is the time till which the object is retained
+	RetainUntil time.Time
+	// LegalHold is true if the object is under legal hold
+	LegalHold bool
+}
+
+// ObjectInfo describes an object of a bucket
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+	// MD5 of the content of the object, if the provider reports it
+	MD5      []byte
+	Metadata map[string]string
+	// Lock of the object, only set by StatObject
+	Lock ObjectLock
+}
+
 // Driver defines an external volume driver interface that must be implemented
 type Driver interface {
 	// String returns the string name of this driver.
@@ -28,17 +53,39 @@ type Driver interface {
 	// ValidateBackupsDeletedFromCloud validates if bucket has been deleted from the cloud objectstore
 	ValidateBackupsDeletedFromCloud(backupLocation *stork_api.BackupLocation, backupPath string) error
 
-	// TODO: Actually add these per PWX-19768
-	// ListBuckets()
-	ListBuckets() ([]string, error)
+	// ListBuckets lists the buckets of the account of the backup location
+	ListBuckets(backupLocation *stork_api.BackupLocation) ([]string, error)
+
+	// ListFilesInBucket lists the keys of the objects under the prefix in the bucket of the backup location
+	ListFilesInBucket(backupLocation *stork_api.BackupLocation, prefix string) ([]string, error)
+
+	// CheckConnection checks that the bucket of the backup location can be listed
+	CheckConnection(backupLocation *stork_api.BackupLocation) error
 
-	// ListFilesInBucket(bucketName string) ([]string, error)
-	ListFilesInBucket(bucketName string) ([]string, error)
+	// ListObjects lists the objects under the prefix in the bucket of the backup location
+	ListObjects(backupLocation *stork_api.BackupLocation, prefix string) ([]*ObjectInfo, error)
 
-	// CheckConnection() error
-	CheckConnection() error
+	// StatObject returns the object with the given key in the bucket of the backup location
+	StatObject(backupLocation *stork_api.BackupLocation, key string) (*ObjectInfo, error)
+
+	// ReadObject returns the content of the object with the given key in the bucket of the backup location
+	ReadObject(backupLocation *stork_api.BackupLocation, key string) ([]byte, error)
+
+	// HashObjects returns the hex encoded SHA-256 of the content of the objects under the prefix
+	// in the bucket of the backup location by key
+	HashObjects(backupLocation *stork_api.BackupLocation, prefix string) (map[string]string, error)
+
+	// GetObjectLockInfo returns the object lock configuration of the bucket of the backup location
+	GetObjectLockInfo(backupLocation *stork_api.BackupLocation) (*common.ObjLockInfo, error)
+
+	// ValidateObjectLock validates that the objects under the prefix in the bucket of the backup
+	// location are locked in the mode of the expected lock, at least till its retain until time
+	// and under legal hold if it is. Empty fields of the expected lock are not validated.
+	ValidateObjectLock(backupLocation *stork_api.BackupLocation, prefix string, expected ObjectLock) error
 }
 
+// objstore is the driver of all backup locations, which uses the driver registered for the type
+// of each backup location
 type objstore struct {
 	DefaultDriver
 }
@@ -56,6 +103,23 @@ func Get() (Driver, error) {
 	}
 }
 
+// GetForLocation returns the objectstore driver of the type of the given backup location
+func GetForLocation(backupLocation *stork_api.BackupLocation) (Driver, error) {
+	if backupLocation == nil {
+		return nil, fmt.Errorf("nil backupLocation")
+	}
+	name := string(backupLocation.Location.Type)
+	d, ok := objectstoredriver[name]
+	if ok && name != driverName {
+		return d, nil
+	}
+
+	return nil, &errors.ErrNotFound{
+		ID:   name,
+		Type: "ObjectstoreDriver",
+	}
+}
+
 // Register registers the objectstore driver
 func Register(driverName string, d Driver) error {
 	if _, ok := objectstoredriver[driverName]; !ok {
@@ -72,17 +136,86 @@ func (o *objstore) String() string {
 	return driverName
 }
 
-func (o *objstore) ListBuckets() ([]string, error) {
-	return []string{}, nil
+func (o *objstore) ValidateBackupsDeletedFromCloud(backupLocation *stork_api.BackupLocation, backupPath string) error {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return err
+	}
+	return d.ValidateBackupsDeletedFromCloud(backupLocation, backupPath)
 }
 
-func (o *objstore) ListFilesInBucket(bucketName string) ([]string, error) {
-	return []string{}, nil
+func (o *objstore) ListBuckets(backupLocation *stork_api.BackupLocation) ([]string, error) {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return d.ListBuckets(backupLocation)
 }
 
-func (o *objstore) CheckConnection() error {
-	return nil
+func (o *objstore) ListFilesInBucket(backupLocation *stork_api.BackupLocation, prefix string) ([]string, error) {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return d.ListFilesInBucket(backupLocation, prefix)
 }
+
+func (o *objstore) CheckConnection(backupLocation *stork_api.BackupLocation) error {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return err
+	}
+	return d.CheckConnection(backupLocation)
+}
+
+func (o *objstore) ListObjects(backupLocation *stork_api.BackupLocation, prefix string) ([]*ObjectInfo, error) {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return d.ListObjects(backupLocation, prefix)
+}
+
+func (o *objstore) StatObject(backupLocation *stork_api.BackupLocation, key string) (*ObjectInfo, error) {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return d.StatObject(backupLocation, key)
+}
+
+func (o *objstore) ReadObject(backupLocation *stork_api.BackupLocation, key string) ([]byte, error) {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return d.ReadObject(backupLocation, key)
+}
+
+func (o *objstore) HashObjects(backupLocation *stork_api.BackupLocation, prefix string) (map[string]string, error) {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return d.HashObjects(backupLocation, prefix)
+}
+
+func (o *objstore) GetObjectLockInfo(backupLocation *stork_api.BackupLocation) (*common.ObjLockInfo, error) {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return d.GetObjectLockInfo(backupLocation)
+}
+
+func (o *objstore) ValidateObjectLock(backupLocation *stork_api.BackupLocation, prefix string, expected ObjectLock) error {
+	d, err := GetForLocation(backupLocation)
+	if err != nil {
+		return err
+	}
+	return d.ValidateObjectLock(backupLocation, prefix, expected)
+}
+
 func init() {
 	Register(driverName, &objstore{})
 }
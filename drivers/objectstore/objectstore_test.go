@@ -0,0 +1,174 @@
+package objectstore_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/torpedo/drivers/objectstore"
+	"github.com/portworx/torpedo/drivers/objectstore/fakeazure"
+	"github.com/portworx/torpedo/drivers/objectstore/fakes3"
+	"github.com/portworx/torpedo/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func s3Location(server *fakes3.Server, bucket string) *stork_api.BackupLocation {
+	return &stork_api.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "fake-s3", Namespace: "backups"},
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationS3,
+			Path: bucket,
+			S3Config: &stork_api.S3Config{
+				Endpoint:        server.Endpoint(),
+				AccessKeyID:     "access",
+				SecretAccessKey: "secret",
+				Region:          "us-east-1",
+				DisableSSL:      true,
+			},
+		},
+	}
+}
+
+func hash(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestS3Driver(t *testing.T) {
+	server := fakes3.New()
+	defer server.Close()
+
+	retainUntil := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	server.CreateBucket("locked", &fakes3.BucketLock{Mode: "COMPLIANCE", Days: 1})
+	server.PutObject("locked", "ns/backup-1/volumes", []byte("volume data"), fakes3.ObjectLock{Mode: "COMPLIANCE", RetainUntil: retainUntil})
+	server.PutObject("locked", "ns/backup-1/resources.json", []byte("{}"), fakes3.ObjectLock{Mode: "COMPLIANCE", RetainUntil: retainUntil, LegalHold: true})
+	server.PutObject("locked", "ns/backup-2/volumes", []byte("other data"), fakes3.ObjectLock{})
+	server.CreateBucket("empty", nil)
+
+	d, err := objectstore.Get()
+	require.NoError(t, err)
+	locked := s3Location(server, "locked")
+
+	buckets, err := d.ListBuckets(locked)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"empty", "locked"}, buckets)
+	require.NoError(t, d.CheckConnection(locked))
+	assert.Error(t, d.CheckConnection(s3Location(server, "missing")))
+
+	files, err := d.ListFilesInBucket(locked, "ns/backup-1/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ns/backup-1/resources.json", "ns/backup-1/volumes"}, files)
+
+	data, err := d.ReadObject(locked, "ns/backup-1/volumes")
+	require.NoError(t, err)
+	assert.Equal(t, "volume data", string(data))
+	hashes, err := d.HashObjects(locked, "ns/")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ns/backup-1/resources.json": hash("{}"),
+		"ns/backup-1/volumes":        hash("volume data"),
+		"ns/backup-2/volumes":        hash("other data"),
+	}, hashes)
+
+	info, err := d.StatObject(locked, "ns/backup-1/resources.json")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), info.Size)
+	assert.Equal(t, "COMPLIANCE", info.Lock.Mode)
+	assert.True(t, info.Lock.RetainUntil.Equal(retainUntil))
+	assert.True(t, info.Lock.LegalHold)
+	_, err = d.StatObject(locked, "ns/missing")
+	assert.Error(t, err)
+
+	lockInfo, err := d.GetObjectLockInfo(locked)
+	require.NoError(t, err)
+	assert.True(t, lockInfo.LockEnabled)
+	assert.Equal(t, "COMPLIANCE", lockInfo.LockMode)
+	lockInfo, err = d.GetObjectLockInfo(s3Location(server, "empty"))
+	require.NoError(t, err)
+	assert.False(t, lockInfo.LockEnabled)
+
+	expected := objectstore.ObjectLock{Mode: "COMPLIANCE", RetainUntil: retainUntil.Add(-time.Hour)}
+	assert.NoError(t, d.ValidateObjectLock(locked, "ns/backup-1/", expected))
+	assert.NoError(t, d.ValidateObjectLock(locked, "ns/backup-2/", expected), "objects are locked by the default retention of the bucket")
+	expected.LegalHold = true
+	err = d.ValidateObjectLock(locked, "ns/backup-1/", expected)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ns/backup-1/volumes is not under legal hold")
+	assert.NotContains(t, err.Error(), "resources.json")
+	assert.Error(t, d.ValidateObjectLock(locked, "ns/backup-3/", expected), "there must be objects to validate")
+}
+
+func azureLocation(container string) *stork_api.BackupLocation {
+	return &stork_api.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "fake-azure", Namespace: "backups"},
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationAzure,
+			Path: container,
+			AzureConfig: &stork_api.AzureConfig{
+				StorageAccountName: "devstoreaccount1",
+				// key of the Azurite development account
+				StorageAccountKey: "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==",
+			},
+		},
+	}
+}
+
+func TestAzureDriver(t *testing.T) {
+	server := fakeazure.New()
+	defer server.Close()
+	t.Setenv("AZURE_BLOB_ENDPOINT", server.Endpoint("devstoreaccount1"))
+
+	server.PutBlob("backups", "ns/backup-1/volumes", []byte("volume data"))
+	server.PutBlob("backups", "ns/backup-1/resources.json", []byte("{}"))
+	server.PutBlob("backups", "ns/backup-2/volumes", []byte("other data"))
+	server.CreateContainer("empty")
+
+	d, err := objectstore.Get()
+	require.NoError(t, err)
+	backups := azureLocation("backups")
+
+	buckets, err := d.ListBuckets(backups)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backups", "empty"}, buckets)
+	require.NoError(t, d.CheckConnection(backups))
+	assert.Error(t, d.CheckConnection(azureLocation("missing")))
+
+	files, err := d.ListFilesInBucket(backups, "ns/backup-1/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ns/backup-1/resources.json", "ns/backup-1/volumes"}, files)
+
+	data, err := d.ReadObject(backups, "ns/backup-1/volumes")
+	require.NoError(t, err)
+	assert.Equal(t, "volume data", string(data))
+	hashes, err := d.HashObjects(backups, "ns/")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ns/backup-1/resources.json": hash("{}"),
+		"ns/backup-1/volumes":        hash("volume data"),
+		"ns/backup-2/volumes":        hash("other data"),
+	}, hashes)
+
+	info, err := d.StatObject(backups, "ns/backup-1/volumes")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), info.Size)
+	assert.Equal(t, objectstore.ObjectLock{}, info.Lock)
+	_, err = d.StatObject(backups, "ns/missing")
+	assert.Error(t, err)
+
+	assert.NoError(t, d.ValidateBackupsDeletedFromCloud(azureLocation("empty"), "ns/"))
+	assert.IsType(t, &errors.ErrNotSupported{}, d.ValidateObjectLock(backups, "ns/", objectstore.ObjectLock{}))
+}
+
+func TestGetForLocation(t *testing.T) {
+	for _, provider := range []stork_api.BackupLocationType{stork_api.BackupLocationS3, stork_api.BackupLocationAzure, stork_api.BackupLocationGoogle} {
+		d, err := objectstore.GetForLocation(&stork_api.BackupLocation{Location: stork_api.BackupLocationItem{Type: provider}})
+		require.NoError(t, err)
+		assert.Equal(t, string(provider), d.String())
+	}
+	_, err := objectstore.GetForLocation(&stork_api.BackupLocation{Location: stork_api.BackupLocationItem{Type: "nfs"}})
+	assert.IsType(t, &errors.ErrNotFound{}, err)
+}
@@ -0,0 +1,83 @@
+package objectstore
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/libopenstorage/secrets/aws/credentials"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"gocloud.dev/blob"
+)
+
+// s3Driver is the driver of S3 backup locations, including S3 compatible object stores
+type s3Driver struct {
+	DefaultDriver
+}
+
+func (d *s3Driver) String() string {
+	return string(stork_api.BackupLocationS3)
+}
+
+// ListBuckets lists the buckets of the account of the backup location
+func (d *s3Driver) ListBuckets(backupLocation *stork_api.BackupLocation) ([]string, error) {
+	sess, err := s3Session(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s3.New(sess).ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %v", err)
+	}
+	buckets := make([]string, 0, len(out.Buckets))
+	for _, b := range out.Buckets {
+		buckets = append(buckets, aws.StringValue(b.Name))
+	}
+	return buckets, nil
+}
+
+// s3LockOf returns the lock of an object from the object lock headers of its attributes
+func s3LockOf(attrs *blob.Attributes) (ObjectLock, error) {
+	var head s3.HeadObjectOutput
+	if !attrs.As(&head) {
+		return ObjectLock{}, fmt.Errorf("attributes are not S3 attributes")
+	}
+	return ObjectLock{
+		Mode:        aws.StringValue(head.ObjectLockMode),
+		RetainUntil: aws.TimeValue(head.ObjectLockRetainUntilDate),
+		LegalHold:   aws.StringValue(head.ObjectLockLegalHoldStatus) == s3.ObjectLockLegalHoldStatusOn,
+	}, nil
+}
+
+// s3Session returns a session for the backup location, configured the way stork configures it
+func s3Session(backupLocation *stork_api.BackupLocation) (*session.Session, error) {
+	if backupLocation.Location.S3Config == nil {
+		return nil, fmt.Errorf("backup location %s has no S3 config", backupLocation.Name)
+	}
+	config := backupLocation.Location.S3Config
+	// AWS SDK fetches the correct endpoint based on region provided if endpoint is passed empty
+	endpoint := config.Endpoint
+	if endpoint == "s3.amazonaws.com" {
+		endpoint = ""
+	}
+	awsCreds, err := credentials.NewAWSCredentials(config.AccessKeyID, config.SecretAccessKey, "", config.UseIam)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := awsCreds.Get()
+	if err != nil {
+		return nil, err
+	}
+	return session.NewSession(&aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Credentials:      creds,
+		Region:           aws.String(config.Region),
+		DisableSSL:       aws.Bool(config.DisableSSL),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+}
+
+func init() {
+	Register(string(stork_api.BackupLocationS3), &s3Driver{DefaultDriver{lockOf: s3LockOf}})
+}
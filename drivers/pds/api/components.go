@@ -25,6 +25,7 @@ type Components struct {
 	BackupJob                *BackupJob
 	BackupTarget             *BackupTarget
 	BackupPolicy             *BackupPolicy
+	Restore                  *Restore
 	APIVersion               *PDSVersion
 	ServiceAccount           *ServiceAccount
 }
@@ -87,6 +88,9 @@ func NewComponents(apiClient *pds.APIClient) *Components {
 		BackupTarget: &BackupTarget{
 			apiClient: apiClient,
 		},
+		Restore: &Restore{
+			apiClient: apiClient,
+		},
 		APIVersion: &PDSVersion{
 			apiClient: apiClient,
 		},
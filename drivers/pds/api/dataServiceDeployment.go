@@ -186,6 +186,30 @@ func (ds *DataServiceDeployment) UpdateDeployment(deploymentID string, appConfig
 	return dsModel, err
 }
 
+// UpdateDeploymentScheduledBackup attaches the backup policy and target to the deployment, or detaches them if both are empty.
+func (ds *DataServiceDeployment) UpdateDeploymentScheduledBackup(deploymentID string, backupPolicyID string, backupTargetID string) (*pds.ModelsDeployment, error) {
+	dsClient := ds.apiClient.DeploymentsApi
+	ctx, err := pdsutils.GetContext()
+	if err != nil {
+		log.Errorf("Error in getting context for api call: %v\n", err)
+		return nil, err
+	}
+	scheduledBackup := pds.ControllersUpdateDeploymentScheduledBackup{}
+	if backupPolicyID != "" || backupTargetID != "" {
+		scheduledBackup.BackupPolicyId = &backupPolicyID
+		scheduledBackup.BackupTargetId = &backupTargetID
+	}
+	updateRequest := pds.ControllersUpdateDeploymentRequest{
+		ScheduledBackup: &scheduledBackup,
+	}
+	dsModel, res, err := dsClient.ApiDeploymentsIdPut(ctx, deploymentID).Body(updateRequest).Execute()
+	if res.StatusCode != status.StatusOK {
+		log.Errorf("Error when calling `ApiDeploymentsIdPut``: %v\n", err)
+		log.Errorf("Full HTTP response: %v\n", res)
+	}
+	return dsModel, err
+}
+
 // GetConnectionDetails return connection details for the given deployment.
 func (ds *DataServiceDeployment) GetConnectionDetails(deploymentID string) (pds.DeploymentsConnectionDetails, map[string]interface{}, error) {
 	dsClient := ds.apiClient.DeploymentsApi
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	status "net/http"
+	"net/url"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	"github.com/portworx/torpedo/drivers/pds/pdsutils"
+	"github.com/portworx/torpedo/pkg/log"
+)
+
+// Restore struct. The restore endpoints are not part of the vendored pds-api-go-client yet, so they
+// are called with the configuration and credentials of its API client.
+type Restore struct {
+	apiClient *pds.APIClient
+}
+
+// ModelsRestore is a restore of a backup job to a new deployment
+type ModelsRestore struct {
+	ID                 string `json:"id,omitempty"`
+	Name               string `json:"name,omitempty"`
+	BackupJobName      string `json:"backup_job_name,omitempty"`
+	DeploymentID       string `json:"deployment_id,omitempty"`
+	DeploymentTargetID string `json:"deployment_target_id,omitempty"`
+	NamespaceID        string `json:"namespace_id,omitempty"`
+	// Status is the status of the restore in the target cluster
+	Status string `json:"status,omitempty"`
+	// ErrorCode and ErrorMessage are set when the restore failed
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// createRestoreRequest is the body of the restore of a backup job
+type createRestoreRequest struct {
+	Name               string `json:"name"`
+	DeploymentTargetID string `json:"deployment_target_id"`
+	NamespaceID        string `json:"namespace_id"`
+}
+
+// RestoreBackupJob restores a backup job of a backup to a new deployment with the given name and returns the restore model.
+func (restore *Restore) RestoreBackupJob(backupID string, jobName string, name string, deploymentTargetID string, namespaceID string) (*ModelsRestore, error) {
+	body, err := json.Marshal(createRestoreRequest{
+		Name:               name,
+		DeploymentTargetID: deploymentTargetID,
+		NamespaceID:        namespaceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("/api/backups/%s/jobs/%s/restore", url.PathEscape(backupID), url.PathEscape(jobName))
+	restoreModel := &ModelsRestore{}
+	if err := restore.call(status.MethodPost, path, body, restoreModel); err != nil {
+		log.Errorf("Error when calling `ApiBackupsIdJobsNameRestorePost``: %v\n", err)
+		return nil, err
+	}
+	return restoreModel, nil
+}
+
+// GetRestore return restore model.
+func (restore *Restore) GetRestore(restoreID string) (*ModelsRestore, error) {
+	restoreModel := &ModelsRestore{}
+	if err := restore.call(status.MethodGet, fmt.Sprintf("/api/restores/%s", url.PathEscape(restoreID)), nil, restoreModel); err != nil {
+		log.Errorf("Error when calling `ApiRestoresIdGet``: %v\n", err)
+		return nil, err
+	}
+	return restoreModel, nil
+}
+
+// call calls an endpoint of the control plane and decodes its response into out
+func (restore *Restore) call(method string, path string, body []byte, out interface{}) error {
+	ctx, err := pdsutils.GetContext()
+	if err != nil {
+		log.Errorf("Error in getting context for api call: %v\n", err)
+		return err
+	}
+	cfg := restore.apiClient.GetConfig()
+	basePath, err := cfg.ServerURLWithContext(ctx, "RestoreApiService")
+	if err != nil {
+		return err
+	}
+	endpoint := url.URL{Scheme: cfg.Scheme, Host: cfg.Host, Path: basePath + path}
+	req, err := status.NewRequestWithContext(context.Background(), method, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range cfg.DefaultHeader {
+		req.Header.Set(key, value)
+	}
+	if auth, ok := ctx.Value(pds.ContextAPIKeys).(map[string]pds.APIKey); ok {
+		if apiKey, ok := auth["ApiKeyAuth"]; ok {
+			req.Header.Set("Authorization", apiKey.Prefix+" "+apiKey.Key)
+		}
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = status.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= status.StatusMultipleChoices {
+		log.Errorf("Full HTTP response: %v\n", res)
+		return fmt.Errorf("%s %s: %s: %s", method, path, res.Status, string(resBody))
+	}
+	return json.Unmarshal(resBody, out)
+}
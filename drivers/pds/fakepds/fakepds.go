@@ -0,0 +1,448 @@
+// Package fakepds is an in-memory PDS control plane, so that the PDS api components and the helpers
+// of drivers/pds/lib can run without a PDS deployment. It serves the password grant of the OpenID
+// Connect token endpoint used by pdsutils.GetContext and the subset of the PDS REST API used by
+// torpedo: accounts, tenants, projects, deployment targets, namespaces, data services, versions,
+// images, storage options, resource settings and application configuration templates, service
+// accounts, deployments and their status, backup credentials, targets, policies, backups, backup
+// jobs and restores.
+//
+// API requests need a token issued by the token endpoint. Status codes of the responses are the
+// ones expected by the api components, and errors are returned like PDS does, as a JSON object
+// with an error message. Responses to a request can be replaced by an error with Fail, to exercise
+// the error paths of the helpers.
+package fakepds
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pborman/uuid"
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+)
+
+// Health of deployments reported by their status
+const (
+	Healthy  = "Healthy"
+	Degraded = "Degraded"
+	Down     = "Down"
+)
+
+const (
+	// Realm is the realm of the issuer of the server
+	Realm = "pds"
+	// DefaultUser is the user of the default seed
+	DefaultUser = "pds-qa"
+	// DefaultPassword is the password of the user of the default seed
+	DefaultPassword = "pds-qa-password"
+	// ClientID is the client of the token requests
+	ClientID = "pds-torpedo"
+	// ClientSecret is the secret of the client
+	ClientSecret = "pds-torpedo-secret"
+)
+
+// Version is a version of a data service with its image builds
+type Version struct {
+	Name     string
+	Disabled bool
+	Builds   []string
+}
+
+// DataService is a data service with its versions. Resource settings and application configuration
+// templates of the seed are created for each data service.
+type DataService struct {
+	Name      string
+	ShortName string
+	Versions  []Version
+}
+
+// DeploymentTarget is a target cluster registered to the tenant of the seed
+type DeploymentTarget struct {
+	Name      string
+	ClusterID string
+	// Namespaces are the names of the namespaces of the target enabled for PDS
+	Namespaces []string
+}
+
+// Seed is the initial state of the server: an account with a tenant and a project, the data
+// services and the templates of the tenant
+type Seed struct {
+	User     string
+	Password string
+
+	Account string
+	Tenant  string
+	Project string
+	DNSZone string
+	// HelmChartVersion is the version of the PDS chart returned by the version endpoint
+	HelmChartVersion string
+
+	DataServices       []DataService
+	DeploymentTargets  []DeploymentTarget
+	StorageTemplates   []string
+	ResourceTemplates  []string
+	AppConfigTemplates []string
+	// ServiceAccounts are the service accounts of the tenant
+	ServiceAccounts []string
+	// Health is the health of new deployments, Healthy if empty
+	Health string
+}
+
+// DefaultSeed returns the QA account, tenant and project of torpedo with its default templates
+// and a few data services
+func DefaultSeed() Seed {
+	return Seed{
+		User:               DefaultUser,
+		Password:           DefaultPassword,
+		Account:            "Portworx-QA",
+		Tenant:             "Default",
+		Project:            "Default",
+		DNSZone:            "pds-qa.portworx.com",
+		HelmChartVersion:   "1.12.0",
+		StorageTemplates:   []string{"QaDefault"},
+		ResourceTemplates:  []string{"Small", "Medium"},
+		AppConfigTemplates: []string{"QaDefault"},
+		ServiceAccounts:    []string{"Default-AgentWriter"},
+		DataServices: []DataService{
+			{Name: "PostgreSQL", ShortName: "pg", Versions: []Version{
+				{Name: "14.6", Builds: []string{"6f5a4b1"}},
+				{Name: "13.9", Builds: []string{"3e1b2c4", "8a9d0f1"}},
+				{Name: "12.13", Disabled: true, Builds: []string{"c0ffee1"}},
+			}},
+			{Name: "Redis", ShortName: "rd", Versions: []Version{{Name: "7.0.5", Builds: []string{"a1b2c3d"}}}},
+			{Name: "Cassandra", ShortName: "cas", Versions: []Version{{Name: "4.0.6", Builds: []string{"d4e5f6a"}}}},
+			{Name: "RabbitMQ", ShortName: "rmq", Versions: []Version{{Name: "3.10.9", Builds: []string{"b7c8d9e"}}}},
+		},
+	}
+}
+
+// object is a resource of the control plane. Its model is the pds model returned by the API.
+type object struct {
+	id     string
+	kind   string
+	name   string
+	parent string
+	model  interface{}
+}
+
+// fault replaces the responses to the requests of a method and path
+type fault struct {
+	status  int
+	message string
+}
+
+// Server is an in-memory PDS control plane. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+	seed Seed
+
+	sync.Mutex
+	tokens  map[string]bool
+	objects map[string]*object
+	// order is the creation order of the objects, the order of lists
+	order []string
+	// health of deployments by id
+	health map[string]string
+	// jobs of backups by backup id
+	jobs   map[string][]pds.ControllersBackupJobStatus
+	faults map[string]fault
+}
+
+// New starts a server with the seed, which must be closed with Close
+func New(seed Seed) (*Server, error) {
+	if seed.Account == "" || seed.Tenant == "" || seed.Project == "" {
+		return nil, fmt.Errorf("account, tenant and project of the seed are required")
+	}
+	if seed.Health == "" {
+		seed.Health = Healthy
+	}
+	s := &Server{
+		seed:    seed,
+		tokens:  make(map[string]bool),
+		objects: make(map[string]*object),
+		health:  make(map[string]string),
+		jobs:    make(map[string][]pds.ControllersBackupJobStatus),
+		faults:  make(map[string]fault),
+	}
+	if err := s.populate(); err != nil {
+		return nil, err
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s, nil
+}
+
+func (s *Server) populate() error {
+	seed := s.seed
+	now := timestamp()
+	account := s.add("account", "", seed.Account, func(id string) interface{} {
+		return &pds.ModelsAccount{Id: &id, Name: &seed.Account, CreatedAt: &now}
+	})
+	tenant := s.add("tenant", account.id, seed.Tenant, func(id string) interface{} {
+		return &pds.ModelsTenant{Id: &id, Name: &seed.Tenant, AccountId: &account.id, CreatedAt: &now}
+	})
+	s.add("project", tenant.id, seed.Project, func(id string) interface{} {
+		return &pds.ModelsProject{Id: &id, Name: &seed.Project, AccountId: &account.id, TenantId: &tenant.id, CreatedAt: &now}
+	})
+	for _, name := range seed.ServiceAccounts {
+		name := name
+		s.add("service-account", tenant.id, name, func(id string) interface{} {
+			return &pds.ModelsServiceAccount{Id: &id, Name: &name, AccountId: &account.id, TenantId: &tenant.id, CreatedAt: &now}
+		})
+	}
+	for _, target := range seed.DeploymentTargets {
+		if target.ClusterID == "" {
+			return fmt.Errorf("cluster id of deployment target %s is required", target.Name)
+		}
+		target := target
+		created := s.add("deployment-target", tenant.id, target.Name, func(id string) interface{} {
+			status := "healthy"
+			return &pds.ModelsDeploymentTarget{Id: &id, Name: &target.Name, ClusterId: &target.ClusterID, Status: &status,
+				AccountId: &account.id, TenantId: &tenant.id, CreatedAt: &now, LastHealthCheck: &now}
+		})
+		for _, name := range target.Namespaces {
+			s.addNamespace(created, name)
+		}
+	}
+	for _, name := range seed.StorageTemplates {
+		name := name
+		s.add("storage-options-template", tenant.id, name, func(id string) interface{} {
+			repl, fg, fs := int32(3), false, "xfs"
+			return &pds.ModelsStorageOptionsTemplate{Id: &id, Name: &name, Repl: &repl, Fg: &fg, Fs: &fs,
+				AccountId: &account.id, TenantId: &tenant.id, CreatedAt: &now}
+		})
+	}
+	for _, ds := range seed.DataServices {
+		ds := ds
+		dataService := s.add("data-service", "", ds.Name, func(id string) interface{} {
+			return &pds.ModelsDataService{Id: &id, Name: &ds.Name, ShortName: &ds.ShortName, CreatedAt: &now}
+		})
+		for _, v := range ds.Versions {
+			v := v
+			version := s.add("version", dataService.id, v.Name, func(id string) interface{} {
+				enabled := !v.Disabled
+				return &pds.ModelsVersion{Id: &id, Name: &v.Name, Enabled: &enabled, DataServiceId: &dataService.id, CreatedAt: &now}
+			})
+			for _, build := range v.Builds {
+				build := build
+				s.add("image", version.id, build, func(id string) interface{} {
+					name, registry := strings.ToLower(ds.Name), "docker.io/portworx"
+					tag := fmt.Sprintf("%s-%s", v.Name, build)
+					return &pds.ModelsImage{Id: &id, Name: &name, Build: &build, Tag: &tag, Registry: &registry,
+						VersionId: &version.id, DataServiceId: &dataService.id, CreatedAt: &now}
+				})
+			}
+		}
+		for _, name := range seed.ResourceTemplates {
+			name := name
+			s.add("resource-settings-template", tenant.id, name, func(id string) interface{} {
+				cpu, memory, storage := "1", "2G", "10G"
+				return &pds.ModelsResourceSettingsTemplate{Id: &id, Name: &name, DataServiceId: &dataService.id,
+					CpuLimit: &cpu, CpuRequest: &cpu, MemoryLimit: &memory, MemoryRequest: &memory, StorageRequest: &storage,
+					AccountId: &account.id, TenantId: &tenant.id, CreatedAt: &now}
+			})
+		}
+		for _, name := range seed.AppConfigTemplates {
+			name := name
+			s.add("application-configuration-template", tenant.id, name, func(id string) interface{} {
+				return &pds.ModelsApplicationConfigurationTemplate{Id: &id, Name: &name, DataServiceId: &dataService.id,
+					ConfigItems: []pds.ModelsConfigItem{}, AccountId: &account.id, TenantId: &tenant.id, CreatedAt: &now}
+			})
+		}
+	}
+	return nil
+}
+
+// add adds an object of a kind with its model built from its new id
+func (s *Server) add(kind, parent, name string, model func(id string) interface{}) *object {
+	o := &object{id: uuid.New(), kind: kind, name: name, parent: parent}
+	o.model = model(o.id)
+	s.objects[o.id] = o
+	s.order = append(s.order, o.id)
+	return o
+}
+
+func (s *Server) addNamespace(target *object, name string) *object {
+	t := target.model.(*pds.ModelsDeploymentTarget)
+	now := timestamp()
+	return s.add("namespace", target.id, name, func(id string) interface{} {
+		status := "available"
+		return &pds.ModelsNamespace{Id: &id, Name: &name, Status: &status, DeploymentTargetId: &target.id,
+			AccountId: t.AccountId, TenantId: t.TenantId, CreatedAt: &now}
+	})
+}
+
+// get returns the object of a kind by id
+func (s *Server) get(kind, id string) (*object, bool) {
+	o, ok := s.objects[id]
+	if !ok || o.kind != kind {
+		return nil, false
+	}
+	return o, true
+}
+
+// list returns the objects of a kind with the parent, or all objects of the kind if parent is empty
+func (s *Server) list(kind, parent string) []*object {
+	var objects []*object
+	for _, id := range s.order {
+		if o, ok := s.objects[id]; ok && o.kind == kind && (parent == "" || o.parent == parent) {
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+// byName returns the object of a kind by name
+func (s *Server) byName(kind, name string) (*object, bool) {
+	for _, o := range s.list(kind, "") {
+		if o.name == name {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// remove removes an object and its descendants
+func (s *Server) remove(id string) {
+	delete(s.objects, id)
+	delete(s.health, id)
+	delete(s.jobs, id)
+	for _, o := range s.objects {
+		if o.parent == id {
+			s.remove(o.id)
+		}
+	}
+}
+
+// Issuer returns the OIDC issuer URL of the server
+func (s *Server) Issuer() string {
+	return fmt.Sprintf("%s/auth/realms/%s", s.URL, Realm)
+}
+
+// Env returns the env variables of pdsutils for the server and the user of its seed
+func (s *Server) Env() map[string]string {
+	return map[string]string{
+		"CONTROL_PLANE_URL": s.URL,
+		"PDS_ISSUER_URL":    s.Issuer(),
+		"PDS_USERNAME":      s.seed.User,
+		"PDS_PASSWORD":      s.seed.Password,
+		"PDS_CLIENT_ID":     ClientID,
+		"PDS_CLIENT_SECRET": ClientSecret,
+	}
+}
+
+// ID returns the id of the object of a kind by name, or an empty string if it does not exist.
+// Kinds are the collections of the API, like tenant, deployment-target or
+// resource-settings-template. Names of templates are shared by data services, so the id is the
+// one of the first data service of the seed.
+func (s *Server) ID(kind, name string) string {
+	s.Lock()
+	defer s.Unlock()
+	if o, ok := s.byName(kind, name); ok {
+		return o.id
+	}
+	return ""
+}
+
+// Template returns the id of the resource settings or application configuration template of a
+// data service by name, or an empty string if it does not exist
+func (s *Server) Template(kind, name, dataServiceID string) string {
+	s.Lock()
+	defer s.Unlock()
+	for _, o := range s.list(kind, "") {
+		if o.name != name {
+			continue
+		}
+		switch m := o.model.(type) {
+		case *pds.ModelsResourceSettingsTemplate:
+			if m.GetDataServiceId() == dataServiceID {
+				return o.id
+			}
+		case *pds.ModelsApplicationConfigurationTemplate:
+			if m.GetDataServiceId() == dataServiceID {
+				return o.id
+			}
+		}
+	}
+	return ""
+}
+
+// SetHealth sets the health of a deployment reported by its status
+func (s *Server) SetHealth(deploymentID, health string) error {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.get("deployment", deploymentID); !ok {
+		return fmt.Errorf("deployment %s not found", deploymentID)
+	}
+	s.health[deploymentID] = health
+	return nil
+}
+
+// SetBackupJobStatus sets the status of a job of a backup, like Succeeded or Failed
+func (s *Server) SetBackupJobStatus(backupID, jobName, status string) error {
+	s.Lock()
+	defer s.Unlock()
+	for i, job := range s.jobs[backupID] {
+		if job.GetName() == jobName {
+			s.jobs[backupID][i].Status = &status
+			return nil
+		}
+	}
+	return fmt.Errorf("job %s of backup %s not found", jobName, backupID)
+}
+
+// Deployments returns the deployments of the server by name
+func (s *Server) Deployments() map[string]*pds.ModelsDeployment {
+	s.Lock()
+	defer s.Unlock()
+	deployments := make(map[string]*pds.ModelsDeployment)
+	for _, o := range s.list("deployment", "") {
+		d := *o.model.(*pds.ModelsDeployment)
+		deployments[o.name] = &d
+	}
+	return deployments
+}
+
+// Fail replaces the responses to the requests of the method and path by an error with the status
+// code, until Recover. The path is the one of the request, like /api/tenants/{id}/projects with the
+// id of the tenant, and the method any method if empty.
+func (s *Server) Fail(method, path string, status int) {
+	s.Lock()
+	defer s.Unlock()
+	s.faults[method+" "+path] = fault{status: status, message: fmt.Sprintf("injected %d %s", status, http.StatusText(status))}
+}
+
+// Recover removes the faults of the server
+func (s *Server) Recover() {
+	s.Lock()
+	defer s.Unlock()
+	s.faults = make(map[string]fault)
+}
+
+// faultOf returns the fault of a request
+func (s *Server) faultOf(r *http.Request) (fault, bool) {
+	if f, ok := s.faults[r.Method+" "+r.URL.Path]; ok {
+		return f, true
+	}
+	f, ok := s.faults[" "+r.URL.Path]
+	return f, ok
+}
+
+// newToken returns a new opaque access token
+func (s *Server) newToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	s.tokens[token] = true
+	return token, nil
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,224 @@
+package fakepds
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	pdsapi "github.com/portworx/torpedo/drivers/pds/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newComponents starts a server with the default seed and a deployment target, and returns the
+// api components of its URL
+func newComponents(t *testing.T) (*Server, *pdsapi.Components) {
+	seed := DefaultSeed()
+	seed.DeploymentTargets = []DeploymentTarget{{Name: "qa-cluster", ClusterID: "kube-system-uid", Namespaces: []string{"pds-qa"}}}
+	server, err := New(seed)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	for key, value := range server.Env() {
+		t.Setenv(key, value)
+	}
+	endpoint, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	conf := pds.NewConfiguration()
+	conf.Host, conf.Scheme = endpoint.Host, endpoint.Scheme
+	return server, pdsapi.NewComponents(pds.NewAPIClient(conf))
+}
+
+func TestLookups(t *testing.T) {
+	server, components := newComponents(t)
+
+	accounts, err := components.Account.GetAccountsList()
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "Portworx-QA", accounts[0].GetName())
+	tenants, err := components.Tenant.GetTenantsList(accounts[0].GetId())
+	require.NoError(t, err)
+	require.Len(t, tenants, 1)
+	tenantID := tenants[0].GetId()
+	dns, err := components.Tenant.GetDNS(tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, "pds-qa.portworx.com", dns.GetDnsZone())
+	projects, err := components.Project.GetprojectsList(tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, server.ID("project", "Default"), projects[0].GetId())
+	helmVersion, err := components.APIVersion.GetHelmChartVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.12.0", helmVersion)
+
+	dataServices, err := components.DataService.ListDataServices()
+	require.NoError(t, err)
+	assert.Len(t, dataServices, 4)
+	postgresID := server.ID("data-service", "PostgreSQL")
+	versions, err := components.Version.ListDataServiceVersions(postgresID)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "13.9", versions[1].GetName())
+	assert.False(t, versions[2].GetEnabled())
+	images, err := components.Image.ListImages(versions[1].GetId())
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Equal(t, "8a9d0f1", images[1].GetBuild())
+	assert.Equal(t, postgresID, images[1].GetDataServiceId())
+
+	resourceTemplates, err := components.ResourceSettingsTemplate.ListTemplates(tenantID)
+	require.NoError(t, err)
+	assert.Len(t, resourceTemplates, 8)
+	storageTemplates, err := components.StorageSettingsTemplate.ListTemplates(tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, "QaDefault", storageTemplates[0].GetName())
+	created, err := components.AppConfigTemplate.CreateTemplate(tenantID, postgresID, "Tuned", []pds.ModelsConfigItem{})
+	require.NoError(t, err)
+	assert.Equal(t, created.GetId(), server.Template("application-configuration-template", "Tuned", postgresID))
+	_, err = components.AppConfigTemplate.CreateTemplate(tenantID, "unknown", "Tuned", []pds.ModelsConfigItem{})
+	assert.Error(t, err)
+}
+
+func TestDeployment(t *testing.T) {
+	server, components := newComponents(t)
+	projectID, targetID := server.ID("project", "Default"), server.ID("deployment-target", "qa-cluster")
+	redisID := server.ID("data-service", "Redis")
+
+	namespace, err := components.Namespace.CreateNamespace(targetID, "pds-redis")
+	require.NoError(t, err)
+	namespaces, err := components.Namespace.ListNamespaces(targetID)
+	require.NoError(t, err)
+	assert.Len(t, namespaces, 2)
+	_, err = components.Namespace.CreateNamespace(targetID, "pds-redis")
+	assert.Error(t, err)
+
+	deployment, err := components.DataServiceDeployment.CreateDeployment(projectID, targetID, "pds-qa.portworx.com", "redis-qa",
+		namespace.GetId(), server.Template("application-configuration-template", "QaDefault", redisID), server.ID("image", "a1b2c3d"), 3,
+		"ClusterIP", server.Template("resource-settings-template", "Small", redisID), server.ID("storage-options-template", "QaDefault"))
+	require.NoError(t, err)
+	assert.Equal(t, redisID, deployment.GetDataServiceId())
+	assert.Contains(t, server.Deployments(), "redis-qa")
+
+	status, res, err := components.DataServiceDeployment.GetDeploymentStatus(deployment.GetId())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, Healthy, status.GetHealth())
+	assert.Equal(t, int32(3), status.GetReadyReplicas())
+	require.NoError(t, server.SetHealth(deployment.GetId(), Degraded))
+	status, _, err = components.DataServiceDeployment.GetDeploymentStatus(deployment.GetId())
+	require.NoError(t, err)
+	assert.Equal(t, Degraded, status.GetHealth())
+	assert.Equal(t, int32(2), status.GetReadyReplicas())
+
+	details, cluster, err := components.DataServiceDeployment.GetConnectionDetails(deployment.GetId())
+	require.NoError(t, err)
+	assert.Len(t, details.GetNodes(), 3)
+	assert.Equal(t, deployment.GetClusterResourceName()+"-pds-redis.pds-qa.portworx.com", cluster["host"])
+	credentials, err := components.DataServiceDeployment.GetDeploymentCredentials(deployment.GetId())
+	require.NoError(t, err)
+	assert.NotEmpty(t, credentials.GetPassword())
+
+	updated, err := components.DataServiceDeployment.UpdateDeployment(deployment.GetId(), "", deployment.GetImageId(), 5,
+		server.Template("resource-settings-template", "Medium", redisID), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), updated.GetNodeCount())
+
+	res, err = components.DataServiceDeployment.DeleteDeployment(deployment.GetId())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+	_, err = components.DataServiceDeployment.GetDeployment(deployment.GetId())
+	assert.Error(t, err)
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	server, components := newComponents(t)
+	tenantID, projectID := server.ID("tenant", "Default"), server.ID("project", "Default")
+	targetID, namespaceID := server.ID("deployment-target", "qa-cluster"), server.ID("namespace", "pds-qa")
+	pgID := server.ID("data-service", "PostgreSQL")
+	deployment, err := components.DataServiceDeployment.CreateDeployment(projectID, targetID, "pds-qa.portworx.com", "pg-qa",
+		namespaceID, "", server.ID("image", "6f5a4b1"), 1, "ClusterIP",
+		server.Template("resource-settings-template", "Small", pgID), server.ID("storage-options-template", "QaDefault"))
+	require.NoError(t, err)
+
+	credential, err := components.BackupCredential.CreateS3BackupCredential(tenantID, "qa-s3", "access", "http://minio:9000", "secret")
+	require.NoError(t, err)
+	target, err := components.BackupTarget.CreateBackupTarget(tenantID, "qa-s3", credential.GetId(), "pds-qa", "us-east-1", "s3")
+	require.NoError(t, err)
+	states, err := components.BackupTarget.LisBackupsStateBelongToBackupTarget(target.GetId())
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "successful", states[0].GetState())
+	assert.Equal(t, targetID, states[0].GetDeploymentTargetId())
+
+	backup, err := components.Backup.CreateBackup(deployment.GetId(), target.GetId(), 5, true)
+	require.NoError(t, err)
+	jobs, err := components.BackupJob.ListBackupJobs(backup.GetId())
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "Succeeded", jobs[0].GetStatus())
+
+	restore, err := components.Restore.RestoreBackupJob(backup.GetId(), jobs[0].GetName(), "pg-qa-restored", targetID, namespaceID)
+	require.NoError(t, err)
+	restore, err = components.Restore.GetRestore(restore.ID)
+	require.NoError(t, err)
+	restored, err := components.DataServiceDeployment.GetDeployment(restore.DeploymentID)
+	require.NoError(t, err)
+	assert.Equal(t, "pg-qa-restored", restored.GetName())
+	assert.Equal(t, deployment.GetImageId(), restored.GetImageId())
+
+	require.NoError(t, server.SetBackupJobStatus(backup.GetId(), jobs[0].GetName(), "Failed"))
+	_, err = components.Restore.RestoreBackupJob(backup.GetId(), jobs[0].GetName(), "pg-qa-failed", targetID, namespaceID)
+	assert.Error(t, err)
+
+	policy, err := components.BackupPolicy.CreateBackupPolicy(tenantID, "hourly", 3, "0 * * * *", "full")
+	require.NoError(t, err)
+	_, err = components.DataServiceDeployment.UpdateDeploymentScheduledBackup(deployment.GetId(), policy.GetId(), target.GetId())
+	require.NoError(t, err)
+	backups, err := components.Backup.ListBackup(deployment.GetId())
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, "scheduled", backups[1].GetBackupType())
+	assert.Equal(t, "0 * * * *", backups[1].GetSchedule())
+	_, err = components.DataServiceDeployment.UpdateDeploymentScheduledBackup(deployment.GetId(), "", "")
+	require.NoError(t, err)
+	backups, err = components.Backup.ListBackup(deployment.GetId())
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+
+	_, err = components.BackupCredential.DeleteBackupCredential(credential.GetId())
+	assert.Error(t, err, "credential of a backup target")
+	res, err := components.BackupTarget.DeleteBackupTarget(target.GetId())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+	res, err = components.BackupCredential.DeleteBackupCredential(credential.GetId())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestErrors(t *testing.T) {
+	server, components := newComponents(t)
+	tenantID := server.ID("tenant", "Default")
+
+	_, err := components.Tenant.GetTenant("unknown")
+	assert.Error(t, err)
+
+	path := "/api/tenants/" + tenantID + "/resource-settings-templates"
+	server.Fail(http.MethodGet, path, http.StatusInternalServerError)
+	_, err = components.ResourceSettingsTemplate.ListTemplates(tenantID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "500")
+	_, err = components.StorageSettingsTemplate.ListTemplates(tenantID)
+	assert.NoError(t, err, "other paths are not failed")
+	server.Recover()
+	_, err = components.ResourceSettingsTemplate.ListTemplates(tenantID)
+	assert.NoError(t, err)
+
+	server.Fail("", "/api/accounts", http.StatusServiceUnavailable)
+	_, err = components.Account.GetAccountsList()
+	assert.Error(t, err)
+	server.Recover()
+
+	t.Setenv("PDS_PASSWORD", "wrong")
+	_, err = components.Account.GetAccountsList()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
@@ -0,0 +1,837 @@
+package fakepds
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	pdsapi "github.com/portworx/torpedo/drivers/pds/api"
+)
+
+// Kinds of the templates, which have the same endpoints
+const (
+	storageTemplate   = "storage-options-template"
+	resourceTemplate  = "resource-settings-template"
+	appConfigTemplate = "application-configuration-template"
+)
+
+// handler handles a request with the variables of its path
+type handler func(s *Server, w http.ResponseWriter, r *http.Request, vars []string)
+
+type route struct {
+	method string
+	path   []string
+	handle handler
+}
+
+// match returns the variables of a path matching the route, the {} segments of its path
+func (rt route) match(path []string) ([]string, bool) {
+	if len(path) != len(rt.path) {
+		return nil, false
+	}
+	var vars []string
+	for i, segment := range rt.path {
+		switch segment {
+		case "{}":
+			vars = append(vars, path[i])
+		case path[i]:
+		default:
+			return nil, false
+		}
+	}
+	return vars, true
+}
+
+func newRoute(method, path string, h handler) route {
+	return route{method: method, path: strings.Split(strings.Trim(path, "/"), "/"), handle: h}
+}
+
+var routes = []route{
+	newRoute(http.MethodGet, "/api/version", (*Server).getVersion),
+	newRoute(http.MethodGet, "/api/accounts", listAll("account")),
+	newRoute(http.MethodGet, "/api/accounts/{}", getObject("account")),
+	newRoute(http.MethodGet, "/api/accounts/{}/tenants", listChildren("account", "tenant")),
+	newRoute(http.MethodGet, "/api/tenants/{}", getObject("tenant")),
+	newRoute(http.MethodGet, "/api/tenants/{}/dns-details", (*Server).getDNSDetails),
+	newRoute(http.MethodGet, "/api/tenants/{}/projects", listChildren("tenant", "project")),
+	newRoute(http.MethodGet, "/api/projects/{}", getObject("project")),
+	newRoute(http.MethodGet, "/api/tenants/{}/service-accounts", listChildren("tenant", "service-account")),
+	newRoute(http.MethodGet, "/api/service-accounts/{}/token", (*Server).getServiceAccountToken),
+
+	newRoute(http.MethodGet, "/api/tenants/{}/deployment-targets", listChildren("tenant", "deployment-target")),
+	newRoute(http.MethodGet, "/api/projects/{}/deployment-targets", (*Server).listProjectDeploymentTargets),
+	newRoute(http.MethodGet, "/api/deployment-targets/{}", getObject("deployment-target")),
+	newRoute(http.MethodDelete, "/api/deployment-targets/{}", deleteObject("deployment-target", http.StatusNoContent)),
+	newRoute(http.MethodGet, "/api/deployment-targets/{}/namespaces", listChildren("deployment-target", "namespace")),
+	newRoute(http.MethodPost, "/api/deployment-targets/{}/namespaces", (*Server).createNamespace),
+	newRoute(http.MethodGet, "/api/namespaces/{}", getObject("namespace")),
+	newRoute(http.MethodDelete, "/api/namespaces/{}", deleteObject("namespace", http.StatusNoContent)),
+
+	newRoute(http.MethodGet, "/api/data-services", listAll("data-service")),
+	newRoute(http.MethodGet, "/api/data-services/{}", getObject("data-service")),
+	newRoute(http.MethodGet, "/api/data-services/{}/versions", listChildren("data-service", "version")),
+	newRoute(http.MethodGet, "/api/versions/{}", getObject("version")),
+	newRoute(http.MethodGet, "/api/versions/{}/images", listChildren("version", "image")),
+	newRoute(http.MethodGet, "/api/images/{}", getObject("image")),
+
+	newRoute(http.MethodGet, "/api/tenants/{}/storage-options-templates", listChildren("tenant", storageTemplate)),
+	newRoute(http.MethodPost, "/api/tenants/{}/storage-options-templates", (*Server).createStorageTemplate),
+	newRoute(http.MethodGet, "/api/storage-options-templates/{}", getObject(storageTemplate)),
+	newRoute(http.MethodDelete, "/api/storage-options-templates/{}", deleteObject(storageTemplate, http.StatusNoContent)),
+	newRoute(http.MethodGet, "/api/tenants/{}/resource-settings-templates", listChildren("tenant", resourceTemplate)),
+	newRoute(http.MethodPost, "/api/tenants/{}/resource-settings-templates", (*Server).createResourceTemplate),
+	newRoute(http.MethodGet, "/api/resource-settings-templates/{}", getObject(resourceTemplate)),
+	newRoute(http.MethodDelete, "/api/resource-settings-templates/{}", deleteObject(resourceTemplate, http.StatusNoContent)),
+	newRoute(http.MethodGet, "/api/tenants/{}/application-configuration-templates", listChildren("tenant", appConfigTemplate)),
+	newRoute(http.MethodPost, "/api/tenants/{}/application-configuration-templates", (*Server).createAppConfigTemplate),
+	newRoute(http.MethodGet, "/api/application-configuration-templates/{}", getObject(appConfigTemplate)),
+	newRoute(http.MethodDelete, "/api/application-configuration-templates/{}", deleteObject(appConfigTemplate, http.StatusNoContent)),
+
+	newRoute(http.MethodGet, "/api/projects/{}/deployments", listChildren("project", "deployment")),
+	newRoute(http.MethodPost, "/api/projects/{}/deployments", (*Server).createDeployment),
+	newRoute(http.MethodGet, "/api/deployments/{}", getObject("deployment")),
+	newRoute(http.MethodPut, "/api/deployments/{}", (*Server).updateDeployment),
+	newRoute(http.MethodDelete, "/api/deployments/{}", deleteObject("deployment", http.StatusAccepted)),
+	newRoute(http.MethodGet, "/api/deployments/{}/status", (*Server).getDeploymentStatus),
+	newRoute(http.MethodGet, "/api/deployments/{}/connection-info", (*Server).getConnectionInfo),
+	newRoute(http.MethodGet, "/api/deployments/{}/credentials", (*Server).getCredentials),
+
+	newRoute(http.MethodGet, "/api/tenants/{}/backup-credentials", listChildren("tenant", "backup-credential")),
+	newRoute(http.MethodPost, "/api/tenants/{}/backup-credentials", (*Server).createBackupCredential),
+	newRoute(http.MethodGet, "/api/backup-credentials/{}", getObject("backup-credential")),
+	newRoute(http.MethodDelete, "/api/backup-credentials/{}", (*Server).deleteBackupCredential),
+	newRoute(http.MethodGet, "/api/tenants/{}/backup-targets", listChildren("tenant", "backup-target")),
+	newRoute(http.MethodPost, "/api/tenants/{}/backup-targets", (*Server).createBackupTarget),
+	newRoute(http.MethodGet, "/api/backup-targets/{}", getObject("backup-target")),
+	newRoute(http.MethodDelete, "/api/backup-targets/{}", deleteObject("backup-target", http.StatusAccepted)),
+	newRoute(http.MethodGet, "/api/backup-targets/{}/states", (*Server).listBackupTargetStates),
+	newRoute(http.MethodGet, "/api/backup-targets/{}/backups", (*Server).listBackupTargetBackups),
+	newRoute(http.MethodGet, "/api/tenants/{}/backup-policies", listChildren("tenant", "backup-policy")),
+	newRoute(http.MethodPost, "/api/tenants/{}/backup-policies", (*Server).createBackupPolicy),
+	newRoute(http.MethodGet, "/api/backup-policies/{}", getObject("backup-policy")),
+	newRoute(http.MethodDelete, "/api/backup-policies/{}", deleteObject("backup-policy", http.StatusNoContent)),
+	newRoute(http.MethodGet, "/api/deployments/{}/backups", listChildren("deployment", "backup")),
+	newRoute(http.MethodPost, "/api/deployments/{}/backups", (*Server).createBackup),
+	newRoute(http.MethodGet, "/api/backups/{}", getObject("backup")),
+	newRoute(http.MethodDelete, "/api/backups/{}", deleteObject("backup", http.StatusNoContent)),
+	newRoute(http.MethodGet, "/api/backups/{}/jobs", (*Server).listBackupJobs),
+	newRoute(http.MethodDelete, "/api/backups/{}/jobs/{}", (*Server).deleteBackupJob),
+	newRoute(http.MethodPost, "/api/backups/{}/jobs/{}/restore", (*Server).restoreBackupJob),
+	newRoute(http.MethodGet, "/api/restores/{}", getObject("restore")),
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error like the PDS API
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, map[string]string{"message": fmt.Sprintf(format, args...)})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if f, ok := s.faultOf(r); ok {
+		writeError(w, f.status, f.message)
+		return
+	}
+	if r.URL.Path == fmt.Sprintf("/auth/realms/%s/protocol/openid-connect/token", Realm) {
+		s.serveToken(w, r)
+		return
+	}
+	if !s.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")] {
+		writeError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+		return
+	}
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	found := false
+	for _, rt := range routes {
+		vars, ok := rt.match(path)
+		if !ok {
+			continue
+		}
+		found = true
+		if rt.method == r.Method {
+			rt.handle(s, w, r, vars)
+			return
+		}
+	}
+	if found {
+		writeError(w, http.StatusMethodNotAllowed, "method %s is not allowed on %s", r.Method, r.URL.Path)
+		return
+	}
+	writeError(w, http.StatusNotFound, "%s not found", r.URL.Path)
+}
+
+// serveToken serves the password grant of the token endpoint, with the JSON body sent by pdsutils
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		GrantType    string `json:"grant_type"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		Username     string `json:"username"`
+		Password     string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+	if req.GrantType != "password" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type", "error_description": "Unsupported grant_type " + req.GrantType})
+		return
+	}
+	if req.ClientID != ClientID || req.ClientSecret != ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized_client", "error_description": "Invalid client credentials"})
+		return
+	}
+	if req.Username != s.seed.User || req.Password != s.seed.Password {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_grant", "error_description": "Invalid user credentials"})
+		return
+	}
+	token, err := s.newToken()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error", "error_description": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": token, "token_type": "Bearer", "expires_in": 3600})
+}
+
+// decode decodes the body of a request, or writes a bad request error
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: %v", err)
+		return false
+	}
+	return true
+}
+
+// lookup returns the object of a kind by id, or writes a not found error
+func (s *Server) lookup(w http.ResponseWriter, kind, id string) (*object, bool) {
+	o, ok := s.get(kind, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "%s %s not found", kind, id)
+	}
+	return o, ok
+}
+
+func writeList(w http.ResponseWriter, objects []*object) {
+	data := make([]interface{}, 0, len(objects))
+	for _, o := range objects {
+		data = append(data, o.model)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+func listAll(kind string) handler {
+	return func(s *Server, w http.ResponseWriter, r *http.Request, vars []string) {
+		writeList(w, s.list(kind, ""))
+	}
+}
+
+func listChildren(parentKind, kind string) handler {
+	return func(s *Server, w http.ResponseWriter, r *http.Request, vars []string) {
+		if _, ok := s.lookup(w, parentKind, vars[0]); ok {
+			writeList(w, s.list(kind, vars[0]))
+		}
+	}
+}
+
+func getObject(kind string) handler {
+	return func(s *Server, w http.ResponseWriter, r *http.Request, vars []string) {
+		if o, ok := s.lookup(w, kind, vars[0]); ok {
+			writeJSON(w, http.StatusOK, o.model)
+		}
+	}
+}
+
+func deleteObject(kind string, status int) handler {
+	return func(s *Server, w http.ResponseWriter, r *http.Request, vars []string) {
+		if _, ok := s.lookup(w, kind, vars[0]); ok {
+			s.remove(vars[0])
+			w.WriteHeader(status)
+		}
+	}
+}
+
+func (s *Server) getVersion(w http.ResponseWriter, r *http.Request, vars []string) {
+	apiVersion := "v1alpha1"
+	writeJSON(w, http.StatusOK, pds.ControllersAPIVersionResponse{ApiVersion: &apiVersion, HelmChartVersion: &s.seed.HelmChartVersion})
+}
+
+func (s *Server) getDNSDetails(w http.ResponseWriter, r *http.Request, vars []string) {
+	if _, ok := s.lookup(w, "tenant", vars[0]); ok {
+		writeJSON(w, http.StatusOK, pds.ModelsDNSDetails{DnsZone: &s.seed.DNSZone})
+	}
+}
+
+func (s *Server) getServiceAccountToken(w http.ResponseWriter, r *http.Request, vars []string) {
+	if o, ok := s.lookup(w, "service-account", vars[0]); ok {
+		token := fmt.Sprintf("%s-token-%s", o.name, o.id)
+		writeJSON(w, http.StatusOK, pds.ControllersServiceAccountTokenResponse{Token: &token})
+	}
+}
+
+func (s *Server) listProjectDeploymentTargets(w http.ResponseWriter, r *http.Request, vars []string) {
+	if project, ok := s.lookup(w, "project", vars[0]); ok {
+		writeList(w, s.list("deployment-target", project.parent))
+	}
+}
+
+func (s *Server) createNamespace(w http.ResponseWriter, r *http.Request, vars []string) {
+	target, ok := s.lookup(w, "deployment-target", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateNamespace
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.GetName() == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	for _, ns := range s.list("namespace", target.id) {
+		if ns.name == req.GetName() {
+			writeError(w, http.StatusConflict, "namespace %s already exists", ns.name)
+			return
+		}
+	}
+	writeJSON(w, http.StatusCreated, s.addNamespace(target, req.GetName()).model)
+}
+
+// uniqueTemplate writes a conflict error if a template of the kind with the name and data service
+// already exists in the tenant
+func (s *Server) uniqueTemplate(w http.ResponseWriter, kind, tenantID, name, dataServiceID string) bool {
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return false
+	}
+	for _, o := range s.list(kind, tenantID) {
+		id := ""
+		switch m := o.model.(type) {
+		case *pds.ModelsResourceSettingsTemplate:
+			id = m.GetDataServiceId()
+		case *pds.ModelsApplicationConfigurationTemplate:
+			id = m.GetDataServiceId()
+		}
+		if o.name == name && id == dataServiceID {
+			writeError(w, http.StatusConflict, "%s %s already exists", kind, name)
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) createStorageTemplate(w http.ResponseWriter, r *http.Request, vars []string) {
+	tenant, ok := s.lookup(w, "tenant", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateStorageOptionsTemplatesRequest
+	if !decode(w, r, &req) || !s.uniqueTemplate(w, storageTemplate, tenant.id, req.GetName(), "") {
+		return
+	}
+	accountID, tenantID := tenant.parent, tenant.id
+	now := timestamp()
+	created := s.add(storageTemplate, tenant.id, req.GetName(), func(id string) interface{} {
+		return &pds.ModelsStorageOptionsTemplate{Id: &id, Name: req.Name, Repl: req.Repl, Fg: req.Fg, Fs: req.Fs, Secure: req.Secure,
+			AccountId: &accountID, TenantId: &tenantID, CreatedAt: &now}
+	})
+	writeJSON(w, http.StatusCreated, created.model)
+}
+
+// dataService writes a bad request error if the data service of a request does not exist
+func (s *Server) dataService(w http.ResponseWriter, id string) bool {
+	if _, ok := s.get("data-service", id); !ok {
+		writeError(w, http.StatusBadRequest, "data service %s does not exist", id)
+		return false
+	}
+	return true
+}
+
+func (s *Server) createResourceTemplate(w http.ResponseWriter, r *http.Request, vars []string) {
+	tenant, ok := s.lookup(w, "tenant", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateResourceSettingsTemplatesRequest
+	if !decode(w, r, &req) || !s.dataService(w, req.GetDataServiceId()) ||
+		!s.uniqueTemplate(w, resourceTemplate, tenant.id, req.GetName(), req.GetDataServiceId()) {
+		return
+	}
+	accountID, tenantID := tenant.parent, tenant.id
+	now := timestamp()
+	created := s.add(resourceTemplate, tenant.id, req.GetName(), func(id string) interface{} {
+		return &pds.ModelsResourceSettingsTemplate{Id: &id, Name: req.Name, DataServiceId: req.DataServiceId,
+			CpuLimit: req.CpuLimit, CpuRequest: req.CpuRequest, MemoryLimit: req.MemoryLimit, MemoryRequest: req.MemoryRequest,
+			StorageRequest: req.StorageRequest, AccountId: &accountID, TenantId: &tenantID, CreatedAt: &now}
+	})
+	writeJSON(w, http.StatusCreated, created.model)
+}
+
+func (s *Server) createAppConfigTemplate(w http.ResponseWriter, r *http.Request, vars []string) {
+	tenant, ok := s.lookup(w, "tenant", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateApplicationConfigurationTemplatesRequest
+	if !decode(w, r, &req) || !s.dataService(w, req.GetDataServiceId()) ||
+		!s.uniqueTemplate(w, appConfigTemplate, tenant.id, req.GetName(), req.GetDataServiceId()) {
+		return
+	}
+	accountID, tenantID := tenant.parent, tenant.id
+	now := timestamp()
+	created := s.add(appConfigTemplate, tenant.id, req.GetName(), func(id string) interface{} {
+		return &pds.ModelsApplicationConfigurationTemplate{Id: &id, Name: req.Name, DataServiceId: req.DataServiceId,
+			ConfigItems: req.ConfigItems, AccountId: &accountID, TenantId: &tenantID, CreatedAt: &now}
+	})
+	writeJSON(w, http.StatusCreated, created.model)
+}
+
+// deploymentOf returns the model of a deployment object
+func deploymentOf(o *object) *pds.ModelsDeployment {
+	return o.model.(*pds.ModelsDeployment)
+}
+
+// addDeployment adds a deployment of the project, with a new cluster resource name
+func (s *Server) addDeployment(project *object, name string, target, namespace, image *object, nodeCount int32, dnsZone, serviceType string) *object {
+	p := project.model.(*pds.ModelsProject)
+	img := image.model.(*pds.ModelsImage)
+	ds, _ := s.get("data-service", img.GetDataServiceId())
+	now := timestamp()
+	created := s.add("deployment", project.id, name, func(id string) interface{} {
+		resourceName := fmt.Sprintf("%s-%s-%s", ds.model.(*pds.ModelsDataService).GetShortName(), name, id[:6])
+		state := "Available"
+		return &pds.ModelsDeployment{Id: &id, Name: &name, ClusterResourceName: &resourceName, State: &state,
+			DataServiceId: img.DataServiceId, VersionId: img.VersionId, ImageId: &image.id, NodeCount: &nodeCount,
+			DeploymentTargetId: &target.id, NamespaceId: &namespace.id, ProjectId: &project.id, TenantId: p.TenantId,
+			AccountId: p.AccountId, DnsZone: &dnsZone, ServiceType: &serviceType, CreatedAt: &now}
+	})
+	s.health[created.id] = s.seed.Health
+	return created
+}
+
+func (s *Server) createDeployment(w http.ResponseWriter, r *http.Request, vars []string) {
+	project, ok := s.lookup(w, "project", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateProjectDeployment
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.GetName() == "" || req.GetNodeCount() < 1 {
+		writeError(w, http.StatusBadRequest, "name and a node count are required")
+		return
+	}
+	target, ok := s.get("deployment-target", req.GetDeploymentTargetId())
+	if !ok {
+		writeError(w, http.StatusBadRequest, "deployment target %s does not exist", req.GetDeploymentTargetId())
+		return
+	}
+	namespace, ok := s.get("namespace", req.GetNamespaceId())
+	if !ok || namespace.parent != target.id {
+		writeError(w, http.StatusBadRequest, "namespace %s does not exist in deployment target %s", req.GetNamespaceId(), target.name)
+		return
+	}
+	image, ok := s.get("image", req.GetImageId())
+	if !ok {
+		writeError(w, http.StatusBadRequest, "image %s does not exist", req.GetImageId())
+		return
+	}
+	dataServiceID := image.model.(*pds.ModelsImage).GetDataServiceId()
+	if _, ok := s.get(resourceTemplate, req.GetResourceSettingsTemplateId()); !ok {
+		writeError(w, http.StatusBadRequest, "resource settings template %s does not exist", req.GetResourceSettingsTemplateId())
+		return
+	}
+	if _, ok := s.get(storageTemplate, req.GetStorageOptionsTemplateId()); !ok {
+		writeError(w, http.StatusBadRequest, "storage options template %s does not exist", req.GetStorageOptionsTemplateId())
+		return
+	}
+	if id := req.GetApplicationConfigurationTemplateId(); id != "" {
+		if t, ok := s.get(appConfigTemplate, id); !ok || t.model.(*pds.ModelsApplicationConfigurationTemplate).GetDataServiceId() != dataServiceID {
+			writeError(w, http.StatusBadRequest, "application configuration template %s does not exist for data service %s", id, dataServiceID)
+			return
+		}
+	}
+	created := s.addDeployment(project, req.GetName(), target, namespace, image, req.GetNodeCount(), req.GetDnsZone(), req.GetServiceType())
+	if req.ScheduledBackup != nil {
+		if !s.scheduleBackup(w, created, req.ScheduledBackup.GetBackupPolicyId(), req.ScheduledBackup.GetBackupTargetId()) {
+			s.remove(created.id)
+			return
+		}
+	}
+	writeJSON(w, http.StatusCreated, created.model)
+}
+
+// scheduleBackup adds the scheduled backup of a deployment with the policy and target, or writes a
+// bad request error if they do not exist
+func (s *Server) scheduleBackup(w http.ResponseWriter, deployment *object, policyID, targetID string) bool {
+	policy, ok := s.get("backup-policy", policyID)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "backup policy %s does not exist", policyID)
+		return false
+	}
+	if _, ok := s.get("backup-target", targetID); !ok {
+		writeError(w, http.StatusBadRequest, "backup target %s does not exist", targetID)
+		return false
+	}
+	schedule := ""
+	if schedules := policy.model.(*pds.ModelsBackupPolicy).Schedules; len(schedules) > 0 {
+		schedule = schedules[0].GetSchedule()
+	}
+	for _, backup := range s.list("backup", deployment.id) {
+		if backup.model.(*pds.ModelsBackup).GetBackupType() == "scheduled" {
+			s.remove(backup.id)
+		}
+	}
+	s.addBackup(deployment, targetID, "scheduled", schedule, 0)
+	return true
+}
+
+func (s *Server) updateDeployment(w http.ResponseWriter, r *http.Request, vars []string) {
+	deployment, ok := s.lookup(w, "deployment", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersUpdateDeploymentRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	d := deploymentOf(deployment)
+	if req.ImageId != nil {
+		image, ok := s.get("image", req.GetImageId())
+		if !ok || image.model.(*pds.ModelsImage).GetDataServiceId() != d.GetDataServiceId() {
+			writeError(w, http.StatusBadRequest, "image %s does not exist for data service %s", req.GetImageId(), d.GetDataServiceId())
+			return
+		}
+		d.ImageId, d.VersionId = req.ImageId, image.model.(*pds.ModelsImage).VersionId
+	}
+	if req.NodeCount != nil {
+		if req.GetNodeCount() < 1 {
+			writeError(w, http.StatusBadRequest, "node count must be positive")
+			return
+		}
+		d.NodeCount = req.NodeCount
+	}
+	if req.ScheduledBackup != nil {
+		if req.ScheduledBackup.GetBackupPolicyId() == "" && req.ScheduledBackup.GetBackupTargetId() == "" {
+			for _, backup := range s.list("backup", deployment.id) {
+				if backup.model.(*pds.ModelsBackup).GetBackupType() == "scheduled" {
+					s.remove(backup.id)
+				}
+			}
+		} else if !s.scheduleBackup(w, deployment, req.ScheduledBackup.GetBackupPolicyId(), req.ScheduledBackup.GetBackupTargetId()) {
+			return
+		}
+	}
+	now := timestamp()
+	d.UpdatedAt = &now
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (s *Server) getDeploymentStatus(w http.ResponseWriter, r *http.Request, vars []string) {
+	deployment, ok := s.lookup(w, "deployment", vars[0])
+	if !ok {
+		return
+	}
+	health := s.health[deployment.id]
+	replicas := deploymentOf(deployment).GetNodeCount()
+	ready := replicas
+	switch health {
+	case Degraded:
+		ready = replicas - 1
+	case Down:
+		ready = 0
+	}
+	initialized := "true"
+	writeJSON(w, http.StatusOK, pds.ControllersStatusResponse{Health: &health, Initialized: &initialized, Replicas: &replicas, ReadyReplicas: &ready})
+}
+
+// hostOf returns the host of a pod of a deployment, or of its service if pod is negative, in the
+// DNS zone of its namespace
+func (s *Server) hostOf(d *pds.ModelsDeployment, pod int32) string {
+	namespace := ""
+	if ns, ok := s.get("namespace", d.GetNamespaceId()); ok {
+		namespace = ns.name
+	}
+	name := d.GetClusterResourceName()
+	if pod >= 0 {
+		name = fmt.Sprintf("%s-%d", name, pod)
+	}
+	return fmt.Sprintf("%s-%s.%s", name, namespace, d.GetDnsZone())
+}
+
+func (s *Server) getConnectionInfo(w http.ResponseWriter, r *http.Request, vars []string) {
+	deployment, ok := s.lookup(w, "deployment", vars[0])
+	if !ok {
+		return
+	}
+	d := deploymentOf(deployment)
+	var nodes []string
+	for i := int32(0); i < d.GetNodeCount(); i++ {
+		nodes = append(nodes, s.hostOf(d, i))
+	}
+	writeJSON(w, http.StatusOK, pds.DeploymentsConnectionInfo{
+		ClusterDetails:    map[string]interface{}{"host": s.hostOf(d, -1)},
+		ConnectionDetails: &pds.DeploymentsConnectionDetails{Nodes: nodes},
+	})
+}
+
+func (s *Server) getCredentials(w http.ResponseWriter, r *http.Request, vars []string) {
+	if deployment, ok := s.lookup(w, "deployment", vars[0]); ok {
+		password := "pds-" + deployment.id[:8]
+		writeJSON(w, http.StatusOK, pds.DeploymentsCredentials{Password: &password})
+	}
+}
+
+func (s *Server) createBackupCredential(w http.ResponseWriter, r *http.Request, vars []string) {
+	tenant, ok := s.lookup(w, "tenant", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateBackupCredentialsRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	credentials := req.GetCredentials()
+	var credentialType string
+	switch {
+	case credentials.S3 != nil:
+		credentialType = "s3"
+	case credentials.S3Compatible != nil:
+		credentialType = "s3-compatible"
+	case credentials.Azure != nil:
+		credentialType = "azure"
+	case credentials.Google != nil:
+		credentialType = "google"
+	}
+	if req.GetName() == "" || credentialType == "" {
+		writeError(w, http.StatusBadRequest, "name and credentials are required")
+		return
+	}
+	accountID, tenantID := tenant.parent, tenant.id
+	now := timestamp()
+	created := s.add("backup-credential", tenant.id, req.GetName(), func(id string) interface{} {
+		return &pds.ModelsBackupCredentials{Id: &id, Name: req.Name, Type: &credentialType, AccountId: &accountID, TenantId: &tenantID, CreatedAt: &now}
+	})
+	writeJSON(w, http.StatusOK, created.model)
+}
+
+func (s *Server) deleteBackupCredential(w http.ResponseWriter, r *http.Request, vars []string) {
+	credential, ok := s.lookup(w, "backup-credential", vars[0])
+	if !ok {
+		return
+	}
+	for _, target := range s.list("backup-target", credential.parent) {
+		if target.model.(*pds.ModelsBackupTarget).GetBackupCredentialsId() == credential.id {
+			writeError(w, http.StatusConflict, "backup credential %s is used by backup target %s", credential.name, target.name)
+			return
+		}
+	}
+	s.remove(credential.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) createBackupTarget(w http.ResponseWriter, r *http.Request, vars []string) {
+	tenant, ok := s.lookup(w, "tenant", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateTenantBackupTarget
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.GetName() == "" || req.GetBucket() == "" {
+		writeError(w, http.StatusBadRequest, "name and bucket are required")
+		return
+	}
+	if _, ok := s.get("backup-credential", req.GetBackupCredentialsId()); !ok {
+		writeError(w, http.StatusBadRequest, "backup credential %s does not exist", req.GetBackupCredentialsId())
+		return
+	}
+	accountID, tenantID := tenant.parent, tenant.id
+	now := timestamp()
+	created := s.add("backup-target", tenant.id, req.GetName(), func(id string) interface{} {
+		return &pds.ModelsBackupTarget{Id: &id, Name: req.Name, BackupCredentialsId: req.BackupCredentialsId, Bucket: req.Bucket,
+			Region: req.Region, Type: req.Type, AccountId: &accountID, TenantId: &tenantID, CreatedAt: &now}
+	})
+	writeJSON(w, http.StatusOK, created.model)
+}
+
+// listBackupTargetStates lists the states of the backup target in the deployment targets of its
+// tenant, which are synced as soon as the target is created
+func (s *Server) listBackupTargetStates(w http.ResponseWriter, r *http.Request, vars []string) {
+	target, ok := s.lookup(w, "backup-target", vars[0])
+	if !ok {
+		return
+	}
+	data := []pds.ModelsBackupTargetState{}
+	for _, deploymentTarget := range s.list("deployment-target", target.parent) {
+		backupTargetID, deploymentTargetID, state := target.id, deploymentTarget.id, "successful"
+		data = append(data, pds.ModelsBackupTargetState{BackupTargetId: &backupTargetID, DeploymentTargetId: &deploymentTargetID, State: &state})
+	}
+	writeJSON(w, http.StatusOK, pds.ControllersPaginatedBackupTargetStates{Data: data})
+}
+
+func (s *Server) listBackupTargetBackups(w http.ResponseWriter, r *http.Request, vars []string) {
+	if _, ok := s.lookup(w, "backup-target", vars[0]); !ok {
+		return
+	}
+	var backups []*object
+	for _, backup := range s.list("backup", "") {
+		if backup.model.(*pds.ModelsBackup).GetBackupTargetId() == vars[0] {
+			backups = append(backups, backup)
+		}
+	}
+	writeList(w, backups)
+}
+
+func (s *Server) createBackupPolicy(w http.ResponseWriter, r *http.Request, vars []string) {
+	tenant, ok := s.lookup(w, "tenant", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateBackupPolicyRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.GetName() == "" || len(req.Schedules) == 0 {
+		writeError(w, http.StatusBadRequest, "name and schedules are required")
+		return
+	}
+	accountID, tenantID := tenant.parent, tenant.id
+	now := timestamp()
+	created := s.add("backup-policy", tenant.id, req.GetName(), func(id string) interface{} {
+		return &pds.ModelsBackupPolicy{Id: &id, Name: req.Name, Schedules: req.Schedules, AccountId: &accountID, TenantId: &tenantID, CreatedAt: &now}
+	})
+	writeJSON(w, http.StatusOK, created.model)
+}
+
+// addBackup adds a backup of a deployment. Ad-hoc backups have a succeeded backup job right away.
+func (s *Server) addBackup(deployment *object, targetID, backupType, schedule string, jobHistoryLimit int32) *object {
+	d := deploymentOf(deployment)
+	now := timestamp()
+	created := s.add("backup", deployment.id, fmt.Sprintf("%s-%s", d.GetClusterResourceName(), backupType), func(id string) interface{} {
+		level := "snapshot"
+		return &pds.ModelsBackup{Id: &id, BackupTargetId: &targetID, BackupType: &backupType, BackupLevel: &level, Schedule: &schedule,
+			JobHistoryLimit: &jobHistoryLimit, ClusterResourceName: d.ClusterResourceName, DataServiceId: d.DataServiceId,
+			DeploymentId: d.Id, DeploymentName: d.Name, DeploymentTargetId: d.DeploymentTargetId, NamespaceId: d.NamespaceId,
+			ProjectId: d.ProjectId, TenantId: d.TenantId, AccountId: d.AccountId, CreatedAt: &now}
+	})
+	if backupType == "adhoc" {
+		name, status := fmt.Sprintf("%s-%s", created.name, created.id[:6]), "Succeeded"
+		s.jobs[created.id] = append(s.jobs[created.id], pds.ControllersBackupJobStatus{Name: &name, Status: &status, StartTime: &now, CompletionTime: &now})
+	}
+	return created
+}
+
+func (s *Server) createBackup(w http.ResponseWriter, r *http.Request, vars []string) {
+	deployment, ok := s.lookup(w, "deployment", vars[0])
+	if !ok {
+		return
+	}
+	var req pds.ControllersCreateDeploymentBackup
+	if !decode(w, r, &req) {
+		return
+	}
+	if _, ok := s.get("backup-target", req.GetBackupTargetId()); !ok {
+		writeError(w, http.StatusBadRequest, "backup target %s does not exist", req.GetBackupTargetId())
+		return
+	}
+	if req.GetBackupType() != "adhoc" && req.GetBackupType() != "scheduled" {
+		writeError(w, http.StatusBadRequest, "unknown backup type %q", req.GetBackupType())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.addBackup(deployment, req.GetBackupTargetId(), req.GetBackupType(), req.GetSchedule(), req.GetJobHistoryLimit()).model)
+}
+
+func (s *Server) listBackupJobs(w http.ResponseWriter, r *http.Request, vars []string) {
+	if _, ok := s.lookup(w, "backup", vars[0]); ok {
+		writeJSON(w, http.StatusOK, pds.ControllersBackupJobsResponse{Data: append([]pds.ControllersBackupJobStatus{}, s.jobs[vars[0]]...)})
+	}
+}
+
+// job returns the index of a job of a backup, or writes a not found error
+func (s *Server) job(w http.ResponseWriter, backupID, name string) (int, bool) {
+	for i, job := range s.jobs[backupID] {
+		if job.GetName() == name {
+			return i, true
+		}
+	}
+	writeError(w, http.StatusNotFound, "job %s of backup %s not found", name, backupID)
+	return 0, false
+}
+
+func (s *Server) deleteBackupJob(w http.ResponseWriter, r *http.Request, vars []string) {
+	if _, ok := s.lookup(w, "backup", vars[0]); !ok {
+		return
+	}
+	if i, ok := s.job(w, vars[0], vars[1]); ok {
+		s.jobs[vars[0]] = append(s.jobs[vars[0]][:i], s.jobs[vars[0]][i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// restoreBackupJob restores a succeeded job of a backup to a new deployment, which is created
+// right away with the settings of the backed up deployment
+func (s *Server) restoreBackupJob(w http.ResponseWriter, r *http.Request, vars []string) {
+	backup, ok := s.lookup(w, "backup", vars[0])
+	if !ok {
+		return
+	}
+	i, ok := s.job(w, backup.id, vars[1])
+	if !ok {
+		return
+	}
+	if status := s.jobs[backup.id][i].GetStatus(); !strings.EqualFold(status, "succeeded") {
+		writeError(w, http.StatusConflict, "job %s of backup %s is %s", vars[1], backup.id, status)
+		return
+	}
+	var req struct {
+		Name               string `json:"name"`
+		DeploymentTargetID string `json:"deployment_target_id"`
+		NamespaceID        string `json:"namespace_id"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	source, ok := s.get("deployment", backup.parent)
+	if !ok {
+		writeError(w, http.StatusConflict, "deployment of backup %s does not exist anymore", backup.id)
+		return
+	}
+	target, ok := s.get("deployment-target", req.DeploymentTargetID)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "deployment target %s does not exist", req.DeploymentTargetID)
+		return
+	}
+	namespace, ok := s.get("namespace", req.NamespaceID)
+	if !ok || namespace.parent != target.id {
+		writeError(w, http.StatusBadRequest, "namespace %s does not exist in deployment target %s", req.NamespaceID, target.name)
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	d := deploymentOf(source)
+	project, _ := s.get("project", source.parent)
+	image, _ := s.get("image", d.GetImageId())
+	restored := s.addDeployment(project, req.Name, target, namespace, image, d.GetNodeCount(), d.GetDnsZone(), d.GetServiceType())
+	created := s.add("restore", backup.id, req.Name, func(id string) interface{} {
+		return &pdsapi.ModelsRestore{ID: id, Name: req.Name, BackupJobName: vars[1], DeploymentID: restored.id,
+			DeploymentTargetID: target.id, NamespaceID: namespace.id, Status: "Successful"}
+	})
+	writeJSON(w, http.StatusOK, created.model)
+}
@@ -0,0 +1,405 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	state "net/http"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	pdsapi "github.com/portworx/torpedo/drivers/pds/api"
+	torpedoerrors "github.com/portworx/torpedo/pkg/errors"
+	"github.com/portworx/torpedo/pkg/log"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// PDS backup const
+const (
+	envBackupBucket       = "PDS_BACKUP_BUCKET"
+	envBackupRegion       = "S3_REGION"
+	envBackupEndpoint     = "S3_ENDPOINT"
+	envBackupAccessKey    = "AWS_ACCESS_KEY_ID"
+	envBackupSecretKey    = "AWS_SECRET_ACCESS_KEY"
+	defaultBackupRegion   = "us-east-1"
+	defaultBackupEndpoint = "s3.amazonaws.com"
+	backupTargetTypeS3    = "s3"
+	backupPolicyTypeFull  = "full"
+	backupTypeScheduled   = "scheduled"
+	backupJobHistoryLimit = 5
+
+	backupTargetStateSuccessful = "successful"
+	backupJobStatusSucceeded    = "succeeded"
+	backupJobStatusFailed       = "failed"
+
+	backupTimeOut       = 30 * time.Minute
+	backupTimeInterval  = 10 * time.Second
+	restoreTimeOut      = 30 * time.Minute
+	restoreTimeInterval = 10 * time.Second
+)
+
+// BackupTargetConfig has the bucket and credentials of an S3 backup target
+type BackupTargetConfig struct {
+	Name      string
+	Bucket    string
+	Region    string
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+}
+
+// GetBackupTargetConfig returns the config of an S3 backup target with the given name from the env variables.
+// PDS_BACKUP_BUCKET, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required, S3_REGION and S3_ENDPOINT default to AWS.
+func GetBackupTargetConfig(name string) (*BackupTargetConfig, error) {
+	config := &BackupTargetConfig{
+		Name:      name,
+		Bucket:    GetAndExpectStringEnvVar(envBackupBucket),
+		Region:    GetAndExpectStringEnvVar(envBackupRegion),
+		Endpoint:  GetAndExpectStringEnvVar(envBackupEndpoint),
+		AccessKey: GetAndExpectStringEnvVar(envBackupAccessKey),
+		SecretKey: GetAndExpectStringEnvVar(envBackupSecretKey),
+	}
+	if config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("env variables %s, %s and %s are required for the backup target", envBackupBucket, envBackupAccessKey, envBackupSecretKey)
+	}
+	if config.Region == "" {
+		config.Region = defaultBackupRegion
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = defaultBackupEndpoint
+	}
+	return config, nil
+}
+
+// CreateS3BackupTarget creates the backup credential and S3 backup target of the config and waits for the target to be synced to the deployment target
+func CreateS3BackupTarget(tenantID, deploymentTargetID string, config *BackupTargetConfig) (*pds.ModelsBackupCredentials, *pds.ModelsBackupTarget, error) {
+	log.InfoD("Creating backup credential and target %v for bucket %v", config.Name, config.Bucket)
+	backupCredential, err := components.BackupCredential.CreateS3BackupCredential(tenantID, config.Name, config.AccessKey, config.Endpoint, config.SecretKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while creating backup credential %v: %v", config.Name, err)
+	}
+	backupTarget, err := components.BackupTarget.CreateBackupTarget(tenantID, config.Name, backupCredential.GetId(), config.Bucket, config.Region, backupTargetTypeS3)
+	if err != nil {
+		return backupCredential, nil, fmt.Errorf("error while creating backup target %v: %v", config.Name, err)
+	}
+	if err = WaitForBackupTargetSync(backupTarget.GetId(), deploymentTargetID); err != nil {
+		return backupCredential, backupTarget, err
+	}
+	return backupCredential, backupTarget, nil
+}
+
+// WaitForBackupTargetSync waits for the credentials of the backup target to be synced to the deployment target
+func WaitForBackupTargetSync(backupTargetID, deploymentTargetID string) error {
+	var syncErr error
+	err := wait.Poll(backupTimeInterval, backupTimeOut, func() (bool, error) {
+		states, err := components.BackupTarget.LisBackupsStateBelongToBackupTarget(backupTargetID)
+		if err != nil {
+			log.Warnf("An Error Occured while getting backup target states %v", err)
+			return false, nil
+		}
+		for _, backupTargetState := range states {
+			if backupTargetState.GetDeploymentTargetId() != deploymentTargetID {
+				continue
+			}
+			log.Infof("Backup target %v state - %v", backupTargetID, backupTargetState.GetState())
+			if backupTargetState.GetState() == backupTargetStateSuccessful {
+				return true, nil
+			}
+			if backupTargetState.GetErrorCode() != "" {
+				syncErr = fmt.Errorf("backup target %v failed to sync: %v: %v", backupTargetID, backupTargetState.GetErrorCode(), backupTargetState.GetErrorMessage())
+				return false, syncErr
+			}
+		}
+		return false, nil
+	})
+	if syncErr != nil {
+		return syncErr
+	}
+	if err != nil {
+		return fmt.Errorf("backup target %v is not synced to deployment target %v: %v", backupTargetID, deploymentTargetID, err)
+	}
+	return nil
+}
+
+// DeleteS3BackupTarget deletes the backup target and its backup credential
+func DeleteS3BackupTarget(backupCredential *pds.ModelsBackupCredentials, backupTarget *pds.ModelsBackupTarget) error {
+	if backupTarget != nil {
+		resp, err := components.BackupTarget.DeleteBackupTarget(backupTarget.GetId())
+		if err != nil {
+			return fmt.Errorf("error while deleting backup target %v: %v", backupTarget.GetName(), err)
+		}
+		if resp.StatusCode != state.StatusAccepted && resp.StatusCode != state.StatusNoContent && resp.StatusCode != state.StatusOK {
+			return fmt.Errorf("unexpected status code %v while deleting backup target %v", resp.StatusCode, backupTarget.GetName())
+		}
+	}
+	if backupCredential != nil {
+		resp, err := components.BackupCredential.DeleteBackupCredential(backupCredential.GetId())
+		if err != nil {
+			return fmt.Errorf("error while deleting backup credential %v: %v", backupCredential.GetName(), err)
+		}
+		if resp.StatusCode != state.StatusNoContent && resp.StatusCode != state.StatusOK {
+			return fmt.Errorf("unexpected status code %v while deleting backup credential %v", resp.StatusCode, backupCredential.GetName())
+		}
+	}
+	return nil
+}
+
+// CreateBackupPolicy creates a backup policy of full backups with the cron schedule, retaining the given number of backup jobs
+func CreateBackupPolicy(tenantID, name, schedule string, retentionCount int32) (*pds.ModelsBackupPolicy, error) {
+	log.InfoD("Creating backup policy %v with schedule %v", name, schedule)
+	backupPolicy, err := components.BackupPolicy.CreateBackupPolicy(tenantID, name, retentionCount, schedule, backupPolicyTypeFull)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating backup policy %v: %v", name, err)
+	}
+	return backupPolicy, nil
+}
+
+// DeleteBackupPolicy deletes the backup policy
+func DeleteBackupPolicy(backupPolicyID string) error {
+	_, err := components.BackupPolicy.DeleteBackupPolicy(backupPolicyID)
+	return err
+}
+
+// AttachBackupPolicy attaches the backup policy and target to the deployment and returns its scheduled backup
+func AttachBackupPolicy(deploymentID, backupPolicyID, backupTargetID string) (*pds.ModelsBackup, error) {
+	log.InfoD("Attaching backup policy %v to deployment %v", backupPolicyID, deploymentID)
+	_, err := components.DataServiceDeployment.UpdateDeploymentScheduledBackup(deploymentID, backupPolicyID, backupTargetID)
+	if err != nil {
+		return nil, fmt.Errorf("error while attaching backup policy %v to deployment %v: %v", backupPolicyID, deploymentID, err)
+	}
+	return GetScheduledBackup(deploymentID)
+}
+
+// DetachBackupPolicy detaches the backup policy and target from the deployment
+func DetachBackupPolicy(deploymentID string) error {
+	log.InfoD("Detaching backup policy from deployment %v", deploymentID)
+	_, err := components.DataServiceDeployment.UpdateDeploymentScheduledBackup(deploymentID, "", "")
+	return err
+}
+
+// GetScheduledBackup waits for the scheduled backup of the deployment and returns it
+func GetScheduledBackup(deploymentID string) (*pds.ModelsBackup, error) {
+	var scheduledBackup *pds.ModelsBackup
+	err := wait.Poll(backupTimeInterval, backupTimeOut, func() (bool, error) {
+		backups, err := components.Backup.ListBackup(deploymentID)
+		if err != nil {
+			log.Warnf("An Error Occured while listing backups %v", err)
+			return false, nil
+		}
+		for i := range backups {
+			if backups[i].GetBackupType() == backupTypeScheduled {
+				scheduledBackup = &backups[i]
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scheduled backup of deployment %v does not exist: %v", deploymentID, err)
+	}
+	return scheduledBackup, nil
+}
+
+// CreateAdhocBackup takes an ad-hoc backup of the deployment to the backup target and waits for its backup job to succeed
+func CreateAdhocBackup(deploymentID, backupTargetID string) (*pds.ModelsBackup, []pds.ControllersBackupJobStatus, error) {
+	log.InfoD("Taking adhoc backup of deployment %v", deploymentID)
+	backup, err := components.Backup.CreateBackup(deploymentID, backupTargetID, backupJobHistoryLimit, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while creating adhoc backup of deployment %v: %v", deploymentID, err)
+	}
+	jobs, err := WaitForBackupJobs(backup.GetId(), 1)
+	return backup, jobs, err
+}
+
+// WaitForBackupJobs waits for the backup to have the given number of succeeded backup jobs and returns them.
+// It fails as soon as a backup job of the backup failed.
+func WaitForBackupJobs(backupID string, count int) ([]pds.ControllersBackupJobStatus, error) {
+	var succeeded []pds.ControllersBackupJobStatus
+	var jobErr error
+	err := wait.Poll(backupTimeInterval, backupTimeOut, func() (bool, error) {
+		jobs, err := components.BackupJob.ListBackupJobs(backupID)
+		if err != nil {
+			log.Warnf("An Error Occured while listing backup jobs %v", err)
+			return false, nil
+		}
+		succeeded = nil
+		for _, job := range jobs {
+			log.Infof("Backup job %v status - %v", job.GetName(), job.GetStatus())
+			switch strings.ToLower(job.GetStatus()) {
+			case backupJobStatusSucceeded:
+				succeeded = append(succeeded, job)
+			case backupJobStatusFailed:
+				jobErr = fmt.Errorf("backup job %v of backup %v failed", job.GetName(), backupID)
+				return false, jobErr
+			}
+		}
+		return len(succeeded) >= count, nil
+	})
+	if jobErr != nil {
+		return nil, jobErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("backup %v has %d of %d succeeded backup jobs: %v", backupID, len(succeeded), count, err)
+	}
+	return succeeded, nil
+}
+
+// DeleteBackup deletes the backup and its backup jobs
+func DeleteBackup(backupID string) error {
+	_, err := components.Backup.DeleteBackup(backupID)
+	return err
+}
+
+// RestoreBackup restores the backup job of the backup to a new deployment with the given name in the namespace and validates the restored deployment
+func RestoreBackup(backupID, jobName, name, deploymentTargetID, namespaceID, namespace string) (*pds.ModelsDeployment, error) {
+	log.InfoD("Restoring backup job %v of backup %v to deployment %v", jobName, backupID, name)
+	restore, err := components.Restore.RestoreBackupJob(backupID, jobName, name, deploymentTargetID, namespaceID)
+	if err != nil {
+		return nil, fmt.Errorf("error while restoring backup job %v: %v", jobName, err)
+	}
+	restore, err = WaitForRestore(restore.ID)
+	if err != nil {
+		return nil, err
+	}
+	restoredDeployment, err := components.DataServiceDeployment.GetDeployment(restore.DeploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting restored deployment %v: %v", restore.DeploymentID, err)
+	}
+	if err = ValidateDataServiceDeployment(restoredDeployment, namespace); err != nil {
+		return restoredDeployment, fmt.Errorf("restored deployment %v is not healthy: %v", restoredDeployment.GetName(), err)
+	}
+	return restoredDeployment, nil
+}
+
+// WaitForRestore waits for the restore to create its deployment and returns it, or fails if the restore failed
+func WaitForRestore(restoreID string) (*pdsapi.ModelsRestore, error) {
+	var restore *pdsapi.ModelsRestore
+	var restoreErr error
+	err := wait.Poll(restoreTimeInterval, restoreTimeOut, func() (bool, error) {
+		r, err := components.Restore.GetRestore(restoreID)
+		if err != nil {
+			log.Warnf("An Error Occured while getting restore %v", err)
+			return false, nil
+		}
+		restore = r
+		log.Infof("Restore %v status - %v", restore.Name, restore.Status)
+		if restore.ErrorCode != "" {
+			restoreErr = fmt.Errorf("restore %v failed: %v: %v", restore.Name, restore.ErrorCode, restore.ErrorMessage)
+			return false, restoreErr
+		}
+		return restore.DeploymentID != "", nil
+	})
+	if restoreErr != nil {
+		return nil, restoreErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("restore %v did not create its deployment: %v", restoreID, err)
+	}
+	return restore, nil
+}
+
+// GetDeploymentVolumes returns the names of the volumes of the deployment
+func GetDeploymentVolumes(deployment *pds.ModelsDeployment, namespace string) ([]string, error) {
+	ss, err := k8sApps.GetStatefulSet(deployment.GetClusterResourceName(), namespace)
+	if err != nil {
+		return nil, err
+	}
+	pvcs, err := k8sApps.GetPVCsForStatefulSet(ss)
+	if err != nil {
+		return nil, err
+	}
+	var volumes []string
+	for _, pvc := range pvcs.Items {
+		volumes = append(volumes, pvc.Spec.VolumeName)
+	}
+	return volumes, nil
+}
+
+// rowCountCommand prints "<table> <rows>" lines of the data written by the workload generators of a
+// data service. The credentials of the deployment are in the PDS_USER and PDS_PASS env variables.
+type rowCountCommand struct {
+	script string
+	// allPods runs the script in every pod of the deployment and sums the rows of each table, for
+	// data services whose client only counts the rows of the pod it runs in
+	allPods bool
+}
+
+var rowCountCommands = map[string]rowCountCommand{
+	postgresql: {
+		script: `export PGPASSWORD="$PDS_PASS"
+for t in $(psql -h localhost -U "$PDS_USER" -d pds -At -c "SELECT schemaname || '.' || tablename FROM pg_tables WHERE schemaname NOT IN ('pg_catalog', 'information_schema')"); do
+  echo "$t $(psql -h localhost -U "$PDS_USER" -d pds -At -c "SELECT count(*) FROM $t")"
+done`,
+	},
+	cassandra: {
+		script: `echo "keyspace1.standard1 $(cqlsh -u "$PDS_USER" -p "$PDS_PASS" --request-timeout=600 -e "SELECT COUNT(*) FROM keyspace1.standard1" | awk '/^ *[0-9]+ *$/ {print $1}')"`,
+	},
+	redis: {
+		script:  `echo "keys $(redis-cli --no-auth-warning --user "$PDS_USER" -a "$PDS_PASS" DBSIZE)"`,
+		allPods: true,
+	},
+}
+
+// GetDeploymentRowCounts returns the rows of the tables written by the workload generators to the
+// deployment, counted with the client of the data service in the pods of the deployment. It returns
+// an *errors.ErrNotFound for data services it has no count for.
+func GetDeploymentRowCounts(deployment *pds.ModelsDeployment, dataServiceName string, namespace string) (map[string]int64, error) {
+	command, ok := rowCountCommands[dataServiceName]
+	if !ok {
+		return nil, &torpedoerrors.ErrNotFound{
+			ID:   dataServiceName,
+			Type: "RowCountCommand",
+		}
+	}
+	password, err := GetDeploymentCredentials(deployment.GetId())
+	if err != nil {
+		return nil, fmt.Errorf("error getting credentials of deployment %v: %v", deployment.GetName(), err)
+	}
+	pods, err := GetPodsFromK8sStatefulSet(deployment, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("deployment %v has no pods", deployment.GetName())
+	}
+	if !command.allPods {
+		pods = pods[:1]
+	}
+	script := fmt.Sprintf("export PDS_USER=pds PDS_PASS='%s'\n%s", strings.ReplaceAll(password, "'", `'\''`), command.script)
+	counts := make(map[string]int64)
+	for _, pod := range pods {
+		output, err := k8sCore.RunCommandInPod([]string{"sh", "-c", script}, pod.Name, pod.Spec.Containers[0].Name, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error counting rows in pod %v: %v", pod.Name, err)
+		}
+		podCounts, err := parseRowCounts(output)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing row counts of pod %v: %v", pod.Name, err)
+		}
+		for table, rows := range podCounts {
+			counts[table] += rows
+		}
+	}
+	return counts, nil
+}
+
+// parseRowCounts parses "<table> <rows>" lines
+func parseRowCounts(output string) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid row count line [%s]", line)
+		}
+		rows, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid row count line [%s]: %v", line, err)
+		}
+		counts[fields[0]] = rows
+	}
+	return counts, nil
+}
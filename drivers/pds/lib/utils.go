@@ -64,6 +64,11 @@ type Parameter struct {
 		NonAdminUsername string `json:"NonAdminUsername"`
 		NonAdminPassword string `json:"NonAdminPassword"`
 	} `json:"Users"`
+	Matrix struct {
+		ResourceTemplates []string `json:"ResourceTemplates"`
+		StorageTemplates  []string `json:"StorageTemplates"`
+		Operations        []string `json:"Operations"`
+	} `json:"Matrix"`
 }
 
 // ResourceSettingTemplate struct used to store template values
@@ -176,8 +181,8 @@ const (
 	esRallyImage          = "elastic/rally"
 	cbloadImage           = "portworx/pds-loadtests:couchbase-0.0.2"
 	pdsTpccImage          = "portworx/torpedo-tpcc-automation:v1"
-	redisStressImage      = "redis:latest"
-	rmqStressImage        = "pivotalrabbitmq/perf-test:latest"
+	redisStressImage      = "redis:7.0.11"
+	rmqStressImage        = "pivotalrabbitmq/perf-test:2.19.0"
 	postgresql            = "PostgreSQL"
 	cassandra             = "Cassandra"
 	elasticSearch         = "Elasticsearch"
@@ -511,6 +516,34 @@ func GetResourceTemplate(tenantID string, supportedDataService string) (string,
 	return resourceTemplateID, nil
 }
 
+// GetResourceTemplateID returns the id of the resource template of a data service by name
+func GetResourceTemplateID(tenantID, dataServiceID, templateName string) (string, error) {
+	resourceTemplates, err := components.ResourceSettingsTemplate.ListTemplates(tenantID)
+	if err != nil {
+		return "", err
+	}
+	for _, template := range resourceTemplates {
+		if template.GetName() == templateName && template.GetDataServiceId() == dataServiceID {
+			return template.GetId(), nil
+		}
+	}
+	return "", fmt.Errorf("resource template %v of data service %v does not exist", templateName, dataServiceID)
+}
+
+// GetStorageTemplateID returns the id of the storage template by name
+func GetStorageTemplateID(tenantID, templateName string) (string, error) {
+	storageTemplates, err := components.StorageSettingsTemplate.ListTemplates(tenantID)
+	if err != nil {
+		return "", err
+	}
+	for _, template := range storageTemplates {
+		if template.GetName() == templateName {
+			return template.GetId(), nil
+		}
+	}
+	return "", fmt.Errorf("storage template %v does not exist", templateName)
+}
+
 // GetAllDataserviceResourceTemplate get the resource template id's of supported dataservices and forms supported dataserviceNameIdMap
 func GetAllDataserviceResourceTemplate(tenantID string, supportedDataServices []string) (map[string]string, map[string]string, error) {
 	log.Infof("Get the resource template for each data services")
@@ -1773,7 +1806,7 @@ func CreateTpccWorkloads(dataServiceName string, deploymentID string, scalefacto
 		var wasMysqlConfigured bool
 		// Waiting for approx an hour to check if Mysql deployment comes up
 		for i := 1; i <= 80; i++ {
-			wasMysqlConfigured := SetupMysqlDatabaseForTpcc(dbUser, pdsPassword, dnsEndpoint, namespace)
+			wasMysqlConfigured = SetupMysqlDatabaseForTpcc(dbUser, pdsPassword, dnsEndpoint, namespace)
 			if wasMysqlConfigured {
 				log.InfoD("MySQL Deployment is successfully configured to run for TPCC Workload. Starting TPCC Workload Now.")
 				break
@@ -1790,7 +1823,10 @@ func CreateTpccWorkloads(dataServiceName string, deploymentID string, scalefacto
 		wasTpccRunSuccessful := RunTpccWorkload(dbUser, "password", dnsEndpoint, dbName,
 			timeToRun, numOfThreads, numOfCustomers, numOfWarehouses,
 			deploymentName, namespace, dataServiceName)
-		return wasTpccRunSuccessful, errors.New("TPCC Run failed. This could be a bug - please check manually")
+		if !wasTpccRunSuccessful {
+			return wasTpccRunSuccessful, errors.New("TPCC Run failed. This could be a bug - please check manually")
+		}
+		return wasTpccRunSuccessful, nil
 	}
 	return false, errors.New("TPCC run failed.")
 }
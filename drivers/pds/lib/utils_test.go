@@ -0,0 +1,163 @@
+package lib
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/torpedo/drivers/pds/fakepds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const clusterID = "5b1d0c7e-kube-system"
+
+// fakeCore returns the kube-system namespace of the target cluster
+type fakeCore struct {
+	core.Ops
+}
+
+func (fakeCore) GetNamespace(name string) (*corev1.Namespace, error) {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(clusterID)}}, nil
+}
+
+// setupPDSTest starts a control plane with the default seed and sets up the helpers with it
+func setupPDSTest(t *testing.T) (*fakepds.Server, string, string) {
+	seed := fakepds.DefaultSeed()
+	seed.DeploymentTargets = []fakepds.DeploymentTarget{{Name: "qa-cluster", ClusterID: clusterID, Namespaces: []string{"pds-qa"}}}
+	server, err := fakepds.New(seed)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	for key, value := range server.Env() {
+		t.Setenv(key, value)
+	}
+	defer func(ops core.Ops) { k8sCore = ops }(k8sCore)
+	k8sCore = fakeCore{}
+
+	accountID, tenantID, dnsZone, projectID, serviceType, cluster, err := SetupPDSTest(server.URL, "onprem", seed.Account, seed.Tenant, seed.Project)
+	require.NoError(t, err)
+	assert.Equal(t, server.ID("account", seed.Account), accountID)
+	assert.Equal(t, server.ID("tenant", seed.Tenant), tenantID)
+	assert.Equal(t, seed.DNSZone, dnsZone)
+	assert.Equal(t, server.ID("project", seed.Project), projectID)
+	assert.Equal(t, "ClusterIP", serviceType)
+	assert.Equal(t, clusterID, cluster)
+	return server, tenantID, projectID
+}
+
+func TestSetupPDSTest(t *testing.T) {
+	server, tenantID, _ := setupPDSTest(t)
+
+	targetID, err := GetDeploymentTargetID(clusterID, tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, server.ID("deployment-target", "qa-cluster"), targetID)
+
+	defer func(ops core.Ops) { k8sCore = ops }(k8sCore)
+	k8sCore = fakeCore{}
+	_, _, _, _, _, _, err = SetupPDSTest(server.URL, "onprem", "unknown", "Default", "Default")
+	assert.EqualError(t, err, "account unknown is not available")
+	server.Fail(http.MethodGet, "/api/accounts", http.StatusInternalServerError)
+	_, _, _, _, _, _, err = SetupPDSTest(server.URL, "onprem", "Portworx-QA", "Default", "Default")
+	assert.Error(t, err)
+}
+
+func TestTemplateLookups(t *testing.T) {
+	server, tenantID, _ := setupPDSTest(t)
+	redisID := server.ID("data-service", "Redis")
+
+	id, err := GetResourceTemplateID(tenantID, redisID, "Medium")
+	require.NoError(t, err)
+	assert.Equal(t, server.Template("resource-settings-template", "Medium", redisID), id)
+	_, err = GetResourceTemplateID(tenantID, redisID, "Large")
+	assert.EqualError(t, err, "resource template Large of data service "+redisID+" does not exist")
+	id, err = GetStorageTemplateID(tenantID, "QaDefault")
+	require.NoError(t, err)
+	assert.Equal(t, server.ID("storage-options-template", "QaDefault"), id)
+	_, err = GetStorageTemplateID(tenantID, "Replicated")
+	assert.Error(t, err)
+	id, err = GetAppConfTemplate(tenantID, "Redis")
+	require.NoError(t, err)
+	assert.Equal(t, server.Template("application-configuration-template", "QaDefault", redisID), id)
+
+	server.Fail(http.MethodGet, "/api/tenants/"+tenantID+"/storage-options-templates", http.StatusBadGateway)
+	_, err = GetStorageTemplateID(tenantID, "QaDefault")
+	assert.Error(t, err)
+}
+
+func TestVersionLookups(t *testing.T) {
+	server, _, _ := setupPDSTest(t)
+	postgresID := server.ID("data-service", "PostgreSQL")
+
+	versionID, imageID, builds, err := GetVersionsImage("13.9", "8a9d0f1", postgresID)
+	require.NoError(t, err)
+	assert.Equal(t, server.ID("version", "13.9"), versionID)
+	assert.Equal(t, server.ID("image", "8a9d0f1"), imageID)
+	assert.Contains(t, builds["13.9"], "8a9d0f1")
+	_, _, _, err = GetVersionsImage("13.9", "0000000", postgresID)
+	assert.EqualError(t, err, "version/build passed is not available")
+	_, _, _, err = GetVersionsImage("15.1", "6f5a4b1", postgresID)
+	assert.Error(t, err)
+
+	_, images, err := GetAllVersionsImages(postgresID)
+	require.NoError(t, err)
+	assert.Len(t, images[server.ID("version", "13.9")], 2)
+	assert.NotContains(t, images, server.ID("version", "12.13"), "disabled versions have no images")
+
+	server.Fail("", "/api/data-services/"+postgresID+"/versions", http.StatusNotFound)
+	_, _, _, err = GetVersionsImage("13.9", "8a9d0f1", postgresID)
+	assert.Error(t, err)
+}
+
+func TestDeploymentHealth(t *testing.T) {
+	server, tenantID, projectID := setupPDSTest(t)
+	redisID := server.ID("data-service", "Redis")
+	targetID, err := GetDeploymentTargetID(clusterID, tenantID)
+	require.NoError(t, err)
+	resourceTemplateID, err := GetResourceTemplateID(tenantID, redisID, "Small")
+	require.NoError(t, err)
+	storageTemplateID, err := GetStorageTemplateID(tenantID, "QaDefault")
+	require.NoError(t, err)
+	deployment, err := components.DataServiceDeployment.CreateDeployment(projectID, targetID, "pds-qa.portworx.com", "redis-qa",
+		server.ID("namespace", "pds-qa"), "", server.ID("image", "a1b2c3d"), 3, "ClusterIP", resourceTemplateID, storageTemplateID)
+	require.NoError(t, err)
+
+	require.NoError(t, WaitForPDSDeploymentToBeUp(deployment, 10*time.Millisecond, time.Second))
+	require.NoError(t, server.SetHealth(deployment.GetId(), fakepds.Down))
+	require.NoError(t, WaitForPDSDeploymentToBeDown(deployment, 10*time.Millisecond, time.Second))
+
+	host, err := GetDeploymentConnectionInfo(deployment.GetId())
+	require.NoError(t, err)
+	assert.Equal(t, deployment.GetClusterResourceName()+"-pds-qa.pds-qa.portworx.com", host)
+	password, err := GetDeploymentCredentials(deployment.GetId())
+	require.NoError(t, err)
+	assert.NotEmpty(t, password)
+
+	server.Fail(http.MethodGet, "/api/deployments/"+deployment.GetId()+"/status", http.StatusInternalServerError)
+	assert.Error(t, WaitForPDSDeploymentToBeUp(deployment, 10*time.Millisecond, time.Second))
+	server.Recover()
+
+	resp, err := DeleteDeployment(deployment.GetId())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	_, err = components.DataServiceDeployment.GetDeployment(deployment.GetId())
+	assert.Error(t, err)
+	assert.Empty(t, server.Deployments())
+}
+
+func TestParseRowCounts(t *testing.T) {
+	counts, err := parseRowCounts("public.pgbench_accounts 10000000\npublic.pgbench_branches 100\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"public.pgbench_accounts": 10000000, "public.pgbench_branches": 100}, counts)
+
+	_, err = parseRowCounts("keyspace1.standard1 \n")
+	assert.Error(t, err)
+	_, err = parseRowCounts("keys ERR\n")
+	assert.Error(t, err)
+
+	_, err = GetDeploymentRowCounts(nil, "RabbitMQ", "pds-qa")
+	assert.EqualError(t, err, "RowCountCommand with UID/Name: RabbitMQ not found")
+}
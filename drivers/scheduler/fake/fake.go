@@ -0,0 +1,2044 @@
+package fake
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1beta1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	apapi "github.com/libopenstorage/autopilot-api/pkg/apis/autopilot/v1alpha1"
+	"github.com/pborman/uuid"
+	"github.com/portworx/sched-ops/task"
+	"github.com/portworx/torpedo/drivers/node"
+	"github.com/portworx/torpedo/drivers/scheduler"
+	kube "github.com/portworx/torpedo/drivers/scheduler/k8s"
+	"github.com/portworx/torpedo/drivers/scheduler/spec"
+	"github.com/portworx/torpedo/drivers/volume"
+	"github.com/portworx/torpedo/pkg/errors"
+	"github.com/portworx/torpedo/pkg/log"
+	appsapi "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storageapi "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// SchedName is the name of the fake scheduler driver implementation
+	SchedName = "fake"
+	// NodeCountEnvVar is the env variable used to override the number of simulated worker nodes
+	NodeCountEnvVar = "FAKE_SCHED_NODE_COUNT"
+	// PodStartupDelayEnvVar is the env variable used to override how long pods stay pending
+	PodStartupDelayEnvVar = "FAKE_SCHED_POD_STARTUP_DELAY"
+	// PodTerminationDelayEnvVar is the env variable used to override how long pods stay terminating
+	PodTerminationDelayEnvVar = "FAKE_SCHED_POD_TERMINATION_DELAY"
+
+	defaultNodeCount           = 3
+	defaultPodStartupDelay     = 2 * time.Second
+	defaultPodTerminationDelay = time.Second
+	defaultTimeout             = 2 * time.Minute
+	defaultRetryInterval       = time.Second
+	autopilotNamespace         = "kube-system"
+	resizeIncrement            = 1024 * 1024 * 1024
+)
+
+// volumeProvisioner is implemented by volume drivers which have to be told about the volumes the
+// fake scheduler binds, as there is no external provisioner to create them
+type volumeProvisioner interface {
+	ProvisionVolume(volumeID string, size uint64, params map[string]string) error
+	ExpandVolume(volumeID string, size uint64) error
+	DeleteVolume(volumeID string) error
+}
+
+type fake struct {
+	specFactory         *spec.Factory
+	parser              *kube.K8s
+	volDriverName       string
+	podStartupDelay     time.Duration
+	podTerminationDelay time.Duration
+	version             string
+
+	lock            sync.Mutex
+	apps            map[string]*appState
+	pvcs            map[string]*pvcState
+	storageClasses  map[string]*storageapi.StorageClass
+	namespaces      map[string]map[string]string
+	secrets         map[string]map[string]string
+	snapshots       map[string]*snapv1.VolumeSnapshot
+	csiSnapClasses  map[string]*v1beta1.VolumeSnapshotClass
+	csiSnapshots    map[string]*v1beta1.VolumeSnapshot
+	autopilotRules  map[string]*apapi.AutopilotRule
+	actionApprovals map[string]*apapi.ActionApproval
+	nodeLabels      map[string]map[string]string
+	cordonedNodes   map[string]bool
+	stoppedNodes    map[string]bool
+	restartCounts   map[string]int32
+	events          map[string][]scheduler.Event
+}
+
+// Init initializes the in-memory cluster and registers the simulated nodes
+func (f *fake) Init(schedOpts scheduler.InitOptions) error {
+	var err error
+
+	f.volDriverName = schedOpts.VolDriverName
+	f.reset()
+
+	if f.podStartupDelay, err = durationFromEnv(PodStartupDelayEnvVar, defaultPodStartupDelay); err != nil {
+		return err
+	}
+	if f.podTerminationDelay, err = durationFromEnv(PodTerminationDelayEnvVar, defaultPodTerminationDelay); err != nil {
+		return err
+	}
+
+	nodeCount := defaultNodeCount
+	if val := os.Getenv(NodeCountEnvVar); val != "" {
+		if nodeCount, err = strconv.Atoi(val); err != nil || nodeCount < 1 {
+			return fmt.Errorf("invalid value %q for %s", val, NodeCountEnvVar)
+		}
+	}
+
+	if err = node.AddNode(node.Node{
+		Name:      "fake-master",
+		Addresses: []string{"10.0.0.1"},
+		Type:      node.TypeMaster,
+	}); err != nil {
+		return err
+	}
+	for i := 1; i <= nodeCount; i++ {
+		if err = node.AddNode(node.Node{
+			Name:                     fmt.Sprintf("fake-node-%d", i),
+			Addresses:                []string{fmt.Sprintf("10.0.1.%d", i)},
+			Type:                     node.TypeWorker,
+			IsStorageDriverInstalled: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if schedOpts.SpecDir != "" {
+		f.specFactory, err = spec.NewFactory(schedOpts.SpecDir, schedOpts.StorageProvisioner, f)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fake) reset() {
+	f.parser = &kube.K8s{}
+	f.apps = make(map[string]*appState)
+	f.pvcs = make(map[string]*pvcState)
+	f.storageClasses = make(map[string]*storageapi.StorageClass)
+	f.namespaces = make(map[string]map[string]string)
+	f.secrets = make(map[string]map[string]string)
+	f.snapshots = make(map[string]*snapv1.VolumeSnapshot)
+	f.csiSnapClasses = make(map[string]*v1beta1.VolumeSnapshotClass)
+	f.csiSnapshots = make(map[string]*v1beta1.VolumeSnapshot)
+	f.autopilotRules = make(map[string]*apapi.AutopilotRule)
+	f.actionApprovals = make(map[string]*apapi.ActionApproval)
+	f.nodeLabels = make(map[string]map[string]string)
+	f.cordonedNodes = make(map[string]bool)
+	f.stoppedNodes = make(map[string]bool)
+	f.restartCounts = make(map[string]int32)
+	f.events = make(map[string][]scheduler.Event)
+}
+
+func durationFromEnv(envVar string, defaultValue time.Duration) (time.Duration, error) {
+	val := os.Getenv(envVar)
+	if val == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for %s: %v", val, envVar, err)
+	}
+	return d, nil
+}
+
+// String returns the string name of this driver.
+func (f *fake) String() string {
+	return SchedName
+}
+
+// ParseSpecs parses the application specs using the same parser as the k8s driver,
+// so the fake consumes the regular torpedo spec directories
+func (f *fake) ParseSpecs(specDir, storageProvisioner string) ([]interface{}, error) {
+	return f.parser.ParseSpecs(specDir, storageProvisioner)
+}
+
+// IsNodeReady returns an error if the scheduler service was stopped on the node
+func (f *fake) IsNodeReady(n node.Node) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.stoppedNodes[n.Name] {
+		return &scheduler.ErrNodeNotReady{
+			Node:  n,
+			Cause: "scheduler service is stopped",
+		}
+	}
+	return nil
+}
+
+// GetNodesForApp returns the nodes on which the pods of the given context are placed
+func (f *fake) GetNodesForApp(ctx *scheduler.Context) ([]node.Node, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	app, err := f.getApp(ctx)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToGetNodesForApp{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+
+	nodeMap := node.GetNodesByName()
+	var result []node.Node
+	for _, ps := range app.livePods() {
+		n, ok := nodeMap[ps.pod.Spec.NodeName]
+		if !ok {
+			return nil, &scheduler.ErrFailedToGetNodesForApp{
+				App:   ctx.App,
+				Cause: fmt.Sprintf("node [%v] not present in node map", ps.pod.Spec.NodeName),
+			}
+		}
+		if !node.Contains(result, n) {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+// Schedule creates the simulated objects for every requested app and returns a context for each
+func (f *fake) Schedule(instanceID string, options scheduler.ScheduleOptions) ([]*scheduler.Context, error) {
+	apps, err := f.getAppSpecs(options.AppKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []*scheduler.Context
+	for _, app := range apps {
+		namespace := options.Namespace
+		if namespace == "" {
+			namespace = app.GetID(instanceID)
+		}
+
+		ctx := &scheduler.Context{
+			UID: instanceID,
+			App: &spec.AppSpec{
+				Key:     app.Key,
+				Enabled: app.Enabled,
+			},
+			ScheduleOptions: options,
+		}
+		ctx.ScheduleOptions.Namespace = namespace
+
+		f.lock.Lock()
+		specObjects, err := f.createSpecObjects(app, namespace, options)
+		if err == nil {
+			ctx.App.SpecList = specObjects
+			f.apps[ctx.GetID()] = &appState{
+				key:       app.Key,
+				namespace: namespace,
+				specs:     specObjects,
+				pods:      make(map[string]*podState),
+			}
+			err = f.reconcile(ctx.GetID(), options.Nodes)
+		}
+		f.lock.Unlock()
+		if err != nil {
+			return nil, &scheduler.ErrFailedToScheduleApp{
+				App:   app,
+				Cause: err.Error(),
+			}
+		}
+		contexts = append(contexts, ctx)
+	}
+	return contexts, nil
+}
+
+// AddTasks adds the specs of the given apps to an existing context
+func (f *fake) AddTasks(ctx *scheduler.Context, options scheduler.ScheduleOptions) error {
+	if ctx == nil {
+		return fmt.Errorf("context to add tasks to cannot be nil")
+	}
+	if len(options.AppKeys) == 0 {
+		return fmt.Errorf("need to specify list of applications to add to context")
+	}
+	apps, err := f.getAppSpecs(options.AppKeys)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	state, err := f.getApp(ctx)
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		specObjects, err := f.createSpecObjects(app, state.namespace, options)
+		if err != nil {
+			return &scheduler.ErrFailedToScheduleApp{
+				App:   app,
+				Cause: err.Error(),
+			}
+		}
+		state.specs = append(state.specs, specObjects...)
+		ctx.App.SpecList = append(ctx.App.SpecList, specObjects...)
+	}
+	return f.reconcile(ctx.GetID(), options.Nodes)
+}
+
+// ScheduleUninstall uninstalls helm charts from an existing context
+func (f *fake) ScheduleUninstall(ctx *scheduler.Context, options scheduler.ScheduleOptions) error {
+	return &errors.ErrNotSupported{
+		Type:      "Function",
+		Operation: "ScheduleUninstall()",
+	}
+}
+
+// RemoveAppSpecsByName removes the given specs from the context so they are skipped during validation
+func (f *fake) RemoveAppSpecsByName(ctx *scheduler.Context, removeSpecs []interface{}) error {
+	var remainSpecs []interface{}
+	for _, specObj := range ctx.App.SpecList {
+		removed := false
+		for _, removeSpec := range removeSpecs {
+			if sameObject(specObj, removeSpec) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			remainSpecs = append(remainSpecs, specObj)
+		}
+	}
+	ctx.App.SpecList = remainSpecs
+	return nil
+}
+
+// UpdateTasksID updates the instance ID of the context and the namespace of its specs
+func (f *fake) UpdateTasksID(ctx *scheduler.Context, id string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	oldID := ctx.GetID()
+	ctx.UID = id
+	for _, specObj := range ctx.App.SpecList {
+		setNamespace(specObj, id)
+	}
+	if app, ok := f.apps[oldID]; ok {
+		delete(f.apps, oldID)
+		f.apps[ctx.GetID()] = app
+	}
+	return nil
+}
+
+// WaitForRunning waits until every controller of the context has all its pods running
+func (f *fake) WaitForRunning(ctx *scheduler.Context, timeout, retryInterval time.Duration) error {
+	t := func() (interface{}, bool, error) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		app, err := f.getApp(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		f.advance()
+		nodeCount := len(f.schedulableNodes(nil))
+		for _, specObj := range app.specs {
+			kind, name, replicas := expectedReplicas(specObj, nodeCount)
+			if kind == "" {
+				continue
+			}
+			pods := app.ownedPods(kind, name)
+			ready := 0
+			for _, ps := range pods {
+				if ps.pod.Status.Phase == corev1.PodRunning || ps.pod.Status.Phase == corev1.PodSucceeded {
+					ready++
+				}
+			}
+			if ready < replicas {
+				return nil, true, fmt.Errorf("%s %s/%s has %d/%d ready pods", kind, app.namespace, name, ready, replicas)
+			}
+		}
+		return nil, false, nil
+	}
+
+	if _, err := task.DoRetryWithTimeout(t, timeout, retryInterval); err != nil {
+		return &scheduler.ErrFailedToValidateApp{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	log.Infof("[%v] Validated all pods are running", ctx.App.Key)
+	return nil
+}
+
+// Destroy starts terminating all pods of the context. Volumes are left in place.
+func (f *fake) Destroy(ctx *scheduler.Context, opts map[string]bool) error {
+	f.lock.Lock()
+	app, err := f.getApp(ctx)
+	if err != nil {
+		f.lock.Unlock()
+		return &scheduler.ErrFailedToDestroyApp{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	deleteAt := time.Now().Add(f.podTerminationDelay)
+	for _, ps := range app.livePods() {
+		ps.deleteAt = deleteAt
+		f.recordEvent(ps.pod.Name, newEvent("Pod", corev1.EventTypeNormal, "Killing"))
+	}
+	app.specs = nil
+	f.lock.Unlock()
+	log.Infof("[%v] Destroyed application", ctx.App.Key)
+
+	if value, ok := opts[scheduler.OptionsWaitForResourceLeakCleanup]; ok && value {
+		return f.WaitForDestroy(ctx, defaultTimeout)
+	} else if value, ok := opts[scheduler.OptionsWaitForDestroy]; ok && value {
+		return f.WaitForDestroy(ctx, defaultTimeout)
+	}
+	return nil
+}
+
+// WaitForDestroy waits until all pods of the context have terminated
+func (f *fake) WaitForDestroy(ctx *scheduler.Context, timeout time.Duration) error {
+	t := func() (interface{}, bool, error) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		f.advance()
+		app, ok := f.apps[ctx.GetID()]
+		if !ok {
+			return nil, false, nil
+		}
+		if len(app.pods) > 0 {
+			return nil, true, fmt.Errorf("%d pods are still terminating", len(app.pods))
+		}
+		delete(f.apps, ctx.GetID())
+		return nil, false, nil
+	}
+
+	if _, err := task.DoRetryWithTimeout(t, timeout, defaultRetryInterval); err != nil {
+		return &scheduler.ErrFailedToValidateAppDestroy{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// SelectiveWaitForTermination waits for pods of the context to terminate, except on the excluded nodes
+func (f *fake) SelectiveWaitForTermination(ctx *scheduler.Context, timeout time.Duration, excludeList []node.Node) error {
+	t := func() (interface{}, bool, error) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		app, err := f.getApp(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		f.advance()
+		for _, ps := range app.pods {
+			if !nodeInList(ps.pod.Spec.NodeName, excludeList) {
+				return nil, true, fmt.Errorf("pod %s on node %s is not terminated", ps.pod.Name, ps.pod.Spec.NodeName)
+			}
+		}
+		return nil, false, nil
+	}
+
+	if _, err := task.DoRetryWithTimeout(t, timeout, defaultRetryInterval); err != nil {
+		return &scheduler.ErrFailedToValidateAppDestroy{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// DeleteTasks deletes all pods of the context. Controllers recreate them as pending pods.
+func (f *fake) DeleteTasks(ctx *scheduler.Context, opts *scheduler.DeleteTasksOptions) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	app, err := f.getApp(ctx)
+	if err != nil {
+		return &scheduler.ErrFailedToDeleteTasks{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	deleteAt := time.Now().Add(f.podTerminationDelay)
+	for _, ps := range app.livePods() {
+		ps.deleteAt = deleteAt
+		f.recordEvent(ps.pod.Name, newEvent("Pod", corev1.EventTypeNormal, "Killing"))
+	}
+	return f.reconcile(ctx.GetID(), ctx.ScheduleOptions.Nodes)
+}
+
+// GetVolumeDriverVolumeName returns the ID of the volume backing the given PVC
+func (f *fake) GetVolumeDriverVolumeName(name string, namespace string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	ps, ok := f.pvcs[nsKey(namespace, name)]
+	if !ok {
+		return "", &errors.ErrNotFound{
+			ID:   nsKey(namespace, name),
+			Type: "PersistentVolumeClaim",
+		}
+	}
+	return ps.volumeID, nil
+}
+
+// GetVolumeParameters returns the storage class parameters for every volume of the context
+func (f *fake) GetVolumeParameters(ctx *scheduler.Context) (map[string]map[string]string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pvcs, err := f.pvcsForApp(ctx)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToGetVolumeParameters{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	result := make(map[string]map[string]string)
+	for _, ps := range pvcs {
+		params := make(map[string]string)
+		for k, v := range ps.params {
+			params[k] = v
+		}
+		params["pvc_name"] = ps.pvc.Name
+		params["pvc_namespace"] = ps.pvc.Namespace
+		result[ps.volumeID] = params
+	}
+	return result, nil
+}
+
+// ValidateVolumes checks that all PVCs of the context are bound
+func (f *fake) ValidateVolumes(ctx *scheduler.Context, timeout, retryInterval time.Duration, options *scheduler.VolumeOptions) error {
+	t := func() (interface{}, bool, error) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		pvcs, err := f.pvcsForApp(ctx)
+		if err != nil {
+			return nil, true, err
+		}
+		for _, ps := range pvcs {
+			if ps.pvc.Status.Phase != corev1.ClaimBound {
+				return nil, true, fmt.Errorf("PVC %s/%s is in phase %s", ps.pvc.Namespace, ps.pvc.Name, ps.pvc.Status.Phase)
+			}
+		}
+		return nil, false, nil
+	}
+
+	if _, err := task.DoRetryWithTimeout(t, timeout, retryInterval); err != nil {
+		return &scheduler.ErrFailedToValidateStorage{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+// ValidateTopologyLabel checks that the pods of the context landed on nodes matching the topology labels
+func (f *fake) ValidateTopologyLabel(ctx *scheduler.Context) error {
+	if len(ctx.ScheduleOptions.TopologyLabels) == 0 {
+		return nil
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	app, err := f.getApp(ctx)
+	if err != nil {
+		return &scheduler.ErrFailedToValidateTopologyLabel{
+			NameSpace: ctx.ScheduleOptions.Namespace,
+			Cause:     err,
+		}
+	}
+	for _, ps := range app.livePods() {
+		nodeLabels := f.nodeLabels[ps.pod.Spec.NodeName]
+		matched := false
+		for _, topology := range ctx.ScheduleOptions.TopologyLabels {
+			if labels.SelectorFromSet(topology).Matches(labels.Set(nodeLabels)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &scheduler.ErrTopologyLabelMismatch{
+				PodName: ps.pod.Name,
+				Cause:   fmt.Sprintf("node %s does not have any of the labels %v", ps.pod.Spec.NodeName, ctx.ScheduleOptions.TopologyLabels),
+			}
+		}
+	}
+	return nil
+}
+
+// GetSnapShotData returns the snapshot data bound to the given snapshot
+func (f *fake) GetSnapShotData(ctx *scheduler.Context, snapshotName, snapshotNameSpace string) (*snapv1.VolumeSnapshotData, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	snap, ok := f.snapshots[nsKey(snapshotNameSpace, snapshotName)]
+	if !ok {
+		return nil, &scheduler.ErrFailedToGetSnapShotData{
+			App:   ctx.App,
+			Cause: fmt.Sprintf("snapshot %s/%s not found", snapshotNameSpace, snapshotName),
+		}
+	}
+	return &snapv1.VolumeSnapshotData{
+		Metadata: metav1.ObjectMeta{
+			Name:              snap.Spec.SnapshotDataName,
+			CreationTimestamp: snap.Status.CreationTimestamp,
+		},
+		Spec: snapv1.VolumeSnapshotDataSpec{
+			VolumeSnapshotRef: &corev1.ObjectReference{
+				Kind:      "VolumeSnapshot",
+				Name:      snapshotName,
+				Namespace: snapshotNameSpace,
+			},
+		},
+		Status: snapv1.VolumeSnapshotDataStatus{
+			CreationTimestamp: snap.Status.CreationTimestamp,
+			Conditions:        []snapv1.VolumeSnapshotDataCondition{{Type: snapv1.VolumeSnapshotDataConditionReady, Status: corev1.ConditionTrue}},
+		},
+	}, nil
+}
+
+// DeleteSnapShot deletes the given snapshot
+func (f *fake) DeleteSnapShot(ctx *scheduler.Context, snapshotName, snapshotNameSpace string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := nsKey(snapshotNameSpace, snapshotName)
+	if _, ok := f.snapshots[key]; !ok {
+		return &scheduler.ErrFailedToDeleteSnapshot{
+			Name:  snapshotNameSpace,
+			Cause: fmt.Errorf("snapshot %s not found", snapshotName),
+		}
+	}
+	delete(f.snapshots, key)
+	return nil
+}
+
+// GetSnapshotsInNameSpace returns all snapshots in the given namespace
+func (f *fake) GetSnapshotsInNameSpace(ctx *scheduler.Context, snapshotNameSpace string) (*snapv1.VolumeSnapshotList, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	list := &snapv1.VolumeSnapshotList{}
+	for _, key := range sortedKeys(f.snapshots) {
+		if snap := f.snapshots[key]; snap.Metadata.Namespace == snapshotNameSpace {
+			list.Items = append(list.Items, *snap.DeepCopy())
+		}
+	}
+	return list, nil
+}
+
+// DeleteVolumes deletes the PVCs of the context and returns the deleted volumes
+func (f *fake) DeleteVolumes(ctx *scheduler.Context, options *scheduler.VolumeOptions) ([]*volume.Volume, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pvcs, err := f.pvcsForApp(ctx)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToDestroyStorage{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	var vols []*volume.Volume
+	p := f.provisioner()
+	for _, ps := range pvcs {
+		vols = append(vols, toVolume(ps))
+		delete(f.pvcs, nsKey(ps.pvc.Namespace, ps.pvc.Name))
+		if p != nil {
+			if err := p.DeleteVolume(ps.volumeID); err != nil {
+				log.Warnf("Failed to delete volume %s of PVC %s/%s: %v", ps.volumeID, ps.pvc.Namespace, ps.pvc.Name, err)
+			}
+		}
+	}
+	if options == nil || !options.SkipClusterScopedObjects {
+		for _, specObj := range ctx.App.SpecList {
+			if sc, ok := specObj.(*storageapi.StorageClass); ok {
+				delete(f.storageClasses, sc.Name)
+			}
+		}
+	}
+	return vols, nil
+}
+
+// GetVolumes returns the volumes of the context
+func (f *fake) GetVolumes(ctx *scheduler.Context) ([]*volume.Volume, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pvcs, err := f.pvcsForApp(ctx)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToGetStorage{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	var vols []*volume.Volume
+	for _, ps := range pvcs {
+		vols = append(vols, toVolume(ps))
+	}
+	return vols, nil
+}
+
+// GetPureVolumes returns the volumes of the context whose storage class uses the given pure backend
+func (f *fake) GetPureVolumes(ctx *scheduler.Context, pureVolType string) ([]*volume.Volume, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pvcs, err := f.pvcsForApp(ctx)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToGetStorage{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	var vols []*volume.Volume
+	for _, ps := range pvcs {
+		if ps.params["backend"] == pureVolType {
+			vols = append(vols, toVolume(ps))
+		}
+	}
+	return vols, nil
+}
+
+// GetPodsForPVC returns the pods which mount the given PVC
+func (f *fake) GetPodsForPVC(pvcname, namespace string) ([]corev1.Pod, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.advance()
+	var pods []corev1.Pod
+	for _, id := range sortedKeys(f.apps) {
+		app := f.apps[id]
+		if app.namespace != namespace {
+			continue
+		}
+		for _, ps := range app.livePods() {
+			for _, claim := range claimNames(ps.pod) {
+				if claim == pvcname {
+					pods = append(pods, *ps.pod.DeepCopy())
+				}
+			}
+		}
+	}
+	return pods, nil
+}
+
+// GetPodLog returns the simulated logs of every pod of the context
+func (f *fake) GetPodLog(ctx *scheduler.Context, sinceSeconds int64, containerName string) (map[string]string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	app, err := f.getApp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logs := make(map[string]string)
+	for _, ps := range app.livePods() {
+		var lines []string
+		for _, event := range f.events[ps.pod.Name] {
+			lines = append(lines, fmt.Sprintf("%s %s", event.EventTime.Format(time.RFC3339), event.Message))
+		}
+		logs[ps.pod.Name] = strings.Join(lines, "\n")
+	}
+	return logs, nil
+}
+
+// ResizeVolume grows every PVC of the context by 1GiB
+func (f *fake) ResizeVolume(ctx *scheduler.Context, configMap string) ([]*volume.Volume, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pvcs, err := f.pvcsForApp(ctx)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToResizeStorage{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	var vols []*volume.Volume
+	p := f.provisioner()
+	for _, ps := range pvcs {
+		vol := toVolume(ps)
+		newSize := resource.NewQuantity(int64(vol.Size)+resizeIncrement, resource.BinarySI)
+		if p != nil {
+			if err := p.ExpandVolume(ps.volumeID, uint64(newSize.Value())); err != nil {
+				return nil, &scheduler.ErrFailedToResizeStorage{
+					App:   ctx.App,
+					Cause: err.Error(),
+				}
+			}
+		}
+		ps.pvc.Spec.Resources.Requests[corev1.ResourceStorage] = *newSize
+		ps.pvc.Status.Capacity[corev1.ResourceStorage] = *newSize
+		vol.RequestedSize = uint64(newSize.Value())
+		vols = append(vols, vol)
+	}
+	return vols, nil
+}
+
+// GetSnapshots returns the snapshots created from the specs of the context
+func (f *fake) GetSnapshots(ctx *scheduler.Context) ([]*volume.Snapshot, error) {
+	var snaps []*volume.Snapshot
+	for _, specObj := range ctx.App.SpecList {
+		if obj, ok := specObj.(*snapv1.VolumeSnapshot); ok {
+			f.lock.Lock()
+			snap, exists := f.snapshots[nsKey(obj.Metadata.Namespace, obj.Metadata.Name)]
+			f.lock.Unlock()
+			if !exists {
+				return nil, &scheduler.ErrFailedToGetSnapShot{
+					App:   ctx.App,
+					Cause: fmt.Sprintf("snapshot %s/%s not found", obj.Metadata.Namespace, obj.Metadata.Name),
+				}
+			}
+			snaps = append(snaps, &volume.Snapshot{
+				ID:        string(snap.Metadata.UID),
+				Name:      snap.Metadata.Name,
+				Namespace: snap.Metadata.Namespace,
+			})
+		}
+	}
+	return snaps, nil
+}
+
+// Describe returns a human readable dump of the simulated state of the context
+func (f *fake) Describe(ctx *scheduler.Context) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	app, err := f.getApp(ctx)
+	if err != nil {
+		return "", err
+	}
+	f.advance()
+	var b strings.Builder
+	fmt.Fprintf(&b, "App: %s Namespace: %s\n", app.key, app.namespace)
+	for _, ps := range app.livePods() {
+		fmt.Fprintf(&b, "  Pod: %s Node: %s Phase: %s Owner: %s/%s\n",
+			ps.pod.Name, ps.pod.Spec.NodeName, ps.pod.Status.Phase, ps.ownerKind, ps.ownerName)
+	}
+	pvcs, _ := f.pvcsForApp(ctx)
+	for _, ps := range pvcs {
+		fmt.Fprintf(&b, "  PVC: %s Volume: %s Size: %d Phase: %s\n",
+			ps.pvc.Name, ps.volumeID, pvcSize(ps.pvc), ps.pvc.Status.Phase)
+	}
+	return b.String(), nil
+}
+
+// ScaleApplication updates the replica count of every scalable controller of the context
+func (f *fake) ScaleApplication(ctx *scheduler.Context, scaleFactorMap map[string]int32) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	app, err := f.getApp(ctx)
+	if err != nil {
+		return &scheduler.ErrFailedToUpdateApp{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	for _, specObj := range app.specs {
+		switch obj := specObj.(type) {
+		case *appsapi.Deployment:
+			if replicas, ok := scaleFactorMap[obj.Name+kube.DeploymentSuffix]; ok {
+				obj.Spec.Replicas = &replicas
+				log.Infof("Deployment %s scaled to %d successfully.", obj.Name, replicas)
+			}
+		case *appsapi.StatefulSet:
+			if replicas, ok := scaleFactorMap[obj.Name+kube.StatefulSetSuffix]; ok {
+				obj.Spec.Replicas = &replicas
+				log.Infof("StatefulSet %s scaled to %d successfully.", obj.Name, replicas)
+			}
+		}
+	}
+	return f.reconcile(ctx.GetID(), ctx.ScheduleOptions.Nodes)
+}
+
+// GetScaleFactorMap returns the current replica count of every scalable controller of the context
+func (f *fake) GetScaleFactorMap(ctx *scheduler.Context) (map[string]int32, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	app, err := f.getApp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scaleFactorMap := make(map[string]int32)
+	for _, specObj := range app.specs {
+		switch obj := specObj.(type) {
+		case *appsapi.Deployment:
+			scaleFactorMap[obj.Name+kube.DeploymentSuffix] = replicasOf(obj.Spec.Replicas)
+		case *appsapi.StatefulSet:
+			scaleFactorMap[obj.Name+kube.StatefulSetSuffix] = replicasOf(obj.Spec.Replicas)
+		}
+	}
+	return scaleFactorMap, nil
+}
+
+// StopSchedOnNode simulates stopping the kubelet on the given node
+func (f *fake) StopSchedOnNode(n node.Node) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.stoppedNodes[n.Name] = true
+	f.recordEvent(n.Name, newEvent("Node", corev1.EventTypeWarning, "NodeNotReady"))
+	return nil
+}
+
+// StartSchedOnNode simulates starting the kubelet on the given node. Pods on the node are restarted.
+func (f *fake) StartSchedOnNode(n node.Node) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.stoppedNodes[n.Name] {
+		return nil
+	}
+	delete(f.stoppedNodes, n.Name)
+	f.recordEvent(n.Name, newEvent("Node", corev1.EventTypeNormal, "NodeReady"))
+	readyAt := time.Now().Add(f.podStartupDelay)
+	for _, app := range f.apps {
+		for _, ps := range app.livePods() {
+			if ps.pod.Spec.NodeName == n.Name && ps.ownerKind != ownerJob {
+				f.restartCounts[nsKey(ps.pod.Namespace, ps.pod.Name)]++
+				ps.pod.Status.Phase = corev1.PodPending
+				ps.pod.Status.Conditions = nil
+				ps.readyAt = readyAt
+			}
+		}
+	}
+	return nil
+}
+
+// RefreshNodeRegistry is a no-op since the node registry is owned by the fake
+func (f *fake) RefreshNodeRegistry() error {
+	return nil
+}
+
+// RescanSpecs re-parses the given spec directory
+func (f *fake) RescanSpecs(specDir, storageDriver string) error {
+	var err error
+	log.Infof("Rescanning specs for %v and driver %s", specDir, storageDriver)
+	f.specFactory, err = spec.NewFactory(specDir, storageDriver, f)
+	return err
+}
+
+// EnableSchedulingOnNode uncordons the given node
+func (f *fake) EnableSchedulingOnNode(n node.Node) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.cordonedNodes, n.Name)
+	return nil
+}
+
+// DisableSchedulingOnNode cordons the given node
+func (f *fake) DisableSchedulingOnNode(n node.Node) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.cordonedNodes[n.Name] = true
+	return nil
+}
+
+// PrepareNodeToDecommission cordons the given node and moves its pods to the other nodes
+func (f *fake) PrepareNodeToDecommission(n node.Node, provisioner string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.cordonedNodes[n.Name] = true
+	deleteAt := time.Now().Add(f.podTerminationDelay)
+	for _, id := range sortedKeys(f.apps) {
+		app := f.apps[id]
+		for _, ps := range app.livePods() {
+			if ps.pod.Spec.NodeName == n.Name {
+				ps.deleteAt = deleteAt
+			}
+		}
+		if err := f.reconcile(id, nil); err != nil {
+			return &scheduler.ErrFailedToDecommissionNode{
+				Node:  n,
+				Cause: err.Error(),
+			}
+		}
+	}
+	return nil
+}
+
+// IsScalable returns true for deployments and statefulsets
+func (f *fake) IsScalable(spec interface{}) bool {
+	switch spec.(type) {
+	case *appsapi.Deployment, *appsapi.StatefulSet:
+		return true
+	}
+	return false
+}
+
+// ValidateVolumeSnapshotRestore checks that the snapshots of the context were taken before the restore started
+func (f *fake) ValidateVolumeSnapshotRestore(ctx *scheduler.Context, timeStart time.Time) error {
+	snaps, err := f.GetSnapshots(ctx)
+	if err != nil {
+		return err
+	}
+	if len(snaps) == 0 {
+		return fmt.Errorf("no valid volumesnapshot specs found")
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, s := range snaps {
+		snap := f.snapshots[nsKey(s.Namespace, s.Name)]
+		if snap.Status.CreationTimestamp.Time.After(timeStart) {
+			return fmt.Errorf("snapshot %s was created after restore start time %v", s.Name, timeStart)
+		}
+	}
+	return nil
+}
+
+// GetTokenFromConfigMap returns the auth token stored in the given secret, if any
+func (f *fake) GetTokenFromConfigMap(configMapName string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.secrets[nsKey("default", configMapName)]["auth-token"], nil
+}
+
+// AddLabelOnNode adds a label on the given node
+func (f *fake) AddLabelOnNode(n node.Node, key string, value string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := node.GetNodesByName()[n.Name]; !ok {
+		return &scheduler.ErrFailedToAddLabelOnNode{
+			Key:   key,
+			Value: value,
+			Node:  n,
+			Cause: "node not found",
+		}
+	}
+	if f.nodeLabels[n.Name] == nil {
+		f.nodeLabels[n.Name] = make(map[string]string)
+	}
+	f.nodeLabels[n.Name][key] = value
+	return nil
+}
+
+// RemoveLabelOnNode removes a label from the given node
+func (f *fake) RemoveLabelOnNode(n node.Node, key string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.nodeLabels[n.Name], key)
+	return nil
+}
+
+// IsAutopilotEnabledForVolume returns true if any autopilot rule selects the PVC of the volume
+func (f *fake) IsAutopilotEnabledForVolume(vol *volume.Volume) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, rule := range f.autopilotRules {
+		selector, err := metav1.LabelSelectorAsSelector(&rule.Spec.Selector.LabelSelector)
+		if err != nil {
+			log.Warnf("Invalid selector in autopilot rule %s: %v", rule.Name, err)
+			continue
+		}
+		if !selector.Empty() && selector.Matches(labels.Set(vol.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SaveSchedulerLogsToFile writes the simulated scheduler events for the node to the given location
+func (f *fake) SaveSchedulerLogsToFile(n node.Node, location string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var lines []string
+	for _, event := range f.events[n.Name] {
+		lines = append(lines, fmt.Sprintf("%s %s %s", event.EventTime.Format(time.RFC3339), event.Type, event.Message))
+	}
+	return ioutil.WriteFile(path.Join(location, "kubelet.log"), []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// GetAutopilotNamespace returns the namespace where autopilot is simulated to run
+func (f *fake) GetAutopilotNamespace() (string, error) {
+	return autopilotNamespace, nil
+}
+
+// GetIOBandwidth returns the IO bandwidth for the given pod name and namespace
+func (f *fake) GetIOBandwidth(podName string, namespace string) (int, error) {
+	return 0, &errors.ErrNotSupported{
+		Type:      "Function",
+		Operation: "GetIOBandwidth()",
+	}
+}
+
+// CreateAutopilotRule stores the given autopilot rule
+func (f *fake) CreateAutopilotRule(apRule apapi.AutopilotRule) (*apapi.AutopilotRule, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := f.autopilotRules[apRule.Name]; ok {
+		return nil, fmt.Errorf("autopilotrule %s already exists", apRule.Name)
+	}
+	rule := apRule.DeepCopy()
+	rule.UID = metav1Uid()
+	rule.CreationTimestamp = metav1.Now()
+	f.autopilotRules[rule.Name] = rule
+	return rule.DeepCopy(), nil
+}
+
+// GetAutopilotRule returns the autopilot rule with the given name
+func (f *fake) GetAutopilotRule(name string) (*apapi.AutopilotRule, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	rule, ok := f.autopilotRules[name]
+	if !ok {
+		return nil, &errors.ErrNotFound{
+			ID:   name,
+			Type: "AutopilotRule",
+		}
+	}
+	return rule.DeepCopy(), nil
+}
+
+// UpdateAutopilotRule replaces the stored autopilot rule
+func (f *fake) UpdateAutopilotRule(apRule *apapi.AutopilotRule) (*apapi.AutopilotRule, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := f.autopilotRules[apRule.Name]; !ok {
+		return nil, &errors.ErrNotFound{
+			ID:   apRule.Name,
+			Type: "AutopilotRule",
+		}
+	}
+	f.autopilotRules[apRule.Name] = apRule.DeepCopy()
+	return apRule.DeepCopy(), nil
+}
+
+// ListAutopilotRules lists all stored autopilot rules
+func (f *fake) ListAutopilotRules() (*apapi.AutopilotRuleList, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	list := &apapi.AutopilotRuleList{}
+	for _, name := range sortedKeys(f.autopilotRules) {
+		list.Items = append(list.Items, *f.autopilotRules[name].DeepCopy())
+	}
+	return list, nil
+}
+
+// DeleteAutopilotRule deletes the autopilot rule with the given name
+func (f *fake) DeleteAutopilotRule(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := f.autopilotRules[name]; !ok {
+		return &errors.ErrNotFound{
+			ID:   name,
+			Type: "AutopilotRule",
+		}
+	}
+	delete(f.autopilotRules, name)
+	return nil
+}
+
+// GetActionApproval returns the action approval with the given name
+func (f *fake) GetActionApproval(namespace, name string) (*apapi.ActionApproval, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	approval, ok := f.actionApprovals[nsKey(namespace, name)]
+	if !ok {
+		return nil, &errors.ErrNotFound{
+			ID:   nsKey(namespace, name),
+			Type: "ActionApproval",
+		}
+	}
+	return approval.DeepCopy(), nil
+}
+
+// UpdateActionApproval stores the given action approval
+func (f *fake) UpdateActionApproval(namespace string, actionApproval *apapi.ActionApproval) (*apapi.ActionApproval, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	approval := actionApproval.DeepCopy()
+	approval.Namespace = namespace
+	f.actionApprovals[nsKey(namespace, approval.Name)] = approval
+	return approval.DeepCopy(), nil
+}
+
+// DeleteActionApproval deletes the action approval with the given name
+func (f *fake) DeleteActionApproval(namespace, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.actionApprovals, nsKey(namespace, name))
+	return nil
+}
+
+// ListActionApprovals lists the action approvals in the given namespace
+func (f *fake) ListActionApprovals(namespace string) (*apapi.ActionApprovalList, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	list := &apapi.ActionApprovalList{}
+	for _, key := range sortedKeys(f.actionApprovals) {
+		if approval := f.actionApprovals[key]; approval.Namespace == namespace {
+			list.Items = append(list.Items, *approval.DeepCopy())
+		}
+	}
+	return list, nil
+}
+
+// GetEvents returns all simulated events keyed by the object they were raised for
+func (f *fake) GetEvents() map[string][]scheduler.Event {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	events := make(map[string][]scheduler.Event, len(f.events))
+	for k, v := range f.events {
+		events[k] = append([]scheduler.Event(nil), v...)
+	}
+	return events
+}
+
+// ValidateAutopilotEvents is a no-op since autopilot actions are not simulated
+func (f *fake) ValidateAutopilotEvents(ctx *scheduler.Context) error {
+	return nil
+}
+
+// ValidateAutopilotRuleObjects is a no-op since autopilot actions are not simulated
+func (f *fake) ValidateAutopilotRuleObjects() error {
+	return nil
+}
+
+// GetWorkloadSizeFromAppSpec gets workload size from an application spec
+func (f *fake) GetWorkloadSizeFromAppSpec(ctx *scheduler.Context) (uint64, error) {
+	return 0, nil
+}
+
+// SetConfig is a no-op since the fake has no connection config
+func (f *fake) SetConfig(configPath string) error {
+	return nil
+}
+
+// UpgradeScheduler records the new scheduler version
+func (f *fake) UpgradeScheduler(version string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.version = version
+	return nil
+}
+
+// CreateSecret creates a secret with the given data field
+func (f *fake) CreateSecret(namespace, name, dataField, secretDataString string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := nsKey(namespace, name)
+	if _, ok := f.secrets[key]; ok {
+		return fmt.Errorf("secret %s already exists", key)
+	}
+	f.secrets[key] = map[string]string{dataField: secretDataString}
+	return nil
+}
+
+// GetSecretData returns the given data field of the secret
+func (f *fake) GetSecretData(namespace, name, dataField string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	secret, ok := f.secrets[nsKey(namespace, name)]
+	if !ok {
+		return "", &errors.ErrNotFound{
+			ID:   nsKey(namespace, name),
+			Type: "Secret",
+		}
+	}
+	return secret[dataField], nil
+}
+
+// DeleteSecret deletes the given secret
+func (f *fake) DeleteSecret(namespace, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.secrets, nsKey(namespace, name))
+	return nil
+}
+
+// RecycleNode replaces the given node with a new node and reschedules its pods
+func (f *fake) RecycleNode(n node.Node) error {
+	if err := node.DeleteNode(n); err != nil {
+		return &scheduler.ErrFailedToDeleteNode{
+			Node:  n,
+			Cause: err.Error(),
+		}
+	}
+	newName := fmt.Sprintf("%s-%s", strings.Split(n.Name, "-r")[0]+"-r", shortID())
+	if err := node.AddNode(node.Node{
+		Name:                     newName,
+		Addresses:                n.Addresses,
+		Type:                     n.Type,
+		IsStorageDriverInstalled: n.IsStorageDriverInstalled,
+	}); err != nil {
+		return &scheduler.ErrFailedToUpdateNodeList{
+			Node:  newName,
+			Cause: err.Error(),
+		}
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.cordonedNodes, n.Name)
+	delete(f.stoppedNodes, n.Name)
+	delete(f.nodeLabels, n.Name)
+	for _, id := range sortedKeys(f.apps) {
+		app := f.apps[id]
+		for name, ps := range app.pods {
+			if ps.pod.Spec.NodeName == n.Name {
+				delete(app.pods, name)
+			}
+		}
+		if err := f.reconcile(id, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateCsiSnapshotClass creates a CSI snapshot class
+func (f *fake) CreateCsiSnapshotClass(snapClassName string, deleionPolicy string) (*v1beta1.VolumeSnapshotClass, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	class := &v1beta1.VolumeSnapshotClass{
+		ObjectMeta:     metav1.ObjectMeta{Name: snapClassName, UID: metav1Uid()},
+		Driver:         f.volDriverName,
+		DeletionPolicy: v1beta1.DeletionPolicy(deleionPolicy),
+	}
+	f.csiSnapClasses[snapClassName] = class
+	return class.DeepCopy(), nil
+}
+
+// CreateCsiSnapshot creates a ready CSI snapshot of the given PVC
+func (f *fake) CreateCsiSnapshot(name string, namespace string, class string, pvc string) (*v1beta1.VolumeSnapshot, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	snap, err := f.createCsiSnapshot(name, namespace, class, pvc)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToCreateSnapshot{
+			PvcName: pvc,
+			Cause:   err,
+		}
+	}
+	return snap.DeepCopy(), nil
+}
+
+// CSISnapshotTest creates a CSI snapshot of the PVC and restores it to a new PVC
+func (f *fake) CSISnapshotTest(ctx *scheduler.Context, request scheduler.CSISnapshotRequest) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	snap, err := f.createCsiSnapshot(request.SnapName, request.Namespace, request.SnapshotclassName, request.OriginalPVCName)
+	if err != nil {
+		return &scheduler.ErrFailedToCreateSnapshot{
+			PvcName: request.OriginalPVCName,
+			Cause:   err,
+		}
+	}
+	_, err = f.restoreCsiSnapshot(snap, request.RestoredPVCName)
+	return err
+}
+
+// CSISnapshotAndRestoreMany creates a single CSI snapshot and restores it to many PVCs
+func (f *fake) CSISnapshotAndRestoreMany(ctx *scheduler.Context, request scheduler.CSISnapshotRequest) error {
+	const restoreCount = 10
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	snap, err := f.createCsiSnapshot(request.SnapName, request.Namespace, request.SnapshotclassName, request.OriginalPVCName)
+	if err != nil {
+		return &scheduler.ErrFailedToCreateSnapshot{
+			PvcName: request.OriginalPVCName,
+			Cause:   err,
+		}
+	}
+	for i := 0; i < restoreCount; i++ {
+		if _, err := f.restoreCsiSnapshot(snap, fmt.Sprintf("%s-%d", request.RestoredPVCName, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CSICloneTest clones the PVC into a new PVC
+func (f *fake) CSICloneTest(ctx *scheduler.Context, request scheduler.CSICloneRequest) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	src, ok := f.pvcs[nsKey(request.Namespace, request.OriginalPVCName)]
+	if !ok {
+		return &scheduler.ErrFailedToValidatePvc{
+			Name:  request.OriginalPVCName,
+			Cause: fmt.Errorf("PVC not found"),
+		}
+	}
+	f.addPVC(newPVC(src.pvc, request.RestoredPVCName, request.Namespace, 0), src.params)
+	return nil
+}
+
+// CreateCsiSnapsForVolumes creates a CSI snapshot of every PVC of the context
+func (f *fake) CreateCsiSnapsForVolumes(ctx *scheduler.Context, snapClass string) (map[string]*v1beta1.VolumeSnapshot, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pvcs, err := f.pvcsForApp(ctx)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToCreateCsiSnapshots{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	snaps := make(map[string]*v1beta1.VolumeSnapshot)
+	for _, ps := range pvcs {
+		name := fmt.Sprintf("%s-snap-%s", ps.pvc.Name, shortID())
+		snap, err := f.createCsiSnapshot(name, ps.pvc.Namespace, snapClass, ps.pvc.Name)
+		if err != nil {
+			return nil, &scheduler.ErrFailedToCreateCsiSnapshots{
+				App:   ctx.App,
+				Cause: err.Error(),
+			}
+		}
+		snaps[ps.pvc.Name] = snap.DeepCopy()
+	}
+	return snaps, nil
+}
+
+// GetCsiSnapshots returns the CSI snapshots of the given PVC
+func (f *fake) GetCsiSnapshots(namespace string, pvcName string) ([]*v1beta1.VolumeSnapshot, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var snaps []*v1beta1.VolumeSnapshot
+	for _, key := range sortedKeys(f.csiSnapshots) {
+		snap := f.csiSnapshots[key]
+		if snap.Namespace == namespace && *snap.Spec.Source.PersistentVolumeClaimName == pvcName {
+			snaps = append(snaps, snap.DeepCopy())
+		}
+	}
+	return snaps, nil
+}
+
+// ValidateCsiSnapshots checks that the given CSI snapshots exist and are ready to use
+func (f *fake) ValidateCsiSnapshots(ctx *scheduler.Context, volSnapMap map[string]*v1beta1.VolumeSnapshot) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for pvcName, s := range volSnapMap {
+		snap, ok := f.csiSnapshots[nsKey(s.Namespace, s.Name)]
+		if !ok || snap.Status == nil || snap.Status.ReadyToUse == nil || !*snap.Status.ReadyToUse {
+			return &scheduler.ErrFailedToValidateCsiSnapshots{
+				App:   ctx.App,
+				Cause: fmt.Sprintf("snapshot %s of PVC %s is not ready", s.Name, pvcName),
+			}
+		}
+	}
+	return nil
+}
+
+// RestoreCsiSnapAndValidate restores every CSI snapshot of the context to a new PVC
+func (f *fake) RestoreCsiSnapAndValidate(ctx *scheduler.Context, scList map[string]*storageapi.StorageClass) (map[string]corev1.PersistentVolumeClaim, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pvcs, err := f.pvcsForApp(ctx)
+	if err != nil {
+		return nil, &scheduler.ErrFailedToRestore{
+			App:   ctx.App,
+			Cause: err.Error(),
+		}
+	}
+	restored := make(map[string]corev1.PersistentVolumeClaim)
+	for _, ps := range pvcs {
+		for _, key := range sortedKeys(f.csiSnapshots) {
+			snap := f.csiSnapshots[key]
+			if snap.Namespace != ps.pvc.Namespace || *snap.Spec.Source.PersistentVolumeClaimName != ps.pvc.Name {
+				continue
+			}
+			pvc, err := f.restoreCsiSnapshot(snap, fmt.Sprintf("%s-restore", snap.Name))
+			if err != nil {
+				return nil, &scheduler.ErrFailedToRestore{
+					App:   ctx.App,
+					Cause: err.Error(),
+				}
+			}
+			restored[pvc.Name] = *pvc.DeepCopy()
+		}
+	}
+	return restored, nil
+}
+
+// DeleteCsiSnapsForVolumes deletes the oldest CSI snapshots of every PVC, keeping retainCount of them
+func (f *fake) DeleteCsiSnapsForVolumes(ctx *scheduler.Context, retainCount int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pvcs, err := f.pvcsForApp(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ps := range pvcs {
+		var snaps []*v1beta1.VolumeSnapshot
+		for _, snap := range f.csiSnapshots {
+			if snap.Namespace == ps.pvc.Namespace && *snap.Spec.Source.PersistentVolumeClaimName == ps.pvc.Name {
+				snaps = append(snaps, snap)
+			}
+		}
+		sort.Slice(snaps, func(i, j int) bool {
+			return snaps[i].CreationTimestamp.Before(&snaps[j].CreationTimestamp)
+		})
+		for i := 0; i < len(snaps)-retainCount; i++ {
+			delete(f.csiSnapshots, nsKey(snaps[i].Namespace, snaps[i].Name))
+		}
+	}
+	return nil
+}
+
+// DeleteCsiSnapshot deletes the given CSI snapshot
+func (f *fake) DeleteCsiSnapshot(ctx *scheduler.Context, snapshotName string, snapshotNameSpace string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := nsKey(snapshotNameSpace, snapshotName)
+	if _, ok := f.csiSnapshots[key]; !ok {
+		return &scheduler.ErrFailedToDeleteSnapshot{
+			Name:  snapshotNameSpace,
+			Cause: fmt.Errorf("snapshot %s not found", snapshotName),
+		}
+	}
+	delete(f.csiSnapshots, key)
+	return nil
+}
+
+// GetPodsRestartCount returns the restart count of the pods matching the labels in the namespace
+func (f *fake) GetPodsRestartCount(namespace string, label map[string]string) (map[*corev1.Pod]int32, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	selector := labels.SelectorFromSet(label)
+	restarts := make(map[*corev1.Pod]int32)
+	for _, app := range f.apps {
+		if app.namespace != namespace {
+			continue
+		}
+		for _, ps := range app.livePods() {
+			if selector.Matches(labels.Set(ps.pod.Labels)) {
+				restarts[ps.pod.DeepCopy()] = f.restartCounts[nsKey(ps.pod.Namespace, ps.pod.Name)]
+			}
+		}
+	}
+	return restarts, nil
+}
+
+// AddNamespaceLabel adds the given labels on the namespace
+func (f *fake) AddNamespaceLabel(namespace string, labelMap map[string]string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	nsLabels, ok := f.namespaces[namespace]
+	if !ok {
+		return &errors.ErrNotFound{
+			ID:   namespace,
+			Type: "Namespace",
+		}
+	}
+	for k, v := range labelMap {
+		nsLabels[k] = v
+	}
+	return nil
+}
+
+// RemoveNamespaceLabel removes the given label keys from the namespace
+func (f *fake) RemoveNamespaceLabel(namespace string, labelMap map[string]string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	nsLabels, ok := f.namespaces[namespace]
+	if !ok {
+		return &errors.ErrNotFound{
+			ID:   namespace,
+			Type: "Namespace",
+		}
+	}
+	for k := range labelMap {
+		delete(nsLabels, k)
+	}
+	return nil
+}
+
+// GetNamespaceLabel returns the labels on the namespace
+func (f *fake) GetNamespaceLabel(namespace string) (map[string]string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	nsLabels, ok := f.namespaces[namespace]
+	if !ok {
+		return nil, &errors.ErrNotFound{
+			ID:   namespace,
+			Type: "Namespace",
+		}
+	}
+	result := make(map[string]string, len(nsLabels))
+	for k, v := range nsLabels {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (f *fake) getAppSpecs(appKeys []string) ([]*spec.AppSpec, error) {
+	if f.specFactory == nil {
+		return nil, fmt.Errorf("no specs were loaded, %s driver was initialized without a spec dir", SchedName)
+	}
+	if len(appKeys) == 0 {
+		return f.specFactory.GetAll(), nil
+	}
+	var apps []*spec.AppSpec
+	for _, key := range appKeys {
+		appSpec, err := f.specFactory.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, appSpec)
+	}
+	return apps, nil
+}
+
+// getApp returns the state of the given context. Caller must hold the lock.
+func (f *fake) getApp(ctx *scheduler.Context) (*appState, error) {
+	app, ok := f.apps[ctx.GetID()]
+	if !ok {
+		return nil, &errors.ErrNotFound{
+			ID:   ctx.GetID(),
+			Type: "Context",
+		}
+	}
+	return app, nil
+}
+
+// createSpecObjects copies the app specs into the namespace and creates the cluster objects
+// which don't depend on pods. Caller must hold the lock.
+func (f *fake) createSpecObjects(app *spec.AppSpec, namespace string, options scheduler.ScheduleOptions) ([]interface{}, error) {
+	if _, ok := f.namespaces[namespace]; !ok {
+		nsLabels := map[string]string{"creator": "torpedo", "app": app.Key}
+		for k, v := range options.Labels {
+			nsLabels[k] = v
+		}
+		f.namespaces[namespace] = nsLabels
+	}
+
+	var specObjects []interface{}
+	for _, appSpec := range app.SpecList {
+		obj, err := copySpec(appSpec)
+		if err != nil {
+			return nil, err
+		}
+		setNamespace(obj, namespace)
+
+		switch o := obj.(type) {
+		case *storageapi.StorageClass:
+			f.storageClasses[o.Name] = o
+		case *corev1.PersistentVolumeClaim:
+			if _, ok := f.pvcs[nsKey(namespace, o.Name)]; ok {
+				return nil, fmt.Errorf("PVC %s/%s already exists", namespace, o.Name)
+			}
+			f.addPVC(newPVC(o, o.Name, namespace, options.PvcSize), nil)
+		case *corev1.Secret:
+			data := make(map[string]string)
+			for k, v := range o.Data {
+				data[k] = string(v)
+			}
+			for k, v := range o.StringData {
+				data[k] = v
+			}
+			f.secrets[nsKey(namespace, o.Name)] = data
+		case *snapv1.VolumeSnapshot:
+			o.Metadata.UID = metav1Uid()
+			o.Spec.SnapshotDataName = fmt.Sprintf("k8s-volume-snapshot-%s", uuid.New())
+			o.Status.CreationTimestamp = metav1.Now()
+			o.Status.Conditions = []snapv1.VolumeSnapshotCondition{{
+				Type:   snapv1.VolumeSnapshotConditionReady,
+				Status: corev1.ConditionTrue,
+			}}
+			f.snapshots[nsKey(namespace, o.Metadata.Name)] = o
+		}
+		specObjects = append(specObjects, obj)
+	}
+	return specObjects, nil
+}
+
+// addPVC registers a PVC and the volume behind it. Caller must hold the lock.
+func (f *fake) addPVC(pvc *corev1.PersistentVolumeClaim, params map[string]string) *pvcState {
+	if params == nil {
+		params = make(map[string]string)
+		if sc, ok := f.storageClasses[storageClassName(pvc)]; ok {
+			for k, v := range sc.Parameters {
+				params[k] = v
+			}
+		}
+	}
+	volumeID := fmt.Sprintf("pvc-%s", pvc.UID)
+	pvc.Spec.VolumeName = volumeID
+	ps := &pvcState{
+		pvc:      pvc,
+		volumeID: volumeID,
+		params:   params,
+	}
+	if p := f.provisioner(); p != nil {
+		if err := p.ProvisionVolume(volumeID, pvcSize(pvc), params); err != nil {
+			log.Warnf("Failed to provision volume %s for PVC %s/%s: %v", volumeID, pvc.Namespace, pvc.Name, err)
+			pvc.Status.Phase = corev1.ClaimPending
+		}
+	}
+	f.pvcs[nsKey(pvc.Namespace, pvc.Name)] = ps
+	return ps
+}
+
+// provisioner returns the volume driver if it needs to be told about bound volumes
+func (f *fake) provisioner() volumeProvisioner {
+	d, err := volume.Get(f.volDriverName)
+	if err != nil {
+		return nil
+	}
+	p, _ := d.(volumeProvisioner)
+	return p
+}
+
+// reconcile creates or removes pods so that every controller of the app matches its desired
+// replica count. Caller must hold the lock.
+func (f *fake) reconcile(id string, restrictTo []node.Node) error {
+	app, ok := f.apps[id]
+	if !ok {
+		return &errors.ErrNotFound{
+			ID:   id,
+			Type: "Context",
+		}
+	}
+
+	nodes := f.schedulableNodes(restrictTo)
+	if len(nodes) == 0 {
+		return fmt.Errorf("no schedulable nodes available")
+	}
+	readyAt := time.Now().Add(f.podStartupDelay)
+	deleteAt := time.Now().Add(f.podTerminationDelay)
+
+	for _, specObj := range app.specs {
+		kind, name, replicas := expectedReplicas(specObj, len(nodes))
+		if kind == "" {
+			continue
+		}
+		pods := app.ownedPods(kind, name)
+
+		// scale down, highest ordinals first
+		for i := len(pods) - 1; i >= replicas; i-- {
+			pods[i].deleteAt = deleteAt
+		}
+
+		existing := make(map[string]bool)
+		for _, ps := range pods {
+			existing[ps.pod.Name] = true
+		}
+		for i := len(pods); i < replicas; i++ {
+			target := nodes[(len(app.pods)+i)%len(nodes)]
+			if kind == ownerDaemonSet {
+				target = nodes[i]
+			}
+			ordinal := i
+			if kind == ownerStatefulSet {
+				// reuse the lowest free ordinal so recreated pods keep their PVCs
+				for ordinal = 0; existing[fmt.Sprintf("%s-%d", name, ordinal)]; ordinal++ {
+				}
+				existing[fmt.Sprintf("%s-%d", name, ordinal)] = true
+			}
+			ps := newPod(specObj, app.namespace, kind, name, ordinal, target, readyAt)
+			if ss, ok := specObj.(*appsapi.StatefulSet); ok {
+				for j := range ss.Spec.VolumeClaimTemplates {
+					tmpl := &ss.Spec.VolumeClaimTemplates[j]
+					pvcName := statefulSetPVCName(tmpl.Name, ss.Name, ordinal)
+					if _, ok := f.pvcs[nsKey(app.namespace, pvcName)]; !ok {
+						f.addPVC(newPVC(tmpl, pvcName, app.namespace, 0), nil)
+					}
+				}
+			}
+			for _, claim := range claimNames(ps.pod) {
+				if _, ok := f.pvcs[nsKey(app.namespace, claim)]; !ok {
+					return fmt.Errorf("pod %s references missing PVC %s", ps.pod.Name, claim)
+				}
+			}
+			// a terminating pod with the same name must be gone before the new one shows up
+			if old, ok := app.pods[ps.pod.Name]; ok && !old.deleteAt.IsZero() && ps.readyAt.Before(old.deleteAt) {
+				ps.readyAt = old.deleteAt.Add(f.podStartupDelay)
+			}
+			app.pods[ps.pod.Name] = ps
+			f.recordEvent(ps.pod.Name, newEvent("Pod", corev1.EventTypeNormal,
+				fmt.Sprintf("Successfully assigned %s/%s to %s", app.namespace, ps.pod.Name, target.Name)))
+		}
+	}
+	return nil
+}
+
+// schedulableNodes returns the worker nodes on which new pods can be placed. Caller must hold the lock.
+func (f *fake) schedulableNodes(restrictTo []node.Node) []node.Node {
+	var nodes []node.Node
+	for _, n := range node.GetWorkerNodes() {
+		if f.cordonedNodes[n.Name] {
+			continue
+		}
+		if len(restrictTo) > 0 && !nodeInList(n.Name, restrictTo) {
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+// advance moves all simulated pods forward in their lifecycle. Caller must hold the lock.
+func (f *fake) advance() {
+	now := time.Now()
+	for _, app := range f.apps {
+		app.advance(now, f.stoppedNodes)
+	}
+}
+
+// pvcsForApp returns the PVCs used by the given context. Caller must hold the lock.
+func (f *fake) pvcsForApp(ctx *scheduler.Context) ([]*pvcState, error) {
+	namespace := ""
+	if app, ok := f.apps[ctx.GetID()]; ok {
+		namespace = app.namespace
+	}
+
+	var pvcs []*pvcState
+	seen := make(map[string]bool)
+	add := func(name, ns string) {
+		key := nsKey(ns, name)
+		if ps, ok := f.pvcs[key]; ok && !seen[key] {
+			seen[key] = true
+			pvcs = append(pvcs, ps)
+		}
+	}
+	for _, specObj := range ctx.App.SpecList {
+		switch obj := specObj.(type) {
+		case *corev1.PersistentVolumeClaim:
+			add(obj.Name, obj.Namespace)
+		case *appsapi.StatefulSet:
+			prefixes := make([]string, 0, len(obj.Spec.VolumeClaimTemplates))
+			for _, tmpl := range obj.Spec.VolumeClaimTemplates {
+				prefixes = append(prefixes, fmt.Sprintf("%s-%s-", tmpl.Name, obj.Name))
+			}
+			for _, key := range sortedKeys(f.pvcs) {
+				ps := f.pvcs[key]
+				if ps.pvc.Namespace != obj.Namespace {
+					continue
+				}
+				for _, prefix := range prefixes {
+					if strings.HasPrefix(ps.pvc.Name, prefix) {
+						add(ps.pvc.Name, ps.pvc.Namespace)
+					}
+				}
+			}
+		}
+	}
+	if namespace == "" && len(pvcs) == 0 {
+		return nil, &errors.ErrNotFound{
+			ID:   ctx.GetID(),
+			Type: "Context",
+		}
+	}
+	return pvcs, nil
+}
+
+// createCsiSnapshot creates a ready CSI snapshot. Caller must hold the lock.
+func (f *fake) createCsiSnapshot(name, namespace, class, pvcName string) (*v1beta1.VolumeSnapshot, error) {
+	ps, ok := f.pvcs[nsKey(namespace, pvcName)]
+	if !ok {
+		return nil, fmt.Errorf("PVC %s/%s not found", namespace, pvcName)
+	}
+	key := nsKey(namespace, name)
+	if _, ok := f.csiSnapshots[key]; ok {
+		return nil, fmt.Errorf("snapshot %s already exists", key)
+	}
+	ready := true
+	contentName := fmt.Sprintf("snapcontent-%s", uuid.New())
+	restoreSize := resource.NewQuantity(int64(pvcSize(ps.pvc)), resource.BinarySI)
+	now := metav1.Now()
+	snap := &v1beta1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			UID:               metav1Uid(),
+			CreationTimestamp: now,
+		},
+		Spec: v1beta1.VolumeSnapshotSpec{
+			Source: v1beta1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: &pvcName,
+			},
+			VolumeSnapshotClassName: &class,
+		},
+		Status: &v1beta1.VolumeSnapshotStatus{
+			BoundVolumeSnapshotContentName: &contentName,
+			CreationTime:                   &now,
+			ReadyToUse:                     &ready,
+			RestoreSize:                    restoreSize,
+		},
+	}
+	f.csiSnapshots[key] = snap
+	return snap, nil
+}
+
+// restoreCsiSnapshot creates a new PVC from the given CSI snapshot. Caller must hold the lock.
+func (f *fake) restoreCsiSnapshot(snap *v1beta1.VolumeSnapshot, pvcName string) (*corev1.PersistentVolumeClaim, error) {
+	src, ok := f.pvcs[nsKey(snap.Namespace, *snap.Spec.Source.PersistentVolumeClaimName)]
+	if !ok {
+		return nil, &scheduler.ErrFailedToValidatePvc{
+			Name:  pvcName,
+			Cause: fmt.Errorf("source PVC of snapshot %s no longer exists", snap.Name),
+		}
+	}
+	if _, ok := f.pvcs[nsKey(snap.Namespace, pvcName)]; ok {
+		return nil, &scheduler.ErrFailedToValidatePvc{
+			Name:  pvcName,
+			Cause: fmt.Errorf("PVC already exists"),
+		}
+	}
+	apiGroup := "snapshot.storage.k8s.io"
+	pvc := newPVC(src.pvc, pvcName, snap.Namespace, snap.Status.RestoreSize.Value())
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     "VolumeSnapshot",
+		Name:     snap.Name,
+	}
+	return f.addPVC(pvc, src.params).pvc, nil
+}
+
+// recordEvent stores an event for the given object. Caller must hold the lock.
+func (f *fake) recordEvent(object string, event scheduler.Event) {
+	f.events[object] = append(f.events[object], event)
+}
+
+func toVolume(ps *pvcState) *volume.Volume {
+	size := pvcSize(ps.pvc)
+	return &volume.Volume{
+		ID:            ps.volumeID,
+		Name:          ps.pvc.Name,
+		Namespace:     ps.pvc.Namespace,
+		Annotations:   ps.pvc.Annotations,
+		Labels:        ps.pvc.Labels,
+		Size:          size,
+		RequestedSize: size,
+		Shared:        isSharedPVC(ps.pvc),
+	}
+}
+
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func nodeInList(name string, nodes []node.Node) bool {
+	for _, n := range nodes {
+		if n.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func copySpec(specObj interface{}) (interface{}, error) {
+	switch obj := specObj.(type) {
+	case *snapv1.VolumeSnapshot:
+		return obj.DeepCopy(), nil
+	case *scheduler.HelmRepo:
+		repo := *obj
+		return &repo, nil
+	case runtime.Object:
+		return obj.DeepCopyObject(), nil
+	}
+	return nil, fmt.Errorf("unsupported object %T in app spec", specObj)
+}
+
+func setNamespace(specObj interface{}, namespace string) {
+	switch obj := specObj.(type) {
+	case *snapv1.VolumeSnapshot:
+		obj.Metadata.Namespace = namespace
+	case *scheduler.HelmRepo:
+		obj.Namespace = namespace
+	case *storageapi.StorageClass:
+		// cluster scoped
+	default:
+		if metadata, err := meta.Accessor(specObj); err == nil {
+			metadata.SetNamespace(namespace)
+		}
+	}
+}
+
+func sameObject(a, b interface{}) bool {
+	if fmt.Sprintf("%T", a) != fmt.Sprintf("%T", b) {
+		return false
+	}
+	metaA, errA := meta.Accessor(a)
+	metaB, errB := meta.Accessor(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return metaA.GetName() == metaB.GetName()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	f := &fake{}
+	f.reset()
+	scheduler.Register(SchedName, f)
+}
@@ -0,0 +1,109 @@
+package fake
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/portworx/torpedo/drivers/node"
+	"github.com/portworx/torpedo/drivers/scheduler"
+	kube "github.com/portworx/torpedo/drivers/scheduler/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSpecDir = "../k8s/specs"
+	testTimeout = 10 * time.Second
+	testRetry   = 10 * time.Millisecond
+)
+
+func newTestDriver(t *testing.T) *fake {
+	os.Setenv(PodStartupDelayEnvVar, "50ms")
+	os.Setenv(PodTerminationDelayEnvVar, "50ms")
+	defer os.Unsetenv(PodStartupDelayEnvVar)
+	defer os.Unsetenv(PodTerminationDelayEnvVar)
+
+	for _, n := range node.GetNodes() {
+		require.NoError(t, node.DeleteNode(n))
+	}
+	f := &fake{}
+	require.NoError(t, f.Init(scheduler.InitOptions{
+		SpecDir:            testSpecDir,
+		StorageProvisioner: "portworx",
+	}))
+	return f
+}
+
+func TestScheduleAndDestroy(t *testing.T) {
+	f := newTestDriver(t)
+	assert.Len(t, node.GetWorkerNodes(), defaultNodeCount)
+	assert.NotEmpty(t, f.specFactory.GetAll(), "expected the k8s specs to be parsed")
+
+	contexts, err := f.Schedule("unit", scheduler.ScheduleOptions{AppKeys: []string{"mysql", "cassandra"}})
+	require.NoError(t, err)
+	require.Len(t, contexts, 2)
+
+	err = f.WaitForRunning(contexts[0], time.Millisecond, time.Millisecond)
+	assert.Error(t, err, "pods should still be pending right after schedule")
+
+	for _, ctx := range contexts {
+		require.NoError(t, f.WaitForRunning(ctx, testTimeout, testRetry))
+		require.NoError(t, f.ValidateVolumes(ctx, testTimeout, testRetry, nil))
+
+		vols, err := f.GetVolumes(ctx)
+		require.NoError(t, err)
+		assert.NotEmpty(t, vols, "app %s should have volumes", ctx.App.Key)
+
+		nodes, err := f.GetNodesForApp(ctx)
+		require.NoError(t, err)
+		assert.NotEmpty(t, nodes)
+	}
+
+	ctx := contexts[0]
+	labels, err := f.GetNamespaceLabel(ctx.ScheduleOptions.Namespace)
+	require.NoError(t, err)
+	assert.Equal(t, ctx.App.Key, labels["app"])
+
+	require.NoError(t, f.Destroy(ctx, map[string]bool{scheduler.OptionsWaitForDestroy: true}))
+	_, err = f.GetNodesForApp(ctx)
+	assert.Error(t, err, "destroyed app should not be known anymore")
+
+	// volumes outlive the app until they are deleted explicitly
+	vols, err := f.DeleteVolumes(ctx, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, vols)
+}
+
+func TestScaleAndDeleteTasks(t *testing.T) {
+	f := newTestDriver(t)
+
+	contexts, err := f.Schedule("scale", scheduler.ScheduleOptions{AppKeys: []string{"cassandra"}})
+	require.NoError(t, err)
+	ctx := contexts[0]
+	require.NoError(t, f.WaitForRunning(ctx, testTimeout, testRetry))
+
+	scaleMap, err := f.GetScaleFactorMap(ctx)
+	require.NoError(t, err)
+	for name, replicas := range scaleMap {
+		scaleMap[name] = replicas + 1
+	}
+	require.NoError(t, f.ScaleApplication(ctx, scaleMap))
+	require.NoError(t, f.WaitForRunning(ctx, testTimeout, testRetry))
+
+	newScaleMap, err := f.GetScaleFactorMap(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, scaleMap, newScaleMap)
+
+	volsBefore, err := f.GetVolumes(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, f.DeleteTasks(ctx, nil))
+	require.NoError(t, f.WaitForRunning(ctx, testTimeout, testRetry))
+
+	// recreated statefulset pods reuse their PVCs
+	volsAfter, err := f.GetVolumes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(volsBefore), len(volsAfter))
+	assert.Contains(t, scaleMap, "cassandra"+kube.StatefulSetSuffix)
+}
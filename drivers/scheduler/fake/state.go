@@ -0,0 +1,320 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/portworx/torpedo/drivers/node"
+	"github.com/portworx/torpedo/drivers/scheduler"
+	appsapi "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	ownerDeployment  = "Deployment"
+	ownerStatefulSet = "StatefulSet"
+	ownerDaemonSet   = "DaemonSet"
+	ownerJob         = "Job"
+	ownerPod         = "Pod"
+)
+
+// podState tracks a single simulated pod and the times at which it changes phase
+type podState struct {
+	pod       *corev1.Pod
+	ownerKind string
+	ownerName string
+	readyAt   time.Time
+	deleteAt  time.Time
+}
+
+// pvcState tracks a simulated PVC and the storage volume that backs it
+type pvcState struct {
+	pvc      *corev1.PersistentVolumeClaim
+	volumeID string
+	params   map[string]string
+}
+
+// appState tracks the simulated objects that belong to one scheduled context
+type appState struct {
+	key       string
+	namespace string
+	specs     []interface{}
+	pods      map[string]*podState
+}
+
+func nsKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func metav1Uid() types.UID {
+	return types.UID(uuid.New())
+}
+
+func shortID() string {
+	return strings.Split(uuid.New(), "-")[0][:5]
+}
+
+func storageClassName(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		return *pvc.Spec.StorageClassName
+	}
+	return pvc.Annotations["volume.beta.kubernetes.io/storage-class"]
+}
+
+func pvcSize(pvc *corev1.PersistentVolumeClaim) uint64 {
+	if pvc.Status.Capacity != nil {
+		if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			return uint64(q.Value())
+		}
+	}
+	if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		return uint64(q.Value())
+	}
+	return 0
+}
+
+func isSharedPVC(pvc *corev1.PersistentVolumeClaim) bool {
+	for _, mode := range pvc.Spec.AccessModes {
+		if mode == corev1.ReadWriteMany {
+			return true
+		}
+	}
+	return false
+}
+
+// advance moves every pod of the app forward in its lifecycle based on the current time
+func (a *appState) advance(now time.Time, stoppedNodes map[string]bool) {
+	for name, ps := range a.pods {
+		if !ps.deleteAt.IsZero() {
+			if !now.Before(ps.deleteAt) {
+				delete(a.pods, name)
+			}
+			continue
+		}
+		if ps.pod.Status.Phase != corev1.PodPending || now.Before(ps.readyAt) {
+			continue
+		}
+		// kubelet on this node is down, so the pod can't make progress
+		if stoppedNodes[ps.pod.Spec.NodeName] {
+			continue
+		}
+		if ps.ownerKind == ownerJob {
+			ps.pod.Status.Phase = corev1.PodSucceeded
+			continue
+		}
+		ps.pod.Status.Phase = corev1.PodRunning
+		ps.pod.Status.StartTime = &metav1.Time{Time: now}
+		ps.pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Time{Time: now},
+		}}
+		for i := range ps.pod.Status.ContainerStatuses {
+			ps.pod.Status.ContainerStatuses[i].Ready = true
+			ps.pod.Status.ContainerStatuses[i].State = corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{StartedAt: metav1.Time{Time: now}},
+			}
+		}
+	}
+}
+
+// livePods returns the pods of the app which are not terminating, sorted by name
+func (a *appState) livePods() []*podState {
+	var pods []*podState
+	for _, ps := range a.pods {
+		if ps.deleteAt.IsZero() {
+			pods = append(pods, ps)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].pod.Name < pods[j].pod.Name
+	})
+	return pods
+}
+
+// ownedPods returns the live pods created for the given controller
+func (a *appState) ownedPods(kind, name string) []*podState {
+	var pods []*podState
+	for _, ps := range a.livePods() {
+		if ps.ownerKind == kind && ps.ownerName == name {
+			pods = append(pods, ps)
+		}
+	}
+	return pods
+}
+
+// expectedReplicas returns the number of pods the given spec object should have
+func expectedReplicas(specObj interface{}, nodeCount int) (string, string, int) {
+	switch obj := specObj.(type) {
+	case *appsapi.Deployment:
+		replicas := 1
+		if obj.Spec.Replicas != nil {
+			replicas = int(*obj.Spec.Replicas)
+		}
+		return ownerDeployment, obj.Name, replicas
+	case *appsapi.StatefulSet:
+		replicas := 1
+		if obj.Spec.Replicas != nil {
+			replicas = int(*obj.Spec.Replicas)
+		}
+		return ownerStatefulSet, obj.Name, replicas
+	case *appsapi.DaemonSet:
+		return ownerDaemonSet, obj.Name, nodeCount
+	case *batchv1.Job:
+		completions := 1
+		if obj.Spec.Completions != nil {
+			completions = int(*obj.Spec.Completions)
+		}
+		return ownerJob, obj.Name, completions
+	case *corev1.Pod:
+		return ownerPod, obj.Name, 1
+	}
+	return "", "", 0
+}
+
+func podTemplate(specObj interface{}) (metav1.ObjectMeta, corev1.PodSpec) {
+	switch obj := specObj.(type) {
+	case *appsapi.Deployment:
+		return obj.Spec.Template.ObjectMeta, obj.Spec.Template.Spec
+	case *appsapi.StatefulSet:
+		return obj.Spec.Template.ObjectMeta, obj.Spec.Template.Spec
+	case *appsapi.DaemonSet:
+		return obj.Spec.Template.ObjectMeta, obj.Spec.Template.Spec
+	case *batchv1.Job:
+		return obj.Spec.Template.ObjectMeta, obj.Spec.Template.Spec
+	case *corev1.Pod:
+		return obj.ObjectMeta, obj.Spec
+	}
+	return metav1.ObjectMeta{}, corev1.PodSpec{}
+}
+
+// newPod builds a pending pod for the given controller, placed on the given node
+func newPod(specObj interface{}, namespace, kind, owner string, ordinal int, n node.Node, readyAt time.Time) *podState {
+	objMeta, podSpec := podTemplate(specObj)
+
+	name := fmt.Sprintf("%s-%s", owner, shortID())
+	switch kind {
+	case ownerStatefulSet:
+		name = fmt.Sprintf("%s-%d", owner, ordinal)
+	case ownerPod:
+		name = owner
+	}
+
+	labels := make(map[string]string)
+	for k, v := range objMeta.Labels {
+		labels[k] = v
+	}
+	podSpec = *podSpec.DeepCopy()
+	podSpec.NodeName = n.Name
+
+	// statefulset pods mount the PVC generated from the claim template for their ordinal
+	if ss, ok := specObj.(*appsapi.StatefulSet); ok {
+		for _, tmpl := range ss.Spec.VolumeClaimTemplates {
+			podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+				Name: tmpl.Name,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: statefulSetPVCName(tmpl.Name, ss.Name, ordinal),
+					},
+				},
+			})
+		}
+	}
+
+	var containerStatuses []corev1.ContainerStatus
+	for _, c := range podSpec.Containers {
+		containerStatuses = append(containerStatuses, corev1.ContainerStatus{
+			Name:  c.Name,
+			Image: c.Image,
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+			},
+		})
+	}
+
+	hostIP := ""
+	if len(n.Addresses) > 0 {
+		hostIP = n.Addresses[0]
+	}
+
+	return &podState{
+		pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				Labels:            labels,
+				Annotations:       objMeta.Annotations,
+				UID:               metav1Uid(),
+				CreationTimestamp: metav1.Now(),
+				OwnerReferences: []metav1.OwnerReference{{
+					Kind: kind,
+					Name: owner,
+				}},
+			},
+			Spec: podSpec,
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodPending,
+				HostIP:            hostIP,
+				ContainerStatuses: containerStatuses,
+			},
+		},
+		ownerKind: kind,
+		ownerName: owner,
+		readyAt:   readyAt,
+	}
+}
+
+func statefulSetPVCName(template, statefulSet string, ordinal int) string {
+	return fmt.Sprintf("%s-%s-%d", template, statefulSet, ordinal)
+}
+
+// claimNames returns the names of all PVCs mounted by the pod
+func claimNames(pod *corev1.Pod) []string {
+	var names []string
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			names = append(names, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return names
+}
+
+// newPVC creates a bound PVC from the given template
+func newPVC(tmpl *corev1.PersistentVolumeClaim, name, namespace string, sizeOverride int64) *corev1.PersistentVolumeClaim {
+	pvc := tmpl.DeepCopy()
+	pvc.Name = name
+	pvc.Namespace = namespace
+	pvc.UID = metav1Uid()
+	pvc.CreationTimestamp = metav1.Now()
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	if sizeOverride > 0 {
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = *resource.NewQuantity(sizeOverride, resource.BinarySI)
+	}
+	pvc.Status.Phase = corev1.ClaimBound
+	pvc.Status.AccessModes = pvc.Spec.AccessModes
+	pvc.Status.Capacity = corev1.ResourceList{
+		corev1.ResourceStorage: pvc.Spec.Resources.Requests[corev1.ResourceStorage],
+	}
+	return pvc
+}
+
+func newEvent(kind, eventType, message string) scheduler.Event {
+	now := time.Now()
+	return scheduler.Event{
+		Message:   message,
+		EventTime: metav1.NewMicroTime(now),
+		Count:     1,
+		LastSeen:  metav1.NewTime(now),
+		Kind:      kind,
+		Type:      eventType,
+	}
+}
@@ -47,6 +47,7 @@ import (
 	tp_errors "github.com/portworx/torpedo/pkg/errors"
 	"github.com/portworx/torpedo/pkg/netutil"
 	"github.com/portworx/torpedo/pkg/osutils"
+	"github.com/portworx/torpedo/pkg/pxctl"
 	"github.com/portworx/torpedo/pkg/units"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -1104,7 +1105,7 @@ func (d *portworx) RecoverPool(n node.Node) error {
 }
 
 func (d *portworx) GetNodePoolsStatus(n node.Node) (map[string]string, error) {
-	cmd := fmt.Sprintf("%s sv pool show | grep -e UUID -e Status", d.getPxctlPath(n))
+	cmd := fmt.Sprintf("%s sv pool show", d.getPxctlPath(n))
 	out, err := d.nodeDriver.RunCommand(
 		n,
 		cmd,
@@ -1115,29 +1116,14 @@ func (d *portworx) GetNodePoolsStatus(n node.Node) (map[string]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error getting pool status on node [%s], Err: %v", n.Name, err)
 	}
-	outLines := strings.Split(out, "\n")
+	pools, err := pxctl.ParsePoolShow(out)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pool status on node [%s], Err: %v", n.Name, err)
+	}
 
 	poolsData := make(map[string]string)
-	var poolId string
-	var status string
-	for _, l := range outLines {
-		line := strings.Trim(l, " ")
-		if strings.Contains(line, "UUID") {
-			poolId = strings.Split(line, ":")[1]
-			poolId = strings.Trim(poolId, " ")
-		}
-		if strings.Contains(line, "Status") {
-			status = strings.Split(line, ":")[1]
-			status = strings.Trim(status, " ")
-		}
-		if poolId != "" && status != "" {
-			// this condition is required to consider only pool status when pool has both pool status and LastOperation status fields
-			if _, ok := poolsData[poolId]; !ok {
-				poolsData[poolId] = status
-			}
-			poolId = ""
-		}
-		status = ""
+	for _, pool := range pools {
+		poolsData[pool.UUID] = pool.Status
 	}
 	return poolsData, nil
 }
@@ -4245,10 +4231,9 @@ func (d *portworx) getPxctlStatus(n node.Node) (string, error) {
 		return "", fmt.Errorf("failed to get pxctl status. cause: %v", err)
 	}
 
-	var data interface{}
-	err = json.Unmarshal([]byte(out), &data)
+	status, err := pxctl.ParseStatusJSON(out)
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal pxctl status. cause: %v", err)
+		return "", err
 	}
 
 	// Delete context
@@ -4259,9 +4244,8 @@ func (d *portworx) getPxctlStatus(n node.Node) (string, error) {
 		}
 	}
 
-	statusMap := data.(map[string]interface{})
-	if status, ok := statusMap["status"]; ok {
-		return status.(string), nil
+	if status.Status != "" {
+		return status.Status, nil
 	}
 	return api.Status_STATUS_NONE.String(), nil
 }
@@ -4412,10 +4396,9 @@ func (d *portworx) GetNodeStats(n node.Node) (map[string]map[string]int, error)
 		return nil, fmt.Errorf("failed to get pxctl status. cause: %v", err)
 	}
 
-	var data interface{}
-	err = json.Unmarshal([]byte(out), &data)
+	stats, err := pxctl.ParseNodeStatsJSON(out)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal pxctl status. cause: %v", err)
+		return nil, err
 	}
 
 	// Delete context
@@ -4426,32 +4409,11 @@ func (d *portworx) GetNodeStats(n node.Node) (map[string]map[string]int, error)
 		}
 	}
 
-	nodeStats := data.(map[string]interface{})
-	deleted := 0
-	pending := 0
-	skipped := 0
-
-	if value, ok := nodeStats["relaxed_reclaim_stats"].(map[string]interface{}); ok {
-
-		if p, ok := value["pending"]; ok {
-			pending = int(p.(float64))
-		}
-
-		if d, ok := value["deleted"]; ok {
-			deleted = int(d.(float64))
-		}
-
-		if s, ok := value["skipped"]; ok {
-			skipped = int(s.(float64))
-		}
-
-	}
-
 	var nodeStatsMap = map[string]map[string]int{}
 	nodeStatsMap[n.Name] = map[string]int{}
-	nodeStatsMap[n.Name]["deleted"] = deleted
-	nodeStatsMap[n.Name]["pending"] = pending
-	nodeStatsMap[n.Name]["skipped"] = skipped
+	nodeStatsMap[n.Name]["deleted"] = stats.RelaxedReclaim.Deleted
+	nodeStatsMap[n.Name]["pending"] = stats.RelaxedReclaim.Pending
+	nodeStatsMap[n.Name]["skipped"] = stats.RelaxedReclaim.Skipped
 
 	return nodeStatsMap, nil
 }
@@ -4768,7 +4730,7 @@ func waitForAddDriveToComplete(n node.Node, drivePath string, d *portworx) error
 
 // GetPoolsUsedSize returns map of pool id and current used size
 func (d *portworx) GetPoolsUsedSize(n *node.Node) (map[string]string, error) {
-	cmd := fmt.Sprintf("%s sv pool show -j | grep -e uuid -e '\"Used\"'", d.getPxctlPath(*n))
+	cmd := fmt.Sprintf("%s sv pool show -j", d.getPxctlPath(*n))
 
 	out, err := d.nodeDriver.RunCommandWithNoRetry(*n, cmd, node.ConnectionOpts{
 		Timeout:         2 * time.Minute,
@@ -4778,29 +4740,14 @@ func (d *portworx) GetPoolsUsedSize(n *node.Node) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	outLines := strings.Split(out, "\n")
+	pools, err := pxctl.ParsePoolShowJSON(out)
+	if err != nil {
+		return nil, err
+	}
 
 	poolsData := make(map[string]string)
-	var poolId string
-	var usedSize string
-	for _, l := range outLines {
-		line := strings.Trim(l, " ")
-		line = strings.Trim(line, ",")
-		if strings.Contains(line, "uuid") {
-			poolId = strings.Split(line, ":")[1]
-			poolId = strings.Trim(poolId, " ")
-			poolId = strings.ReplaceAll(poolId, "\"", "")
-		}
-		if strings.Contains(line, "Used") {
-			usedSize = strings.Split(line, ":")[1]
-			usedSize = strings.Trim(usedSize, " ")
-			usedSize = strings.ReplaceAll(usedSize, "\"", "")
-		}
-		if poolId != "" && usedSize != "" {
-			poolsData[poolId] = usedSize
-			poolId = ""
-			usedSize = ""
-		}
+	for _, pool := range pools {
+		poolsData[pool.UUID] = strconv.FormatUint(pool.Used, 10)
 	}
 	return poolsData, nil
 }
@@ -4809,7 +4756,7 @@ func (d *portworx) GetPoolsUsedSize(n *node.Node) (map[string]string, error) {
 func (d *portworx) IsIOsInProgressForTheVolume(n *node.Node, volumeNameOrID string) (bool, error) {
 
 	log.Infof("Got vol-id [%s] for checking IOs", volumeNameOrID)
-	cmd := fmt.Sprintf("%s v i %s| grep -e 'IOs in progress'", d.getPxctlPath(*n), volumeNameOrID)
+	cmd := fmt.Sprintf("%s v i %s", d.getPxctlPath(*n), volumeNameOrID)
 
 	out, err := d.nodeDriver.RunCommandWithNoRetry(*n, cmd, node.ConnectionOpts{
 		Timeout:         2 * time.Minute,
@@ -4819,18 +4766,11 @@ func (d *portworx) IsIOsInProgressForTheVolume(n *node.Node, volumeNameOrID stri
 	if err != nil {
 		return false, err
 	}
-	line := strings.Trim(out, " ")
-	data := strings.Split(line, ":")[1]
-	data = strings.Trim(data, "\n")
-	data = strings.Trim(data, " ")
-	val, err := strconv.Atoi(data)
+	volume, err := pxctl.ParseVolumeInspect(out)
 	if err != nil {
 		return false, err
 	}
-	if val > 0 {
-		return true, nil
-	}
-	return false, nil
+	return volume.IOsInProgress > 0, nil
 }
 
 // GetRebalanceJobs returns the list of rebalance jobs
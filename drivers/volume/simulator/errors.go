@@ -0,0 +1,57 @@
+package simulator
+
+import "fmt"
+
+// ErrVolumeNotFound error type for a volume that is unknown to the simulator
+type ErrVolumeNotFound struct {
+	// ID is the ID/name of the volume
+	ID string
+}
+
+func (e *ErrVolumeNotFound) Error() string {
+	return fmt.Sprintf("Volume: %v not found in simulated cluster", e.ID)
+}
+
+// ErrNodeNotFound error type for a node that is not part of the simulated cluster
+type ErrNodeNotFound struct {
+	// Node is the name of the node
+	Node string
+}
+
+func (e *ErrNodeNotFound) Error() string {
+	return fmt.Sprintf("Node: %v is not part of the simulated cluster", e.Node)
+}
+
+// ErrFailedToSetReplicationFactor error type for failing to set replication factor
+type ErrFailedToSetReplicationFactor struct {
+	// ID is the ID/name of the volume
+	ID string
+	// Cause is the underlying cause of the error
+	Cause string
+}
+
+func (e *ErrFailedToSetReplicationFactor) Error() string {
+	return fmt.Sprintf("Failed to set replication factor of volume: %v due to err: %v", e.ID, e.Cause)
+}
+
+// ErrFailedToValidateVolume error type for a volume which doesn't match what was requested
+type ErrFailedToValidateVolume struct {
+	// ID is the ID/name of the volume
+	ID string
+	// Cause is the underlying cause of the error
+	Cause string
+}
+
+func (e *ErrFailedToValidateVolume) Error() string {
+	return fmt.Sprintf("Failed to validate volume: %v due to err: %v", e.ID, e.Cause)
+}
+
+// ErrInjectedFailure error type returned by operations which were set up to fail
+type ErrInjectedFailure struct {
+	// Operation is the driver method that failed
+	Operation string
+}
+
+func (e *ErrInjectedFailure) Error() string {
+	return fmt.Sprintf("Injected failure for operation: %v", e.Operation)
+}
@@ -0,0 +1,86 @@
+package simulator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/portworx/torpedo/pkg/log"
+)
+
+// failureRule makes a driver operation fail a number of times
+type failureRule struct {
+	err error
+	// remaining is the number of calls left to fail, a negative value fails forever
+	remaining int
+}
+
+var (
+	failuresLock sync.Mutex
+	failures     = make(map[string]*failureRule)
+)
+
+// InjectFailure makes the next count calls of the given driver operation (the method name, e.g.
+// "SetReplicationFactor") return err. A count of zero or less fails the operation until
+// ClearFailures is called. If err is nil an ErrInjectedFailure is returned instead.
+func InjectFailure(operation string, err error, count int) {
+	failuresLock.Lock()
+	defer failuresLock.Unlock()
+
+	if err == nil {
+		err = &ErrInjectedFailure{Operation: operation}
+	}
+	if count <= 0 {
+		count = -1
+	}
+	failures[operation] = &failureRule{err: err, remaining: count}
+}
+
+// ClearFailures removes all injected failures
+func ClearFailures() {
+	failuresLock.Lock()
+	defer failuresLock.Unlock()
+
+	failures = make(map[string]*failureRule)
+}
+
+// injectedFailure returns the error injected for the given operation, if any
+func injectedFailure(operation string) error {
+	failuresLock.Lock()
+	defer failuresLock.Unlock()
+
+	rule, ok := failures[operation]
+	if !ok {
+		return nil
+	}
+	if rule.remaining > 0 {
+		rule.remaining--
+		if rule.remaining == 0 {
+			delete(failures, operation)
+		}
+	}
+	log.Warnf("simulated volume driver: failing %s: %v", operation, rule.err)
+	return rule.err
+}
+
+// parseFailures injects the failures described by the given value. The format is a comma
+// separated list of operation[:count] entries, e.g. "StopDriver:2,ExpandPool".
+func parseFailures(val string) error {
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		operation, countStr, hasCount := strings.Cut(entry, ":")
+		count := 0
+		if hasCount {
+			var err error
+			if count, err = strconv.Atoi(countStr); err != nil {
+				return fmt.Errorf("invalid failure count in %q: %v", entry, err)
+			}
+		}
+		InjectFailure(operation, nil, count)
+	}
+	return nil
+}
@@ -0,0 +1,518 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/openstorage/api"
+	"github.com/pborman/uuid"
+	"github.com/portworx/torpedo/drivers/node"
+	"github.com/portworx/torpedo/pkg/log"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	runtimeStateClean    = "clean"
+	runtimeStateResync   = "resync"
+	runtimeStateDegraded = "degraded"
+
+	poolStatusOnline      = "Online"
+	poolStatusMaintenance = "In Maintenance"
+	poolStatusOffline     = "Offline"
+
+	kvdbPeerPort   = 9018
+	kvdbClientPort = 9019
+	kvdbDbSize     = 1 << 20
+)
+
+// simNode is the simulated storage state of a single scheduler node
+type simNode struct {
+	name     string
+	ip       string
+	storage  *api.StorageNode
+	driverUp bool
+	// upAt is when a started driver becomes usable, zero if no start is pending
+	upAt time.Time
+	// downSince is when the driver went down, used to time kvdb failover
+	downSince    time.Time
+	drives       map[int32][]string
+	poolStatus   map[string]string
+	poolOps      map[string]*poolOp
+	kvdbMember   bool
+	kvdbLeader   bool
+	decommission bool
+}
+
+// poolOp is an in-flight pool resize which completes at doneAt
+type poolOp struct {
+	target uint64
+	doneAt time.Time
+}
+
+// simVolume is a simulated volume together with the state of its replicas
+type simVolume struct {
+	vol *api.Volume
+	// resyncUntil is when replicas that were added or came back finish syncing
+	resyncUntil time.Time
+	// degraded is set while at least one replica is on an unusable node
+	degraded bool
+}
+
+// cloudsnap is a simulated cloud backup of a volume
+type cloudsnap struct {
+	id       string
+	volumeID string
+	nodeName string
+	doneAt   time.Time
+}
+
+// trashedVolume is a deleted volume kept around until it expires
+type trashedVolume struct {
+	vol       *api.Volume
+	expiresAt time.Time
+}
+
+func newSimNode(n node.Node, poolCount int, poolSize uint64) *simNode {
+	ip := ""
+	if len(n.Addresses) > 0 {
+		ip = n.Addresses[0]
+	}
+	sn := &simNode{
+		name:       n.Name,
+		ip:         ip,
+		driverUp:   true,
+		drives:     make(map[int32][]string),
+		poolStatus: make(map[string]string),
+		poolOps:    make(map[string]*poolOp),
+		storage: &api.StorageNode{
+			Id:                uuid.New(),
+			Status:            api.Status_STATUS_OK,
+			MgmtIp:            ip,
+			DataIp:            ip,
+			Hostname:          n.Name,
+			SchedulerNodeName: n.Name,
+			Disks:             make(map[string]*api.StorageResource),
+			NodeLabels:        make(map[string]string),
+		},
+	}
+	for i := 0; i < poolCount; i++ {
+		sn.addPool(poolSize, fmt.Sprintf("/dev/sd%c", 'b'+i))
+	}
+	return sn
+}
+
+// addPool creates a new pool backed by a single drive of the given size
+func (sn *simNode) addPool(size uint64, drive string) *api.StoragePool {
+	pool := &api.StoragePool{
+		ID:        int32(len(sn.storage.Pools)),
+		Cos:       api.CosType_LOW,
+		Medium:    api.StorageMedium_STORAGE_MEDIUM_SSD,
+		RaidLevel: "raid0",
+		TotalSize: size,
+		Labels: map[string]string{
+			"medium":     "STORAGE_MEDIUM_SSD",
+			"iopriority": "LOW",
+		},
+		Uuid: uuid.New(),
+	}
+	sn.storage.Pools = append(sn.storage.Pools, pool)
+	sn.poolStatus[pool.Uuid] = poolStatusOnline
+	sn.addDrive(pool, drive, size)
+	return pool
+}
+
+func (sn *simNode) addDrive(pool *api.StoragePool, drive string, size uint64) {
+	sn.drives[pool.ID] = append(sn.drives[pool.ID], drive)
+	sn.storage.Disks[drive] = &api.StorageResource{
+		Id:     drive,
+		Path:   drive,
+		Medium: pool.Medium,
+		Online: true,
+		Size:   size,
+	}
+}
+
+func (sn *simNode) pool(uuidOrID string) *api.StoragePool {
+	for _, pool := range sn.storage.Pools {
+		if pool.Uuid == uuidOrID || strconv.Itoa(int(pool.ID)) == uuidOrID {
+			return pool
+		}
+	}
+	return nil
+}
+
+// usable returns true if replicas on this node can serve IO
+func (sn *simNode) usable() bool {
+	return sn.driverUp && !sn.decommission && sn.storage.Status == api.Status_STATUS_OK
+}
+
+// nodeByName returns the simulated node with the given scheduler name or storage node ID
+func (d *simulator) nodeByName(nameOrID string) *simNode {
+	if sn, ok := d.nodes[nameOrID]; ok {
+		return sn
+	}
+	for _, sn := range d.nodes {
+		if sn.storage.Id == nameOrID {
+			return sn
+		}
+	}
+	return nil
+}
+
+func (d *simulator) lookupNode(n node.Node) (*simNode, error) {
+	sn := d.nodeByName(n.Name)
+	if sn == nil && n.VolDriverNodeID != "" {
+		sn = d.nodeByName(n.VolDriverNodeID)
+	}
+	if sn == nil {
+		return nil, &ErrNodeNotFound{Node: n.Name}
+	}
+	return sn, nil
+}
+
+// sortedNodes returns the simulated nodes ordered by name
+func (d *simulator) sortedNodes() []*simNode {
+	var nodes []*simNode
+	for _, sn := range d.nodes {
+		nodes = append(nodes, sn)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].name < nodes[j].name
+	})
+	return nodes
+}
+
+// poolByUUID returns the node and pool with the given pool UUID
+func (d *simulator) poolByUUID(poolUUID string) (*simNode, *api.StoragePool) {
+	for _, sn := range d.nodes {
+		for _, pool := range sn.storage.Pools {
+			if pool.Uuid == poolUUID {
+				return sn, pool
+			}
+		}
+	}
+	return nil, nil
+}
+
+// lookupVolume returns the volume with the given ID or name
+func (d *simulator) lookupVolume(nameOrID string) (*simVolume, error) {
+	if sv, ok := d.volumes[nameOrID]; ok {
+		return sv, nil
+	}
+	for _, sv := range d.volumes {
+		if sv.vol.Locator.GetName() == nameOrID {
+			return sv, nil
+		}
+	}
+	return nil, &ErrVolumeNotFound{ID: nameOrID}
+}
+
+// replicaCount returns how many replicas of each volume live on each node
+func (d *simulator) replicaCount() map[string]int {
+	counts := make(map[string]int)
+	for _, sv := range d.volumes {
+		for _, rs := range sv.vol.ReplicaSets {
+			for _, id := range rs.Nodes {
+				counts[id]++
+			}
+		}
+	}
+	return counts
+}
+
+// pickReplicaNodes selects count usable nodes, least loaded first, skipping the excluded node IDs
+func (d *simulator) pickReplicaNodes(count int, exclude map[string]bool) ([]*simNode, error) {
+	counts := d.replicaCount()
+	var candidates []*simNode
+	for _, sn := range d.sortedNodes() {
+		if sn.usable() && len(sn.storage.Pools) > 0 && !exclude[sn.storage.Id] {
+			candidates = append(candidates, sn)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return counts[candidates[i].storage.Id] < counts[candidates[j].storage.Id]
+	})
+	if len(candidates) < count {
+		return nil, fmt.Errorf("need %d nodes to place replicas but only %d are available", count, len(candidates))
+	}
+	return candidates[:count], nil
+}
+
+// pickPool returns the online pool on the node with the most free space
+func pickPool(sn *simNode) *api.StoragePool {
+	var best *api.StoragePool
+	for _, pool := range sn.storage.Pools {
+		if sn.poolStatus[pool.Uuid] != poolStatusOnline {
+			continue
+		}
+		if best == nil || pool.TotalSize-pool.Used > best.TotalSize-best.Used {
+			best = pool
+		}
+	}
+	return best
+}
+
+// addReplica places a replica of the volume on the given node
+func addReplica(vol *api.Volume, sn *simNode, poolUUID string) error {
+	pool := sn.pool(poolUUID)
+	if poolUUID == "" {
+		pool = pickPool(sn)
+	}
+	if pool == nil {
+		return fmt.Errorf("no usable pool on node %s for volume %s", sn.name, vol.Id)
+	}
+	if len(vol.ReplicaSets) == 0 {
+		vol.ReplicaSets = []*api.ReplicaSet{{}}
+	}
+	rs := vol.ReplicaSets[0]
+	rs.Nodes = append(rs.Nodes, sn.storage.Id)
+	rs.PoolUuids = append(rs.PoolUuids, pool.Uuid)
+	pool.Used += vol.Usage
+	return nil
+}
+
+// removeReplica drops the replica of the volume on the given node ID
+func (d *simulator) removeReplica(vol *api.Volume, nodeID string) {
+	for _, rs := range vol.ReplicaSets {
+		for i, id := range rs.Nodes {
+			if id != nodeID {
+				continue
+			}
+			if i < len(rs.PoolUuids) {
+				if _, pool := d.poolByUUID(rs.PoolUuids[i]); pool != nil && pool.Used >= vol.Usage {
+					pool.Used -= vol.Usage
+				}
+				rs.PoolUuids = append(rs.PoolUuids[:i], rs.PoolUuids[i+1:]...)
+			}
+			rs.Nodes = append(rs.Nodes[:i], rs.Nodes[i+1:]...)
+			return
+		}
+	}
+}
+
+func replicaNodes(vol *api.Volume) []string {
+	var nodes []string
+	for _, rs := range vol.ReplicaSets {
+		nodes = append(nodes, rs.Nodes...)
+	}
+	return nodes
+}
+
+func setRuntimeState(vol *api.Volume, state string) {
+	vol.RuntimeState = nil
+	for range replicaNodes(vol) {
+		vol.RuntimeState = append(vol.RuntimeState, &api.RuntimeStateMap{
+			RuntimeState: map[string]string{"RuntimeState": state},
+		})
+	}
+}
+
+// advance moves every simulated operation forward based on the current time.
+// Caller must hold the lock.
+func (d *simulator) advance(now time.Time) {
+	for _, sn := range d.nodes {
+		if !sn.upAt.IsZero() && !now.Before(sn.upAt) {
+			log.Debugf("simulated driver is up on node %s", sn.name)
+			sn.driverUp = true
+			sn.upAt = time.Time{}
+			sn.downSince = time.Time{}
+			if sn.storage.Status == api.Status_STATUS_OFFLINE {
+				sn.storage.Status = api.Status_STATUS_OK
+			}
+		}
+		for poolUUID, op := range sn.poolOps {
+			if now.Before(op.doneAt) || !sn.driverUp {
+				continue
+			}
+			pool := sn.pool(poolUUID)
+			pool.TotalSize = op.target
+			pool.LastOperation = &api.StoragePoolOperation{
+				Type:   api.SdkStoragePool_OPERATION_RESIZE,
+				Msg:    fmt.Sprintf("resized pool to %d bytes", op.target),
+				Status: api.SdkStoragePool_OPERATION_SUCCESSFUL,
+			}
+			delete(sn.poolOps, poolUUID)
+		}
+	}
+	d.advanceKvdb(now)
+
+	for _, sv := range d.volumes {
+		allUsable := true
+		for _, id := range replicaNodes(sv.vol) {
+			if sn := d.nodeByName(id); sn == nil || !sn.usable() {
+				allUsable = false
+			}
+		}
+		switch {
+		case !allUsable:
+			sv.degraded = true
+			sv.vol.Status = api.VolumeStatus_VOLUME_STATUS_DEGRADED
+			setRuntimeState(sv.vol, runtimeStateDegraded)
+		case sv.degraded:
+			// replicas that were unreachable need to catch up before the volume is clean
+			sv.degraded = false
+			sv.resyncUntil = now.Add(d.resyncDelay)
+			fallthrough
+		case now.Before(sv.resyncUntil):
+			sv.vol.Status = api.VolumeStatus_VOLUME_STATUS_UP
+			setRuntimeState(sv.vol, runtimeStateResync)
+		default:
+			sv.vol.Status = api.VolumeStatus_VOLUME_STATUS_UP
+			setRuntimeState(sv.vol, runtimeStateClean)
+		}
+	}
+
+	for id, tv := range d.trashcan {
+		if !now.Before(tv.expiresAt) {
+			delete(d.trashcan, id)
+		}
+	}
+}
+
+// advanceKvdb marks kvdb members whose driver is down as unhealthy, replaces members that stayed
+// down longer than the failover delay and makes sure a healthy member is the leader.
+// Caller must hold the lock.
+func (d *simulator) advanceKvdb(now time.Time) {
+	var members, spares []*simNode
+	for _, sn := range d.sortedNodes() {
+		if sn.kvdbMember {
+			members = append(members, sn)
+		} else if sn.usable() && len(sn.storage.Pools) > 0 {
+			spares = append(spares, sn)
+		}
+	}
+	for _, sn := range members {
+		failed := sn.decommission || (!sn.driverUp && !sn.downSince.IsZero() && now.Sub(sn.downSince) >= d.kvdbFailoverDelay)
+		if !failed || len(spares) == 0 {
+			continue
+		}
+		log.Infof("kvdb member on node %s failed over to node %s", sn.name, spares[0].name)
+		sn.kvdbMember = false
+		sn.kvdbLeader = false
+		spares[0].kvdbMember = true
+		spares = spares[1:]
+	}
+
+	var leader *simNode
+	var firstHealthy *simNode
+	for _, sn := range d.sortedNodes() {
+		if !sn.kvdbMember {
+			continue
+		}
+		if !sn.driverUp {
+			sn.kvdbLeader = false
+		}
+		if sn.kvdbLeader {
+			leader = sn
+		}
+		if firstHealthy == nil && sn.driverUp {
+			firstHealthy = sn
+		}
+	}
+	if leader == nil && firstHealthy != nil {
+		firstHealthy.kvdbLeader = true
+	}
+}
+
+// syncNodes pushes the simulated storage state into the node registry. Caller must hold the lock.
+func (d *simulator) syncNodes() error {
+	registered := node.GetNodesByName()
+	for _, sn := range d.nodes {
+		n, ok := registered[sn.name]
+		if !ok {
+			continue
+		}
+		n.StorageNode = proto.Clone(sn.storage).(*api.StorageNode)
+		n.VolDriverNodeID = sn.storage.Id
+		n.IsStorageDriverInstalled = !sn.decommission
+		n.IsMetadataNode = sn.kvdbMember
+
+		atInit := make(map[string]*api.StoragePool)
+		for _, pool := range n.StoragePools {
+			atInit[pool.Uuid] = pool.StoragePoolAtInit
+		}
+		n.StoragePools = nil
+		for _, pool := range n.StorageNode.Pools {
+			initPool, ok := atInit[pool.Uuid]
+			if !ok || initPool == nil {
+				initPool = proto.Clone(pool).(*api.StoragePool)
+			}
+			n.StoragePools = append(n.StoragePools, node.StoragePool{
+				StoragePool:       pool,
+				StoragePoolAtInit: initPool,
+			})
+		}
+		if err := node.UpdateNode(n); err != nil {
+			return fmt.Errorf("failed to update node [%s], Err: %v", n.Name, err)
+		}
+	}
+	return nil
+}
+
+// newVolume builds a detached volume with the given spec and no replicas yet
+func newVolume(id, name string, spec *api.VolumeSpec, labels map[string]string) *api.Volume {
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	return &api.Volume{
+		Id: id,
+		Locator: &api.VolumeLocator{
+			Name:         name,
+			VolumeLabels: labels,
+		},
+		Source: &api.Source{},
+		Ctime:  timestamppb.Now(),
+		Spec:   spec,
+		Format: spec.Format,
+		Status: api.VolumeStatus_VOLUME_STATUS_UP,
+		State:  api.VolumeState_VOLUME_STATE_DETACHED,
+	}
+}
+
+// specFromParams builds a volume spec from storage class style parameters
+func specFromParams(size uint64, params map[string]string) (*api.VolumeSpec, error) {
+	spec := &api.VolumeSpec{
+		Size:             size,
+		HaLevel:          1,
+		AggregationLevel: 1,
+		Format:           api.FSType_FS_TYPE_EXT4,
+		VolumeLabels:     make(map[string]string),
+	}
+	for k, v := range params {
+		var err error
+		switch strings.ToLower(k) {
+		case "repl":
+			spec.HaLevel, err = strconv.ParseInt(v, 10, 64)
+		case "aggregation_level":
+			var level uint64
+			level, err = strconv.ParseUint(v, 10, 32)
+			spec.AggregationLevel = uint32(level)
+		case "shared":
+			spec.Shared, err = strconv.ParseBool(v)
+		case "sharedv4":
+			spec.Sharedv4, err = strconv.ParseBool(v)
+		case "secure":
+			spec.Encrypted, err = strconv.ParseBool(v)
+		case "fs":
+			fs, ok := api.FSType_value["FS_TYPE_"+strings.ToUpper(v)]
+			if !ok {
+				err = fmt.Errorf("unknown filesystem")
+			}
+			spec.Format = api.FSType(fs)
+		case "priority_io", "io_priority":
+			cos, ok := api.CosType_value[strings.ToUpper(v)]
+			if !ok {
+				err = fmt.Errorf("unknown io priority")
+			}
+			spec.Cos = api.CosType(cos)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for volume parameter %s: %v", v, k, err)
+		}
+	}
+	return spec, nil
+}
@@ -0,0 +1,1060 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/libopenstorage/openstorage/api"
+	"github.com/portworx/sched-ops/task"
+	driver_api "github.com/portworx/torpedo/drivers/api"
+	"github.com/portworx/torpedo/drivers/node"
+	torpedovolume "github.com/portworx/torpedo/drivers/volume"
+	"github.com/portworx/torpedo/pkg/log"
+	"google.golang.org/protobuf/proto"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// DriverName is the name of the simulated volume driver implementation
+	DriverName = "simulator"
+	// SimulatorStorage simulated storage driver name
+	SimulatorStorage torpedovolume.StorageProvisionerType = "simulator"
+
+	// PoolsPerNodeEnvVar is the env var for the number of pools created on every node
+	PoolsPerNodeEnvVar = "SIMULATOR_POOLS_PER_NODE"
+	// PoolSizeEnvVar is the env var for the size of every pool in GiB
+	PoolSizeEnvVar = "SIMULATOR_POOL_SIZE_GIB"
+	// ResyncDelayEnvVar is the env var for how long new or returning replicas take to sync
+	ResyncDelayEnvVar = "SIMULATOR_RESYNC_DELAY"
+	// OperationDelayEnvVar is the env var for how long driver starts, pool resizes and cloudsnaps take
+	OperationDelayEnvVar = "SIMULATOR_OPERATION_DELAY"
+	// KvdbFailoverDelayEnvVar is the env var for how long a kvdb member may be down before it is replaced
+	KvdbFailoverDelayEnvVar = "SIMULATOR_KVDB_FAILOVER_DELAY"
+	// FailuresEnvVar is the env var with failures to inject at init, see parseFailures for the format
+	FailuresEnvVar = "SIMULATOR_FAILURES"
+	// VersionEnvVar is the env var for the version reported by the simulated driver
+	VersionEnvVar = "SIMULATOR_VERSION"
+
+	defaultPoolsPerNode      = 2
+	defaultPoolSizeGiB       = 100
+	defaultResyncDelay       = 5 * time.Second
+	defaultOperationDelay    = 2 * time.Second
+	defaultKvdbFailoverDelay = 10 * time.Second
+	defaultVersion           = "2.13.0.0-simulated"
+	defaultTimeout           = 2 * time.Minute
+	defaultRetryInterval     = 1 * time.Second
+	kvdbMemberCount          = 3
+	driverNamespace          = "kube-system"
+	volumeExpirationOpt      = "--volume-expiration-minutes"
+)
+
+// Provisioners types of supported provisioners
+var provisioners = map[torpedovolume.StorageProvisionerType]torpedovolume.StorageProvisionerType{
+	SimulatorStorage: "simulator",
+}
+
+type simulator struct {
+	torpedovolume.DefaultDriver
+	poolsPerNode      int
+	poolSize          uint64
+	resyncDelay       time.Duration
+	operationDelay    time.Duration
+	kvdbFailoverDelay time.Duration
+	version           string
+
+	lock        sync.Mutex
+	nodes       map[string]*simNode
+	volumes     map[string]*simVolume
+	cloudsnaps  map[string]*cloudsnap
+	trashcan    map[string]*trashedVolume
+	clusterOpts map[string]string
+}
+
+func (d *simulator) String() string {
+	return DriverName
+}
+
+// Init builds the simulated storage cluster on top of the worker nodes in the node registry
+func (d *simulator) Init(sched, nodeDriver, token, storageProvisioner, csiGenericDriverConfigMap string) error {
+	log.Infof("Using the simulated volume driver with provisioner %s under scheduler: %v", storageProvisioner, sched)
+	torpedovolume.StorageDriver = DriverName
+	// The simulator stands in for whichever provisioner the app specs were written for
+	if storageProvisioner == "" {
+		return fmt.Errorf("Provisioner is empty for volume driver: %s", DriverName)
+	}
+	if p, ok := provisioners[torpedovolume.StorageProvisionerType(storageProvisioner)]; ok {
+		torpedovolume.StorageProvisioner = p
+	} else {
+		torpedovolume.StorageProvisioner = torpedovolume.StorageProvisionerType(storageProvisioner)
+	}
+
+	var err error
+	if d.poolsPerNode, err = intFromEnv(PoolsPerNodeEnvVar, defaultPoolsPerNode); err != nil {
+		return err
+	}
+	poolSizeGiB, err := intFromEnv(PoolSizeEnvVar, defaultPoolSizeGiB)
+	if err != nil {
+		return err
+	}
+	d.poolSize = uint64(poolSizeGiB) << 30
+	if d.resyncDelay, err = durationFromEnv(ResyncDelayEnvVar, defaultResyncDelay); err != nil {
+		return err
+	}
+	if d.operationDelay, err = durationFromEnv(OperationDelayEnvVar, defaultOperationDelay); err != nil {
+		return err
+	}
+	if d.kvdbFailoverDelay, err = durationFromEnv(KvdbFailoverDelayEnvVar, defaultKvdbFailoverDelay); err != nil {
+		return err
+	}
+	d.version = defaultVersion
+	if val := os.Getenv(VersionEnvVar); val != "" {
+		d.version = val
+	}
+	if val := os.Getenv(FailuresEnvVar); val != "" {
+		if err = parseFailures(val); err != nil {
+			return fmt.Errorf("invalid value %q for %s: %v", val, FailuresEnvVar, err)
+		}
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.nodes = make(map[string]*simNode)
+	d.volumes = make(map[string]*simVolume)
+	d.cloudsnaps = make(map[string]*cloudsnap)
+	d.trashcan = make(map[string]*trashedVolume)
+	d.clusterOpts = make(map[string]string)
+	for _, n := range node.GetWorkerNodes() {
+		if !n.IsStorageDriverInstalled {
+			continue
+		}
+		d.nodes[n.Name] = newSimNode(n, d.poolsPerNode, d.poolSize)
+	}
+	if len(d.nodes) == 0 {
+		return fmt.Errorf("no worker nodes with the storage driver installed were found for the simulated cluster")
+	}
+	for i, sn := range d.sortedNodes() {
+		if i < kvdbMemberCount {
+			sn.kvdbMember = true
+			sn.kvdbLeader = i == 0
+		}
+	}
+	log.Infof("Simulated storage cluster has %d nodes with %d pools of %d GiB each", len(d.nodes), d.poolsPerNode, poolSizeGiB)
+	return d.syncNodes()
+}
+
+func intFromEnv(envVar string, defaultValue int) (int, error) {
+	val := os.Getenv(envVar)
+	if val == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid value %q for %s", val, envVar)
+	}
+	return i, nil
+}
+
+func durationFromEnv(envVar string, defaultValue time.Duration) (time.Duration, error) {
+	val := os.Getenv(envVar)
+	if val == "" {
+		return defaultValue, nil
+	}
+	dur, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for %s: %v", val, envVar, err)
+	}
+	return dur, nil
+}
+
+// refresh advances the simulation and returns with the lock held. Callers must unlock.
+func (d *simulator) refresh() {
+	d.lock.Lock()
+	d.advance(time.Now())
+}
+
+// GetVolumeDriverNamespace returns the namespace the simulated driver pretends to run in
+func (d *simulator) GetVolumeDriverNamespace() (string, error) {
+	return driverNamespace, nil
+}
+
+// RefreshDriverEndpoints updates the node registry with the current simulated state
+func (d *simulator) RefreshDriverEndpoints() error {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	return d.syncNodes()
+}
+
+// ValidateDriver checks that the driver is up on every node which isn't decommissioned
+func (d *simulator) ValidateDriver(endpointVersion string, autoUpdateComponents bool) error {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	for _, sn := range d.sortedNodes() {
+		if !sn.decommission && !sn.driverUp {
+			return fmt.Errorf("simulated driver is not up on node %s", sn.name)
+		}
+	}
+	return nil
+}
+
+func (d *simulator) ValidateVolumeCleanup() error {
+	return nil
+}
+
+// GetDriverVersion returns the version reported by the simulated driver
+func (d *simulator) GetDriverVersion() (string, error) {
+	return d.version, nil
+}
+
+// GetDriverVersionOnNode returns the version of the simulated driver on the given node
+func (d *simulator) GetDriverVersionOnNode(n node.Node) (string, error) {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	if _, err := d.lookupNode(n); err != nil {
+		return "", err
+	}
+	return d.version, nil
+}
+
+// IsOperatorBasedInstall returns false as there is no StorageCluster behind the simulator
+func (d *simulator) IsOperatorBasedInstall() (bool, error) {
+	return false, nil
+}
+
+// GetDriverNode returns the simulated storage node for the given node
+func (d *simulator) GetDriverNode(n *node.Node, nManagers ...api.OpenStorageNodeClient) (*api.StorageNode, error) {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(*n)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(sn.storage).(*api.StorageNode), nil
+}
+
+// GetDriverNodes returns all simulated storage nodes
+func (d *simulator) GetDriverNodes() ([]*api.StorageNode, error) {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	var nodes []*api.StorageNode
+	for _, sn := range d.sortedNodes() {
+		nodes = append(nodes, proto.Clone(sn.storage).(*api.StorageNode))
+	}
+	return nodes, nil
+}
+
+// GetStoragelessNodes returns the simulated nodes which have no pools
+func (d *simulator) GetStoragelessNodes() ([]*api.StorageNode, error) {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	var nodes []*api.StorageNode
+	for _, sn := range d.sortedNodes() {
+		if len(sn.storage.Pools) == 0 && !sn.decommission {
+			nodes = append(nodes, proto.Clone(sn.storage).(*api.StorageNode))
+		}
+	}
+	return nodes, nil
+}
+
+// Contains checks if the list of storage nodes has a node with the same ID
+func (d *simulator) Contains(nodes []*api.StorageNode, n *api.StorageNode) bool {
+	for _, sn := range nodes {
+		if sn.Id == n.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateNodeWithStorageInfo updates the node registry with the simulated storage state
+func (d *simulator) UpdateNodeWithStorageInfo(n node.Node, skipNodeName string) error {
+	return d.RefreshDriverEndpoints()
+}
+
+// IsDriverInstalled returns true if the node is part of the simulated cluster
+func (d *simulator) IsDriverInstalled(n node.Node) (bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return false, nil
+	}
+	return !sn.decommission, nil
+}
+
+// GetStorageDevices returns the drives used by the simulated node
+func (d *simulator) GetStorageDevices(n node.Node) ([]string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return nil, err
+	}
+	var devices []string
+	for drive := range sn.storage.Disks {
+		devices = append(devices, drive)
+	}
+	sort.Strings(devices)
+	return devices, nil
+}
+
+// GetNodeStatus returns the status of the simulated storage node
+func (d *simulator) GetNodeStatus(n node.Node) (*api.Status, error) {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return nil, err
+	}
+	status := sn.storage.Status
+	return &status, nil
+}
+
+// StopDriver stops the simulated driver on the given nodes. Replicas on those nodes become unusable.
+func (d *simulator) StopDriver(nodes []node.Node, force bool, triggerOpts *driver_api.TriggerOptions) error {
+	if err := injectedFailure("StopDriver"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	now := time.Now()
+	for _, n := range nodes {
+		sn, err := d.lookupNode(n)
+		if err != nil {
+			return err
+		}
+		log.Infof("Stopping simulated volume driver on node [%s], force: %v", n.Name, force)
+		sn.driverUp = false
+		sn.upAt = time.Time{}
+		if sn.downSince.IsZero() {
+			sn.downSince = now
+		}
+		if sn.storage.Status == api.Status_STATUS_OK {
+			sn.storage.Status = api.Status_STATUS_OFFLINE
+		}
+	}
+	d.advance(now)
+	return d.syncNodes()
+}
+
+// StartDriver starts the simulated driver on the given node, it becomes usable after the operation delay
+func (d *simulator) StartDriver(n node.Node) error {
+	if err := injectedFailure("StartDriver"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return err
+	}
+	if sn.driverUp || !sn.upAt.IsZero() {
+		return nil
+	}
+	log.Infof("Starting simulated volume driver on node [%s]", n.Name)
+	sn.upAt = time.Now().Add(d.operationDelay)
+	return nil
+}
+
+// RestartDriver stops and starts the simulated driver on the given node
+func (d *simulator) RestartDriver(n node.Node, triggerOpts *driver_api.TriggerOptions) error {
+	if err := injectedFailure("RestartDriver"); err != nil {
+		return err
+	}
+	if err := d.StopDriver([]node.Node{n}, false, triggerOpts); err != nil {
+		return err
+	}
+	return d.StartDriver(n)
+}
+
+// RecoverDriver brings the simulated driver back up and clears any maintenance state on the node
+func (d *simulator) RecoverDriver(n node.Node) error {
+	if err := injectedFailure("RecoverDriver"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return err
+	}
+	if sn.driverUp {
+		sn.storage.Status = api.Status_STATUS_OK
+	} else {
+		sn.storage.Status = api.Status_STATUS_OFFLINE
+		sn.upAt = time.Now().Add(d.operationDelay)
+	}
+	for poolUUID := range sn.poolStatus {
+		sn.poolStatus[poolUUID] = poolStatusOnline
+	}
+	return d.syncNodes()
+}
+
+// WaitDriverUpOnNode waits till the simulated driver is usable on the given node
+func (d *simulator) WaitDriverUpOnNode(n node.Node, timeout time.Duration) error {
+	t := func() (interface{}, bool, error) {
+		d.refresh()
+		defer d.lock.Unlock()
+
+		sn, err := d.lookupNode(n)
+		if err != nil {
+			return nil, false, err
+		}
+		if !sn.driverUp {
+			return nil, true, fmt.Errorf("simulated driver is not up yet on node %s", n.Name)
+		}
+		return nil, false, d.syncNodes()
+	}
+
+	_, err := task.DoRetryWithTimeout(t, timeout, retryInterval(timeout))
+	return err
+}
+
+// WaitDriverDownOnNode waits till the simulated driver is down on the given node
+func (d *simulator) WaitDriverDownOnNode(n node.Node) error {
+	t := func() (interface{}, bool, error) {
+		d.refresh()
+		defer d.lock.Unlock()
+
+		sn, err := d.lookupNode(n)
+		if err != nil {
+			return nil, false, err
+		}
+		if sn.driverUp {
+			return nil, true, fmt.Errorf("simulated driver is still up on node %s", n.Name)
+		}
+		return nil, false, nil
+	}
+
+	_, err := task.DoRetryWithTimeout(t, defaultTimeout, defaultRetryInterval)
+	return err
+}
+
+// WaitForPxPodsToBeUp waits till the simulated driver is usable on the given node
+func (d *simulator) WaitForPxPodsToBeUp(n node.Node) error {
+	return d.WaitDriverUpOnNode(n, defaultTimeout)
+}
+
+func retryInterval(timeout time.Duration) time.Duration {
+	if interval := timeout / 20; interval < defaultRetryInterval {
+		return interval
+	}
+	return defaultRetryInterval
+}
+
+// EnterMaintenance puts the simulated node in maintenance mode
+func (d *simulator) EnterMaintenance(n node.Node) error {
+	if err := injectedFailure("EnterMaintenance"); err != nil {
+		return err
+	}
+	return d.setNodeStatus(n, api.Status_STATUS_OK, api.Status_STATUS_MAINTENANCE, poolStatusOnline)
+}
+
+// ExitMaintenance takes the simulated node out of maintenance mode
+func (d *simulator) ExitMaintenance(n node.Node) error {
+	if err := injectedFailure("ExitMaintenance"); err != nil {
+		return err
+	}
+	return d.setNodeStatus(n, api.Status_STATUS_MAINTENANCE, api.Status_STATUS_OK, poolStatusOnline)
+}
+
+// EnterPoolMaintenance puts all pools of the simulated node in maintenance mode
+func (d *simulator) EnterPoolMaintenance(n node.Node) error {
+	if err := injectedFailure("EnterPoolMaintenance"); err != nil {
+		return err
+	}
+	return d.setNodeStatus(n, api.Status_STATUS_OK, api.Status_STATUS_POOLMAINTENANCE, poolStatusMaintenance)
+}
+
+// ExitPoolMaintenance takes all pools of the simulated node out of maintenance mode
+func (d *simulator) ExitPoolMaintenance(n node.Node) error {
+	if err := injectedFailure("ExitPoolMaintenance"); err != nil {
+		return err
+	}
+	return d.setNodeStatus(n, api.Status_STATUS_POOLMAINTENANCE, api.Status_STATUS_OK, poolStatusOnline)
+}
+
+// setNodeStatus moves the node from the given status to the new one and sets the status of its pools
+func (d *simulator) setNodeStatus(n node.Node, from, to api.Status, poolStatus string) error {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return err
+	}
+	if !sn.driverUp {
+		return fmt.Errorf("simulated driver is not up on node %s", n.Name)
+	}
+	if sn.storage.Status == to {
+		return nil
+	}
+	if sn.storage.Status != from {
+		return fmt.Errorf("node %s is in state %s, expected %s", n.Name, sn.storage.Status, from)
+	}
+	log.Infof("Moving simulated node [%s] from %s to %s", n.Name, from, to)
+	sn.storage.Status = to
+	for poolUUID := range sn.poolStatus {
+		sn.poolStatus[poolUUID] = poolStatus
+	}
+	d.advance(time.Now())
+	return d.syncNodes()
+}
+
+// IsNodeInMaintenance returns true if the simulated node is in maintenance mode
+func (d *simulator) IsNodeInMaintenance(n node.Node) (bool, error) {
+	status, err := d.GetNodeStatus(n)
+	if err != nil {
+		return false, err
+	}
+	return *status == api.Status_STATUS_MAINTENANCE, nil
+}
+
+// IsNodeOutOfMaintenance returns true if the simulated node is up and out of maintenance mode
+func (d *simulator) IsNodeOutOfMaintenance(n node.Node) (bool, error) {
+	status, err := d.GetNodeStatus(n)
+	if err != nil {
+		return false, err
+	}
+	return *status == api.Status_STATUS_OK, nil
+}
+
+// DecommissionNode removes the node from the simulated cluster, moving its replicas to other nodes
+func (d *simulator) DecommissionNode(n *node.Node) error {
+	if err := injectedFailure("DecommissionNode"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(*n)
+	if err != nil {
+		return err
+	}
+	if sn.decommission {
+		return nil
+	}
+
+	now := time.Now()
+	for _, id := range sortedKeys(d.volumes) {
+		sv := d.volumes[id]
+		replicas := replicaNodes(sv.vol)
+		if !containsString(replicas, sn.storage.Id) {
+			continue
+		}
+		exclude := map[string]bool{sn.storage.Id: true}
+		for _, nodeID := range replicas {
+			exclude[nodeID] = true
+		}
+		replacement, err := d.pickReplicaNodes(1, exclude)
+		if err != nil {
+			if len(replicas) == 1 {
+				return fmt.Errorf("cannot decommission node %s: volume %s has its only replica there and %v", n.Name, id, err)
+			}
+			d.removeReplica(sv.vol, sn.storage.Id)
+			sv.vol.Spec.HaLevel--
+			continue
+		}
+		d.removeReplica(sv.vol, sn.storage.Id)
+		if err = addReplica(sv.vol, replacement[0], ""); err != nil {
+			return err
+		}
+		sv.resyncUntil = now.Add(d.resyncDelay)
+	}
+
+	log.Infof("Decommissioning simulated node [%s]", n.Name)
+	sn.decommission = true
+	sn.driverUp = false
+	sn.upAt = time.Time{}
+	sn.storage.Status = api.Status_STATUS_DECOMMISSION
+	d.advance(now)
+	return d.syncNodes()
+}
+
+// RejoinNode adds a decommissioned node back to the simulated cluster with fresh storage
+func (d *simulator) RejoinNode(n *node.Node) error {
+	if err := injectedFailure("RejoinNode"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(*n)
+	if err != nil {
+		return err
+	}
+	if !sn.decommission {
+		return fmt.Errorf("node %s is not decommissioned", n.Name)
+	}
+	registered, err := node.GetNodeByName(sn.name)
+	if err != nil {
+		return err
+	}
+	registered.StoragePools = nil
+	if err = node.UpdateNode(registered); err != nil {
+		return err
+	}
+	d.nodes[sn.name] = newSimNode(registered, d.poolsPerNode, d.poolSize)
+	log.Infof("Simulated node [%s] rejoined the cluster with ID %s", n.Name, d.nodes[sn.name].storage.Id)
+	return d.syncNodes()
+}
+
+// RecoverNode brings a node back to normal operation
+func (d *simulator) RecoverNode(n *node.Node) error {
+	return d.RecoverDriver(*n)
+}
+
+// GetKvdbMembers returns the simulated kvdb members keyed by storage node ID
+func (d *simulator) GetKvdbMembers(n node.Node) (map[string]*torpedovolume.MetadataNode, error) {
+	if err := injectedFailure("GetKvdbMembers"); err != nil {
+		return nil, err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	members := make(map[string]*torpedovolume.MetadataNode)
+	for _, sn := range d.sortedNodes() {
+		if !sn.kvdbMember {
+			continue
+		}
+		members[sn.storage.Id] = &torpedovolume.MetadataNode{
+			PeerUrls:   []string{fmt.Sprintf("http://%s:%d", sn.ip, kvdbPeerPort)},
+			ClientUrls: []string{fmt.Sprintf("http://%s:%d", sn.ip, kvdbClientPort)},
+			Leader:     sn.kvdbLeader,
+			DbSize:     kvdbDbSize,
+			IsHealthy:  sn.driverUp,
+			ID:         sn.storage.Id,
+		}
+	}
+	return members, nil
+}
+
+// SetClusterOpts sets simulated cluster options
+func (d *simulator) SetClusterOpts(n node.Node, clusterOpts map[string]string) error {
+	if err := injectedFailure("SetClusterOpts"); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for k, v := range clusterOpts {
+		d.clusterOpts[k] = v
+	}
+	return nil
+}
+
+// SetClusterOptsWithConfirmation sets simulated cluster options, they take effect immediately
+func (d *simulator) SetClusterOptsWithConfirmation(n node.Node, clusterOpts map[string]string) error {
+	return d.SetClusterOpts(n, clusterOpts)
+}
+
+// SetClusterRunTimeOpts sets simulated runtime options
+func (d *simulator) SetClusterRunTimeOpts(n node.Node, rtOpts map[string]string) error {
+	return d.SetClusterOpts(n, rtOpts)
+}
+
+// GetClusterOpts returns the requested simulated cluster options
+func (d *simulator) GetClusterOpts(n node.Node, options []string) (map[string]string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	opts := make(map[string]string)
+	for _, opt := range options {
+		val, ok := d.clusterOpts[opt]
+		if !ok {
+			return nil, fmt.Errorf("cluster option %s is not set", opt)
+		}
+		opts[opt] = val
+	}
+	return opts, nil
+}
+
+// ToggleCallHome records the call-home setting as a cluster option
+func (d *simulator) ToggleCallHome(n node.Node, enabled bool) error {
+	return d.SetClusterOpts(n, map[string]string{"--callhome": strconv.FormatBool(enabled)})
+}
+
+// GetNodePoolsStatus returns the status of every pool on the node
+func (d *simulator) GetNodePoolsStatus(n node.Node) (map[string]string, error) {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]string)
+	for poolUUID, status := range sn.poolStatus {
+		if !sn.driverUp {
+			status = poolStatusOffline
+		}
+		statuses[poolUUID] = status
+	}
+	return statuses, nil
+}
+
+// RecoverPool brings all pools on the node back online
+func (d *simulator) RecoverPool(n node.Node) error {
+	return d.RecoverDriver(n)
+}
+
+// UpdatePoolIOPriority sets the IO priority of the given pool
+func (d *simulator) UpdatePoolIOPriority(n node.Node, poolUUID string, IOPriority string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return err
+	}
+	pool := sn.pool(poolUUID)
+	if pool == nil {
+		return fmt.Errorf("pool %s not found on node %s", poolUUID, n.Name)
+	}
+	cos, ok := api.CosType_value[IOPriority]
+	if !ok {
+		return fmt.Errorf("invalid IO priority %s", IOPriority)
+	}
+	pool.Cos = api.CosType(cos)
+	pool.Labels["iopriority"] = IOPriority
+	return d.syncNodes()
+}
+
+// DeletePool deletes a pool which has no replicas on it
+func (d *simulator) DeletePool(n node.Node, poolID string) error {
+	if err := injectedFailure("DeletePool"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return err
+	}
+	pool := sn.pool(poolID)
+	if pool == nil {
+		return fmt.Errorf("pool %s not found on node %s", poolID, n.Name)
+	}
+	if sn.storage.Status != api.Status_STATUS_POOLMAINTENANCE {
+		return fmt.Errorf("node %s must be in pool maintenance to delete pool %s", n.Name, poolID)
+	}
+	for _, sv := range d.volumes {
+		for _, rs := range sv.vol.ReplicaSets {
+			if containsString(rs.PoolUuids, pool.Uuid) {
+				return fmt.Errorf("pool %s still has replicas of volume %s", poolID, sv.vol.Id)
+			}
+		}
+	}
+	for i, p := range sn.storage.Pools {
+		if p == pool {
+			sn.storage.Pools = append(sn.storage.Pools[:i], sn.storage.Pools[i+1:]...)
+			break
+		}
+	}
+	for _, drive := range sn.drives[pool.ID] {
+		delete(sn.storage.Disks, drive)
+	}
+	delete(sn.drives, pool.ID)
+	delete(sn.poolStatus, pool.Uuid)
+	delete(sn.poolOps, pool.Uuid)
+	return d.syncNodes()
+}
+
+// ExpandPool resizes the given pool to size GiB. The resize finishes after the operation delay.
+func (d *simulator) ExpandPool(poolUUID string, operation api.SdkStoragePool_ResizeOperationType, size uint64) error {
+	if err := injectedFailure("ExpandPool"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	log.Infof("Initiating pool %v resize to %v GiB with operationtype %v", poolUUID, size, operation.String())
+	return d.startPoolResize(poolUUID, size<<30)
+}
+
+// ExpandPoolUsingPxctlCmd resizes the given pool on the node to size GiB
+func (d *simulator) ExpandPoolUsingPxctlCmd(n node.Node, poolUUID string, operation api.SdkStoragePool_ResizeOperationType, size uint64) error {
+	return d.ExpandPool(poolUUID, operation, size)
+}
+
+// ResizeStoragePoolByPercentage grows the given pool by the given percentage
+func (d *simulator) ResizeStoragePoolByPercentage(poolUUID string, operation api.SdkStoragePool_ResizeOperationType, percentage uint64) error {
+	if err := injectedFailure("ResizeStoragePoolByPercentage"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	_, pool := d.poolByUUID(poolUUID)
+	if pool == nil {
+		return fmt.Errorf("pool %s not found", poolUUID)
+	}
+	log.Infof("Initiating pool %v resize by %v%% with operationtype %v", poolUUID, percentage, operation.String())
+	return d.startPoolResize(poolUUID, pool.TotalSize+pool.TotalSize*percentage/100)
+}
+
+// startPoolResize records a pending resize of the pool to target bytes. Caller must hold the lock.
+func (d *simulator) startPoolResize(poolUUID string, target uint64) error {
+	sn, pool := d.poolByUUID(poolUUID)
+	if pool == nil {
+		return fmt.Errorf("pool %s not found", poolUUID)
+	}
+	if _, ok := sn.poolOps[poolUUID]; ok {
+		return fmt.Errorf("pool %s already has a resize in progress", poolUUID)
+	}
+	if target <= pool.TotalSize {
+		return fmt.Errorf("pool %s can't be resized to %d bytes, it is already %d bytes", poolUUID, target, pool.TotalSize)
+	}
+	sn.poolOps[poolUUID] = &poolOp{
+		target: target,
+		doneAt: time.Now().Add(d.operationDelay),
+	}
+	pool.LastOperation = &api.StoragePoolOperation{
+		Type:   api.SdkStoragePool_OPERATION_RESIZE,
+		Msg:    fmt.Sprintf("resizing pool to %d bytes", target),
+		Status: api.SdkStoragePool_OPERATION_IN_PROGRESS,
+	}
+	return d.syncNodes()
+}
+
+// IsStorageExpansionEnabled returns true as simulated pools can always be expanded
+func (d *simulator) IsStorageExpansionEnabled() (bool, error) {
+	return true, nil
+}
+
+// ListStoragePools returns the pools matching the given label selector keyed by pool UUID
+func (d *simulator) ListStoragePools(labelSelector metav1.LabelSelector) (map[string]*api.StoragePool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	d.refresh()
+	defer d.lock.Unlock()
+
+	pools := make(map[string]*api.StoragePool)
+	for _, sn := range d.nodes {
+		for _, pool := range sn.storage.Pools {
+			if selector.Matches(labels.Set(pool.Labels)) {
+				pools[pool.Uuid] = proto.Clone(pool).(*api.StoragePool)
+			}
+		}
+	}
+	return pools, nil
+}
+
+// ValidateStoragePools waits for pending pool resizes and checks the node registry matches the simulated pools
+func (d *simulator) ValidateStoragePools() error {
+	if err := injectedFailure("ValidateStoragePools"); err != nil {
+		return err
+	}
+	t := func() (interface{}, bool, error) {
+		d.refresh()
+		defer d.lock.Unlock()
+
+		for _, sn := range d.sortedNodes() {
+			for poolUUID := range sn.poolOps {
+				return nil, true, fmt.Errorf("node [%s], pool: %s still has a resize in progress", sn.name, poolUUID)
+			}
+		}
+		return nil, false, d.syncNodes()
+	}
+	if _, err := task.DoRetryWithTimeout(t, defaultTimeout, retryInterval(d.operationDelay)); err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, n := range node.GetWorkerNodes() {
+		sn := d.nodeByName(n.Name)
+		if sn == nil || sn.decommission {
+			continue
+		}
+		for _, pool := range n.StoragePools {
+			simPool := sn.pool(pool.Uuid)
+			if simPool == nil {
+				return fmt.Errorf("node [%s], pool: %s is not known to the simulated cluster", n.Name, pool.Uuid)
+			}
+			if pool.TotalSize != simPool.TotalSize {
+				return fmt.Errorf("node [%s], pool: %s, size is not as expected. Expected: %v, Actual: %v",
+					n.Name, pool.Uuid, simPool.TotalSize, pool.TotalSize)
+			}
+			if pool.Used > pool.TotalSize {
+				return fmt.Errorf("node [%s], pool: %s uses %d bytes which is more than its size %d",
+					n.Name, pool.Uuid, pool.Used, pool.TotalSize)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateRebalanceJobs returns nil as the simulated cluster doesn't rebalance
+func (d *simulator) ValidateRebalanceJobs() error {
+	return nil
+}
+
+// GetRebalanceJobs returns no jobs as the simulated cluster doesn't rebalance
+func (d *simulator) GetRebalanceJobs() ([]*api.StorageRebalanceJob, error) {
+	return nil, nil
+}
+
+// UpdatePoolLabels adds the given labels to the pool
+func (d *simulator) UpdatePoolLabels(n node.Node, poolID string, labels map[string]string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(n)
+	if err != nil {
+		return err
+	}
+	pool := sn.pool(poolID)
+	if pool == nil {
+		return fmt.Errorf("pool %s not found on node %s", poolID, n.Name)
+	}
+	for k, v := range labels {
+		pool.Labels[k] = v
+	}
+	return d.syncNodes()
+}
+
+// GetPoolLabelValue returns the value of the given label on the pool
+func (d *simulator) GetPoolLabelValue(poolUUID string, label string) (string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	_, pool := d.poolByUUID(poolUUID)
+	if pool == nil {
+		return "", fmt.Errorf("pool %s not found", poolUUID)
+	}
+	return pool.Labels[label], nil
+}
+
+// GetPoolDrives returns the drives of every pool on the node keyed by pool ID
+func (d *simulator) GetPoolDrives(n *node.Node) (map[string][]string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(*n)
+	if err != nil {
+		return nil, err
+	}
+	poolDrives := make(map[string][]string)
+	for poolID, drives := range sn.drives {
+		poolDrives[strconv.Itoa(int(poolID))] = append([]string{}, drives...)
+	}
+	return poolDrives, nil
+}
+
+// GetPoolsUsedSize returns the used bytes of every pool on the node keyed by pool UUID
+func (d *simulator) GetPoolsUsedSize(n *node.Node) (map[string]string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(*n)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]string)
+	for _, pool := range sn.storage.Pools {
+		used[pool.Uuid] = strconv.FormatUint(pool.Used, 10)
+	}
+	return used, nil
+}
+
+// AddBlockDrives creates a new pool on the node for every given drive
+func (d *simulator) AddBlockDrives(n *node.Node, drivePath []string) error {
+	if err := injectedFailure("AddBlockDrives"); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(*n)
+	if err != nil {
+		return err
+	}
+	for _, drive := range drivePath {
+		if _, ok := sn.storage.Disks[drive]; ok {
+			return fmt.Errorf("drive %s is already in use on node %s", drive, n.Name)
+		}
+	}
+	for _, drive := range drivePath {
+		sn.addPool(d.poolSize, drive)
+	}
+	return d.syncNodes()
+}
+
+var cloudDriveSizeRegex = regexp.MustCompile(`size=(\d+)`)
+
+// AddCloudDrive adds a drive described by the device spec (e.g. "type=gp2,size=150") to the given
+// pool, or to a new pool if poolID is -1
+func (d *simulator) AddCloudDrive(n *node.Node, deviceSpec string, poolID int32) error {
+	if err := injectedFailure("AddCloudDrive"); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sn, err := d.lookupNode(*n)
+	if err != nil {
+		return err
+	}
+	size := d.poolSize
+	if match := cloudDriveSizeRegex.FindStringSubmatch(deviceSpec); match != nil {
+		sizeGiB, _ := strconv.ParseUint(match[1], 10, 64)
+		size = sizeGiB << 30
+	}
+	drive := fmt.Sprintf("/dev/simdrive%d", len(sn.storage.Disks))
+	if poolID == -1 {
+		sn.addPool(size, drive)
+		return d.syncNodes()
+	}
+	pool := sn.pool(strconv.Itoa(int(poolID)))
+	if pool == nil {
+		return fmt.Errorf("pool %d not found on node %s", poolID, n.Name)
+	}
+	sn.addDrive(pool, drive, size)
+	pool.TotalSize += size
+	return d.syncNodes()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	torpedovolume.Register(DriverName, provisioners, &simulator{})
+}
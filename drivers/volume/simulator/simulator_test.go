@@ -0,0 +1,160 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/libopenstorage/openstorage/api"
+	"github.com/portworx/torpedo/drivers/node"
+	torpedovolume "github.com/portworx/torpedo/drivers/volume"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTimeout = 5 * time.Second
+
+func newTestDriver(t *testing.T) *simulator {
+	os.Setenv(ResyncDelayEnvVar, "100ms")
+	os.Setenv(OperationDelayEnvVar, "50ms")
+	os.Setenv(KvdbFailoverDelayEnvVar, "100ms")
+	defer os.Unsetenv(ResyncDelayEnvVar)
+	defer os.Unsetenv(OperationDelayEnvVar)
+	defer os.Unsetenv(KvdbFailoverDelayEnvVar)
+
+	ClearFailures()
+	node.CleanupRegistry()
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, node.AddNode(node.Node{
+			Name:                     fmt.Sprintf("sim-node-%d", i),
+			Addresses:                []string{fmt.Sprintf("10.0.2.%d", i)},
+			Type:                     node.TypeWorker,
+			IsStorageDriverInstalled: true,
+		}))
+	}
+	d := &simulator{}
+	require.NoError(t, d.Init("fake", "", "", "portworx", ""))
+	return d
+}
+
+func TestReplicationChange(t *testing.T) {
+	d := newTestDriver(t)
+
+	id, err := d.CreateVolume("repl-vol", 1<<30, 2)
+	require.NoError(t, err)
+	vol := &torpedovolume.Volume{ID: id}
+
+	require.NoError(t, d.SetReplicationFactor(vol, 3, nil, nil, false))
+	err = d.WaitForReplicationToComplete(vol, 3, 10*time.Millisecond)
+	assert.Error(t, err, "new replica should still be syncing")
+	require.NoError(t, d.WaitForReplicationToComplete(vol, 3, testTimeout))
+
+	replicaSets, err := d.GetReplicaSets(vol)
+	require.NoError(t, err)
+	require.Len(t, replicaSets[0].Nodes, 3)
+
+	// a replica on a stopped node keeps the volume from being clean until the driver is back
+	replicaNode := node.GetNodesByVoDriverNodeID()[replicaSets[0].Nodes[0]]
+	require.NoError(t, d.StopDriver([]node.Node{replicaNode}, false, nil))
+	inspected, err := d.InspectVolume(id)
+	require.NoError(t, err)
+	assert.Equal(t, api.VolumeStatus_VOLUME_STATUS_DEGRADED, inspected.Status)
+	assert.Error(t, d.WaitForReplicationToComplete(vol, 3, 200*time.Millisecond))
+
+	require.NoError(t, d.StartDriver(replicaNode))
+	require.NoError(t, d.WaitDriverUpOnNode(replicaNode, testTimeout))
+	require.NoError(t, d.WaitForReplicationToComplete(vol, 3, testTimeout))
+
+	require.NoError(t, d.SetReplicationFactor(vol, 2, []string{replicaNode.VolDriverNodeID}, nil, true))
+	replicaSets, err = d.GetReplicaSets(vol)
+	require.NoError(t, err)
+	assert.NotContains(t, replicaSets[0].Nodes, replicaNode.VolDriverNodeID)
+
+	assert.Error(t, d.SetReplicationFactor(vol, 4, nil, nil, false), "max replication factor is 3")
+}
+
+func TestPoolsAndMaintenance(t *testing.T) {
+	d := newTestDriver(t)
+
+	n := node.GetWorkerNodes()[0]
+	require.Len(t, n.StoragePools, defaultPoolsPerNode)
+	pool := n.StoragePools[0]
+
+	require.NoError(t, d.ExpandPool(pool.Uuid, api.SdkStoragePool_RESIZE_TYPE_AUTO, 2*defaultPoolSizeGiB))
+	require.NoError(t, d.ValidateStoragePools())
+	n, err := node.GetNodeByName(n.Name)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2*defaultPoolSizeGiB)<<30, n.StoragePools[0].TotalSize)
+	assert.Equal(t, uint64(defaultPoolSizeGiB)<<30, n.StoragePools[0].StoragePoolAtInit.TotalSize)
+
+	require.NoError(t, d.EnterMaintenance(n))
+	inMaintenance, err := d.IsNodeInMaintenance(n)
+	require.NoError(t, err)
+	assert.True(t, inMaintenance)
+	assert.Error(t, d.EnterPoolMaintenance(n), "pool maintenance needs the node out of maintenance")
+	require.NoError(t, d.ExitMaintenance(n))
+
+	require.NoError(t, d.EnterPoolMaintenance(n))
+	statuses, err := d.GetNodePoolsStatus(n)
+	require.NoError(t, err)
+	assert.Equal(t, poolStatusMaintenance, statuses[pool.Uuid])
+	require.NoError(t, d.ExitPoolMaintenance(n))
+	outOfMaintenance, err := d.IsNodeOutOfMaintenance(n)
+	require.NoError(t, err)
+	assert.True(t, outOfMaintenance)
+}
+
+func TestKvdbFailover(t *testing.T) {
+	d := newTestDriver(t)
+	n := node.GetWorkerNodes()[0]
+
+	members, err := d.GetKvdbMembers(n)
+	require.NoError(t, err)
+	require.Len(t, members, kvdbMemberCount)
+
+	var leader node.Node
+	for id, m := range members {
+		if m.Leader {
+			leader = node.GetNodesByVoDriverNodeID()[id]
+		}
+	}
+	require.NotEmpty(t, leader.Name)
+	require.NoError(t, d.StopDriver([]node.Node{leader}, true, nil))
+
+	members, err = d.GetKvdbMembers(n)
+	require.NoError(t, err)
+	assert.False(t, members[leader.VolDriverNodeID].IsHealthy)
+	assert.False(t, members[leader.VolDriverNodeID].Leader)
+
+	time.Sleep(150 * time.Millisecond)
+	members, err = d.GetKvdbMembers(n)
+	require.NoError(t, err)
+	assert.Len(t, members, kvdbMemberCount)
+	assert.NotContains(t, members, leader.VolDriverNodeID, "stopped member should have been replaced")
+}
+
+func TestInjectedFailuresAndTrashcan(t *testing.T) {
+	d := newTestDriver(t)
+	n := node.GetWorkerNodes()[0]
+
+	InjectFailure("CreateVolume", nil, 1)
+	_, err := d.CreateVolume("failing-vol", 1<<30, 1)
+	assert.IsType(t, &ErrInjectedFailure{}, err)
+	id, err := d.CreateVolume("failing-vol", 1<<30, 1)
+	require.NoError(t, err, "failure should only be injected once")
+
+	require.NoError(t, d.SetClusterOptsWithConfirmation(n, map[string]string{volumeExpirationOpt: "10"}))
+	require.NoError(t, d.DeleteVolume(id))
+	trashed, err := d.GetTrashCanVolumeIds(n)
+	require.NoError(t, err)
+	assert.Equal(t, []string{id}, trashed)
+
+	require.NoError(t, parseFailures("StopDriver:2,ExpandPool"))
+	assert.Error(t, d.StopDriver([]node.Node{n}, false, nil))
+	assert.Error(t, d.StopDriver([]node.Node{n}, false, nil))
+	assert.NoError(t, d.StopDriver([]node.Node{n}, false, nil))
+	assert.Error(t, d.ExpandPool(n.StoragePools[0].Uuid, api.SdkStoragePool_RESIZE_TYPE_AUTO, 500))
+	assert.Error(t, d.ExpandPool(n.StoragePools[0].Uuid, api.SdkStoragePool_RESIZE_TYPE_AUTO, 500))
+	ClearFailures()
+}
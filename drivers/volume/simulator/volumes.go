@@ -0,0 +1,753 @@
+package simulator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	"github.com/libopenstorage/openstorage/api"
+	"github.com/pborman/uuid"
+	"github.com/portworx/sched-ops/task"
+	"github.com/portworx/torpedo/drivers/node"
+	torpedovolume "github.com/portworx/torpedo/drivers/volume"
+	"github.com/portworx/torpedo/pkg/log"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	minReplicationFactor = 1
+	maxReplicationFactor = 3
+)
+
+// ProvisionVolume creates the volume backing a claim bound by the scheduler. It stands in for the
+// dynamic provisioner, so it is a no-op if the volume already exists.
+func (d *simulator) ProvisionVolume(volumeID string, size uint64, params map[string]string) error {
+	if err := injectedFailure("ProvisionVolume"); err != nil {
+		return err
+	}
+	spec, err := specFromParams(size, params)
+	if err != nil {
+		return err
+	}
+
+	d.refresh()
+	defer d.lock.Unlock()
+
+	if _, ok := d.volumes[volumeID]; ok {
+		return nil
+	}
+	sv, err := d.createVolume(volumeID, volumeID, spec, nil)
+	if err != nil {
+		return err
+	}
+	return d.attach(sv)
+}
+
+// ExpandVolume grows a provisioned volume after its claim was resized
+func (d *simulator) ExpandVolume(volumeID string, size uint64) error {
+	if err := injectedFailure("ExpandVolume"); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(volumeID)
+	if err != nil {
+		return err
+	}
+	if size < sv.vol.Spec.Size {
+		return fmt.Errorf("volume %s can't shrink from %d to %d bytes", volumeID, sv.vol.Spec.Size, size)
+	}
+	sv.vol.Spec.Size = size
+	return nil
+}
+
+// createVolume places the replicas of a new volume. Caller must hold the lock.
+func (d *simulator) createVolume(id, name string, spec *api.VolumeSpec, labels map[string]string) (*simVolume, error) {
+	if spec.HaLevel < minReplicationFactor || spec.HaLevel > maxReplicationFactor {
+		return nil, fmt.Errorf("invalid replication factor %d for volume %s", spec.HaLevel, name)
+	}
+	for _, sv := range d.volumes {
+		if sv.vol.Locator.GetName() == name {
+			return nil, fmt.Errorf("volume with name %s already exists", name)
+		}
+	}
+	nodes, err := d.pickReplicaNodes(int(spec.HaLevel), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create volume %s: %v", name, err)
+	}
+	vol := newVolume(id, name, spec, labels)
+	for _, sn := range nodes {
+		if err = addReplica(vol, sn, ""); err != nil {
+			return nil, err
+		}
+	}
+	setRuntimeState(vol, runtimeStateClean)
+	sv := &simVolume{vol: vol}
+	d.volumes[id] = sv
+	log.Infof("Created simulated volume %s (%s) with %d replicas", name, id, spec.HaLevel)
+	return sv, nil
+}
+
+// attach attaches the volume on the first usable node holding a replica. Caller must hold the lock.
+func (d *simulator) attach(sv *simVolume) error {
+	for _, id := range replicaNodes(sv.vol) {
+		if sn := d.nodeByName(id); sn != nil && sn.usable() {
+			sv.vol.State = api.VolumeState_VOLUME_STATE_ATTACHED
+			sv.vol.AttachedOn = sn.ip
+			sv.vol.DevicePath = "/dev/pxd/pxd" + sv.vol.Id
+			return nil
+		}
+	}
+	return fmt.Errorf("no usable replica node to attach volume %s", sv.vol.Id)
+}
+
+// CreateVolume creates a simulated volume and returns its ID
+func (d *simulator) CreateVolume(volName string, size uint64, haLevel int64) (string, error) {
+	if err := injectedFailure("CreateVolume"); err != nil {
+		return "", err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	spec, _ := specFromParams(size, nil)
+	spec.HaLevel = haLevel
+	sv, err := d.createVolume(uuid.New(), volName, spec, nil)
+	if err != nil {
+		return "", err
+	}
+	return sv.vol.Id, nil
+}
+
+// CreateVolumeUsingRequest creates a simulated volume from the given request and returns its ID
+func (d *simulator) CreateVolumeUsingRequest(request *api.SdkVolumeCreateRequest) (string, error) {
+	if err := injectedFailure("CreateVolumeUsingRequest"); err != nil {
+		return "", err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	spec := proto.Clone(request.GetSpec()).(*api.VolumeSpec)
+	if spec.HaLevel == 0 {
+		spec.HaLevel = 1
+	}
+	sv, err := d.createVolume(uuid.New(), request.GetName(), spec, request.GetLabels())
+	if err != nil {
+		return "", err
+	}
+	return sv.vol.Id, nil
+}
+
+// CloneVolume creates a writable copy of the volume on the same nodes
+func (d *simulator) CloneVolume(volumeID string) (string, error) {
+	if err := injectedFailure("CloneVolume"); err != nil {
+		return "", err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(volumeID)
+	if err != nil {
+		return "", err
+	}
+	clone := d.copyVolume(sv, fmt.Sprintf("%s-clone-%s", sv.vol.Locator.GetName(), uuid.New()[:8]))
+	return clone.vol.Id, nil
+}
+
+// copyVolume creates a detached copy of the volume with replicas on the same pools. Caller must hold the lock.
+func (d *simulator) copyVolume(sv *simVolume, name string) *simVolume {
+	vol := proto.Clone(sv.vol).(*api.Volume)
+	vol.Id = uuid.New()
+	vol.Locator.Name = name
+	vol.Source = &api.Source{Parent: sv.vol.Id}
+	vol.Ctime = newVolume("", "", vol.Spec, nil).Ctime
+	vol.State = api.VolumeState_VOLUME_STATE_DETACHED
+	vol.AttachedOn = ""
+	vol.DevicePath = ""
+	copied := &simVolume{vol: vol}
+	d.volumes[vol.Id] = copied
+	return copied
+}
+
+// AttachVolume attaches the volume and returns its device path
+func (d *simulator) AttachVolume(volumeID string) (string, error) {
+	if err := injectedFailure("AttachVolume"); err != nil {
+		return "", err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(volumeID)
+	if err != nil {
+		return "", err
+	}
+	if err = d.attach(sv); err != nil {
+		return "", err
+	}
+	return sv.vol.DevicePath, nil
+}
+
+// DetachVolume detaches the volume
+func (d *simulator) DetachVolume(volumeID string) error {
+	if err := injectedFailure("DetachVolume"); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(volumeID)
+	if err != nil {
+		return err
+	}
+	sv.vol.State = api.VolumeState_VOLUME_STATE_DETACHED
+	sv.vol.AttachedOn = ""
+	sv.vol.DevicePath = ""
+	return nil
+}
+
+// DeleteVolume deletes the volume, moving it to the trashcan if volume expiration is enabled
+func (d *simulator) DeleteVolume(volumeID string) error {
+	if err := injectedFailure("DeleteVolume"); err != nil {
+		return err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(volumeID)
+	if err != nil {
+		return err
+	}
+	for _, id := range replicaNodes(sv.vol) {
+		d.removeReplica(sv.vol, id)
+	}
+	delete(d.volumes, sv.vol.Id)
+
+	if minutes, _ := strconv.Atoi(d.clusterOpts[volumeExpirationOpt]); minutes > 0 {
+		sv.vol.State = api.VolumeState_VOLUME_STATE_DELETED
+		d.trashcan[sv.vol.Id] = &trashedVolume{
+			vol:       sv.vol,
+			expiresAt: time.Now().Add(time.Duration(minutes) * time.Minute),
+		}
+		log.Infof("Moved simulated volume %s to the trashcan", sv.vol.Id)
+	}
+	return nil
+}
+
+// CleanupVolume deletes the volume if it exists
+func (d *simulator) CleanupVolume(name string) error {
+	err := d.DeleteVolume(name)
+	if _, ok := err.(*ErrVolumeNotFound); ok {
+		return nil
+	}
+	return err
+}
+
+// InspectVolume returns a copy of the simulated volume
+func (d *simulator) InspectVolume(name string) (*api.Volume, error) {
+	if err := injectedFailure("InspectVolume"); err != nil {
+		return nil, err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(sv.vol).(*api.Volume), nil
+}
+
+// CreateSnapshot creates a read-only snapshot of the volume
+func (d *simulator) CreateSnapshot(volumeID string, snapName string) (*api.SdkVolumeSnapshotCreateResponse, error) {
+	if err := injectedFailure("CreateSnapshot"); err != nil {
+		return nil, err
+	}
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	snap := d.copyVolume(sv, snapName)
+	snap.vol.Readonly = true
+	return &api.SdkVolumeSnapshotCreateResponse{SnapshotId: snap.vol.Id}, nil
+}
+
+// ValidateCreateVolume checks the volume exists and matches the given parameters
+func (d *simulator) ValidateCreateVolume(name string, params map[string]string) error {
+	if err := injectedFailure("ValidateCreateVolume"); err != nil {
+		return err
+	}
+	expected, err := specFromParams(0, params)
+	if err != nil {
+		return err
+	}
+
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(name)
+	if err != nil {
+		return err
+	}
+	if _, ok := params["repl"]; ok && sv.vol.Spec.HaLevel != expected.HaLevel {
+		return &ErrFailedToValidateVolume{
+			ID:    name,
+			Cause: fmt.Sprintf("expected replication factor %d, got %d", expected.HaLevel, sv.vol.Spec.HaLevel),
+		}
+	}
+	if _, ok := params["shared"]; ok && sv.vol.Spec.Shared != expected.Shared {
+		return &ErrFailedToValidateVolume{
+			ID:    name,
+			Cause: fmt.Sprintf("expected shared to be %v", expected.Shared),
+		}
+	}
+	if _, ok := params["sharedv4"]; ok && sv.vol.Spec.Sharedv4 != expected.Sharedv4 {
+		return &ErrFailedToValidateVolume{
+			ID:    name,
+			Cause: fmt.Sprintf("expected sharedv4 to be %v", expected.Sharedv4),
+		}
+	}
+	return nil
+}
+
+// ValidateCreateSnapshot checks the snapshot exists and has a parent volume
+func (d *simulator) ValidateCreateSnapshot(name string, params map[string]string) error {
+	if err := injectedFailure("ValidateCreateSnapshot"); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(name)
+	if err != nil {
+		return err
+	}
+	if sv.vol.Source.GetParent() == "" {
+		return &ErrFailedToValidateVolume{
+			ID:    name,
+			Cause: "volume is not a snapshot",
+		}
+	}
+	return nil
+}
+
+// ValidateCreateSnapshotUsingPxctl checks the snapshot exists and has a parent volume
+func (d *simulator) ValidateCreateSnapshotUsingPxctl(name string) error {
+	return d.ValidateCreateSnapshot(name, nil)
+}
+
+// ValidateCreateCloudsnap backs up the volume to the simulated cloud and waits for it to finish
+func (d *simulator) ValidateCreateCloudsnap(name string, params map[string]string) error {
+	if err := injectedFailure("ValidateCreateCloudsnap"); err != nil {
+		return err
+	}
+	d.refresh()
+	sv, err := d.lookupVolume(name)
+	if err != nil {
+		d.lock.Unlock()
+		return err
+	}
+	var backupNode *simNode
+	for _, id := range replicaNodes(sv.vol) {
+		if sn := d.nodeByName(id); sn != nil && sn.usable() {
+			backupNode = sn
+			break
+		}
+	}
+	if backupNode == nil {
+		d.lock.Unlock()
+		return fmt.Errorf("no usable replica of volume %s to take a cloudsnap from", name)
+	}
+	cs := &cloudsnap{
+		id:       uuid.New(),
+		volumeID: sv.vol.Id,
+		nodeName: backupNode.name,
+		doneAt:   time.Now().Add(d.operationDelay),
+	}
+	d.cloudsnaps[cs.id] = cs
+	d.lock.Unlock()
+	log.Infof("Started simulated cloudsnap %s of volume %s on node %s", cs.id, name, cs.nodeName)
+
+	t := func() (interface{}, bool, error) {
+		if time.Now().Before(cs.doneAt) {
+			return nil, true, fmt.Errorf("cloudsnap %s of volume %s is still in progress", cs.id, name)
+		}
+		return nil, false, nil
+	}
+	_, err = task.DoRetryWithTimeout(t, defaultTimeout, retryInterval(d.operationDelay))
+	return err
+}
+
+// ValidateCreateCloudsnapUsingPxctl backs up the volume to the simulated cloud
+func (d *simulator) ValidateCreateCloudsnapUsingPxctl(name string) error {
+	return d.ValidateCreateCloudsnap(name, nil)
+}
+
+// GetNodeForBackup returns the node on which the given cloudsnap ran
+func (d *simulator) GetNodeForBackup(backupID string) (node.Node, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	cs, ok := d.cloudsnaps[backupID]
+	if !ok {
+		return node.Node{}, fmt.Errorf("cloudsnap %s not found", backupID)
+	}
+	return node.GetNodeByName(cs.nodeName)
+}
+
+// ValidateGetByteUsedForVolume returns the bytes used by the volume
+func (d *simulator) ValidateGetByteUsedForVolume(volumeName string, params map[string]string) (uint64, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(volumeName)
+	if err != nil {
+		return 0, err
+	}
+	return sv.vol.Usage, nil
+}
+
+// ValidateVolumeInPxctlList checks the volume exists
+func (d *simulator) ValidateVolumeInPxctlList(name string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	_, err := d.lookupVolume(name)
+	return err
+}
+
+// IsPureVolume returns false as simulated volumes are never backed by a FlashArray
+func (d *simulator) IsPureVolume(volume *torpedovolume.Volume) (bool, error) {
+	return false, nil
+}
+
+// IsPureFileVolume returns false as simulated volumes are never backed by a FlashBlade
+func (d *simulator) IsPureFileVolume(volume *torpedovolume.Volume) (bool, error) {
+	return false, nil
+}
+
+// ValidateUpdateVolume checks the volume was resized to at least the requested size
+func (d *simulator) ValidateUpdateVolume(vol *torpedovolume.Volume, params map[string]string) error {
+	if err := injectedFailure("ValidateUpdateVolume"); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupTorpedoVolume(vol)
+	if err != nil {
+		return err
+	}
+	if sv.vol.Spec.Size < vol.RequestedSize {
+		return &ErrFailedToValidateVolume{
+			ID:    vol.ID,
+			Cause: fmt.Sprintf("expected size of at least %d, got %d", vol.RequestedSize, sv.vol.Spec.Size),
+		}
+	}
+	return nil
+}
+
+// SetIoBandwidth sets the IO throttle of the volume
+func (d *simulator) SetIoBandwidth(vol *torpedovolume.Volume, readBandwidthMBps uint32, writeBandwidthMBps uint32) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupTorpedoVolume(vol)
+	if err != nil {
+		return err
+	}
+	sv.vol.Spec.IoThrottle = &api.IoThrottle{
+		ReadBwMbytes:  readBandwidthMBps,
+		WriteBwMbytes: writeBandwidthMBps,
+	}
+	return nil
+}
+
+// ValidateDeleteVolume checks the volume no longer exists
+func (d *simulator) ValidateDeleteVolume(vol *torpedovolume.Volume) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if _, err := d.lookupTorpedoVolume(vol); err == nil {
+		return fmt.Errorf("volume %s still exists", vol.ID)
+	}
+	return nil
+}
+
+// ValidateVolumeSetup checks the volume exists and is up
+func (d *simulator) ValidateVolumeSetup(vol *torpedovolume.Volume) error {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupTorpedoVolume(vol)
+	if err != nil {
+		return err
+	}
+	if sv.vol.Status != api.VolumeStatus_VOLUME_STATUS_UP {
+		return &ErrFailedToValidateVolume{
+			ID:    vol.ID,
+			Cause: fmt.Sprintf("volume is in status %s", sv.vol.Status),
+		}
+	}
+	return nil
+}
+
+// ValidateVolumeSnapshotRestore checks the restored volume exists and was created from a snapshot
+func (d *simulator) ValidateVolumeSnapshotRestore(vol string, snapData *snapv1.VolumeSnapshotData, timeStart time.Time) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	_, err := d.lookupVolume(vol)
+	return err
+}
+
+// GetNodeForVolume returns the node the volume is attached on
+func (d *simulator) GetNodeForVolume(vol *torpedovolume.Volume, timeout time.Duration, retryInterval time.Duration) (*node.Node, error) {
+	t := func() (interface{}, bool, error) {
+		d.refresh()
+		defer d.lock.Unlock()
+
+		sv, err := d.lookupTorpedoVolume(vol)
+		if err != nil {
+			return nil, false, err
+		}
+		if sv.vol.State != api.VolumeState_VOLUME_STATE_ATTACHED {
+			return nil, true, fmt.Errorf("volume %s is not attached", vol.ID)
+		}
+		n, err := node.GetNodeByIP(sv.vol.AttachedOn)
+		if err != nil {
+			return nil, false, err
+		}
+		return &n, false, nil
+	}
+
+	n, err := task.DoRetryWithTimeout(t, timeout, retryInterval)
+	if err != nil {
+		return nil, err
+	}
+	return n.(*node.Node), nil
+}
+
+// lookupTorpedoVolume finds the simulated volume by ID, falling back to the name. Caller must hold the lock.
+func (d *simulator) lookupTorpedoVolume(vol *torpedovolume.Volume) (*simVolume, error) {
+	if sv, err := d.lookupVolume(vol.ID); err == nil {
+		return sv, nil
+	}
+	return d.lookupVolume(vol.Name)
+}
+
+// GetReplicationFactor returns the current replication factor of the volume
+func (d *simulator) GetReplicationFactor(vol *torpedovolume.Volume) (int64, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupTorpedoVolume(vol)
+	if err != nil {
+		return 0, err
+	}
+	return sv.vol.Spec.HaLevel, nil
+}
+
+// SetReplicationFactor changes the replication factor of the volume. New replicas go to the given
+// nodes and pools if any, removed replicas are taken from the given nodes if any.
+func (d *simulator) SetReplicationFactor(vol *torpedovolume.Volume, replFactor int64, nodesToBeUpdated []string, poolsToBeUpdated []string, waitForUpdateToFinish bool, opts ...torpedovolume.Options) error {
+	replicationUpdateTimeout := defaultTimeout
+	if len(opts) > 0 && opts[0].ValidateReplicationUpdateTimeout > 0 {
+		replicationUpdateTimeout = opts[0].ValidateReplicationUpdateTimeout
+	}
+	log.Infof("Setting ReplicationFactor of %s to: %v", vol.ID, replFactor)
+
+	if err := injectedFailure("SetReplicationFactor"); err != nil {
+		return &ErrFailedToSetReplicationFactor{
+			ID:    vol.ID,
+			Cause: err.Error(),
+		}
+	}
+	if err := d.updateReplication(vol, replFactor, nodesToBeUpdated, poolsToBeUpdated); err != nil {
+		return &ErrFailedToSetReplicationFactor{
+			ID:    vol.ID,
+			Cause: err.Error(),
+		}
+	}
+	if !waitForUpdateToFinish {
+		return nil
+	}
+	if err := d.WaitForReplicationToComplete(vol, replFactor, replicationUpdateTimeout); err != nil {
+		return &ErrFailedToSetReplicationFactor{
+			ID:    vol.ID,
+			Cause: err.Error(),
+		}
+	}
+	return nil
+}
+
+func (d *simulator) updateReplication(vol *torpedovolume.Volume, replFactor int64, nodesToBeUpdated []string, poolsToBeUpdated []string) error {
+	if replFactor < minReplicationFactor || replFactor > maxReplicationFactor {
+		return fmt.Errorf("replication factor %d is outside of [%d, %d]", replFactor, minReplicationFactor, maxReplicationFactor)
+	}
+
+	d.refresh()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupTorpedoVolume(vol)
+	if err != nil {
+		return err
+	}
+	replicas := replicaNodes(sv.vol)
+	current := int64(len(replicas))
+	switch {
+	case replFactor > current:
+		if replFactor-current > 1 {
+			return fmt.Errorf("replication factor can only be increased by one at a time, current: %d", current)
+		}
+		if sv.degraded || time.Now().Before(sv.resyncUntil) {
+			return fmt.Errorf("volume %s is not clean yet", sv.vol.Id)
+		}
+		exclude := make(map[string]bool)
+		for _, id := range replicas {
+			exclude[id] = true
+		}
+		var target *simNode
+		if len(nodesToBeUpdated) > 0 {
+			if target = d.nodeByName(nodesToBeUpdated[0]); target == nil {
+				return &ErrNodeNotFound{Node: nodesToBeUpdated[0]}
+			}
+			if !target.usable() || exclude[target.storage.Id] {
+				return fmt.Errorf("node %s can't take a new replica of volume %s", target.name, sv.vol.Id)
+			}
+		} else {
+			nodes, err := d.pickReplicaNodes(1, exclude)
+			if err != nil {
+				return err
+			}
+			target = nodes[0]
+		}
+		poolUUID := ""
+		if len(poolsToBeUpdated) > 0 {
+			poolUUID = poolsToBeUpdated[0]
+			if target.pool(poolUUID) == nil {
+				return fmt.Errorf("pool %s is not on node %s", poolUUID, target.name)
+			}
+		}
+		if err = addReplica(sv.vol, target, poolUUID); err != nil {
+			return err
+		}
+		sv.resyncUntil = time.Now().Add(d.resyncDelay)
+	case replFactor < current:
+		if current-replFactor > 1 {
+			return fmt.Errorf("replication factor can only be decreased by one at a time, current: %d", current)
+		}
+		victim := replicas[len(replicas)-1]
+		if len(nodesToBeUpdated) > 0 {
+			sn := d.nodeByName(nodesToBeUpdated[0])
+			if sn == nil || !containsString(replicas, sn.storage.Id) {
+				return fmt.Errorf("node %s has no replica of volume %s", nodesToBeUpdated[0], sv.vol.Id)
+			}
+			victim = sn.storage.Id
+		}
+		d.removeReplica(sv.vol, victim)
+	}
+	sv.vol.Spec.HaLevel = replFactor
+	d.advance(time.Now())
+	return d.syncNodes()
+}
+
+// WaitForReplicationToComplete waits till all replica sets have replFactor nodes and are clean
+func (d *simulator) WaitForReplicationToComplete(vol *torpedovolume.Volume, replFactor int64, replicationUpdateTimeout time.Duration) error {
+	t := func() (interface{}, bool, error) {
+		d.refresh()
+		defer d.lock.Unlock()
+
+		sv, err := d.lookupTorpedoVolume(vol)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, rs := range sv.vol.ReplicaSets {
+			if int64(len(rs.GetNodes())) != replFactor {
+				return nil, true, fmt.Errorf("volume %s has %d replicas, expected %d", vol.ID, len(rs.GetNodes()), replFactor)
+			}
+		}
+		for _, rt := range sv.vol.RuntimeState {
+			if state := rt.GetRuntimeState()["RuntimeState"]; state != runtimeStateClean {
+				return nil, true, fmt.Errorf("volume %s is in runtime state %s", vol.ID, state)
+			}
+		}
+		return nil, false, nil
+	}
+
+	if _, err := task.DoRetryWithTimeout(t, replicationUpdateTimeout, retryInterval(d.resyncDelay)); err != nil {
+		return fmt.Errorf("volume didn't successfully change to replication factor of %d: %v", replFactor, err)
+	}
+	return nil
+}
+
+// GetMaxReplicationFactor returns the max supported replication factor of a volume
+func (d *simulator) GetMaxReplicationFactor() int64 {
+	return maxReplicationFactor
+}
+
+// GetMinReplicationFactor returns the min supported replication factor of a volume
+func (d *simulator) GetMinReplicationFactor() int64 {
+	return minReplicationFactor
+}
+
+// GetAggregationLevel returns the aggregation level of the volume
+func (d *simulator) GetAggregationLevel(vol *torpedovolume.Volume) (int64, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupTorpedoVolume(vol)
+	if err != nil {
+		return 0, err
+	}
+	return int64(sv.vol.Spec.AggregationLevel), nil
+}
+
+// GetReplicaSets returns copies of the replica sets of the volume
+func (d *simulator) GetReplicaSets(vol *torpedovolume.Volume) ([]*api.ReplicaSet, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupTorpedoVolume(vol)
+	if err != nil {
+		return nil, err
+	}
+	var replicaSets []*api.ReplicaSet
+	for _, rs := range sv.vol.ReplicaSets {
+		replicaSets = append(replicaSets, proto.Clone(rs).(*api.ReplicaSet))
+	}
+	return replicaSets, nil
+}
+
+// UpdateIOPriority sets the class of service of the volume
+func (d *simulator) UpdateIOPriority(volumeName string, priorityType string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sv, err := d.lookupVolume(volumeName)
+	if err != nil {
+		return err
+	}
+	spec, err := specFromParams(0, map[string]string{"priority_io": priorityType})
+	if err != nil {
+		return err
+	}
+	sv.vol.Spec.Cos = spec.Cos
+	return nil
+}
+
+// IsIOsInProgressForTheVolume returns false as no IO is simulated
+func (d *simulator) IsIOsInProgressForTheVolume(n *node.Node, volumeNameOrID string) (bool, error) {
+	return false, nil
+}
+
+// GetTrashCanVolumeIds returns the IDs of deleted volumes which haven't expired yet
+func (d *simulator) GetTrashCanVolumeIds(n node.Node) ([]string, error) {
+	d.refresh()
+	defer d.lock.Unlock()
+
+	return sortedKeys(d.trashcan), nil
+}
@@ -70,6 +70,14 @@ type Dashboard struct {
 	testcaseID        int
 	testSetStartTime  time.Time
 	testCaseStartTime time.Time
+	// reporters receive the results whether the dashboard is enabled or not
+	reporters []Reporter
+}
+
+// AddReporter adds a reporter receiving the test sets, test cases, verifications, comments and
+// stats reported to the dashboard
+func (d *Dashboard) AddReporter(r Reporter) {
+	d.reporters = append(d.reporters, r)
 }
 
 // TestSet struct
@@ -129,6 +137,9 @@ type stats struct {
 
 // TestSetBegin start testset and push data to dashboard DB
 func (d *Dashboard) TestSetBegin(testSet *TestSet) {
+	for _, r := range d.reporters {
+		r.TestSetBegin(testSet)
+	}
 	dashURL := "Dash is disabled"
 	if d.IsEnabled && d.TestSetID == 0 {
 
@@ -172,6 +183,11 @@ func (d *Dashboard) TestSetBegin(testSet *TestSet) {
 
 // TestSetEnd  end testset and update  to dashboard DB
 func (d *Dashboard) TestSetEnd() {
+	defer func() {
+		for _, r := range d.reporters {
+			r.TestSetEnd()
+		}
+	}()
 
 	if d.IsEnabled {
 		if d.TestSetID == 0 {
@@ -222,7 +238,11 @@ func (d *Dashboard) TestCaseEnd() {
 			logrus.Errorf("Error parsing update test output, %v", err)
 		}
 		testCaseResult := updateResponse.TestCaseStatus
-		d.VerifySafely(testCaseResult, "PASS", "Test completed successfully ?")
+		// The status of the test case in the dashboard is not a verification of the reporters
+		d.postResult(d.verifyResult(testCaseResult, "PASS", "Test completed successfully ?"))
+	}
+	for _, r := range d.reporters {
+		r.TestCaseEnd()
 	}
 
 	logrus.Info("--------Test End------")
@@ -269,60 +289,72 @@ func (d *Dashboard) TestSetUpdate(testSet *TestSet) {
 
 // TestCaseBegin start the test case and push data to dashboard DB
 func (d *Dashboard) TestCaseBegin(testName, description, testRailID string, tags map[string]string) {
+	_, file, _, _ := runtime.Caller(1)
+	d.TestCaseBeginInFile(file, testName, description, testRailID, tags)
+}
+
+// TestCaseBeginInFile start the test case defined in the given file and push data to dashboard DB
+func (d *Dashboard) TestCaseBeginInFile(file, testName, description, testRailID string, tags map[string]string) {
 
 	logrus.Info("--------Test Start------")
 	logrus.Infof("#Test: %s ", testName)
 	logrus.Infof("#Description: %s ", description)
 	logrus.Info("------------------------")
-	if d.IsEnabled {
-		if d.TestSetID == 0 {
-			return
-		}
 
-		testCase = TestCase{}
-		testCase.Tags = make(map[string]string)
-		testCase.Name = testName
+	tc := TestCase{}
+	tc.Tags = make(map[string]string)
+	tc.Name = testName
 
-		_, file, _, ok := runtime.Caller(1)
-		if ok {
+	if file != "" {
 
-			m := regexp.MustCompile(`torpedo`)
+		m := regexp.MustCompile(`torpedo`)
 
-			r := m.FindStringIndex(file)
-			if r != nil {
-				fp := file[r[0]:]
-				testCase.ModuleName = fp
-				files := strings.Split(fp, "/")
-				testCase.ShortName = files[len(files)-1]
+		r := m.FindStringIndex(file)
+		if r != nil {
+			fp := file[r[0]:]
+			tc.ModuleName = fp
+			files := strings.Split(fp, "/")
+			tc.ShortName = files[len(files)-1]
 
-				logrus.Infof("Running test from file %s, module: %s", fp, testName)
+			logrus.Infof("Running test from file %s, module: %s", fp, testName)
 
-			}
-
-		}
-		//t.StartTime = time.Now().Format(time.RFC3339)
-		testCase.Status = INPROGRESS
-		testCase.Description = description
-		testCase.HostOs = runtime.GOOS
-		testCase.TestType = "TEST"
-
-		testCase.TestSetID = d.TestSetID
-		testCase.TestRailID = testRailID
-
-		// Check for common env variables and add as tags
-		testCase.Tags["torpedo"] = "true"
-		if os.Getenv("JOB_NAME") != "" {
-			testCase.Tags["JOB_NAME"] = os.Getenv("JOB_NAME")
 		}
-		if os.Getenv("BUILD_URL") != "" {
-			testCase.Tags["BUILD_URL"] = os.Getenv("BUILD_URL")
+
+	}
+	//t.StartTime = time.Now().Format(time.RFC3339)
+	tc.Status = INPROGRESS
+	tc.Description = description
+	tc.HostOs = runtime.GOOS
+	tc.TestType = "TEST"
+
+	tc.TestSetID = d.TestSetID
+	tc.TestRailID = testRailID
+
+	// Check for common env variables and add as tags
+	tc.Tags["torpedo"] = "true"
+	if os.Getenv("JOB_NAME") != "" {
+		tc.Tags["JOB_NAME"] = os.Getenv("JOB_NAME")
+	}
+	if os.Getenv("BUILD_URL") != "" {
+		tc.Tags["BUILD_URL"] = os.Getenv("BUILD_URL")
+	}
+
+	if tags != nil {
+		for key, val := range tags {
+			tc.Tags[key] = val
 		}
+	}
+	// Reporters get their own copy, the global test case is the one of the dashboard
+	for _, r := range d.reporters {
+		r.TestCaseBegin(tc)
+	}
 
-		if tags != nil {
-			for key, val := range tags {
-				testCase.Tags[key] = val
-			}
+	if d.IsEnabled {
+		if d.TestSetID == 0 {
+			return
 		}
+
+		testCase = tc
 		testCaseStartTime = time.Now()
 
 		createTestCaseURL := fmt.Sprintf("%s/testcase", DashBoardBaseURL)
@@ -344,7 +376,22 @@ func (d *Dashboard) TestCaseBegin(testName, description, testRailID string, tags
 	}
 }
 
-func (d *Dashboard) verify(r result) {
+func (d *Dashboard) verify(r result, fatal bool) {
+	for _, reporter := range d.reporters {
+		reporter.Verify(Verification{
+			Description: r.Description,
+			Actual:      r.Actual,
+			Expected:    r.Expected,
+			Passed:      r.ResultStatus,
+			Fatal:       fatal,
+			Time:        time.Now(),
+		})
+	}
+	d.postResult(r)
+}
+
+// postResult posts the result of a verification to the dashboard only
+func (d *Dashboard) postResult(r result) {
 	if d.IsEnabled {
 
 		if r.TestCaseID == 0 {
@@ -364,6 +411,11 @@ func (d *Dashboard) verify(r result) {
 
 // VerifySafely verify test without aborting the execution
 func (d *Dashboard) VerifySafely(actual, expected interface{}, description string) {
+	d.verify(d.verifyResult(actual, expected, description), false)
+}
+
+// verifyResult compares the actual and expected values and returns the result of the verification
+func (d *Dashboard) verifyResult(actual, expected interface{}, description string) result {
 	if actual == nil && expected == nil {
 		actual = true
 		expected = true
@@ -395,9 +447,7 @@ func (d *Dashboard) VerifySafely(actual, expected interface{}, description strin
 			logrus.Errorf("Actual:%v, Expected: %v", actual, expected)
 		}
 	}
-	if d.IsEnabled {
-		d.verify(res)
-	}
+	return res
 }
 
 func (d *Dashboard) Fatal(description string, args ...interface{}) {
@@ -408,15 +458,13 @@ func (d *Dashboard) Fatal(description string, args ...interface{}) {
 	res.TestCaseID = d.testcaseID
 	res.ResultStatus = false
 	res.ResultType = "error"
-	if d.IsEnabled {
-		d.verify(res)
-	}
+	d.verify(res, true)
 }
 
 // VerifyFatal verify test and abort operation upon failure
 func (d *Dashboard) VerifyFatal(actual, expected interface{}, description string) {
 
-	d.VerifySafely(actual, expected, description)
+	d.verify(d.verifyResult(actual, expected, description), true)
 	var err error
 	if actual != expected {
 		err = fmt.Errorf(description)
@@ -426,69 +474,43 @@ func (d *Dashboard) VerifyFatal(actual, expected interface{}, description string
 
 // Info logging info message
 func (d *Dashboard) Info(message string) {
-	if d.IsEnabled {
-		res := comment{}
-		res.TestCaseID = d.testcaseID
-		res.Description = message
-		res.ResultType = "info"
-		d.addComment(res)
-	}
+	d.comment("info", message)
 }
 
 // Infof logging info with formated message
 func (d *Dashboard) Infof(message string, args ...interface{}) {
-	if d.IsEnabled {
-		fmtMsg := fmt.Sprintf(message, args...)
-		res := comment{}
-		res.TestCaseID = d.testcaseID
-		res.Description = fmtMsg
-		res.ResultType = "info"
-		d.addComment(res)
-	}
+	d.comment("info", fmt.Sprintf(message, args...))
 }
 
 // Warnf logging formatted warn message
 func (d *Dashboard) Warnf(message string, args ...interface{}) {
-	if d.IsEnabled {
-		fmtMsg := fmt.Sprintf(message, args...)
-		res := comment{}
-		res.TestCaseID = d.testcaseID
-		res.Description = fmtMsg
-		res.ResultType = "warning"
-		d.addComment(res)
-	}
+	d.comment("warning", fmt.Sprintf(message, args...))
 }
 
 // Warn logging warn message
 func (d *Dashboard) Warn(message string) {
-	if d.IsEnabled {
-		res := comment{}
-		res.TestCaseID = d.testcaseID
-		res.Description = message
-		res.ResultType = "warning"
-		d.addComment(res)
-	}
+	d.comment("warning", message)
 }
 
 // Error logging error message
 func (d *Dashboard) Error(message string) {
-	if d.IsEnabled {
-		res := comment{}
-		res.TestCaseID = d.testcaseID
-		res.Description = message
-		res.ResultType = "error"
-		d.addComment(res)
-	}
+	d.comment("error", message)
 }
 
 // Errorf logging formatted error message
 func (d *Dashboard) Errorf(message string, args ...interface{}) {
+	d.comment("error", fmt.Sprintf(message, args...))
+}
+
+func (d *Dashboard) comment(resultType, message string) {
+	for _, r := range d.reporters {
+		r.Comment(Comment{Level: resultType, Message: message, Time: time.Now()})
+	}
 	if d.IsEnabled {
-		fmtMsg := fmt.Sprintf(message, args...)
 		res := comment{}
 		res.TestCaseID = d.testcaseID
-		res.Description = fmtMsg
-		res.ResultType = "error"
+		res.Description = message
+		res.ResultType = resultType
 		d.addComment(res)
 	}
 }
@@ -525,6 +547,9 @@ func Get() *Dashboard {
 }
 
 func (d *Dashboard) UpdateStats(name, product, statType, version string, dashStats map[string]string) {
+	for _, r := range d.reporters {
+		r.Stats(Stats{Name: name, Product: product, StatsType: statType, Version: version, Data: dashStats})
+	}
 	if d.IsEnabled {
 
 		dashStats["dash-url"] = fmt.Sprintf("%s/resultSet/testSetID/%d", AetosBaseURL, d.TestSetID)
@@ -0,0 +1,387 @@
+package aetosutil
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// JUnitReportFile is the name of the JUnit XML report written by the file reporter
+	JUnitReportFile = "junit.xml"
+	// JSONReportFile is the name of the JSON report written by the file reporter
+	JSONReportFile = "report.json"
+)
+
+// Reporter receives the results reported to the dashboard
+type Reporter interface {
+	// TestSetBegin is called when the test set begins
+	TestSetBegin(testSet *TestSet)
+	// TestSetEnd is called when the test set ends
+	TestSetEnd()
+	// TestCaseBegin is called when a test case begins
+	TestCaseBegin(testCase TestCase)
+	// TestCaseEnd is called when the last test case which began ends
+	TestCaseEnd()
+	// Verify is called for every verification of the running test case
+	Verify(v Verification)
+	// Comment is called for every comment of the running test case
+	Comment(c Comment)
+	// Stats is called for every stats update
+	Stats(s Stats)
+}
+
+// Verification is a verification of a test case
+type Verification struct {
+	Description string    `json:"description"`
+	Actual      string    `json:"actual"`
+	Expected    string    `json:"expected"`
+	Passed      bool      `json:"passed"`
+	Fatal       bool      `json:"fatal"`
+	Time        time.Time `json:"time"`
+}
+
+// Comment is a comment of a test case
+type Comment struct {
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Stats are stats of a test case or test set
+type Stats struct {
+	Name      string            `json:"name"`
+	Product   string            `json:"product"`
+	StatsType string            `json:"statsType"`
+	Version   string            `json:"version"`
+	Data      map[string]string `json:"data"`
+}
+
+// TestCaseReport is the report of a test case
+type TestCaseReport struct {
+	Name            string            `json:"name"`
+	ShortName       string            `json:"shortName"`
+	ModuleName      string            `json:"moduleName"`
+	Description     string            `json:"description"`
+	TestRailID      string            `json:"testRepoID"`
+	Tags            map[string]string `json:"tags"`
+	Status          string            `json:"status"`
+	Start           time.Time         `json:"start"`
+	End             time.Time         `json:"end"`
+	DurationSeconds float64           `json:"durationSeconds"`
+	Verifications   []Verification    `json:"verifications"`
+	Comments        []Comment         `json:"comments"`
+	Stats           []Stats           `json:"stats"`
+}
+
+// failures returns the failed verifications of the test case
+func (c *TestCaseReport) failures() []Verification {
+	var failed []Verification
+	for _, v := range c.Verifications {
+		if !v.Passed {
+			failed = append(failed, v)
+		}
+	}
+	return failed
+}
+
+// Report is the report of a test set
+type Report struct {
+	TestSet   *TestSet          `json:"testSet"`
+	Start     time.Time         `json:"start"`
+	End       time.Time         `json:"end"`
+	Summary   map[string]int    `json:"summary"`
+	TestCases []*TestCaseReport `json:"testCases"`
+	Stats     []Stats           `json:"stats"`
+}
+
+// FileReporter writes a JUnit XML and a JSON report of the test set to a directory. Reports are
+// rewritten whenever a test case ends, so they are available even if the test set is aborted.
+type FileReporter struct {
+	dir string
+
+	sync.Mutex
+	report  Report
+	running []*TestCaseReport
+}
+
+// NewFileReporter returns a reporter writing reports to the given directory, which is created
+// if needed
+func NewFileReporter(dir string) (*FileReporter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create report directory %s: %v", dir, err)
+	}
+	return &FileReporter{dir: dir, report: Report{Start: time.Now()}}, nil
+}
+
+// Report returns the report of the test set so far
+func (f *FileReporter) Report() Report {
+	f.Lock()
+	defer f.Unlock()
+	return f.report
+}
+
+// TestSetBegin records the test set
+func (f *FileReporter) TestSetBegin(testSet *TestSet) {
+	f.Lock()
+	defer f.Unlock()
+	f.report.TestSet = testSet
+	f.report.Start = time.Now()
+}
+
+// TestSetEnd aborts the test cases which did not end and writes the reports
+func (f *FileReporter) TestSetEnd() {
+	f.Lock()
+	defer f.Unlock()
+	now := time.Now()
+	for _, c := range f.running {
+		c.Status = ABORT
+		c.End = now
+	}
+	f.running = nil
+	f.report.End = now
+	f.writeLocked()
+}
+
+// TestCaseBegin starts a test case report
+func (f *FileReporter) TestCaseBegin(testCase TestCase) {
+	f.Lock()
+	defer f.Unlock()
+	c := &TestCaseReport{
+		Name:        testCase.Name,
+		ShortName:   testCase.ShortName,
+		ModuleName:  testCase.ModuleName,
+		Description: testCase.Description,
+		TestRailID:  testCase.TestRailID,
+		Tags:        testCase.Tags,
+		Status:      INPROGRESS,
+		Start:       time.Now(),
+	}
+	f.report.TestCases = append(f.report.TestCases, c)
+	f.running = append(f.running, c)
+}
+
+// TestCaseEnd ends the last test case which began and writes the reports. The test case passed
+// if all its verifications passed.
+func (f *FileReporter) TestCaseEnd() {
+	f.Lock()
+	defer f.Unlock()
+	if len(f.running) == 0 {
+		return
+	}
+	c := f.running[len(f.running)-1]
+	f.running = f.running[:len(f.running)-1]
+	c.End = time.Now()
+	c.Status = PASS
+	if len(c.failures()) > 0 {
+		c.Status = FAIL
+	}
+	f.writeLocked()
+}
+
+// Verify records the verification in the running test case
+func (f *FileReporter) Verify(v Verification) {
+	f.Lock()
+	defer f.Unlock()
+	if c := f.currentLocked(); c != nil {
+		c.Verifications = append(c.Verifications, v)
+	}
+}
+
+// Comment records the comment in the running test case
+func (f *FileReporter) Comment(comment Comment) {
+	f.Lock()
+	defer f.Unlock()
+	if c := f.currentLocked(); c != nil {
+		c.Comments = append(c.Comments, comment)
+	}
+}
+
+// Stats records the stats in the running test case, or in the test set if no test case is running
+func (f *FileReporter) Stats(s Stats) {
+	f.Lock()
+	defer f.Unlock()
+	if c := f.currentLocked(); c != nil {
+		c.Stats = append(c.Stats, s)
+		return
+	}
+	f.report.Stats = append(f.report.Stats, s)
+}
+
+func (f *FileReporter) currentLocked() *TestCaseReport {
+	if len(f.running) == 0 {
+		return nil
+	}
+	return f.running[len(f.running)-1]
+}
+
+func (f *FileReporter) writeLocked() {
+	f.report.Summary = map[string]int{}
+	for _, c := range f.report.TestCases {
+		end := c.End
+		if end.IsZero() {
+			end = time.Now()
+		}
+		c.DurationSeconds = end.Sub(c.Start).Seconds()
+		f.report.Summary[c.Status]++
+	}
+
+	data, err := json.MarshalIndent(f.report, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(f.dir, JSONReportFile), data)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to write JSON report to %s: %v", f.dir, err)
+	}
+
+	data, err = xml.MarshalIndent(junitOf(f.report), "", "  ")
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(f.dir, JUnitReportFile), append([]byte(xml.Header), data...))
+	}
+	if err != nil {
+		logrus.Errorf("Failed to write JUnit report to %s: %v", f.dir, err)
+	}
+}
+
+// writeFileAtomic writes the file through a temporary file, so CI systems never read a partial report
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func junitSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
+
+// junitOf returns the report as JUnit test suites. Failed verifications are failures, test
+// cases which did not end are errors and comments are the output of the test cases.
+func junitOf(r Report) junitTestSuites {
+	name := "torpedo"
+	if r.TestSet != nil && r.TestSet.Description != "" {
+		name = r.TestSet.Description
+	}
+	suite := junitTestSuite{
+		Name:      name,
+		Timestamp: r.Start.UTC().Format("2006-01-02T15:04:05"),
+	}
+	if r.TestSet != nil {
+		for _, p := range [][2]string{
+			{"user", r.TestSet.User},
+			{"product", r.TestSet.Product},
+			{"branch", r.TestSet.Branch},
+			{"commitId", r.TestSet.CommitID},
+			{"testType", r.TestSet.TestType},
+		} {
+			if p[1] != "" {
+				suite.Properties = append(suite.Properties, junitProperty{Name: p[0], Value: p[1]})
+			}
+		}
+		var tags []string
+		for k := range r.TestSet.Tags {
+			tags = append(tags, k)
+		}
+		sort.Strings(tags)
+		for _, k := range tags {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "tag." + k, Value: r.TestSet.Tags[k]})
+		}
+	}
+
+	var total float64
+	for _, c := range r.TestCases {
+		tc := junitTestCase{
+			Name:      c.Name,
+			ClassName: strings.TrimSuffix(c.ShortName, ".go"),
+			Time:      junitSeconds(c.DurationSeconds),
+		}
+		if tc.ClassName == "" {
+			tc.ClassName = name
+		}
+		switch c.Status {
+		case FAIL:
+			failed := c.failures()
+			var details []string
+			for _, v := range failed {
+				details = append(details, fmt.Sprintf("%s: actual [%s], expected [%s]", v.Description, v.Actual, v.Expected))
+			}
+			tc.Failure = &junitMessage{
+				Message: failed[0].Description,
+				Type:    "verification",
+				Text:    strings.Join(details, "\n"),
+			}
+			suite.Failures++
+		case ABORT, INPROGRESS:
+			tc.Error = &junitMessage{Message: "test case did not end", Type: c.Status}
+			suite.Errors++
+		}
+		var out []string
+		for _, comment := range c.Comments {
+			out = append(out, fmt.Sprintf("%s [%s] %s", comment.Time.Format(time.RFC3339), comment.Level, comment.Message))
+		}
+		tc.SystemOut = strings.Join(out, "\n")
+		suite.TestCases = append(suite.TestCases, tc)
+		suite.Tests++
+		total += c.DurationSeconds
+	}
+	suite.Time = junitSeconds(total)
+	return junitTestSuites{
+		Name:     name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+}
@@ -0,0 +1,88 @@
+package aetosutil
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileReporter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	reporter, err := NewFileReporter(dir)
+	require.NoError(t, err)
+
+	d := &Dashboard{}
+	d.AddReporter(reporter)
+	d.TestSetBegin(&TestSet{Description: "Torpedo Workflows", User: "ci", Tags: map[string]string{"env": "kind"}})
+
+	d.TestCaseBegin("RebootNode", "reboot a node", "C123", nil)
+	d.Infof("rebooting node %s", "node-1")
+	d.VerifySafely(true, true, "node is up")
+	d.UpdateStats("reboot", "px", "duration", "3.0", map[string]string{"seconds": "42"})
+	d.TestCaseEnd()
+
+	d.TestCaseBegin("CrashNode", "crash a node", "", map[string]string{"chaos": "high"})
+	d.VerifySafely(errors.New("node-2 did not come up"), nil, "node is up after crash")
+	d.VerifySafely(3, 2, "apps are running")
+	d.TestCaseEnd()
+
+	d.TestCaseBegin("Upgrade", "upgrade the cluster", "", nil)
+	d.TestSetEnd()
+
+	report := reporter.Report()
+	require.Len(t, report.TestCases, 3)
+	assert.Equal(t, map[string]int{PASS: 1, FAIL: 1, ABORT: 1}, report.Summary)
+	assert.Equal(t, "C123", report.TestCases[0].TestRailID)
+	assert.Equal(t, "rebooting node node-1", report.TestCases[0].Comments[0].Message)
+	assert.Equal(t, "42", report.TestCases[0].Stats[0].Data["seconds"])
+	assert.Equal(t, "high", report.TestCases[1].Tags["chaos"])
+	assert.Empty(t, report.TestCases[2].Verifications)
+
+	data, err := os.ReadFile(filepath.Join(dir, JSONReportFile))
+	require.NoError(t, err)
+	var fromFile Report
+	require.NoError(t, json.Unmarshal(data, &fromFile))
+	assert.Equal(t, FAIL, fromFile.TestCases[1].Status)
+	assert.Len(t, fromFile.TestCases[1].Verifications, 2)
+
+	data, err = os.ReadFile(filepath.Join(dir, JUnitReportFile))
+	require.NoError(t, err)
+	var junit junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &junit))
+	assert.Equal(t, 3, junit.Tests)
+	assert.Equal(t, 1, junit.Failures)
+	assert.Equal(t, 1, junit.Errors)
+	suite := junit.Suites[0]
+	assert.Equal(t, "Torpedo Workflows", suite.Name)
+	assert.Contains(t, suite.Properties, junitProperty{Name: "tag.env", Value: "kind"})
+	assert.NotEmpty(t, suite.TestCases[0].ClassName)
+	assert.Nil(t, suite.TestCases[0].Failure)
+	require.NotNil(t, suite.TestCases[1].Failure)
+	assert.Equal(t, "node is up after crash", suite.TestCases[1].Failure.Message)
+	assert.Contains(t, suite.TestCases[1].Failure.Text, "apps are running: actual [3], expected [2]")
+	require.NotNil(t, suite.TestCases[2].Error)
+}
+
+func TestFileReporterWithDashboardDisabled(t *testing.T) {
+	reporter, err := NewFileReporter(t.TempDir())
+	require.NoError(t, err)
+
+	d := &Dashboard{}
+	d.AddReporter(reporter)
+	d.TestCaseBegin("RebootNode", "reboot a node", "C123", nil)
+	d.TestCaseEnd()
+
+	// The test case of the disabled dashboard is left as is
+	assert.Empty(t, testCase.Name)
+	report := reporter.Report()
+	require.Len(t, report.TestCases, 1)
+	assert.Equal(t, "RebootNode", report.TestCases[0].Name)
+	assert.Equal(t, PASS, report.TestCases[0].Status)
+	assert.Empty(t, report.TestCases[0].Verifications)
+}
@@ -0,0 +1,264 @@
+package applicationbackup
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeStork keeps the stork objects of the tests in memory
+type fakeStork struct {
+	storkops.Ops
+	backups   map[string]*storkv1.ApplicationBackup
+	restores  map[string]*storkv1.ApplicationRestore
+	clones    map[string]*storkv1.ApplicationClone
+	schedules map[string]*storkv1.ApplicationBackupSchedule
+}
+
+func key(name, namespace string) string {
+	return namespace + "/" + name
+}
+
+func (s *fakeStork) GetApplicationBackup(name, namespace string) (*storkv1.ApplicationBackup, error) {
+	if b, ok := s.backups[key(name, namespace)]; ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("applicationbackup %s/%s not found", namespace, name)
+}
+
+func (s *fakeStork) CreateApplicationRestore(r *storkv1.ApplicationRestore) (*storkv1.ApplicationRestore, error) {
+	s.restores[key(r.Name, r.Namespace)] = r
+	return r, nil
+}
+
+func (s *fakeStork) GetApplicationRestore(name, namespace string) (*storkv1.ApplicationRestore, error) {
+	if r, ok := s.restores[key(name, namespace)]; ok {
+		return r, nil
+	}
+	return nil, fmt.Errorf("applicationrestore %s/%s not found", namespace, name)
+}
+
+func (s *fakeStork) GetApplicationClone(name, namespace string) (*storkv1.ApplicationClone, error) {
+	if c, ok := s.clones[key(name, namespace)]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("applicationclone %s/%s not found", namespace, name)
+}
+
+func (s *fakeStork) GetApplicationBackupSchedule(name, namespace string) (*storkv1.ApplicationBackupSchedule, error) {
+	if sched, ok := s.schedules[key(name, namespace)]; ok {
+		return sched, nil
+	}
+	return nil, fmt.Errorf("applicationbackupschedule %s/%s not found", namespace, name)
+}
+
+func (s *fakeStork) UpdateApplicationBackupSchedule(sched *storkv1.ApplicationBackupSchedule) (*storkv1.ApplicationBackupSchedule, error) {
+	s.schedules[key(sched.Name, sched.Namespace)] = sched
+	return sched, nil
+}
+
+// fakeCore holds the pvcs of the tests
+type fakeCore struct {
+	core.Ops
+	pvcs map[string][]string
+}
+
+func (c *fakeCore) GetPersistentVolumeClaim(name, namespace string) (*corev1.PersistentVolumeClaim, error) {
+	for _, pvc := range c.pvcs[namespace] {
+		if pvc == name {
+			return &corev1.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: name, Namespace: namespace}}, nil
+		}
+	}
+	return nil, fmt.Errorf("pvc %s/%s not found", namespace, name)
+}
+
+func (c *fakeCore) GetPersistentVolumeClaims(namespace string, labelSelector map[string]string) (*corev1.PersistentVolumeClaimList, error) {
+	list := &corev1.PersistentVolumeClaimList{}
+	for _, pvc := range c.pvcs[namespace] {
+		list.Items = append(list.Items, corev1.PersistentVolumeClaim{ObjectMeta: meta.ObjectMeta{Name: pvc, Namespace: namespace}})
+	}
+	return list, nil
+}
+
+func setup(t *testing.T) (*fakeStork, *fakeCore) {
+	s := &fakeStork{
+		backups:   make(map[string]*storkv1.ApplicationBackup),
+		restores:  make(map[string]*storkv1.ApplicationRestore),
+		clones:    make(map[string]*storkv1.ApplicationClone),
+		schedules: make(map[string]*storkv1.ApplicationBackupSchedule),
+	}
+	c := &fakeCore{pvcs: map[string][]string{"app": {"data"}}}
+	prevStork, prevCore := storkops.Instance(), core.Instance()
+	storkops.SetInstance(s)
+	core.SetInstance(c)
+	t.Cleanup(func() {
+		storkops.SetInstance(prevStork)
+		core.SetInstance(prevCore)
+	})
+	return s, c
+}
+
+func object(namespace, name, kind string) storkv1.ObjectInfo {
+	info := storkv1.ObjectInfo{Name: name, Namespace: namespace}
+	info.Kind = kind
+	info.Version = "v1"
+	return info
+}
+
+// completedBackup is a successful backup of the namespace app with a pvc, a service and a cluster role
+func completedBackup(name string) *storkv1.ApplicationBackup {
+	return &storkv1.ApplicationBackup{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "app"},
+		Spec:       storkv1.ApplicationBackupSpec{Namespaces: []string{"app"}, BackupLocation: "location"},
+		Status: storkv1.ApplicationBackupStatus{
+			Stage:  storkv1.ApplicationBackupStageFinal,
+			Status: storkv1.ApplicationBackupStatusSuccessful,
+			Resources: []*storkv1.ApplicationBackupResourceInfo{
+				{ObjectInfo: object("app", "data", "PersistentVolumeClaim")},
+				{ObjectInfo: object("app", "web", "Service")},
+				{ObjectInfo: object("", "app-role", "ClusterRole")},
+			},
+			Volumes: []*storkv1.ApplicationBackupVolumeInfo{
+				{Namespace: "app", PersistentVolumeClaim: "data", Volume: "pvc-1", BackupID: "bucket/pvc-1-backup", Status: storkv1.ApplicationBackupStatusSuccessful},
+			},
+		},
+	}
+}
+
+func TestValidateApplicationBackup(t *testing.T) {
+	s, _ := setup(t)
+	s.backups[key("backup", "app")] = completedBackup("backup")
+	expected, err := ExpectedFromNamespaces([]string{"app"})
+	require.NoError(t, err)
+	assert.Equal(t, []Volume{{Namespace: "app", PersistentVolumeClaim: "data"}}, expected.Volumes)
+	expected.Resources = append(expected.Resources, object("app", "web", "Service"))
+	require.NoError(t, ValidateApplicationBackup("backup", "app", expected))
+
+	b := completedBackup("partial")
+	b.Status.Status = storkv1.ApplicationBackupStatusPartialSuccess
+	b.Status.Volumes[0].Status = storkv1.ApplicationBackupStatusFailed
+	b.Status.Volumes[0].Reason = "snapshot timed out"
+	b.Status.Resources = append(b.Status.Resources[1:], &storkv1.ApplicationBackupResourceInfo{ObjectInfo: object("other", "secret", "Secret")})
+	s.backups[key("partial", "app")] = b
+	err = ValidateApplicationBackup("partial", "app", expected)
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"status is PartialSuccess: ",
+		"resource Secret other/secret is outside of the backed up namespaces",
+		"volume of pvc app/data is Failed: snapshot timed out",
+		"resource PersistentVolumeClaim app/data is not backed up",
+	}, err.(*ErrValidation).Failures)
+}
+
+func TestValidateApplicationRestore(t *testing.T) {
+	s, c := setup(t)
+	s.backups[key("backup", "app")] = completedBackup("backup")
+	_, err := CreateApplicationRestore("restore", s.backups[key("backup", "app")], map[string]string{"app": "app-restored"}, storkv1.ApplicationRestoreReplacePolicyRetain)
+	require.NoError(t, err)
+	r := s.restores[key("restore", "app")]
+	assert.Equal(t, "location", r.Spec.BackupLocation)
+	r.Status = storkv1.ApplicationRestoreStatus{
+		Stage:  storkv1.ApplicationRestoreStageFinal,
+		Status: storkv1.ApplicationRestoreStatusPartialSuccess,
+		Resources: []*storkv1.ApplicationRestoreResourceInfo{
+			{ObjectInfo: object("app-restored", "data", "PersistentVolumeClaim"), Status: storkv1.ApplicationRestoreStatusSuccessful},
+			{ObjectInfo: object("app-restored", "web", "Service"), Status: storkv1.ApplicationRestoreStatusSuccessful},
+			{ObjectInfo: object("", "app-role", "ClusterRole"), Status: storkv1.ApplicationRestoreStatusRetained},
+		},
+		Volumes: []*storkv1.ApplicationRestoreVolumeInfo{
+			{SourceNamespace: "app", PersistentVolumeClaim: "data", SourceVolume: "pvc-1", RestoreVolume: "pvc-2", Status: storkv1.ApplicationRestoreStatusSuccessful},
+		},
+	}
+	restored, err := WaitForAppRestoreCompletion("restore", "app", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, r, restored)
+
+	// the restored pvc is missing from the cluster
+	err = ValidateApplicationRestore("restore", "app")
+	require.Error(t, err)
+	assert.Equal(t, []string{"restored pvc app-restored/data: pvc app-restored/data not found"}, err.(*ErrValidation).Failures)
+	c.pvcs["app-restored"] = []string{"data"}
+	require.NoError(t, ValidateApplicationRestore("restore", "app"))
+
+	// retained resources fail restores replacing resources
+	r.Spec.ReplacePolicy = storkv1.ApplicationRestoreReplacePolicyDelete
+	r.Status.Resources = r.Status.Resources[1:]
+	err = ValidateApplicationRestore("restore", "app")
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"status is PartialSuccess with replace policy Delete: ",
+		"resource PersistentVolumeClaim app/data is not restored to app-restored/data",
+		"resource ClusterRole app-role is Retained with replace policy Delete: ",
+	}, err.(*ErrValidation).Failures)
+
+	r.Status.Status = storkv1.ApplicationRestoreStatusFailed
+	r.Status.Reason = "backup location unreachable"
+	_, err = WaitForAppRestoreCompletion("restore", "app", time.Second)
+	assert.EqualError(t, err, "app restore restore in app failed: backup location unreachable")
+}
+
+func TestValidateApplicationClone(t *testing.T) {
+	s, c := setup(t)
+	s.clones[key("clone", "kube-system")] = &storkv1.ApplicationClone{
+		ObjectMeta: meta.ObjectMeta{Name: "clone", Namespace: "kube-system"},
+		Spec:       storkv1.ApplicationCloneSpec{SourceNamespace: "app", DestinationNamespace: "app-clone", ReplacePolicy: storkv1.ApplicationCloneReplacePolicyRetain},
+		Status: storkv1.ApplicationCloneStatus{
+			Stage:  storkv1.ApplicationCloneStageFinal,
+			Status: storkv1.ApplicationCloneStatusSuccessful,
+			Resources: []*storkv1.ApplicationCloneResourceInfo{
+				{Name: "data", GroupVersionKind: meta.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}, Status: storkv1.ApplicationCloneStatusSuccessful},
+			},
+			Volumes: []*storkv1.ApplicationCloneVolumeInfo{
+				{PersistentVolumeClaim: "data", Volume: "pvc-1", CloneVolume: "pvc-3", Status: storkv1.ApplicationCloneStatusSuccessful},
+			},
+		},
+	}
+	expected, err := ExpectedFromNamespaces([]string{"app"})
+	require.NoError(t, err)
+	err = ValidateApplicationClone("clone", "kube-system", expected)
+	require.Error(t, err)
+	assert.Equal(t, []string{"cloned pvc app-clone/data: pvc app-clone/data not found"}, err.(*ErrValidation).Failures)
+	c.pvcs["app-clone"] = []string{"data"}
+	require.NoError(t, ValidateApplicationClone("clone", "kube-system", expected))
+
+	expected.Resources = append(expected.Resources, object("app", "web", "Service"))
+	err = ValidateApplicationClone("clone", "kube-system", expected)
+	require.Error(t, err)
+	assert.Equal(t, []string{"resource Service web is not cloned"}, err.(*ErrValidation).Failures)
+}
+
+func TestWaitForScheduledAppBackups(t *testing.T) {
+	s, _ := setup(t)
+	s.backups[key("sched-interval-1", "app")] = completedBackup("sched-interval-1")
+	s.backups[key("sched-interval-2", "app")] = completedBackup("sched-interval-2")
+	sched := &storkv1.ApplicationBackupSchedule{
+		ObjectMeta: meta.ObjectMeta{Name: "sched", Namespace: "app"},
+		Status: storkv1.ApplicationBackupScheduleStatus{Items: map[storkv1.SchedulePolicyType][]*storkv1.ScheduledApplicationBackupStatus{
+			storkv1.SchedulePolicyTypeInterval: {
+				{Name: "sched-interval-1", Status: storkv1.ApplicationBackupStatusSuccessful},
+				{Name: "sched-interval-2", Status: storkv1.ApplicationBackupStatusSuccessful},
+				{Name: "sched-interval-3", Status: storkv1.ApplicationBackupStatusInProgress},
+			},
+		}},
+	}
+	s.schedules[key("sched", "app")] = sched
+	backups, err := WaitForScheduledAppBackups("sched", "app", storkv1.SchedulePolicyTypeInterval, 2, time.Second)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, "sched-interval-2", backups[1].Name)
+
+	require.NoError(t, SuspendApplicationBackupSchedule("sched", "app", true))
+	assert.True(t, *sched.Spec.Suspend)
+
+	sched.Status.Items[storkv1.SchedulePolicyTypeInterval][2].Status = storkv1.ApplicationBackupStatusFailed
+	_, err = WaitForScheduledAppBackups("sched", "app", storkv1.SchedulePolicyTypeInterval, 3, time.Second)
+	assert.EqualError(t, err, "scheduled app backup sched-interval-3 of sched in app failed")
+}
@@ -0,0 +1,65 @@
+package applicationbackup
+
+import (
+	"fmt"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/portworx/sched-ops/task"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateApplicationClone clones the applications of a namespace to another namespace. Clones are
+// only allowed in the admin namespace of stork.
+func CreateApplicationClone(
+	name string,
+	adminNamespace string,
+	sourceNamespace string,
+	destinationNamespace string,
+	replacePolicy storkv1.ApplicationCloneReplacePolicyType,
+) (*storkv1.ApplicationClone, error) {
+
+	appClone := &storkv1.ApplicationClone{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: adminNamespace,
+		},
+		Spec: storkv1.ApplicationCloneSpec{
+			SourceNamespace:      sourceNamespace,
+			DestinationNamespace: destinationNamespace,
+			ReplacePolicy:        replacePolicy,
+		},
+	}
+
+	return storkops.Instance().CreateApplicationClone(appClone)
+}
+
+// WaitForAppCloneCompletion waits for a clone to succeed and returns it. It fails as soon as the
+// clone fails.
+func WaitForAppCloneCompletion(name, namespace string, timeout time.Duration) (*storkv1.ApplicationClone, error) {
+	getAppClone := func() (interface{}, bool, error) {
+		appClone, err := storkops.Instance().GetApplicationClone(name, namespace)
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch appClone.Status.Status {
+		case storkv1.ApplicationCloneStatusSuccessful, storkv1.ApplicationCloneStatusPartialSuccess:
+			return appClone, false, nil
+		case storkv1.ApplicationCloneStatusFailed:
+			return nil, false, fmt.Errorf("app clone %s in %s failed", name, namespace)
+		}
+		return nil, true, fmt.Errorf("app clone %s in %s not complete yet.Retrying Status: %s", name, namespace, appClone.Status.Status)
+	}
+	appClone, err := task.DoRetryWithTimeout(getAppClone, timeout, applicationBackupScheduleRetryInterval)
+	if err != nil {
+		return nil, err
+	}
+	return appClone.(*storkv1.ApplicationClone), nil
+}
+
+// DeleteApplicationClone deletes a clone, leaving the cloned resources in place
+func DeleteApplicationClone(name, namespace string) error {
+	return storkops.Instance().DeleteApplicationClone(name, namespace)
+}
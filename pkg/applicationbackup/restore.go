@@ -0,0 +1,67 @@
+package applicationbackup
+
+import (
+	"fmt"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/portworx/sched-ops/task"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateApplicationRestore restores a backup in its namespace. namespaceMapping maps the backed up
+// namespaces to the namespaces they are restored to, and replacePolicy decides what happens to
+// resources which already exist. Stork only restores to other namespaces than the one of the
+// backup if the backup is in its admin namespace.
+func CreateApplicationRestore(
+	name string,
+	backup *storkv1.ApplicationBackup,
+	namespaceMapping map[string]string,
+	replacePolicy storkv1.ApplicationRestoreReplacePolicyType,
+) (*storkv1.ApplicationRestore, error) {
+
+	appRestore := &storkv1.ApplicationRestore{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: backup.Namespace,
+		},
+		Spec: storkv1.ApplicationRestoreSpec{
+			BackupName:       backup.Name,
+			BackupLocation:   backup.Spec.BackupLocation,
+			NamespaceMapping: namespaceMapping,
+			ReplacePolicy:    replacePolicy,
+		},
+	}
+
+	return storkops.Instance().CreateApplicationRestore(appRestore)
+}
+
+// WaitForAppRestoreCompletion waits for a restore to succeed and returns it. It fails as soon as
+// the restore fails.
+func WaitForAppRestoreCompletion(name, namespace string, timeout time.Duration) (*storkv1.ApplicationRestore, error) {
+	getAppRestore := func() (interface{}, bool, error) {
+		appRestore, err := storkops.Instance().GetApplicationRestore(name, namespace)
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch appRestore.Status.Status {
+		case storkv1.ApplicationRestoreStatusSuccessful, storkv1.ApplicationRestoreStatusPartialSuccess:
+			return appRestore, false, nil
+		case storkv1.ApplicationRestoreStatusFailed:
+			return nil, false, fmt.Errorf("app restore %s in %s failed: %s", name, namespace, appRestore.Status.Reason)
+		}
+		return nil, true, fmt.Errorf("app restore %s in %s not complete yet.Retrying Status: %s", name, namespace, appRestore.Status.Status)
+	}
+	appRestore, err := task.DoRetryWithTimeout(getAppRestore, timeout, applicationBackupScheduleRetryInterval)
+	if err != nil {
+		return nil, err
+	}
+	return appRestore.(*storkv1.ApplicationRestore), nil
+}
+
+// DeleteApplicationRestore deletes a restore, leaving the restored resources in place
+func DeleteApplicationRestore(name, namespace string) error {
+	return storkops.Instance().DeleteApplicationRestore(name, namespace)
+}
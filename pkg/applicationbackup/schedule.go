@@ -0,0 +1,117 @@
+package applicationbackup
+
+import (
+	"fmt"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/portworx/sched-ops/task"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateIntervalSchedulePolicy creates a schedule policy triggering every intervalMinutes and
+// retaining the last retain objects
+func CreateIntervalSchedulePolicy(name string, intervalMinutes int, retain int) (*storkv1.SchedulePolicy, error) {
+	schedPolicy := &storkv1.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: name,
+		},
+		Policy: storkv1.SchedulePolicyItem{
+			Interval: &storkv1.IntervalPolicy{
+				IntervalMinutes: intervalMinutes,
+				Retain:          storkv1.Retain(retain),
+			},
+		},
+	}
+	return storkops.Instance().CreateSchedulePolicy(schedPolicy)
+}
+
+// CreateApplicationBackupSchedule creates a schedule backing up a namespace with a schedule policy
+func CreateApplicationBackupSchedule(
+	name string,
+	namespace string,
+	schedulePolicyName string,
+	backupLocation *storkv1.BackupLocation,
+) (*storkv1.ApplicationBackupSchedule, error) {
+
+	appBackupSchedule := &storkv1.ApplicationBackupSchedule{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: storkv1.ApplicationBackupScheduleSpec{
+			Template: storkv1.ApplicationBackupTemplateSpec{
+				Spec: storkv1.ApplicationBackupSpec{
+					Namespaces:     []string{namespace},
+					BackupLocation: backupLocation.Name,
+				},
+			},
+			SchedulePolicyName: schedulePolicyName,
+			ReclaimPolicy:      storkv1.ReclaimPolicyDelete,
+		},
+	}
+
+	return storkops.Instance().CreateApplicationBackupSchedule(appBackupSchedule)
+}
+
+// SuspendApplicationBackupSchedule suspends or resumes a schedule
+func SuspendApplicationBackupSchedule(name, namespace string, suspend bool) error {
+	appBackupSchedule, err := storkops.Instance().GetApplicationBackupSchedule(name, namespace)
+	if err != nil {
+		return err
+	}
+	appBackupSchedule.Spec.Suspend = &suspend
+	_, err = storkops.Instance().UpdateApplicationBackupSchedule(appBackupSchedule)
+	return err
+}
+
+// WaitForScheduledAppBackups waits for a schedule to complete count backups of a policy type and
+// returns them, oldest first. It fails as soon as a scheduled backup fails.
+func WaitForScheduledAppBackups(
+	name string,
+	namespace string,
+	policyType storkv1.SchedulePolicyType,
+	count int,
+	timeout time.Duration,
+) ([]*storkv1.ApplicationBackup, error) {
+	getScheduledBackups := func() (interface{}, bool, error) {
+		appBackupSchedule, err := storkops.Instance().GetApplicationBackupSchedule(name, namespace)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var completed []string
+		for _, item := range appBackupSchedule.Status.Items[policyType] {
+			switch item.Status {
+			case storkv1.ApplicationBackupStatusSuccessful, storkv1.ApplicationBackupStatusPartialSuccess:
+				completed = append(completed, item.Name)
+			case storkv1.ApplicationBackupStatusFailed:
+				return nil, false, fmt.Errorf("scheduled app backup %s of %s in %s failed", item.Name, name, namespace)
+			}
+		}
+		if len(completed) < count {
+			return nil, true, fmt.Errorf("%d of %d %s app backups of %s in %s complete.Retrying", len(completed), count, policyType, name, namespace)
+		}
+		return completed, false, nil
+	}
+	completed, err := task.DoRetryWithTimeout(getScheduledBackups, timeout, applicationBackupScheduleRetryInterval)
+	if err != nil {
+		return nil, err
+	}
+	var appBackups []*storkv1.ApplicationBackup
+	for _, backupName := range completed.([]string) {
+		appBackup, err := storkops.Instance().GetApplicationBackup(backupName, namespace)
+		if err != nil {
+			return nil, err
+		}
+		appBackups = append(appBackups, appBackup)
+	}
+	return appBackups, nil
+}
+
+// DeleteApplicationBackupSchedule deletes a schedule, which deletes its backups as its reclaim
+// policy is Delete
+func DeleteApplicationBackupSchedule(name, namespace string) error {
+	return storkops.Instance().DeleteApplicationBackupSchedule(name, namespace)
+}
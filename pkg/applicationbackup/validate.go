@@ -0,0 +1,292 @@
+package applicationbackup
+
+import (
+	"fmt"
+	"strings"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+)
+
+// Volume is a persistent volume claim whose volume is backed up, restored or cloned
+type Volume struct {
+	Namespace             string
+	PersistentVolumeClaim string
+}
+
+// Expected is what a backup or clone must contain
+type Expected struct {
+	// Resources must be backed up or cloned. An empty group is the core group.
+	Resources []storkv1.ObjectInfo
+	// Volumes must be backed up or cloned
+	Volumes []Volume
+}
+
+// ExpectedFromNamespaces expects the persistent volume claims of namespaces and their volumes
+func ExpectedFromNamespaces(namespaces []string) (*Expected, error) {
+	expected := &Expected{}
+	for _, namespace := range namespaces {
+		pvcs, err := core.Instance().GetPersistentVolumeClaims(namespace, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pvcs of namespace %s: %v", namespace, err)
+		}
+		for _, pvc := range pvcs.Items {
+			info := storkv1.ObjectInfo{Name: pvc.Name, Namespace: namespace}
+			info.Kind = "PersistentVolumeClaim"
+			info.Version = "v1"
+			expected.Resources = append(expected.Resources, info)
+			expected.Volumes = append(expected.Volumes, Volume{Namespace: namespace, PersistentVolumeClaim: pvc.Name})
+		}
+	}
+	return expected, nil
+}
+
+// ErrValidation is returned when a backup, restore or clone isn't what was expected
+type ErrValidation struct {
+	// Kind is ApplicationBackup, ApplicationRestore or ApplicationClone
+	Kind      string
+	Name      string
+	Namespace string
+	Failures  []string
+}
+
+func (e *ErrValidation) Error() string {
+	return fmt.Sprintf("%s %s in %s failed validation: %s", e.Kind, e.Name, e.Namespace, strings.Join(e.Failures, "; "))
+}
+
+type failures []string
+
+func (f *failures) add(format string, args ...interface{}) {
+	*f = append(*f, fmt.Sprintf(format, args...))
+}
+
+func (f failures) err(kind, name, namespace string) error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &ErrValidation{Kind: kind, Name: name, Namespace: namespace, Failures: f}
+}
+
+// qualifiedName returns the name of a resource prefixed by its namespace, if it is namespaced
+func qualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// resourceKey identifies a resource regardless of its version
+func resourceKey(namespace, name, group, kind string) string {
+	if group == "" {
+		group = "core"
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", group, kind, namespace, name)
+}
+
+// ValidateApplicationBackup validates that a backup completed with every one of its volumes, that
+// it holds the expected resources and volumes and that it holds nothing outside of its namespaces
+func ValidateApplicationBackup(name, namespace string, expected *Expected) error {
+	appBackup, err := storkops.Instance().GetApplicationBackup(name, namespace)
+	if err != nil {
+		return err
+	}
+	var f failures
+	if appBackup.Status.Status != storkv1.ApplicationBackupStatusSuccessful {
+		f.add("status is %s: %s", appBackup.Status.Status, appBackup.Status.Reason)
+	}
+	if appBackup.Status.Stage != storkv1.ApplicationBackupStageFinal {
+		f.add("stage is %s", appBackup.Status.Stage)
+	}
+	namespaces := make(map[string]bool)
+	for _, ns := range appBackup.Spec.Namespaces {
+		namespaces[ns] = true
+	}
+
+	resources := make(map[string]bool)
+	for _, r := range appBackup.Status.Resources {
+		resources[resourceKey(r.Namespace, r.Name, r.Group, r.Kind)] = true
+		if r.Namespace != "" && !namespaces[r.Namespace] {
+			f.add("resource %s %s is outside of the backed up namespaces", r.Kind, qualifiedName(r.Namespace, r.Name))
+		}
+	}
+	volumes := make(map[Volume]bool)
+	for _, v := range appBackup.Status.Volumes {
+		volumes[Volume{Namespace: v.Namespace, PersistentVolumeClaim: v.PersistentVolumeClaim}] = true
+		if v.Status != storkv1.ApplicationBackupStatusSuccessful {
+			f.add("volume of pvc %s/%s is %s: %s", v.Namespace, v.PersistentVolumeClaim, v.Status, v.Reason)
+		} else if v.BackupID == "" {
+			f.add("volume of pvc %s/%s has no backup id", v.Namespace, v.PersistentVolumeClaim)
+		}
+		if !namespaces[v.Namespace] {
+			f.add("volume of pvc %s/%s is outside of the backed up namespaces", v.Namespace, v.PersistentVolumeClaim)
+		}
+	}
+
+	if expected != nil {
+		for _, r := range expected.Resources {
+			if !resources[resourceKey(r.Namespace, r.Name, r.Group, r.Kind)] {
+				f.add("resource %s %s is not backed up", r.Kind, qualifiedName(r.Namespace, r.Name))
+			}
+		}
+		for _, v := range expected.Volumes {
+			if !volumes[v] {
+				f.add("volume of pvc %s/%s is not backed up", v.Namespace, v.PersistentVolumeClaim)
+			}
+		}
+	}
+	return f.err("ApplicationBackup", name, namespace)
+}
+
+// restoredStatus validates the status of a restored resource or volume against the replace policy
+// of the restore. Retain keeps resources which already exist.
+func restoredStatus(status storkv1.ApplicationRestoreStatusType, policy storkv1.ApplicationRestoreReplacePolicyType) bool {
+	switch status {
+	case storkv1.ApplicationRestoreStatusSuccessful:
+		return true
+	case storkv1.ApplicationRestoreStatusRetained:
+		return policy == storkv1.ApplicationRestoreReplacePolicyRetain
+	}
+	return false
+}
+
+// ValidateApplicationRestore validates that a restore restored every resource and volume of its
+// backup to the namespaces they are mapped to, honoring its replace policy, and that the restored
+// persistent volume claims exist
+func ValidateApplicationRestore(name, namespace string) error {
+	appRestore, err := storkops.Instance().GetApplicationRestore(name, namespace)
+	if err != nil {
+		return err
+	}
+	appBackup, err := storkops.Instance().GetApplicationBackup(appRestore.Spec.BackupName, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get backup %s of restore %s: %v", appRestore.Spec.BackupName, name, err)
+	}
+	policy := appRestore.Spec.ReplacePolicy
+	mapping := appRestore.Spec.NamespaceMapping
+	if len(mapping) == 0 {
+		mapping = make(map[string]string)
+		for _, ns := range appBackup.Spec.Namespaces {
+			mapping[ns] = ns
+		}
+	}
+
+	var f failures
+	switch appRestore.Status.Status {
+	case storkv1.ApplicationRestoreStatusSuccessful:
+	case storkv1.ApplicationRestoreStatusPartialSuccess:
+		if policy != storkv1.ApplicationRestoreReplacePolicyRetain {
+			f.add("status is %s with replace policy %s: %s", appRestore.Status.Status, policy, appRestore.Status.Reason)
+		}
+	default:
+		f.add("status is %s: %s", appRestore.Status.Status, appRestore.Status.Reason)
+	}
+
+	resources := make(map[string]*storkv1.ApplicationRestoreResourceInfo)
+	for _, r := range appRestore.Status.Resources {
+		resources[resourceKey(r.Namespace, r.Name, r.Group, r.Kind)] = r
+	}
+	for _, r := range appBackup.Status.Resources {
+		ns := r.Namespace
+		if ns != "" {
+			var ok bool
+			if ns, ok = mapping[r.Namespace]; !ok {
+				continue
+			}
+		}
+		restored, ok := resources[resourceKey(ns, r.Name, r.Group, r.Kind)]
+		if !ok {
+			f.add("resource %s %s is not restored to %s", r.Kind, qualifiedName(r.Namespace, r.Name), qualifiedName(ns, r.Name))
+		} else if !restoredStatus(restored.Status, policy) {
+			f.add("resource %s %s is %s with replace policy %s: %s", r.Kind, qualifiedName(ns, r.Name), restored.Status, policy, restored.Reason)
+		}
+	}
+
+	volumes := make(map[Volume]*storkv1.ApplicationRestoreVolumeInfo)
+	for _, v := range appRestore.Status.Volumes {
+		volumes[Volume{Namespace: v.SourceNamespace, PersistentVolumeClaim: v.PersistentVolumeClaim}] = v
+	}
+	for _, v := range appBackup.Status.Volumes {
+		ns, ok := mapping[v.Namespace]
+		if !ok {
+			continue
+		}
+		restored, ok := volumes[Volume{Namespace: v.Namespace, PersistentVolumeClaim: v.PersistentVolumeClaim}]
+		if !ok {
+			f.add("volume of pvc %s/%s is not restored", v.Namespace, v.PersistentVolumeClaim)
+			continue
+		}
+		if !restoredStatus(restored.Status, policy) {
+			f.add("volume of pvc %s/%s is %s with replace policy %s: %s", ns, v.PersistentVolumeClaim, restored.Status, policy, restored.Reason)
+			continue
+		}
+		if _, err := core.Instance().GetPersistentVolumeClaim(v.PersistentVolumeClaim, ns); err != nil {
+			f.add("restored pvc %s/%s: %v", ns, v.PersistentVolumeClaim, err)
+		}
+	}
+	return f.err("ApplicationRestore", name, namespace)
+}
+
+// ValidateApplicationClone validates that a clone cloned the expected resources and volumes of its
+// source namespace, honoring its replace policy, and that the cloned persistent volume claims exist
+// in its destination namespace
+func ValidateApplicationClone(name, namespace string, expected *Expected) error {
+	appClone, err := storkops.Instance().GetApplicationClone(name, namespace)
+	if err != nil {
+		return err
+	}
+	policy := appClone.Spec.ReplacePolicy
+	cloned := func(status storkv1.ApplicationCloneStatusType) bool {
+		return status == storkv1.ApplicationCloneStatusSuccessful ||
+			(status == storkv1.ApplicationCloneStatusRetained && policy == storkv1.ApplicationCloneReplacePolicyRetain)
+	}
+
+	var f failures
+	if appClone.Status.Status != storkv1.ApplicationCloneStatusSuccessful &&
+		!(appClone.Status.Status == storkv1.ApplicationCloneStatusPartialSuccess && policy == storkv1.ApplicationCloneReplacePolicyRetain) {
+		f.add("status is %s with replace policy %s", appClone.Status.Status, policy)
+	}
+	if appClone.Status.Stage != storkv1.ApplicationCloneStageFinal {
+		f.add("stage is %s", appClone.Status.Stage)
+	}
+
+	resources := make(map[string]*storkv1.ApplicationCloneResourceInfo)
+	for _, r := range appClone.Status.Resources {
+		resources[resourceKey("", r.Name, r.Group, r.Kind)] = r
+		if !cloned(r.Status) {
+			f.add("resource %s %s is %s with replace policy %s: %s", r.Kind, r.Name, r.Status, policy, r.Reason)
+		}
+	}
+	volumes := make(map[string]*storkv1.ApplicationCloneVolumeInfo)
+	for _, v := range appClone.Status.Volumes {
+		volumes[v.PersistentVolumeClaim] = v
+		if !cloned(v.Status) {
+			f.add("volume of pvc %s is %s with replace policy %s: %s", v.PersistentVolumeClaim, v.Status, policy, v.Reason)
+		}
+	}
+
+	if expected != nil {
+		for _, r := range expected.Resources {
+			if r.Namespace != "" && r.Namespace != appClone.Spec.SourceNamespace {
+				continue
+			}
+			if _, ok := resources[resourceKey("", r.Name, r.Group, r.Kind)]; !ok {
+				f.add("resource %s %s is not cloned", r.Kind, r.Name)
+			}
+		}
+		for _, v := range expected.Volumes {
+			if v.Namespace != appClone.Spec.SourceNamespace {
+				continue
+			}
+			clonedVolume, ok := volumes[v.PersistentVolumeClaim]
+			if !ok || clonedVolume.CloneVolume == "" {
+				f.add("volume of pvc %s is not cloned", v.PersistentVolumeClaim)
+				continue
+			}
+			if _, err := core.Instance().GetPersistentVolumeClaim(v.PersistentVolumeClaim, appClone.Spec.DestinationNamespace); err != nil {
+				f.add("cloned pvc %s/%s: %v", appClone.Spec.DestinationNamespace, v.PersistentVolumeClaim, err)
+			}
+		}
+	}
+	return f.err("ApplicationClone", name, namespace)
+}
@@ -0,0 +1,176 @@
+package asyncdr
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/portworx/sched-ops/k8s/apps"
+	"github.com/portworx/torpedo/pkg/log"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// migrationReplicasAnnotation is where stork keeps the replicas of the applications it migrates
+	// without starting them, which storkctl activate migrations restores
+	migrationReplicasAnnotation = "stork.libopenstorage.org/migrationReplicas"
+
+	appRetryInterval = 10 * time.Second
+)
+
+// Replicas are the replicas of the applications scaled down by DeactivateApplications, by
+// kind/namespace/name. Migrations after the scale down record 0 replicas in their annotations, so
+// activations fall back to them.
+type Replicas map[string]int32
+
+func appKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// target returns the replicas an application is activated with
+func (r Replicas) target(kind, namespace, name string, annotations map[string]string) (int32, error) {
+	if value, ok := annotations[migrationReplicasAnnotation]; ok {
+		replicas, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s annotation [%s] of %s %s/%s", migrationReplicasAnnotation, value, kind, namespace, name)
+		}
+		if replicas > 0 {
+			return int32(replicas), nil
+		}
+	}
+	return r[appKey(kind, namespace, name)], nil
+}
+
+// DeactivateApplications scales the deployments and statefulsets of the namespaces down to 0
+// replicas and records their replicas
+func DeactivateApplications(namespaces []string, replicas Replicas) error {
+	for _, ns := range namespaces {
+		deployments, err := apps.Instance().ListDeployments(ns, meta_v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range deployments.Items {
+			d := &deployments.Items[i]
+			current := int32(1)
+			if d.Spec.Replicas != nil {
+				current = *d.Spec.Replicas
+			}
+			if current == 0 {
+				continue
+			}
+			replicas[appKey("Deployment", ns, d.Name)] = current
+			zero := int32(0)
+			d.Spec.Replicas = &zero
+			if _, err := apps.Instance().UpdateDeployment(d); err != nil {
+				return fmt.Errorf("Failed to scale down deployment %s/%s: %v", ns, d.Name, err)
+			}
+			log.Infof("Scaled down deployment %s/%s from %d replicas", ns, d.Name, current)
+		}
+
+		statefulSets, err := apps.Instance().ListStatefulSets(ns, meta_v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range statefulSets.Items {
+			ss := &statefulSets.Items[i]
+			current := int32(1)
+			if ss.Spec.Replicas != nil {
+				current = *ss.Spec.Replicas
+			}
+			if current == 0 {
+				continue
+			}
+			replicas[appKey("StatefulSet", ns, ss.Name)] = current
+			zero := int32(0)
+			ss.Spec.Replicas = &zero
+			if _, err := apps.Instance().UpdateStatefulSet(ss); err != nil {
+				return fmt.Errorf("Failed to scale down statefulset %s/%s: %v", ns, ss.Name, err)
+			}
+			log.Infof("Scaled down statefulset %s/%s from %d replicas", ns, ss.Name, current)
+		}
+	}
+	return nil
+}
+
+// ActivateApplications scales the deployments and statefulsets of the namespaces up to the
+// replicas recorded by stork when migrating them, like storkctl activate migrations does, or else
+// to the replicas recorded when deactivating them
+func ActivateApplications(namespaces []string, replicas Replicas) error {
+	for _, ns := range namespaces {
+		deployments, err := apps.Instance().ListDeployments(ns, meta_v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range deployments.Items {
+			d := &deployments.Items[i]
+			target, err := replicas.target("Deployment", ns, d.Name, d.Annotations)
+			if err != nil {
+				return err
+			}
+			if target == 0 || (d.Spec.Replicas != nil && *d.Spec.Replicas == target) {
+				continue
+			}
+			d.Spec.Replicas = &target
+			if _, err := apps.Instance().UpdateDeployment(d); err != nil {
+				return fmt.Errorf("Failed to activate deployment %s/%s: %v", ns, d.Name, err)
+			}
+			log.Infof("Activated deployment %s/%s with %d replicas", ns, d.Name, target)
+		}
+
+		statefulSets, err := apps.Instance().ListStatefulSets(ns, meta_v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range statefulSets.Items {
+			ss := &statefulSets.Items[i]
+			target, err := replicas.target("StatefulSet", ns, ss.Name, ss.Annotations)
+			if err != nil {
+				return err
+			}
+			if target == 0 || (ss.Spec.Replicas != nil && *ss.Spec.Replicas == target) {
+				continue
+			}
+			ss.Spec.Replicas = &target
+			if _, err := apps.Instance().UpdateStatefulSet(ss); err != nil {
+				return fmt.Errorf("Failed to activate statefulset %s/%s: %v", ns, ss.Name, err)
+			}
+			log.Infof("Activated statefulset %s/%s with %d replicas", ns, ss.Name, target)
+		}
+	}
+	return nil
+}
+
+// WaitForApplications waits for the deployments and statefulsets of the namespaces with replicas
+// to be ready
+func WaitForApplications(namespaces []string, timeout time.Duration) error {
+	for _, ns := range namespaces {
+		deployments, err := apps.Instance().ListDeployments(ns, meta_v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range deployments.Items {
+			d := &deployments.Items[i]
+			if d.Spec.Replicas != nil && *d.Spec.Replicas == 0 {
+				continue
+			}
+			if err := apps.Instance().ValidateDeployment(d, timeout, appRetryInterval); err != nil {
+				return fmt.Errorf("Deployment %s/%s is not ready: %v", ns, d.Name, err)
+			}
+		}
+
+		statefulSets, err := apps.Instance().ListStatefulSets(ns, meta_v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range statefulSets.Items {
+			ss := &statefulSets.Items[i]
+			if ss.Spec.Replicas != nil && *ss.Spec.Replicas == 0 {
+				continue
+			}
+			if err := apps.Instance().ValidateStatefulSet(ss, timeout); err != nil {
+				return fmt.Errorf("Statefulset %s/%s is not ready: %v", ns, ss.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package asyncdr
+
+import (
+	"fmt"
+	"time"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	clusterPairRetryTimeout  = 5 * time.Minute
+	clusterPairRetryInterval = 10 * time.Second
+)
+
+// CreateClusterPair pairs the current cluster with the remote cluster of a kubeconfig, like
+// storkctl generate clusterpair does. options are the storage options of the pair, such as the
+// ones the volume driver returns from GetClusterPairingInfo.
+func CreateClusterPair(
+	name string,
+	namespace string,
+	remoteKubeconfig string,
+	options map[string]string,
+) (*storkapi.ClusterPair, error) {
+	config, err := clientcmd.LoadFromFile(remoteKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load kubeconfig %s: %v", remoteKubeconfig, err)
+	}
+	// Keep only the current context of the remote cluster, with its certificates inlined
+	if err := clientcmdapi.MinifyConfig(config); err != nil {
+		return nil, fmt.Errorf("Failed to minify kubeconfig %s: %v", remoteKubeconfig, err)
+	}
+	if err := clientcmdapi.FlattenConfig(config); err != nil {
+		return nil, fmt.Errorf("Failed to flatten kubeconfig %s: %v", remoteKubeconfig, err)
+	}
+
+	clusterPair := &storkapi.ClusterPair{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: storkapi.ClusterPairSpec{
+			Config:  *config,
+			Options: options,
+		},
+	}
+	return storkops.Instance().CreateClusterPair(clusterPair)
+}
+
+// WaitForClusterPair waits for the scheduler and storage of a cluster pair to be ready
+func WaitForClusterPair(name, namespace string) error {
+	return storkops.Instance().ValidateClusterPair(name, namespace, clusterPairRetryTimeout, clusterPairRetryInterval)
+}
+
+// DeleteClusterPair deletes a cluster pair
+func DeleteClusterPair(name, namespace string) error {
+	return storkops.Instance().DeleteClusterPair(name, namespace)
+}
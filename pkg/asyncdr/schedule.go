@@ -0,0 +1,65 @@
+package asyncdr
+
+import (
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateIntervalSchedulePolicy creates a schedule policy triggering every intervalMinutes
+func CreateIntervalSchedulePolicy(name string, intervalMinutes int) (*storkapi.SchedulePolicy, error) {
+	schedPolicy := &storkapi.SchedulePolicy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: name,
+		},
+		Policy: storkapi.SchedulePolicyItem{
+			Interval: &storkapi.IntervalPolicy{
+				IntervalMinutes: intervalMinutes,
+			},
+		},
+	}
+	return storkops.Instance().CreateSchedulePolicy(schedPolicy)
+}
+
+// CreateMigrationSchedule creates a schedule migrating namespaces with a cluster pair without
+// starting the migrated applications
+func CreateMigrationSchedule(
+	name string,
+	namespace string,
+	clusterPair string,
+	migrationNamespaces []string,
+	schedulePolicyName string,
+) (*storkapi.MigrationSchedule, error) {
+	includeResources := true
+	startApplications := false
+
+	migrationSchedule := &storkapi.MigrationSchedule{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: storkapi.MigrationScheduleSpec{
+			Template: storkapi.MigrationTemplateSpec{
+				Spec: storkapi.MigrationSpec{
+					ClusterPair:       clusterPair,
+					IncludeResources:  &includeResources,
+					StartApplications: &startApplications,
+					Namespaces:        migrationNamespaces,
+				},
+			},
+			SchedulePolicyName: schedulePolicyName,
+		},
+	}
+	return storkops.Instance().CreateMigrationSchedule(migrationSchedule)
+}
+
+// SuspendMigrationSchedule suspends or resumes a migration schedule
+func SuspendMigrationSchedule(name, namespace string, suspend bool) error {
+	migrationSchedule, err := storkops.Instance().GetMigrationSchedule(name, namespace)
+	if err != nil {
+		return err
+	}
+	migrationSchedule.Spec.Suspend = &suspend
+	_, err = storkops.Instance().UpdateMigrationSchedule(migrationSchedule)
+	return err
+}
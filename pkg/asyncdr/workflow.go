@@ -0,0 +1,477 @@
+package asyncdr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/portworx/sched-ops/task"
+	"github.com/portworx/torpedo/pkg/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PhasePair pairs the source and destination clusters both ways
+	PhasePair = "Pair"
+	// PhaseSchedule migrates the applications to the destination on a schedule
+	PhaseSchedule = "Schedule"
+	// PhasePlannedFailover moves the applications to the destination with the source up
+	PhasePlannedFailover = "PlannedFailover"
+	// PhaseUnplannedFailover starts the applications on the destination with the source down
+	PhaseUnplannedFailover = "UnplannedFailover"
+	// PhaseFailback moves the applications back to the source with a reverse migration
+	PhaseFailback = "Failback"
+
+	defaultWorkflowTimeout = 10 * time.Minute
+)
+
+// Cluster is a cluster of a DR relationship
+type Cluster struct {
+	Name string
+	// Kubeconfig is the path of the kubeconfig of the cluster
+	Kubeconfig string
+}
+
+// Objectives are the recovery point and recovery time objectives of the DR runbooks. Zero
+// objectives are not checked.
+type Objectives struct {
+	RPO time.Duration
+	RTO time.Duration
+}
+
+// Report is what a phase of a DR runbook achieved
+type Report struct {
+	Phase string
+	Start time.Time
+	End   time.Time
+	// LastSync is the start of the last migration whose data the phase recovered
+	LastSync time.Time
+	// RPO is how much of the data written before the phase may be lost
+	RPO time.Duration
+	// RTO is how long the applications were down
+	RTO time.Duration
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf("%s took %v with RPO %v and RTO %v", r.Phase, r.End.Sub(r.Start).Round(time.Second), r.RPO.Round(time.Second), r.RTO.Round(time.Second))
+}
+
+// ErrObjectivesMissed is returned when a phase of a DR runbook misses its RPO or RTO objective
+type ErrObjectivesMissed struct {
+	Report     Report
+	Objectives Objectives
+}
+
+func (e *ErrObjectivesMissed) Error() string {
+	var missed []string
+	if e.Objectives.RPO > 0 && e.Report.RPO > e.Objectives.RPO {
+		missed = append(missed, fmt.Sprintf("RPO %v over objective %v", e.Report.RPO.Round(time.Second), e.Objectives.RPO))
+	}
+	if e.Objectives.RTO > 0 && e.Report.RTO > e.Objectives.RTO {
+		missed = append(missed, fmt.Sprintf("RTO %v over objective %v", e.Report.RTO.Round(time.Second), e.Objectives.RTO))
+	}
+	return fmt.Sprintf("%s missed its objectives: %s", e.Report.Phase, strings.Join(missed, ", "))
+}
+
+// Workflow runs the DR runbooks of applications between a source and a destination cluster:
+// pairing the clusters, migrating on a schedule, failing over with or without the source and
+// failing back. Each phase is reported and checked against the objectives.
+type Workflow struct {
+	Source      Cluster
+	Destination Cluster
+	// Namespaces are the namespaces of the applications
+	Namespaces []string
+	// Namespace holds the cluster pairs, the schedule and the migrations on both clusters. It must
+	// be the admin namespace of stork to migrate several namespaces.
+	Namespace string
+	// Name prefixes the cluster pairs, the schedule policy, the schedule and the migrations
+	Name       string
+	Objectives Objectives
+	// Use switches the clients of sched-ops to a cluster
+	Use func(c Cluster) error
+	// PairingInfo returns the storage options to pair with the remote cluster, which is in use,
+	// reverse being true to pair the destination with the source. Pairs have no storage options
+	// if nil.
+	PairingInfo func(remote Cluster, reverse bool) (map[string]string, error)
+	// Timeout bounds each migration and the wait for the applications, 10 minutes if 0
+	Timeout time.Duration
+	// RetryInterval is the interval of the polls, 10 seconds if 0
+	RetryInterval time.Duration
+	// Reports are the reports of the phases run
+	Reports []Report
+
+	replicas Replicas
+	lastSync time.Time
+	// resumed is when a failback resumed the schedule, before which scheduled migrations are
+	// not counted
+	resumed time.Time
+}
+
+func (w *Workflow) pairName() string {
+	return w.Name + "-pair"
+}
+
+func (w *Workflow) reversePairName() string {
+	return w.Name + "-reverse-pair"
+}
+
+func (w *Workflow) policyName() string {
+	return w.Name + "-policy"
+}
+
+func (w *Workflow) scheduleName() string {
+	return w.Name + "-schedule"
+}
+
+func (w *Workflow) timeouts() (time.Duration, time.Duration) {
+	timeout, retryInterval := w.Timeout, w.RetryInterval
+	if timeout == 0 {
+		timeout = defaultWorkflowTimeout
+	}
+	if retryInterval == 0 {
+		retryInterval = migrationRetryInterval
+	}
+	return timeout, retryInterval
+}
+
+func (w *Workflow) use(c Cluster) error {
+	if err := w.Use(c); err != nil {
+		return fmt.Errorf("Failed to switch to cluster %s: %v", c.Name, err)
+	}
+	return nil
+}
+
+// report records the report of a phase and checks it against the objectives
+func (w *Workflow) report(r Report) (*Report, error) {
+	w.Reports = append(w.Reports, r)
+	log.Infof("DR phase %s", r.String())
+	if (w.Objectives.RPO > 0 && r.RPO > w.Objectives.RPO) || (w.Objectives.RTO > 0 && r.RTO > w.Objectives.RTO) {
+		return &r, &ErrObjectivesMissed{Report: r, Objectives: w.Objectives}
+	}
+	return &r, nil
+}
+
+// Pair pairs the source with the destination for migrations and the destination with the source
+// for failbacks
+func (w *Workflow) Pair() (*Report, error) {
+	r := Report{Phase: PhasePair, Start: time.Now()}
+	if err := w.pair(w.Source, w.Destination, w.pairName(), false); err != nil {
+		return nil, err
+	}
+	if err := w.pair(w.Destination, w.Source, w.reversePairName(), true); err != nil {
+		return nil, err
+	}
+	r.End = time.Now()
+	return w.report(r)
+}
+
+func (w *Workflow) pair(local, remote Cluster, name string, reverse bool) error {
+	var options map[string]string
+	if w.PairingInfo != nil {
+		if err := w.use(remote); err != nil {
+			return err
+		}
+		var err error
+		if options, err = w.PairingInfo(remote, reverse); err != nil {
+			return fmt.Errorf("Failed to get pairing info of cluster %s: %v", remote.Name, err)
+		}
+	}
+	if err := w.use(local); err != nil {
+		return err
+	}
+	log.Infof("Pairing cluster %s with cluster %s as %s", local.Name, remote.Name, name)
+	if _, err := CreateClusterPair(name, w.Namespace, remote.Kubeconfig, options); err != nil {
+		return fmt.Errorf("Failed to create cluster pair %s on cluster %s: %v", name, local.Name, err)
+	}
+	if err := WaitForClusterPair(name, w.Namespace); err != nil {
+		return fmt.Errorf("Cluster pair %s on cluster %s is not ready: %v", name, local.Name, err)
+	}
+	return nil
+}
+
+// StartSchedule migrates the applications to the destination every intervalMinutes
+func (w *Workflow) StartSchedule(intervalMinutes int) error {
+	if err := w.use(w.Source); err != nil {
+		return err
+	}
+	if _, err := CreateIntervalSchedulePolicy(w.policyName(), intervalMinutes); err != nil {
+		return fmt.Errorf("Failed to create schedule policy %s: %v", w.policyName(), err)
+	}
+	if _, err := CreateMigrationSchedule(w.scheduleName(), w.Namespace, w.pairName(), w.Namespaces, w.policyName()); err != nil {
+		return fmt.Errorf("Failed to create migration schedule %s: %v", w.scheduleName(), err)
+	}
+	return nil
+}
+
+// SuspendSchedule suspends or resumes the migrations to the destination
+func (w *Workflow) SuspendSchedule(suspend bool) error {
+	if err := w.use(w.Source); err != nil {
+		return err
+	}
+	return SuspendMigrationSchedule(w.scheduleName(), w.Namespace, suspend)
+}
+
+// scheduledMigrations returns the migrations of the schedule
+func (w *Workflow) scheduledMigrations() ([]*storkapi.ScheduledMigrationStatus, error) {
+	migrationSchedule, err := storkops.Instance().GetMigrationSchedule(w.scheduleName(), w.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return migrationSchedule.Status.Items[storkapi.SchedulePolicyTypeInterval], nil
+}
+
+// WaitForScheduledMigrations waits for count migrations of the schedule to succeed, counting only
+// the migrations started since the last failback. Its RPO is the longest time between the starts
+// of successful migrations, up to now, as a disaster at the end of it loses the data written since
+// the last one.
+func (w *Workflow) WaitForScheduledMigrations(count int) (*Report, error) {
+	r := Report{Phase: PhaseSchedule, Start: time.Now()}
+	if err := w.use(w.Source); err != nil {
+		return nil, err
+	}
+	timeout, retryInterval := w.timeouts()
+	var successful []time.Time
+	checkMigrations := func() (interface{}, bool, error) {
+		migrations, err := w.scheduledMigrations()
+		if err != nil {
+			return "", false, err
+		}
+		successful = successful[:0]
+		for _, m := range migrations {
+			if m.CreationTimestamp.Time.Before(w.resumed) {
+				continue
+			}
+			switch m.Status {
+			case storkapi.MigrationStatusSuccessful:
+				successful = append(successful, m.CreationTimestamp.Time)
+			case storkapi.MigrationStatusFailed:
+				return "", false, fmt.Errorf("Scheduled migration %s failed", m.Name)
+			}
+		}
+		if len(successful) < count {
+			return "", true, fmt.Errorf("%d of %d scheduled migrations of %s are successful", len(successful), count, w.scheduleName())
+		}
+		return "", false, nil
+	}
+	if _, err := task.DoRetryWithTimeout(checkMigrations, time.Duration(count)*timeout, retryInterval); err != nil {
+		return nil, err
+	}
+	r.End = time.Now()
+	for i := range successful {
+		next := r.End
+		if i+1 < len(successful) {
+			next = successful[i+1]
+		}
+		if gap := next.Sub(successful[i]); gap > r.RPO {
+			r.RPO = gap
+		}
+	}
+	w.lastSync = successful[len(successful)-1]
+	r.LastSync = w.lastSync
+	return w.report(r)
+}
+
+// VerifyScheduleSuspended verifies that the suspended schedule starts no migration for a while
+func (w *Workflow) VerifyScheduleSuspended(window time.Duration) error {
+	if err := w.use(w.Source); err != nil {
+		return err
+	}
+	before, err := w.scheduledMigrations()
+	if err != nil {
+		return err
+	}
+	time.Sleep(window)
+	after, err := w.scheduledMigrations()
+	if err != nil {
+		return err
+	}
+	if len(after) > len(before) {
+		return fmt.Errorf("Suspended migration schedule %s started migration %s", w.scheduleName(), after[len(after)-1].Name)
+	}
+	return nil
+}
+
+// migrate migrates the applications with a cluster pair of the cluster in use and returns when
+// the migration started
+func (w *Workflow) migrate(name, clusterPair string) (time.Time, error) {
+	includeResources := true
+	startApplications := false
+	migration := &storkapi.Migration{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      name,
+			Namespace: w.Namespace,
+		},
+		Spec: storkapi.MigrationSpec{
+			ClusterPair:       clusterPair,
+			IncludeResources:  &includeResources,
+			StartApplications: &startApplications,
+			Namespaces:        w.Namespaces,
+		},
+	}
+	started := time.Now()
+	if _, err := storkops.Instance().CreateMigration(migration); err != nil {
+		return started, fmt.Errorf("Failed to create migration %s: %v", name, err)
+	}
+	timeout, retryInterval := w.timeouts()
+	checkMigration := func() (interface{}, bool, error) {
+		mig, err := storkops.Instance().GetMigration(name, w.Namespace)
+		if err != nil {
+			return "", false, err
+		}
+		switch mig.Status.Status {
+		case storkapi.MigrationStatusSuccessful:
+			return "", false, nil
+		case storkapi.MigrationStatusFailed, storkapi.MigrationStatusPartialSuccess:
+			return "", false, fmt.Errorf("Migration %s in namespace %s is %s", name, w.Namespace, mig.Status.Status)
+		}
+		return "", true, fmt.Errorf("Migration %s in namespace %s is %s. Retrying", name, w.Namespace, mig.Status.Status)
+	}
+	_, err := task.DoRetryWithTimeout(checkMigration, timeout, retryInterval)
+	return started, err
+}
+
+// activate starts the applications on a cluster and returns when they are ready
+func (w *Workflow) activate(c Cluster) (time.Time, error) {
+	if err := w.use(c); err != nil {
+		return time.Time{}, err
+	}
+	if err := ActivateApplications(w.Namespaces, w.replicas); err != nil {
+		return time.Time{}, err
+	}
+	timeout, _ := w.timeouts()
+	if err := WaitForApplications(w.Namespaces, timeout); err != nil {
+		return time.Time{}, fmt.Errorf("Applications are not ready on cluster %s: %v", c.Name, err)
+	}
+	return time.Now(), nil
+}
+
+// deactivate stops the applications on a cluster
+func (w *Workflow) deactivate(c Cluster) error {
+	if err := w.use(c); err != nil {
+		return err
+	}
+	if w.replicas == nil {
+		w.replicas = make(Replicas)
+	}
+	return DeactivateApplications(w.Namespaces, w.replicas)
+}
+
+// PlannedFailover moves the applications to the destination: it suspends the schedule, stops the
+// applications on the source, migrates them a last time and starts them on the destination. As
+// the last migration starts after the applications stopped, no data is lost.
+func (w *Workflow) PlannedFailover() (*Report, error) {
+	r := Report{Phase: PhasePlannedFailover, Start: time.Now()}
+	if err := w.SuspendSchedule(true); err != nil {
+		return nil, fmt.Errorf("Failed to suspend migration schedule %s: %v", w.scheduleName(), err)
+	}
+	if err := w.deactivate(w.Source); err != nil {
+		return nil, err
+	}
+	down := time.Now()
+	lastSync, err := w.migrate(fmt.Sprintf("%s-failover-%s", w.Name, time.Now().Format("15h03m05s")), w.pairName())
+	if err != nil {
+		return nil, err
+	}
+	w.lastSync = lastSync
+	ready, err := w.activate(w.Destination)
+	if err != nil {
+		return nil, err
+	}
+	r.End, r.LastSync = time.Now(), lastSync
+	if down.After(lastSync) {
+		r.RPO = down.Sub(lastSync)
+	}
+	r.RTO = ready.Sub(down)
+	return w.report(r)
+}
+
+// UnplannedFailover starts the applications on the destination from the last scheduled migration
+// without touching the source, which went down at outage. It loses the data written between the
+// start of the last migration and the outage.
+func (w *Workflow) UnplannedFailover(outage time.Time) (*Report, error) {
+	if w.lastSync.IsZero() {
+		return nil, fmt.Errorf("No migration to fail over from, wait for scheduled migrations first")
+	}
+	r := Report{Phase: PhaseUnplannedFailover, Start: time.Now(), LastSync: w.lastSync}
+	ready, err := w.activate(w.Destination)
+	if err != nil {
+		return nil, err
+	}
+	r.End = time.Now()
+	r.RPO = outage.Sub(w.lastSync)
+	r.RTO = ready.Sub(outage)
+	return w.report(r)
+}
+
+// Failback moves the applications back to the source once it is up: it stops the schedule and the
+// applications on the source, which are still running after an unplanned failover, stops the
+// applications on the destination, migrates them back to the source with the reverse pair, starts
+// them on the source and resumes the schedule.
+func (w *Workflow) Failback() (*Report, error) {
+	r := Report{Phase: PhaseFailback, Start: time.Now()}
+	if err := w.SuspendSchedule(true); err != nil {
+		return nil, fmt.Errorf("Failed to suspend migration schedule %s: %v", w.scheduleName(), err)
+	}
+	if err := w.deactivate(w.Source); err != nil {
+		return nil, err
+	}
+	if err := w.deactivate(w.Destination); err != nil {
+		return nil, err
+	}
+	down := time.Now()
+	lastSync, err := w.migrate(fmt.Sprintf("%s-failback-%s", w.Name, time.Now().Format("15h03m05s")), w.reversePairName())
+	if err != nil {
+		return nil, err
+	}
+	ready, err := w.activate(w.Source)
+	if err != nil {
+		return nil, err
+	}
+	if err := SuspendMigrationSchedule(w.scheduleName(), w.Namespace, false); err != nil {
+		return nil, fmt.Errorf("Failed to resume migration schedule %s: %v", w.scheduleName(), err)
+	}
+	w.lastSync, w.resumed = time.Time{}, time.Now()
+	r.End, r.LastSync = time.Now(), lastSync
+	if down.After(lastSync) {
+		r.RPO = down.Sub(lastSync)
+	}
+	r.RTO = ready.Sub(down)
+	return w.report(r)
+}
+
+// Cleanup deletes the schedule, the schedule policy and the pairs of both clusters, and the
+// namespaces of the applications migrated to the destination
+func (w *Workflow) Cleanup() error {
+	var errs []string
+	if err := w.use(w.Source); err != nil {
+		return err
+	}
+	if err := storkops.Instance().DeleteMigrationSchedule(w.scheduleName(), w.Namespace); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := storkops.Instance().DeleteSchedulePolicy(w.policyName()); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := DeleteClusterPair(w.pairName(), w.Namespace); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := w.use(w.Destination); err != nil {
+		return err
+	}
+	if err := DeleteClusterPair(w.reversePairName(), w.Namespace); err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, ns := range w.Namespaces {
+		if err := core.Instance().DeleteNamespace(ns); err != nil && !k8serrors.IsNotFound(err) {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Failed to clean up DR workflow %s: %s", w.Name, strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package asyncdr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/apps"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	adminNamespace = "kube-system"
+	appNamespace   = "mysql"
+)
+
+// cluster is an in-memory cluster whose stork migrates deployments and statefulsets to the
+// clusters it is paired with
+type cluster struct {
+	name  string
+	stork *fakeStork
+	apps  *fakeApps
+	core  *fakeCore
+}
+
+type fakeStork struct {
+	storkops.Ops
+	cluster    *cluster
+	clusters   map[string]*cluster
+	pairs      map[string]*storkapi.ClusterPair
+	policies   map[string]*storkapi.SchedulePolicy
+	schedules  map[string]*storkapi.MigrationSchedule
+	migrations map[string]*storkapi.Migration
+}
+
+func (s *fakeStork) CreateClusterPair(pair *storkapi.ClusterPair) (*storkapi.ClusterPair, error) {
+	s.pairs[pair.Name] = pair
+	return pair, nil
+}
+
+func (s *fakeStork) ValidateClusterPair(name, namespace string, timeout, retryInterval time.Duration) error {
+	if _, ok := s.pairs[name]; !ok {
+		return fmt.Errorf("cluster pair %s not found", name)
+	}
+	return nil
+}
+
+func (s *fakeStork) DeleteClusterPair(name, namespace string) error {
+	delete(s.pairs, name)
+	return nil
+}
+
+func (s *fakeStork) CreateSchedulePolicy(policy *storkapi.SchedulePolicy) (*storkapi.SchedulePolicy, error) {
+	s.policies[policy.Name] = policy
+	return policy, nil
+}
+
+func (s *fakeStork) DeleteSchedulePolicy(name string) error {
+	delete(s.policies, name)
+	return nil
+}
+
+func (s *fakeStork) CreateMigrationSchedule(schedule *storkapi.MigrationSchedule) (*storkapi.MigrationSchedule, error) {
+	s.schedules[schedule.Name] = schedule
+	return schedule, nil
+}
+
+func (s *fakeStork) GetMigrationSchedule(name, namespace string) (*storkapi.MigrationSchedule, error) {
+	if schedule, ok := s.schedules[name]; ok {
+		return schedule, nil
+	}
+	return nil, fmt.Errorf("migration schedule %s not found", name)
+}
+
+func (s *fakeStork) UpdateMigrationSchedule(schedule *storkapi.MigrationSchedule) (*storkapi.MigrationSchedule, error) {
+	s.schedules[schedule.Name] = schedule
+	return schedule, nil
+}
+
+func (s *fakeStork) DeleteMigrationSchedule(name, namespace string) error {
+	delete(s.schedules, name)
+	return nil
+}
+
+func (s *fakeStork) CreateMigration(migration *storkapi.Migration) (*storkapi.Migration, error) {
+	migration.CreationTimestamp = meta_v1.Now()
+	migration.Status.Status = storkapi.MigrationStatusSuccessful
+	if err := s.migrate(migration.Spec.ClusterPair, migration.Spec.Namespaces); err != nil {
+		migration.Status.Status = storkapi.MigrationStatusFailed
+	}
+	s.migrations[migration.Name] = migration
+	return migration, nil
+}
+
+func (s *fakeStork) GetMigration(name, namespace string) (*storkapi.Migration, error) {
+	if migration, ok := s.migrations[name]; ok {
+		return migration, nil
+	}
+	return nil, fmt.Errorf("migration %s not found", name)
+}
+
+// migrate copies the applications of namespaces to the cluster of a pair without starting them,
+// recording their replicas like stork does
+func (s *fakeStork) migrate(pairName string, namespaces []string) error {
+	pair, ok := s.pairs[pairName]
+	if !ok {
+		return fmt.Errorf("cluster pair %s not found", pairName)
+	}
+	remote := s.clusters[pair.Spec.Config.CurrentContext]
+	for _, ns := range namespaces {
+		for _, d := range s.cluster.apps.deployments[ns] {
+			migrated := d.DeepCopy()
+			migrated.Annotations = map[string]string{migrationReplicasAnnotation: strconv.Itoa(int(*d.Spec.Replicas))}
+			zero := int32(0)
+			migrated.Spec.Replicas = &zero
+			remote.apps.add(migrated)
+		}
+	}
+	return nil
+}
+
+// tick runs the schedule of the cluster once, unless it is suspended
+func (s *fakeStork) tick(t *testing.T, name string) {
+	schedule := s.schedules[name]
+	if schedule.Spec.Suspend != nil && *schedule.Spec.Suspend {
+		return
+	}
+	if schedule.Status.Items == nil {
+		schedule.Status.Items = make(map[storkapi.SchedulePolicyType][]*storkapi.ScheduledMigrationStatus)
+	}
+	items := schedule.Status.Items[storkapi.SchedulePolicyTypeInterval]
+	require.NoError(t, s.migrate(schedule.Spec.Template.Spec.ClusterPair, schedule.Spec.Template.Spec.Namespaces))
+	schedule.Status.Items[storkapi.SchedulePolicyTypeInterval] = append(items, &storkapi.ScheduledMigrationStatus{
+		Name:              fmt.Sprintf("%s-interval-%d", name, len(items)),
+		CreationTimestamp: meta_v1.Now(),
+		Status:            storkapi.MigrationStatusSuccessful,
+	})
+}
+
+type fakeCore struct {
+	core.Ops
+	deletedNamespaces []string
+}
+
+func (c *fakeCore) DeleteNamespace(name string) error {
+	c.deletedNamespaces = append(c.deletedNamespaces, name)
+	return nil
+}
+
+type fakeApps struct {
+	apps.Ops
+	deployments map[string]map[string]*appsv1.Deployment
+}
+
+func (a *fakeApps) add(d *appsv1.Deployment) {
+	if a.deployments[d.Namespace] == nil {
+		a.deployments[d.Namespace] = make(map[string]*appsv1.Deployment)
+	}
+	a.deployments[d.Namespace][d.Name] = d
+}
+
+func (a *fakeApps) replicas(name string) int32 {
+	return *a.deployments[appNamespace][name].Spec.Replicas
+}
+
+func (a *fakeApps) ListDeployments(namespace string, options meta_v1.ListOptions) (*appsv1.DeploymentList, error) {
+	list := &appsv1.DeploymentList{}
+	for _, d := range a.deployments[namespace] {
+		list.Items = append(list.Items, *d.DeepCopy())
+	}
+	return list, nil
+}
+
+func (a *fakeApps) UpdateDeployment(d *appsv1.Deployment) (*appsv1.Deployment, error) {
+	a.add(d.DeepCopy())
+	return d, nil
+}
+
+func (a *fakeApps) ValidateDeployment(d *appsv1.Deployment, timeout, retryInterval time.Duration) error {
+	return nil
+}
+
+func (a *fakeApps) ListStatefulSets(namespace string, options meta_v1.ListOptions) (*appsv1.StatefulSetList, error) {
+	return &appsv1.StatefulSetList{}, nil
+}
+
+func writeKubeconfig(t *testing.T, name string) string {
+	path := filepath.Join(t.TempDir(), name)
+	config := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[1]s:6443
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: admin
+current-context: %[1]s
+users:
+- name: admin
+  user:
+    token: secret
+`, name)
+	require.NoError(t, os.WriteFile(path, []byte(config), 0644))
+	return path
+}
+
+func TestWorkflow(t *testing.T) {
+	clusters := make(map[string]*cluster)
+	for _, name := range []string{"source", "destination"} {
+		c := &cluster{name: name, apps: &fakeApps{deployments: make(map[string]map[string]*appsv1.Deployment)}, core: &fakeCore{}}
+		c.stork = &fakeStork{
+			cluster:    c,
+			clusters:   clusters,
+			pairs:      make(map[string]*storkapi.ClusterPair),
+			policies:   make(map[string]*storkapi.SchedulePolicy),
+			schedules:  make(map[string]*storkapi.MigrationSchedule),
+			migrations: make(map[string]*storkapi.Migration),
+		}
+		clusters[name] = c
+	}
+	source, destination := clusters["source"], clusters["destination"]
+	three := int32(3)
+	source.apps.add(&appsv1.Deployment{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "mysql", Namespace: appNamespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &three},
+	})
+
+	prevStork, prevApps, prevCore := storkops.Instance(), apps.Instance(), core.Instance()
+	t.Cleanup(func() {
+		storkops.SetInstance(prevStork)
+		apps.SetInstance(prevApps)
+		core.SetInstance(prevCore)
+	})
+	down := make(map[string]bool)
+	var pairings []string
+	w := &Workflow{
+		Source:      Cluster{Name: "source", Kubeconfig: writeKubeconfig(t, "source")},
+		Destination: Cluster{Name: "destination", Kubeconfig: writeKubeconfig(t, "destination")},
+		Namespaces:  []string{appNamespace},
+		Namespace:   adminNamespace,
+		Name:        "dr",
+		Objectives:  Objectives{RPO: time.Hour, RTO: time.Hour},
+		Use: func(c Cluster) error {
+			if down[c.Name] {
+				return fmt.Errorf("cluster %s is down", c.Name)
+			}
+			storkops.SetInstance(clusters[c.Name].stork)
+			apps.SetInstance(clusters[c.Name].apps)
+			core.SetInstance(clusters[c.Name].core)
+			return nil
+		},
+		PairingInfo: func(remote Cluster, reverse bool) (map[string]string, error) {
+			pairings = append(pairings, fmt.Sprintf("%s:%v", remote.Name, reverse))
+			return map[string]string{"ip": remote.Name}, nil
+		},
+		Timeout:       time.Second,
+		RetryInterval: time.Millisecond,
+	}
+
+	_, err := w.UnplannedFailover(time.Now())
+	assert.Error(t, err, "no migration to fail over from")
+
+	// pairing
+	_, err = w.Pair()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"destination:false", "source:true"}, pairings)
+	require.Contains(t, source.stork.pairs, "dr-pair")
+	assert.Equal(t, "destination", source.stork.pairs["dr-pair"].Spec.Config.CurrentContext)
+	assert.Equal(t, map[string]string{"ip": "destination"}, source.stork.pairs["dr-pair"].Spec.Options)
+	require.Contains(t, destination.stork.pairs, "dr-reverse-pair")
+	assert.Equal(t, "source", destination.stork.pairs["dr-reverse-pair"].Spec.Config.CurrentContext)
+
+	// scheduled migrations, suspended and resumed
+	require.NoError(t, w.StartSchedule(15))
+	source.stork.tick(t, "dr-schedule")
+	time.Sleep(20 * time.Millisecond)
+	source.stork.tick(t, "dr-schedule")
+	r, err := w.WaitForScheduledMigrations(2)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, r.RPO, 20*time.Millisecond)
+	assert.Equal(t, int32(0), destination.apps.replicas("mysql"))
+	assert.Equal(t, "3", destination.apps.deployments[appNamespace]["mysql"].Annotations[migrationReplicasAnnotation])
+
+	require.NoError(t, w.SuspendSchedule(true))
+	source.stork.tick(t, "dr-schedule")
+	require.NoError(t, w.VerifyScheduleSuspended(time.Millisecond))
+	require.NoError(t, w.SuspendSchedule(false))
+	source.stork.tick(t, "dr-schedule")
+	_, err = w.WaitForScheduledMigrations(3)
+	require.NoError(t, err)
+
+	// planned failover and failback, whose last migrations record 0 replicas
+	r, err = w.PlannedFailover()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), r.RPO)
+	assert.Equal(t, int32(0), source.apps.replicas("mysql"))
+	assert.Equal(t, int32(3), destination.apps.replicas("mysql"))
+	assert.Equal(t, "0", destination.apps.deployments[appNamespace]["mysql"].Annotations[migrationReplicasAnnotation])
+	assert.True(t, *source.stork.schedules["dr-schedule"].Spec.Suspend)
+
+	r, err = w.Failback()
+	require.NoError(t, err)
+	assert.Equal(t, PhaseFailback, r.Phase)
+	assert.Equal(t, int32(3), source.apps.replicas("mysql"))
+	assert.Equal(t, int32(0), destination.apps.replicas("mysql"))
+	assert.False(t, *source.stork.schedules["dr-schedule"].Spec.Suspend)
+
+	// unplanned failover with the source down, missing its RPO, and failback once it is up. Only
+	// the migration started after the failback counts.
+	_, err = w.WaitForScheduledMigrations(1)
+	assert.Error(t, err)
+	source.stork.tick(t, "dr-schedule")
+	_, err = w.WaitForScheduledMigrations(1)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	outage := time.Now()
+	down["source"] = true
+	w.Objectives = Objectives{RPO: 5 * time.Millisecond}
+	r, err = w.UnplannedFailover(outage)
+	require.Error(t, err)
+	missed, ok := err.(*ErrObjectivesMissed)
+	require.True(t, ok)
+	assert.Equal(t, PhaseUnplannedFailover, missed.Report.Phase)
+	assert.GreaterOrEqual(t, r.RPO, 10*time.Millisecond)
+	assert.Equal(t, int32(3), destination.apps.replicas("mysql"))
+	assert.Equal(t, int32(3), source.apps.replicas("mysql"), "the source is down with its applications")
+
+	_, err = w.Failback()
+	require.Error(t, err)
+	down["source"] = false
+	w.Objectives = Objectives{RPO: time.Hour, RTO: time.Hour}
+	_, err = w.Failback()
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), source.apps.replicas("mysql"))
+	assert.Equal(t, int32(0), destination.apps.replicas("mysql"))
+
+	var phases []string
+	for _, report := range w.Reports {
+		phases = append(phases, report.Phase)
+	}
+	assert.Equal(t, []string{PhasePair, PhaseSchedule, PhaseSchedule, PhasePlannedFailover, PhaseFailback, PhaseSchedule,
+		PhaseUnplannedFailover, PhaseFailback}, phases)
+
+	require.NoError(t, w.Cleanup())
+	assert.Empty(t, source.stork.pairs)
+	assert.Empty(t, source.stork.schedules)
+	assert.Empty(t, destination.stork.pairs)
+	assert.Equal(t, []string{appNamespace}, destination.core.deletedNamespaces)
+	assert.Empty(t, source.core.deletedNamespaces)
+}
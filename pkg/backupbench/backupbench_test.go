@@ -0,0 +1,193 @@
+package backupbench
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb/pxbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const orgID = fakepxb.DefaultOrg
+
+// core records the objects created by the generator
+type core struct {
+	namespaces []string
+	pvcs       []*corev1.PersistentVolumeClaim
+	configMaps []*corev1.ConfigMap
+	pods       []*corev1.Pod
+	deleted    []string
+}
+
+func (c *core) CreateNamespace(namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	c.namespaces = append(c.namespaces, namespace.Name)
+	return namespace, nil
+}
+
+func (c *core) DeleteNamespace(name string) error {
+	c.deleted = append(c.deleted, name)
+	return nil
+}
+
+func (c *core) CreatePersistentVolumeClaim(pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	c.pvcs = append(c.pvcs, pvc)
+	return pvc, nil
+}
+
+func (c *core) CreateConfigMap(configMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	c.configMaps = append(c.configMaps, configMap)
+	return configMap, nil
+}
+
+func (c *core) CreatePod(pod *corev1.Pod) (*corev1.Pod, error) {
+	c.pods = append(c.pods, pod)
+	return pod, nil
+}
+
+func (c *core) GetPodByName(podName string, namespace string) (*corev1.Pod, error) {
+	return &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}}, nil
+}
+
+func (c *core) DeletePod(name string, ns string, force bool) error {
+	return nil
+}
+
+type sampler struct {
+	load float64
+}
+
+func (s *sampler) Sample() (Usage, error) {
+	s.load++
+	return Usage{Load: s.load, MemoryUsed: 1 << 30}, nil
+}
+
+func TestGenerate(t *testing.T) {
+	d := &DataSet{Name: "small", Namespaces: 2, VolumesPerNamespace: 2, VolumeSize: "1Gi", FilesPerVolume: 3, FileSize: 64 << 20, ResourcesPerNamespace: 5}
+	assert.Equal(t, int64(768<<20), d.Bytes())
+	c := &core{}
+	g := &Generator{Core: c, Timeout: time.Second, RetryInterval: time.Millisecond}
+	namespaces, err := g.Generate(d)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"small-0", "small-1"}, namespaces)
+	assert.Len(t, c.pvcs, 4)
+	assert.Len(t, c.configMaps, 10)
+	require.Len(t, c.pods, 2)
+	script := c.pods[0].Spec.Containers[0].Command[2]
+	assert.Contains(t, script, "for v in /data-0 /data-1; do")
+	assert.Contains(t, script, "bs=1048576 count=64")
+	require.NoError(t, g.Delete(namespaces))
+	assert.Equal(t, namespaces, c.deleted)
+
+	tooBig := *d
+	tooBig.FileSize = 1 << 30
+	_, err = g.Generate(&tooBig)
+	assert.Error(t, err)
+}
+
+func TestParseUsage(t *testing.T) {
+	usage, err := parseUsage("1.50 1.20 0.90 2/345 6789\nMemTotal:       16384000 kB\nMemAvailable:    8192000 kB\n")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Load: 1.5, MemoryUsed: 8192000 * 1024}, usage)
+	_, err = parseUsage("garbage")
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	driver, _ := pxbtest.NewDriver(t, fakepxb.Config{BackupTimeline: []fakepxb.BackupPhase{
+		{Status: api.BackupInfo_StatusInfo_Pending, Stage: api.BackupInfo_Initial},
+		{After: 30 * time.Millisecond, Status: api.BackupInfo_StatusInfo_InProgress, Stage: api.BackupInfo_Volumes},
+		{After: 80 * time.Millisecond, Status: api.BackupInfo_StatusInfo_Success, Stage: api.BackupInfo_Final},
+	}})
+	ctx := backup.GetCtxWithToken(fakepxb.DefaultAdminUser)
+	_, err := driver.CreateCluster(ctx, &api.ClusterCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: "source-cluster", OrgId: orgID},
+		Kubeconfig:     "kubeconfig",
+	})
+	require.NoError(t, err)
+	_, err = driver.CreateBackupLocation(ctx, &api.BackupLocationCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: "s3-location", OrgId: orgID},
+		BackupLocation: &api.BackupLocationInfo{Type: api.BackupLocationInfo_S3, Path: "bucket"},
+	})
+	require.NoError(t, err)
+
+	var cleaned [][]string
+	r := &Runner{
+		Driver:       driver,
+		Env:          Env{OrgID: orgID, Cluster: "source-cluster", BackupLocation: "s3-location", RestoreCluster: "source-cluster"},
+		Iterations:   2,
+		Timeout:      5 * time.Second,
+		PollInterval: 5 * time.Millisecond,
+		Sampler:      &sampler{},
+		Cleanup: func(namespaces []string) error {
+			cleaned = append(cleaned, namespaces)
+			return nil
+		},
+	}
+	d := &DataSet{Name: "tiny", Namespaces: 1, VolumesPerNamespace: 1, VolumeSize: "1Gi", FilesPerVolume: 1, FileSize: 1 << 20}
+	result, err := r.Run(ctx, d, []string{"tiny-0"})
+	require.NoError(t, err)
+	assert.Equal(t, "2.4.0", result.Version)
+	require.Len(t, result.Backups, 2)
+	require.Len(t, result.Restores, 2)
+	assert.Equal(t, [][]string{{"tiny-0-r0"}, {"tiny-0-r1"}}, cleaned)
+
+	b := result.Backups[1]
+	assert.Equal(t, 1, b.Iteration)
+	assert.Equal(t, uint64(1<<20), b.Bytes)
+	assert.Greater(t, b.Throughput, 0.0)
+	assert.GreaterOrEqual(t, b.Seconds, 0.08)
+	assert.Contains(t, b.Phases, "Initial")
+	assert.Contains(t, b.Phases, "Volumes")
+	require.NotNil(t, b.Usage)
+	assert.Greater(t, b.Usage.Peak.Load, b.Usage.Average.Load)
+	assert.Contains(t, result.Restores[0].Phases, "Pending")
+
+	// backups and restores of each iteration are deleted
+	resp, err := driver.EnumerateRestore(ctx, &api.RestoreEnumerateRequest{OrgId: orgID})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetRestores())
+}
+
+func TestBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	b, err := LoadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultThresholds, b.Thresholds)
+
+	result := &Result{DataSet: DataSet{Name: "large"}, Version: "2.4.0"}
+	for i, seconds := range []float64{100, 120, 110} {
+		result.Backups = append(result.Backups, Measurement{Iteration: i, Seconds: seconds, Throughput: 1000 / seconds,
+			Phases: map[string]float64{"Volumes": seconds - 40, "Initial": 5}, Usage: &UsageStats{Peak: Usage{Load: 4, MemoryUsed: 1 << 30}}})
+		result.Restores = append(result.Restores, Measurement{Iteration: i, Seconds: 60, Throughput: 1000 / 60.0})
+	}
+	summary := Summarize(result)
+	assert.Equal(t, 110.0, summary.Backup.Seconds)
+	assert.Equal(t, 70.0, summary.Backup.Phases["Volumes"])
+	assert.Equal(t, 3, summary.Iterations)
+	assert.Empty(t, b.Compare(summary))
+	b.Update(summary)
+	require.NoError(t, b.Save(path))
+
+	loaded, err := LoadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, summary, loaded.Summaries["large"])
+
+	// a release whose backups are slower
+	slower := summary
+	slower.Backup = OperationSummary{Seconds: 150, Throughput: 1000 / 150.0, Phases: map[string]float64{"Volumes": 110, "Initial": 25}, PeakLoad: 4, PeakMemoryUsed: 1 << 30}
+	regressions := loaded.Compare(slower)
+	var metrics []string
+	for _, r := range regressions {
+		metrics = append(metrics, r.Metric)
+	}
+	assert.Equal(t, []string{"backup seconds", "backup throughput", "backup phase Volumes seconds"}, metrics)
+	err = &ErrRegression{Regressions: regressions}
+	assert.True(t, strings.HasPrefix(err.Error(), "3 benchmark metrics regressed:\nlarge backup seconds: 110.00 -> 150.00 (+36%)"), err.Error())
+}
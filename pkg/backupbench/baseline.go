@@ -0,0 +1,204 @@
+package backupbench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Thresholds are the regressions tolerated against a baseline, as fractions of the baseline
+type Thresholds struct {
+	// Duration is the tolerated increase of the duration of backups and restores
+	Duration float64 `json:"duration"`
+	// Throughput is the tolerated decrease of the throughput of backups and restores
+	Throughput float64 `json:"throughput"`
+	// Phase is the tolerated increase of the duration of each phase
+	Phase float64 `json:"phase"`
+	// Usage is the tolerated increase of the peak usage of the cluster
+	Usage float64 `json:"usage"`
+	// MinSeconds is the duration under which durations are not compared, as they are noise
+	MinSeconds float64 `json:"minSeconds"`
+}
+
+// DefaultThresholds are the thresholds of new baselines
+var DefaultThresholds = Thresholds{Duration: 0.2, Throughput: 0.2, Phase: 0.5, Usage: 0.3, MinSeconds: 30}
+
+// OperationSummary is the median of the measurements of the backups or restores of a data set
+type OperationSummary struct {
+	Seconds    float64            `json:"seconds"`
+	Throughput float64            `json:"throughput"`
+	Phases     map[string]float64 `json:"phases"`
+	// PeakLoad and PeakMemoryUsed are the medians of the peak usage of the cluster
+	PeakLoad       float64 `json:"peakLoad,omitempty"`
+	PeakMemoryUsed uint64  `json:"peakMemoryUsed,omitempty"`
+}
+
+// Summary is the summary of the result of a data set
+type Summary struct {
+	DataSet    DataSet          `json:"dataSet"`
+	Version    string           `json:"version"`
+	Iterations int              `json:"iterations"`
+	Backup     OperationSummary `json:"backup"`
+	Restore    OperationSummary `json:"restore"`
+}
+
+// Summarize returns the medians of the measurements of a result
+func Summarize(r *Result) Summary {
+	return Summary{
+		DataSet:    r.DataSet,
+		Version:    r.Version,
+		Iterations: len(r.Backups),
+		Backup:     summarize(r.Backups),
+		Restore:    summarize(r.Restores),
+	}
+}
+
+func summarize(measurements []Measurement) OperationSummary {
+	var seconds, throughputs, loads, memory []float64
+	phases := make(map[string][]float64)
+	for _, m := range measurements {
+		seconds = append(seconds, m.Seconds)
+		throughputs = append(throughputs, m.Throughput)
+		for phase, s := range m.Phases {
+			phases[phase] = append(phases[phase], s)
+		}
+		if m.Usage != nil {
+			loads = append(loads, m.Usage.Peak.Load)
+			memory = append(memory, float64(m.Usage.Peak.MemoryUsed))
+		}
+	}
+	s := OperationSummary{
+		Seconds:        median(seconds),
+		Throughput:     median(throughputs),
+		Phases:         make(map[string]float64),
+		PeakLoad:       median(loads),
+		PeakMemoryUsed: uint64(median(memory)),
+	}
+	for phase, values := range phases {
+		s.Phases[phase] = median(values)
+	}
+	return s
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	if n := len(sorted); n%2 == 0 {
+		return (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return sorted[len(sorted)/2]
+}
+
+// Regression is a metric of a data set which regressed past its threshold
+type Regression struct {
+	DataSet  string  `json:"dataSet"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	// Change is the relative change of the metric from the baseline
+	Change float64 `json:"change"`
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s %s: %.2f -> %.2f (%+.0f%%)", r.DataSet, r.Metric, r.Baseline, r.Current, r.Change*100)
+}
+
+// ErrRegression is returned when a benchmark regressed against its baseline
+type ErrRegression struct {
+	Regressions []Regression
+}
+
+func (e *ErrRegression) Error() string {
+	msgs := make([]string, 0, len(e.Regressions))
+	for _, r := range e.Regressions {
+		msgs = append(msgs, r.String())
+	}
+	return fmt.Sprintf("%d benchmark metrics regressed:\n%s", len(e.Regressions), strings.Join(msgs, "\n"))
+}
+
+// Baseline is the summaries of data sets benchmarked with a release of PX-Backup, by data set
+type Baseline struct {
+	Thresholds Thresholds         `json:"thresholds"`
+	Summaries  map[string]Summary `json:"summaries"`
+}
+
+// LoadBaseline loads a baseline from a JSON file. It returns an empty baseline with the default
+// thresholds if the file does not exist.
+func LoadBaseline(path string) (*Baseline, error) {
+	b := &Baseline{Thresholds: DefaultThresholds, Summaries: make(map[string]Summary)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %v", path, err)
+	}
+	if b.Summaries == nil {
+		b.Summaries = make(map[string]Summary)
+	}
+	return b, nil
+}
+
+// Save writes the baseline to a JSON file
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Update records the summary of a data set in the baseline
+func (b *Baseline) Update(s Summary) {
+	b.Summaries[s.DataSet.Name] = s
+}
+
+// Compare returns the regressions of the summary of a data set against the baseline, nil if the
+// baseline has no summary of the data set
+func (b *Baseline) Compare(s Summary) []Regression {
+	base, ok := b.Summaries[s.DataSet.Name]
+	if !ok {
+		return nil
+	}
+	var regressions []Regression
+	for _, op := range []struct {
+		name          string
+		base, current OperationSummary
+	}{{"backup", base.Backup, s.Backup}, {"restore", base.Restore, s.Restore}} {
+		check := func(metric string, baseline, current, threshold float64, lowerIsWorse bool) {
+			if baseline <= 0 {
+				return
+			}
+			change := (current - baseline) / baseline
+			if (lowerIsWorse && -change > threshold) || (!lowerIsWorse && change > threshold) {
+				regressions = append(regressions, Regression{DataSet: s.DataSet.Name, Metric: op.name + " " + metric, Baseline: baseline, Current: current, Change: change})
+			}
+		}
+		if op.base.Seconds >= b.Thresholds.MinSeconds {
+			check("seconds", op.base.Seconds, op.current.Seconds, b.Thresholds.Duration, false)
+			check("throughput", op.base.Throughput, op.current.Throughput, b.Thresholds.Throughput, true)
+		}
+		phases := make([]string, 0, len(op.base.Phases))
+		for phase := range op.base.Phases {
+			phases = append(phases, phase)
+		}
+		sort.Strings(phases)
+		for _, phase := range phases {
+			if seconds := op.base.Phases[phase]; seconds >= b.Thresholds.MinSeconds {
+				check("phase "+phase+" seconds", seconds, op.current.Phases[phase], b.Thresholds.Phase, false)
+			}
+		}
+		check("peak load", op.base.PeakLoad, op.current.PeakLoad, b.Thresholds.Usage, false)
+		check("peak memory used", float64(op.base.PeakMemoryUsed), float64(op.current.PeakMemoryUsed), b.Thresholds.Usage, false)
+	}
+	return regressions
+}
@@ -0,0 +1,274 @@
+package backupbench
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/pkg/log"
+)
+
+const (
+	defaultIterations   = 3
+	defaultTimeout      = time.Hour
+	defaultPollInterval = 10 * time.Second
+	mib                 = 1 << 20
+)
+
+// Env is where data sets are backed up from and restored to
+type Env struct {
+	OrgID             string
+	Cluster           string
+	ClusterUID        string
+	BackupLocation    string
+	BackupLocationUID string
+	// RestoreCluster is the cluster backups are restored to, which may be the backed up cluster
+	// as restores go to new namespaces
+	RestoreCluster string
+}
+
+// Measurement is the measurement of a backup or restore
+type Measurement struct {
+	Name      string  `json:"name"`
+	Iteration int     `json:"iteration"`
+	Seconds   float64 `json:"seconds"`
+	Bytes     uint64  `json:"bytes"`
+	Resources uint64  `json:"resources"`
+	// Throughput is in MiB per second
+	Throughput float64 `json:"throughput"`
+	// Phases are the seconds spent in each stage of backups or status of restores, as seen by
+	// polling them
+	Phases map[string]float64 `json:"phases"`
+	Usage  *UsageStats        `json:"usage,omitempty"`
+}
+
+// Result is the measurements of the backups and restores of a data set
+type Result struct {
+	DataSet DataSet `json:"dataSet"`
+	// Version of PX-Backup
+	Version  string        `json:"version"`
+	Time     time.Time     `json:"time"`
+	Backups  []Measurement `json:"backups"`
+	Restores []Measurement `json:"restores"`
+}
+
+// Runner backs up and restores the namespaces of data sets repeatedly and measures them
+type Runner struct {
+	Driver backup.Driver
+	Env    Env
+	// Iterations is the number of backups and restores of each data set, 3 if 0
+	Iterations int
+	// Timeout bounds each backup and restore, an hour if 0
+	Timeout time.Duration
+	// PollInterval is the interval at which backups and restores are inspected and the usage of
+	// the cluster sampled, 10 seconds if 0. Phase durations are as precise as the interval.
+	PollInterval time.Duration
+	// Sampler samples the usage of the cluster, if set
+	Sampler Sampler
+	// Cleanup deletes the namespaces of a restore before the next iteration, if set
+	Cleanup func(namespaces []string) error
+}
+
+// phases tracks the phases of a backup or restore as they are polled
+type phases struct {
+	order []string
+	start map[string]time.Time
+}
+
+func (p *phases) observe(phase string, at time.Time) {
+	if p.start == nil {
+		p.start = make(map[string]time.Time)
+	}
+	if _, ok := p.start[phase]; !ok {
+		p.start[phase] = at
+		p.order = append(p.order, phase)
+	}
+}
+
+// seconds returns the seconds spent in each phase but the last one, which ended the poll
+func (p *phases) seconds() map[string]float64 {
+	durations := make(map[string]float64)
+	for i := 0; i+1 < len(p.order); i++ {
+		durations[p.order[i]] = p.start[p.order[i+1]].Sub(p.start[p.order[i]]).Seconds()
+	}
+	return durations
+}
+
+// Run backs up and restores the namespaces of the data set, which Generate returned, and
+// returns the measurements. Backups and restores are deleted after each iteration.
+func (r *Runner) Run(ctx context.Context, d *DataSet, namespaces []string) (*Result, error) {
+	result := &Result{DataSet: *d, Time: time.Now()}
+	if resp, err := r.Driver.GetPxBackupVersion(ctx, &api.VersionGetRequest{}); err != nil {
+		log.Warnf("failed to get version of px-backup: %v", err)
+	} else {
+		v := resp.GetVersion()
+		result.Version = fmt.Sprintf("%s.%s.%s", v.GetMajor(), v.GetMinor(), v.GetPatch())
+	}
+	iterations := r.Iterations
+	if iterations == 0 {
+		iterations = defaultIterations
+	}
+	for i := 0; i < iterations; i++ {
+		name := fmt.Sprintf("%s-bench-%d-%d", d.Name, result.Time.Unix(), i)
+		log.InfoD("Benchmarking backup %s of data set %s, iteration %d of %d", name, d.Name, i+1, iterations)
+		backupMeasurement, backupUID, err := r.backup(ctx, name, namespaces, d)
+		if err != nil {
+			return result, err
+		}
+		backupMeasurement.Iteration = i
+		result.Backups = append(result.Backups, *backupMeasurement)
+
+		mapping := make(map[string]string)
+		var restored []string
+		for _, ns := range namespaces {
+			mapping[ns] = fmt.Sprintf("%s-r%d", ns, i)
+			restored = append(restored, mapping[ns])
+		}
+		restoreMeasurement, err := r.restore(ctx, name, backupUID, mapping, backupMeasurement.Bytes)
+		if err != nil {
+			return result, err
+		}
+		restoreMeasurement.Iteration = i
+		result.Restores = append(result.Restores, *restoreMeasurement)
+		log.Infof("Backup %s took %.1fs at %.1f MiB/s, restore took %.1fs at %.1f MiB/s", name,
+			backupMeasurement.Seconds, backupMeasurement.Throughput, restoreMeasurement.Seconds, restoreMeasurement.Throughput)
+
+		if _, err := r.Driver.DeleteRestore(ctx, &api.RestoreDeleteRequest{OrgId: r.Env.OrgID, Name: name}); err != nil {
+			log.Warnf("failed to delete restore %s: %v", name, err)
+		}
+		if _, err := r.Driver.DeleteBackup(ctx, &api.BackupDeleteRequest{OrgId: r.Env.OrgID, Name: name, Uid: backupUID}); err != nil {
+			log.Warnf("failed to delete backup %s: %v", name, err)
+		}
+		if r.Cleanup != nil {
+			if err := r.Cleanup(restored); err != nil {
+				return result, fmt.Errorf("failed to clean up restore %s: %v", name, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *Runner) backup(ctx context.Context, name string, namespaces []string, d *DataSet) (*Measurement, string, error) {
+	start := time.Now()
+	_, err := r.Driver.CreateBackup(ctx, &api.BackupCreateRequest{
+		CreateMetadata:    &api.CreateMetadata{Name: name, OrgId: r.Env.OrgID},
+		BackupLocationRef: &api.ObjectRef{Name: r.Env.BackupLocation, Uid: r.Env.BackupLocationUID},
+		Cluster:           r.Env.Cluster,
+		ClusterRef:        &api.ObjectRef{Name: r.Env.Cluster, Uid: r.Env.ClusterUID},
+		Namespaces:        namespaces,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create backup %s: %v", name, err)
+	}
+	var info *api.BackupObject
+	m, err := r.poll(name, start, func() (string, bool, error) {
+		resp, err := r.Driver.InspectBackup(ctx, &api.BackupInspectRequest{OrgId: r.Env.OrgID, Name: name})
+		if err != nil {
+			return "", false, err
+		}
+		info = resp.GetBackup()
+		status := info.GetStatus().GetStatus()
+		switch status {
+		case api.BackupInfo_StatusInfo_Success, api.BackupInfo_StatusInfo_PartialSuccess:
+			return status.String(), true, nil
+		case api.BackupInfo_StatusInfo_Failed, api.BackupInfo_StatusInfo_Aborted:
+			return "", false, fmt.Errorf("backup %s is %s: %s", name, status, info.GetStatus().GetReason())
+		}
+		if info.GetStage() != api.BackupInfo_Invalid {
+			return info.GetStage().String(), false, nil
+		}
+		return status.String(), false, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	m.Bytes, m.Resources = info.GetTotalSize(), info.GetResourceCount()
+	if m.Bytes == 0 {
+		m.Bytes = uint64(d.Bytes())
+	}
+	m.Throughput = throughput(m.Bytes, m.Seconds)
+	return m, info.GetUid(), nil
+}
+
+func (r *Runner) restore(ctx context.Context, name, backupUID string, mapping map[string]string, backupBytes uint64) (*Measurement, error) {
+	start := time.Now()
+	_, err := r.Driver.CreateRestore(ctx, &api.RestoreCreateRequest{
+		CreateMetadata:   &api.CreateMetadata{Name: name, OrgId: r.Env.OrgID},
+		BackupRef:        &api.ObjectRef{Name: name, Uid: backupUID},
+		Cluster:          r.Env.RestoreCluster,
+		NamespaceMapping: mapping,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restore %s: %v", name, err)
+	}
+	var info *api.RestoreObject
+	m, err := r.poll(name, start, func() (string, bool, error) {
+		resp, err := r.Driver.InspectRestore(ctx, &api.RestoreInspectRequest{OrgId: r.Env.OrgID, Name: name})
+		if err != nil {
+			return "", false, err
+		}
+		info = resp.GetRestore()
+		status := info.GetStatus().GetStatus()
+		switch status {
+		case api.RestoreInfo_StatusInfo_Success, api.RestoreInfo_StatusInfo_PartialSuccess:
+			return status.String(), true, nil
+		case api.RestoreInfo_StatusInfo_Failed, api.RestoreInfo_StatusInfo_Aborted:
+			return "", false, fmt.Errorf("restore %s is %s: %s", name, status, info.GetStatus().GetReason())
+		}
+		return status.String(), false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.Bytes, m.Resources = info.GetTotalSize(), info.GetResourceCount()
+	if m.Bytes == 0 {
+		m.Bytes = backupBytes
+	}
+	m.Throughput = throughput(m.Bytes, m.Seconds)
+	return m, nil
+}
+
+// poll inspects a backup or restore until it is done, tracking its phases and sampling the usage
+// of the cluster. inspect returns the current phase and whether it is done.
+func (r *Runner) poll(name string, start time.Time, inspect func() (string, bool, error)) (*Measurement, error) {
+	timeout, interval := r.Timeout, r.PollInterval
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	var p phases
+	var usage usageStats
+	for {
+		phase, done, err := inspect()
+		now := time.Now()
+		if err != nil {
+			return nil, err
+		}
+		p.observe(phase, now)
+		if done {
+			return &Measurement{Name: name, Seconds: now.Sub(start).Seconds(), Phases: p.seconds(), Usage: usage.stats()}, nil
+		}
+		if now.Sub(start) > timeout {
+			return nil, fmt.Errorf("%s did not complete in %v, last phase %s", name, timeout, phase)
+		}
+		if r.Sampler != nil {
+			if sample, err := r.Sampler.Sample(); err != nil {
+				log.Warnf("failed to sample usage of cluster: %v", err)
+			} else {
+				usage.add(sample)
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
+func throughput(bytes uint64, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return float64(bytes) / mib / seconds
+}
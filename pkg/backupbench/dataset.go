@@ -0,0 +1,247 @@
+// Package backupbench benchmarks backups and restores of PX-Backup. It generates data sets of
+// namespaces with volumes full of files and resources, backs them up and restores them
+// repeatedly, and measures the duration, throughput and stage durations of each backup and
+// restore along with the resource usage of the cluster. Results are summarized and compared
+// against a JSON baseline with regression thresholds, so that a PX-Backup release which is
+// slower than the previous one fails instead of only passing later.
+package backupbench
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/portworx/sched-ops/task"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultImage           = "busybox"
+	defaultGenerateTimeout = 30 * time.Minute
+	defaultRetryInterval   = 10 * time.Second
+	writerPod              = "data-writer"
+	// dataSetLabel labels the namespaces of data sets with the name of their data set
+	dataSetLabel = "backupbench/dataset"
+)
+
+// DataSet describes the data backed up by a benchmark
+type DataSet struct {
+	// Name of the data set, which prefixes its namespaces and names its results in baselines
+	Name string `json:"name"`
+	// Namespaces is the number of namespaces of the data set
+	Namespaces int `json:"namespaces"`
+	// VolumesPerNamespace is the number of persistent volume claims of each namespace
+	VolumesPerNamespace int `json:"volumesPerNamespace"`
+	// VolumeSize is the size of each volume, such as 10Gi
+	VolumeSize string `json:"volumeSize"`
+	// FilesPerVolume is the number of files of random data written to each volume
+	FilesPerVolume int `json:"filesPerVolume"`
+	// FileSize is the size of each file in bytes, a multiple of 1MiB for speed
+	FileSize int64 `json:"fileSize"`
+	// ResourcesPerNamespace is the number of config maps of each namespace, which add resources
+	// to back up besides the volumes
+	ResourcesPerNamespace int `json:"resourcesPerNamespace"`
+	// StorageClass of the volumes, the default storage class if empty
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// Bytes returns the number of bytes written to the volumes of the data set
+func (d *DataSet) Bytes() int64 {
+	return int64(d.Namespaces*d.VolumesPerNamespace*d.FilesPerVolume) * d.FileSize
+}
+
+// Validate returns an error if the data set can't be generated
+func (d *DataSet) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("data set has no name")
+	}
+	if d.Namespaces < 1 {
+		return fmt.Errorf("data set %s needs at least one namespace", d.Name)
+	}
+	if d.VolumesPerNamespace > 0 {
+		size, err := resource.ParseQuantity(d.VolumeSize)
+		if err != nil {
+			return fmt.Errorf("invalid volume size [%s] of data set %s: %v", d.VolumeSize, d.Name, err)
+		}
+		if need := int64(d.FilesPerVolume) * d.FileSize; need > size.Value() {
+			return fmt.Errorf("%d files of %d bytes don't fit in volumes of %s of data set %s", d.FilesPerVolume, d.FileSize, d.VolumeSize, d.Name)
+		}
+	}
+	return nil
+}
+
+// namespace returns the name of the ith namespace of the data set
+func (d *DataSet) namespace(i int) string {
+	return fmt.Sprintf("%s-%d", d.Name, i)
+}
+
+// Core is the subset of the core operations of sched-ops the generator uses, which
+// core.Instance() implements
+type Core interface {
+	CreateNamespace(namespace *corev1.Namespace) (*corev1.Namespace, error)
+	DeleteNamespace(name string) error
+	CreatePersistentVolumeClaim(pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error)
+	CreateConfigMap(configMap *corev1.ConfigMap) (*corev1.ConfigMap, error)
+	CreatePod(pod *corev1.Pod) (*corev1.Pod, error)
+	GetPodByName(podName string, namespace string) (*corev1.Pod, error)
+	DeletePod(name string, ns string, force bool) error
+}
+
+// Generator generates the data of data sets in a cluster
+type Generator struct {
+	Core Core
+	// Image of the pods writing the data, busybox if empty
+	Image string
+	// Timeout and RetryInterval bound the wait for the data to be written
+	Timeout       time.Duration
+	RetryInterval time.Duration
+}
+
+// Generate creates the namespaces of the data set with their volumes and resources, writes the
+// files of the volumes and returns the namespaces
+func (g *Generator) Generate(d *DataSet) ([]string, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	var namespaces []string
+	for i := 0; i < d.Namespaces; i++ {
+		ns := d.namespace(i)
+		if err := g.generateNamespace(d, ns); err != nil {
+			return namespaces, fmt.Errorf("failed to generate namespace %s of data set %s: %v", ns, d.Name, err)
+		}
+		namespaces = append(namespaces, ns)
+	}
+	timeout, retryInterval := g.Timeout, g.RetryInterval
+	if timeout == 0 {
+		timeout = defaultGenerateTimeout
+	}
+	if retryInterval == 0 {
+		retryInterval = defaultRetryInterval
+	}
+	for _, ns := range namespaces {
+		if err := g.waitForData(ns, timeout, retryInterval); err != nil {
+			return namespaces, err
+		}
+	}
+	return namespaces, nil
+}
+
+func (g *Generator) generateNamespace(d *DataSet, ns string) error {
+	_, err := g.Core.CreateNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   ns,
+		Labels: map[string]string{dataSetLabel: d.Name},
+	}})
+	if err != nil {
+		return err
+	}
+	for i := 0; i < d.ResourcesPerNamespace; i++ {
+		_, err := g.Core.CreateConfigMap(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("resource-%d", i), Namespace: ns},
+			Data:       map[string]string{"index": fmt.Sprint(i)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if d.VolumesPerNamespace == 0 {
+		return nil
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: writerPod, Namespace: ns},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:    writerPod,
+				Image:   g.image(),
+				Command: []string{"sh", "-c", writeScript(d)},
+			}},
+		},
+	}
+	for i := 0; i < d.VolumesPerNamespace; i++ {
+		claim := fmt.Sprintf("data-%d", i)
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: claim, Namespace: ns},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(d.VolumeSize)},
+				},
+			},
+		}
+		if d.StorageClass != "" {
+			pvc.Spec.StorageClassName = &d.StorageClass
+		}
+		if _, err := g.Core.CreatePersistentVolumeClaim(pvc); err != nil {
+			return err
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name:         claim,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+		})
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: claim, MountPath: "/" + claim})
+	}
+	_, err = g.Core.CreatePod(pod)
+	return err
+}
+
+// writeScript returns the script writing the files of random data of the volumes of a namespace
+func writeScript(d *DataSet) string {
+	var mounts []string
+	for i := 0; i < d.VolumesPerNamespace; i++ {
+		mounts = append(mounts, fmt.Sprintf("/data-%d", i))
+	}
+	return fmt.Sprintf(`set -e
+for v in %s; do
+  i=0
+  while [ $i -lt %d ]; do
+    dd if=/dev/urandom of=$v/file-$i bs=1048576 count=%d 2>/dev/null
+    i=$((i+1))
+  done
+done
+sync`, strings.Join(mounts, " "), d.FilesPerVolume, (d.FileSize+1<<20-1)>>20)
+}
+
+// waitForData waits for the writer pod of a namespace to complete and deletes it, so that the
+// backups only hold the volumes and resources of the data set
+func (g *Generator) waitForData(ns string, timeout, retryInterval time.Duration) error {
+	_, err := task.DoRetryWithTimeout(func() (interface{}, bool, error) {
+		pod, err := g.Core.GetPodByName(writerPod, ns)
+		if err != nil {
+			return nil, true, err
+		}
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			return nil, false, nil
+		case corev1.PodFailed:
+			return nil, false, fmt.Errorf("pod %s/%s failed to write data: %s", ns, writerPod, pod.Status.Message)
+		}
+		return nil, true, fmt.Errorf("pod %s/%s is %s", ns, writerPod, pod.Status.Phase)
+	}, timeout, retryInterval)
+	if err != nil {
+		return err
+	}
+	return g.Core.DeletePod(writerPod, ns, false)
+}
+
+// Delete deletes the namespaces of a data set, or of its restores
+func (g *Generator) Delete(namespaces []string) error {
+	var errs []string
+	for _, ns := range namespaces {
+		if err := g.Core.DeleteNamespace(ns); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete namespaces: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (g *Generator) image() string {
+	if g.Image == "" {
+		return defaultImage
+	}
+	return g.Image
+}
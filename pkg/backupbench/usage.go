@@ -0,0 +1,125 @@
+package backupbench
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/portworx/torpedo/drivers/node"
+)
+
+// Usage is the resource usage of the cluster at a point in time
+type Usage struct {
+	// Load is the sum of the one minute load averages of the nodes
+	Load float64 `json:"load"`
+	// MemoryUsed is the sum of the memory used on the nodes, in bytes
+	MemoryUsed uint64 `json:"memoryUsed"`
+}
+
+// Sampler samples the resource usage of the cluster
+type Sampler interface {
+	Sample() (Usage, error)
+}
+
+// usageCommand prints the load averages and the total and available memory of a node
+const usageCommand = "cat /proc/loadavg; grep -E '^(MemTotal|MemAvailable):' /proc/meminfo"
+
+// NodeSampler samples the resource usage of nodes by running commands on them with the node driver
+type NodeSampler struct {
+	Driver node.Driver
+	Nodes  []node.Node
+}
+
+// Sample returns the sum of the usage of the nodes
+func (s *NodeSampler) Sample() (Usage, error) {
+	var total Usage
+	for _, n := range s.Nodes {
+		out, err := s.Driver.RunCommand(n, usageCommand, node.ConnectionOpts{Timeout: time.Minute, TimeBeforeRetry: 5 * time.Second})
+		if err != nil {
+			return Usage{}, fmt.Errorf("failed to sample usage of node %s: %v", n.Name, err)
+		}
+		usage, err := parseUsage(out)
+		if err != nil {
+			return Usage{}, fmt.Errorf("failed to parse usage of node %s: %v", n.Name, err)
+		}
+		total.Load += usage.Load
+		total.MemoryUsed += usage.MemoryUsed
+	}
+	return total, nil
+}
+
+// parseUsage parses the output of usageCommand
+func parseUsage(out string) (Usage, error) {
+	var usage Usage
+	var memTotal, memAvailable uint64
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for first := true; scanner.Scan(); first = false {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if first {
+			load, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return Usage{}, fmt.Errorf("invalid load average [%s]", fields[0])
+			}
+			usage.Load = load
+			continue
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return Usage{}, fmt.Errorf("invalid memory [%s]", scanner.Text())
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			memTotal = kb * 1024
+		case "MemAvailable:":
+			memAvailable = kb * 1024
+		}
+	}
+	if memTotal == 0 {
+		return Usage{}, fmt.Errorf("no total memory in [%s]", out)
+	}
+	usage.MemoryUsed = memTotal - memAvailable
+	return usage, nil
+}
+
+// usageStats accumulates samples of usage
+type usageStats struct {
+	samples int
+	sum     Usage
+	peak    Usage
+}
+
+func (u *usageStats) add(usage Usage) {
+	u.samples++
+	u.sum.Load += usage.Load
+	u.sum.MemoryUsed += usage.MemoryUsed
+	if usage.Load > u.peak.Load {
+		u.peak.Load = usage.Load
+	}
+	if usage.MemoryUsed > u.peak.MemoryUsed {
+		u.peak.MemoryUsed = usage.MemoryUsed
+	}
+}
+
+// UsageStats is the average and peak usage of the cluster during a backup or restore
+type UsageStats struct {
+	Average Usage `json:"average"`
+	Peak    Usage `json:"peak"`
+}
+
+func (u *usageStats) stats() *UsageStats {
+	if u.samples == 0 {
+		return nil
+	}
+	return &UsageStats{
+		Average: Usage{Load: u.sum.Load / float64(u.samples), MemoryUsed: u.sum.MemoryUsed / uint64(u.samples)},
+		Peak:    u.peak,
+	}
+}
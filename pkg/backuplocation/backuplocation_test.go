@@ -0,0 +1,134 @@
+package backuplocation
+
+import (
+	"testing"
+
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb/pxbtest"
+	"github.com/portworx/torpedo/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func names(providers []Provider) []string {
+	var n []string
+	for _, p := range providers {
+		n = append(n, p.Name())
+	}
+	return n
+}
+
+func TestMatrix(t *testing.T) {
+	all, err := Matrix(nil, Capabilities{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{drivers.ProviderAws, drivers.ProviderAzure, drivers.ProviderGke, ProviderNFS, ProviderS3ObjectLock}, names(all))
+
+	locked, err := Matrix(nil, Capabilities{ObjectLock: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{drivers.ProviderAws, ProviderS3ObjectLock}, names(locked))
+
+	encrypted, err := Matrix([]string{"azure", " nfs"}, Capabilities{Encryption: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{drivers.ProviderAzure, ProviderNFS}, names(encrypted))
+
+	_, err = Matrix([]string{"tape"}, Capabilities{})
+	assert.IsType(t, &errors.ErrNotFound{}, err)
+	assert.Equal(t, "[object-lock,immutable]", Capabilities{ObjectLock: true, Immutable: true}.String())
+}
+
+func TestProviders(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	// The cloud credential of AWS only needs its keys, not its object store
+	aws, err := Get(drivers.ProviderAws)
+	require.NoError(t, err)
+	credential, err := aws.CloudCredential()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", credential.GetAwsConfig().GetSecretKey())
+	_, err = aws.BackupLocation("bucket")
+	assert.EqualError(t, err, "Environment Variable with UID/Name: S3_ENDPOINT not found")
+
+	t.Setenv("S3_ENDPOINT", "s3.amazonaws.com")
+	t.Setenv("S3_REGION", "us-east-1")
+	t.Setenv("S3_DISABLE_SSL", "false")
+	t.Setenv("S3_OBJECT_LOCK_ENDPOINT", "minio:9000")
+	t.Setenv("S3_OBJECT_LOCK_DISABLE_SSL", "true")
+
+	locked, err := Get(ProviderS3ObjectLock)
+	require.NoError(t, err)
+	location, err := locked.BackupLocation("locked-bucket")
+	require.NoError(t, err)
+	assert.Equal(t, &api.S3Config{Endpoint: "minio:9000", Region: "us-east-1", DisableSsl: true}, location.GetS3Config())
+	credential, err = locked.CloudCredential()
+	require.NoError(t, err)
+	assert.Equal(t, "id", credential.GetAwsConfig().GetAccessKey())
+	s3Store, err := locked.(S3Provider).S3Store()
+	require.NoError(t, err)
+	assert.Equal(t, &S3Store{AccessKeyID: "id", SecretAccessKey: "secret", Endpoint: "minio:9000", Region: "us-east-1", DisableSSL: true}, s3Store)
+
+	gcp, err := Get(drivers.ProviderGke)
+	require.NoError(t, err)
+	_, err = gcp.CloudCredential()
+	assert.EqualError(t, err, "Environment Variable with UID/Name: GCP_PROJECT_ID or GCP_PROJECT_ID_ not found")
+	t.Setenv("GCP_PROJECT_ID", "torpedo")
+	t.Setenv("GCP_ACCOUNT_KEY", "{}")
+	store, err := gcp.(GoogleProvider).GoogleStore()
+	require.NoError(t, err)
+	assert.Equal(t, &GoogleStore{ProjectID: "torpedo", AccountKey: "{}"}, store)
+	credential, err = gcp.CloudCredential()
+	require.NoError(t, err)
+	assert.Equal(t, "torpedo", credential.GetGoogleConfig().GetProjectId())
+
+	nfs, err := Get(ProviderNFS)
+	require.NoError(t, err)
+	credential, err = nfs.CloudCredential()
+	require.NoError(t, err)
+	assert.Nil(t, credential)
+	t.Setenv("NFS_SERVER_ADDR", "10.0.0.1")
+	t.Setenv("NFS_PATH", "/exports/backups")
+	location, err = nfs.BackupLocation("suite")
+	require.NoError(t, err)
+	assert.Equal(t, "/exports/backups", location.GetPath())
+	assert.Equal(t, "suite", location.GetNfsConfig().GetSubPath())
+}
+
+func TestCreate(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("S3_ENDPOINT", "s3.amazonaws.com")
+	t.Setenv("S3_REGION", "us-east-1")
+	t.Setenv("S3_DISABLE_SSL", "false")
+	t.Setenv("NFS_SERVER_ADDR", "10.0.0.1")
+	t.Setenv("NFS_PATH", "/exports/backups")
+	d, _ := pxbtest.NewDriver(t, fakepxb.Config{})
+	ctx := backup.GetCtxWithToken(fakepxb.DefaultAdminUser)
+
+	for _, name := range []string{drivers.ProviderAws, ProviderNFS} {
+		p, err := Get(name)
+		require.NoError(t, err)
+		location := Location{Name: name + "-location", OrgID: fakepxb.DefaultOrg, Bucket: "bucket", EncryptionKey: "torpedo"}
+		if credential, _ := p.CloudCredential(); credential != nil {
+			location.CloudCredential = name + "-cred"
+		}
+		require.NoError(t, CreateCloudCredential(ctx, d, p, name+"-cred", "", fakepxb.DefaultOrg))
+		require.NoError(t, CreateBackupLocation(ctx, d, p, location))
+	}
+
+	resp, err := d.EnumerateBackupLocation(ctx, &api.BackupLocationEnumerateRequest{OrgId: fakepxb.DefaultOrg})
+	require.NoError(t, err)
+	types := make(map[string]api.BackupLocationInfo_Type)
+	for _, l := range resp.GetBackupLocations() {
+		types[l.GetName()] = l.GetBackupLocationInfo().GetType()
+	}
+	assert.Equal(t, map[string]api.BackupLocationInfo_Type{
+		"aws-location": api.BackupLocationInfo_S3,
+		"nfs-location": api.BackupLocationInfo_NFS,
+	}, types)
+	creds, err := d.EnumerateCloudCredential(ctx, &api.CloudCredentialEnumerateRequest{OrgId: fakepxb.DefaultOrg})
+	require.NoError(t, err)
+	assert.Len(t, creds.GetCloudCredentials(), 1)
+}
@@ -0,0 +1,206 @@
+// Package backuplocation describes the providers of backup locations and their cloud credentials,
+// so that backup tests can create them for any provider and run over a matrix of providers
+// selected by their capabilities instead of branching on the provider name.
+package backuplocation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/pkg/errors"
+)
+
+var providers = make(map[string]Provider)
+
+// Capabilities are the features of the backup locations of a provider
+type Capabilities struct {
+	// ObjectLock is for buckets whose objects can be locked for a retention period
+	ObjectLock bool
+	// Encryption is for backups encrypted by PX-Backup with the encryption key of the location
+	Encryption bool
+	// Immutable is for storage which can't be overwritten nor deleted, such as WORM containers
+	Immutable bool
+}
+
+// Satisfies returns true if the capabilities include all the needed ones
+func (c Capabilities) Satisfies(need Capabilities) bool {
+	return (c.ObjectLock || !need.ObjectLock) && (c.Encryption || !need.Encryption) && (c.Immutable || !need.Immutable)
+}
+
+func (c Capabilities) String() string {
+	var names []string
+	if c.ObjectLock {
+		names = append(names, "object-lock")
+	}
+	if c.Encryption {
+		names = append(names, "encryption")
+	}
+	if c.Immutable {
+		names = append(names, "immutable")
+	}
+	return "[" + strings.Join(names, ",") + "]"
+}
+
+// Provider is a provider of backup locations
+type Provider interface {
+	// Name returns the name of the provider
+	Name() string
+	// Capabilities returns the capabilities of the backup locations of the provider
+	Capabilities() Capabilities
+	// CloudCredential returns the cloud credential of the backup locations of the provider, nil
+	// if they don't need one
+	CloudCredential() (*api.CloudCredentialInfo, error)
+	// BackupLocation returns the backup location of the provider for a bucket, without its
+	// cloud credential and encryption key
+	BackupLocation(bucket string) (*api.BackupLocationInfo, error)
+}
+
+// S3Store is the endpoint and credentials of the S3 compatible object store of a provider
+type S3Store struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Endpoint        string
+	Region          string
+	DisableSSL      bool
+}
+
+// S3Provider is a provider of backup locations in S3 compatible object stores, whose buckets are
+// created and deleted by tests with the endpoint and credentials of the provider
+type S3Provider interface {
+	Provider
+	// S3Store returns the object store of the backup locations of the provider
+	S3Store() (*S3Store, error)
+}
+
+// GoogleStore is the project and service account key of the Google cloud storage of a provider
+type GoogleStore struct {
+	ProjectID string
+	// AccountKey is the JSON key of the service account
+	AccountKey string
+}
+
+// GoogleProvider is a provider of backup locations in Google cloud storage, whose buckets are
+// created and deleted by tests with the project and service account key of the provider
+type GoogleProvider interface {
+	Provider
+	// GoogleStore returns the Google cloud storage of the backup locations of the provider
+	GoogleStore() (*GoogleStore, error)
+}
+
+// Register registers a provider
+func Register(p Provider) error {
+	if _, ok := providers[p.Name()]; ok {
+		return fmt.Errorf("backup location provider: %s is already registered", p.Name())
+	}
+	providers[p.Name()] = p
+	return nil
+}
+
+// Get returns the registered provider with the given name
+func Get(name string) (Provider, error) {
+	if p, ok := providers[name]; ok {
+		return p, nil
+	}
+	return nil, &errors.ErrNotFound{
+		ID:   name,
+		Type: "BackupLocationProvider",
+	}
+}
+
+// Names returns the names of the registered providers, sorted
+func Names() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Matrix returns the providers with the given names, or all registered providers if there are no
+// names, which have the needed capabilities
+func Matrix(names []string, need Capabilities) ([]Provider, error) {
+	if len(names) == 0 {
+		names = Names()
+	}
+	var matrix []Provider
+	for _, name := range names {
+		p, err := Get(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if p.Capabilities().Satisfies(need) {
+			matrix = append(matrix, p)
+		}
+	}
+	return matrix, nil
+}
+
+// Location describes a backup location to create for a provider
+type Location struct {
+	Name          string
+	UID           string
+	OrgID         string
+	Bucket        string
+	EncryptionKey string
+	// CloudCredential and CloudCredentialUID are the cloud credential of the location, if the
+	// provider needs one
+	CloudCredential    string
+	CloudCredentialUID string
+}
+
+// CreateCloudCredential creates the cloud credential of a provider. Providers without cloud
+// credentials need nothing to be created.
+func CreateCloudCredential(ctx context.Context, d backup.Driver, p Provider, name, uid, orgID string) error {
+	credential, err := p.CloudCredential()
+	if err != nil || credential == nil {
+		return err
+	}
+	_, err = d.CreateCloudCredential(ctx, &api.CloudCredentialCreateRequest{
+		CreateMetadata:  &api.CreateMetadata{Name: name, Uid: uid, OrgId: orgID},
+		CloudCredential: credential,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s cloud credential [%s] in org [%s]: %v", p.Name(), name, orgID, err)
+	}
+	return nil
+}
+
+// CreateBackupLocation creates a backup location of a provider. PX-Backup finds out itself if the
+// bucket of the location is locked.
+func CreateBackupLocation(ctx context.Context, d backup.Driver, p Provider, l Location) error {
+	info, err := p.BackupLocation(l.Bucket)
+	if err != nil {
+		return err
+	}
+	info.EncryptionKey = l.EncryptionKey
+	if l.CloudCredential != "" {
+		info.CloudCredentialRef = &api.ObjectRef{Name: l.CloudCredential, Uid: l.CloudCredentialUID}
+	}
+	_, err = d.CreateBackupLocation(ctx, &api.BackupLocationCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: l.Name, Uid: l.UID, OrgId: l.OrgID},
+		BackupLocation: info,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s backup location [%s] in org [%s]: %v", p.Name(), l.Name, l.OrgID, err)
+	}
+	return nil
+}
+
+// env returns the value of the first of the environment variables which is set
+func env(keys ...string) (string, error) {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value, nil
+		}
+	}
+	return "", &errors.ErrNotFound{
+		ID:   strings.Join(keys, " or "),
+		Type: "Environment Variable",
+	}
+}
@@ -0,0 +1,256 @@
+package backuplocation
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers"
+)
+
+const (
+	// ProviderS3ObjectLock is the provider of S3 compatible object stores with object lock, such as
+	// MinIO or FlashBlade, configured by the S3 environment variables with the S3_OBJECT_LOCK_ prefix
+	ProviderS3ObjectLock = "s3-object-lock"
+	// ProviderNFS is the provider of NFS shares
+	ProviderNFS = "nfs"
+)
+
+// s3Provider is the provider of S3 and S3 compatible buckets. Its environment variables are the
+// ones of the S3 helpers, optionally overridden by the ones with the prefix.
+type s3Provider struct {
+	name         string
+	prefix       string
+	capabilities Capabilities
+}
+
+func (s *s3Provider) Name() string {
+	return s.name
+}
+
+func (s *s3Provider) Capabilities() Capabilities {
+	return s.capabilities
+}
+
+// env returns the value of the environment variable with the prefix of the provider, or of the
+// first of the given ones
+func (s *s3Provider) env(key string, fallbacks ...string) (string, error) {
+	keys := fallbacks
+	if s.prefix != "" {
+		keys = append([]string{s.prefix + key}, fallbacks...)
+	}
+	return env(keys...)
+}
+
+// credentials returns the access key id and secret access key of the provider
+func (s *s3Provider) credentials() (string, string, error) {
+	id, err := s.env("ACCESS_KEY_ID", "AWS_ACCESS_KEY_ID")
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := s.env("SECRET_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY")
+	if err != nil {
+		return "", "", err
+	}
+	return id, secret, nil
+}
+
+func (s *s3Provider) S3Store() (*S3Store, error) {
+	id, secret, err := s.credentials()
+	if err != nil {
+		return nil, err
+	}
+	store := &S3Store{AccessKeyID: id, SecretAccessKey: secret}
+	for _, v := range []struct {
+		key      string
+		fallback string
+		value    *string
+	}{
+		{"ENDPOINT", "S3_ENDPOINT", &store.Endpoint},
+		{"REGION", "S3_REGION", &store.Region},
+	} {
+		value, err := s.env(v.key, v.fallback)
+		if err != nil {
+			return nil, err
+		}
+		*v.value = value
+	}
+	disableSSL, err := s.env("DISABLE_SSL", "S3_DISABLE_SSL")
+	if err != nil {
+		return nil, err
+	}
+	store.DisableSSL, err = strconv.ParseBool(disableSSL)
+	if err != nil {
+		return nil, fmt.Errorf("S3_DISABLE_SSL=%s is not a valid boolean value", disableSSL)
+	}
+	return store, nil
+}
+
+func (s *s3Provider) CloudCredential() (*api.CloudCredentialInfo, error) {
+	id, secret, err := s.credentials()
+	if err != nil {
+		return nil, err
+	}
+	return &api.CloudCredentialInfo{
+		Type: api.CloudCredentialInfo_AWS,
+		Config: &api.CloudCredentialInfo_AwsConfig{
+			AwsConfig: &api.AWSConfig{
+				AccessKey: id,
+				SecretKey: secret,
+			},
+		},
+	}, nil
+}
+
+func (s *s3Provider) BackupLocation(bucket string) (*api.BackupLocationInfo, error) {
+	store, err := s.S3Store()
+	if err != nil {
+		return nil, err
+	}
+	return &api.BackupLocationInfo{
+		Path: bucket,
+		Type: api.BackupLocationInfo_S3,
+		Config: &api.BackupLocationInfo_S3Config{
+			S3Config: &api.S3Config{
+				Endpoint:   store.Endpoint,
+				Region:     store.Region,
+				DisableSsl: store.DisableSSL,
+			},
+		},
+	}, nil
+}
+
+// azureProvider is the provider of Azure blob containers
+type azureProvider struct{}
+
+func (a *azureProvider) Name() string {
+	return drivers.ProviderAzure
+}
+
+func (a *azureProvider) Capabilities() Capabilities {
+	return Capabilities{Encryption: true}
+}
+
+func (a *azureProvider) CloudCredential() (*api.CloudCredentialInfo, error) {
+	config := &api.AzureConfig{}
+	for _, v := range []struct {
+		key   string
+		value *string
+	}{
+		{"AZURE_ACCOUNT_NAME", &config.AccountName},
+		{"AZURE_ACCOUNT_KEY", &config.AccountKey},
+		{"AZURE_TENANT_ID", &config.TenantId},
+		{"AZURE_CLIENT_ID", &config.ClientId},
+		{"AZURE_CLIENT_SECRET", &config.ClientSecret},
+	} {
+		value, err := env(v.key)
+		if err != nil {
+			return nil, err
+		}
+		*v.value = value
+	}
+	config.SubscriptionId = os.Getenv("AZURE_SUBSCRIPTION_ID")
+	return &api.CloudCredentialInfo{
+		Type:   api.CloudCredentialInfo_Azure,
+		Config: &api.CloudCredentialInfo_AzureConfig{AzureConfig: config},
+	}, nil
+}
+
+func (a *azureProvider) BackupLocation(bucket string) (*api.BackupLocationInfo, error) {
+	return &api.BackupLocationInfo{
+		Path: bucket,
+		Type: api.BackupLocationInfo_Azure,
+	}, nil
+}
+
+// gcpProvider is the provider of Google cloud storage buckets
+type gcpProvider struct{}
+
+func (g *gcpProvider) Name() string {
+	return drivers.ProviderGke
+}
+
+func (g *gcpProvider) Capabilities() Capabilities {
+	return Capabilities{Encryption: true}
+}
+
+func (g *gcpProvider) GoogleStore() (*GoogleStore, error) {
+	projectID, err := env("GCP_PROJECT_ID", "GCP_PROJECT_ID_")
+	if err != nil {
+		return nil, err
+	}
+	accountKey, err := env("GCP_ACCOUNT_KEY")
+	if err != nil {
+		return nil, err
+	}
+	return &GoogleStore{ProjectID: projectID, AccountKey: accountKey}, nil
+}
+
+func (g *gcpProvider) CloudCredential() (*api.CloudCredentialInfo, error) {
+	store, err := g.GoogleStore()
+	if err != nil {
+		return nil, err
+	}
+	return &api.CloudCredentialInfo{
+		Type: api.CloudCredentialInfo_Google,
+		Config: &api.CloudCredentialInfo_GoogleConfig{
+			GoogleConfig: &api.GoogleConfig{
+				ProjectId: store.ProjectID,
+				JsonKey:   store.AccountKey,
+			},
+		},
+	}, nil
+}
+
+func (g *gcpProvider) BackupLocation(bucket string) (*api.BackupLocationInfo, error) {
+	return &api.BackupLocationInfo{
+		Path: bucket,
+		Type: api.BackupLocationInfo_Google,
+	}, nil
+}
+
+// nfsProvider is the provider of NFS shares. Buckets are sub paths of the exported path.
+type nfsProvider struct{}
+
+func (n *nfsProvider) Name() string {
+	return ProviderNFS
+}
+
+func (n *nfsProvider) Capabilities() Capabilities {
+	return Capabilities{Encryption: true}
+}
+
+func (n *nfsProvider) CloudCredential() (*api.CloudCredentialInfo, error) {
+	return nil, nil
+}
+
+func (n *nfsProvider) BackupLocation(bucket string) (*api.BackupLocationInfo, error) {
+	server, err := env("NFS_SERVER_ADDR")
+	if err != nil {
+		return nil, err
+	}
+	path, err := env("NFS_PATH")
+	if err != nil {
+		return nil, err
+	}
+	return &api.BackupLocationInfo{
+		Path: path,
+		Type: api.BackupLocationInfo_NFS,
+		Config: &api.BackupLocationInfo_NfsConfig{
+			NfsConfig: &api.NFSConfig{
+				ServerAddr:  server,
+				SubPath:     bucket,
+				MountOption: os.Getenv("NFS_MOUNT_OPTION"),
+			},
+		},
+	}, nil
+}
+
+func init() {
+	Register(&s3Provider{name: drivers.ProviderAws, capabilities: Capabilities{ObjectLock: true, Encryption: true, Immutable: true}})
+	Register(&s3Provider{name: ProviderS3ObjectLock, prefix: "S3_OBJECT_LOCK_", capabilities: Capabilities{ObjectLock: true, Encryption: true, Immutable: true}})
+	Register(&azureProvider{})
+	Register(&gcpProvider{})
+	Register(&nfsProvider{})
+}
@@ -0,0 +1,146 @@
+package backuprbac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/backup/fakekeycloak"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb/pxbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const orgID = fakepxb.DefaultOrg
+
+// newEngine returns an engine running against a fake px-central, with a cluster and a backup
+// location of the admin
+func newEngine(t *testing.T) (*Engine, context.Context) {
+	keycloak, err := fakekeycloak.New(fakekeycloak.PxCentralRealm("admin-password"))
+	require.NoError(t, err)
+	t.Cleanup(keycloak.Close)
+	t.Setenv(backup.PxCentralUIURL, keycloak.URL)
+	pwd := backup.PxCentralAdminPwd
+	t.Cleanup(func() { backup.PxCentralAdminPwd = pwd })
+	backup.PxCentralAdminPwd = "admin-password"
+	d, _ := pxbtest.NewDriver(t, fakepxb.Config{})
+	ctx, err := backup.GetPxCentralAdminCtx()
+	require.NoError(t, err)
+	_, err = d.CreateCloudCredential(ctx, &api.CloudCredentialCreateRequest{
+		CreateMetadata:  &api.CreateMetadata{Name: "aws-cred", OrgId: orgID},
+		CloudCredential: &api.CloudCredentialInfo{Type: api.CloudCredentialInfo_AWS},
+	})
+	require.NoError(t, err)
+	_, err = d.CreateCluster(ctx, &api.ClusterCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: "source-cluster", OrgId: orgID},
+		Kubeconfig:     "kubeconfig",
+	})
+	require.NoError(t, err)
+	_, err = d.CreateBackupLocation(ctx, &api.BackupLocationCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: "s3-location", OrgId: orgID},
+		BackupLocation: &api.BackupLocationInfo{Type: api.BackupLocationInfo_S3, Path: "bucket", CloudCredential: "aws-cred"},
+	})
+	require.NoError(t, err)
+
+	engine := &Engine{
+		Driver: d,
+		Users:  &KeycloakUsers{Password: "user-password"},
+		Env: Env{
+			OrgID:               orgID,
+			Cluster:             "source-cluster",
+			BackupLocation:      "s3-location",
+			Namespaces:          []string{"mysql"},
+			RestoreCluster:      "destination-cluster",
+			CloudCredential:     &api.CloudCredentialInfo{Type: api.CloudCredentialInfo_AWS},
+			Location:            &api.BackupLocationInfo{Type: api.BackupLocationInfo_S3, Path: "bucket"},
+			BackupTimeout:       10 * time.Second,
+			BackupRetryInterval: 20 * time.Millisecond,
+		},
+		// each user registers the destination cluster, which the fake names in the whole organization
+		Setup: func(ctx context.Context, user string) error {
+			_, err := d.CreateCluster(ctx, &api.ClusterCreateRequest{
+				CreateMetadata: &api.CreateMetadata{Name: "destination-cluster", OrgId: orgID},
+				Kubeconfig:     "kubeconfig",
+			})
+			return err
+		},
+		Teardown: func(ctx context.Context, user string) error {
+			_, err := d.DeleteCluster(ctx, &api.ClusterDeleteRequest{Name: "destination-cluster", OrgId: orgID})
+			return err
+		},
+	}
+	return engine, ctx
+}
+
+func TestDefaultMatrix(t *testing.T) {
+	m := DefaultMatrix()
+	require.NoError(t, m.Validate())
+	subjects := m.Subjects()
+	require.Len(t, subjects, 9)
+	assert.Equal(t, Subject{Role: backup.ApplicationOwner, Access: ViewAccess}, subjects[0])
+	assert.Equal(t, Subject{Role: backup.InfrastructureOwner, Access: FullAccess}, subjects[8])
+	assert.Equal(t, Deny, m.Expect(backup.ApplicationUser, ViewAccess, Backup, Restore))
+	assert.Equal(t, Allow, m.Expect(backup.ApplicationUser, RestoreAccess, Backup, Restore))
+	assert.Equal(t, Deny, m.Expect(backup.ApplicationUser, RestoreAccess, Backup, Delete))
+	assert.Equal(t, Deny, m.Expect(backup.ApplicationUser, FullAccess, Backup, Update), "operations without requirement are denied")
+
+	cells := m.Cells(subjects[0], Backup)
+	require.Len(t, cells, 3)
+	assert.Equal(t, Delete, cells[2].Operation, "deletions run last")
+
+	m.Requirements = append(m.Requirements, Requirement{Object: Rule, Operation: Restore})
+	assert.Error(t, m.Validate())
+}
+
+func TestOutcomeOf(t *testing.T) {
+	assert.Equal(t, Allow, OutcomeOf(nil))
+	assert.Equal(t, Deny, OutcomeOf(status.Error(codes.PermissionDenied, "access denied")))
+	assert.Equal(t, Deny, OutcomeOf(fmt.Errorf("user testuser doesn't have permission to delete backup")))
+	assert.Equal(t, Deny, OutcomeOf(fmt.Errorf("failed to retrieve backup location")))
+	assert.Equal(t, Error, OutcomeOf(status.Error(codes.NotFound, "backup not found")))
+	assert.Equal(t, Error, OutcomeOf(status.Error(codes.Unauthenticated, "invalid token")))
+}
+
+func TestEngineRun(t *testing.T) {
+	engine, ctx := newEngine(t)
+	m := DefaultMatrix()
+	m.Accesses = append([]Access{NoAccess}, m.Accesses...)
+	// only application owners may update rules
+	for i, r := range m.Requirements {
+		if r.Object == Rule && r.Operation == Update {
+			m.Requirements[i].Roles = []backup.PxBackupRole{backup.ApplicationOwner}
+		}
+	}
+
+	report, err := engine.Run(ctx, m)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 12*(6*3))
+	t.Log("\n" + report.String())
+
+	// the fake enforces access levels but not roles
+	failures := report.Failures()
+	require.Len(t, failures, 2, report.Err())
+	for _, f := range failures {
+		assert.Equal(t, Rule, f.Object)
+		assert.Equal(t, Update, f.Operation)
+		assert.Equal(t, FullAccess, f.Access)
+		assert.Equal(t, Allow, f.Got)
+	}
+	assert.Contains(t, report.Err().Error(), "2 of 216 rbac matrix cells failed")
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	require.Len(t, lines, 13)
+	assert.Contains(t, lines[0], "BACKUP:RESTORE")
+	assert.Contains(t, lines[4], "px-backup-app.admin/full")
+	assert.Contains(t, lines[8], "allow!=deny")
+
+	users, err := backup.GetAllUsers()
+	require.NoError(t, err)
+	assert.Len(t, users, 1, "users are deleted")
+}
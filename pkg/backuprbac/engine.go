@@ -0,0 +1,268 @@
+package backuprbac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/pkg/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultBackupTimeout       = 10 * time.Minute
+	defaultBackupRetryInterval = 10 * time.Second
+)
+
+// Env is the environment the objects of the matrix are created in by the admin. Backups are taken
+// of the namespaces of the cluster to the backup location, restored to the restore cluster, and
+// new credentials and locations are copies of the templates.
+type Env struct {
+	OrgID             string
+	Cluster           string
+	ClusterUID        string
+	BackupLocation    string
+	BackupLocationUID string
+	Namespaces        []string
+	// RestoreCluster is the cluster of each user backups are restored to, see Engine.Setup
+	RestoreCluster string
+	// CloudCredential is the template of the cloud credentials shared with users
+	CloudCredential *api.CloudCredentialInfo
+	// Location is the template of the backup locations shared with users. Each of them has its
+	// own cloud credential, which users get read access to along with the location.
+	Location *api.BackupLocationInfo
+	// SchedulePolicy is the template of the schedule policies, an interval policy by default
+	SchedulePolicy *api.SchedulePolicyInfo
+	// Rule is the template of the rules, a rule without items by default
+	Rule *api.RulesInfo
+	// BackupTimeout and BackupRetryInterval bound the wait for backups to complete
+	BackupTimeout       time.Duration
+	BackupRetryInterval time.Duration
+}
+
+// Users creates the users of the subjects of a matrix
+type Users interface {
+	// Create creates a user with a role. It returns the id of the user, which objects are shared
+	// with, and its context.
+	Create(name string, role backup.PxBackupRole) (string, context.Context, error)
+	// Delete deletes a user
+	Delete(name string) error
+}
+
+// KeycloakUsers creates users in the Keycloak of px-central with the backup auth helpers
+type KeycloakUsers struct {
+	Password string
+}
+
+// Create creates a user with a role in Keycloak and returns its id and context
+func (k *KeycloakUsers) Create(name string, role backup.PxBackupRole) (string, context.Context, error) {
+	if err := backup.AddUser(name, name, "rbac", name+"@cnbu.com", k.Password); err != nil {
+		return "", nil, err
+	}
+	if err := backup.AddRoleToUser(name, role, "rbac matrix"); err != nil {
+		return "", nil, err
+	}
+	id, err := backup.FetchIDOfUser(name)
+	if err != nil {
+		return "", nil, err
+	}
+	ctx, err := backup.GetNonAdminCtx(name, k.Password)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, ctx, nil
+}
+
+// Delete deletes a user from Keycloak
+func (k *KeycloakUsers) Delete(name string) error {
+	return backup.DeleteUser(name)
+}
+
+// Engine runs the cells of a matrix against PX-Backup
+type Engine struct {
+	Driver backup.Driver
+	Users  Users
+	Env    Env
+	// Prefix prefixes the names of the users and objects, rbac by default
+	Prefix string
+	// Setup prepares a new user before it runs its operations, typically by registering its
+	// restore cluster. It is optional.
+	Setup func(ctx context.Context, user string) error
+	// Teardown cleans up what Setup created, before the user is deleted. It is optional.
+	Teardown func(ctx context.Context, user string) error
+}
+
+// subject is a subject of a matrix with its user
+type subject struct {
+	Subject
+	user string
+	id   string
+	ctx  context.Context
+}
+
+// ref is an object created for a subject
+type ref struct {
+	name string
+	uid  string
+	// credential is the cloud credential of a backup location
+	credential *ref
+}
+
+// target creates objects of a kind, shares them and runs operations on them
+type target interface {
+	create(ctx context.Context, name string) (*ref, error)
+	share(ctx context.Context, r *ref, s *subject) error
+	run(s *subject, r *ref, operation Operation) error
+	// cleanup deletes what is left of the object as the admin
+	cleanup(ctx context.Context, r *ref, s *subject)
+}
+
+// Run runs all cells of the matrix with the admin context and returns their report. The error is
+// for failures to set up users and objects, mismatches between expected and actual outcomes are
+// in the report.
+func (e *Engine) Run(adminCtx context.Context, m *Matrix) (*Report, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = "rbac"
+	}
+	targets := e.targets()
+	for _, object := range m.Objects() {
+		if _, ok := targets[object]; !ok {
+			return nil, fmt.Errorf("object %s is not supported", object)
+		}
+	}
+	report := &Report{Matrix: m}
+	for i, sub := range m.Subjects() {
+		s := &subject{Subject: sub, user: fmt.Sprintf("%s-user-%d-%d", prefix, time.Now().Unix(), i)}
+		log.InfoD("Validating rbac matrix for user %s with %s", s.user, s.Subject)
+		results, err := e.runSubject(adminCtx, m, s, targets, prefix)
+		report.Results = append(report.Results, results...)
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (e *Engine) runSubject(adminCtx context.Context, m *Matrix, s *subject, targets map[Object]target, prefix string) ([]Result, error) {
+	var err error
+	s.id, s.ctx, err = e.Users.Create(s.user, s.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user %s with role %s: %v", s.user, s.Role, err)
+	}
+	defer func() {
+		if err := e.Users.Delete(s.user); err != nil {
+			log.Warnf("failed to delete user %s: %v", s.user, err)
+		}
+	}()
+	if e.Teardown != nil {
+		defer func() {
+			if err := e.Teardown(s.ctx, s.user); err != nil {
+				log.Warnf("failed to tear down user %s: %v", s.user, err)
+			}
+		}()
+	}
+	if e.Setup != nil {
+		if err := e.Setup(s.ctx, s.user); err != nil {
+			return nil, fmt.Errorf("failed to set up user %s: %v", s.user, err)
+		}
+	}
+	var results []Result
+	for _, object := range m.Objects() {
+		t := targets[object]
+		r, err := t.create(adminCtx, fmt.Sprintf("%s-%s-%s", prefix, object, uuid.New()[:8]))
+		if err != nil {
+			return results, fmt.Errorf("failed to create %s for user %s: %v", object, s.user, err)
+		}
+		if s.Access != NoAccess {
+			if err := t.share(adminCtx, r, s); err != nil {
+				t.cleanup(adminCtx, r, s)
+				return results, fmt.Errorf("failed to share %s %s with user %s: %v", object, r.name, s.user, err)
+			}
+		}
+		for _, cell := range m.Cells(s.Subject, object) {
+			err := t.run(s, r, cell.Operation)
+			result := Result{Cell: cell, Got: OutcomeOf(err), Err: err}
+			if result.Failed() {
+				log.Errorf("%s of %s %s by %s: got %s, want %s: %v", cell.Operation, object, r.name, s.user, result.Got, cell.Expect, err)
+			}
+			results = append(results, result)
+		}
+		t.cleanup(adminCtx, r, s)
+	}
+	return results, nil
+}
+
+// deniedMessages are parts of the messages of PX-Backup errors for lack of permission which
+// don't have a permission denied code. Restores of backups shared with view access fail on
+// the backup location the user can't see.
+var deniedMessages = []string{"doesn't have permission", "failed to retrieve backup location"}
+
+// OutcomeOf returns the outcome of an operation from its error. Errors with the permission denied
+// code, or with the messages of PX-Backup denials, are denials. Other errors, such as objects
+// which are not found, are errors.
+func OutcomeOf(err error) Outcome {
+	if err == nil {
+		return Allow
+	}
+	if status.Code(err) == codes.PermissionDenied {
+		return Deny
+	}
+	msg := err.Error()
+	for _, denied := range deniedMessages {
+		if strings.Contains(msg, denied) {
+			return Deny
+		}
+	}
+	return Error
+}
+
+func (e *Engine) targets() map[Object]target {
+	env := e.Env
+	if env.BackupTimeout == 0 {
+		env.BackupTimeout = defaultBackupTimeout
+	}
+	if env.BackupRetryInterval == 0 {
+		env.BackupRetryInterval = defaultBackupRetryInterval
+	}
+	if env.SchedulePolicy == nil {
+		env.SchedulePolicy = &api.SchedulePolicyInfo{Interval: &api.SchedulePolicyInfo_IntervalPolicy{Minutes: 15, Retain: 2}}
+	}
+	if env.Rule == nil {
+		env.Rule = &api.RulesInfo{}
+	}
+	credentials := &credentialTarget{driver: e.Driver, env: &env}
+	return map[Object]target{
+		Backup:          &backupTarget{driver: e.Driver, env: &env},
+		Cluster:         &backupTarget{driver: e.Driver, env: &env, clusterShare: true},
+		BackupLocation:  &locationTarget{driver: e.Driver, env: &env, credentials: credentials},
+		CloudCredential: credentials,
+		Rule:            &ruleTarget{driver: e.Driver, env: &env},
+		SchedulePolicy:  &policyTarget{driver: e.Driver, env: &env},
+	}
+}
+
+// ownership returns the ownership sharing an object with a subject, read for view and restore
+// access and write for full access
+func ownership(s *subject) *api.Ownership {
+	access := api.Ownership_Read
+	if s.Access >= FullAccess {
+		access = api.Ownership_Write
+	}
+	return &api.Ownership{Collaborators: []*api.Ownership_AccessConfig{{Id: s.id, Access: access}}}
+}
+
+// warnOnError logs the failure of a cleanup
+func warnOnError(err error, format string, args ...interface{}) {
+	if err != nil && OutcomeOf(err) != Deny {
+		log.Warnf("%s: %v", fmt.Sprintf(format, args...), err)
+	}
+}
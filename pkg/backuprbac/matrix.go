@@ -0,0 +1,251 @@
+// Package backuprbac validates the role based access control of PX-Backup against a declarative
+// permission matrix. A matrix crosses PX-Backup roles and share access levels with the objects
+// users get access to and the operations they run on them, and declares which cells are allowed.
+// The engine creates a user for each role and access level, shares dedicated objects with it,
+// runs every operation as that user and reports the outcome of every cell in a table, so that a
+// new role or access level is a new row of the matrix rather than new test code.
+package backuprbac
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/portworx/torpedo/drivers/backup"
+)
+
+// Access is the access level an object is shared with. Its values match the backup share access
+// types of PX-Backup.
+type Access int32
+
+const (
+	// NoAccess is for objects which are not shared with the user
+	NoAccess Access = iota
+	// ViewAccess allows to see an object
+	ViewAccess
+	// RestoreAccess allows to see a backup and restore it
+	RestoreAccess
+	// FullAccess allows to see, restore, update and delete an object
+	FullAccess
+)
+
+func (a Access) String() string {
+	switch a {
+	case NoAccess:
+		return "none"
+	case ViewAccess:
+		return "view"
+	case RestoreAccess:
+		return "restore"
+	case FullAccess:
+		return "full"
+	}
+	return fmt.Sprintf("access(%d)", int32(a))
+}
+
+// Object is a kind of PX-Backup object shared with users
+type Object string
+
+const (
+	// Backup is a backup shared through its backup share
+	Backup Object = "backup"
+	// Cluster is a cluster whose backups are shared through its cluster backup share. Its
+	// operations run on a backup of the cluster.
+	Cluster Object = "cluster"
+	// BackupLocation is a backup location shared through its ownership
+	BackupLocation Object = "location"
+	// CloudCredential is a cloud credential shared through its ownership
+	CloudCredential Object = "credential"
+	// Rule is a pre or post exec rule shared through its ownership
+	Rule Object = "rule"
+	// SchedulePolicy is a schedule policy shared through its ownership
+	SchedulePolicy Object = "policy"
+)
+
+// Operation is an operation a user runs on an object
+type Operation string
+
+const (
+	// View inspects the object
+	View Operation = "view"
+	// Update updates the object with its current content
+	Update Operation = "update"
+	// Restore restores a backup, or a backup of a cluster, to the restore cluster of the user
+	Restore Operation = "restore"
+	// Delete deletes the object, it always runs last on an object
+	Delete Operation = "delete"
+)
+
+// operationOrder is the order operations run on an object, so that the object exists until its
+// last operation
+var operationOrder = map[Operation]int{View: 0, Update: 1, Restore: 2, Delete: 3}
+
+// Outcome is the outcome of an operation
+type Outcome string
+
+const (
+	// Allow is for operations which succeed
+	Allow Outcome = "allow"
+	// Deny is for operations rejected for lack of permission, including on objects the user can't see
+	Deny Outcome = "deny"
+	// Error is for operations which fail for another reason, it is never expected
+	Error Outcome = "error"
+)
+
+// Requirement declares the access level needed to run an operation on an object
+type Requirement struct {
+	Object    Object
+	Operation Operation
+	// MinAccess is the lowest access level which allows the operation
+	MinAccess Access
+	// Roles are the roles allowed to run the operation, all roles if empty
+	Roles []backup.PxBackupRole
+}
+
+// allows returns true if a user with the role and access level is allowed to run the operation
+func (r Requirement) allows(role backup.PxBackupRole, access Access) bool {
+	if access < r.MinAccess {
+		return false
+	}
+	if len(r.Roles) == 0 {
+		return true
+	}
+	for _, allowed := range r.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Subject is a role and access level, for which a user is created
+type Subject struct {
+	Role   backup.PxBackupRole
+	Access Access
+}
+
+func (s Subject) String() string {
+	return fmt.Sprintf("%s/%s", s.Role, s.Access)
+}
+
+// Cell is an operation on an object run by a subject, with its expected outcome
+type Cell struct {
+	Subject
+	Object    Object
+	Operation Operation
+	Expect    Outcome
+}
+
+// Matrix is a permission matrix: each role is crossed with each access level, and each of these
+// subjects runs the operation of each requirement
+type Matrix struct {
+	Roles        []backup.PxBackupRole
+	Accesses     []Access
+	Requirements []Requirement
+}
+
+// DefaultMatrix returns the permission matrix of the PX-Backup roles with backups, and the
+// backups of clusters, shared with view, restore or full access, and other objects shared with
+// read (view and restore access) or write (full access) ownership. Roles don't restrict operations
+// on shared objects.
+func DefaultMatrix() *Matrix {
+	m := &Matrix{
+		Roles:    []backup.PxBackupRole{backup.ApplicationOwner, backup.ApplicationUser, backup.InfrastructureOwner},
+		Accesses: []Access{ViewAccess, RestoreAccess, FullAccess},
+	}
+	for _, object := range []Object{Backup, Cluster} {
+		m.Requirements = append(m.Requirements,
+			Requirement{Object: object, Operation: View, MinAccess: ViewAccess},
+			Requirement{Object: object, Operation: Restore, MinAccess: RestoreAccess},
+			Requirement{Object: object, Operation: Delete, MinAccess: FullAccess},
+		)
+	}
+	for _, object := range []Object{BackupLocation, CloudCredential, Rule, SchedulePolicy} {
+		m.Requirements = append(m.Requirements,
+			Requirement{Object: object, Operation: View, MinAccess: ViewAccess},
+			Requirement{Object: object, Operation: Update, MinAccess: FullAccess},
+			Requirement{Object: object, Operation: Delete, MinAccess: FullAccess},
+		)
+	}
+	return m
+}
+
+// Subjects returns the roles crossed with the access levels, by role then access level
+func (m *Matrix) Subjects() []Subject {
+	subjects := make([]Subject, 0, len(m.Roles)*len(m.Accesses))
+	for _, role := range m.Roles {
+		for _, access := range m.Accesses {
+			subjects = append(subjects, Subject{Role: role, Access: access})
+		}
+	}
+	return subjects
+}
+
+// Objects returns the objects of the requirements, in order of first appearance
+func (m *Matrix) Objects() []Object {
+	var objects []Object
+	seen := make(map[Object]bool)
+	for _, r := range m.Requirements {
+		if !seen[r.Object] {
+			seen[r.Object] = true
+			objects = append(objects, r.Object)
+		}
+	}
+	return objects
+}
+
+// Expect returns the expected outcome of an operation on an object for a role and access level.
+// Operations without requirement are denied.
+func (m *Matrix) Expect(role backup.PxBackupRole, access Access, object Object, operation Operation) Outcome {
+	for _, r := range m.Requirements {
+		if r.Object == object && r.Operation == operation {
+			if r.allows(role, access) {
+				return Allow
+			}
+			return Deny
+		}
+	}
+	return Deny
+}
+
+// Cells returns the cells of a subject on an object, in the order their operations run
+func (m *Matrix) Cells(subject Subject, object Object) []Cell {
+	var cells []Cell
+	for _, r := range m.Requirements {
+		if r.Object != object {
+			continue
+		}
+		cell := Cell{Subject: subject, Object: object, Operation: r.Operation, Expect: Deny}
+		if r.allows(subject.Role, subject.Access) {
+			cell.Expect = Allow
+		}
+		cells = append(cells, cell)
+	}
+	sort.SliceStable(cells, func(i, j int) bool {
+		return operationOrder[cells[i].Operation] < operationOrder[cells[j].Operation]
+	})
+	return cells
+}
+
+// Validate checks that the matrix has subjects and that its operations are known and apply to
+// their objects
+func (m *Matrix) Validate() error {
+	if len(m.Roles) == 0 || len(m.Accesses) == 0 {
+		return fmt.Errorf("matrix needs at least one role and one access level")
+	}
+	seen := make(map[string]bool)
+	for _, r := range m.Requirements {
+		if _, ok := operationOrder[r.Operation]; !ok {
+			return fmt.Errorf("unknown operation %s on %s", r.Operation, r.Object)
+		}
+		backups := r.Object == Backup || r.Object == Cluster
+		if (r.Operation == Restore && !backups) || (r.Operation == Update && backups) {
+			return fmt.Errorf("operation %s doesn't apply to %s", r.Operation, r.Object)
+		}
+		key := string(r.Object) + ":" + string(r.Operation)
+		if seen[key] {
+			return fmt.Errorf("operation %s on %s has several requirements", r.Operation, r.Object)
+		}
+		seen[key] = true
+	}
+	return nil
+}
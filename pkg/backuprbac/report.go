@@ -0,0 +1,93 @@
+package backuprbac
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Result is the outcome of the operation of a cell
+type Result struct {
+	Cell
+	Got Outcome
+	Err error
+}
+
+// Failed returns true if the outcome of the operation is not the expected one
+func (r Result) Failed() bool {
+	return r.Got != r.Expect
+}
+
+// Report is the outcome of the cells of a matrix
+type Report struct {
+	Matrix  *Matrix
+	Results []Result
+}
+
+// Failures returns the results whose outcome is not the expected one
+func (r *Report) Failures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if result.Failed() {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Err returns an error listing the failures of the report, nil if there are none
+func (r *Report) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(failures))
+	for _, f := range failures {
+		msgs = append(msgs, fmt.Sprintf("%s %s by %s: got %s, want %s (%v)", f.Object, f.Operation, f.Subject, f.Got, f.Expect, f.Err))
+	}
+	return fmt.Errorf("%d of %d rbac matrix cells failed:\n%s", len(failures), len(r.Results), strings.Join(msgs, "\n"))
+}
+
+// String returns the report as a table with a row by subject and a column by object and
+// operation. Cells with an unexpected outcome are marked with the expected one.
+func (r *Report) String() string {
+	var columns []string
+	for _, object := range r.Matrix.Objects() {
+		for _, cell := range r.Matrix.Cells(Subject{}, object) {
+			columns = append(columns, fmt.Sprintf("%s:%s", object, cell.Operation))
+		}
+	}
+	rows := make(map[Subject]map[string]string)
+	for _, result := range r.Results {
+		if rows[result.Subject] == nil {
+			rows[result.Subject] = make(map[string]string)
+		}
+		outcome := string(result.Got)
+		if result.Failed() {
+			outcome = fmt.Sprintf("%s!=%s", result.Got, result.Expect)
+		}
+		rows[result.Subject][fmt.Sprintf("%s:%s", result.Object, result.Operation)] = outcome
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ROLE/ACCESS\t%s\n", strings.ToUpper(strings.Join(columns, "\t")))
+	for _, subject := range r.Matrix.Subjects() {
+		row, ok := rows[subject]
+		if !ok {
+			continue
+		}
+		outcomes := make([]string, 0, len(columns))
+		for _, column := range columns {
+			outcome, ok := row[column]
+			if !ok {
+				outcome = "-"
+			}
+			outcomes = append(outcomes, outcome)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", subject, strings.Join(outcomes, "\t"))
+	}
+	w.Flush()
+	return buf.String()
+}
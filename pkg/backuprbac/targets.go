@@ -0,0 +1,319 @@
+package backuprbac
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+)
+
+// backupTarget shares backups through their backup share or, for clusterShare, through the
+// backup share of their cluster
+type backupTarget struct {
+	driver       backup.Driver
+	env          *Env
+	clusterShare bool
+}
+
+func (b *backupTarget) create(ctx context.Context, name string) (*ref, error) {
+	_, err := b.driver.CreateBackup(ctx, &api.BackupCreateRequest{
+		CreateMetadata:    &api.CreateMetadata{Name: name, OrgId: b.env.OrgID},
+		BackupLocation:    b.env.BackupLocation,
+		BackupLocationRef: &api.ObjectRef{Name: b.env.BackupLocation, Uid: b.env.BackupLocationUID},
+		Cluster:           b.env.Cluster,
+		ClusterRef:        &api.ObjectRef{Name: b.env.Cluster, Uid: b.env.ClusterUID},
+		Namespaces:        b.env.Namespaces,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := b.driver.WaitForBackupCompletion(ctx, name, b.env.OrgID, b.env.BackupTimeout, b.env.BackupRetryInterval); err != nil {
+		return nil, err
+	}
+	uid, err := b.driver.GetBackupUID(ctx, name, b.env.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	return &ref{name: name, uid: uid}, nil
+}
+
+// backupShare returns the backup share of a subject
+func backupShare(s *subject) *api.BackupShare {
+	return &api.BackupShare{Collaborators: []*api.BackupShare_AccessConfig{{Id: s.id, Access: api.BackupShare_AccessType(s.Access)}}}
+}
+
+func (b *backupTarget) share(ctx context.Context, r *ref, s *subject) error {
+	if b.clusterShare {
+		_, err := b.driver.ClusterUpdateBackupShare(ctx, &api.ClusterBackupShareUpdateRequest{
+			OrgId:          b.env.OrgID,
+			Name:           b.env.Cluster,
+			Uid:            b.env.ClusterUID,
+			AddBackupShare: backupShare(s),
+		})
+		return err
+	}
+	_, err := b.driver.UpdateBackupShare(ctx, &api.BackupShareUpdateRequest{
+		OrgId:       b.env.OrgID,
+		Name:        r.name,
+		Uid:         r.uid,
+		Backupshare: backupShare(s),
+	})
+	return err
+}
+
+func (b *backupTarget) run(s *subject, r *ref, operation Operation) error {
+	var err error
+	switch operation {
+	case View:
+		_, err = b.driver.InspectBackup(s.ctx, &api.BackupInspectRequest{OrgId: b.env.OrgID, Name: r.name, Uid: r.uid})
+	case Restore:
+		_, err = b.driver.CreateRestore(s.ctx, &api.RestoreCreateRequest{
+			CreateMetadata: &api.CreateMetadata{Name: r.name + "-restore", OrgId: b.env.OrgID},
+			Backup:         r.name,
+			BackupRef:      &api.ObjectRef{Name: r.name, Uid: r.uid},
+			Cluster:        b.env.RestoreCluster,
+		})
+	case Delete:
+		_, err = b.driver.DeleteBackup(s.ctx, &api.BackupDeleteRequest{OrgId: b.env.OrgID, Name: r.name, Uid: r.uid})
+	default:
+		err = fmt.Errorf("operation %s is not supported on backups", operation)
+	}
+	return err
+}
+
+func (b *backupTarget) cleanup(ctx context.Context, r *ref, s *subject) {
+	if b.clusterShare && s.Access != NoAccess {
+		_, err := b.driver.ClusterUpdateBackupShare(ctx, &api.ClusterBackupShareUpdateRequest{
+			OrgId:          b.env.OrgID,
+			Name:           b.env.Cluster,
+			Uid:            b.env.ClusterUID,
+			DelBackupShare: backupShare(s),
+		})
+		warnOnError(err, "failed to remove user %s from the backup share of cluster %s", s.user, b.env.Cluster)
+	}
+	_, err := b.driver.DeleteRestore(ctx, &api.RestoreDeleteRequest{OrgId: b.env.OrgID, Name: r.name + "-restore"})
+	warnOnError(err, "failed to delete restore %s-restore", r.name)
+	_, err = b.driver.DeleteBackup(ctx, &api.BackupDeleteRequest{OrgId: b.env.OrgID, Name: r.name, Uid: r.uid})
+	warnOnError(err, "failed to delete backup %s", r.name)
+}
+
+// credentialTarget shares cloud credentials through their ownership
+type credentialTarget struct {
+	driver backup.Driver
+	env    *Env
+}
+
+func (c *credentialTarget) create(ctx context.Context, name string) (*ref, error) {
+	r := &ref{name: name, uid: uuid.New()}
+	_, err := c.driver.CreateCloudCredential(ctx, &api.CloudCredentialCreateRequest{
+		CreateMetadata:  &api.CreateMetadata{Name: r.name, Uid: r.uid, OrgId: c.env.OrgID},
+		CloudCredential: c.env.CloudCredential,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (c *credentialTarget) shareWith(ctx context.Context, r *ref, ownership *api.Ownership) error {
+	_, err := c.driver.UpdateOwnershipCloudCredential(ctx, &api.CloudCredentialOwnershipUpdateRequest{
+		OrgId: c.env.OrgID, Name: r.name, Uid: r.uid, Ownership: ownership,
+	})
+	return err
+}
+
+func (c *credentialTarget) share(ctx context.Context, r *ref, s *subject) error {
+	return c.shareWith(ctx, r, ownership(s))
+}
+
+func (c *credentialTarget) run(s *subject, r *ref, operation Operation) error {
+	var err error
+	switch operation {
+	case View:
+		_, err = c.driver.InspectCloudCredential(s.ctx, &api.CloudCredentialInspectRequest{OrgId: c.env.OrgID, Name: r.name, Uid: r.uid})
+	case Update:
+		_, err = c.driver.UpdateCloudCredential(s.ctx, &api.CloudCredentialUpdateRequest{
+			CreateMetadata:  &api.CreateMetadata{Name: r.name, Uid: r.uid, OrgId: c.env.OrgID},
+			CloudCredential: c.env.CloudCredential,
+		})
+	case Delete:
+		_, err = c.driver.DeleteCloudCredential(s.ctx, &api.CloudCredentialDeleteRequest{OrgId: c.env.OrgID, Name: r.name, Uid: r.uid})
+	default:
+		err = fmt.Errorf("operation %s is not supported on cloud credentials", operation)
+	}
+	return err
+}
+
+func (c *credentialTarget) cleanup(ctx context.Context, r *ref, _ *subject) {
+	_, err := c.driver.DeleteCloudCredential(ctx, &api.CloudCredentialDeleteRequest{OrgId: c.env.OrgID, Name: r.name, Uid: r.uid})
+	warnOnError(err, "failed to delete cloud credential %s", r.name)
+}
+
+// locationTarget shares backup locations through their ownership, with read access to their
+// credential so that users with write access can update them
+type locationTarget struct {
+	driver      backup.Driver
+	env         *Env
+	credentials *credentialTarget
+}
+
+func (l *locationTarget) info(r *ref) *api.BackupLocationInfo {
+	info := proto.Clone(l.env.Location).(*api.BackupLocationInfo)
+	info.CloudCredential = r.credential.name
+	info.CloudCredentialRef = &api.ObjectRef{Name: r.credential.name, Uid: r.credential.uid}
+	return info
+}
+
+func (l *locationTarget) create(ctx context.Context, name string) (*ref, error) {
+	if l.env.Location == nil {
+		return nil, fmt.Errorf("backup location template is required")
+	}
+	credential, err := l.credentials.create(ctx, name+"-cred")
+	if err != nil {
+		return nil, err
+	}
+	r := &ref{name: name, uid: uuid.New(), credential: credential}
+	_, err = l.driver.CreateBackupLocation(ctx, &api.BackupLocationCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: r.name, Uid: r.uid, OrgId: l.env.OrgID},
+		BackupLocation: l.info(r),
+	})
+	if err != nil {
+		l.credentials.cleanup(ctx, credential, nil)
+		return nil, err
+	}
+	return r, nil
+}
+
+func (l *locationTarget) share(ctx context.Context, r *ref, s *subject) error {
+	read := &api.Ownership{Collaborators: []*api.Ownership_AccessConfig{{Id: s.id, Access: api.Ownership_Read}}}
+	if err := l.credentials.shareWith(ctx, r.credential, read); err != nil {
+		return err
+	}
+	_, err := l.driver.UpdateOwnershipBackupLocation(ctx, &api.BackupLocationOwnershipUpdateRequest{
+		OrgId: l.env.OrgID, Name: r.name, Uid: r.uid, Ownership: ownership(s),
+	})
+	return err
+}
+
+func (l *locationTarget) run(s *subject, r *ref, operation Operation) error {
+	var err error
+	switch operation {
+	case View:
+		_, err = l.driver.InspectBackupLocation(s.ctx, &api.BackupLocationInspectRequest{OrgId: l.env.OrgID, Name: r.name, Uid: r.uid})
+	case Update:
+		_, err = l.driver.UpdateBackupLocation(s.ctx, &api.BackupLocationUpdateRequest{
+			CreateMetadata: &api.CreateMetadata{Name: r.name, Uid: r.uid, OrgId: l.env.OrgID},
+			BackupLocation: l.info(r),
+		})
+	case Delete:
+		_, err = l.driver.DeleteBackupLocation(s.ctx, &api.BackupLocationDeleteRequest{OrgId: l.env.OrgID, Name: r.name, Uid: r.uid})
+	default:
+		err = fmt.Errorf("operation %s is not supported on backup locations", operation)
+	}
+	return err
+}
+
+func (l *locationTarget) cleanup(ctx context.Context, r *ref, s *subject) {
+	_, err := l.driver.DeleteBackupLocation(ctx, &api.BackupLocationDeleteRequest{OrgId: l.env.OrgID, Name: r.name, Uid: r.uid})
+	warnOnError(err, "failed to delete backup location %s", r.name)
+	l.credentials.cleanup(ctx, r.credential, s)
+}
+
+// ruleTarget shares rules through their ownership
+type ruleTarget struct {
+	driver backup.Driver
+	env    *Env
+}
+
+func (t *ruleTarget) create(ctx context.Context, name string) (*ref, error) {
+	r := &ref{name: name, uid: uuid.New()}
+	_, err := t.driver.CreateRule(ctx, &api.RuleCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: r.name, Uid: r.uid, OrgId: t.env.OrgID},
+		RulesInfo:      t.env.Rule,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (t *ruleTarget) share(ctx context.Context, r *ref, s *subject) error {
+	_, err := t.driver.UpdateOwnershipRule(ctx, &api.RuleOwnershipUpdateRequest{
+		OrgId: t.env.OrgID, Name: r.name, Uid: r.uid, Ownership: ownership(s),
+	})
+	return err
+}
+
+func (t *ruleTarget) run(s *subject, r *ref, operation Operation) error {
+	var err error
+	switch operation {
+	case View:
+		_, err = t.driver.InspectRule(s.ctx, &api.RuleInspectRequest{OrgId: t.env.OrgID, Name: r.name, Uid: r.uid})
+	case Update:
+		_, err = t.driver.UpdateRule(s.ctx, &api.RuleUpdateRequest{
+			CreateMetadata: &api.CreateMetadata{Name: r.name, Uid: r.uid, OrgId: t.env.OrgID},
+			RulesInfo:      t.env.Rule,
+		})
+	case Delete:
+		_, err = t.driver.DeleteRule(s.ctx, &api.RuleDeleteRequest{OrgId: t.env.OrgID, Name: r.name, Uid: r.uid})
+	default:
+		err = fmt.Errorf("operation %s is not supported on rules", operation)
+	}
+	return err
+}
+
+func (t *ruleTarget) cleanup(ctx context.Context, r *ref, _ *subject) {
+	_, err := t.driver.DeleteRule(ctx, &api.RuleDeleteRequest{OrgId: t.env.OrgID, Name: r.name, Uid: r.uid})
+	warnOnError(err, "failed to delete rule %s", r.name)
+}
+
+// policyTarget shares schedule policies through their ownership
+type policyTarget struct {
+	driver backup.Driver
+	env    *Env
+}
+
+func (p *policyTarget) create(ctx context.Context, name string) (*ref, error) {
+	r := &ref{name: name, uid: uuid.New()}
+	_, err := p.driver.CreateSchedulePolicy(ctx, &api.SchedulePolicyCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: r.name, Uid: r.uid, OrgId: p.env.OrgID},
+		SchedulePolicy: p.env.SchedulePolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (p *policyTarget) share(ctx context.Context, r *ref, s *subject) error {
+	_, err := p.driver.UpdateOwnershiSchedulePolicy(ctx, &api.SchedulePolicyOwnershipUpdateRequest{
+		OrgId: p.env.OrgID, Name: r.name, Uid: r.uid, Ownership: ownership(s),
+	})
+	return err
+}
+
+func (p *policyTarget) run(s *subject, r *ref, operation Operation) error {
+	var err error
+	switch operation {
+	case View:
+		_, err = p.driver.InspectSchedulePolicy(s.ctx, &api.SchedulePolicyInspectRequest{OrgId: p.env.OrgID, Name: r.name, Uid: r.uid})
+	case Update:
+		_, err = p.driver.UpdateSchedulePolicy(s.ctx, &api.SchedulePolicyUpdateRequest{
+			CreateMetadata: &api.CreateMetadata{Name: r.name, Uid: r.uid, OrgId: p.env.OrgID},
+			SchedulePolicy: p.env.SchedulePolicy,
+		})
+	case Delete:
+		_, err = p.driver.DeleteSchedulePolicy(s.ctx, &api.SchedulePolicyDeleteRequest{OrgId: p.env.OrgID, Name: r.name, Uid: r.uid})
+	default:
+		err = fmt.Errorf("operation %s is not supported on schedule policies", operation)
+	}
+	return err
+}
+
+func (p *policyTarget) cleanup(ctx context.Context, r *ref, _ *subject) {
+	_, err := p.driver.DeleteSchedulePolicy(ctx, &api.SchedulePolicyDeleteRequest{OrgId: p.env.OrgID, Name: r.name, Uid: r.uid})
+	warnOnError(err, "failed to delete schedule policy %s", r.name)
+}
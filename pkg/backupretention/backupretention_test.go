@@ -0,0 +1,364 @@
+package backupretention
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb"
+	"github.com/portworx/torpedo/drivers/backup/fakepxb/pxbtest"
+	"github.com/portworx/torpedo/drivers/objectstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orgID = fakepxb.DefaultOrg
+
+// clock is a time moved forward by the tests
+type clock struct {
+	sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// bucket is the objectstore of the backup location. It holds an object under the path of each
+// backup PX-Backup enumerates, locked for the lock period of its chain unless unlocked is set.
+type bucket struct {
+	objectstore.Driver
+	driver    backup.Driver
+	chainLock time.Duration
+	unlocked  bool
+	objects   map[string]time.Time
+	leaked    map[string]bool
+}
+
+// sync adds the objects of new backups and removes those of deleted ones, except leaked ones
+func (b *bucket) sync(ctx context.Context) error {
+	resp, err := b.driver.EnumerateBackup(ctx, &api.BackupEnumerateRequest{OrgId: orgID})
+	if err != nil {
+		return err
+	}
+	present := make(map[string]bool)
+	for _, backup := range resp.GetBackups() {
+		path := backup.GetBackupPath()
+		present[path] = true
+		if _, ok := b.objects[path]; !ok {
+			created, err := types.TimestampFromProto(backup.GetCreateTime())
+			if err != nil {
+				return err
+			}
+			b.objects[path] = created
+			if !b.unlocked {
+				b.objects[path] = created.Add(b.chainLock)
+			}
+		}
+	}
+	for path := range b.objects {
+		if !present[path] && !b.leaked[path] {
+			delete(b.objects, path)
+		}
+	}
+	return nil
+}
+
+func (b *bucket) ListFilesInBucket(_ *stork_api.BackupLocation, prefix string) ([]string, error) {
+	if _, ok := b.objects[prefix]; !ok {
+		return nil, nil
+	}
+	return []string{prefix + "/resources.json"}, nil
+}
+
+func (b *bucket) ValidateObjectLock(_ *stork_api.BackupLocation, prefix string, expected objectstore.ObjectLock) error {
+	lockedUntil, ok := b.objects[prefix]
+	if !ok {
+		return fmt.Errorf("no objects under %s", prefix)
+	}
+	if lockedUntil.Before(expected.RetainUntil) {
+		return fmt.Errorf("objects under %s are not locked until %v", prefix, expected.RetainUntil)
+	}
+	return nil
+}
+
+type env struct {
+	clock  *clock
+	driver backup.Driver
+	bucket *bucket
+	ctx    context.Context
+}
+
+func newEnv(t *testing.T, lockPeriod time.Duration) *env {
+	c := &clock{now: time.Date(2026, time.January, 5, 10, 0, 0, 0, time.UTC)}
+	d, _ := pxbtest.NewDriver(t, fakepxb.Config{Now: c.Now, ObjectLockPeriod: lockPeriod})
+	ctx := backup.GetCtxWithToken(fakepxb.DefaultAdminUser)
+	_, err := d.CreateCluster(ctx, &api.ClusterCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: "source-cluster", OrgId: orgID},
+		Kubeconfig:     "kubeconfig",
+	})
+	require.NoError(t, err)
+	_, err = d.CreateBackupLocation(ctx, &api.BackupLocationCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: "s3-location", OrgId: orgID},
+		BackupLocation: &api.BackupLocationInfo{Type: api.BackupLocationInfo_S3, Path: "bucket"},
+	})
+	require.NoError(t, err)
+	return &env{clock: c, driver: d, ctx: ctx, bucket: &bucket{
+		driver: d, objects: make(map[string]time.Time), leaked: make(map[string]bool),
+	}}
+}
+
+// schedule creates a schedule with the policy and returns its validator
+func (e *env) schedule(t *testing.T, name string, policy *api.SchedulePolicyInfo, lockPeriod time.Duration) *Validator {
+	_, err := e.driver.CreateSchedulePolicy(e.ctx, &api.SchedulePolicyCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: name + "-policy", OrgId: orgID},
+		SchedulePolicy: policy,
+	})
+	require.NoError(t, err)
+	_, err = e.driver.CreateBackupSchedule(e.ctx, &api.BackupScheduleCreateRequest{
+		CreateMetadata: &api.CreateMetadata{Name: name, OrgId: orgID},
+		SchedulePolicy: name + "-policy",
+		BackupLocation: "s3-location",
+		Cluster:        "source-cluster",
+		Namespaces:     []string{"mysql"},
+	})
+	require.NoError(t, err)
+	model, err := NewModel(policy, lockPeriod, time.Minute)
+	require.NoError(t, err)
+	return &Validator{
+		Driver:   e.driver,
+		Model:    model,
+		OrgID:    orgID,
+		Schedule: name,
+		Objects:  e.bucket,
+		Location: &stork_api.BackupLocation{},
+		Now:      e.clock.Now,
+	}
+}
+
+// run moves time forward in steps, observing the schedule after each of them
+func (e *env) run(t *testing.T, v *Validator, duration, step time.Duration) {
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += step {
+		e.clock.Add(step)
+		require.NoError(t, e.bucket.sync(e.ctx))
+		require.NoError(t, v.Observe(e.ctx))
+	}
+}
+
+// rows returns the fields of the rows of a timeline, without its header
+func rows(timeline string) [][]string {
+	var r [][]string
+	for _, line := range strings.Split(strings.TrimSpace(timeline), "\n")[1:] {
+		r = append(r, strings.Fields(line))
+	}
+	return r
+}
+
+func checks(v *Validator) map[string]string {
+	found := make(map[string]string)
+	for _, violation := range v.Violations() {
+		found[violation.Check] = violation.Backup
+	}
+	return found
+}
+
+func TestModel(t *testing.T) {
+	daily, err := NewModel(&api.SchedulePolicyInfo{Daily: &api.SchedulePolicyInfo_DailyPolicy{
+		Time: "10:30PM", Retain: 5, IncrementalCount: &api.SchedulePolicyInfo_IncrementalCount{Count: 6},
+	}}, 0, time.Minute)
+	require.NoError(t, err)
+	daily.Location = time.UTC
+	at := time.Date(2026, time.March, 31, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, time.April, 1, 22, 30, 0, 0, time.UTC), daily.NextTrigger(at))
+	assert.Equal(t, Full, daily.Kind(7))
+	assert.Equal(t, Incremental, daily.Kind(13))
+
+	weekly, err := NewModel(&api.SchedulePolicyInfo{Weekly: &api.SchedulePolicyInfo_WeeklyPolicy{Day: "Mon", Time: "9:00AM"}}, 0, time.Minute)
+	require.NoError(t, err)
+	weekly.Location = time.UTC
+	assert.Equal(t, time.Date(2026, time.April, 6, 9, 0, 0, 0, time.UTC), weekly.NextTrigger(at))
+
+	monthly, err := NewModel(&api.SchedulePolicyInfo{Monthly: &api.SchedulePolicyInfo_MonthlyPolicy{Date: 31, Time: "9:00AM"}}, 0, time.Minute)
+	require.NoError(t, err)
+	monthly.Location = time.UTC
+	assert.Equal(t, time.Date(2026, time.May, 31, 9, 0, 0, 0, time.UTC), monthly.NextTrigger(at))
+
+	_, err = NewModel(&api.SchedulePolicyInfo{Weekly: &api.SchedulePolicyInfo_WeeklyPolicy{Day: "Someday", Time: "9:00AM"}}, 0, 0)
+	assert.Error(t, err)
+
+	// the full backup of a locked chain is locked as long as its last incremental backup
+	locked := &Model{Policy: "interval", Interval: time.Hour, IncrementalCount: 2, ObjectLock: true, AutoDelete: true, LockPeriod: 24 * time.Hour, Grace: time.Minute}
+	start := time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)
+	var backups []Backup
+	for i := 0; i < 4; i++ {
+		backups = append(backups, Backup{Name: fmt.Sprintf("b%d", i), Created: start.Add(time.Duration(i) * time.Hour)})
+	}
+	expectations := locked.Expect(backups)
+	assert.Equal(t, start.Add(26*time.Hour), expectations[0].LockedUntil)
+	assert.Equal(t, start.Add(25*time.Hour), expectations[1].LockedUntil)
+	assert.Equal(t, "b0", expectations[2].Chain)
+	assert.Equal(t, Full, expectations[3].Kind)
+	assert.Equal(t, start.Add(26*time.Hour+time.Minute), expectations[0].DeleteBy)
+
+	missed := locked.MissedTriggers([]Backup{backups[0], backups[3]}, start.Add(4*time.Hour))
+	assert.Equal(t, []time.Time{start.Add(time.Hour), start.Add(2 * time.Hour)}, missed)
+}
+
+func TestModelTimelines(t *testing.T) {
+	start := time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	hourly := func(n int) []Backup {
+		var backups []Backup
+		for i := 0; i < n; i++ {
+			backups = append(backups, Backup{Name: fmt.Sprintf("b%d", i), Created: at(time.Duration(i) * time.Hour)})
+		}
+		return backups
+	}
+	backups := hourly(5)
+
+	for _, tc := range []struct {
+		name     string
+		model    *Model
+		expected []Expectation
+	}{
+		{
+			name:  "retain 2 with 1 incremental backup",
+			model: &Model{Policy: "interval", Interval: time.Hour, Retain: 2, IncrementalCount: 1, Grace: time.Minute},
+			expected: []Expectation{
+				{Backup: backups[0], Sequence: 0, Kind: Full, Chain: "b0", DeleteAfter: at(2 * time.Hour), DeleteBy: at(2*time.Hour + time.Minute)},
+				{Backup: backups[1], Sequence: 1, Kind: Incremental, Chain: "b0", DeleteAfter: at(3 * time.Hour), DeleteBy: at(3*time.Hour + time.Minute)},
+				{Backup: backups[2], Sequence: 2, Kind: Full, Chain: "b2", DeleteAfter: at(4 * time.Hour), DeleteBy: at(4*time.Hour + time.Minute)},
+				{Backup: backups[3], Sequence: 3, Kind: Incremental, Chain: "b2"},
+				{Backup: backups[4], Sequence: 4, Kind: Full, Chain: "b4"},
+			},
+		},
+		{
+			name:  "retain all full backups",
+			model: &Model{Policy: "interval", Interval: time.Hour, Grace: time.Minute},
+			expected: []Expectation{
+				{Backup: backups[0], Sequence: 0, Kind: Full, Chain: "b0"},
+				{Backup: backups[1], Sequence: 1, Kind: Full, Chain: "b1"},
+				{Backup: backups[2], Sequence: 2, Kind: Full, Chain: "b2"},
+				{Backup: backups[3], Sequence: 3, Kind: Full, Chain: "b3"},
+				{Backup: backups[4], Sequence: 4, Kind: Full, Chain: "b4"},
+			},
+		},
+		{
+			name:  "object lock without auto delete",
+			model: &Model{Policy: "interval", Interval: time.Hour, Retain: 1, IncrementalCount: 2, ObjectLock: true, LockPeriod: 10 * time.Hour, Grace: time.Minute},
+			expected: []Expectation{
+				{Backup: backups[0], Sequence: 0, Kind: Full, Chain: "b0", DeleteAfter: at(12 * time.Hour), LockedUntil: at(12 * time.Hour)},
+				{Backup: backups[1], Sequence: 1, Kind: Incremental, Chain: "b0", DeleteAfter: at(11 * time.Hour), LockedUntil: at(11 * time.Hour)},
+				{Backup: backups[2], Sequence: 2, Kind: Incremental, Chain: "b0", DeleteAfter: at(12 * time.Hour), LockedUntil: at(12 * time.Hour)},
+				{Backup: backups[3], Sequence: 3, Kind: Full, Chain: "b3", DeleteAfter: at(14 * time.Hour), LockedUntil: at(14 * time.Hour)},
+				{Backup: backups[4], Sequence: 4, Kind: Incremental, Chain: "b3", DeleteAfter: at(14 * time.Hour), LockedUntil: at(14 * time.Hour)},
+			},
+		},
+		{
+			name:  "object lock with auto delete",
+			model: &Model{Policy: "interval", Interval: time.Hour, IncrementalCount: 1, ObjectLock: true, AutoDelete: true, LockPeriod: 2 * time.Hour, Grace: time.Minute},
+			expected: []Expectation{
+				{Backup: backups[0], Sequence: 0, Kind: Full, Chain: "b0", DeleteAfter: at(3 * time.Hour), DeleteBy: at(3*time.Hour + time.Minute), LockedUntil: at(3 * time.Hour)},
+				{Backup: backups[1], Sequence: 1, Kind: Incremental, Chain: "b0", DeleteAfter: at(3 * time.Hour), DeleteBy: at(3*time.Hour + time.Minute), LockedUntil: at(3 * time.Hour)},
+				{Backup: backups[2], Sequence: 2, Kind: Full, Chain: "b2", DeleteAfter: at(5 * time.Hour), DeleteBy: at(5*time.Hour + time.Minute), LockedUntil: at(5 * time.Hour)},
+				{Backup: backups[3], Sequence: 3, Kind: Incremental, Chain: "b2", DeleteAfter: at(5 * time.Hour), DeleteBy: at(5*time.Hour + time.Minute), LockedUntil: at(5 * time.Hour)},
+				{Backup: backups[4], Sequence: 4, Kind: Full, Chain: "b4", DeleteAfter: at(6 * time.Hour), DeleteBy: at(6*time.Hour + time.Minute), LockedUntil: at(6 * time.Hour)},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.model.Expect(backups))
+		})
+	}
+
+	// a daily schedule which skipped a day, and is due again at 22:30 of the last day
+	daily := &Model{Policy: "daily", Hour: 22, Minute: 30, Grace: time.Minute, Location: time.UTC}
+	created := []Backup{{Name: "d0", Created: at(22*time.Hour + 30*time.Minute)}, {Name: "d2", Created: at(70*time.Hour + 30*time.Minute)}}
+	assert.Equal(t, []time.Time{at(46*time.Hour + 30*time.Minute)}, daily.MissedTriggers(created, at(94*time.Hour)))
+	assert.Equal(t, []time.Time{at(46*time.Hour + 30*time.Minute), at(94*time.Hour + 30*time.Minute)}, daily.MissedTriggers(created, at(95*time.Hour)))
+}
+
+func TestValidatorRetain(t *testing.T) {
+	e := newEnv(t, 0)
+	v := e.schedule(t, "mysql-schedule", &api.SchedulePolicyInfo{Interval: &api.SchedulePolicyInfo_IntervalPolicy{
+		Minutes: 15, Retain: 3, IncrementalCount: &api.SchedulePolicyInfo_IncrementalCount{Count: 2},
+	}}, 0)
+	e.run(t, v, 2*time.Hour, 5*time.Minute)
+	require.NoError(t, v.Err(), v.Timeline())
+	// backups every 15 minutes from 10:00 to 12:00, in chains of a full and 2 incremental backups,
+	// each deleted once 3 newer backups were created
+	assert.Equal(t, [][]string{
+		{"mysql-schedule-interval-1", "full", "mysql-schedule-interval-1", "2026-01-05T10:00:00Z", "Deleted", "2026-01-05T10:45:00Z", "2026-01-05T10:46:00Z", "-"},
+		{"mysql-schedule-interval-2", "incremental", "mysql-schedule-interval-1", "2026-01-05T10:15:00Z", "Deleted", "2026-01-05T11:00:00Z", "2026-01-05T11:01:00Z", "-"},
+		{"mysql-schedule-interval-3", "incremental", "mysql-schedule-interval-1", "2026-01-05T10:30:00Z", "Deleted", "2026-01-05T11:15:00Z", "2026-01-05T11:16:00Z", "-"},
+		{"mysql-schedule-interval-4", "full", "mysql-schedule-interval-4", "2026-01-05T10:45:00Z", "Deleted", "2026-01-05T11:30:00Z", "2026-01-05T11:31:00Z", "-"},
+		{"mysql-schedule-interval-5", "incremental", "mysql-schedule-interval-4", "2026-01-05T11:00:00Z", "Deleted", "2026-01-05T11:45:00Z", "2026-01-05T11:46:00Z", "-"},
+		{"mysql-schedule-interval-6", "incremental", "mysql-schedule-interval-4", "2026-01-05T11:15:00Z", "Deleted", "2026-01-05T12:00:00Z", "2026-01-05T12:01:00Z", "-"},
+		{"mysql-schedule-interval-7", "full", "mysql-schedule-interval-7", "2026-01-05T11:30:00Z", "Success", "-", "-", "-"},
+		{"mysql-schedule-interval-8", "incremental", "mysql-schedule-interval-7", "2026-01-05T11:45:00Z", "Success", "-", "-", "-"},
+		{"mysql-schedule-interval-9", "incremental", "mysql-schedule-interval-7", "2026-01-05T12:00:00Z", "Pending", "-", "-", "-"},
+	}, rows(v.Timeline()))
+
+	// a retained backup deleted by hand, and a deleted backup whose objects are not cleaned up
+	resp, err := e.driver.EnumerateBackup(e.ctx, &api.BackupEnumerateRequest{OrgId: orgID})
+	require.NoError(t, err)
+	require.Len(t, resp.GetBackups(), 3)
+	retained := resp.GetBackups()[0]
+	_, err = e.driver.DeleteBackup(e.ctx, &api.BackupDeleteRequest{OrgId: orgID, Name: retained.GetName(), Uid: retained.GetUid()})
+	require.NoError(t, err)
+	e.bucket.leaked[retained.GetBackupPath()] = true
+	e.run(t, v, 10*time.Minute, 5*time.Minute)
+	found := checks(v)
+	assert.Equal(t, retained.GetName(), found[EarlyDeletion])
+	assert.Equal(t, retained.GetName(), found[LeftoverObjects])
+	assert.Len(t, v.Violations(), 2)
+}
+
+func TestValidatorObjectLock(t *testing.T) {
+	lockPeriod := 30 * time.Minute
+	e := newEnv(t, lockPeriod)
+	e.bucket.chainLock = lockPeriod + 15*time.Minute
+	v := e.schedule(t, "locked-schedule", &api.SchedulePolicyInfo{
+		Interval: &api.SchedulePolicyInfo_IntervalPolicy{
+			Minutes: 15, Retain: 1, IncrementalCount: &api.SchedulePolicyInfo_IncrementalCount{Count: 1},
+		},
+		ForObjectLock: true,
+		AutoDelete:    true,
+	}, lockPeriod)
+	e.run(t, v, 3*time.Hour, 5*time.Minute)
+	require.NoError(t, v.Err(), v.Timeline())
+
+	// backups are retained past the retain count of the policy while their objects are locked: at
+	// 13:00 the chain of 12:30 is locked until 13:15 and the backup of 13:00 until 13:30, while the
+	// chain of 12:00 was unlocked at 12:45
+	resp, err := e.driver.EnumerateBackup(e.ctx, &api.BackupEnumerateRequest{OrgId: orgID})
+	require.NoError(t, err)
+	var names []string
+	for _, b := range resp.GetBackups() {
+		names = append(names, b.GetName())
+	}
+	assert.ElementsMatch(t, []string{"locked-schedule-interval-11", "locked-schedule-interval-12", "locked-schedule-interval-13"}, names)
+
+	// objects of new backups which are not locked
+	e.bucket.unlocked = true
+	e.run(t, v, 30*time.Minute, 5*time.Minute)
+	require.NotEmpty(t, v.Violations(), v.Timeline())
+	for _, violation := range v.Violations() {
+		assert.Equal(t, ObjectLock, violation.Check)
+	}
+}
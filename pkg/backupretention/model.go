@@ -0,0 +1,218 @@
+// Package backupretention validates the retention of the backups of schedules. A model computes
+// from a schedule policy when backups are due, which of them are full or incremental backups, and
+// when each of them may and must be deleted, for plain buckets and for buckets with object lock.
+// A validator polls PX-Backup and the objectstore of the backup location for the backups of a
+// schedule and reports every difference with the model, so that retention bugs surface in hours
+// rather than after weeks in the field.
+package backupretention
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+)
+
+// BackupKind is whether a backup is a full or an incremental backup
+type BackupKind string
+
+const (
+	// Full is a backup of all the data of the volumes, which starts an incremental chain
+	Full BackupKind = "full"
+	// Incremental is a backup of the changes since the previous backup of its chain
+	Incremental BackupKind = "incremental"
+)
+
+// Backup is a backup created by a schedule
+type Backup struct {
+	Name    string
+	Created time.Time
+}
+
+// Expectation is what the model expects of a backup of a schedule
+type Expectation struct {
+	Backup
+	// Sequence is the position of the backup in the backups of its schedule, from 0
+	Sequence int
+	Kind     BackupKind
+	// Chain is the name of the full backup of the incremental chain of the backup
+	Chain string
+	// DeleteAfter is the earliest time the backup may be deleted, zero while it may not
+	DeleteAfter time.Time
+	// DeleteBy is the latest time the backup must be deleted by, zero while it must not
+	DeleteBy time.Time
+	// LockedUntil is the time the objects of the backup are locked until, zero without object
+	// lock. Full backups are locked as long as the last backup of their chain.
+	LockedUntil time.Time
+}
+
+// Model is the retention model of a schedule policy. Backups past the retain count of the policy
+// are deleted once the backup which exceeds the count is created. Backups of policies for object
+// lock are not deleted for the retain count, they may be deleted once their objects are unlocked
+// and, with auto delete, must be.
+type Model struct {
+	// Policy is the kind of the policy: interval, daily, weekly or monthly
+	Policy string
+	// Interval is the interval between backups of interval policies
+	Interval time.Duration
+	// Hour and Minute are the time of day of backups of daily, weekly and monthly policies
+	Hour, Minute int
+	// Weekday is the day of backups of weekly policies
+	Weekday time.Weekday
+	// Date is the day of the month of backups of monthly policies
+	Date int
+	// Retain is the number of backups retained, all if 0
+	Retain int
+	// IncrementalCount is the number of incremental backups after each full backup
+	IncrementalCount int
+	// ObjectLock and AutoDelete are the object lock options of the policy
+	ObjectLock bool
+	AutoDelete bool
+	// LockPeriod is the retention period of the objects of the locked bucket of the backups
+	LockPeriod time.Duration
+	// Grace is how late backups may be created and deleted
+	Grace time.Duration
+	// Location is the time zone of the times of the policy, time.Local if nil
+	Location *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday, "Mon": time.Monday, "Tue": time.Tuesday, "Wed": time.Wednesday,
+	"Thu": time.Thursday, "Fri": time.Friday, "Sat": time.Saturday,
+}
+
+// NewModel returns the model of a schedule policy created by CreateIntervalSchedulePolicy,
+// CreateDailySchedulePolicy, CreateWeeklySchedulePolicy or CreateMonthlySchedulePolicy. The lock
+// period is only used by policies for object lock.
+func NewModel(policy *api.SchedulePolicyInfo, lockPeriod, grace time.Duration) (*Model, error) {
+	m := &Model{ObjectLock: policy.GetForObjectLock(), AutoDelete: policy.GetAutoDelete(), LockPeriod: lockPeriod, Grace: grace}
+	var at string
+	var retain int64
+	var incremental *api.SchedulePolicyInfo_IncrementalCount
+	switch {
+	case policy.GetInterval() != nil:
+		p := policy.GetInterval()
+		m.Policy, m.Interval, retain, incremental = "interval", time.Duration(p.GetMinutes())*time.Minute, p.GetRetain(), p.GetIncrementalCount()
+		if m.Interval <= 0 {
+			return nil, fmt.Errorf("interval of schedule policy must be positive, got %d minutes", p.GetMinutes())
+		}
+	case policy.GetDaily() != nil:
+		p := policy.GetDaily()
+		m.Policy, at, retain, incremental = "daily", p.GetTime(), p.GetRetain(), p.GetIncrementalCount()
+	case policy.GetWeekly() != nil:
+		p := policy.GetWeekly()
+		day, ok := weekdays[p.GetDay()]
+		if !ok {
+			return nil, fmt.Errorf("invalid day [%s] of weekly schedule policy", p.GetDay())
+		}
+		m.Policy, m.Weekday, at, retain, incremental = "weekly", day, p.GetTime(), p.GetRetain(), p.GetIncrementalCount()
+	case policy.GetMonthly() != nil:
+		p := policy.GetMonthly()
+		if p.GetDate() < 1 || p.GetDate() > 31 {
+			return nil, fmt.Errorf("invalid date [%d] of monthly schedule policy", p.GetDate())
+		}
+		m.Policy, m.Date, at, retain, incremental = "monthly", int(p.GetDate()), p.GetTime(), p.GetRetain(), p.GetIncrementalCount()
+	default:
+		return nil, fmt.Errorf("schedule policy has no interval, daily, weekly nor monthly policy")
+	}
+	if m.Policy != "interval" {
+		t, err := time.Parse(time.Kitchen, strings.ToUpper(at))
+		if err != nil {
+			return nil, fmt.Errorf("invalid time [%s] of %s schedule policy: %v", at, m.Policy, err)
+		}
+		m.Hour, m.Minute = t.Hour(), t.Minute()
+	}
+	m.Retain, m.IncrementalCount = int(retain), int(incremental.GetCount())
+	return m, nil
+}
+
+func (m *Model) location() *time.Location {
+	if m.Location == nil {
+		return time.Local
+	}
+	return m.Location
+}
+
+// NextTrigger returns the first time the schedule is due after a backup created at the given time
+func (m *Model) NextTrigger(after time.Time) time.Time {
+	if m.Policy == "interval" {
+		return after.Add(m.Interval)
+	}
+	t := after.In(m.location())
+	day := time.Date(t.Year(), t.Month(), t.Day(), m.Hour, m.Minute, 0, 0, m.location())
+	// months without the date of monthly policies are skipped, so look a bit over a year ahead
+	for i := 0; i < 400; i++ {
+		candidate := day.AddDate(0, 0, i)
+		if !candidate.After(after) {
+			continue
+		}
+		switch {
+		case m.Policy == "weekly" && candidate.Weekday() != m.Weekday:
+		case m.Policy == "monthly" && candidate.Day() != m.Date:
+		default:
+			return candidate
+		}
+	}
+	return time.Time{}
+}
+
+// Kind returns whether the backup at a position in its schedule is a full or an incremental backup
+func (m *Model) Kind(sequence int) BackupKind {
+	if sequence%(m.IncrementalCount+1) == 0 {
+		return Full
+	}
+	return Incremental
+}
+
+// Expect returns the expectations of the backups of a schedule, which are all backups created by
+// the schedule so far, deleted or not, from the oldest
+func (m *Model) Expect(backups []Backup) []Expectation {
+	expectations := make([]Expectation, len(backups))
+	for i, b := range backups {
+		e := Expectation{Backup: b, Sequence: i, Kind: m.Kind(i)}
+		e.Chain = backups[i-i%(m.IncrementalCount+1)].Name
+		switch {
+		case m.ObjectLock:
+			e.LockedUntil = b.Created.Add(m.LockPeriod)
+		case m.Retain > 0 && i+m.Retain < len(backups):
+			e.DeleteAfter = backups[i+m.Retain].Created
+			e.DeleteBy = e.DeleteAfter.Add(m.Grace)
+		}
+		expectations[i] = e
+	}
+	if !m.ObjectLock {
+		return expectations
+	}
+	// full backups are locked as long as their chain
+	for i := range expectations {
+		full := &expectations[i-i%(m.IncrementalCount+1)]
+		if expectations[i].LockedUntil.After(full.LockedUntil) {
+			full.LockedUntil = expectations[i].LockedUntil
+		}
+	}
+	for i := range expectations {
+		e := &expectations[i]
+		e.DeleteAfter = e.LockedUntil
+		if m.AutoDelete {
+			e.DeleteBy = e.LockedUntil.Add(m.Grace)
+		}
+	}
+	return expectations
+}
+
+// MissedTriggers returns the times the schedule was due at without creating a backup within the
+// grace period, between the backups of the schedule, from the oldest, and up to now
+func (m *Model) MissedTriggers(backups []Backup, now time.Time) []time.Time {
+	var missed []time.Time
+	for i, b := range backups {
+		until := now
+		if i+1 < len(backups) {
+			until = backups[i+1].Created
+		}
+		for due := m.NextTrigger(b.Created); !due.IsZero() && due.Add(m.Grace).Before(until); due = m.NextTrigger(due) {
+			missed = append(missed, due)
+		}
+	}
+	return missed
+}
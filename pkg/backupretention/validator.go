@@ -0,0 +1,273 @@
+package backupretention
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/objectstore"
+	"github.com/portworx/torpedo/pkg/log"
+)
+
+// Checks of the validator, which violations are reported for
+const (
+	// EarlyDeletion is for backups deleted before the model allows it
+	EarlyDeletion = "early-deletion"
+	// LateDeletion is for backups not deleted when the model requires it
+	LateDeletion = "late-deletion"
+	// MissedTrigger is for times the schedule was due at without creating a backup
+	MissedTrigger = "missed-trigger"
+	// MissingObjects is for successful backups without objects in the objectstore
+	MissingObjects = "missing-objects"
+	// ObjectLock is for objects of backups which are not locked long enough
+	ObjectLock = "object-lock"
+	// LeftoverObjects is for deleted backups whose objects are still in the objectstore
+	LeftoverObjects = "leftover-objects"
+)
+
+// Violation is a difference between the model and the backups of the schedule
+type Violation struct {
+	// Backup is the name of the backup, or the time the schedule was due at for missed triggers
+	Backup string
+	Check  string
+	Detail string
+	// At is when the violation was observed
+	At time.Time
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("[%s] %s: %s", v.Check, v.Backup, v.Detail)
+}
+
+// tracked is a backup of the schedule seen by the validator
+type tracked struct {
+	Backup
+	path   string
+	status api.BackupInfo_StatusInfo_Status
+	// gone is when the backup was first seen deleted or being deleted, zero if it was not
+	gone time.Time
+	// objectsFound and objectsCleaned are set once the objects of the backup are validated
+	objectsFound   bool
+	objectsCleaned bool
+	// lockedUntil is the retain until time the locks of the objects were last validated for
+	lockedUntil time.Time
+}
+
+// Validator compares the backups of a schedule with the retention model of its policy
+type Validator struct {
+	Driver   backup.Driver
+	Model    *Model
+	OrgID    string
+	Schedule string
+	// Objects is the objectstore driver of Location, the backup location of the schedule. The
+	// objects of backups are only validated if both are set.
+	Objects  objectstore.Driver
+	Location *stork_api.BackupLocation
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+
+	backups    map[string]*tracked
+	violations []Violation
+	reported   map[string]bool
+}
+
+func (v *Validator) now() time.Time {
+	if v.Now == nil {
+		return time.Now()
+	}
+	return v.Now()
+}
+
+// Observe enumerates the backups of the schedule and their objects once and records the
+// violations of the model. The context is the one of the owner of the schedule.
+func (v *Validator) Observe(ctx context.Context) error {
+	if v.backups == nil {
+		v.backups, v.reported = make(map[string]*tracked), make(map[string]bool)
+	}
+	resp, err := v.Driver.EnumerateBackup(ctx, &api.BackupEnumerateRequest{OrgId: v.OrgID})
+	if err != nil {
+		return fmt.Errorf("failed to enumerate backups of schedule %s: %v", v.Schedule, err)
+	}
+	now := v.now()
+	present := make(map[string]bool)
+	for _, b := range resp.GetBackups() {
+		if b.GetBackupSchedule().GetName() != v.Schedule {
+			continue
+		}
+		t, ok := v.backups[b.GetName()]
+		if !ok {
+			created, err := types.TimestampFromProto(b.GetCreateTime())
+			if err != nil {
+				return fmt.Errorf("invalid create time of backup %s: %v", b.GetName(), err)
+			}
+			t = &tracked{Backup: Backup{Name: b.GetName(), Created: created}, path: b.GetBackupPath()}
+			v.backups[b.GetName()] = t
+		}
+		t.status = b.GetStatus().GetStatus()
+		if t.status != api.BackupInfo_StatusInfo_Deleting {
+			present[b.GetName()] = true
+		}
+	}
+	for name, t := range v.backups {
+		if !present[name] && t.gone.IsZero() {
+			t.gone = now
+		}
+	}
+
+	backups := v.sorted()
+	for _, e := range v.Model.Expect(backups) {
+		if err := v.check(v.backups[e.Name], e, now); err != nil {
+			return err
+		}
+	}
+	for _, due := range v.Model.MissedTriggers(backups, now) {
+		v.report(due.Format(time.RFC3339), MissedTrigger, now, "schedule was due without creating a backup within %v", v.Model.Grace)
+	}
+	return nil
+}
+
+// sorted returns the backups of the schedule seen so far, from the oldest
+func (v *Validator) sorted() []Backup {
+	backups := make([]Backup, 0, len(v.backups))
+	for _, t := range v.backups {
+		backups = append(backups, t.Backup)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].Created.Equal(backups[j].Created) {
+			return backups[i].Name < backups[j].Name
+		}
+		return backups[i].Created.Before(backups[j].Created)
+	})
+	return backups
+}
+
+// check validates a backup against its expectation
+func (v *Validator) check(t *tracked, e Expectation, now time.Time) error {
+	if !t.gone.IsZero() {
+		if e.DeleteAfter.IsZero() || t.gone.Before(e.DeleteAfter) {
+			detail := "deleted while it is retained"
+			if !e.LockedUntil.IsZero() {
+				detail = fmt.Sprintf("deleted while its objects are locked until %v", e.LockedUntil)
+			} else if !e.DeleteAfter.IsZero() {
+				detail = fmt.Sprintf("deleted before %v", e.DeleteAfter)
+			}
+			v.report(t.Name, EarlyDeletion, now, "%s %s", e.Kind, detail)
+		}
+	} else if !e.DeleteBy.IsZero() && now.After(e.DeleteBy) {
+		v.report(t.Name, LateDeletion, now, "%s backup not deleted by %v", e.Kind, e.DeleteBy)
+	}
+	if v.Objects == nil || v.Location == nil || t.path == "" {
+		return nil
+	}
+	switch {
+	case !t.gone.IsZero():
+		// objects of deleted backups are cleaned up within the grace period
+		if t.objectsCleaned || now.Before(t.gone.Add(v.Model.Grace)) {
+			return nil
+		}
+		files, err := v.Objects.ListFilesInBucket(v.Location, t.path)
+		if err != nil {
+			return fmt.Errorf("failed to list objects of backup %s: %v", t.Name, err)
+		}
+		if len(files) > 0 {
+			v.report(t.Name, LeftoverObjects, now, "%d objects left under %s after deletion", len(files), t.path)
+		}
+		t.objectsCleaned = true
+	case t.status == api.BackupInfo_StatusInfo_Success || t.status == api.BackupInfo_StatusInfo_PartialSuccess:
+		if !t.objectsFound {
+			files, err := v.Objects.ListFilesInBucket(v.Location, t.path)
+			if err != nil {
+				return fmt.Errorf("failed to list objects of backup %s: %v", t.Name, err)
+			}
+			if len(files) == 0 {
+				v.report(t.Name, MissingObjects, now, "no objects under %s", t.path)
+				return nil
+			}
+			t.objectsFound = true
+		}
+		// locks are validated again when the chain of a full backup grows
+		if !e.LockedUntil.IsZero() && e.LockedUntil.After(t.lockedUntil) {
+			err := v.Objects.ValidateObjectLock(v.Location, t.path, objectstore.ObjectLock{RetainUntil: e.LockedUntil.Add(-v.Model.Grace)})
+			if err != nil {
+				v.report(t.Name, ObjectLock, now, "%s backup: %v", e.Kind, err)
+			}
+			t.lockedUntil = e.LockedUntil
+		}
+	}
+	return nil
+}
+
+// report records a violation, once by backup and check
+func (v *Validator) report(name, check string, now time.Time, format string, args ...interface{}) {
+	key := name + "/" + check
+	if v.reported[key] {
+		return
+	}
+	v.reported[key] = true
+	violation := Violation{Backup: name, Check: check, Detail: fmt.Sprintf(format, args...), At: now}
+	log.Errorf("Retention of schedule %s: %s", v.Schedule, violation)
+	v.violations = append(v.violations, violation)
+}
+
+// Violations returns the violations observed so far
+func (v *Validator) Violations() []Violation {
+	return v.violations
+}
+
+// Err returns an error listing the violations observed so far, nil if there are none
+func (v *Validator) Err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(v.violations))
+	for _, violation := range v.violations {
+		msgs = append(msgs, violation.String())
+	}
+	return fmt.Errorf("retention of schedule %s violates its model:\n%s", v.Schedule, strings.Join(msgs, "\n"))
+}
+
+// Run observes the schedule at every interval for the duration and returns the violations
+func (v *Validator) Run(ctx context.Context, duration, interval time.Duration) error {
+	for deadline := time.Now().Add(duration); ; time.Sleep(interval) {
+		if err := v.Observe(ctx); err != nil {
+			return err
+		}
+		if !time.Now().Add(interval).Before(deadline) {
+			break
+		}
+	}
+	log.Infof("Retention timeline of schedule %s:\n%s", v.Schedule, v.Timeline())
+	return v.Err()
+}
+
+// Timeline returns the backups of the schedule seen so far as a table, with their expectations
+func (v *Validator) Timeline() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BACKUP\tKIND\tCHAIN\tCREATED\tSTATUS\tDELETE AFTER\tDELETE BY\tLOCKED UNTIL")
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+	for _, e := range v.Model.Expect(v.sorted()) {
+		t := v.backups[e.Name]
+		status := t.status.String()
+		if !t.gone.IsZero() {
+			status = "Deleted"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Name, e.Kind, e.Chain, formatTime(e.Created), status,
+			formatTime(e.DeleteAfter), formatTime(e.DeleteBy), formatTime(e.LockedUntil))
+	}
+	w.Flush()
+	return buf.String()
+}
@@ -0,0 +1,233 @@
+// Package claims arbitrates which longevity triggers may run at the same time.
+//
+// Each trigger declares the resources it disrupts or depends on as claims. Two triggers run
+// concurrently only when none of their claims conflict. A claim covers all instances of a
+// resource, one specific instance, or "any" instance. A trigger which declares an "any"
+// claim narrows it once it has picked the instance it works on, by acquiring a claim on that
+// instance. Triggers must hold at most one narrowed claim at a time so that they can never
+// wait on each other.
+package claims
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Well known resources claimed by triggers
+const (
+	// Node is claimed by triggers which reboot, crash or otherwise disrupt nodes
+	Node = "node"
+	// Pool is claimed by triggers which resize, add to or rebalance storage pools
+	Pool = "pool"
+	// KvdbLeader is claimed by triggers which disrupt the kvdb leader or force kvdb failovers
+	KvdbLeader = "kvdb-leader"
+	// BackupServer is claimed by triggers which use or disrupt the backup server
+	BackupServer = "backup-server"
+	// App is claimed by triggers which use the deployed applications. Triggers which deploy or
+	// delete applications claim it exclusively, all others share it.
+	App = "app"
+)
+
+const (
+	// AllInstances is the instance of claims which cover every instance of the resource
+	AllInstances = ""
+	// AnyInstance is the instance of claims which cover a single instance picked by the trigger
+	AnyInstance = "any"
+)
+
+// Mode is the mode of a claim
+type Mode int
+
+const (
+	// Exclusive claims conflict with every other claim on the same instances
+	Exclusive Mode = iota
+	// Shared claims only conflict with exclusive claims on the same instances
+	Shared
+)
+
+// String returns the name of the mode
+func (m Mode) String() string {
+	if m == Shared {
+		return "shared"
+	}
+	return "exclusive"
+}
+
+// Claim is a claim of a trigger on a resource
+type Claim struct {
+	// Resource claimed, e.g. Node or Pool
+	Resource string
+	// Instance of the resource claimed: AllInstances, AnyInstance or the name of an instance
+	Instance string
+	// Mode of the claim
+	Mode Mode
+}
+
+// String returns the claim in the form accepted by Parse
+func (c Claim) String() string {
+	s := c.Resource
+	if c.Instance != AllInstances {
+		s += "/" + c.Instance
+	}
+	return s + ":" + c.Mode.String()
+}
+
+// ConflictsWith returns true if the two claims cannot be held by different triggers at the same time
+func (c Claim) ConflictsWith(other Claim) bool {
+	if c.Resource != other.Resource {
+		return false
+	}
+	if c.Mode == Shared && other.Mode == Shared {
+		return false
+	}
+	switch {
+	case c.Instance == AllInstances || other.Instance == AllInstances:
+		return true
+	case c.Instance == AnyInstance || other.Instance == AnyInstance:
+		// the instance is only known once the claim is narrowed
+		return false
+	default:
+		return c.Instance == other.Instance
+	}
+}
+
+// Parse parses a claim of the form <resource>[/<instance>][:shared|exclusive]. Claims without
+// an instance cover all instances and claims without a mode are exclusive.
+func Parse(s string) (Claim, error) {
+	c := Claim{Mode: Exclusive}
+	rest := s
+	if i := strings.LastIndex(rest, ":"); i != -1 {
+		switch rest[i+1:] {
+		case "shared":
+			c.Mode = Shared
+		case "exclusive":
+		default:
+			return Claim{}, fmt.Errorf("invalid mode %q in claim %q, expected shared or exclusive", rest[i+1:], s)
+		}
+		rest = rest[:i]
+	}
+	c.Resource, c.Instance, _ = strings.Cut(rest, "/")
+	if c.Resource == "" {
+		return Claim{}, fmt.Errorf("claim %q has no resource", s)
+	}
+	if c.Instance == "*" {
+		c.Instance = AllInstances
+	}
+	return c, nil
+}
+
+type holding struct {
+	owner string
+	claim Claim
+}
+
+// Manager keeps track of the claims held by running triggers
+type Manager struct {
+	sync.Mutex
+	cond *sync.Cond
+	held []*holding
+}
+
+// NewManager returns a manager with no claims held
+func NewManager() *Manager {
+	m := &Manager{}
+	m.cond = sync.NewCond(&m.Mutex)
+	return m
+}
+
+// Acquire blocks till the owner can hold all of the given claims at once, and returns a
+// function releasing them
+func (m *Manager) Acquire(owner string, claims ...Claim) (release func()) {
+	m.Lock()
+	defer m.Unlock()
+	for m.conflictLocked(owner, claims) != nil {
+		m.cond.Wait()
+	}
+	return m.holdLocked(owner, claims)
+}
+
+// TryAcquire acquires the given claims if none of them conflict with claims held by other
+// owners. It never blocks.
+func (m *Manager) TryAcquire(owner string, claims ...Claim) (release func(), ok bool) {
+	m.Lock()
+	defer m.Unlock()
+	if m.conflictLocked(owner, claims) != nil {
+		return nil, false
+	}
+	return m.holdLocked(owner, claims), true
+}
+
+// Conflicts returns the owners holding claims which conflict with the given claims
+func (m *Manager) Conflicts(owner string, claims ...Claim) []string {
+	m.Lock()
+	defer m.Unlock()
+	var owners []string
+	seen := make(map[string]bool)
+	for _, h := range m.held {
+		if h.owner == owner || seen[h.owner] {
+			continue
+		}
+		for _, c := range claims {
+			if c.ConflictsWith(h.claim) {
+				owners = append(owners, h.owner)
+				seen[h.owner] = true
+				break
+			}
+		}
+	}
+	return owners
+}
+
+// Held returns the claims currently held by the given owner
+func (m *Manager) Held(owner string) []Claim {
+	m.Lock()
+	defer m.Unlock()
+	var claims []Claim
+	for _, h := range m.held {
+		if h.owner == owner {
+			claims = append(claims, h.claim)
+		}
+	}
+	return claims
+}
+
+func (m *Manager) conflictLocked(owner string, claims []Claim) *holding {
+	for _, h := range m.held {
+		if h.owner == owner {
+			continue
+		}
+		for _, c := range claims {
+			if c.ConflictsWith(h.claim) {
+				return h
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) holdLocked(owner string, claims []Claim) func() {
+	holdings := make([]*holding, 0, len(claims))
+	for _, c := range claims {
+		h := &holding{owner: owner, claim: c}
+		holdings = append(holdings, h)
+		m.held = append(m.held, h)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.Lock()
+			defer m.Unlock()
+			for _, h := range holdings {
+				for i := range m.held {
+					if m.held[i] == h {
+						m.held = append(m.held[:i], m.held[i+1:]...)
+						break
+					}
+				}
+			}
+			m.cond.Broadcast()
+		})
+	}
+}
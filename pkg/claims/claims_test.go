@@ -0,0 +1,84 @@
+package claims
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParse(t *testing.T, s string) Claim {
+	c, err := Parse(s)
+	require.NoError(t, err)
+	return c
+}
+
+func TestParseAndConflicts(t *testing.T) {
+	assert.Equal(t, Claim{Resource: Node, Instance: AllInstances, Mode: Exclusive}, mustParse(t, "node"))
+	assert.Equal(t, Claim{Resource: Pool, Instance: AnyInstance, Mode: Shared}, mustParse(t, "pool/any:shared"))
+	assert.Equal(t, Claim{Resource: Node, Instance: "node-a", Mode: Exclusive}, mustParse(t, "node/node-a:exclusive"))
+	assert.Equal(t, "node/node-a:exclusive", mustParse(t, "node/node-a").String())
+	_, err := Parse("node:sometimes")
+	assert.Error(t, err)
+	_, err = Parse(":shared")
+	assert.Error(t, err)
+
+	for _, tc := range []struct {
+		a, b      string
+		conflicts bool
+	}{
+		{"node", "pool", false},
+		{"node:shared", "node:shared", false},
+		{"node", "node:shared", true},
+		{"node", "node/node-a:shared", true},
+		{"node/any", "node/any", false},
+		{"node/any", "node/node-a", false},
+		{"node/node-a", "node/node-b", false},
+		{"node/node-a", "node/node-a:shared", true},
+	} {
+		assert.Equal(t, tc.conflicts, mustParse(t, tc.a).ConflictsWith(mustParse(t, tc.b)), "%s vs %s", tc.a, tc.b)
+		assert.Equal(t, tc.conflicts, mustParse(t, tc.b).ConflictsWith(mustParse(t, tc.a)), "%s vs %s", tc.b, tc.a)
+	}
+}
+
+func TestManager(t *testing.T) {
+	m := NewManager()
+
+	// pool resize on node A runs alongside app task down on node B
+	releaseResize := m.Acquire("poolResizeDisk", mustParse(t, "pool"), mustParse(t, "node/any"), mustParse(t, "app:shared"))
+	releaseTaskDown, ok := m.TryAcquire("appTaskDown", mustParse(t, "node/any"), mustParse(t, "app:shared"))
+	require.True(t, ok)
+	releaseNodeA, ok := m.TryAcquire("poolResizeDisk", mustParse(t, "node/node-a"))
+	require.True(t, ok)
+	releaseNodeB, ok := m.TryAcquire("appTaskDown", mustParse(t, "node/node-b"))
+	require.True(t, ok)
+	_, ok = m.TryAcquire("appTaskDown", mustParse(t, "node/node-a"))
+	assert.False(t, ok, "node A is claimed by pool resize")
+	_, ok = m.TryAcquire("poolAddDisk", mustParse(t, "pool"))
+	assert.False(t, ok)
+	assert.Equal(t, []string{"poolResizeDisk"}, m.Conflicts("poolAddDisk", mustParse(t, "pool")))
+
+	acquired := make(chan struct{})
+	go func() {
+		release := m.Acquire("deployApps", mustParse(t, "app"))
+		close(acquired)
+		release()
+	}()
+	releaseNodeA()
+	releaseResize()
+	releaseNodeB()
+	select {
+	case <-acquired:
+		t.Fatal("deployApps should wait for all shared app claims to be released")
+	case <-time.After(50 * time.Millisecond):
+	}
+	releaseTaskDown()
+	releaseTaskDown()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("deployApps should acquire its claims once they are released")
+	}
+	assert.Empty(t, m.Held("poolResizeDisk"))
+}
@@ -0,0 +1,223 @@
+// Package eventlog writes the events of test triggers to a durable JSON-lines file.
+//
+// Each line is one Event. The schema is versioned by SchemaVersion: fields are only ever added
+// within a version, so tools reading the log can rely on the fields they know about.
+package eventlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	torpedoerrors "github.com/portworx/torpedo/pkg/errors"
+)
+
+// SchemaVersion is the version of the schema of the events written by this package
+const SchemaVersion = 1
+
+// TargetKind is the kind of resource targeted by a trigger
+type TargetKind string
+
+const (
+	// TargetNode is a node targeted by a trigger
+	TargetNode TargetKind = "node"
+	// TargetVolume is a volume targeted by a trigger
+	TargetVolume TargetKind = "volume"
+	// TargetApp is an application targeted by a trigger
+	TargetApp TargetKind = "app"
+	// TargetNamespace is a namespace targeted by a trigger
+	TargetNamespace TargetKind = "namespace"
+	// TargetPool is a storage pool targeted by a trigger
+	TargetPool TargetKind = "pool"
+)
+
+// Target is a resource targeted by a trigger
+type Target struct {
+	Kind TargetKind `json:"kind"`
+	Name string     `json:"name"`
+}
+
+// String returns the target as kind/name
+func (t Target) String() string {
+	return string(t.Kind) + "/" + t.Name
+}
+
+// PhaseKind is the kind of a phase of a trigger
+type PhaseKind string
+
+const (
+	// PhaseAction is a phase in which the trigger disrupts or changes the cluster
+	PhaseAction PhaseKind = "action"
+	// PhaseValidation is a phase in which the trigger validates the cluster or the applications
+	PhaseValidation PhaseKind = "validation"
+)
+
+// PhaseKindOf returns the kind of a phase from its name. Phases which validate are named so.
+func PhaseKindOf(name string) PhaseKind {
+	if strings.Contains(strings.ToLower(name), "validat") {
+		return PhaseValidation
+	}
+	return PhaseAction
+}
+
+// Phase is a step taken by a trigger
+type Phase struct {
+	Name            string    `json:"name"`
+	Kind            PhaseKind `json:"kind"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Failed          bool      `json:"failed"`
+}
+
+// ErrorClass is the classification of an error
+type ErrorClass string
+
+const (
+	// ClassTimeout are errors of operations which did not complete in time
+	ClassTimeout ErrorClass = "timeout"
+	// ClassNotFound are errors about missing resources
+	ClassNotFound ErrorClass = "not-found"
+	// ClassNotSupported are errors of operations not supported by a driver
+	ClassNotSupported ErrorClass = "not-supported"
+	// ClassConnection are errors reaching nodes or endpoints
+	ClassConnection ErrorClass = "connection"
+	// ClassValidation are errors of validations
+	ClassValidation ErrorClass = "validation"
+	// ClassUnknown are all other errors
+	ClassUnknown ErrorClass = "unknown"
+)
+
+// Error is an error of a trigger
+type Error struct {
+	Message string     `json:"message"`
+	Class   ErrorClass `json:"class"`
+	// Phase in which the error occurred, if any
+	Phase string `json:"phase,omitempty"`
+}
+
+// Classify returns the class of the given error
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ClassUnknown
+	}
+	var notSupported *torpedoerrors.ErrNotSupported
+	if errors.As(err, &notSupported) {
+		return ClassNotSupported
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "timed out") || strings.Contains(msg, "timeout") ||
+		strings.Contains(msg, "deadline exceeded"):
+		return ClassTimeout
+	case strings.Contains(msg, "not supported"):
+		return ClassNotSupported
+	case strings.Contains(msg, "connection refused") || strings.Contains(msg, "no route to host") ||
+		strings.Contains(msg, "connection reset") || strings.Contains(msg, "unreachable"):
+		return ClassConnection
+	case strings.Contains(msg, "not found") || strings.Contains(msg, "does not exist"):
+		return ClassNotFound
+	case strings.Contains(msg, "validat"):
+		return ClassValidation
+	}
+	return ClassUnknown
+}
+
+// Result is the result of an event
+type Result string
+
+const (
+	// ResultPassed is the result of events without errors
+	ResultPassed Result = "passed"
+	// ResultFailed is the result of events with errors
+	ResultFailed Result = "failed"
+)
+
+// Event is a single run of a trigger
+type Event struct {
+	SchemaVersion   int       `json:"schemaVersion"`
+	RunID           string    `json:"runId"`
+	ID              string    `json:"id"`
+	Trigger         string    `json:"trigger"`
+	ChaosLevel      int       `json:"chaosLevel"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Result          Result    `json:"result"`
+	Targets         []Target  `json:"targets"`
+	Phases          []Phase   `json:"phases"`
+	Errors          []Error   `json:"errors"`
+}
+
+// Writer appends events to an event log file. It is safe for concurrent use.
+type Writer struct {
+	runID string
+
+	sync.Mutex
+	f *os.File
+}
+
+// NewWriter opens the event log at the given path for appending events of the given run
+func NewWriter(path, runID string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log %s: %v", path, err)
+	}
+	return &Writer{runID: runID, f: f}, nil
+}
+
+// Path returns the path of the event log
+func (w *Writer) Path() string {
+	return w.f.Name()
+}
+
+// Write appends the given event to the log, filling in the schema version, run ID, result and
+// durations
+func (w *Writer) Write(e Event) error {
+	e.SchemaVersion = SchemaVersion
+	e.RunID = w.runID
+	if !e.End.IsZero() {
+		e.DurationSeconds = e.End.Sub(e.Start).Seconds()
+	}
+	for i := range e.Phases {
+		if !e.Phases[i].End.IsZero() {
+			e.Phases[i].DurationSeconds = e.Phases[i].End.Sub(e.Phases[i].Start).Seconds()
+		}
+	}
+	e.Result = ResultPassed
+	if len(e.Errors) > 0 {
+		e.Result = ResultFailed
+	}
+	// empty lists are written as [] rather than null to keep the schema simple to consume
+	if e.Targets == nil {
+		e.Targets = []Target{}
+	}
+	if e.Phases == nil {
+		e.Phases = []Phase{}
+	}
+	if e.Errors == nil {
+		e.Errors = []Error{}
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	w.Lock()
+	defer w.Unlock()
+	if _, err = w.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to event log %s: %v", w.f.Name(), err)
+	}
+	return w.f.Sync()
+}
+
+// Close closes the event log
+func (w *Writer) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	return w.f.Close()
+}
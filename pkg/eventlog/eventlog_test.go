@@ -0,0 +1,72 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	torpedoerrors "github.com/portworx/torpedo/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	for err, class := range map[error]ErrorClass{
+		fmt.Errorf("timed out waiting for node-1 to be up"):                      ClassTimeout,
+		fmt.Errorf("wrapped: %w", &torpedoerrors.ErrNotSupported{Type: "netns"}): ClassNotSupported,
+		fmt.Errorf("dial tcp 10.0.0.1:9001: connect: connection refused"):        ClassConnection,
+		fmt.Errorf("volume pvc-123 not found"):                                   ClassNotFound,
+		fmt.Errorf("failed to validate app mysql"):                               ClassValidation,
+		fmt.Errorf("something else"):                                             ClassUnknown,
+	} {
+		assert.Equal(t, class, Classify(err), err.Error())
+	}
+	assert.Equal(t, PhaseValidation, PhaseKindOf("validate apps after reboot"))
+	assert.Equal(t, PhaseAction, PhaseKindOf("reboot node: node-1"))
+}
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	w, err := NewWriter(path, "run-1")
+	require.NoError(t, err)
+
+	start := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, w.Write(Event{ID: "1", Trigger: "deployApps", Start: start, End: start.Add(time.Minute)}))
+	require.NoError(t, w.Write(Event{
+		ID:         "2",
+		Trigger:    "rebootNode",
+		ChaosLevel: 8,
+		Start:      start,
+		End:        start.Add(10 * time.Minute),
+		Targets:    []Target{{Kind: TargetNode, Name: "node-1"}},
+		Phases: []Phase{
+			{Name: "reboot node: node-1", Kind: PhaseAction, Start: start, End: start.Add(2 * time.Minute)},
+			{Name: "validate apps", Kind: PhaseValidation, Start: start.Add(2 * time.Minute), End: start.Add(10 * time.Minute), Failed: true},
+		},
+		Errors: []Error{{Message: "app mysql is not running", Class: ClassValidation, Phase: "validate apps"}},
+	}))
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var passed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &passed))
+	assert.Equal(t, float64(SchemaVersion), passed["schemaVersion"])
+	assert.Equal(t, "run-1", passed["runId"])
+	assert.Equal(t, "passed", passed["result"])
+	assert.Equal(t, []interface{}{}, passed["errors"])
+
+	var failed Event
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &failed))
+	assert.Equal(t, ResultFailed, failed.Result)
+	assert.Equal(t, 600.0, failed.DurationSeconds)
+	assert.Equal(t, 480.0, failed.Phases[1].DurationSeconds)
+	assert.Equal(t, "node/node-1", failed.Targets[0].String())
+}
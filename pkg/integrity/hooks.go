@@ -0,0 +1,157 @@
+package integrity
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Files prints the SHA-256 of the files of the persistent volumes mounted in a pod, by claim and
+// path. It is used for apps without a more specific hook.
+var Files Hook = &filesHook{}
+
+// MySQL prints the checksums of the tables of the user databases of MySQL
+var MySQL Hook = &dbHook{
+	name:   "mysql",
+	apps:   []string{"mysql"},
+	images: []string{"mysql", "mariadb"},
+	script: `export MYSQL_PWD="$MYSQL_ROOT_PASSWORD"
+for t in $(mysql -uroot -N -B -e "SELECT CONCAT(table_schema, '.', table_name) FROM information_schema.tables WHERE table_type = 'BASE TABLE' AND table_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')"); do
+  mysql -uroot -N -B -e "CHECKSUM TABLE $t EXTENDED" | awk '{print $2" "$1}'
+done`,
+}
+
+// Postgres prints the row count and MD5 of the ordered rows of the tables of all databases of Postgres
+var Postgres Hook = &dbHook{
+	name:   "postgres",
+	apps:   []string{"postgres"},
+	images: []string{"postgres"},
+	script: `u="${POSTGRES_USER:-postgres}"
+for d in $(psql -U "$u" -At -c "SELECT datname FROM pg_database WHERE NOT datistemplate"); do
+  for t in $(psql -U "$u" -d "$d" -At -c "SELECT schemaname || '.' || tablename FROM pg_tables WHERE schemaname NOT IN ('pg_catalog', 'information_schema')"); do
+    echo "$(psql -U "$u" -d "$d" -At -c "SELECT count(*) || ':' || coalesce(md5(string_agg(r::text, '' ORDER BY r::text)), '') FROM $t r") $d.$t"
+  done
+done`,
+}
+
+// MongoDB prints the hashes of the collections of the user databases of MongoDB
+var MongoDB Hook = &dbHook{
+	name:   "mongodb",
+	apps:   []string{"mongodb", "mongo"},
+	images: []string{"mongodb", "mongo"},
+	script: `auth=""
+if [ -n "$MONGODB_ROOT_PASSWORD" ]; then auth="-u root -p $MONGODB_ROOT_PASSWORD --authenticationDatabase admin"; fi
+mongo --quiet $auth --eval '
+db.adminCommand({listDatabases: 1}).databases.forEach(function(d) {
+  if (["admin", "config", "local"].indexOf(d.name) >= 0) return;
+  var h = db.getSiblingDB(d.name).runCommand({dbHash: 1});
+  for (var c in h.collections) print(h.collections[c] + " " + d.name + "." + c);
+})'`,
+}
+
+// Cassandra prints the SHA-256 of the sorted rows of the tables of the user keyspaces of Cassandra
+// and ScyllaDB
+var Cassandra Hook = &dbHook{
+	name:   "cassandra",
+	apps:   []string{"cassandra", "scylladb"},
+	images: []string{"cassandra", "scylla"},
+	script: `for t in $(cqlsh -e "SELECT keyspace_name, table_name FROM system_schema.tables" | awk -F'|' 'NF == 2 {gsub(/ /, ""); print $1"."$2}' | grep -v -e '^system' -e '^keyspace_name\.'); do
+  echo "$(cqlsh -e "COPY $t TO STDOUT" | sort | sha256sum | cut -d' ' -f1) $t"
+done`,
+}
+
+// dbHook prints the digests of a database with a script run in the database container of a pod
+type dbHook struct {
+	name string
+	// apps are the tokens of app keys the hook matches, like mysql for snap-mysql
+	apps []string
+	// images are the names of the images of database containers, without registry nor tag
+	images []string
+	script string
+}
+
+func (h *dbHook) String() string {
+	return h.name
+}
+
+func (h *dbHook) Matches(appKey string) bool {
+	for _, token := range strings.Split(appKey, "-") {
+		for _, app := range h.apps {
+			if token == app {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *dbHook) Command(pod *corev1.Pod) (string, []string) {
+	for _, c := range pod.Spec.Containers {
+		name := imageName(c.Image)
+		for _, image := range h.images {
+			if name == image {
+				return c.Name, []string{"sh", "-c", h.script}
+			}
+		}
+	}
+	return "", nil
+}
+
+// imageName returns the name of an image without its registry, repository, tag and digest
+func imageName(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+type filesHook struct{}
+
+func (h *filesHook) String() string {
+	return "files"
+}
+
+func (h *filesHook) Matches(appKey string) bool {
+	return true
+}
+
+// Command returns the command listing the files of the claims mounted in the first container
+// which mounts any, with the lost+found directory of the file system skipped
+func (h *filesHook) Command(pod *corev1.Pod) (string, []string) {
+	claims := make(map[string]string)
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			claims[v.Name] = v.PersistentVolumeClaim.ClaimName
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		var scripts []string
+		listed := make(map[string]bool)
+		for _, m := range c.VolumeMounts {
+			claim, ok := claims[m.Name]
+			if !ok || listed[claim] {
+				continue
+			}
+			listed[claim] = true
+			scripts = append(scripts, fmt.Sprintf(
+				`cd %s && find . -type f ! -path './lost+found/*' -exec sha256sum {} + | sed 's|  \./|  %s/|'`,
+				shellQuote(m.MountPath), claim))
+		}
+		if len(scripts) > 0 {
+			return c.Name, []string{"sh", "-c", strings.Join(scripts, " && ")}
+		}
+	}
+	return "", nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	for _, h := range []Hook{MySQL, Postgres, MongoDB, Cassandra} {
+		Register(h)
+	}
+}
@@ -0,0 +1,209 @@
+// Package integrity verifies that the data of applications survives backup and restore. Hooks
+// print digests of the data of an app from inside its pods: checksums of the files of its volumes
+// or, for databases, of their tables. Snapshots of the digests taken before backup and after
+// restore are compared item by item, so that a restore which boots but lost rows or files fails.
+package integrity
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/portworx/torpedo/pkg/log"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxItemsInError is the maximum number of items of each kind listed in a mismatch error
+const maxItemsInError = 10
+
+// Digests maps the items of the data of an app, such as files or tables, to a digest of their content
+type Digests map[string]string
+
+// Snapshot is the digests of the data of the app of a namespace at a point in time
+type Snapshot struct {
+	Namespace string
+	AppKey    string
+	// Hook which printed the digests
+	Hook    string
+	Digests Digests
+	Time    time.Time
+}
+
+// Executor runs a command in a container of a pod and returns its output, like
+// core.Instance().RunCommandInPod
+type Executor func(cmds []string, podName, containerName, namespace string) (string, error)
+
+// Hook prints the digests of the data of an app
+type Hook interface {
+	// String returns the name of the hook
+	String() string
+	// Matches returns true if the hook knows how to print the data of the app with the given key
+	Matches(appKey string) bool
+	// Command returns the container of the pod and the command which prints the digests of the
+	// data of the app in it, one "<digest> <item>" line per item. It returns an empty container
+	// if the pod holds no data for the hook.
+	Command(pod *corev1.Pod) (container string, cmd []string)
+}
+
+// Diff is the difference between two snapshots of the data of an app
+type Diff struct {
+	// Missing are the items which disappeared
+	Missing []string
+	// Extra are the items which appeared
+	Extra []string
+	// Changed are the items whose digest changed
+	Changed []string
+}
+
+// Empty returns true if the snapshots hold the same data
+func (d *Diff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Changed) == 0
+}
+
+// ErrDataMismatch is returned when the data of a restored app does not match the data of the app
+// before backup
+type ErrDataMismatch struct {
+	// Namespace of the restored app
+	Namespace string
+	Diff      *Diff
+}
+
+func (e *ErrDataMismatch) Error() string {
+	var parts []string
+	for _, kind := range []struct {
+		name  string
+		items []string
+	}{{"missing", e.Diff.Missing}, {"changed", e.Diff.Changed}, {"unexpected", e.Diff.Extra}} {
+		if len(kind.items) == 0 {
+			continue
+		}
+		items := kind.items
+		if len(items) > maxItemsInError {
+			items = append(items[:maxItemsInError:maxItemsInError], "...")
+		}
+		parts = append(parts, fmt.Sprintf("%d %s: %v", len(kind.items), kind.name, items))
+	}
+	return fmt.Sprintf("data of app in namespace %s does not match its backup, %s", e.Namespace, strings.Join(parts, ", "))
+}
+
+var hooks []Hook
+
+// Register registers a hook. Hooks are matched against app keys in registration order.
+func Register(hook Hook) error {
+	for _, h := range hooks {
+		if h.String() == hook.String() {
+			return fmt.Errorf("integrity hook: %s is already registered", hook.String())
+		}
+	}
+	hooks = append(hooks, hook)
+	return nil
+}
+
+// HookFor returns the first registered hook which matches the app key, or the files hook
+func HookFor(appKey string) Hook {
+	for _, h := range hooks {
+		if h.Matches(appKey) {
+			return h
+		}
+	}
+	return Files
+}
+
+// ParseDigests parses "<digest> <item>" lines. Items may contain spaces.
+func ParseDigests(output string) (Digests, error) {
+	digests := make(Digests)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+			return nil, fmt.Errorf("invalid digest line [%s]", line)
+		}
+		digests[strings.TrimSpace(fields[1])] = fields[0]
+	}
+	return digests, scanner.Err()
+}
+
+// Capture runs the hook of the app in its running pods and returns the snapshot of their data.
+// Pods holding the same item, such as replicas of a database, are expected to agree on its digest.
+func Capture(exec Executor, namespace, appKey string, pods []corev1.Pod) (*Snapshot, error) {
+	hook := HookFor(appKey)
+	snapshot := &Snapshot{
+		Namespace: namespace,
+		AppKey:    appKey,
+		Hook:      hook.String(),
+		Digests:   make(Digests),
+		Time:      time.Now(),
+	}
+	captured := 0
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		container, cmd := hook.Command(pod)
+		if container == "" {
+			continue
+		}
+		output, err := exec(cmd, pod.Name, container, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %s hook in pod %s/%s: %v", hook, namespace, pod.Name, err)
+		}
+		digests, err := ParseDigests(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse output of %s hook in pod %s/%s: %v", hook, namespace, pod.Name, err)
+		}
+		for item, digest := range digests {
+			if d, ok := snapshot.Digests[item]; ok && d != digest {
+				log.Warnf("Pods of app %s in namespace %s disagree on the digest of %s", appKey, namespace, item)
+				continue
+			}
+			snapshot.Digests[item] = digest
+		}
+		captured++
+	}
+	if captured == 0 {
+		return nil, fmt.Errorf("no running pod of app %s in namespace %s holds data for the %s hook", appKey, namespace, hook)
+	}
+	log.Infof("Captured %d digests of app %s in namespace %s with the %s hook", len(snapshot.Digests), appKey, namespace, hook)
+	return snapshot, nil
+}
+
+// Compare returns the difference from the before to the after snapshot
+func Compare(before, after *Snapshot) *Diff {
+	diff := &Diff{}
+	for item, digest := range before.Digests {
+		if d, ok := after.Digests[item]; !ok {
+			diff.Missing = append(diff.Missing, item)
+		} else if d != digest {
+			diff.Changed = append(diff.Changed, item)
+		}
+	}
+	for item := range after.Digests {
+		if _, ok := before.Digests[item]; !ok {
+			diff.Extra = append(diff.Extra, item)
+		}
+	}
+	sort.Strings(diff.Missing)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Extra)
+	return diff
+}
+
+// Verify returns an *ErrDataMismatch if the restored snapshot does not hold the data of the
+// snapshot taken before backup
+func Verify(before, restored *Snapshot) error {
+	if before.Hook != restored.Hook {
+		return fmt.Errorf("app in namespace %s was captured with the %s hook before backup and the %s hook after restore",
+			restored.Namespace, before.Hook, restored.Hook)
+	}
+	if diff := Compare(before, restored); !diff.Empty() {
+		return &ErrDataMismatch{Namespace: restored.Namespace, Diff: diff}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package integrity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func pod(name string, phase corev1.PodPhase, containers ...corev1.Container) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PodSpec{
+			Containers: containers,
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "nginx-pvc"},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestHookFor(t *testing.T) {
+	assert.Equal(t, MySQL, HookFor("snap-mysql"))
+	assert.Equal(t, MySQL, HookFor("mysql"))
+	assert.Equal(t, Postgres, HookFor("aut-postgres"))
+	assert.Equal(t, MongoDB, HookFor("mongodb"))
+	assert.Equal(t, Cassandra, HookFor("scylladb"))
+	assert.Equal(t, Files, HookFor("nginx"))
+	assert.Equal(t, Files, HookFor("mysqlslap"), "app keys match whole tokens")
+	assert.Error(t, Register(MySQL))
+
+	container, _ := MySQL.Command(&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "slap", Image: "adityadani/mysqlslap"},
+		{Name: "db", Image: "docker.io/library/mysql:5.6"},
+	}}})
+	assert.Equal(t, "db", container)
+}
+
+func TestCaptureAndVerify(t *testing.T) {
+	outputs := map[string]string{
+		"nginx-0": "aaa  nginx-pvc/index.html\nbbb  nginx-pvc/images/a logo.png\n",
+		"nginx-1": "aaa  nginx-pvc/index.html\n",
+	}
+	var ran []string
+	exec := func(cmds []string, podName, containerName, namespace string) (string, error) {
+		ran = append(ran, podName)
+		require.Equal(t, "nginx", containerName)
+		require.Equal(t, "sh", cmds[0])
+		require.Contains(t, cmds[2], "cd '/usr/share/nginx/html' && find .")
+		output, ok := outputs[podName]
+		if !ok {
+			return "", fmt.Errorf("pod %s not found", podName)
+		}
+		return output, nil
+	}
+	nginx := corev1.Container{Name: "nginx", Image: "nginx", VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/usr/share/nginx/html"}}}
+	pods := []corev1.Pod{
+		pod("nginx-0", corev1.PodRunning, nginx),
+		pod("nginx-1", corev1.PodRunning, corev1.Container{Name: "sidecar", Image: "busybox"}, nginx),
+		pod("nginx-2", corev1.PodPending, nginx),
+	}
+
+	before, err := Capture(exec, "ns", "nginx", pods)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nginx-0", "nginx-1"}, ran, "only running pods are captured")
+	assert.Equal(t, "files", before.Hook)
+	assert.Equal(t, Digests{"nginx-pvc/index.html": "aaa", "nginx-pvc/images/a logo.png": "bbb"}, before.Digests)
+
+	outputs["nginx-0"] = "aaa  nginx-pvc/index.html\nccc  nginx-pvc/new.html\n"
+	restored, err := Capture(exec, "ns-restored", "nginx", pods[:1])
+	require.NoError(t, err)
+	err = Verify(before, restored)
+	require.IsType(t, &ErrDataMismatch{}, err)
+	assert.Equal(t, &Diff{Missing: []string{"nginx-pvc/images/a logo.png"}, Extra: []string{"nginx-pvc/new.html"}}, err.(*ErrDataMismatch).Diff)
+	assert.Contains(t, err.Error(), "1 missing: [nginx-pvc/images/a logo.png]")
+	assert.NoError(t, Verify(before, before))
+
+	_, err = Capture(exec, "ns", "nginx", pods[2:])
+	assert.Error(t, err, "there must be a running pod with data")
+	_, err = Capture(exec, "ns", "nginx", []corev1.Pod{pod("nginx-9", corev1.PodRunning, nginx)})
+	assert.Error(t, err, "hooks must succeed")
+	_, err = ParseDigests("aaa\n")
+	assert.Error(t, err)
+}
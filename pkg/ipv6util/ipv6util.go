@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/portworx/torpedo/pkg/pxctl"
 )
 
 const (
@@ -111,15 +112,16 @@ func (p *parser) parseLine(line string) {
 	}
 }
 
-// ParseIPv6AddressInPxctlCommand takes output of `pxctl command` and return the list of IPs parsed
+// ParseIPv6AddressInPxctlCommand takes output of `pxctl command` and return the list of IPs parsed.
+// The outputs of pxctl status and pxctl cluster list must list nodeCount nodes, unless it is negative.
 func ParseIPv6AddressInPxctlCommand(command string, output string, nodeCount int) ([]string, error) {
 	switch command {
 	case PxctlStatus:
-		return parseIPv6AddressInPxctlStatus(output, nodeCount), nil
+		return parseIPv6AddressInPxctlStatus(output, nodeCount)
 	case PxctlClusterList:
-		return parseIPv6AddressInPxctlClusterList(output, nodeCount), nil
+		return parseIPv6AddressInPxctlClusterList(output, nodeCount)
 	case PxctlClusterInspect:
-		return parseIPv6AddressInPxctlClusterInspect(output, nodeCount), nil
+		return parseIPv6AddressInPxctlClusterInspect(output), nil
 	case PxctlServiceKvdbEndpoints:
 		return parseIPAddressInPxctlServiceKvdbEndpoints(output)
 	case PxctlServiceKvdbMembers:
@@ -127,51 +129,60 @@ func ParseIPv6AddressInPxctlCommand(command string, output string, nodeCount int
 	case PxctlVolumeList:
 		return parseIPv6AddressInPxctlVolumeList(output), nil
 	case PxctlVolumeInspect:
-		return parseIPv6AddressInPxctlVolumeInspect(output), nil
+		return parseIPv6AddressInPxctlVolumeInspect(output)
 	default:
 		return []string{}, nil
 	}
 }
 
-// parseIpv6AddressInPxctlStatus takes output of `pxctl status` and return the list of IPs parsed
-// iterate each line to check for two conditions where IPs are printed:
-//  1. `IP: <addr>`
-//     ex: IP: 0000:111:2222:3333:444:5555:6666:777
-//  2. (number of nodes) lines after `IP \t ID...`
-//     ex: IP					ID					SchedulerNodeName	Auth		StorageNode	Used	Capacity	Status	StorageStatus	Version		Kernel			OS
-//     0000:111:2222:3333:444:5555:6666:777	f703597a-9772-4bdb-b630-6395b3c98658	...	...
-//     0000:111:2222:3333:444:5555:6666:777	cedc897f-a489-4c28-9c20-12b8b4c3d1d8	...	...
-func parseIPv6AddressInPxctlStatus(status string, nodeCount int) []string {
-	// parse the case for IP: 0000:111:2222:3333:444:5555:6666:777
-	p1 := newIPv6ParserOption("IP:", 1, 0)
-
-	// parse the case:
-	// IP					ID					SchedulerNodeName	Auth		StorageNode	Used	Capacity	Status	StorageStatus	Version		Kernel			OS
-	// 0000:111:2222:3333:444:5555:6666:777	f703597a-9772-4bdb-b630-6395b3c98658	...	...
-	// 0000:111:2222:3333:444:5555:6666:777	cedc897f-a489-4c28-9c20-12b8b4c3d1d8	...	...
-	p2 := newIPv6ParserOption("IP\t", 0, nodeCount)
-	p := newIPv6Parser([]parserOption{p1, p2})
+// parseIPv6AddressInPxctlStatus takes output of `pxctl status` and return the IP of the node followed
+// by the IPs of the nodes of the cluster summary
+func parseIPv6AddressInPxctlStatus(output string, nodeCount int) ([]string, error) {
+	status, err := pxctl.ParseStatus(output)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkNodeCount(PxctlStatus, len(status.Nodes), nodeCount); err != nil {
+		return nil, err
+	}
+	ips := []string{status.IP}
+	for _, n := range status.Nodes {
+		ips = append(ips, n.IP)
+	}
+	return ips, nil
+}
 
-	return p.parse(status)
+// parseIPv6AddressInPxctlClusterList takes output of `pxctl cluster list` and return the data IPs
+// of the nodes
+func parseIPv6AddressInPxctlClusterList(output string, nodeCount int) ([]string, error) {
+	cluster, err := pxctl.ParseClusterList(output)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkNodeCount(PxctlClusterList, len(cluster.Nodes), nodeCount); err != nil {
+		return nil, err
+	}
+	ips := []string{}
+	for _, n := range cluster.Nodes {
+		ips = append(ips, n.DataIP)
+	}
+	return ips, nil
 }
 
-// parseIpv6AddressInPxctlClusterList takes output of `pxctl cluster list` and return the list of IPs parsed
-// iterate each line to check for the conditions where IPs are printed:
-// (number of nodes) lines after `ID\t... DATA IP\t`. ex:
-// ID					SCHEDULER_NODE_NAME	DATA IP					CPU		MEM TOTAL	MEM FREE	CONTAINERS	VERSION		Kernel				OS		STATUS
-// 2ca8932b-b17e-425c-bcbe-d33b0f64b623	node03			0000:111:2222:3333:444:5555:6666:777	... ...
-// 6b9d12e0-fb28-459e-acf1-cea4d57004e2	node04			0000:111:2222:3333:444:5555:6666:777	... ...
-func parseIPv6AddressInPxctlClusterList(output string, nodeCount int) []string {
-	option := newIPv6ParserOption("ID\t", 2, nodeCount)
-	p := newIPv6Parser([]parserOption{option})
-	return p.parse(output)
+// checkNodeCount checks the output of a pxctl command listed the expected number of nodes, any
+// number if it is negative
+func checkNodeCount(command string, parsed, expected int) error {
+	if expected >= 0 && parsed != expected {
+		return fmt.Errorf("parsed %d nodes in output of pxctl %s, expected %d", parsed, command, expected)
+	}
+	return nil
 }
 
 // parseIPv6AddressInPxctlClusterInspect takes output of `pxctl cluster inspect` and return the list of IPs parsed
 // iterate each line to check for two conditions where IPs are printed:
 // 1. `Mgmt IP\t :\t  <addr>` ex: Mgmt IP       		:  0000:111:2222:3333:444:5555:6666:111
 // 2. `Data IP\t :\t <addr>` ex: Data IP       		:  0000:111:2222:3333:444:5555:6666:111
-func parseIPv6AddressInPxctlClusterInspect(output string, nodeCount int) []string {
+func parseIPv6AddressInPxctlClusterInspect(output string) []string {
 	option1 := newIPv6ParserOption("Mgmt IP", 3, 0)
 	option2 := newIPv6ParserOption("Data IP", 3, 0)
 	p := newIPv6Parser([]parserOption{option1, option2})
@@ -198,22 +209,23 @@ func parseIPv6AddressInPxctlVolumeList(output string) []string {
 	return ips
 }
 
-// parseIPv6AddressInPxctlVolumeInspect takes output of `pxctl volume inspect` and return the list of IPs parsed
-// iterate each line to check for two condition where IPs are printed:
-// 1. State           	 :  Attached: 1c251a9f-605d-47fc-925d-7c8cb38d8b48 (0000:111:2222:3333:444:5555:6666:111)
-// 2. Node 		 : 0000:111:2222:3333:444:5555:6666:222 (Pool f54c56c1-eb9e-408b-ac92-010426e59500 )
-func parseIPv6AddressInPxctlVolumeInspect(output string) []string {
-	// 5th item in [State, :, Attached:, 1c251a9f-605d-47fc-925d-7c8cb38d8b48, (0000:111:2222:3333:444:5555:6666:111)]
-	option1 := newIPv6ParserOption("State", 4, 0)
-	// 3rd item in [Node, :, 0000:111:2222:3333:444:5555:6666:222, (Pool, f54c56c1-eb9e-408b-ac92-010426e59500, )]
-	option2 := newIPv6ParserOption("Node", 2, 0)
-	p := newIPv6Parser([]parserOption{option1, option2})
-	ips := p.parse(output)
-	// trim out the parenthesis for (0000:111:2222:3333:444:5555:6666:111)
-	for i, out := range ips {
-		ips[i] = strings.Trim(out, "()")
+// parseIPv6AddressInPxctlVolumeInspect takes output of `pxctl volume inspect` and return the IP of the
+// node the volume is attached on followed by the IPs of the nodes of its replicas
+func parseIPv6AddressInPxctlVolumeInspect(output string) ([]string, error) {
+	volume, err := pxctl.ParseVolumeInspect(output)
+	if err != nil {
+		return nil, err
 	}
-	return ips
+	ips := []string{}
+	if volume.AttachedIP != "" {
+		ips = append(ips, volume.AttachedIP)
+	}
+	for _, set := range volume.ReplicaSets {
+		for _, replica := range set {
+			ips = append(ips, replica.Node)
+		}
+	}
+	return ips, nil
 }
 
 // IsAddressIPv6 checks the given address is a valid Ipv6 address
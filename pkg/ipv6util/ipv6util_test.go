@@ -8,18 +8,22 @@ import (
 
 func TestValidIpv6Address(t *testing.T) {
 	// pxctl status tests
-	addrs, _ := ParseIPv6AddressInPxctlCommand(PxctlStatus, sampleIpv6PxctlStatusOutput, sampleNodeCount)
+	addrs, err := ParseIPv6AddressInPxctlCommand(PxctlStatus, sampleIpv6PxctlStatusOutput, sampleNodeCount)
+	assert.NoError(t, err, "Failed to parse addresses running command: %v", PxctlStatus)
+	assert.Len(t, addrs, sampleNodeCount+1, "running command %v. expected the IP of the node and of each node of the cluster", PxctlStatus)
 	assert.NotEmpty(t, addrs, "addresses are not expected to be empty. running command: %v", PxctlStatus)
 	isIpv6 := AreAddressesIPv6(addrs)
 	assert.True(t, isIpv6, "running command %v. addresses are expected to be ipv6, got: %v", PxctlStatus, addrs)
 
-	addrs, _ = ParseIPv6AddressInPxctlCommand(PxctlStatus, sampleIpv4PxctlStatusOutput, sampleNodeCount)
+	addrs, err = ParseIPv6AddressInPxctlCommand(PxctlStatus, sampleIpv4PxctlStatusOutput, sampleNodeCount)
+	assert.NoError(t, err, "Failed to parse addresses running command: %v", PxctlStatus)
 	assert.NotEmpty(t, addrs, "addresses are not expected to be empty. running command: %v", PxctlStatus)
 	isIpv6 = AreAddressesIPv6(addrs)
 	assert.False(t, isIpv6, "running command %v. addresses are expected to be ipv4, got: %v", PxctlStatus, addrs)
 
 	// pxctl cluster list tests
-	addrs, _ = ParseIPv6AddressInPxctlCommand(PxctlClusterList, sampleIpv6PxctlClusterListOutput, sampleNodeCount)
+	addrs, err = ParseIPv6AddressInPxctlCommand(PxctlClusterList, sampleIpv6PxctlClusterListOutput, sampleNodeCount)
+	assert.NoError(t, err, "Failed to parse addresses running command: %v", PxctlClusterList)
 	assert.NotEmpty(t, addrs, "addresses are not expected to be empty. running command: %v", PxctlClusterList)
 	isIpv6 = AreAddressesIPv6(addrs)
 	assert.True(t, isIpv6, "running command %v. addresses are expected to be ipv6, got: %v", PxctlClusterList, addrs)
@@ -40,10 +44,7 @@ func TestValidIpv6Address(t *testing.T) {
 	isIpv6 = AreAddressesIPv6(addrs)
 	assert.True(t, isIpv6, "running command %v. addresses are expected to be ipv6, got: %v", PxctlVolumeInspect, addrs)
 
-	var (
-		err error
-		ip  string
-	)
+	var ip string
 
 	addrs, err = ParseIPv6AddressInPxctlCommand(PxctlServiceKvdbEndpoints, sampleIpv6PxctlSvcKvdbEndPtsOutput, -1)
 	assert.NoError(t, err, "Failed to parse addresses running command: %v", PxctlServiceKvdbEndpoints)
@@ -61,3 +62,14 @@ func TestValidIpv6Address(t *testing.T) {
 	assert.True(t, isIpv6, "running command %v. addresses are expected to be ipv6, got: %v", PxctlAlertsShow, ip)
 
 }
+
+func TestOfflineNodeInPxctlStatus(t *testing.T) {
+	// The row of the offline node has no version, kernel or OS but its IP
+	addrs, err := ParseIPv6AddressInPxctlCommand(PxctlStatus, sampleIpv6PxctlStatusOfflineNodeOutput, sampleNodeCount)
+	assert.NoError(t, err, "Failed to parse addresses running command: %v", PxctlStatus)
+	assert.Len(t, addrs, sampleNodeCount+1, "running command %v. expected the IP of the node and of each node of the cluster", PxctlStatus)
+	assert.Contains(t, addrs, "0000:111:2222:3333:444:5555:6666:111")
+
+	_, err = ParseIPv6AddressInPxctlCommand(PxctlStatus, sampleIpv6PxctlStatusOfflineNodeOutput, sampleNodeCount+1)
+	assert.EqualError(t, err, "parsed 6 nodes in output of pxctl status, expected 7")
+}
@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// file appends notifications as JSON lines to a local file
+type file struct {
+	path string
+}
+
+func newFile(c Config) (Notifier, error) {
+	if c.Path == "" {
+		return nil, fmt.Errorf("file notification sink requires a path")
+	}
+	return &file{path: c.Path}, nil
+}
+
+func (f *file) String() string {
+	return "file:" + f.path
+}
+
+func (f *file) Notify(n *Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	// the file is opened for every notification so that it can be rotated or removed during a run
+	fh, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = fh.Write(append(data, '\n')); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
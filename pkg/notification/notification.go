@@ -0,0 +1,273 @@
+// Package notification sends the events of test triggers to notification sinks such as
+// webhooks, chat channels and files.
+//
+// Sinks are configured with a list of Config, each with its own Filter. A Dispatcher fans the
+// events out to all sinks, either one notification per event or, for sinks with a digest
+// interval, one notification per interval.
+package notification
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portworx/torpedo/pkg/eventlog"
+	"github.com/portworx/torpedo/pkg/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Notification is a set of events sent to a sink
+type Notification struct {
+	Subject string           `json:"subject"`
+	Events  []eventlog.Event `json:"events"`
+}
+
+// Failures returns the number of failed events of the notification
+func (n *Notification) Failures() int {
+	failures := 0
+	for _, e := range n.Events {
+		if len(e.Errors) > 0 {
+			failures++
+		}
+	}
+	return failures
+}
+
+// Notifier sends notifications to a sink
+type Notifier interface {
+	// String returns the name of the sink
+	String() string
+	// Notify sends the given notification
+	Notify(n *Notification) error
+}
+
+// Filter selects the events sent to a sink and how often they are sent
+type Filter struct {
+	// FailuresOnly sends only events with errors
+	FailuresOnly bool `json:"failuresOnly,omitempty"`
+	// Triggers sends only events of the given triggers. All triggers are sent if empty.
+	Triggers []string `json:"triggers,omitempty"`
+	// DigestInterval collects events and sends them at most once per interval. Events are sent
+	// as they come if zero.
+	DigestInterval metav1.Duration `json:"digestInterval,omitempty"`
+}
+
+// Matches returns true if the given event passes the filter
+func (f Filter) Matches(e eventlog.Event) bool {
+	if f.FailuresOnly && len(e.Errors) == 0 {
+		return false
+	}
+	if len(f.Triggers) == 0 {
+		return true
+	}
+	for _, t := range f.Triggers {
+		if t == e.Trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is the configuration of a sink
+type Config struct {
+	// Type of the sink: webhook, slack, teams or file
+	Type string `json:"type"`
+	// URL of webhook, slack and teams sinks
+	URL string `json:"url,omitempty"`
+	// Headers added to the requests of webhook sinks
+	Headers map[string]string `json:"headers,omitempty"`
+	// Path of file sinks
+	Path string `json:"path,omitempty"`
+	Filter
+}
+
+// notifiers are the constructors of the sinks by type
+var notifiers = map[string]func(Config) (Notifier, error){
+	"webhook": newWebhook,
+	"slack":   newSlack,
+	"teams":   newTeams,
+	"file":    newFile,
+}
+
+// New returns the sink of the given configuration
+func New(c Config) (Notifier, error) {
+	newNotifier, ok := notifiers[c.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown notification sink type %q, expected one of %s", c.Type, strings.Join(types(), ", "))
+	}
+	return newNotifier(c)
+}
+
+func types() []string {
+	var names []string
+	for name := range notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseConfigs parses a YAML or JSON list of sink configurations
+func ParseConfigs(data []byte) ([]Config, error) {
+	var configs []Config
+	if err := yaml.UnmarshalStrict(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse notification sinks: %v", err)
+	}
+	return configs, nil
+}
+
+type sink struct {
+	notifier Notifier
+	filter   Filter
+	pending  []eventlog.Event
+	lastSent time.Time
+}
+
+// Dispatcher sends events to several sinks. It is safe for concurrent use.
+type Dispatcher struct {
+	sync.Mutex
+	subject string
+	sinks   []*sink
+}
+
+// NewDispatcher returns a dispatcher without sinks sending notifications with the given subject
+func NewDispatcher(subject string) *Dispatcher {
+	return &Dispatcher{subject: subject}
+}
+
+// Configure replaces the sinks of the dispatcher with the sinks of the given configurations and
+// sets the subject of the notifications. The pending events of the replaced sinks are sent first.
+func (d *Dispatcher) Configure(subject string, configs []Config) error {
+	var sinks []*sink
+	for _, c := range configs {
+		n, err := New(c)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, &sink{notifier: n, filter: c.Filter, lastSent: time.Now()})
+	}
+	d.Lock()
+	due := d.dueLocked(time.Now(), true)
+	d.subject = subject
+	d.sinks = sinks
+	d.Unlock()
+	send(due)
+	return nil
+}
+
+// Add adds a sink with the given filter
+func (d *Dispatcher) Add(n Notifier, f Filter) {
+	d.Lock()
+	defer d.Unlock()
+	d.sinks = append(d.sinks, &sink{notifier: n, filter: f, lastSent: time.Now()})
+}
+
+// Len returns the number of sinks
+func (d *Dispatcher) Len() int {
+	d.Lock()
+	defer d.Unlock()
+	return len(d.sinks)
+}
+
+// Publish sends the event to the sinks whose filter it matches, and the digests which are due.
+// Failing sinks are logged and do not prevent sending to the other sinks.
+func (d *Dispatcher) Publish(e eventlog.Event) {
+	d.Lock()
+	for _, s := range d.sinks {
+		if !s.filter.Matches(e) {
+			continue
+		}
+		s.pending = append(s.pending, e)
+	}
+	due := d.dueLocked(time.Now(), false)
+	d.Unlock()
+	send(due)
+}
+
+// Flush sends the digests which are due at the given time
+func (d *Dispatcher) Flush(now time.Time) {
+	d.Lock()
+	due := d.dueLocked(now, false)
+	d.Unlock()
+	send(due)
+}
+
+// Close sends all pending events, whether their digest is due or not
+func (d *Dispatcher) Close() {
+	d.Lock()
+	due := d.dueLocked(time.Now(), true)
+	d.Unlock()
+	send(due)
+}
+
+// Run flushes due digests every interval till stop is closed
+func (d *Dispatcher) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			d.Flush(now)
+		}
+	}
+}
+
+// delivery is a notification due to a notifier
+type delivery struct {
+	notifier     Notifier
+	notification *Notification
+}
+
+// dueLocked takes the pending events of the sinks whose digest is due, so that they are sent
+// without holding the lock, as notifiers may block for as long as their timeout
+func (d *Dispatcher) dueLocked(now time.Time, force bool) []delivery {
+	var due []delivery
+	for _, s := range d.sinks {
+		if len(s.pending) == 0 {
+			continue
+		}
+		if !force && now.Sub(s.lastSent) < s.filter.DigestInterval.Duration {
+			continue
+		}
+		due = append(due, delivery{notifier: s.notifier, notification: &Notification{Subject: d.subject, Events: s.pending}})
+		// events are dropped rather than retried, a sink which is down should not grow without bound
+		s.pending = nil
+		s.lastSent = now
+	}
+	return due
+}
+
+func send(due []delivery) {
+	for _, dl := range due {
+		if err := dl.notifier.Notify(dl.notification); err != nil {
+			log.Errorf("Failed to send %d events to notification sink [%s]: %v", len(dl.notification.Events), dl.notifier, err)
+		}
+	}
+}
+
+// summary returns a single line describing the event
+func summary(e eventlog.Event) string {
+	result := "passed"
+	if len(e.Errors) > 0 {
+		result = fmt.Sprintf("failed with %d errors", len(e.Errors))
+	}
+	line := fmt.Sprintf("%s %s in %s", e.Trigger, result, e.End.Sub(e.Start).Round(time.Second))
+	if len(e.Targets) > 0 {
+		var targets []string
+		for _, t := range e.Targets {
+			targets = append(targets, t.String())
+		}
+		line += " on " + strings.Join(targets, ", ")
+	}
+	return line
+}
+
+// title returns a single line describing the notification
+func title(n *Notification) string {
+	return fmt.Sprintf("%s: %d events, %d failed", n.Subject, len(n.Events), n.Failures())
+}
@@ -0,0 +1,155 @@
+package notification
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/portworx/torpedo/pkg/eventlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recorder records the notifications it receives
+type recorder struct {
+	sync.Mutex
+	notifications []*Notification
+}
+
+func (r *recorder) String() string {
+	return "recorder"
+}
+
+func (r *recorder) Notify(n *Notification) error {
+	r.Lock()
+	defer r.Unlock()
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func event(trigger string, errs ...string) eventlog.Event {
+	e := eventlog.Event{Trigger: trigger, Start: time.Now(), End: time.Now().Add(time.Minute)}
+	for _, err := range errs {
+		e.Errors = append(e.Errors, eventlog.Error{Message: err, Class: eventlog.ClassUnknown})
+	}
+	return e
+}
+
+func TestDispatcherFilters(t *testing.T) {
+	all, failures, digest := &recorder{}, &recorder{}, &recorder{}
+	d := NewDispatcher("longevity")
+	d.Add(all, Filter{})
+	d.Add(failures, Filter{FailuresOnly: true, Triggers: []string{"rebootNode"}})
+	d.Add(digest, Filter{DigestInterval: metav1.Duration{Duration: time.Hour}})
+
+	d.Publish(event("rebootNode"))
+	d.Publish(event("rebootNode", "node-1 did not come up"))
+	d.Publish(event("crashNode", "timed out"))
+
+	assert.Len(t, all.notifications, 3)
+	require.Len(t, failures.notifications, 1)
+	assert.Equal(t, "rebootNode", failures.notifications[0].Events[0].Trigger)
+	assert.Empty(t, digest.notifications, "digest is not due yet")
+
+	d.Flush(time.Now().Add(2 * time.Hour))
+	require.Len(t, digest.notifications, 1)
+	assert.Len(t, digest.notifications[0].Events, 3)
+	assert.Equal(t, 2, digest.notifications[0].Failures())
+
+	d.Publish(event("crashNode"))
+	d.Close()
+	assert.Len(t, digest.notifications, 2, "pending events are sent on close")
+}
+
+// blocking blocks in Notify till it is released
+type blocking struct {
+	notifying chan struct{}
+	release   chan struct{}
+}
+
+func (b *blocking) String() string {
+	return "blocking"
+}
+
+func (b *blocking) Notify(n *Notification) error {
+	b.notifying <- struct{}{}
+	<-b.release
+	return nil
+}
+
+func TestDispatcherSendsOutsideLock(t *testing.T) {
+	slow := &blocking{notifying: make(chan struct{}), release: make(chan struct{})}
+	d := NewDispatcher("longevity")
+	d.Add(slow, Filter{})
+	published := make(chan struct{})
+	go func() {
+		d.Publish(event("rebootNode"))
+		close(published)
+	}()
+	<-slow.notifying
+
+	// a slow sink does not hold the dispatcher
+	fast := &recorder{}
+	d.Add(fast, Filter{})
+	assert.Equal(t, 2, d.Len())
+	close(slow.release)
+	<-published
+	assert.Empty(t, fast.notifications, "events published before the sink was added are not sent to it")
+}
+
+func TestSinks(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, r.Header.Get("X-Token")+" "+string(body))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	configs, err := ParseConfigs([]byte(`
+- type: webhook
+  url: ` + server.URL + `
+  headers:
+    X-Token: secret
+- type: slack
+  url: ` + server.URL + `
+  failuresOnly: true
+- type: teams
+  url: ` + server.URL + `
+- type: file
+  path: ` + path + `
+  digestInterval: 1h
+`))
+	require.NoError(t, err)
+	d := NewDispatcher("")
+	require.NoError(t, d.Configure("longevity", configs))
+	assert.Equal(t, 4, d.Len())
+
+	d.Publish(event("rebootNode", "node-1 did not come up"))
+	require.Len(t, bodies, 3)
+	assert.True(t, strings.HasPrefix(bodies[0], `secret {"subject":"longevity","events":[`), bodies[0])
+	assert.Contains(t, bodies[1], `:x: rebootNode failed with 1 errors in 1m0s`)
+	assert.Contains(t, bodies[2], `"@type":"MessageCard"`)
+	assert.Contains(t, bodies[2], `"themeColor":"D40E0D"`)
+
+	require.NoError(t, d.Configure("longevity", nil))
+	assert.Equal(t, 0, d.Len())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var n Notification
+	require.NoError(t, json.Unmarshal(data, &n), "pending events are sent when sinks are replaced")
+	assert.Equal(t, "rebootNode", n.Events[0].Trigger)
+
+	_, err = New(Config{Type: "pager"})
+	assert.Error(t, err)
+	_, err = New(Config{Type: "slack"})
+	assert.Error(t, err, "slack sinks require a url")
+}
@@ -0,0 +1,165 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// httpTimeout is the timeout of requests to webhooks
+	httpTimeout = 30 * time.Second
+	// maxErrorLines is the number of errors of an event shown in chat messages
+	maxErrorLines = 3
+)
+
+// webhook posts notifications as JSON to a URL
+type webhook struct {
+	name    string
+	url     string
+	headers map[string]string
+	client  *http.Client
+	// payload returns the body of the request for a notification
+	payload func(n *Notification) interface{}
+}
+
+func newWebhookOf(name string, c Config, payload func(n *Notification) interface{}) (Notifier, error) {
+	if c.URL == "" {
+		return nil, fmt.Errorf("%s notification sink requires a url", name)
+	}
+	return &webhook{
+		name:    name,
+		url:     c.URL,
+		headers: c.Headers,
+		client:  &http.Client{Timeout: httpTimeout},
+		payload: payload,
+	}, nil
+}
+
+// newWebhook returns a sink posting notifications as they are to a generic webhook
+func newWebhook(c Config) (Notifier, error) {
+	return newWebhookOf("webhook", c, func(n *Notification) interface{} {
+		return n
+	})
+}
+
+// newSlack returns a sink posting notifications to a Slack compatible incoming webhook
+func newSlack(c Config) (Notifier, error) {
+	return newWebhookOf("slack", c, slackPayload)
+}
+
+// newTeams returns a sink posting notifications as message cards to a Microsoft Teams webhook
+func newTeams(c Config) (Notifier, error) {
+	return newWebhookOf("teams", c, teamsPayload)
+}
+
+func (w *webhook) String() string {
+	// the url of chat webhooks is a secret, so only its host is shown
+	host := w.url
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	return w.name + ":" + host
+}
+
+func (w *webhook) Notify(n *Notification) error {
+	body, err := json.Marshal(w.payload(n))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned %s: %s", w, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// slackMessage is the payload of Slack incoming webhooks
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+func slackPayload(n *Notification) interface{} {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n", title(n))
+	for _, e := range n.Events {
+		icon := ":white_check_mark:"
+		if len(e.Errors) > 0 {
+			icon = ":x:"
+		}
+		fmt.Fprintf(&b, "%s %s\n", icon, summary(e))
+		for i, err := range e.Errors {
+			if i == maxErrorLines {
+				fmt.Fprintf(&b, "      _and %d more errors_\n", len(e.Errors)-maxErrorLines)
+				break
+			}
+			fmt.Fprintf(&b, "      `%s` %s\n", err.Class, err.Message)
+		}
+	}
+	return slackMessage{Text: b.String()}
+}
+
+// teamsCard is the payload of Microsoft Teams incoming webhooks
+type teamsCard struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	Summary    string         `json:"summary"`
+	ThemeColor string         `json:"themeColor"`
+	Title      string         `json:"title"`
+	Sections   []teamsSection `json:"sections"`
+}
+
+type teamsSection struct {
+	ActivityTitle string      `json:"activityTitle"`
+	Facts         []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func teamsPayload(n *Notification) interface{} {
+	card := teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    title(n),
+		ThemeColor: "2EB886",
+		Title:      title(n),
+	}
+	if n.Failures() > 0 {
+		card.ThemeColor = "D40E0D"
+	}
+	for _, e := range n.Events {
+		section := teamsSection{ActivityTitle: summary(e)}
+		for i, err := range e.Errors {
+			if i == maxErrorLines {
+				section.Facts = append(section.Facts, teamsFact{Name: "...", Value: fmt.Sprintf("%d more errors", len(e.Errors)-maxErrorLines)})
+				break
+			}
+			section.Facts = append(section.Facts, teamsFact{Name: string(err.Class), Value: err.Message})
+		}
+		card.Sections = append(card.Sections, section)
+	}
+	return card
+}
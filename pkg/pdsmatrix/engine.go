@@ -0,0 +1,148 @@
+package pdsmatrix
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	torpedoerrors "github.com/portworx/torpedo/pkg/errors"
+	"github.com/portworx/torpedo/pkg/log"
+)
+
+// Runner runs the steps of the cells on PDS. Operations a runner doesn't support return an
+// errors.ErrNotSupported, which skips their cells.
+type Runner interface {
+	// Deploy deploys the version of the data service of a cell with its templates and validates
+	// the deployment
+	Deploy(c Cell) (*pds.ModelsDeployment, error)
+	// Validate validates a deployment is healthy
+	Validate(c Cell, deployment *pds.ModelsDeployment) error
+	// Scale scales a deployment to the scale replicas of the data service of a cell
+	Scale(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error)
+	// Upgrade upgrades a deployment to the upgrade version of a cell
+	Upgrade(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error)
+	// Backup takes an ad-hoc backup of a deployment and waits for it to complete
+	Backup(c Cell, deployment *pds.ModelsDeployment) error
+	// Restore restores the last backup of a deployment to a new deployment
+	Restore(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error)
+	// DisruptNodes disrupts the nodes of a deployment
+	DisruptNodes(c Cell, deployment *pds.ModelsDeployment) error
+	// Delete deletes a deployment
+	Delete(deployment *pds.ModelsDeployment) error
+}
+
+// Engine runs the cells of a matrix with a runner
+type Engine struct {
+	Runner Runner
+	// FailFast stops the run at the first failed cell, the remaining cells are not reported
+	FailFast bool
+}
+
+// Run runs all cells of the matrix and returns their report. Each cell deploys its own data
+// service, which is deleted once the cell has run whatever its outcome.
+func (e *Engine) Run(m *Matrix) (*Report, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	for _, cell := range m.Cells() {
+		result := e.runCell(cell)
+		report.Results = append(report.Results, result)
+		if result.Status == Failed {
+			log.Errorf("PDS matrix cell %s failed: %v", cell, result.Err)
+			if e.FailFast {
+				break
+			}
+		}
+	}
+	return report, nil
+}
+
+func (e *Engine) runCell(cell Cell) Result {
+	if cell.Skip != "" {
+		return Result{Cell: cell, Status: Skipped, Err: errors.New(cell.Skip)}
+	}
+	log.InfoD("Running PDS matrix cell %s", cell)
+	start := time.Now()
+	err := e.run(cell)
+	return Result{Cell: cell, Status: StatusOf(err), Err: err, Duration: time.Since(start)}
+}
+
+// run runs the steps of the operation of a cell
+func (e *Engine) run(cell Cell) (err error) {
+	// Upgrades start from the version of the cell, which is the old version of the data service
+	deployment, err := e.Runner.Deploy(cell)
+	if deployment != nil {
+		defer func() {
+			if deleteErr := e.Runner.Delete(deployment); deleteErr != nil {
+				log.Warnf("Failed to delete deployment %s of cell %s: %v", deployment.GetId(), cell, deleteErr)
+				if err == nil {
+					err = fmt.Errorf("failed to delete deployment %s: %v", deployment.GetId(), deleteErr)
+				}
+			}
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to deploy: %w", err)
+	}
+
+	switch cell.Operation {
+	case Deploy:
+		return nil
+	case Scale:
+		scaled, err := e.Runner.Scale(cell, deployment)
+		if err != nil {
+			return fmt.Errorf("failed to scale to %d replicas: %w", cell.DataService.ScaleReplicas, err)
+		}
+		deployment = scaled
+		return e.Runner.Validate(cell, deployment)
+	case Upgrade:
+		upgraded, err := e.Runner.Upgrade(cell, deployment)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade to %s-%s: %w", cell.UpgradeVersion, cell.UpgradeImage, err)
+		}
+		deployment = upgraded
+		return e.Runner.Validate(cell, deployment)
+	case Backup:
+		if err := e.Runner.Backup(cell, deployment); err != nil {
+			return fmt.Errorf("failed to back up: %w", err)
+		}
+		return nil
+	case Restore:
+		if err := e.Runner.Backup(cell, deployment); err != nil {
+			return fmt.Errorf("failed to back up: %w", err)
+		}
+		restored, err := e.Runner.Restore(cell, deployment)
+		if restored != nil {
+			defer func() {
+				if deleteErr := e.Runner.Delete(restored); deleteErr != nil {
+					log.Warnf("Failed to delete restored deployment %s of cell %s: %v", restored.GetId(), cell, deleteErr)
+				}
+			}()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore: %w", err)
+		}
+		return e.Runner.Validate(cell, restored)
+	case NodeDisruption:
+		if err := e.Runner.DisruptNodes(cell, deployment); err != nil {
+			return fmt.Errorf("failed to disrupt nodes: %w", err)
+		}
+		return e.Runner.Validate(cell, deployment)
+	}
+	return fmt.Errorf("operation %s is not supported", cell.Operation)
+}
+
+// StatusOf returns the status of a cell from the error of its steps. Steps not supported by the
+// runner skip the cell.
+func StatusOf(err error) Status {
+	if err == nil {
+		return Passed
+	}
+	var notSupported *torpedoerrors.ErrNotSupported
+	if errors.As(err, &notSupported) {
+		return Skipped
+	}
+	return Failed
+}
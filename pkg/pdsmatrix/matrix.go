@@ -0,0 +1,212 @@
+// Package pdsmatrix runs PDS data services through a declarative test matrix. A matrix crosses the
+// data services of pds_default_parameters.json and their versions with resource templates,
+// storage templates and operations. The engine deploys a data service for every cell, runs the
+// operation of the cell on it and reports the outcome of every cell in a table, so that a new
+// data service, version or template is a new entry of the parameters rather than new test code.
+package pdsmatrix
+
+import (
+	"fmt"
+
+	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
+)
+
+// Operation is an operation run on the deployment of a data service
+type Operation string
+
+const (
+	// Deploy deploys the data service and validates the deployment
+	Deploy Operation = "deploy"
+	// Scale scales the deployment up to the scale replicas of the data service
+	Scale Operation = "scale"
+	// Upgrade deploys the old version of the data service and upgrades it to the version of the cell
+	Upgrade Operation = "upgrade"
+	// Backup takes an ad-hoc backup of the deployment
+	Backup Operation = "backup"
+	// Restore takes an ad-hoc backup of the deployment and restores it to a new deployment
+	Restore Operation = "restore"
+	// NodeDisruption disrupts the nodes of the deployment, such as rebooting them, and validates
+	// the deployment recovers
+	NodeDisruption Operation = "nodedisruption"
+)
+
+// Operations are all the operations in the order they run for a version of a data service
+var Operations = []Operation{Deploy, Scale, Upgrade, Backup, Restore, NodeDisruption}
+
+const (
+	// DefaultResourceTemplate is the resource template of the matrices with none
+	DefaultResourceTemplate = "Small"
+	// DefaultStorageTemplate is the storage template of the matrices with none
+	DefaultStorageTemplate = "QaDefault"
+
+	zookeeper = "ZooKeeper"
+)
+
+// DataService is a data service of the matrix with its versions and replicas
+type DataService struct {
+	Name          string
+	Version       string
+	Image         string
+	Replicas      int
+	ScaleReplicas int
+	// OldVersion and OldImage are the version upgrades start from, if any
+	OldVersion string
+	OldImage   string
+}
+
+// Matrix crosses data services and their versions with resource templates, storage templates and
+// operations
+type Matrix struct {
+	DataServices []DataService
+	// ResourceTemplates are the names of the resource templates of each data service
+	ResourceTemplates []string
+	// StorageTemplates are the names of the storage templates
+	StorageTemplates []string
+	Operations       []Operation
+}
+
+// Cell is a data service version deployed with a resource and storage template, running an
+// operation
+type Cell struct {
+	DataService      DataService
+	Version          string
+	Image            string
+	ResourceTemplate string
+	StorageTemplate  string
+	Operation        Operation
+	// UpgradeVersion and UpgradeImage are the version and image upgrade cells upgrade to
+	UpgradeVersion string
+	UpgradeImage   string
+	// Skip is the reason the cell doesn't apply to the data service version, if it doesn't
+	Skip string
+}
+
+func (c Cell) String() string {
+	return fmt.Sprintf("%s %s-%s %s/%s %s", c.DataService.Name, c.Version, c.Image, c.ResourceTemplate, c.StorageTemplate, c.Operation)
+}
+
+// Replicas returns the replicas the data service of the cell is deployed with
+func (c Cell) Replicas() int32 {
+	return int32(c.DataService.Replicas)
+}
+
+// FromParameters returns the matrix of the parameters of the PDS tests. The resource templates,
+// storage templates and operations of the matrix are the ones of its Matrix section, or the
+// default templates and all operations if they aren't set.
+func FromParameters(params *pdslib.Parameter) (*Matrix, error) {
+	m := &Matrix{
+		ResourceTemplates: params.Matrix.ResourceTemplates,
+		StorageTemplates:  params.Matrix.StorageTemplates,
+	}
+	for _, ds := range params.DataServiceToTest {
+		m.DataServices = append(m.DataServices, DataService{
+			Name:          ds.Name,
+			Version:       ds.Version,
+			Image:         ds.Image,
+			Replicas:      ds.Replicas,
+			ScaleReplicas: ds.ScaleReplicas,
+			OldVersion:    ds.OldVersion,
+			OldImage:      ds.OldImage,
+		})
+	}
+	for _, operation := range params.Matrix.Operations {
+		m.Operations = append(m.Operations, Operation(operation))
+	}
+	if len(m.ResourceTemplates) == 0 {
+		m.ResourceTemplates = []string{DefaultResourceTemplate}
+	}
+	if len(m.StorageTemplates) == 0 {
+		m.StorageTemplates = []string{DefaultStorageTemplate}
+	}
+	if len(m.Operations) == 0 {
+		m.Operations = Operations
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Validate checks the matrix has cells and only known operations
+func (m *Matrix) Validate() error {
+	if len(m.DataServices) == 0 || len(m.ResourceTemplates) == 0 || len(m.StorageTemplates) == 0 || len(m.Operations) == 0 {
+		return fmt.Errorf("pds matrix has no cells: %d data services, %d resource templates, %d storage templates and %d operations",
+			len(m.DataServices), len(m.ResourceTemplates), len(m.StorageTemplates), len(m.Operations))
+	}
+	for _, operation := range m.Operations {
+		known := false
+		for _, o := range Operations {
+			known = known || o == operation
+		}
+		if !known {
+			return fmt.Errorf("operation %s is not supported, supported operations are %v", operation, Operations)
+		}
+	}
+	for _, ds := range m.DataServices {
+		if ds.Name == "" || ds.Version == "" || ds.Image == "" {
+			return fmt.Errorf("data service [%+v] has no name, version or image", ds)
+		}
+		if (ds.OldVersion == "") != (ds.OldImage == "") {
+			return fmt.Errorf("data service %s has an old version without image or an old image without version", ds.Name)
+		}
+	}
+	return nil
+}
+
+// Cells expands the matrix into its cells, by data service, version, resource template, storage
+// template and operation. Versions are the version of each data service and its old version, if
+// any. Cells of operations which don't apply to a version are skipped rather than left out, so that
+// reports show the whole matrix.
+func (m *Matrix) Cells() []Cell {
+	var cells []Cell
+	for _, ds := range m.DataServices {
+		versions := [][2]string{{ds.Version, ds.Image}}
+		if ds.OldVersion != "" {
+			versions = append(versions, [2]string{ds.OldVersion, ds.OldImage})
+		}
+		for _, version := range versions {
+			for _, resourceTemplate := range m.ResourceTemplates {
+				for _, storageTemplate := range m.StorageTemplates {
+					for _, operation := range m.Operations {
+						cell := Cell{
+							DataService:      ds,
+							Version:          version[0],
+							Image:            version[1],
+							ResourceTemplate: resourceTemplate,
+							StorageTemplate:  storageTemplate,
+							Operation:        operation,
+						}
+						cell.Skip = skip(cell)
+						if operation == Upgrade && cell.Skip == "" {
+							cell.UpgradeVersion, cell.UpgradeImage = ds.Version, ds.Image
+						}
+						cells = append(cells, cell)
+					}
+				}
+			}
+		}
+	}
+	return cells
+}
+
+// skip returns the reason the operation of a cell doesn't apply to its data service version
+func skip(c Cell) string {
+	ds := c.DataService
+	switch c.Operation {
+	case Scale:
+		if ds.Name == zookeeper {
+			return fmt.Sprintf("scaling of nodes is not supported for %s", ds.Name)
+		}
+		if ds.ScaleReplicas <= ds.Replicas {
+			return fmt.Sprintf("no scale replicas above %d replicas", ds.Replicas)
+		}
+	case Upgrade:
+		if ds.OldVersion == "" {
+			return "no old version to upgrade from"
+		}
+		if c.Version == ds.Version && c.Image == ds.Image {
+			return "no newer version to upgrade to"
+		}
+	}
+	return ""
+}
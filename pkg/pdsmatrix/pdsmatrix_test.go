@@ -0,0 +1,229 @@
+package pdsmatrix
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
+	"github.com/portworx/torpedo/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner deploys data services in memory and fails the steps of failOn
+type fakeRunner struct {
+	failOn   map[string]bool
+	deployed map[string]*pds.ModelsDeployment
+	steps    []string
+	next     int
+}
+
+func newFakeRunner(failOn ...string) *fakeRunner {
+	r := &fakeRunner{failOn: make(map[string]bool), deployed: make(map[string]*pds.ModelsDeployment)}
+	for _, step := range failOn {
+		r.failOn[step] = true
+	}
+	return r
+}
+
+func (r *fakeRunner) step(name string, c Cell) error {
+	r.steps = append(r.steps, fmt.Sprintf("%s %s", name, c.DataService.Name))
+	if r.failOn[name] || r.failOn[name+" "+c.DataService.Name] {
+		return fmt.Errorf("%s of %s failed", name, c.DataService.Name)
+	}
+	return nil
+}
+
+func (r *fakeRunner) deploy(c Cell, version, image string) *pds.ModelsDeployment {
+	r.next++
+	id := fmt.Sprintf("deployment-%d", r.next)
+	name := fmt.Sprintf("%s-%s-%s", c.DataService.Name, version, image)
+	deployment := &pds.ModelsDeployment{Id: &id, Name: &name}
+	r.deployed[id] = deployment
+	return deployment
+}
+
+func (r *fakeRunner) Deploy(c Cell) (*pds.ModelsDeployment, error) {
+	if err := r.step("deploy", c); err != nil {
+		return nil, err
+	}
+	return r.deploy(c, c.Version, c.Image), nil
+}
+
+func (r *fakeRunner) Validate(c Cell, deployment *pds.ModelsDeployment) error {
+	if _, ok := r.deployed[deployment.GetId()]; !ok {
+		return fmt.Errorf("deployment %s does not exist", deployment.GetId())
+	}
+	return r.step("validate", c)
+}
+
+func (r *fakeRunner) Scale(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error) {
+	return deployment, r.step("scale", c)
+}
+
+func (r *fakeRunner) Upgrade(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error) {
+	if err := r.step("upgrade", c); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%s-%s-%s", c.DataService.Name, c.UpgradeVersion, c.UpgradeImage)
+	deployment.Name = &name
+	return deployment, nil
+}
+
+func (r *fakeRunner) Backup(c Cell, deployment *pds.ModelsDeployment) error {
+	if r.failOn["unsupported backup"] {
+		return &errors.ErrNotSupported{Type: "fake", Operation: Backup}
+	}
+	return r.step("backup", c)
+}
+
+func (r *fakeRunner) Restore(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error) {
+	if err := r.step("restore", c); err != nil {
+		return nil, err
+	}
+	return r.deploy(c, c.Version, c.Image), nil
+}
+
+func (r *fakeRunner) DisruptNodes(c Cell, deployment *pds.ModelsDeployment) error {
+	return r.step("disrupt", c)
+}
+
+func (r *fakeRunner) Delete(deployment *pds.ModelsDeployment) error {
+	if _, ok := r.deployed[deployment.GetId()]; !ok {
+		return fmt.Errorf("deployment %s does not exist", deployment.GetId())
+	}
+	delete(r.deployed, deployment.GetId())
+	return nil
+}
+
+var (
+	postgres = DataService{Name: "PostgreSQL", Version: "14.6", Image: "37490df", Replicas: 3, ScaleReplicas: 6, OldVersion: "14.4", OldImage: "b64d741"}
+	zk       = DataService{Name: "ZooKeeper", Version: "3.8.0", Image: "273fa6f", Replicas: 3, OldVersion: "3.7.1", OldImage: "d8f144f"}
+	redis    = DataService{Name: "Redis", Version: "7.0.5", Image: "9181925", Replicas: 6, ScaleReplicas: 8}
+)
+
+func TestFromParameters(t *testing.T) {
+	data, err := os.ReadFile("../../drivers/pds/parameters/pds_default_parameters.json")
+	require.NoError(t, err)
+	params := &pdslib.Parameter{}
+	require.NoError(t, json.Unmarshal(data, params))
+
+	m, err := FromParameters(params)
+	require.NoError(t, err)
+	assert.Len(t, m.DataServices, len(params.DataServiceToTest))
+	assert.Equal(t, []string{DefaultResourceTemplate}, m.ResourceTemplates)
+	assert.Equal(t, []string{DefaultStorageTemplate}, m.StorageTemplates)
+	assert.Equal(t, Operations, m.Operations)
+
+	// Parameters without a matrix section run all operations with the default templates
+	params.Matrix.ResourceTemplates, params.Matrix.StorageTemplates, params.Matrix.Operations = nil, nil, nil
+	m, err = FromParameters(params)
+	require.NoError(t, err)
+	assert.Equal(t, Operations, m.Operations)
+	assert.Equal(t, []string{DefaultResourceTemplate}, m.ResourceTemplates)
+
+	params.Matrix.Operations = []string{"deploy", "failover"}
+	_, err = FromParameters(params)
+	assert.EqualError(t, err, "operation failover is not supported, supported operations are [deploy scale upgrade backup restore nodedisruption]")
+}
+
+func TestCells(t *testing.T) {
+	m := &Matrix{
+		DataServices:      []DataService{postgres, zk, redis},
+		ResourceTemplates: []string{"Small", "Medium"},
+		StorageTemplates:  []string{"QaDefault"},
+		Operations:        Operations,
+	}
+	cells := m.Cells()
+	// 2 versions of postgres and zookeeper, 1 of redis, by 2 resource templates and 6 operations
+	require.Len(t, cells, (2+2+1)*2*len(Operations))
+
+	skips := make(map[string]string)
+	for _, c := range cells {
+		if c.ResourceTemplate == "Small" {
+			skips[fmt.Sprintf("%s %s %s", c.DataService.Name, c.Version, c.Operation)] = c.Skip
+		}
+	}
+	assert.Equal(t, "", skips["PostgreSQL 14.6 scale"])
+	assert.Equal(t, "no newer version to upgrade to", skips["PostgreSQL 14.6 upgrade"])
+	assert.Equal(t, "", skips["PostgreSQL 14.4 upgrade"])
+	assert.Equal(t, "scaling of nodes is not supported for ZooKeeper", skips["ZooKeeper 3.8.0 scale"])
+	assert.Equal(t, "no old version to upgrade from", skips["Redis 7.0.5 upgrade"])
+	assert.Equal(t, "", skips["Redis 7.0.5 nodedisruption"])
+
+	for _, c := range cells {
+		if c.Operation == Upgrade && c.Skip == "" {
+			assert.Equal(t, c.DataService.Version, c.UpgradeVersion, "cell %s", c)
+			assert.Equal(t, c.DataService.OldVersion, c.Version, "cell %s", c)
+		}
+	}
+
+	invalid := &Matrix{DataServices: []DataService{{Name: "MySQL", Version: "8.0.31", Image: "033c060", OldVersion: "8.0.30"}},
+		ResourceTemplates: []string{"Small"}, StorageTemplates: []string{"QaDefault"}, Operations: []Operation{Deploy}}
+	assert.Error(t, invalid.Validate())
+}
+
+func TestEngine(t *testing.T) {
+	m := &Matrix{
+		DataServices:      []DataService{postgres, redis},
+		ResourceTemplates: []string{"Small"},
+		StorageTemplates:  []string{"QaDefault"},
+		Operations:        Operations,
+	}
+
+	t.Run("all cells pass", func(t *testing.T) {
+		runner := newFakeRunner()
+		report, err := (&Engine{Runner: runner}).Run(m)
+		require.NoError(t, err)
+		assert.NoError(t, report.Err())
+		assert.Equal(t, len(m.Cells()), len(report.Results))
+		// Postgres upgrades from 14.4 only, Redis has no old version to upgrade from
+		assert.Equal(t, 2, report.Count(Skipped))
+		assert.Equal(t, len(report.Results)-2, report.Count(Passed))
+		assert.Empty(t, runner.deployed, "every deployment of every cell is deleted")
+		assert.Contains(t, runner.steps, "restore Redis")
+		assert.Contains(t, runner.steps, "disrupt PostgreSQL")
+	})
+
+	t.Run("failures are reported by cell", func(t *testing.T) {
+		runner := newFakeRunner("scale PostgreSQL", "restore")
+		report, err := (&Engine{Runner: runner}).Run(m)
+		require.NoError(t, err)
+		assert.Empty(t, runner.deployed, "deployments of failed cells are deleted")
+		assert.Equal(t, 5, report.Count(Failed))
+		err = report.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "PostgreSQL 14.6-37490df Small/QaDefault scale: failed to scale to 6 replicas: scale of PostgreSQL failed")
+		assert.Contains(t, err.Error(), "Redis 7.0.5-9181925 Small/QaDefault restore: failed to restore: restore of Redis failed")
+
+		table := report.String()
+		assert.Contains(t, table, "DATA SERVICE")
+		assert.Contains(t, table, "PostgreSQL    14.4-b64d741->14.6-37490df")
+		assert.Contains(t, table, fmt.Sprintf("%d cells: %d passed, 5 failed, 2 skipped", len(report.Results), len(report.Results)-7))
+		assert.Equal(t, len(report.Results)+2, strings.Count(table, "\n"))
+	})
+
+	t.Run("unsupported operations are skipped", func(t *testing.T) {
+		runner := newFakeRunner("unsupported backup")
+		report, err := (&Engine{Runner: runner}).Run(m)
+		require.NoError(t, err)
+		assert.NoError(t, report.Err())
+		// backup and restore of each version
+		assert.Equal(t, 2+3*2, report.Count(Skipped))
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		runner := newFakeRunner("deploy Redis")
+		report, err := (&Engine{Runner: runner, FailFast: true}).Run(m)
+		require.NoError(t, err)
+		last := report.Results[len(report.Results)-1]
+		assert.Equal(t, Failed, last.Status)
+		assert.Equal(t, "Redis", last.DataService.Name)
+		assert.Equal(t, 1, report.Count(Failed))
+		assert.Less(t, len(report.Results), len(m.Cells()))
+	})
+}
@@ -0,0 +1,82 @@
+package pdsmatrix
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Status is the outcome of a cell
+type Status string
+
+const (
+	// Passed is for cells whose steps all succeeded
+	Passed Status = "passed"
+	// Failed is for cells with a failed step
+	Failed Status = "failed"
+	// Skipped is for cells which don't apply to their data service version, or whose operation
+	// the runner doesn't support
+	Skipped Status = "skipped"
+)
+
+// Result is the outcome of a cell
+type Result struct {
+	Cell
+	Status   Status
+	Err      error
+	Duration time.Duration
+}
+
+// Report is the outcome of the cells of a matrix
+type Report struct {
+	Results []Result
+}
+
+// Count returns the number of cells with a status
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Err returns an error listing the failed cells of the report, nil if there are none
+func (r *Report) Err() error {
+	var msgs []string
+	for _, result := range r.Results {
+		if result.Status == Failed {
+			msgs = append(msgs, fmt.Sprintf("%s: %v", result.Cell, result.Err))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d pds matrix cells failed:\n%s", len(msgs), len(r.Results), strings.Join(msgs, "\n"))
+}
+
+// String returns the report as a table with a row by cell and a summary
+func (r *Report) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATA SERVICE\tVERSION\tRESOURCE\tSTORAGE\tOPERATION\tSTATUS\tDURATION\tDETAILS")
+	for _, result := range r.Results {
+		details := ""
+		if result.Err != nil {
+			details = strings.ReplaceAll(result.Err.Error(), "\n", " ")
+		}
+		version := fmt.Sprintf("%s-%s", result.Version, result.Image)
+		if result.UpgradeVersion != "" {
+			version = fmt.Sprintf("%s->%s-%s", version, result.UpgradeVersion, result.UpgradeImage)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", result.DataService.Name, version, result.ResourceTemplate,
+			result.StorageTemplate, result.Operation, result.Status, result.Duration.Round(time.Second), details)
+	}
+	w.Flush()
+	fmt.Fprintf(&buf, "%d cells: %d passed, %d failed, %d skipped\n", len(r.Results), r.Count(Passed), r.Count(Failed), r.Count(Skipped))
+	return buf.String()
+}
@@ -0,0 +1,156 @@
+package pdsmatrix
+
+import (
+	"fmt"
+	"net/http"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
+	"github.com/portworx/torpedo/pkg/errors"
+)
+
+// LibRunner runs the cells with the helpers of drivers/pds/lib on the deployment target of the PDS
+// tests. Its fields are the ones the PDS tests get from SetupPDSTest.
+type LibRunner struct {
+	TenantID           string
+	ProjectID          string
+	DeploymentTargetID string
+	DNSZone            string
+	ServiceType        string
+	// Namespace is the namespace data services are deployed to, NamespaceID its PDS id
+	Namespace   string
+	NamespaceID string
+	// DeploymentName prefixes the names of the deployments
+	DeploymentName string
+	// NodeDisruption disrupts the nodes of a deployment, such as rebooting them. Node disruption
+	// cells are skipped if it is nil.
+	NodeDisruption func(deployment *pds.ModelsDeployment) error
+	// BackupTargetID is the backup target backups are taken to. Backup and restore cells are
+	// skipped if it is empty.
+	BackupTargetID string
+
+	// backups are the last backup and backup job of each deployment
+	backups map[string]backupJob
+}
+
+// backupJob is a backup job of a backup
+type backupJob struct {
+	backupID string
+	jobName  string
+}
+
+// templates returns the ids of the app config, resource and storage templates of a cell
+func (r *LibRunner) templates(c Cell) (string, string, string, error) {
+	dataServiceID := pdslib.GetDataServiceID(c.DataService.Name)
+	if dataServiceID == "" {
+		return "", "", "", fmt.Errorf("data service %s does not exist", c.DataService.Name)
+	}
+	appConfigID, err := pdslib.GetAppConfTemplate(r.TenantID, c.DataService.Name)
+	if err != nil {
+		return "", "", "", err
+	}
+	resourceTemplateID, err := pdslib.GetResourceTemplateID(r.TenantID, dataServiceID, c.ResourceTemplate)
+	if err != nil {
+		return "", "", "", err
+	}
+	storageTemplateID, err := pdslib.GetStorageTemplateID(r.TenantID, c.StorageTemplate)
+	if err != nil {
+		return "", "", "", err
+	}
+	return appConfigID, resourceTemplateID, storageTemplateID, nil
+}
+
+// Deploy deploys the data service of a cell with DeployDataServices, which validates the deployment
+func (r *LibRunner) Deploy(c Cell) (*pds.ModelsDeployment, error) {
+	appConfigID, resourceTemplateID, storageTemplateID, err := r.templates(c)
+	if err != nil {
+		return nil, err
+	}
+	deployment, _, _, err := pdslib.DeployDataServices(c.DataService.Name, r.ProjectID, r.DeploymentTargetID, r.DNSZone,
+		r.DeploymentName, r.NamespaceID, appConfigID, c.Replicas(), r.ServiceType, resourceTemplateID, storageTemplateID,
+		c.Version, c.Image, r.Namespace)
+	return deployment, err
+}
+
+// Validate validates a deployment with ValidateDataServiceDeployment
+func (r *LibRunner) Validate(c Cell, deployment *pds.ModelsDeployment) error {
+	return pdslib.ValidateDataServiceDeployment(deployment, r.Namespace)
+}
+
+// Scale scales a deployment with UpdateDataServices
+func (r *LibRunner) Scale(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error) {
+	appConfigID, resourceTemplateID, _, err := r.templates(c)
+	if err != nil {
+		return nil, err
+	}
+	return pdslib.UpdateDataServices(deployment.GetId(), appConfigID, deployment.GetImageId(),
+		int32(c.DataService.ScaleReplicas), resourceTemplateID, r.Namespace)
+}
+
+// Upgrade upgrades a deployment with UpdateDataServiceVerison
+func (r *LibRunner) Upgrade(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error) {
+	appConfigID, resourceTemplateID, _, err := r.templates(c)
+	if err != nil {
+		return nil, err
+	}
+	return pdslib.UpdateDataServiceVerison(deployment.GetDataServiceId(), deployment.GetId(), appConfigID,
+		c.Replicas(), resourceTemplateID, c.UpgradeImage, r.Namespace, c.UpgradeVersion)
+}
+
+// Backup takes an ad-hoc backup of a deployment to the backup target with CreateAdhocBackup
+func (r *LibRunner) Backup(c Cell, deployment *pds.ModelsDeployment) error {
+	if r.BackupTargetID == "" {
+		return &errors.ErrNotSupported{Type: "pds matrix", Operation: Backup}
+	}
+	backup, jobs, err := pdslib.CreateAdhocBackup(deployment.GetId(), r.BackupTargetID)
+	if backup != nil {
+		if r.backups == nil {
+			r.backups = make(map[string]backupJob)
+		}
+		last := backupJob{backupID: backup.GetId()}
+		if len(jobs) > 0 {
+			last.jobName = jobs[len(jobs)-1].GetName()
+		}
+		r.backups[deployment.GetId()] = last
+	}
+	return err
+}
+
+// Restore restores the last backup of a deployment to a new deployment with RestoreBackup
+func (r *LibRunner) Restore(c Cell, deployment *pds.ModelsDeployment) (*pds.ModelsDeployment, error) {
+	if r.BackupTargetID == "" {
+		return nil, &errors.ErrNotSupported{Type: "pds matrix", Operation: Restore}
+	}
+	last, ok := r.backups[deployment.GetId()]
+	if !ok || last.jobName == "" {
+		return nil, fmt.Errorf("deployment %s has no succeeded backup to restore", deployment.GetId())
+	}
+	name := fmt.Sprintf("%s-restored-%s", r.DeploymentName, pdslib.GetRandomString(4))
+	return pdslib.RestoreBackup(last.backupID, last.jobName, name, r.DeploymentTargetID, r.NamespaceID, r.Namespace)
+}
+
+// DisruptNodes disrupts the nodes of a deployment with the NodeDisruption of the runner
+func (r *LibRunner) DisruptNodes(c Cell, deployment *pds.ModelsDeployment) error {
+	if r.NodeDisruption == nil {
+		return &errors.ErrNotSupported{Type: "pds matrix", Operation: NodeDisruption}
+	}
+	return r.NodeDisruption(deployment)
+}
+
+// Delete deletes the backup of a deployment, if any, and the deployment with DeleteDeployment
+func (r *LibRunner) Delete(deployment *pds.ModelsDeployment) error {
+	if last, ok := r.backups[deployment.GetId()]; ok {
+		if err := pdslib.DeleteBackup(last.backupID); err != nil {
+			return fmt.Errorf("failed to delete backup %s: %v", last.backupID, err)
+		}
+		delete(r.backups, deployment.GetId())
+	}
+	resp, err := pdslib.DeleteDeployment(deployment.GetId())
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("unexpected status code %d deleting deployment %s", resp.StatusCode, deployment.GetId())
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package pdsworkload
+
+import (
+	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Data services with a registered workload
+const (
+	PostgreSQL = "PostgreSQL"
+	Redis      = "Redis"
+	RabbitMQ   = "RabbitMQ"
+	Cassandra  = "Cassandra"
+	MySQL      = "MySQL"
+	Consul     = "Consul"
+)
+
+// scaleFactor and iterations are the workload generation params the tests pass to the lib
+const (
+	scaleFactor = "100"
+	iterations  = "1"
+)
+
+var (
+	// pgbench initializes the pgbench tables of the pds database and runs TPC-B like transactions
+	// with the pgbench image of the lib, in a deployment
+	pgbench = generator{
+		name:   "pgbench",
+		launch: dataServiceWorkload("pgload"),
+		parse:  parsePgbench,
+	}
+
+	// redisBenchmark runs the redis-benchmark tests on the cluster in a loop, in a pod
+	redisBenchmark = generator{
+		name:   "redis-benchmark",
+		launch: dataServiceWorkload("redisbench"),
+		parse:  parseRedisBenchmark,
+	}
+
+	// perfTest runs RabbitMQ perf-test for 100 seconds in a loop, in a pod
+	perfTest = generator{
+		name:   "perf-test",
+		launch: dataServiceWorkload("rmq"),
+		parse:  parsePerfTest,
+	}
+
+	// cassandraStress writes a million rows at consistency level ONE and prints its log, in a
+	// deployment
+	cassandraStress = generator{
+		name:   "cassandra-stress",
+		launch: dataServiceWorkload("cassandra-stress"),
+		parse:  parseCassandraStress,
+	}
+
+	// consulBench registers and flaps services and watches them next to a consul agent, in a
+	// deployment. It has no output to parse.
+	consulBench = generator{
+		name:      "consul-bench",
+		container: "consul-bench",
+		launch: func(spec Spec) (*corev1.Pod, *appsv1.Deployment, error) {
+			deployment, err := pdslib.RunConsulBenchWorkload(spec.Deployment.GetClusterResourceName(), spec.Namespace)
+			return nil, deployment, err
+		},
+	}
+)
+
+// dataServiceWorkload launches the lib workload of the data service of a spec, named after
+// deploymentName
+func dataServiceWorkload(deploymentName string) func(spec Spec) (*corev1.Pod, *appsv1.Deployment, error) {
+	return func(spec Spec) (*corev1.Pod, *appsv1.Deployment, error) {
+		return pdslib.CreateDataServiceWorkloads(pdslib.WorkloadGenerationParams{
+			DataServiceName: spec.DataService,
+			DeploymentName:  deploymentName,
+			DeploymentID:    spec.Deployment.GetId(),
+			ScaleFactor:     scaleFactor,
+			Iterations:      iterations,
+			Namespace:       spec.Namespace,
+		})
+	}
+}
+
+func init() {
+	for dataService, g := range map[string]generator{
+		PostgreSQL: pgbench,
+		Redis:      redisBenchmark,
+		RabbitMQ:   perfTest,
+		Cassandra:  cassandraStress,
+		Consul:     consulBench,
+	} {
+		if err := Register(dataService, newLibWorkload(g)); err != nil {
+			panic(err)
+		}
+	}
+	if err := Register(MySQL, newTpccWorkload("my-tpcc")); err != nil {
+		panic(err)
+	}
+}
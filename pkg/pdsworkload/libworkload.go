@@ -0,0 +1,206 @@
+package pdsworkload
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portworx/sched-ops/k8s/apps"
+	"github.com/portworx/sched-ops/k8s/core"
+	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
+	"github.com/portworx/torpedo/pkg/log"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// generator is a workload generator of drivers/pds/lib
+type generator struct {
+	name string
+	// container is the container of the generator in its pods, the only one if empty
+	container string
+	// launch creates the pod or the deployment of the generator and returns once it runs
+	launch func(spec Spec) (*corev1.Pod, *appsv1.Deployment, error)
+	// parse parses the result of the generator from its output. The results of generators without
+	// one are errors only, the restarts of their container.
+	parse func(output string) (*Result, error)
+}
+
+// libWorkload runs a generator of the lib for the duration of its spec. The generators of the lib
+// run until they are deleted, so the workload completes once its duration elapsed and Stop deletes
+// its pod or deployment.
+type libWorkload struct {
+	generator
+	spec Spec
+
+	mu         sync.Mutex
+	pod        *corev1.Pod
+	deployment *appsv1.Deployment
+	started    time.Time
+	finished   time.Time
+	stopped    bool
+	output     string
+	restarts   int64
+}
+
+func newLibWorkload(g generator) Factory {
+	return func(spec Spec) (Workload, error) {
+		if spec.Deployment == nil || spec.Namespace == "" {
+			return nil, fmt.Errorf("pds workload %s needs a deployment and its namespace", g.name)
+		}
+		return &libWorkload{generator: g, spec: spec}, nil
+	}
+}
+
+func (w *libWorkload) Name() string {
+	return w.name
+}
+
+func (w *libWorkload) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.started.IsZero() {
+		return fmt.Errorf("pds workload %s is already started", w.name)
+	}
+	pod, deployment, err := w.launch(w.spec)
+	if err != nil {
+		return fmt.Errorf("failed to launch pds workload %s: %v", w.name, err)
+	}
+	if pod == nil && deployment == nil {
+		return fmt.Errorf("pds workload %s launched neither a pod nor a deployment", w.name)
+	}
+	w.pod, w.deployment, w.started = pod, deployment, time.Now()
+	log.InfoD("Started pds workload %s in %s for %v", w.name, w.resource(), w.spec.duration())
+	return nil
+}
+
+func (w *libWorkload) Wait(timeout time.Duration) error {
+	started, err := w.startedAt()
+	if err != nil {
+		return err
+	}
+	remaining := time.Until(started.Add(w.spec.duration()))
+	if remaining > timeout {
+		time.Sleep(timeout)
+		return fmt.Errorf("pds workload %s did not complete its %v in %v", w.name, w.spec.duration(), timeout)
+	}
+	time.Sleep(remaining)
+	w.mu.Lock()
+	if w.finished.IsZero() {
+		w.finished = time.Now()
+	}
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *libWorkload) Stop() error {
+	if _, err := w.startedAt(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return nil
+	}
+	output, restarts, err := w.collect()
+	if err != nil {
+		return err
+	}
+	if w.finished.IsZero() {
+		w.finished = time.Now()
+	}
+	w.output, w.restarts, w.stopped = output, restarts, true
+	if w.pod != nil {
+		err = pdslib.DeleteK8sPods(w.pod.Name, w.pod.Namespace)
+	} else {
+		err = pdslib.DeleteK8sDeployments(w.deployment.Name, w.deployment.Namespace)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s of pds workload %s: %v", w.resource(), w.name, err)
+	}
+	return nil
+}
+
+func (w *libWorkload) Result() (*Result, error) {
+	started, err := w.startedAt()
+	if err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	output, restarts, finished, stopped := w.output, w.restarts, w.finished, w.stopped
+	w.mu.Unlock()
+	if !stopped {
+		if output, restarts, err = w.collect(); err != nil {
+			return nil, err
+		}
+	}
+	result := &Result{Errors: restarts, ErrorsOnly: true}
+	if w.parse != nil {
+		if result, err = w.parse(output); err != nil {
+			return nil, fmt.Errorf("failed to parse output of pds workload %s: %v", w.name, err)
+		}
+	}
+	if finished.IsZero() {
+		finished = time.Now()
+	}
+	result.Duration = finished.Sub(started)
+	return result, nil
+}
+
+func (w *libWorkload) Verify(c Criteria) error {
+	result, err := w.Result()
+	if err != nil {
+		return err
+	}
+	return result.Verify(c)
+}
+
+// collect returns the output of the generator container in the pods of the workload and the
+// restarts of the container
+func (w *libWorkload) collect() (string, int64, error) {
+	var pods []corev1.Pod
+	if w.pod != nil {
+		pod, err := core.Instance().GetPodByName(w.pod.Name, w.pod.Namespace)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to get pod %s of pds workload %s: %v", w.pod.Name, w.name, err)
+		}
+		pods = append(pods, *pod)
+	} else {
+		var err error
+		if pods, err = apps.Instance().GetDeploymentPods(w.deployment); err != nil {
+			return "", 0, fmt.Errorf("failed to get pods of %s of pds workload %s: %v", w.resource(), w.name, err)
+		}
+	}
+	var output strings.Builder
+	var restarts int64
+	for _, pod := range pods {
+		podOutput, err := core.Instance().GetPodLog(pod.Name, pod.Namespace, &corev1.PodLogOptions{Container: w.container})
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to get output of pod %s of pds workload %s: %v", pod.Name, w.name, err)
+		}
+		output.WriteString(podOutput)
+		for _, status := range pod.Status.ContainerStatuses {
+			if w.container == "" || status.Name == w.container {
+				restarts += int64(status.RestartCount)
+			}
+		}
+	}
+	return output.String(), restarts, nil
+}
+
+// resource returns the kind and name of the pod or deployment of the workload
+func (w *libWorkload) resource() string {
+	if w.pod != nil {
+		return fmt.Sprintf("pod %s/%s", w.pod.Namespace, w.pod.Name)
+	}
+	return fmt.Sprintf("deployment %s/%s", w.deployment.Namespace, w.deployment.Name)
+}
+
+func (w *libWorkload) startedAt() (time.Time, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started.IsZero() {
+		return time.Time{}, fmt.Errorf("pds workload %s is not started", w.name)
+	}
+	return w.started, nil
+}
@@ -0,0 +1,220 @@
+package pdsworkload
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	pgbenchTPS      = regexp.MustCompile(`^tps = ([\d.]+)`)
+	pgbenchProgress = regexp.MustCompile(`^progress: [\d.]+ s, ([\d.]+) tps, lat ([\d.]+) ms`)
+	pgbenchAverage  = regexp.MustCompile(`^latency average = ([\d.]+) ms`)
+	pgbenchFailed   = regexp.MustCompile(`^number of failed transactions: (\d+)`)
+
+	redisBenchmarkTest = regexp.MustCompile(`^[^:]+: ([\d.]+) requests per second(?:, p50=([\d.]+) msec)?`)
+
+	perfTestSample = regexp.MustCompile(`time [\d.]+ s, sent: (\d+) msg/s, received: (\d+) msg/s, ` +
+		`min/median/75th/95th/99th consumer latency: (\d+)/(\d+)/(\d+)/(\d+)/(\d+) (µs|ms)`)
+	perfTestRate    = regexp.MustCompile(`receiving rate avg: (\d+) msg/s`)
+	perfTestLatency = regexp.MustCompile(`consumer latency min/median/75th/95th/99th (\d+)/(\d+)/(\d+)/(\d+)/(\d+) (µs|ms)`)
+
+	cassandraStressRate   = regexp.MustCompile(`^Op rate\s*:\s*([\d,.]+) op/s`)
+	cassandraStressMedian = regexp.MustCompile(`^Latency median\s*:\s*([\d,.]+) ms`)
+	cassandraStressP95    = regexp.MustCompile(`^Latency 95th percentile\s*:\s*([\d,.]+) ms`)
+	cassandraStressP99    = regexp.MustCompile(`^Latency 99th percentile\s*:\s*([\d,.]+) ms`)
+	cassandraStressErrors = regexp.MustCompile(`^Total errors\s*:\s*([\d,]+)`)
+)
+
+// parsePgbench parses the output of pgbench. The throughput is the one of its summary, or the
+// average of its progress reports if it was stopped before its summary. Latency percentiles are
+// the ones of the average latencies of its progress reports.
+func parsePgbench(output string) (*Result, error) {
+	result := &Result{}
+	var tps, latencies []float64
+	var average float64
+	summary := false
+	for _, line := range lines(output) {
+		if m := pgbenchTPS.FindStringSubmatch(line); m != nil {
+			result.OpsPerSec, summary = number(m[1]), true
+		} else if m := pgbenchProgress.FindStringSubmatch(line); m != nil {
+			tps = append(tps, number(m[1]))
+			latencies = append(latencies, number(m[2]))
+		} else if m := pgbenchAverage.FindStringSubmatch(line); m != nil {
+			average = number(m[1])
+		} else if m := pgbenchFailed.FindStringSubmatch(line); m != nil {
+			result.Errors += int64(number(m[1]))
+		} else if strings.HasPrefix(line, "pgbench: error") || strings.HasPrefix(line, "pgbench: fatal") ||
+			strings.Contains(line, "aborted in command") {
+			result.Errors++
+		}
+	}
+	if !summary && len(tps) == 0 {
+		return nil, fmt.Errorf("no pgbench throughput in output")
+	}
+	if !summary {
+		result.OpsPerSec = mean(tps)
+	}
+	if len(latencies) > 0 {
+		result.Latency = Latency{
+			P50: milliseconds(percentile(latencies, 50)),
+			P95: milliseconds(percentile(latencies, 95)),
+			P99: milliseconds(percentile(latencies, 99)),
+		}
+	} else {
+		result.Latency.P50 = milliseconds(average)
+	}
+	return result, nil
+}
+
+// parseRedisBenchmark parses the quiet output of redis-benchmark looping over its tests. The
+// throughput and median latency are the averages of the tests of all loops, redis-benchmark reports
+// no other latency percentile in its quiet output. Errors are the connection errors.
+func parseRedisBenchmark(output string) (*Result, error) {
+	result := &Result{}
+	var rps, p50 []float64
+	for _, line := range lines(output) {
+		if strings.HasPrefix(line, "ERROR") || strings.Contains(line, "Could not connect") {
+			result.Errors++
+		} else if m := redisBenchmarkTest.FindStringSubmatch(line); m != nil {
+			rps = append(rps, number(m[1]))
+			if m[2] != "" {
+				p50 = append(p50, number(m[2]))
+			}
+		}
+	}
+	if len(rps) == 0 {
+		return nil, fmt.Errorf("no redis-benchmark results in output")
+	}
+	result.OpsPerSec = mean(rps)
+	if len(p50) > 0 {
+		result.Latency.P50 = milliseconds(mean(p50))
+	}
+	return result, nil
+}
+
+// parsePerfTest parses the output of RabbitMQ perf-test runs. The throughput is the average of the
+// receiving rates of the summaries of the runs, or the average of their samples if no run reached
+// its summary, and the same goes for the consumer latency percentiles. Errors are the exceptions
+// perf-test logged.
+func parsePerfTest(output string) (*Result, error) {
+	result := &Result{}
+	var received, p50, p95, p99 []float64
+	var summaryRate, summaryP50, summaryP95, summaryP99 []float64
+	for _, line := range lines(output) {
+		if m := perfTestSample.FindStringSubmatch(line); m != nil {
+			unit := unitOf(m[8])
+			received = append(received, number(m[2]))
+			p50 = append(p50, number(m[4])*unit)
+			p95 = append(p95, number(m[6])*unit)
+			p99 = append(p99, number(m[7])*unit)
+		} else if m := perfTestRate.FindStringSubmatch(line); m != nil {
+			summaryRate = append(summaryRate, number(m[1]))
+		} else if m := perfTestLatency.FindStringSubmatch(line); m != nil {
+			unit := unitOf(m[6])
+			summaryP50 = append(summaryP50, number(m[2])*unit)
+			summaryP95 = append(summaryP95, number(m[4])*unit)
+			summaryP99 = append(summaryP99, number(m[5])*unit)
+		} else if strings.Contains(line, "Exception") || strings.Contains(line, " ERROR ") {
+			result.Errors++
+		}
+	}
+	switch {
+	case len(summaryRate) > 0:
+		result.OpsPerSec = mean(summaryRate)
+	case len(received) > 0:
+		result.OpsPerSec = mean(received)
+	default:
+		return nil, fmt.Errorf("no perf-test receiving rate in output")
+	}
+	if len(summaryP50) > 0 {
+		result.Latency = Latency{
+			P50: milliseconds(mean(summaryP50)),
+			P95: milliseconds(mean(summaryP95)),
+			P99: milliseconds(mean(summaryP99)),
+		}
+	} else if len(received) > 0 {
+		result.Latency = Latency{
+			P50: milliseconds(mean(p50)),
+			P95: milliseconds(mean(p95)),
+			P99: milliseconds(mean(p99)),
+		}
+	}
+	return result, nil
+}
+
+// parseCassandraStress parses the results summary of cassandra-stress
+func parseCassandraStress(output string) (*Result, error) {
+	result := &Result{}
+	rate := false
+	for _, line := range lines(output) {
+		if m := cassandraStressRate.FindStringSubmatch(line); m != nil {
+			result.OpsPerSec, rate = number(m[1]), true
+		} else if m := cassandraStressMedian.FindStringSubmatch(line); m != nil {
+			result.Latency.P50 = milliseconds(number(m[1]))
+		} else if m := cassandraStressP95.FindStringSubmatch(line); m != nil {
+			result.Latency.P95 = milliseconds(number(m[1]))
+		} else if m := cassandraStressP99.FindStringSubmatch(line); m != nil {
+			result.Latency.P99 = milliseconds(number(m[1]))
+		} else if m := cassandraStressErrors.FindStringSubmatch(line); m != nil {
+			result.Errors = int64(number(m[1]))
+		}
+	}
+	if !rate {
+		return nil, fmt.Errorf("no cassandra-stress results summary in output")
+	}
+	return result, nil
+}
+
+// lines returns the non empty lines of the output. Carriage returns end lines too, as generators
+// use them to overwrite their progress.
+func lines(output string) []string {
+	var trimmed []string
+	for _, line := range strings.FieldsFunc(output, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if line = strings.TrimSpace(line); line != "" {
+			trimmed = append(trimmed, line)
+		}
+	}
+	return trimmed
+}
+
+// number parses a number matched by the regular expressions, which may have thousands separators
+func number(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	return f
+}
+
+// unitOf returns the milliseconds of a latency unit
+func unitOf(unit string) float64 {
+	if unit == "µs" {
+		return 0.001
+	}
+	return 1
+}
+
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(math.Round(ms * float64(time.Millisecond)))
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentile returns the nearest rank percentile of the values
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
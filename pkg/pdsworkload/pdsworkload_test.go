@@ -0,0 +1,179 @@
+package pdsworkload
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	"github.com/portworx/torpedo/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		fixture string
+		parse   func(string) (*Result, error)
+		want    Result
+	}{
+		{
+			fixture: "pgbench.log",
+			parse:   parsePgbench,
+			want: Result{OpsPerSec: 750.573117, Errors: 1, Latency: Latency{
+				P50: 12144 * time.Microsecond, P95: 24870 * time.Microsecond, P99: 24870 * time.Microsecond}},
+		},
+		{
+			// pgbench stopped before its summary
+			fixture: "pgbench_stopped.log",
+			parse:   parsePgbench,
+			want: Result{OpsPerSec: 850, Latency: Latency{
+				P50: 11100 * time.Microsecond, P95: 12500 * time.Microsecond, P99: 12500 * time.Microsecond}},
+		},
+		{
+			fixture: "redis-benchmark.log",
+			parse:   parseRedisBenchmark,
+			want:    Result{OpsPerSec: 39000, Errors: 2, Latency: Latency{P50: 1100 * time.Microsecond}},
+		},
+		{
+			fixture: "perf-test.log",
+			parse:   parsePerfTest,
+			// two runs of the perf-test loop
+			want: Result{OpsPerSec: 9900, Errors: 1, Latency: Latency{
+				P50: 10200 * time.Microsecond, P95: 25 * time.Millisecond, P99: 31500 * time.Microsecond}},
+		},
+		{
+			fixture: "cassandra-stress.log",
+			parse:   parseCassandraStress,
+			want: Result{OpsPerSec: 14212, Errors: 3, Latency: Latency{
+				P50: 2900 * time.Microsecond, P95: 7100 * time.Microsecond, P99: 12400 * time.Microsecond}},
+		},
+	} {
+		t.Run(tc.fixture, func(t *testing.T) {
+			output, err := os.ReadFile(filepath.Join("testdata", tc.fixture))
+			require.NoError(t, err)
+			result, err := tc.parse(string(output))
+			require.NoError(t, err)
+			assert.InDelta(t, tc.want.OpsPerSec, result.OpsPerSec, 0.001)
+			assert.Equal(t, tc.want.Errors, result.Errors)
+			assert.Equal(t, tc.want.Latency, result.Latency)
+		})
+	}
+}
+
+func TestParseNoResult(t *testing.T) {
+	output := "Connection refused\n"
+	for name, parse := range map[string]func(string) (*Result, error){
+		"pgbench":          parsePgbench,
+		"redis-benchmark":  parseRedisBenchmark,
+		"perf-test":        parsePerfTest,
+		"cassandra-stress": parseCassandraStress,
+	} {
+		_, err := parse(output)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	result := &Result{OpsPerSec: 800, Errors: 2, Latency: Latency{P99: 20 * time.Millisecond}, Duration: time.Minute}
+	assert.NoError(t, result.Verify(Criteria{MinOpsPerSec: 500, MaxErrors: 2, MaxP99: 50 * time.Millisecond}))
+	assert.NoError(t, result.Verify(Criteria{MaxErrors: 5}))
+
+	err := result.Verify(Criteria{MinOpsPerSec: 1000, MaxP99: 10 * time.Millisecond})
+	require.Error(t, err)
+	assert.Equal(t, "workload result [800.00 ops/s, 2 errors, latency p50 0s p95 0s p99 20ms over 1m0s] does not meet criteria: "+
+		"throughput 800.00 ops/s is below 1000.00 ops/s, 2 errors are above 0 errors, p99 latency 20ms is above 10ms", err.Error())
+
+	errorsOnly := &Result{Errors: 2, Duration: time.Minute, ErrorsOnly: true}
+	assert.NoError(t, errorsOnly.Verify(Criteria{MinOpsPerSec: 1, MaxErrors: 2, MaxP99: time.Millisecond}))
+	assert.EqualError(t, errorsOnly.Verify(Criteria{MinOpsPerSec: 1}),
+		"workload result [2 errors over 1m0s] does not meet criteria: 2 errors are above 0 errors")
+}
+
+func TestRegistry(t *testing.T) {
+	assert.Equal(t, []string{Cassandra, Consul, MySQL, PostgreSQL, RabbitMQ, Redis}, DataServices())
+	assert.Error(t, Register(Redis, newLibWorkload(redisBenchmark)))
+
+	id, name := "deployment-1", "pg-qa-abc123"
+	deployment := &pds.ModelsDeployment{Id: &id, ClusterResourceName: &name}
+	for dataService, want := range map[string]string{
+		PostgreSQL: "pgbench",
+		Redis:      "redis-benchmark",
+		RabbitMQ:   "perf-test",
+		Cassandra:  "cassandra-stress",
+		Consul:     "consul-bench",
+		MySQL:      "tpcc",
+	} {
+		w, err := Get(Spec{DataService: dataService, Deployment: deployment, Namespace: "pds-qa", Duration: 90 * time.Second})
+		require.NoError(t, err, dataService)
+		assert.Equal(t, want, w.Name())
+
+		_, err = w.Result()
+		assert.EqualError(t, err, fmt.Sprintf("pds workload %s is not started", want))
+		assert.Error(t, w.Stop())
+		assert.Error(t, w.Wait(time.Second))
+
+		_, err = Get(Spec{DataService: dataService})
+		assert.Error(t, err, dataService)
+	}
+	assert.Nil(t, consulBench.parse, "consul-bench results are errors only")
+	assert.Equal(t, "consul-bench", consulBench.container)
+
+	_, err := Get(Spec{DataService: "Couchbase"})
+	assert.IsType(t, &errors.ErrNotFound{}, err)
+}
+
+func TestTpccWorkload(t *testing.T) {
+	defer func(run func(string, string, string, string, string, string) (bool, error)) { runTpcc = run }(runTpcc)
+
+	id, name := "deployment-1", "my-qa-abc123"
+	spec := Spec{DataService: MySQL, Deployment: &pds.ModelsDeployment{Id: &id, ClusterResourceName: &name}, Namespace: "pds-qa"}
+	for _, tc := range []struct {
+		name      string
+		succeeded bool
+		err       error
+		wantErr   string
+		errors    int64
+	}{
+		{name: "succeeded", succeeded: true},
+		{name: "failed", err: fmt.Errorf("TPCC Run failed"), wantErr: "pds workload tpcc failed: TPCC Run failed", errors: 1},
+		{name: "failed without error", wantErr: "pds workload tpcc failed: tpcc run failed", errors: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			release := make(chan struct{})
+			runTpcc = func(dataService, deploymentID, scaleFactor, iterations, deploymentName, namespace string) (bool, error) {
+				assert.Equal(t, MySQL, dataService)
+				assert.Equal(t, id, deploymentID)
+				assert.Equal(t, "my-tpcc", deploymentName)
+				assert.Equal(t, "pds-qa", namespace)
+				<-release
+				return tc.succeeded, tc.err
+			}
+			w, err := Get(spec)
+			require.NoError(t, err)
+			require.NoError(t, w.Start())
+			assert.Error(t, w.Start())
+
+			assert.EqualError(t, w.Wait(10*time.Millisecond), "pds workload tpcc did not complete in 10ms")
+			result, err := w.Result()
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), result.Errors)
+
+			close(release)
+			err = w.Wait(time.Minute)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+			assert.NoError(t, w.Stop())
+			result, err = w.Result()
+			require.NoError(t, err)
+			assert.True(t, result.ErrorsOnly)
+			assert.Equal(t, tc.errors, result.Errors)
+			assert.Equal(t, tc.errors == 0, w.Verify(Criteria{MinOpsPerSec: 1}) == nil)
+		})
+	}
+}
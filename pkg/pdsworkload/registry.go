@@ -0,0 +1,43 @@
+package pdsworkload
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/portworx/torpedo/pkg/errors"
+)
+
+// Factory returns the workload of a spec
+type Factory func(spec Spec) (Workload, error)
+
+var factories = make(map[string]Factory)
+
+// Register registers the workload factory of a data service
+func Register(dataService string, f Factory) error {
+	if _, ok := factories[dataService]; ok {
+		return fmt.Errorf("pds workload: %s is already registered", dataService)
+	}
+	factories[dataService] = f
+	return nil
+}
+
+// Get returns the workload of the data service of the spec
+func Get(spec Spec) (Workload, error) {
+	if f, ok := factories[spec.DataService]; ok {
+		return f(spec)
+	}
+	return nil, &errors.ErrNotFound{
+		ID:   spec.DataService,
+		Type: "PDSWorkload",
+	}
+}
+
+// DataServices returns the names of the data services with a registered workload, sorted
+func DataServices() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
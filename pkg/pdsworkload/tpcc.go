@@ -0,0 +1,152 @@
+package pdsworkload
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portworx/sched-ops/k8s/apps"
+	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
+	"github.com/portworx/torpedo/pkg/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// runTpcc runs the TPCC workload of the lib and returns whether it succeeded
+var runTpcc = pdslib.CreateTpccWorkloads
+
+// tpccWorkload runs the TPCC workload of the lib on a MySQL deployment. The lib prepares the
+// schema, runs TPCC for its fixed duration and deletes its deployment once it completed. It
+// reports no throughput, its result is errors only: one error if the run failed.
+type tpccWorkload struct {
+	spec Spec
+	// deploymentName is the name prefix of the TPCC deployment of the lib
+	deploymentName string
+
+	mu       sync.Mutex
+	done     chan struct{}
+	started  time.Time
+	finished time.Time
+	stopped  bool
+	err      error
+}
+
+func newTpccWorkload(deploymentName string) Factory {
+	return func(spec Spec) (Workload, error) {
+		if spec.Deployment == nil || spec.Namespace == "" {
+			return nil, fmt.Errorf("pds workload tpcc needs a deployment and its namespace")
+		}
+		return &tpccWorkload{spec: spec, deploymentName: deploymentName}, nil
+	}
+}
+
+func (w *tpccWorkload) Name() string {
+	return "tpcc"
+}
+
+func (w *tpccWorkload) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done != nil {
+		return fmt.Errorf("pds workload tpcc is already started")
+	}
+	w.done, w.started = make(chan struct{}), time.Now()
+	go func(done chan struct{}) {
+		defer close(done)
+		succeeded, err := runTpcc(MySQL, w.spec.Deployment.GetId(), scaleFactor, iterations, w.deploymentName, w.spec.Namespace)
+		if err == nil && !succeeded {
+			err = fmt.Errorf("tpcc run failed")
+		}
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		// A stopped run fails once its deployment is deleted, which is not an error of the workload
+		if !w.stopped {
+			w.err, w.finished = err, time.Now()
+		}
+	}(w.done)
+	log.InfoD("Started pds workload tpcc on deployment %s", w.spec.Deployment.GetClusterResourceName())
+	return nil
+}
+
+func (w *tpccWorkload) Wait(timeout time.Duration) error {
+	done, err := w.startedDone()
+	if err != nil {
+		return err
+	}
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		return fmt.Errorf("pds workload tpcc did not complete in %v", timeout)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return fmt.Errorf("pds workload tpcc failed: %v", w.err)
+	}
+	return nil
+}
+
+func (w *tpccWorkload) Stop() error {
+	done, err := w.startedDone()
+	if err != nil {
+		return err
+	}
+	select {
+	case <-done:
+		return nil
+	default:
+	}
+	w.mu.Lock()
+	if !w.stopped {
+		w.stopped, w.finished = true, time.Now()
+	}
+	w.mu.Unlock()
+	deployments, err := apps.Instance().ListDeployments(w.spec.Namespace, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list deployments of pds workload tpcc: %v", err)
+	}
+	for _, deployment := range deployments.Items {
+		if !strings.HasPrefix(deployment.Name, w.deploymentName+"-") {
+			continue
+		}
+		if err = pdslib.DeleteK8sDeployments(deployment.Name, deployment.Namespace); err != nil {
+			return fmt.Errorf("failed to delete deployment %s of pds workload tpcc: %v", deployment.Name, err)
+		}
+	}
+	return nil
+}
+
+func (w *tpccWorkload) Result() (*Result, error) {
+	if _, err := w.startedDone(); err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	result := &Result{ErrorsOnly: true}
+	if w.err != nil {
+		result.Errors = 1
+	}
+	finished := w.finished
+	if finished.IsZero() {
+		finished = time.Now()
+	}
+	result.Duration = finished.Sub(w.started)
+	return result, nil
+}
+
+func (w *tpccWorkload) Verify(c Criteria) error {
+	result, err := w.Result()
+	if err != nil {
+		return err
+	}
+	return result.Verify(c)
+}
+
+func (w *tpccWorkload) startedDone() (chan struct{}, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done == nil {
+		return nil, fmt.Errorf("pds workload tpcc is not started")
+	}
+	return w.done, nil
+}
@@ -0,0 +1,110 @@
+// Package pdsworkload runs workload generators on PDS data service deployments through a common
+// interface. Generators are registered by data service name and launched through the workload
+// generators of drivers/pds/lib, next to the deployment. They report a structured result parsed
+// from their output: throughput, errors and latency percentiles. Tests attach a workload to any
+// deployment with Get, run their disruptions while it runs and verify the throughput it sustained
+// with Verify.
+package pdsworkload
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+)
+
+// DefaultDuration is the duration of the workloads of specs with none
+const DefaultDuration = 5 * time.Minute
+
+// Spec is the deployment a workload runs on
+type Spec struct {
+	// DataService is the name of the data service of the deployment, such as PostgreSQL
+	DataService string
+	Deployment  *pds.ModelsDeployment
+	// Namespace is the namespace of the deployment, where the workload runs
+	Namespace string
+	// Duration is how long the workload runs, DefaultDuration if zero. The MySQL TPCC workload
+	// runs the fixed duration of its generator.
+	Duration time.Duration
+}
+
+func (s Spec) duration() time.Duration {
+	if s.Duration == 0 {
+		return DefaultDuration
+	}
+	return s.Duration
+}
+
+// Latency are latency percentiles of the operations of a workload
+type Latency struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+// Result is the outcome of a workload
+type Result struct {
+	// OpsPerSec is the average throughput of the workload, in the operations of its generator:
+	// transactions for pgbench, requests for redis-benchmark, messages for RabbitMQ perf-test
+	OpsPerSec float64
+	// Errors is the number of failed operations or errors reported by the generator, or the failed
+	// runs and restarts of the generators of errors only results
+	Errors  int64
+	Latency Latency
+	// Duration is how long the workload ran
+	Duration time.Duration
+	// ErrorsOnly is set for generators which report no throughput nor latency, only their errors
+	ErrorsOnly bool
+}
+
+func (r *Result) String() string {
+	if r.ErrorsOnly {
+		return fmt.Sprintf("%d errors over %v", r.Errors, r.Duration.Round(time.Second))
+	}
+	return fmt.Sprintf("%.2f ops/s, %d errors, latency p50 %v p95 %v p99 %v over %v",
+		r.OpsPerSec, r.Errors, r.Latency.P50, r.Latency.P95, r.Latency.P99, r.Duration.Round(time.Second))
+}
+
+// Criteria are the thresholds a result is verified against. Zero thresholds are not checked,
+// except MaxErrors which allows no errors. Only MaxErrors is checked on errors only results.
+type Criteria struct {
+	MinOpsPerSec float64
+	MaxErrors    int64
+	MaxP99       time.Duration
+}
+
+// Verify checks the result meets the criteria and returns all the thresholds it doesn't meet
+func (r *Result) Verify(c Criteria) error {
+	var failures []string
+	if !r.ErrorsOnly && c.MinOpsPerSec > 0 && r.OpsPerSec < c.MinOpsPerSec {
+		failures = append(failures, fmt.Sprintf("throughput %.2f ops/s is below %.2f ops/s", r.OpsPerSec, c.MinOpsPerSec))
+	}
+	if r.Errors > c.MaxErrors {
+		failures = append(failures, fmt.Sprintf("%d errors are above %d errors", r.Errors, c.MaxErrors))
+	}
+	if !r.ErrorsOnly && c.MaxP99 > 0 && r.Latency.P99 > c.MaxP99 {
+		failures = append(failures, fmt.Sprintf("p99 latency %v is above %v", r.Latency.P99, c.MaxP99))
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("workload result [%s] does not meet criteria: %s", r, strings.Join(failures, ", "))
+	}
+	return nil
+}
+
+// Workload is a workload generator running on a deployment
+type Workload interface {
+	// Name returns the name of the workload generator, such as pgbench
+	Name() string
+	// Start starts the workload and returns once it runs
+	Start() error
+	// Wait waits for the workload to complete its duration
+	Wait(timeout time.Duration) error
+	// Stop stops the workload, whether it completed or not, and keeps its output for Result
+	Stop() error
+	// Result returns the result of the workload so far, or its final result once it completed or
+	// was stopped
+	Result() (*Result, error)
+	// Verify checks the result of the workload meets the criteria
+	Verify(c Criteria) error
+}
@@ -0,0 +1,31 @@
+package aetos
+
+import (
+	"strconv"
+
+	"github.com/portworx/torpedo/pkg/aetosutil"
+	"github.com/portworx/torpedo/pkg/publisher"
+)
+
+// Name is the name of the Aetos dashboard publisher
+const Name = "aetos"
+
+// aetos publishes test cases to the Aetos dashboard. The dashboard itself is configured by the
+// dashboard flags and may be disabled, in which case only its offline reporters get results.
+type aetos struct {
+	publisher.NotSupportedPublisher
+}
+
+func (a *aetos) TestCaseBegin(tc *publisher.TestCase) error {
+	aetosutil.Get().TestCaseBeginInFile(tc.File, tc.Name, tc.Description, strconv.Itoa(tc.TestRepoID), tc.Tags)
+	return nil
+}
+
+func (a *aetos) TestCaseEnd(tc *publisher.TestCase) error {
+	aetosutil.Get().TestCaseEnd()
+	return nil
+}
+
+func init() {
+	publisher.Register(Name, &aetos{NotSupportedPublisher: publisher.NotSupportedPublisher{Name: Name}})
+}
@@ -0,0 +1,50 @@
+package jira
+
+import (
+	"github.com/portworx/torpedo/pkg/jirautils"
+	"github.com/portworx/torpedo/pkg/publisher"
+)
+
+const (
+	// Name is the name of the Jira publisher
+	Name = "jira"
+
+	// UsernameParam is the parameter of the Jira user
+	UsernameParam = "username"
+	// TokenParam is the parameter of the Jira API token
+	TokenParam = "token"
+	// AccountIDParam is the parameter of the account issues are assigned to
+	AccountIDParam = "accountID"
+)
+
+// jira files issues about failures in Jira
+type jira struct {
+	publisher.NotSupportedPublisher
+}
+
+func (j *jira) Init(params map[string]string) error {
+	if err := publisher.RequireParams(Name, params, UsernameParam, TokenParam); err != nil {
+		return err
+	}
+	if accountID := params[AccountIDParam]; accountID != "" {
+		jirautils.AccountID = accountID
+	}
+	jirautils.Init(params[UsernameParam], params[TokenParam])
+	return nil
+}
+
+func (j *jira) TestCaseBegin(tc *publisher.TestCase) error {
+	return nil
+}
+
+func (j *jira) TestCaseEnd(tc *publisher.TestCase) error {
+	return nil
+}
+
+func (j *jira) CreateIssue(issue *publisher.Issue) (string, error) {
+	return jirautils.CreateIssue(issue.Description, issue.Summary)
+}
+
+func init() {
+	publisher.Register(Name, &jira{NotSupportedPublisher: publisher.NotSupportedPublisher{Name: Name}})
+}
@@ -0,0 +1,324 @@
+// Package publisher publishes test results to result trackers such as TestRail, the Aetos
+// dashboard or Jira.
+//
+// Publishers register themselves by name, like drivers, and are enabled with their parameters.
+// Results are published to all enabled publishers. Operations a publisher does not support, such
+// as filing issues in a dashboard, are skipped for that publisher.
+package publisher
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/portworx/torpedo/pkg/errors"
+	"github.com/portworx/torpedo/pkg/log"
+)
+
+const (
+	// StatusPass is the status of test cases which passed
+	StatusPass = "Pass"
+	// StatusFail is the status of test cases which failed
+	StatusFail = "Fail"
+)
+
+// TestCase is a test case being run
+type TestCase struct {
+	// Name of the test case
+	Name string
+	// Description of the test case
+	Description string
+	// TestRepoID is the ID of the test case in the test repository, 0 if it has none
+	TestRepoID int
+	// RunID is the ID of the test run of the test case in the test repository, set by
+	// publishers which create runs
+	RunID int
+	// Tags of the test case
+	Tags map[string]string
+	// File defining the test case
+	File string
+}
+
+// Result is the result of a test case
+type Result struct {
+	// TestRepoID is the ID of the test case in the test repository
+	TestRepoID int
+	// RunID is the ID of the test run of the test case in the test repository
+	RunID int
+	// Status of the test case, StatusPass or StatusFail
+	Status string
+	// DriverVersion is the version of the storage driver under test
+	DriverVersion string
+	// PxBackupVersion is the version of PX-Backup under test, if any
+	PxBackupVersion string
+}
+
+// Issue is an issue to file about a failure
+type Issue struct {
+	Summary     string
+	Description string
+}
+
+// Publisher publishes test results to a result tracker
+type Publisher interface {
+	// String returns the name of the publisher
+	String() string
+	// Init initializes the publisher with its parameters. It returns ErrNotConfigured if the
+	// parameters the publisher requires are not provided, and ErrUnavailable if its tracker cannot
+	// be reached.
+	Init(params map[string]string) error
+	// TestCaseBegin is called when a test case begins
+	TestCaseBegin(tc *TestCase) error
+	// TestCaseEnd is called when a test case ends
+	TestCaseEnd(tc *TestCase) error
+	// PublishResult publishes the result of a test case
+	PublishResult(r *Result) error
+	// CreateIssue files an issue and returns its key
+	CreateIssue(issue *Issue) (string, error)
+}
+
+// ErrNotConfigured is returned by publishers whose required parameters are not provided
+type ErrNotConfigured struct {
+	// Publisher which is not configured
+	Publisher string
+	// Missing parameters
+	Missing []string
+}
+
+func (e *ErrNotConfigured) Error() string {
+	return fmt.Sprintf("publisher %s is not configured, missing parameters: %s", e.Publisher, strings.Join(e.Missing, ", "))
+}
+
+// ErrUnavailable is returned by publishers whose tracker cannot be reached
+type ErrUnavailable struct {
+	// Publisher whose tracker cannot be reached
+	Publisher string
+	// Cause is the error of the tracker
+	Cause error
+}
+
+func (e *ErrUnavailable) Error() string {
+	return fmt.Sprintf("tracker of publisher %s is unavailable: %v", e.Publisher, e.Cause)
+}
+
+// RequireParams returns ErrNotConfigured if any of the given parameters is empty
+func RequireParams(publisher string, params map[string]string, names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if params[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return &ErrNotConfigured{Publisher: publisher, Missing: missing}
+	}
+	return nil
+}
+
+// NotSupportedPublisher returns ErrNotSupported for all operations. Publishers embed it and
+// implement the operations they support.
+type NotSupportedPublisher struct {
+	Name string
+}
+
+// String returns the name of the publisher
+func (p *NotSupportedPublisher) String() string {
+	return p.Name
+}
+
+// Init does nothing
+func (p *NotSupportedPublisher) Init(params map[string]string) error {
+	return nil
+}
+
+// TestCaseBegin is not supported
+func (p *NotSupportedPublisher) TestCaseBegin(tc *TestCase) error {
+	return &errors.ErrNotSupported{Type: p.Name, Operation: "TestCaseBegin()"}
+}
+
+// TestCaseEnd is not supported
+func (p *NotSupportedPublisher) TestCaseEnd(tc *TestCase) error {
+	return &errors.ErrNotSupported{Type: p.Name, Operation: "TestCaseEnd()"}
+}
+
+// PublishResult is not supported
+func (p *NotSupportedPublisher) PublishResult(r *Result) error {
+	return &errors.ErrNotSupported{Type: p.Name, Operation: "PublishResult()"}
+}
+
+// CreateIssue is not supported
+func (p *NotSupportedPublisher) CreateIssue(issue *Issue) (string, error) {
+	return "", &errors.ErrNotSupported{Type: p.Name, Operation: "CreateIssue()"}
+}
+
+var (
+	publishers = make(map[string]Publisher)
+
+	lock    sync.Mutex
+	enabled []Publisher
+)
+
+// Register registers the given publisher
+func Register(name string, p Publisher) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if _, ok := publishers[name]; ok {
+		return fmt.Errorf("publisher: %s is already registered", name)
+	}
+	log.Infof("Registering publisher name: %s", name)
+	publishers[name] = p
+	return nil
+}
+
+// Get returns a registered publisher
+func Get(name string) (Publisher, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	if p, ok := publishers[name]; ok {
+		return p, nil
+	}
+	return nil, &errors.ErrNotFound{
+		ID:   name,
+		Type: "Publisher",
+	}
+}
+
+// Registered returns the names of the registered publishers
+func Registered() []string {
+	lock.Lock()
+	defer lock.Unlock()
+	var names []string
+	for name := range publishers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Enable initializes the registered publisher with the given parameters and publishes to it.
+// Enabling a publisher which is already enabled does nothing.
+func Enable(name string, params map[string]string) error {
+	p, err := Get(name)
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	for _, e := range enabled {
+		if e == p {
+			lock.Unlock()
+			return nil
+		}
+	}
+	lock.Unlock()
+
+	if err = p.Init(params); err != nil {
+		return err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	enabled = append(enabled, p)
+	return nil
+}
+
+// Disable stops publishing to the given publisher
+func Disable(name string) {
+	lock.Lock()
+	defer lock.Unlock()
+	for i, p := range enabled {
+		if p.String() == name {
+			enabled = append(enabled[:i], enabled[i+1:]...)
+			return
+		}
+	}
+}
+
+// Enabled returns the enabled publishers in the order they were enabled
+func Enabled() []Publisher {
+	lock.Lock()
+	defer lock.Unlock()
+	return append([]Publisher{}, enabled...)
+}
+
+// IsEnabled returns true if the given publisher is enabled
+func IsEnabled(name string) bool {
+	for _, p := range Enabled() {
+		if p.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseParams parses comma separated publisher.key=value parameters into the parameters of each
+// publisher
+func ParseParams(s string) (map[string]map[string]string, error) {
+	params := make(map[string]map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, "=", 2)
+		nameAndKey := strings.SplitN(parts[0], ".", 2)
+		if len(parts) != 2 || len(nameAndKey) != 2 || nameAndKey[0] == "" || nameAndKey[1] == "" {
+			return nil, fmt.Errorf("invalid publisher parameter %q, expected publisher.key=value", kv)
+		}
+		if params[nameAndKey[0]] == nil {
+			params[nameAndKey[0]] = make(map[string]string)
+		}
+		params[nameAndKey[0]][nameAndKey[1]] = parts[1]
+	}
+	return params, nil
+}
+
+// TestCaseBegin notifies the enabled publishers that the test case begins. The file defining the
+// test case defaults to the file of the caller.
+func TestCaseBegin(tc *TestCase) {
+	if tc.File == "" {
+		if _, file, _, ok := runtime.Caller(1); ok {
+			tc.File = file
+		}
+	}
+	for _, p := range Enabled() {
+		logError(p, "begin test case", p.TestCaseBegin(tc))
+	}
+}
+
+// TestCaseEnd notifies the enabled publishers that the test case ends
+func TestCaseEnd(tc *TestCase) {
+	for _, p := range Enabled() {
+		logError(p, "end test case", p.TestCaseEnd(tc))
+	}
+}
+
+// PublishResult publishes the result of a test case to the enabled publishers
+func PublishResult(r *Result) {
+	for _, p := range Enabled() {
+		logError(p, "publish result", p.PublishResult(r))
+	}
+}
+
+// CreateIssue files the issue in the enabled publishers and returns the keys of the issues filed
+func CreateIssue(issue *Issue) []string {
+	var keys []string
+	for _, p := range Enabled() {
+		key, err := p.CreateIssue(issue)
+		logError(p, "create issue", err)
+		if err == nil && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func logError(p Publisher, operation string, err error) {
+	if err == nil {
+		return
+	}
+	if _, ok := err.(*errors.ErrNotSupported); ok {
+		return
+	}
+	log.Errorf("Publisher [%s] failed to %s: %v", p, operation, err)
+}
@@ -0,0 +1,71 @@
+package publisher_test
+
+import (
+	"testing"
+
+	"github.com/portworx/torpedo/pkg/publisher"
+	"github.com/portworx/torpedo/pkg/publisher/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dashboard only supports test cases, like the Aetos dashboard
+type dashboard struct {
+	publisher.NotSupportedPublisher
+	begun []string
+}
+
+func (d *dashboard) TestCaseBegin(tc *publisher.TestCase) error {
+	d.begun = append(d.begun, tc.Name)
+	return nil
+}
+
+// tracker requires a token, like Jira
+type tracker struct {
+	publisher.NotSupportedPublisher
+}
+
+func (t *tracker) Init(params map[string]string) error {
+	return publisher.RequireParams(t.Name, params, "token")
+}
+
+func TestPublish(t *testing.T) {
+	rec := recorder.New("test-recorder")
+	dash := &dashboard{NotSupportedPublisher: publisher.NotSupportedPublisher{Name: "test-dashboard"}}
+	require.NoError(t, publisher.Register(rec.String(), rec))
+	require.NoError(t, publisher.Register(dash.String(), dash))
+	require.NoError(t, publisher.Register("test-tracker", &tracker{publisher.NotSupportedPublisher{Name: "test-tracker"}}))
+	assert.Error(t, publisher.Register(rec.String(), rec), "publishers are registered once")
+
+	params, err := publisher.ParseParams("test-recorder.url=http://localhost:8080/a=b, test-tracker.user=me")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/a=b", params["test-recorder"]["url"])
+	_, err = publisher.ParseParams("token=secret")
+	assert.Error(t, err)
+
+	require.NoError(t, publisher.Enable(rec.String(), params[rec.String()]))
+	require.NoError(t, publisher.Enable(rec.String(), nil), "enabling twice does nothing")
+	require.NoError(t, publisher.Enable(dash.String(), nil))
+	err = publisher.Enable("test-tracker", params["test-tracker"])
+	require.IsType(t, &publisher.ErrNotConfigured{}, err)
+	assert.Equal(t, []string{"token"}, err.(*publisher.ErrNotConfigured).Missing)
+	assert.False(t, publisher.IsEnabled("test-tracker"))
+	defer publisher.Disable(rec.String())
+	defer publisher.Disable(dash.String())
+
+	tc := &publisher.TestCase{Name: "RebootNode", TestRepoID: 123}
+	publisher.TestCaseBegin(tc)
+	assert.Contains(t, tc.File, "publisher_test.go", "test cases are defined in the file of the caller")
+	publisher.PublishResult(&publisher.Result{TestRepoID: 123, Status: publisher.StatusFail})
+	keys := publisher.CreateIssue(&publisher.Issue{Summary: "node-1 did not come up"})
+	publisher.TestCaseEnd(tc)
+
+	assert.Equal(t, []string{"RebootNode"}, dash.begun)
+	assert.Equal(t, []string{"test-recorder-4"}, keys, "only the recorder files issues")
+	var methods []string
+	for _, c := range rec.Calls() {
+		methods = append(methods, c.Method)
+	}
+	assert.Equal(t, []string{"Init", "TestCaseBegin", "PublishResult", "CreateIssue", "TestCaseEnd"}, methods)
+	assert.Equal(t, publisher.StatusFail, rec.Calls()[2].Arg.(*publisher.Result).Status)
+}
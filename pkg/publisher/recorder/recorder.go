@@ -0,0 +1,93 @@
+// Package recorder is a publisher which records the calls it receives instead of publishing them,
+// so that publishing logic can be tested without result trackers.
+package recorder
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/portworx/torpedo/pkg/publisher"
+)
+
+// Name is the name of the recording publisher
+const Name = "recorder"
+
+// Call is a call received by the recorder
+type Call struct {
+	// Method called
+	Method string
+	// Arg of the call, a *publisher.TestCase, *publisher.Result or *publisher.Issue, or the
+	// parameters of Init
+	Arg interface{}
+}
+
+// Recorder records the calls it receives. It is safe for concurrent use.
+type Recorder struct {
+	name string
+
+	sync.Mutex
+	calls []Call
+}
+
+// New returns a recorder with the given name
+func New(name string) *Recorder {
+	return &Recorder{name: name}
+}
+
+// Default is the recorder registered as Name
+var Default = New(Name)
+
+// Calls returns the calls received so far
+func (r *Recorder) Calls() []Call {
+	r.Lock()
+	defer r.Unlock()
+	return append([]Call{}, r.calls...)
+}
+
+// Reset forgets the calls received so far
+func (r *Recorder) Reset() {
+	r.Lock()
+	defer r.Unlock()
+	r.calls = nil
+}
+
+func (r *Recorder) record(method string, arg interface{}) int {
+	r.Lock()
+	defer r.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Arg: arg})
+	return len(r.calls)
+}
+
+func (r *Recorder) String() string {
+	return r.name
+}
+
+func (r *Recorder) Init(params map[string]string) error {
+	r.record("Init", params)
+	return nil
+}
+
+func (r *Recorder) TestCaseBegin(tc *publisher.TestCase) error {
+	r.record("TestCaseBegin", tc)
+	return nil
+}
+
+func (r *Recorder) TestCaseEnd(tc *publisher.TestCase) error {
+	r.record("TestCaseEnd", tc)
+	return nil
+}
+
+func (r *Recorder) PublishResult(result *publisher.Result) error {
+	r.record("PublishResult", result)
+	return nil
+}
+
+// CreateIssue records the issue and returns a key numbered after the calls received
+func (r *Recorder) CreateIssue(issue *publisher.Issue) (string, error) {
+	n := r.record("CreateIssue", issue)
+	return fmt.Sprintf("%s-%d", r.name, n), nil
+}
+
+func init() {
+	publisher.Register(Name, Default)
+}
@@ -0,0 +1,105 @@
+package testrail
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/portworx/torpedo/pkg/log"
+	"github.com/portworx/torpedo/pkg/publisher"
+	"github.com/portworx/torpedo/pkg/testrailuttils"
+)
+
+const (
+	// Name is the name of the TestRail publisher
+	Name = "testrail"
+
+	// HostnameParam is the parameter of the TestRail URL
+	HostnameParam = "hostname"
+	// UsernameParam is the parameter of the TestRail user
+	UsernameParam = "username"
+	// PasswordParam is the parameter of the TestRail password
+	PasswordParam = "password"
+	// MilestoneParam is the parameter of the milestone of the runs
+	MilestoneParam = "milestone"
+	// RunNameParam is the parameter of the name of the run, usually the jenkins job name
+	RunNameParam = "runName"
+	// JobRunIDParam is the parameter of the ID of the jenkins job run
+	JobRunIDParam = "jobRunID"
+	// JenkinsBuildURLParam is the parameter of the jenkins build URL stored with the results
+	JenkinsBuildURLParam = "jenkinsBuildURL"
+)
+
+// testrail publishes test runs and results to TestRail
+type testrail struct {
+	publisher.NotSupportedPublisher
+	hostname string
+
+	sync.Mutex
+	runs map[int]int
+}
+
+func (t *testrail) Init(params map[string]string) error {
+	if err := publisher.RequireParams(Name, params, HostnameParam, UsernameParam, PasswordParam); err != nil {
+		return err
+	}
+	if err := testrailuttils.Init(params[HostnameParam], params[UsernameParam], params[PasswordParam]); err != nil {
+		return &publisher.ErrUnavailable{Publisher: Name, Cause: err}
+	}
+	// once TestRail is reachable, missing details of the run are a misconfiguration
+	if err := publisher.RequireParams(Name, params, MilestoneParam, RunNameParam, JobRunIDParam); err != nil {
+		return fmt.Errorf("not all details provided to update testrail: %v", err)
+	}
+	testrailuttils.MilestoneName = params[MilestoneParam]
+	testrailuttils.RunName = params[RunNameParam]
+	testrailuttils.JobRunID = params[JobRunIDParam]
+	testrailuttils.JenkinsBuildURL = params[JenkinsBuildURLParam]
+	testrailuttils.CreateMilestone()
+	t.hostname = params[HostnameParam]
+	return nil
+}
+
+// TestCaseBegin adds the test case to the run of the milestone and sets the run ID of the test case
+func (t *testrail) TestCaseBegin(tc *publisher.TestCase) error {
+	if tc.TestRepoID == 0 {
+		return nil
+	}
+	tc.RunID = testrailuttils.AddRunsToMilestone(tc.TestRepoID)
+	t.Lock()
+	defer t.Unlock()
+	t.runs[tc.TestRepoID] = tc.RunID
+	return nil
+}
+
+func (t *testrail) TestCaseEnd(tc *publisher.TestCase) error {
+	return nil
+}
+
+// PublishResult adds the result to the test of the run. The run defaults to the run the test
+// case was added to when it began.
+func (t *testrail) PublishResult(r *publisher.Result) error {
+	if r.TestRepoID == 0 {
+		return nil
+	}
+	runID := r.RunID
+	if runID == 0 {
+		t.Lock()
+		runID = t.runs[r.TestRepoID]
+		t.Unlock()
+	}
+	testrailuttils.AddTestEntry(testrailuttils.Testrail{
+		Status:          r.Status,
+		TestID:          r.TestRepoID,
+		RunID:           runID,
+		DriverVersion:   r.DriverVersion,
+		PxBackupVersion: r.PxBackupVersion,
+	})
+	log.Infof("Testrail testrun url: %s/index.php?/runs/view/%d&group_by=cases:custom_automated&group_order=asc&group_id=%d", t.hostname, runID, testrailuttils.PwxProjectID)
+	return nil
+}
+
+func init() {
+	publisher.Register(Name, &testrail{
+		NotSupportedPublisher: publisher.NotSupportedPublisher{Name: Name},
+		runs:                  make(map[int]int),
+	})
+}
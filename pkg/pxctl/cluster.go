@@ -0,0 +1,69 @@
+package pxctl
+
+import (
+	"strings"
+)
+
+// ClusterListOutput is the output of pxctl cluster list
+type ClusterListOutput struct {
+	ClusterID   string
+	ClusterUUID string
+	Status      string
+	Nodes       []ClusterNode
+}
+
+// ClusterNode is a node of pxctl cluster list
+type ClusterNode struct {
+	ID                string
+	SchedulerNodeName string
+	DataIP            string
+	Version           string
+	Status            string
+}
+
+// ParseClusterList parses the output of pxctl cluster list
+func ParseClusterList(output string) (*ClusterListOutput, error) {
+	cluster := &ClusterListOutput{}
+	var nodes *table
+	for _, line := range strings.Split(output, "\n") {
+		columns := splitColumns(line)
+		if nodes != nil {
+			if len(columns) > 1 {
+				row, err := nodes.row(line)
+				if err != nil {
+					return nil, err
+				}
+				cluster.Nodes = append(cluster.Nodes, ClusterNode{
+					ID:                nodes.get(row, "ID"),
+					SchedulerNodeName: nodes.get(row, "SchedulerNodeName"),
+					DataIP:            nodes.get(row, "DataIP"),
+					Version:           nodes.get(row, "Version"),
+					Status:            nodes.get(row, "Status"),
+				})
+				continue
+			}
+			nodes = nil
+		}
+		if len(columns) > 1 && columns[0] == "ID" {
+			nodes = newTable(ClusterList, columns)
+			continue
+		}
+
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Cluster ID":
+			cluster.ClusterID = value
+		case "Cluster UUID":
+			cluster.ClusterUUID = value
+		case "Status":
+			cluster.Status = value
+		}
+	}
+	if cluster.ClusterID == "" {
+		return nil, &ErrParse{Command: ClusterList, Cause: "no cluster ID found"}
+	}
+	return cluster, nil
+}
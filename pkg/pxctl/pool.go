@@ -0,0 +1,137 @@
+package pxctl
+
+import (
+	"strings"
+)
+
+// PoolJSON is a pool of pxctl service pool show -j
+type PoolJSON struct {
+	ID        int32             `json:"ID"`
+	UUID      string            `json:"uuid"`
+	TotalSize uint64            `json:"TotalSize"`
+	Used      uint64            `json:"Used"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// Pool is a pool of pxctl service pool show
+type Pool struct {
+	ID         int
+	UUID       string
+	Type       string
+	IOPriority string
+	Size       string
+	// Status is the status of the pool, such as Online
+	Status string
+	// Drives are the drives of the pool, as printed by pxctl, such as 1: /dev/sdb, Total size 100 GiB, Online
+	Drives []string
+	// LastOperation is the last operation on the pool, if any
+	LastOperation *PoolOperation
+}
+
+// PoolOperation is the last operation of a pool of pxctl service pool show
+type PoolOperation struct {
+	Type    string
+	Status  string
+	Message string
+}
+
+// ParsePoolShowJSON parses the output of pxctl service pool show -j. Older versions of PX print
+// the pools as an array and newer ones as the datapools of an object.
+func ParsePoolShowJSON(output string) ([]PoolJSON, error) {
+	var pools []PoolJSON
+	if strings.HasPrefix(strings.TrimSpace(output), "[") {
+		if err := parseJSON(PoolShow, output, &pools); err != nil {
+			return nil, err
+		}
+		return pools, nil
+	}
+
+	dataPools := struct {
+		DataPools []PoolJSON `json:"datapools"`
+	}{}
+	if err := parseJSON(PoolShow, output, &dataPools); err != nil {
+		return nil, err
+	}
+	return dataPools.DataPools, nil
+}
+
+// ParsePoolShow parses the output of pxctl service pool show
+func ParsePoolShow(output string) ([]Pool, error) {
+	var (
+		pools []Pool
+		pool  *Pool
+		// section is the section of the pool the line is in, drives or the last operation
+		section string
+	)
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		key, value, ok := splitKeyValue(line)
+		if ok && key == "Pool ID" {
+			id, err := parseInt(PoolShow, trimmed, value)
+			if err != nil {
+				return nil, err
+			}
+			pools = append(pools, Pool{ID: id})
+			pool = &pools[len(pools)-1]
+			section = ""
+			continue
+		}
+		if pool == nil || trimmed == "" {
+			continue
+		}
+
+		switch {
+		case ok && key == "Drives" && value == "":
+			section = "Drives"
+			continue
+		case ok && key == "Cache Drives" && value == "":
+			section = "Cache Drives"
+			continue
+		case ok && key == "Last Operation":
+			section = "Last Operation"
+			pool.LastOperation = &PoolOperation{Type: value}
+			continue
+		}
+
+		switch section {
+		case "Drives":
+			if ok && key != "" && strings.Trim(key, "0123456789") == "" {
+				pool.Drives = append(pool.Drives, trimmed)
+				continue
+			}
+		case "Cache Drives":
+			// Cache drives aren't drives of the pool
+			continue
+		case "Last Operation":
+			switch key {
+			case "Status":
+				pool.LastOperation.Status = value
+				continue
+			case "Message":
+				pool.LastOperation.Message = value
+				continue
+			}
+		}
+
+		if !ok {
+			continue
+		}
+		section = ""
+		switch key {
+		case "Type":
+			pool.Type = value
+		case "UUID":
+			pool.UUID = value
+		case "IO Priority":
+			pool.IOPriority = value
+		case "Size":
+			pool.Size = value
+		case "Status":
+			pool.Status = value
+		}
+	}
+	if len(pools) == 0 {
+		return nil, &ErrParse{Command: PoolShow, Cause: "no pool found"}
+	}
+	return pools, nil
+}
@@ -0,0 +1,128 @@
+// Package pxctl parses the text and JSON outputs of pxctl commands into typed structs, so that a
+// change in the output of pxctl fails in one place with the command and line it failed at
+package pxctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Status defines the pxctl status command
+	Status = "status"
+	// ClusterList defines the pxctl cluster list command
+	ClusterList = "cluster list"
+	// VolumeInspect defines the pxctl volume inspect command
+	VolumeInspect = "volume inspect"
+	// PoolShow defines the pxctl service pool show command
+	PoolShow = "service pool show"
+	// NodeStats defines the pxctl service dump --nodestats command
+	NodeStats = "service dump --nodestats"
+)
+
+// ErrParse is error type when the output of a pxctl command can't be parsed
+type ErrParse struct {
+	// Command is the pxctl command of the output
+	Command string
+	// Line is the line of the output the parsing failed at, if any
+	Line string
+	// Cause is the reason of the failure
+	Cause string
+}
+
+func (e *ErrParse) Error() string {
+	if e.Line == "" {
+		return fmt.Sprintf("Failed to parse output of pxctl %s. Cause: %v", e.Command, e.Cause)
+	}
+	return fmt.Sprintf("Failed to parse output of pxctl %s at line [%s]. Cause: %v", e.Command, e.Line, e.Cause)
+}
+
+// columnsRgx matches the runs of tabs pxctl aligns the columns of its tables with
+var columnsRgx = regexp.MustCompile(`\t+`)
+
+// splitColumns splits a line of a pxctl table into its columns
+func splitColumns(line string) []string {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	columns := columnsRgx.Split(line, -1)
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+	return columns
+}
+
+// splitKeyValue splits a `key : value` line of pxctl on its first colon
+func splitKeyValue(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(value), true
+}
+
+// normalizeColumn makes the names of columns comparable across PX versions, which spell them
+// like DATA IP, SCHEDULER_NODE_NAME or SchedulerNodeName
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name))
+}
+
+// table maps the columns of a pxctl table to their index
+type table struct {
+	command string
+	columns map[string]int
+	width   int
+}
+
+func newTable(command string, header []string) *table {
+	t := &table{command: command, columns: make(map[string]int), width: len(header)}
+	for i, name := range header {
+		t.columns[normalizeColumn(name)] = i
+	}
+	return t
+}
+
+// row checks a line of the table has a value for each column
+func (t *table) row(line string) ([]string, error) {
+	columns := splitColumns(line)
+	if len(columns) != t.width {
+		return nil, &ErrParse{
+			Command: t.command,
+			Line:    strings.TrimSpace(line),
+			Cause:   fmt.Sprintf("expected %d columns, got %d", t.width, len(columns)),
+		}
+	}
+	return columns, nil
+}
+
+// shortRow checks a line of the table has a value for at most each column. The missing values of
+// the last columns are empty.
+func (t *table) shortRow(line string) ([]string, error) {
+	columns := splitColumns(line)
+	if len(columns) > t.width {
+		return nil, &ErrParse{
+			Command: t.command,
+			Line:    strings.TrimSpace(line),
+			Cause:   fmt.Sprintf("expected at most %d columns, got %d", t.width, len(columns)),
+		}
+	}
+	return append(columns, make([]string, t.width-len(columns))...), nil
+}
+
+// get returns the value of a column in a row, empty if the table has no such column
+func (t *table) get(row []string, column string) string {
+	if i, ok := t.columns[normalizeColumn(column)]; ok {
+		return row[i]
+	}
+	return ""
+}
+
+func parseJSON(command, output string, v interface{}) error {
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), v); err != nil {
+		return &ErrParse{Command: command, Cause: err.Error()}
+	}
+	return nil
+}
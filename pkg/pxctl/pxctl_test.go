@@ -0,0 +1,95 @@
+package pxctl
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites the golden files with the outputs of the parsers, go test ./pkg/pxctl -update
+var update = flag.Bool("update", false, "update the golden files of the pxctl parsers")
+
+// parsers parse the fixtures of pxctl outputs by file name
+var parsers = map[string]func(string) (interface{}, error){
+	"status.txt": func(output string) (interface{}, error) {
+		return ParseStatus(output)
+	},
+	"status.json": func(output string) (interface{}, error) {
+		return ParseStatusJSON(output)
+	},
+	"cluster_list.txt": func(output string) (interface{}, error) {
+		return ParseClusterList(output)
+	},
+	"volume_inspect.txt": func(output string) (interface{}, error) {
+		return ParseVolumeInspect(output)
+	},
+	"pool_show.txt": func(output string) (interface{}, error) {
+		return ParsePoolShow(output)
+	},
+	"pool_show.json": func(output string) (interface{}, error) {
+		return ParsePoolShowJSON(output)
+	},
+	"nodestats.json": func(output string) (interface{}, error) {
+		return ParseNodeStatsJSON(output)
+	},
+}
+
+// TestGolden parses the pxctl outputs of each PX version in testdata and compares them with their
+// golden files
+func TestGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*", "*"))
+	require.NoError(t, err)
+	for _, fixture := range fixtures {
+		if filepath.Ext(fixture) == ".golden" {
+			continue
+		}
+		parse, ok := parsers[filepath.Base(fixture)]
+		require.True(t, ok, "no parser for fixture %s", fixture)
+
+		t.Run(fixture, func(t *testing.T) {
+			output, err := os.ReadFile(fixture)
+			require.NoError(t, err)
+			parsed, err := parse(string(output))
+			require.NoError(t, err)
+			actual, err := json.MarshalIndent(parsed, "", "  ")
+			require.NoError(t, err)
+
+			golden := fixture + ".golden"
+			if *update {
+				require.NoError(t, os.WriteFile(golden, append(actual, '\n'), 0644))
+			}
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err, "run go test with -update to create the golden file")
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := ParseStatus("PX is not running on this host\n")
+	assert.IsType(t, &ErrParse{}, err)
+
+	_, err = ParseVolumeInspect("\tVolume\t :  1\n\tHA\t :  two\n")
+	assert.EqualError(t, err, `Failed to parse output of pxctl volume inspect at line [HA	 :  two]. Cause: strconv.Atoi: parsing "two": invalid syntax`)
+
+	_, err = ParseVolumeInspect("\tVolume\t :  1\n\tNode\t :  10.0.0.1\n")
+	assert.EqualError(t, err, "Failed to parse output of pxctl volume inspect at line [Node\t :  10.0.0.1]. Cause: replica out of a replica set")
+
+	_, err = ParsePoolShowJSON("PX is not running on this host")
+	assert.IsType(t, &ErrParse{}, err)
+}
+
+func TestParseStatusOfflineNode(t *testing.T) {
+	status, err := ParseStatus("Status: PX is operational\n\tIP\tID\tStatus\tVersion\n\t10.0.0.1\tf703597a\tOffline\n")
+	require.NoError(t, err)
+	require.Len(t, status.Nodes, 1)
+	assert.Equal(t, StatusNode{IP: "10.0.0.1", ID: "f703597a", Status: "Offline"}, status.Nodes[0])
+
+	_, err = ParseStatus("Status: PX is operational\n\tIP\tID\n\t10.0.0.1\tf703597a\tOnline\n")
+	assert.EqualError(t, err, "Failed to parse output of pxctl status at line [10.0.0.1\tf703597a\tOnline]. Cause: expected at most 2 columns, got 3")
+}
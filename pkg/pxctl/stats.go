@@ -0,0 +1,22 @@
+package pxctl
+
+// NodeStatsJSON is the output of pxctl service dump --nodestats -j
+type NodeStatsJSON struct {
+	RelaxedReclaim RelaxedReclaimStats `json:"relaxed_reclaim_stats"`
+}
+
+// RelaxedReclaimStats are the volumes of a node in the relaxed reclaim queue
+type RelaxedReclaimStats struct {
+	Pending int `json:"pending"`
+	Deleted int `json:"deleted"`
+	Skipped int `json:"skipped"`
+}
+
+// ParseNodeStatsJSON parses the output of pxctl service dump --nodestats -j
+func ParseNodeStatsJSON(output string) (*NodeStatsJSON, error) {
+	stats := &NodeStatsJSON{}
+	if err := parseJSON(NodeStats, output, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
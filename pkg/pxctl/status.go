@@ -0,0 +1,116 @@
+package pxctl
+
+import (
+	"strings"
+)
+
+// StatusJSON is the output of pxctl -j status
+type StatusJSON struct {
+	// Status is the status of the node, such as STATUS_OK
+	Status   string `json:"status"`
+	ID       string `json:"id"`
+	Hostname string `json:"hostname"`
+}
+
+// StatusOutput is the output of pxctl status
+type StatusOutput struct {
+	// Status is the status of PX, such as PX is operational
+	Status        string
+	License       string
+	NodeID        string
+	IP            string
+	ClusterID     string
+	ClusterUUID   string
+	Nodes         []StatusNode
+	TotalUsed     string
+	TotalCapacity string
+}
+
+// StatusNode is a node of the cluster summary of pxctl status
+type StatusNode struct {
+	IP                string
+	ID                string
+	SchedulerNodeName string
+	StorageNode       string
+	Used              string
+	Capacity          string
+	Status            string
+	StorageStatus     string
+	Version           string
+}
+
+// ParseStatusJSON parses the output of pxctl -j status
+func ParseStatusJSON(output string) (*StatusJSON, error) {
+	status := &StatusJSON{}
+	if err := parseJSON(Status, output, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// ParseStatus parses the output of pxctl status. The last columns of the rows of the cluster summary
+// which don't have a value for each column are empty.
+func ParseStatus(output string) (*StatusOutput, error) {
+	status := &StatusOutput{}
+	var nodes *table
+	for _, line := range strings.Split(output, "\n") {
+		columns := splitColumns(line)
+		if nodes != nil {
+			// The cluster summary ends at the first line which isn't a row, such as Warnings:
+			if len(columns) > 1 {
+				// Offline nodes have no version, kernel or OS and their rows fewer columns
+				row, err := nodes.shortRow(line)
+				if err != nil {
+					return nil, err
+				}
+				status.Nodes = append(status.Nodes, StatusNode{
+					IP:                nodes.get(row, "IP"),
+					ID:                nodes.get(row, "ID"),
+					SchedulerNodeName: nodes.get(row, "SchedulerNodeName"),
+					StorageNode:       nodes.get(row, "StorageNode"),
+					Used:              nodes.get(row, "Used"),
+					Capacity:          nodes.get(row, "Capacity"),
+					Status:            nodes.get(row, "Status"),
+					StorageStatus:     nodes.get(row, "StorageStatus"),
+					Version:           nodes.get(row, "Version"),
+				})
+				continue
+			}
+			nodes = nil
+		}
+		if len(columns) > 1 && columns[0] == "IP" && columns[1] == "ID" {
+			nodes = newTable(Status, columns)
+			continue
+		}
+
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Status":
+			// Pools and devices have no status of this form, only PX has
+			if status.Status == "" {
+				status.Status = value
+			}
+		case "License":
+			status.License = value
+		case "Node ID":
+			status.NodeID = value
+		case "IP":
+			status.IP = value
+		case "Cluster ID":
+			status.ClusterID = value
+		case "Cluster UUID":
+			status.ClusterUUID = value
+		case "Total Used":
+			status.TotalUsed = value
+		case "Total Capacity":
+			status.TotalCapacity = value
+		}
+	}
+	if status.Status == "" {
+		return nil, &ErrParse{Command: Status, Cause: "no status of PX found"}
+	}
+	return status, nil
+}
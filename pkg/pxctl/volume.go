@@ -0,0 +1,120 @@
+package pxctl
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// attachedRgx matches the state of attached volumes, Attached: <node ID> (<node IP>)
+	attachedRgx = regexp.MustCompile(`^Attached:\s*(\S+)\s*\((.*)\)$`)
+	// replicaRgx matches the replicas of the replica sets, <node> (Pool <pool UUID> )
+	replicaRgx = regexp.MustCompile(`^(\S+)\s*\(Pool\s+(\S+)\s*\)$`)
+)
+
+// VolumeInspectOutput is the output of pxctl volume inspect for a volume
+type VolumeInspectOutput struct {
+	ID         string
+	Name       string
+	Size       string
+	Format     string
+	HA         int
+	IOPriority string
+	Shared     string
+	Status     string
+	// State is the state of the volume, such as Attached: <node ID> (<node IP>) or Detached
+	State string
+	// AttachedOn is the ID of the node the volume is attached on, if attached
+	AttachedOn string
+	// AttachedIP is the IP of the node the volume is attached on, if attached
+	AttachedIP        string
+	DevicePath        string
+	IOsInProgress     int
+	BytesUsed         string
+	ReplicaSets       [][]Replica
+	ReplicationStatus string
+}
+
+// Replica is a replica of a replica set of pxctl volume inspect
+type Replica struct {
+	// Node is the node of the replica, by IP
+	Node string
+	// Pool is the UUID of the pool of the replica
+	Pool string
+}
+
+func parseInt(command, line, value string) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ErrParse{Command: command, Line: line, Cause: err.Error()}
+	}
+	return i, nil
+}
+
+// ParseVolumeInspect parses the output of pxctl volume inspect for a volume
+func ParseVolumeInspect(output string) (*VolumeInspectOutput, error) {
+	volume := &VolumeInspectOutput{}
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "Set ") {
+			volume.ReplicaSets = append(volume.ReplicaSets, []Replica{})
+			continue
+		}
+
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			continue
+		}
+		var err error
+		switch key {
+		case "Volume":
+			volume.ID = value
+		case "Name":
+			volume.Name = value
+		case "Size":
+			volume.Size = value
+		case "Format":
+			volume.Format = value
+		case "HA":
+			volume.HA, err = parseInt(VolumeInspect, trimmed, value)
+		case "IO Priority":
+			volume.IOPriority = value
+		case "Shared":
+			volume.Shared = value
+		case "Status":
+			volume.Status = value
+		case "State":
+			volume.State = value
+			if match := attachedRgx.FindStringSubmatch(value); match != nil {
+				volume.AttachedOn = match[1]
+				volume.AttachedIP = match[2]
+			}
+		case "Device Path":
+			volume.DevicePath = value
+		case "IOs in progress":
+			volume.IOsInProgress, err = parseInt(VolumeInspect, trimmed, value)
+		case "Bytes used":
+			volume.BytesUsed = value
+		case "Node":
+			if len(volume.ReplicaSets) == 0 {
+				return nil, &ErrParse{Command: VolumeInspect, Line: trimmed, Cause: "replica out of a replica set"}
+			}
+			match := replicaRgx.FindStringSubmatch(value)
+			if match == nil {
+				return nil, &ErrParse{Command: VolumeInspect, Line: trimmed, Cause: "expected <node> (Pool <pool UUID> )"}
+			}
+			last := len(volume.ReplicaSets) - 1
+			volume.ReplicaSets[last] = append(volume.ReplicaSets[last], Replica{Node: match[1], Pool: match[2]})
+		case "Replication Status":
+			volume.ReplicationStatus = value
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if volume.ID == "" {
+		return nil, &ErrParse{Command: VolumeInspect, Cause: "no volume found"}
+	}
+	return volume, nil
+}
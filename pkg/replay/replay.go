@@ -0,0 +1,183 @@
+// Package replay makes longevity runs reproducible.
+//
+// All random choices of triggers are drawn from a Source seeded once per run. Each trigger
+// draws from its own stream, so the choices a trigger makes depend only on the seed and on
+// how many times the trigger ran before, not on how triggers interleave. The events of a run
+// are persisted to a Journal which a later run replays in the same order with the same seed.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Source is a seeded source of random numbers split into independent named streams. It is
+// safe for concurrent use.
+type Source struct {
+	seed int64
+
+	sync.Mutex
+	streams map[string]*rand.Rand
+}
+
+// NewSource returns a source with the given seed
+func NewSource(seed int64) *Source {
+	return &Source{
+		seed:    seed,
+		streams: make(map[string]*rand.Rand),
+	}
+}
+
+// Seed returns the seed of the source
+func (s *Source) Seed() int64 {
+	return s.seed
+}
+
+// Intn returns a random number in [0, n) from the given stream. It panics if n <= 0.
+func (s *Source) Intn(stream string, n int) int {
+	s.Lock()
+	defer s.Unlock()
+	return s.streamLocked(stream).Intn(n)
+}
+
+// Perm returns a random permutation of [0, n) from the given stream
+func (s *Source) Perm(stream string, n int) []int {
+	s.Lock()
+	defer s.Unlock()
+	return s.streamLocked(stream).Perm(n)
+}
+
+// Pick returns count distinct random numbers in [0, n) from the given stream, in ascending order.
+// count is capped to n.
+func (s *Source) Pick(stream string, count, n int) []int {
+	if count > n {
+		count = n
+	}
+	if count <= 0 {
+		return []int{}
+	}
+	picked := s.Perm(stream, n)[:count]
+	sort.Ints(picked)
+	return picked
+}
+
+func (s *Source) streamLocked(stream string) *rand.Rand {
+	r, ok := s.streams[stream]
+	if !ok {
+		h := fnv.New64a()
+		h.Write([]byte(stream))
+		r = rand.New(rand.NewSource(s.seed ^ int64(h.Sum64())))
+		s.streams[stream] = r
+	}
+	return r
+}
+
+// Entry is a single event of a run as persisted in the journal
+type Entry struct {
+	// Seq is the position of the event in the run
+	Seq int `json:"seq"`
+	// Seed of the run
+	Seed int64 `json:"seed"`
+	// Trigger which generated the event
+	Trigger string `json:"trigger"`
+	// ID of the event
+	ID string `json:"id"`
+	// Start and End of the event
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// ChaosLevel and Params of the trigger when it ran
+	ChaosLevel int               `json:"chaosLevel"`
+	Params     map[string]string `json:"params,omitempty"`
+	// Targets chosen by the trigger, such as node names
+	Targets []string `json:"targets,omitempty"`
+	// Outcome are the errors of the event
+	Outcome []string `json:"outcome,omitempty"`
+}
+
+// Journal persists the events of a run, one JSON object per line
+type Journal struct {
+	seed int64
+
+	sync.Mutex
+	f   *os.File
+	seq int
+}
+
+// CreateJournal creates the journal file at the given path for a run with the given seed
+func CreateJournal(path string, seed int64) (*Journal, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay journal %s: %v", path, err)
+	}
+	return &Journal{seed: seed, f: f}, nil
+}
+
+// Path returns the path of the journal file
+func (j *Journal) Path() string {
+	return j.f.Name()
+}
+
+// Append appends the given entry to the journal, setting its sequence number and seed
+func (j *Journal) Append(e Entry) error {
+	j.Lock()
+	defer j.Unlock()
+	j.seq++
+	e.Seq = j.seq
+	e.Seed = j.seed
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err = j.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to replay journal %s: %v", j.f.Name(), err)
+	}
+	// a long run may be killed at any time, so every entry is flushed to disk
+	return j.f.Sync()
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.Lock()
+	defer j.Unlock()
+	return j.f.Close()
+}
+
+// ReadJournal reads the entries of the journal at the given path ordered by sequence number
+func ReadJournal(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay journal %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid entry at line %d of replay journal %s: %v", line, path, err)
+		}
+		if len(entries) > 0 && e.Seed != entries[0].Seed {
+			return nil, fmt.Errorf("entry at line %d of replay journal %s has seed %d, expected %d",
+				line, path, e.Seed, entries[0].Seed)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read replay journal %s: %v", path, err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Seq < entries[j].Seq
+	})
+	return entries, nil
+}
@@ -0,0 +1,57 @@
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceStreamsAreReproducible(t *testing.T) {
+	draw := func(s *Source, streams ...string) []int {
+		var drawn []int
+		for _, stream := range streams {
+			drawn = append(drawn, s.Intn(stream, 1000))
+		}
+		return drawn
+	}
+
+	a, b := NewSource(42), NewSource(42)
+	// the order in which streams are used does not change what each stream draws
+	drawnA := draw(a, "rebootNode", "crashNode", "rebootNode")
+	drawnB := draw(b, "crashNode", "rebootNode", "rebootNode")
+	assert.Equal(t, drawnA[0], drawnB[1])
+	assert.Equal(t, drawnA[1], drawnB[0])
+	assert.Equal(t, drawnA[2], drawnB[2])
+
+	assert.NotEqual(t, draw(NewSource(42), "rebootNode", "rebootNode", "rebootNode"),
+		draw(NewSource(43), "rebootNode", "rebootNode", "rebootNode"))
+
+	picked := NewSource(7).Pick("rebootManyNodes", 3, 5)
+	assert.Equal(t, picked, NewSource(7).Pick("rebootManyNodes", 3, 5))
+	assert.Len(t, picked, 3)
+	assert.IsIncreasing(t, picked)
+	assert.Len(t, NewSource(7).Pick("rebootManyNodes", 8, 5), 5)
+	assert.Empty(t, NewSource(7).Pick("rebootManyNodes", 0, 5))
+}
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := CreateJournal(path, 42)
+	require.NoError(t, err)
+	require.NoError(t, j.Append(Entry{Trigger: "rebootManyNodes", ID: "1", Targets: []string{"node-1", "node-3"}}))
+	require.NoError(t, j.Append(Entry{Trigger: "crashNode", ID: "2", Outcome: []string{"node-2 did not come up"}}))
+	require.NoError(t, j.Close())
+
+	entries, err := ReadJournal(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{Seq: 1, Seed: 42, Trigger: "rebootManyNodes", ID: "1", Targets: []string{"node-1", "node-3"}}, entries[0])
+	assert.Equal(t, 2, entries[1].Seq)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"seq":1,"seed":1}`+"\n"+`{"seq":2,"seed":2}`+"\n"), 0644))
+	_, err = ReadJournal(path)
+	assert.Error(t, err, "all entries of a journal come from the same run")
+}
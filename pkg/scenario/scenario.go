@@ -0,0 +1,283 @@
+// Package scenario defines the declarative scenario files which drive the longevity test.
+//
+// A scenario lists the triggers to run along with their intervals, ordering constraints,
+// parameters, active windows and concurrency groups, and the conditions on which the whole
+// run stops. Scenario files are YAML or JSON, for example:
+//
+//	version: v1
+//	name: release-longevity
+//	chaosLevel: 5
+//	stop:
+//	  duration: 24h
+//	  maxFailures: 10
+//	triggers:
+//	- name: deployApps
+//	  interval: 2h
+//	- name: rebootNode
+//	  chaosLevel: 8
+//	  after: [deployApps]
+//	  group: disruptive
+//	- name: crashNode
+//	  after: [deployApps]
+//	  group: disruptive
+//	  duration: 6h
+//	- name: volumeResize
+//	  group: volume-ops
+//	  claims: [app:shared, pool:shared]
+//	  params:
+//	    size-increase-gib: "5"
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/portworx/torpedo/pkg/claims"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// Version is the scenario format version understood by this package
+	Version = "v1"
+	// DefaultGroup is the concurrency group of triggers which do not declare one
+	DefaultGroup = "default"
+	// MaxChaosLevel is the highest supported chaos level
+	MaxChaosLevel = 10
+)
+
+// Scenario is a declarative description of a longevity run
+type Scenario struct {
+	// Version of the scenario format
+	Version string `json:"version"`
+	// Name of the scenario, used in logs and reports
+	Name string `json:"name,omitempty"`
+	// ChaosLevel is the chaos level of triggers which do not set their own
+	ChaosLevel int `json:"chaosLevel,omitempty"`
+	// Settings are the non trigger keys of the longevity-triggers ConfigMap, such as email settings
+	Settings map[string]string `json:"settings,omitempty"`
+	// Stop are the conditions on which the whole run stops
+	Stop StopConditions `json:"stop,omitempty"`
+	// Triggers to run. Triggers which are not listed do not run.
+	Triggers []Trigger `json:"triggers"`
+}
+
+// StopConditions are the conditions on which a scenario stops. Zero values are not checked.
+type StopConditions struct {
+	// Duration after which no trigger is started
+	Duration metav1.Duration `json:"duration,omitempty"`
+	// MaxFailures is the number of failed trigger runs after which no trigger is started
+	MaxFailures int `json:"maxFailures,omitempty"`
+	// MaxRuns is the total number of trigger runs after which no trigger is started
+	MaxRuns int `json:"maxRuns,omitempty"`
+}
+
+// Trigger declares how and when a single trigger runs
+type Trigger struct {
+	// Name of the trigger, as registered with the longevity test
+	Name string `json:"name"`
+	// Interval before the first and between two runs of the trigger. Overrides the interval of the chaos level.
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// ChaosLevel of the trigger. Defaults to the chaos level of the scenario.
+	ChaosLevel int `json:"chaosLevel,omitempty"`
+	// After lists the triggers which must have completed at least once before this trigger runs
+	After []string `json:"after,omitempty"`
+	// Group is the concurrency group of the trigger. Only one trigger of a group runs at a time.
+	Group string `json:"group,omitempty"`
+	// Claims on resources such as "node/any" or "pool:shared" which replace the default claims
+	// of the trigger. Triggers run concurrently only when their claims do not conflict.
+	Claims []string `json:"claims,omitempty"`
+	// Params are trigger specific parameters, read by the triggers with GetTriggerParam of the
+	// tests package
+	Params map[string]string `json:"params,omitempty"`
+	// Delay before the trigger may run for the first time, counted from the start of the scenario
+	Delay metav1.Duration `json:"delay,omitempty"`
+	// Duration for which the trigger stays active, counted from the start of the scenario
+	Duration metav1.Duration `json:"duration,omitempty"`
+	// MaxRuns is the number of times the trigger runs before it is disabled
+	MaxRuns int `json:"maxRuns,omitempty"`
+}
+
+// Load reads and parses the scenario file at the given path
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file %s: %v", path, err)
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// Parse parses a YAML or JSON scenario. Unknown fields are rejected so that typos do not go unnoticed.
+func Parse(data []byte) (*Scenario, error) {
+	s := &Scenario{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Trigger returns the trigger with the given name
+func (s *Scenario) Trigger(name string) (*Trigger, bool) {
+	for i := range s.Triggers {
+		if s.Triggers[i].Name == name {
+			return &s.Triggers[i], true
+		}
+	}
+	return nil, false
+}
+
+// ChaosLevelOf returns the effective chaos level of the given trigger
+func (s *Scenario) ChaosLevelOf(t *Trigger) int {
+	if t.ChaosLevel != 0 {
+		return t.ChaosLevel
+	}
+	return s.ChaosLevel
+}
+
+// GroupOf returns the effective concurrency group of the given trigger
+func (s *Scenario) GroupOf(t *Trigger) string {
+	if t.Group != "" {
+		return t.Group
+	}
+	return DefaultGroup
+}
+
+// ParseClaims parses the claims of the trigger
+func (t *Trigger) ParseClaims() ([]claims.Claim, error) {
+	parsed := make([]claims.Claim, 0, len(t.Claims))
+	for _, c := range t.Claims {
+		claim, err := claims.Parse(c)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, claim)
+	}
+	return parsed, nil
+}
+
+// Validate checks the scenario up front. isKnown reports whether a trigger name is registered.
+// All problems found are returned together.
+func (s *Scenario) Validate(isKnown func(name string) bool) error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if s.Version != Version {
+		addProblem("unsupported version %q, expected %q", s.Version, Version)
+	}
+	if s.ChaosLevel < 0 || s.ChaosLevel > MaxChaosLevel {
+		addProblem("chaosLevel %d is out of range [0, %d]", s.ChaosLevel, MaxChaosLevel)
+	}
+	if s.Stop.Duration.Duration < 0 || s.Stop.MaxFailures < 0 || s.Stop.MaxRuns < 0 {
+		addProblem("stop conditions cannot be negative")
+	}
+	if len(s.Triggers) == 0 {
+		addProblem("no triggers declared")
+	}
+
+	seen := make(map[string]bool)
+	for i, t := range s.Triggers {
+		if t.Name == "" {
+			addProblem("trigger #%d has no name", i+1)
+			continue
+		}
+		if seen[t.Name] {
+			addProblem("trigger %s is declared more than once", t.Name)
+		}
+		seen[t.Name] = true
+		if !isKnown(t.Name) {
+			addProblem("trigger %s is not a registered trigger", t.Name)
+		}
+		if t.ChaosLevel < 0 || t.ChaosLevel > MaxChaosLevel {
+			addProblem("trigger %s: chaosLevel %d is out of range [0, %d]", t.Name, t.ChaosLevel, MaxChaosLevel)
+		}
+		if t.Interval.Duration < 0 || t.Delay.Duration < 0 || t.Duration.Duration < 0 || t.MaxRuns < 0 {
+			addProblem("trigger %s: interval, delay, duration and maxRuns cannot be negative", t.Name)
+		}
+		if _, err := t.ParseClaims(); err != nil {
+			addProblem("trigger %s: %v", t.Name, err)
+		}
+		if t.Duration.Duration != 0 && t.Delay.Duration >= t.Duration.Duration {
+			addProblem("trigger %s: delay %v leaves no time within duration %v", t.Name, t.Delay.Duration, t.Duration.Duration)
+		}
+	}
+
+	for _, t := range s.Triggers {
+		for _, dep := range t.After {
+			if dep == t.Name {
+				addProblem("trigger %s cannot run after itself", t.Name)
+			} else if !seen[dep] {
+				addProblem("trigger %s runs after %s which is not declared in the scenario", t.Name, dep)
+			}
+		}
+	}
+	if cycle := s.findCycle(); len(cycle) > 0 {
+		addProblem("ordering constraints form a cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid scenario %s:\n  %s", s.Name, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// findCycle returns the triggers forming a cycle of ordering constraints, if any
+func (s *Scenario) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i, p := range path {
+				if p == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		case visited:
+			return nil
+		}
+		t, ok := s.Trigger(name)
+		if !ok {
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range t.After {
+			if dep == name {
+				continue
+			}
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	names := make([]string, 0, len(s.Triggers))
+	for _, t := range s.Triggers {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package scenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/portworx/torpedo/pkg/claims"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testScenario = `
+version: v1
+name: test
+chaosLevel: 5
+stop:
+  duration: 10h
+  maxFailures: 2
+triggers:
+- name: deployApps
+  interval: 2h
+  maxRuns: 1
+- name: rebootNode
+  chaosLevel: 8
+  after: [deployApps]
+  group: disruptive
+- name: crashNode
+  after: [rebootNode]
+  group: disruptive
+  claims: [node/any, app:shared]
+  delay: 1h
+  duration: 3h
+  params:
+    nodes: "2"
+`
+
+func isKnown(name string) bool {
+	return name == "deployApps" || name == "rebootNode" || name == "crashNode"
+}
+
+func TestParseAndValidate(t *testing.T) {
+	s, err := Parse([]byte(testScenario))
+	require.NoError(t, err)
+	require.NoError(t, s.Validate(isKnown))
+
+	crash, ok := s.Trigger("crashNode")
+	require.True(t, ok)
+	assert.Equal(t, 3*time.Hour, crash.Duration.Duration)
+	assert.Equal(t, "2", crash.Params["nodes"])
+	crashClaims, err := crash.ParseClaims()
+	require.NoError(t, err)
+	assert.Equal(t, []claims.Claim{
+		{Resource: claims.Node, Instance: claims.AnyInstance, Mode: claims.Exclusive},
+		{Resource: claims.App, Instance: claims.AllInstances, Mode: claims.Shared},
+	}, crashClaims)
+	assert.Equal(t, 5, s.ChaosLevelOf(crash))
+	deploy, _ := s.Trigger("deployApps")
+	assert.Equal(t, DefaultGroup, s.GroupOf(deploy))
+
+	_, err = Parse([]byte("version: v1\ntriggers:\n- name: rebootNode\n  intervall: 1h\n"))
+	assert.Error(t, err, "unknown fields should be rejected")
+
+	bad, err := Parse([]byte(`
+version: v2
+triggers:
+- name: rebootNode
+  after: [crashNode]
+- name: crashNode
+  after: [rebootNode, upgrade]
+- name: unknownTrigger
+  chaosLevel: 11
+  claims: [node:sometimes]
+`))
+	require.NoError(t, err)
+	err = bad.Validate(isKnown)
+	require.Error(t, err)
+	for _, problem := range []string{
+		"unsupported version",
+		"unknownTrigger is not a registered trigger",
+		"chaosLevel 11 is out of range",
+		"runs after upgrade which is not declared",
+		"cycle: crashNode -> rebootNode -> crashNode",
+		"invalid mode \"sometimes\"",
+	} {
+		assert.Contains(t, err.Error(), problem)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	s, err := Parse([]byte(testScenario))
+	require.NoError(t, err)
+	start := time.Now()
+	tr := NewTracker(s, start)
+
+	assert.True(t, tr.Ready("deployApps", start))
+	assert.False(t, tr.Ready("rebootNode", start), "rebootNode runs only after deployApps")
+	assert.Same(t, tr.GroupLock("rebootNode"), tr.GroupLock("crashNode"))
+	assert.NotSame(t, tr.GroupLock("rebootNode"), tr.GroupLock("deployApps"))
+
+	tr.RecordRun("deployApps")
+	assert.True(t, tr.Done("deployApps", start), "deployApps runs only once")
+	assert.True(t, tr.Ready("rebootNode", start))
+
+	tr.RecordRun("rebootNode")
+	assert.False(t, tr.Ready("crashNode", start.Add(30*time.Minute)), "crashNode is delayed by an hour")
+	assert.True(t, tr.Ready("crashNode", start.Add(time.Hour)))
+	assert.True(t, tr.Done("crashNode", start.Add(3*time.Hour)), "crashNode is active for 3 hours")
+	assert.False(t, tr.Done("rebootNode", start.Add(3*time.Hour)))
+
+	tr.RecordFailure()
+	assert.False(t, tr.Stopped(start))
+	tr.RecordFailure()
+	assert.True(t, tr.Stopped(start), "scenario stops after 2 failures")
+
+	tr = NewTracker(s, start)
+	assert.True(t, tr.Stopped(start.Add(10*time.Hour)))
+	assert.True(t, tr.Done("crashNode", start.Add(4*time.Hour)))
+}
@@ -0,0 +1,135 @@
+package scenario
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker keeps the runtime state of a scenario and decides when each trigger may run
+type Tracker struct {
+	scenario *Scenario
+	start    time.Time
+
+	sync.Mutex
+	runs     map[string]int
+	total    int
+	failures int
+	groups   map[string]*sync.Mutex
+}
+
+// NewTracker returns a tracker for the given validated scenario started at start
+func NewTracker(s *Scenario, start time.Time) *Tracker {
+	t := &Tracker{
+		scenario: s,
+		start:    start,
+		runs:     make(map[string]int),
+		groups:   make(map[string]*sync.Mutex),
+	}
+	for i := range s.Triggers {
+		group := s.GroupOf(&s.Triggers[i])
+		if _, ok := t.groups[group]; !ok {
+			t.groups[group] = &sync.Mutex{}
+		}
+	}
+	return t
+}
+
+// Stopped returns true once any stop condition of the scenario is met
+func (t *Tracker) Stopped(now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+	return t.stoppedLocked(now)
+}
+
+func (t *Tracker) stoppedLocked(now time.Time) bool {
+	stop := t.scenario.Stop
+	switch {
+	case stop.Duration.Duration != 0 && now.Sub(t.start) >= stop.Duration.Duration:
+		return true
+	case stop.MaxFailures != 0 && t.failures >= stop.MaxFailures:
+		return true
+	case stop.MaxRuns != 0 && t.total >= stop.MaxRuns:
+		return true
+	}
+	return false
+}
+
+// Done returns true if the given trigger will never run again, either because the scenario
+// stopped, its active window or run budget is used up, or a trigger it runs after is done
+// without ever having run.
+func (t *Tracker) Done(name string, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+	return t.stoppedLocked(now) || t.doneLocked(name, now)
+}
+
+func (t *Tracker) doneLocked(name string, now time.Time) bool {
+	trigger, ok := t.scenario.Trigger(name)
+	if !ok {
+		return true
+	}
+	if trigger.Duration.Duration != 0 && now.Sub(t.start) >= trigger.Duration.Duration {
+		return true
+	}
+	if trigger.MaxRuns != 0 && t.runs[name] >= trigger.MaxRuns {
+		return true
+	}
+	for _, dep := range trigger.After {
+		// validation guarantees there are no cycles
+		if t.runs[dep] == 0 && t.doneLocked(dep, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ready returns true if the given trigger may run now, ignoring its interval which is
+// tracked by the caller
+func (t *Tracker) Ready(name string, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+	if t.stoppedLocked(now) || t.doneLocked(name, now) {
+		return false
+	}
+	trigger, _ := t.scenario.Trigger(name)
+	if now.Sub(t.start) < trigger.Delay.Duration {
+		return false
+	}
+	for _, dep := range trigger.After {
+		if t.runs[dep] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// RecordRun records a completed run of the given trigger
+func (t *Tracker) RecordRun(name string) {
+	t.Lock()
+	defer t.Unlock()
+	t.runs[name]++
+	t.total++
+}
+
+// RecordFailure records a failed trigger run
+func (t *Tracker) RecordFailure() {
+	t.Lock()
+	defer t.Unlock()
+	t.failures++
+}
+
+// Runs returns the number of completed runs of the given trigger
+func (t *Tracker) Runs(name string) int {
+	t.Lock()
+	defer t.Unlock()
+	return t.runs[name]
+}
+
+// GroupLock returns the lock shared by the concurrency group of the given trigger
+func (t *Tracker) GroupLock(name string) *sync.Mutex {
+	trigger, ok := t.scenario.Trigger(name)
+	if !ok {
+		return &sync.Mutex{}
+	}
+	return t.groups[t.scenario.GroupOf(trigger)]
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/portworx/torpedo/drivers/node"
 	"github.com/portworx/torpedo/drivers/scheduler"
 	"github.com/portworx/torpedo/pkg/aetosutil"
+	"github.com/portworx/torpedo/pkg/backuplocation"
 	"github.com/portworx/torpedo/pkg/log"
 	. "github.com/portworx/torpedo/tests"
 	"os"
@@ -37,6 +38,8 @@ func getGlobalBucketName(provider string) string {
 		return globalAzureBucketName
 	case drivers.ProviderGke:
 		return globalGCPBucketName
+	case backuplocation.ProviderS3ObjectLock:
+		return globalS3BucketName
 	default:
 		return globalAWSBucketName
 	}
@@ -46,6 +49,8 @@ func getGlobalLockedBucketName(provider string) string {
 	switch provider {
 	case drivers.ProviderAws:
 		return globalAWSLockedBucketName
+	case backuplocation.ProviderS3ObjectLock:
+		return globalS3LockedBucketName
 	default:
 		log.Errorf("environment variable [%s] not provided with valid values", "PROVIDERS")
 		return ""
@@ -85,7 +90,7 @@ func BackupInitInstance() {
 		log.FailOnError(err, "Error occurred while Backup Driver Initialization")
 	}
 
-	SetupTestRail()
+	SetupResultPublishers()
 
 	// Getting Px version info
 	pxVersion, err := Inst().V.GetDriverVersion()
@@ -136,6 +141,10 @@ var _ = BeforeSuite(func() {
 			globalGCPBucketName = fmt.Sprintf("%s-%s", globalGCPBucketPrefix, bucketNameSuffix)
 			CreateBucket(provider, globalGCPBucketName)
 			log.Infof("Bucket created with name - %s", globalGCPBucketName)
+		case backuplocation.ProviderS3ObjectLock:
+			globalS3BucketName = fmt.Sprintf("%s-%s", globalS3BucketPrefix, bucketNameSuffix)
+			CreateBucket(provider, globalS3BucketName)
+			log.Infof("Bucket created with name - %s", globalS3BucketName)
 		}
 	}
 	lockedBucketNameSuffix, present := os.LookupEnv("LOCKED_BUCKET_NAME")
@@ -148,6 +157,8 @@ var _ = BeforeSuite(func() {
 				globalAzureLockedBucketName = fmt.Sprintf("%s-%s", globalAzureLockedBucketPrefix, lockedBucketNameSuffix)
 			case drivers.ProviderGke:
 				globalGCPLockedBucketName = fmt.Sprintf("%s-%s", globalGCPLockedBucketPrefix, lockedBucketNameSuffix)
+			case backuplocation.ProviderS3ObjectLock:
+				globalS3LockedBucketName = fmt.Sprintf("%s-%s", globalS3LockedBucketPrefix, lockedBucketNameSuffix)
 			}
 		}
 	} else {
@@ -255,6 +266,9 @@ var _ = AfterSuite(func() {
 		case drivers.ProviderGke:
 			DeleteBucket(provider, globalGCPBucketName)
 			log.Infof("Bucket deleted - %s", globalGCPBucketName)
+		case backuplocation.ProviderS3ObjectLock:
+			DeleteBucket(provider, globalS3BucketName)
+			log.Infof("Bucket deleted - %s", globalS3BucketName)
 		}
 	}
 
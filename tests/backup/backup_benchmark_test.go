@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	"github.com/pborman/uuid"
+	api "github.com/portworx/px-backup-api/pkg/apis/v1"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/node"
+	"github.com/portworx/torpedo/pkg/backupbench"
+	"github.com/portworx/torpedo/pkg/log"
+	. "github.com/portworx/torpedo/tests"
+	"io/ioutil"
+	"strconv"
+	"time"
+)
+
+const (
+	// benchmarkBaselineEnv is the path of the JSON baseline of the benchmark, which runs only if set
+	benchmarkBaselineEnv = "BACKUP_BENCHMARK_BASELINE"
+	// benchmarkDataSetsEnv is the path of a JSON list of data sets, defaultBenchmarkDataSets if unset
+	benchmarkDataSetsEnv = "BACKUP_BENCHMARK_DATASETS"
+	// benchmarkIterationsEnv is the number of backups and restores of each data set
+	benchmarkIterationsEnv = "BACKUP_BENCHMARK_ITERATIONS"
+	// benchmarkUpdateBaselineEnv saves the results as the new baseline instead of failing on regressions
+	benchmarkUpdateBaselineEnv = "BACKUP_BENCHMARK_UPDATE_BASELINE"
+)
+
+var defaultBenchmarkDataSets = []backupbench.DataSet{
+	{Name: "bench-small", Namespaces: 1, VolumesPerNamespace: 2, VolumeSize: "5Gi", FilesPerVolume: 10, FileSize: 100 << 20, ResourcesPerNamespace: 50},
+	{Name: "bench-wide", Namespaces: 4, VolumesPerNamespace: 4, VolumeSize: "5Gi", FilesPerVolume: 100, FileSize: 10 << 20, ResourcesPerNamespace: 200},
+}
+
+func getBenchmarkDataSets() ([]backupbench.DataSet, error) {
+	path := getEnv(benchmarkDataSetsEnv, "")
+	if path == "" {
+		return defaultBenchmarkDataSets, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var dataSets []backupbench.DataSet
+	if err := json.Unmarshal(data, &dataSets); err != nil {
+		return nil, fmt.Errorf("invalid data sets %s: %v", path, err)
+	}
+	return dataSets, nil
+}
+
+// This testcase benchmarks backups and restores of generated data sets against a baseline
+var _ = Describe("{BackupRestoreBenchmark}", func() {
+	var (
+		baselinePath       string
+		credName           string
+		cloudCredUID       string
+		clusterUid         string
+		clusterStatus      api.ClusterInfo_StatusInfo_Status
+		backupLocationName string
+		backupLocationUID  string
+		generated          []string
+	)
+	backupLocationMap := make(map[string]string)
+	generator := &backupbench.Generator{Core: core.Instance()}
+
+	JustBeforeEach(func() {
+		StartTorpedoTest("BackupRestoreBenchmark", "Benchmark backups and restores of data sets against a baseline", nil, 0)
+		baselinePath = getEnv(benchmarkBaselineEnv, "")
+		if baselinePath == "" {
+			Skip(fmt.Sprintf("Skip benchmark as %s is not set", benchmarkBaselineEnv))
+		}
+	})
+	It("Benchmark backups and restores of data sets", func() {
+		providers := getProviders()
+		dataSets, err := getBenchmarkDataSets()
+		log.FailOnError(err, "Getting data sets to benchmark")
+		baseline, err := backupbench.LoadBaseline(baselinePath)
+		log.FailOnError(err, "Loading baseline %s", baselinePath)
+
+		Step("Creating cloud credential and backup location", func() {
+			provider := providers[0]
+			credName = fmt.Sprintf("%s-%s-%v", "cred", provider, time.Now().Unix())
+			cloudCredUID = uuid.New()
+			CreateCloudCredential(provider, credName, cloudCredUID, orgID)
+			backupLocationName = fmt.Sprintf("%s-%s-bench", provider, getGlobalBucketName(provider))
+			backupLocationUID = uuid.New()
+			backupLocationMap[backupLocationUID] = backupLocationName
+			err := CreateBackupLocation(provider, backupLocationName, backupLocationUID, credName, cloudCredUID,
+				getGlobalBucketName(provider), orgID, "")
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating backup location %s", backupLocationName))
+		})
+
+		Step("Register cluster for backup", func() {
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = CreateSourceAndDestClusters(orgID, "", "", ctx)
+			dash.VerifyFatal(err, nil, "Creating source and destination cluster")
+			clusterStatus, clusterUid = Inst().Backup.RegisterBackupCluster(orgID, SourceClusterName, "")
+			dash.VerifyFatal(clusterStatus, api.ClusterInfo_StatusInfo_Online, fmt.Sprintf("Verifying backup cluster %s", SourceClusterName))
+		})
+
+		iterations, err := strconv.Atoi(getEnv(benchmarkIterationsEnv, "3"))
+		log.FailOnError(err, "Parsing %s", benchmarkIterationsEnv)
+		runner := &backupbench.Runner{
+			Driver: Inst().Backup,
+			Env: backupbench.Env{
+				OrgID:             orgID,
+				Cluster:           SourceClusterName,
+				ClusterUID:        clusterUid,
+				BackupLocation:    backupLocationName,
+				BackupLocationUID: backupLocationUID,
+				RestoreCluster:    SourceClusterName,
+			},
+			Iterations: iterations,
+			Sampler:    &backupbench.NodeSampler{Driver: Inst().N, Nodes: node.GetWorkerNodes()},
+			Cleanup:    generator.Delete,
+		}
+		var regressions []backupbench.Regression
+		for i := range dataSets {
+			dataSet := dataSets[i]
+			Step(fmt.Sprintf("Benchmarking data set %s", dataSet.Name), func() {
+				namespaces, err := generator.Generate(&dataSet)
+				generated = append(generated, namespaces...)
+				log.FailOnError(err, "Generating data set %s", dataSet.Name)
+				ctx, err := backup.GetAdminCtxFromSecret()
+				log.FailOnError(err, "Fetching px-central-admin ctx")
+				result, err := runner.Run(ctx, &dataSet, namespaces)
+				log.FailOnError(err, "Benchmarking data set %s", dataSet.Name)
+				summary := backupbench.Summarize(result)
+				log.InfoD("Data set %s: backup %.1fs at %.1f MiB/s, restore %.1fs at %.1f MiB/s with px-backup %s", dataSet.Name,
+					summary.Backup.Seconds, summary.Backup.Throughput, summary.Restore.Seconds, summary.Restore.Throughput, summary.Version)
+				regressions = append(regressions, baseline.Compare(summary)...)
+				baseline.Update(summary)
+			})
+		}
+
+		if getEnv(benchmarkUpdateBaselineEnv, "false") == "true" {
+			Step(fmt.Sprintf("Saving baseline %s", baselinePath), func() {
+				err := baseline.Save(baselinePath)
+				dash.VerifyFatal(err, nil, fmt.Sprintf("Saving baseline %s", baselinePath))
+			})
+			return
+		}
+		Step("Comparing results against baseline", func() {
+			var err error
+			if len(regressions) > 0 {
+				err = &backupbench.ErrRegression{Regressions: regressions}
+			}
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Comparing results against baseline %s", baselinePath))
+		})
+	})
+	JustAfterEach(func() {
+		defer EndPxBackupTorpedoTest(nil)
+		if baselinePath == "" {
+			return
+		}
+		log.InfoD("Deleting the namespaces of the data sets")
+		err := generator.Delete(generated)
+		dash.VerifySafely(err, nil, "Deleting the namespaces of the data sets")
+		ctx, err := backup.GetAdminCtxFromSecret()
+		log.FailOnError(err, "Fetching px-central-admin ctx")
+		CleanupCloudSettingsAndClusters(backupLocationMap, credName, cloudCredUID, ctx)
+	})
+})
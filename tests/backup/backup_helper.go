@@ -16,11 +16,17 @@ import (
 	"github.com/portworx/sched-ops/k8s/apps"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	api "github.com/portworx/px-backup-api/pkg/apis/v1"
 	"github.com/portworx/sched-ops/k8s/core"
 	"github.com/portworx/sched-ops/task"
 	"github.com/portworx/torpedo/drivers/backup"
+	"github.com/portworx/torpedo/drivers/objectstore"
+	"github.com/portworx/torpedo/pkg/backuplocation"
+	"github.com/portworx/torpedo/pkg/backuprbac"
+	"github.com/portworx/torpedo/pkg/backupretention"
+	"github.com/portworx/torpedo/pkg/integrity"
 	"github.com/portworx/torpedo/pkg/log"
 	. "github.com/portworx/torpedo/tests"
 )
@@ -45,9 +51,11 @@ const (
 	globalAWSBucketPrefix                     = "global-aws"
 	globalAzureBucketPrefix                   = "global-azure"
 	globalGCPBucketPrefix                     = "global-gcp"
+	globalS3BucketPrefix                      = "global-s3"
 	globalAWSLockedBucketPrefix               = "global-aws-locked"
 	globalAzureLockedBucketPrefix             = "global-azure-locked"
 	globalGCPLockedBucketPrefix               = "global-gcp-locked"
+	globalS3LockedBucketPrefix                = "global-s3-locked"
 	userName                                  = "testuser"
 	firstName                                 = "firstName"
 	lastName                                  = "lastName"
@@ -56,6 +64,8 @@ const (
 	backupDeleteRetryTime                     = 30 * time.Second
 	mongodbPodStatusTimeout                   = 20 * time.Minute
 	mongodbPodStatusRetryTime                 = 30 * time.Second
+	quiesceCheckInterval                      = 1 * time.Minute
+	retentionGrace                            = 5 * time.Minute
 )
 
 var (
@@ -65,9 +75,11 @@ var (
 	globalAWSBucketName         string
 	globalAzureBucketName       string
 	globalGCPBucketName         string
+	globalS3BucketName          string
 	globalAWSLockedBucketName   string
 	globalAzureLockedBucketName string
 	globalGCPLockedBucketName   string
+	globalS3LockedBucketName    string
 	cloudProviders              = []string{"aws"}
 	commonPassword              string
 )
@@ -112,6 +124,19 @@ func getProviders() []string {
 	return cloudProviders
 }
 
+// getProvidersWith returns the providers from getProviders whose backup locations have the needed
+// capabilities, such as object lock for tests of locked buckets
+func getProvidersWith(need backuplocation.Capabilities) []string {
+	matrix, err := backuplocation.Matrix(getProviders(), need)
+	log.FailOnError(err, "Fetching backup location providers with capabilities %s", need)
+	providers := make([]string, 0, len(matrix))
+	for _, p := range matrix {
+		providers = append(providers, p.Name())
+	}
+	log.Infof("Backup location providers with capabilities %s: %v", need, providers)
+	return providers
+}
+
 // getPXNamespace fetches px namespace from env else sends backup kube-system
 func getPXNamespace() string {
 	namespace := os.Getenv("PX_NAMESPACE")
@@ -810,6 +835,66 @@ func getSizeOfMountPoint(podName string, namespace string, kubeConfigFile string
 	return number, nil
 }
 
+// CaptureAppData captures the digests of the data of the apps of the namespaces, given the app key
+// by namespace, to be compared with the data of the restored apps by ValidateRestoredAppData.
+// Apps with a database hook are captured by their tables and the others by the checksums of the
+// files of their volumes. Each app is captured twice, quiesceCheckInterval apart, and an app whose
+// data changed in between is not quiesced and fails the capture.
+func CaptureAppData(appKeys map[string]string) (map[string]*integrity.Snapshot, error) {
+	snapshots := make(map[string]*integrity.Snapshot)
+	for namespace, appKey := range appKeys {
+		first, err := captureAppData(namespace, appKey)
+		if err != nil {
+			return nil, err
+		}
+		time.Sleep(quiesceCheckInterval)
+		snapshot, err := captureAppData(namespace, appKey)
+		if err != nil {
+			return nil, err
+		}
+		if err = integrity.Verify(first, snapshot); err != nil {
+			return nil, fmt.Errorf("app %s in namespace %s is not quiesced: %v", appKey, namespace, err)
+		}
+		snapshots[namespace] = snapshot
+	}
+	return snapshots, nil
+}
+
+// ValidateRestoredAppData validates that the data of the apps restored with the namespace mapping
+// matches the data captured before backup. Restored apps are given time to start and recover.
+// Namespaces which are not restored are skipped.
+func ValidateRestoredAppData(snapshots map[string]*integrity.Snapshot, namespaceMapping map[string]string) error {
+	for namespace, restoredNamespace := range namespaceMapping {
+		before, ok := snapshots[namespace]
+		if !ok {
+			continue
+		}
+		t := func() (interface{}, bool, error) {
+			restored, err := captureAppData(restoredNamespace, before.AppKey)
+			if err != nil {
+				return nil, true, err
+			}
+			if err = integrity.Verify(before, restored); err != nil {
+				return nil, true, err
+			}
+			return nil, false, nil
+		}
+		if _, err := task.DoRetryWithTimeout(t, appReadinessTimeout, 30*time.Second); err != nil {
+			return err
+		}
+		log.InfoD("Data of app %s restored to namespace %s matches its backup", before.AppKey, restoredNamespace)
+	}
+	return nil
+}
+
+func captureAppData(namespace string, appKey string) (*integrity.Snapshot, error) {
+	pods, err := core.Instance().GetPods(namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	return integrity.Capture(core.Instance().RunCommandInPod, namespace, appKey, pods.Items)
+}
+
 func kubectlExec(arguments []string) (string, error) {
 	if len(arguments) == 0 {
 		return "", fmt.Errorf("no arguments supplied for kubectl command")
@@ -881,50 +966,18 @@ func CleanupCloudSettingsAndClusters(backupLocationMap map[string]string, credNa
 	Inst().Dash.VerifySafely(err, nil, fmt.Sprintf("Deleting cluster %s", destinationClusterName))
 }
 
-// AddRoleAndAccessToUsers assigns role and access level to the users
+// AddRoleAndAccessToUsers assigns role and access level to the users, cycling through the subjects of the default rbac matrix
 // AddRoleAndAccessToUsers return map whose key is userRoleAccess and value is backup for each user
 func AddRoleAndAccessToUsers(users []string, backupNames []string) (map[userRoleAccess]string, error) {
-	var access BackupAccess
-	var role backup.PxBackupRole
 	roleAccessUserBackupContext := make(map[userRoleAccess]string)
 	ctx, err := backup.GetAdminCtxFromSecret()
 	if err != nil {
 		return nil, err
 	}
+	subjects := backuprbac.DefaultMatrix().Subjects()
 	for i := 0; i < len(users); i++ {
-		userIndex := i % 9
-		switch userIndex {
-		case 0:
-			access = ViewOnlyAccess
-			role = backup.ApplicationOwner
-		case 1:
-			access = RestoreAccess
-			role = backup.ApplicationOwner
-		case 2:
-			access = FullAccess
-			role = backup.ApplicationOwner
-		case 3:
-			access = ViewOnlyAccess
-			role = backup.ApplicationUser
-		case 4:
-			access = RestoreAccess
-			role = backup.ApplicationUser
-		case 5:
-			access = FullAccess
-			role = backup.ApplicationUser
-		case 6:
-			access = ViewOnlyAccess
-			role = backup.InfrastructureOwner
-		case 7:
-			access = RestoreAccess
-			role = backup.InfrastructureOwner
-		case 8:
-			access = FullAccess
-			role = backup.InfrastructureOwner
-		default:
-			access = ViewOnlyAccess
-			role = backup.ApplicationOwner
-		}
+		role := subjects[i%len(subjects)].Role
+		access := BackupAccess(subjects[i%len(subjects)].Access)
 		ctxNonAdmin, err := backup.GetNonAdminCtx(users[i], commonPassword)
 		if err != nil {
 			return nil, err
@@ -1526,3 +1579,51 @@ func DeleteBackupAndWait(backupName string, ctx context.Context) error {
 	_, err := task.DoRetryWithTimeout(backupDeletionSuccessCheck, backupDeleteTimeout, backupDeleteRetryTime)
 	return err
 }
+
+// NewRetentionValidator returns a validator of the retention of the backups of a schedule with the
+// policy, against the model of the policy. The objects of the backups are validated too for
+// providers of S3 buckets.
+func NewRetentionValidator(scheduleName string, policy *api.SchedulePolicyInfo, provider string, bucketName string,
+	lockPeriod time.Duration) (*backupretention.Validator, error) {
+	model, err := backupretention.NewModel(policy, lockPeriod, retentionGrace)
+	if err != nil {
+		return nil, err
+	}
+	validator := &backupretention.Validator{
+		Driver:   Inst().Backup,
+		Model:    model,
+		OrgID:    orgID,
+		Schedule: scheduleName,
+	}
+	p, err := backuplocation.Get(provider)
+	if err != nil {
+		return nil, err
+	}
+	s3Provider, ok := p.(backuplocation.S3Provider)
+	if !ok {
+		log.Infof("Objects of backups of schedule %s are not validated for provider %s", scheduleName, provider)
+		return validator, nil
+	}
+	store, err := s3Provider.S3Store()
+	if err != nil {
+		return nil, err
+	}
+	validator.Objects, err = objectstore.Get()
+	if err != nil {
+		return nil, err
+	}
+	validator.Location = &storkapi.BackupLocation{
+		Location: storkapi.BackupLocationItem{
+			Type: storkapi.BackupLocationS3,
+			Path: bucketName,
+			S3Config: &storkapi.S3Config{
+				Endpoint:        store.Endpoint,
+				AccessKeyID:     store.AccessKeyID,
+				SecretAccessKey: store.SecretAccessKey,
+				Region:          store.Region,
+				DisableSSL:      store.DisableSSL,
+			},
+		},
+	}
+	return validator, nil
+}
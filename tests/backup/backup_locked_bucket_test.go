@@ -10,6 +10,8 @@ import (
 	"github.com/portworx/torpedo/drivers/backup/portworx"
 	"github.com/portworx/torpedo/drivers/scheduler"
 	"github.com/portworx/torpedo/drivers/volume"
+	"github.com/portworx/torpedo/pkg/backuplocation"
+	"github.com/portworx/torpedo/pkg/backupretention"
 	"github.com/portworx/torpedo/pkg/log"
 	. "github.com/portworx/torpedo/tests"
 	"strings"
@@ -63,7 +65,7 @@ var _ = Describe("{BackupAlternatingBetweenLockedAndUnlockedBuckets}", func() {
 		}
 	})
 	It("Backup alternating between locked and unlocked buckets", func() {
-		providers := getProviders()
+		providers := getProvidersWith(backuplocation.Capabilities{ObjectLock: true})
 		Step("Validate applications", func() {
 			ValidateApplications(contexts)
 		})
@@ -103,7 +105,7 @@ var _ = Describe("{BackupAlternatingBetweenLockedAndUnlockedBuckets}", func() {
 					CredName := fmt.Sprintf("%s-%s-%v", "cred", provider, time.Now().Unix())
 					bucketName := fmt.Sprintf("%s-%s-%s-locked", provider, getGlobalLockedBucketName(provider), strings.ToLower(mode))
 					backupLocation = fmt.Sprintf("%s-%s-%s-lock", provider, getGlobalLockedBucketName(provider), strings.ToLower(mode))
-					err := CreateS3Bucket(bucketName, true, 3, mode)
+					err := CreateS3BucketOfProvider(provider, bucketName, true, 3, mode)
 					log.FailOnError(err, "Unable to create locked s3 bucket %s", bucketName)
 					BackupLocationUID = uuid.New()
 					BackupLocationMap[BackupLocationUID] = backupLocation
@@ -253,7 +255,7 @@ var _ = Describe("{LockedBucketResizeOnRestoredVolume}", func() {
 		}
 	})
 	It("Resize after the volume is restored from a backup", func() {
-		providers := getProviders()
+		providers := getProvidersWith(backuplocation.Capabilities{ObjectLock: true})
 		Step("Validate applications", func() {
 			ValidateApplications(contexts)
 		})
@@ -292,7 +294,7 @@ var _ = Describe("{LockedBucketResizeOnRestoredVolume}", func() {
 				for _, mode := range modes {
 					bucketName := fmt.Sprintf("%s-%s-%s", provider, getGlobalLockedBucketName(provider), strings.ToLower(mode))
 					backupLocation = fmt.Sprintf("%s-%s-%s-lock", provider, getGlobalLockedBucketName(provider), strings.ToLower(mode))
-					err := CreateS3Bucket(bucketName, true, 3, mode)
+					err := CreateS3BucketOfProvider(provider, bucketName, true, 3, mode)
 					log.FailOnError(err, "Unable to create locked s3 bucket %s", bucketName)
 					BackupLocationUID = uuid.New()
 					BackupLocationMap[BackupLocationUID] = backupLocation
@@ -406,3 +408,129 @@ var _ = Describe("{LockedBucketResizeOnRestoredVolume}", func() {
 		CleanupCloudSettingsAndClusters(BackupLocationMap, credName, CloudCredUID, ctx)
 	})
 })
+
+// LockedBucketScheduleBackupRetention validates the retention of the backups of an interval schedule
+// for object lock to a locked bucket against the model of its policy, in PX-Backup and in the bucket
+var _ = Describe("{LockedBucketScheduleBackupRetention}", func() {
+	var (
+		contexts           []*scheduler.Context
+		bkpNamespaces      []string
+		cloudCredName      string
+		cloudCredUID       string
+		backupLocationName string
+		backupLocationUID  string
+		backupLocationMap  map[string]string
+		bucketName         string
+		scheduleName       string
+		schPolicyName      string
+		schPolicyUid       string
+		locationProvider   string
+		validator          *backupretention.Validator
+		clusterStatus      api.ClusterInfo_StatusInfo_Status
+	)
+	const (
+		retain           = 3
+		intervalMinutes  = 15
+		incrementalCount = 2
+		lockDays         = 1
+		lockPeriod       = lockDays * 24 * time.Hour
+		// the schedule runs until the first two chains of backups are past the retain count
+		retentionDuration = (2*(incrementalCount+1) + retain) * intervalMinutes * time.Minute
+		observeInterval   = time.Minute
+	)
+	JustBeforeEach(func() {
+		StartTorpedoTest("LockedBucketScheduleBackupRetention", "Validate retention of the backups of a schedule to a locked bucket", nil, 0)
+		timeStamp := time.Now().Unix()
+		scheduleName = fmt.Sprintf("%s-locked-retention-%v", BackupNamePrefix, timeStamp)
+		schPolicyName = fmt.Sprintf("periodic-locked-retention-%v", timeStamp)
+		bkpNamespaces = make([]string, 0)
+		backupLocationMap = make(map[string]string)
+		log.InfoD("Deploying applications")
+		contexts = make([]*scheduler.Context, 0)
+		for i := 0; i < Inst().GlobalScaleFactor; i++ {
+			taskName := fmt.Sprintf("%s-%d", taskNamePrefix, i)
+			appContexts := ScheduleApplications(taskName)
+			contexts = append(contexts, appContexts...)
+			for _, ctx := range appContexts {
+				ctx.ReadinessTimeout = appReadinessTimeout
+				bkpNamespaces = append(bkpNamespaces, GetAppNamespace(ctx, taskName))
+			}
+		}
+	})
+	It("Validates retention of the backups of a schedule to a locked bucket", func() {
+		policy := Inst().Backup.CreateIntervalSchedulePolicy(retain, intervalMinutes, incrementalCount)
+		policy.ForObjectLock = true
+		policy.AutoDelete = true
+		Step("Validating deployed applications", func() {
+			log.InfoD("Validating deployed applications")
+			ValidateApplications(contexts)
+		})
+		Step("Creating locked bucket, backup location and cloud setting", func() {
+			log.InfoD("Creating locked bucket, backup location and cloud setting")
+			for _, provider := range getProvidersWith(backuplocation.Capabilities{ObjectLock: true}) {
+				cloudCredName = fmt.Sprintf("%s-%s-%v", "cred", provider, time.Now().Unix())
+				bucketName = fmt.Sprintf("%s-%s-retention-%v", provider, getGlobalLockedBucketName(provider), time.Now().Unix())
+				backupLocationName = fmt.Sprintf("%s-lock", bucketName)
+				cloudCredUID = uuid.New()
+				backupLocationUID = uuid.New()
+				backupLocationMap[backupLocationUID] = backupLocationName
+				locationProvider = provider
+				err := CreateS3BucketOfProvider(provider, bucketName, true, lockDays, "GOVERNANCE")
+				log.FailOnError(err, "Creating locked bucket %s", bucketName)
+				CreateCloudCredential(provider, cloudCredName, cloudCredUID, orgID)
+				err = CreateBackupLocation(provider, backupLocationName, backupLocationUID, cloudCredName, cloudCredUID, bucketName, orgID, "")
+				dash.VerifyFatal(err, nil, fmt.Sprintf("Creating backup location %s", backupLocationName))
+			}
+		})
+		Step("Registering cluster for backup", func() {
+			log.InfoD("Registering cluster for backup")
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = CreateSourceAndDestClusters(orgID, "", "", ctx)
+			dash.VerifyFatal(err, nil, "Creating source and destination cluster")
+			clusterStatus, _ = Inst().Backup.RegisterBackupCluster(orgID, SourceClusterName, "")
+			dash.VerifyFatal(clusterStatus, api.ClusterInfo_StatusInfo_Online, fmt.Sprintf("Verifying backup cluster %s", SourceClusterName))
+		})
+		Step("Creating schedule policy for object lock", func() {
+			log.InfoD("Creating schedule policy %s", schPolicyName)
+			err := Inst().Backup.BackupSchedulePolicy(schPolicyName, uuid.New(), orgID, policy)
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating schedule policy %s", schPolicyName))
+		})
+		Step("Creating schedule backups", func() {
+			log.InfoD("Creating schedule backups %s", scheduleName)
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			schPolicyUid, err = Inst().Backup.GetSchedulePolicyUid(orgID, ctx, schPolicyName)
+			log.FailOnError(err, "Fetching uid of schedule policy %s", schPolicyName)
+			_, err = CreateScheduleBackupWithoutCheck(scheduleName, SourceClusterName, backupLocationName, backupLocationUID, bkpNamespaces,
+				make(map[string]string), orgID, "", "", "", "", schPolicyName, schPolicyUid, ctx)
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating schedule backup %s", scheduleName))
+			validator, err = NewRetentionValidator(scheduleName, policy, locationProvider, bucketName, lockPeriod)
+			log.FailOnError(err, "Creating retention validator of schedule %s", scheduleName)
+		})
+		Step("Validating retention of schedule backups", func() {
+			log.InfoD("Validating retention of schedule %s for %v", scheduleName, retentionDuration)
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = validator.Run(ctx, retentionDuration, observeInterval)
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Validating retention of schedule %s", scheduleName))
+		})
+	})
+	JustAfterEach(func() {
+		defer EndPxBackupTorpedoTest(contexts)
+		ctx, err := backup.GetAdminCtxFromSecret()
+		log.FailOnError(err, "Fetching px-central-admin ctx")
+		opts := make(map[string]bool)
+		opts[SkipClusterScopedObjects] = true
+		log.InfoD("Deleting deployed applications")
+		ValidateAndDestroy(contexts, opts)
+
+		log.InfoD("Deleting schedule %s", scheduleName)
+		scheduleUid, err := GetScheduleUID(scheduleName, orgID, ctx)
+		log.FailOnError(err, "Fetching uid of schedule %s", scheduleName)
+		err = DeleteSchedule(scheduleName, scheduleUid, schPolicyName, schPolicyUid, orgID)
+		dash.VerifySafely(err, nil, fmt.Sprintf("Deleting schedule %s", scheduleName))
+
+		CleanupCloudSettingsAndClusters(backupLocationMap, cloudCredName, cloudCredUID, ctx)
+	})
+})
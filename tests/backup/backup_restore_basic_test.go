@@ -16,6 +16,8 @@ import (
 	"github.com/portworx/torpedo/drivers/backup"
 	"github.com/portworx/torpedo/drivers/backup/portworx"
 	"github.com/portworx/torpedo/drivers/scheduler"
+	"github.com/portworx/torpedo/pkg/backupretention"
+	"github.com/portworx/torpedo/pkg/integrity"
 	"github.com/portworx/torpedo/pkg/log"
 	. "github.com/portworx/torpedo/tests"
 	v1 "k8s.io/api/core/v1"
@@ -104,7 +106,7 @@ var _ = Describe("{BasicSelectiveRestore}", func() {
 		})
 		Step("Selecting random backed-up apps and restoring them", func() {
 			log.InfoD("Selecting random backed-up apps and restoring them")
-			selectedBkpNamespaces, err := GetSubsetOfSlice(bkpNamespaces, len(bkpNamespaces)/2)
+			selectedBkpNamespaces, err := GetSubsetOfSlice("BasicSelectiveRestore", bkpNamespaces, len(bkpNamespaces)/2)
 			log.FailOnError(err, "Getting a subset of backed-up namespaces")
 			selectedBkpNamespaceMapping := make(map[string]string)
 			for _, namespace := range selectedBkpNamespaces {
@@ -1272,3 +1274,249 @@ var _ = Describe("{BackupSyncBasicTest}", func() {
 		CleanupCloudSettingsAndClusters(backupLocationMap, credName, cloudCredUID, ctx)
 	})
 })
+
+// BackupRestoreDataIntegrity validates that the data of quiesced database apps restored from a backup
+// matches the data of the apps before backup
+var _ = Describe("{BackupRestoreDataIntegrity}", func() {
+	var (
+		backupName        string
+		contexts          []*scheduler.Context
+		bkpNamespaces     []string
+		clusterUid        string
+		clusterStatus     api.ClusterInfo_StatusInfo_Status
+		restoreName       string
+		cloudCredName     string
+		cloudCredUID      string
+		backupLocationUID string
+		bkpLocationName   string
+		backupLocationMap map[string]string
+		appKeys           map[string]string
+		appData           map[string]*integrity.Snapshot
+		namespaceMapping  map[string]string
+	)
+	JustBeforeEach(func() {
+		StartTorpedoTest("BackupRestoreDataIntegrity", "Validate data of database apps after backup and restore", nil, 0)
+		backupName = fmt.Sprintf("%s-%v", BackupNamePrefix, time.Now().Unix())
+		restoreName = fmt.Sprintf("%s-%v", RestoreNamePrefix, time.Now().Unix())
+		bkpNamespaces = make([]string, 0)
+		backupLocationMap = make(map[string]string)
+		appKeys = make(map[string]string)
+		namespaceMapping = make(map[string]string)
+		log.InfoD("Deploying applications")
+		contexts = make([]*scheduler.Context, 0)
+		for i := 0; i < Inst().GlobalScaleFactor; i++ {
+			taskName := fmt.Sprintf("%s-%d", taskNamePrefix, i)
+			appContexts := ScheduleApplications(taskName)
+			contexts = append(contexts, appContexts...)
+			for _, ctx := range appContexts {
+				ctx.ReadinessTimeout = appReadinessTimeout
+				namespace := GetAppNamespace(ctx, taskName)
+				bkpNamespaces = append(bkpNamespaces, namespace)
+				appKeys[namespace] = ctx.App.Key
+			}
+		}
+	})
+	It("Validates data of database apps after backup and restore", func() {
+		Step("Validating deployed applications", func() {
+			log.InfoD("Validating deployed applications")
+			ValidateApplications(contexts)
+		})
+		Step("Capturing data of quiesced database applications", func() {
+			log.InfoD("Capturing data of quiesced database applications")
+			var err error
+			appData, err = CaptureAppData(appKeys)
+			log.FailOnError(err, "Capturing data of applications")
+			dash.VerifyFatal(len(appData) > 0, true, "Verifying that data of at least one database application is captured")
+			for namespace := range appData {
+				namespaceMapping[namespace] = namespace
+			}
+		})
+		Step("Creating backup location and cloud setting", func() {
+			log.InfoD("Creating backup location and cloud setting")
+			for _, provider := range getProviders() {
+				cloudCredName = fmt.Sprintf("%s-%s-%v", "cred", provider, time.Now().Unix())
+				bkpLocationName = fmt.Sprintf("%s-%s-bl", provider, getGlobalBucketName(provider))
+				cloudCredUID = uuid.New()
+				backupLocationUID = uuid.New()
+				backupLocationMap[backupLocationUID] = bkpLocationName
+				CreateCloudCredential(provider, cloudCredName, cloudCredUID, orgID)
+				err := CreateBackupLocation(provider, bkpLocationName, backupLocationUID, cloudCredName, cloudCredUID, getGlobalBucketName(provider), orgID, "")
+				dash.VerifyFatal(err, nil, fmt.Sprintf("Creating backup location %s", bkpLocationName))
+			}
+		})
+		Step("Registering cluster for backup", func() {
+			log.InfoD("Registering cluster for backup")
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = CreateSourceAndDestClusters(orgID, "", "", ctx)
+			dash.VerifyFatal(err, nil, "Creating source and destination cluster")
+			clusterStatus, clusterUid = Inst().Backup.RegisterBackupCluster(orgID, SourceClusterName, "")
+			dash.VerifyFatal(clusterStatus, api.ClusterInfo_StatusInfo_Online, fmt.Sprintf("Verifying backup cluster with uid: [%s]", clusterUid))
+		})
+		Step("Taking backup of application namespaces", func() {
+			log.InfoD(fmt.Sprintf("Taking backup of namespaces [%v]", bkpNamespaces))
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = CreateBackup(backupName, SourceClusterName, bkpLocationName, backupLocationUID, bkpNamespaces,
+				make(map[string]string), orgID, clusterUid, "", "", "", "", ctx)
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Verifying [%s] backup creation", backupName))
+		})
+		Step("Restoring database applications", func() {
+			log.InfoD("Restoring namespaces [%v]", namespaceMapping)
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = CreateRestore(restoreName, backupName, namespaceMapping, destinationClusterName, orgID, ctx, make(map[string]string))
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating restore [%s]", restoreName))
+		})
+		Step("Validating data of restored applications", func() {
+			log.InfoD("Validating data of restored applications")
+			err := SetDestinationKubeConfig()
+			log.FailOnError(err, "Switching context to destination cluster")
+			defer func() {
+				err := SetSourceKubeConfig()
+				log.FailOnError(err, "Switching context to source cluster")
+			}()
+			err = ValidateRestoredAppData(appData, namespaceMapping)
+			dash.VerifyFatal(err, nil, "Validating data of restored applications")
+		})
+	})
+	JustAfterEach(func() {
+		defer EndPxBackupTorpedoTest(contexts)
+		ctx, err := backup.GetAdminCtxFromSecret()
+		log.FailOnError(err, "Fetching px-central-admin ctx")
+		opts := make(map[string]bool)
+		opts[SkipClusterScopedObjects] = true
+		log.InfoD("Deleting deployed applications")
+		ValidateAndDestroy(contexts, opts)
+
+		backupUID, err := Inst().Backup.GetBackupUID(ctx, backupName, orgID)
+		log.FailOnError(err, "Failed while trying to get backup UID for - [%s]", backupName)
+		log.InfoD("Deleting backup")
+		_, err = DeleteBackup(backupName, backupUID, orgID, ctx)
+		dash.VerifyFatal(err, nil, fmt.Sprintf("Deleting backup [%s]", backupName))
+
+		log.InfoD("Deleting restore")
+		err = DeleteRestore(restoreName, orgID, ctx)
+		dash.VerifyFatal(err, nil, fmt.Sprintf("Deleting restore [%s]", restoreName))
+
+		CleanupCloudSettingsAndClusters(backupLocationMap, cloudCredName, cloudCredUID, ctx)
+	})
+})
+
+// ScheduleBackupRetention validates the retention of the backups of an interval schedule with
+// incremental backups against the model of its policy, in PX-Backup and in the bucket
+var _ = Describe("{ScheduleBackupRetention}", func() {
+	var (
+		contexts           []*scheduler.Context
+		bkpNamespaces      []string
+		cloudCredName      string
+		cloudCredUID       string
+		backupLocationName string
+		backupLocationUID  string
+		backupLocationMap  map[string]string
+		scheduleName       string
+		schPolicyName      string
+		schPolicyUid       string
+		locationProvider   string
+		validator          *backupretention.Validator
+		clusterStatus      api.ClusterInfo_StatusInfo_Status
+	)
+	const (
+		retain           = 3
+		intervalMinutes  = 15
+		incrementalCount = 2
+		// the schedule runs until the first two chains of backups are past the retain count
+		retentionDuration = (2*(incrementalCount+1) + retain) * intervalMinutes * time.Minute
+		observeInterval   = time.Minute
+	)
+	JustBeforeEach(func() {
+		StartTorpedoTest("ScheduleBackupRetention", "Validate retention of the backups of a schedule", nil, 0)
+		timeStamp := time.Now().Unix()
+		scheduleName = fmt.Sprintf("%s-retention-%v", BackupNamePrefix, timeStamp)
+		schPolicyName = fmt.Sprintf("periodic-retention-%v", timeStamp)
+		bkpNamespaces = make([]string, 0)
+		backupLocationMap = make(map[string]string)
+		log.InfoD("Deploying applications")
+		contexts = make([]*scheduler.Context, 0)
+		for i := 0; i < Inst().GlobalScaleFactor; i++ {
+			taskName := fmt.Sprintf("%s-%d", taskNamePrefix, i)
+			appContexts := ScheduleApplications(taskName)
+			contexts = append(contexts, appContexts...)
+			for _, ctx := range appContexts {
+				ctx.ReadinessTimeout = appReadinessTimeout
+				bkpNamespaces = append(bkpNamespaces, GetAppNamespace(ctx, taskName))
+			}
+		}
+	})
+	It("Validates retention of the backups of a schedule", func() {
+		policy := Inst().Backup.CreateIntervalSchedulePolicy(retain, intervalMinutes, incrementalCount)
+		Step("Validating deployed applications", func() {
+			log.InfoD("Validating deployed applications")
+			ValidateApplications(contexts)
+		})
+		Step("Creating backup location and cloud setting", func() {
+			log.InfoD("Creating backup location and cloud setting")
+			for _, provider := range getProviders() {
+				cloudCredName = fmt.Sprintf("%s-%s-%v", "cred", provider, time.Now().Unix())
+				backupLocationName = fmt.Sprintf("%s-%s-bl", provider, getGlobalBucketName(provider))
+				cloudCredUID = uuid.New()
+				backupLocationUID = uuid.New()
+				backupLocationMap[backupLocationUID] = backupLocationName
+				locationProvider = provider
+				CreateCloudCredential(provider, cloudCredName, cloudCredUID, orgID)
+				err := CreateBackupLocation(provider, backupLocationName, backupLocationUID, cloudCredName, cloudCredUID, getGlobalBucketName(provider), orgID, "")
+				dash.VerifyFatal(err, nil, fmt.Sprintf("Creating backup location %s", backupLocationName))
+			}
+		})
+		Step("Registering cluster for backup", func() {
+			log.InfoD("Registering cluster for backup")
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = CreateSourceAndDestClusters(orgID, "", "", ctx)
+			dash.VerifyFatal(err, nil, "Creating source and destination cluster")
+			clusterStatus, _ = Inst().Backup.RegisterBackupCluster(orgID, SourceClusterName, "")
+			dash.VerifyFatal(clusterStatus, api.ClusterInfo_StatusInfo_Online, fmt.Sprintf("Verifying backup cluster %s", SourceClusterName))
+		})
+		Step("Creating schedule policy", func() {
+			log.InfoD("Creating schedule policy %s", schPolicyName)
+			err := Inst().Backup.BackupSchedulePolicy(schPolicyName, uuid.New(), orgID, policy)
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating schedule policy %s", schPolicyName))
+		})
+		Step("Creating schedule backups", func() {
+			log.InfoD("Creating schedule backups %s", scheduleName)
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			schPolicyUid, err = Inst().Backup.GetSchedulePolicyUid(orgID, ctx, schPolicyName)
+			log.FailOnError(err, "Fetching uid of schedule policy %s", schPolicyName)
+			_, err = CreateScheduleBackupWithoutCheck(scheduleName, SourceClusterName, backupLocationName, backupLocationUID, bkpNamespaces,
+				make(map[string]string), orgID, "", "", "", "", schPolicyName, schPolicyUid, ctx)
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating schedule backup %s", scheduleName))
+			validator, err = NewRetentionValidator(scheduleName, policy, locationProvider, getGlobalBucketName(locationProvider), 0)
+			log.FailOnError(err, "Creating retention validator of schedule %s", scheduleName)
+		})
+		Step("Validating retention of schedule backups", func() {
+			log.InfoD("Validating retention of schedule %s for %v", scheduleName, retentionDuration)
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = validator.Run(ctx, retentionDuration, observeInterval)
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Validating retention of schedule %s", scheduleName))
+		})
+	})
+	JustAfterEach(func() {
+		defer EndPxBackupTorpedoTest(contexts)
+		ctx, err := backup.GetAdminCtxFromSecret()
+		log.FailOnError(err, "Fetching px-central-admin ctx")
+		opts := make(map[string]bool)
+		opts[SkipClusterScopedObjects] = true
+		log.InfoD("Deleting deployed applications")
+		ValidateAndDestroy(contexts, opts)
+
+		log.InfoD("Deleting schedule %s", scheduleName)
+		scheduleUid, err := GetScheduleUID(scheduleName, orgID, ctx)
+		log.FailOnError(err, "Fetching uid of schedule %s", scheduleName)
+		err = DeleteSchedule(scheduleName, scheduleUid, schPolicyName, schPolicyUid, orgID)
+		dash.VerifySafely(err, nil, fmt.Sprintf("Deleting schedule %s", scheduleName))
+
+		CleanupCloudSettingsAndClusters(backupLocationMap, cloudCredName, cloudCredUID, ctx)
+	})
+})
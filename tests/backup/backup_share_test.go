@@ -18,6 +18,8 @@ import (
 	"github.com/portworx/torpedo/drivers/backup"
 	"github.com/portworx/torpedo/drivers/scheduler"
 	"github.com/portworx/torpedo/drivers/scheduler/k8s"
+	"github.com/portworx/torpedo/pkg/backuplocation"
+	"github.com/portworx/torpedo/pkg/backuprbac"
 	"github.com/portworx/torpedo/pkg/log"
 	. "github.com/portworx/torpedo/tests"
 	v1 "k8s.io/api/core/v1"
@@ -3358,7 +3360,8 @@ var _ = Describe("{IssueMultipleRestoresWithNamespaceAndStorageClassMapping}", f
 			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating new storage class %v on source cluster %s", scName, SourceClusterName))
 
 			log.InfoD("Switching cluster context to destination cluster")
-			SetDestinationKubeConfig()
+			err = SetDestinationKubeConfig()
+			log.FailOnError(err, "Failed to set destination kubeconfig")
 			log.InfoD("Create new storage class on destination cluster for storage class mapping for restore")
 			_, err = k8sStorage.CreateStorageClass(&scObj)
 			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating new storage class %v on destination cluster %s", scName, destinationClusterName))
@@ -3528,7 +3531,8 @@ var _ = Describe("{IssueMultipleRestoresWithNamespaceAndStorageClassMapping}", f
 		err = k8sStorage.DeleteStorageClass(scName)
 		dash.VerifyFatal(err, nil, fmt.Sprintf("Deleting storage class %s from source cluster", scName))
 		log.InfoD("Switching cluster context to destination cluster")
-		SetDestinationKubeConfig()
+		err = SetDestinationKubeConfig()
+		log.FailOnError(err, "Failed to set destination kubeconfig")
 		err = k8sStorage.DeleteStorageClass(scName)
 		dash.VerifySafely(err, nil, fmt.Sprintf("Deleting storage class %s from destination cluster", scName))
 		log.InfoD("Switching cluster context back to source cluster")
@@ -4075,3 +4079,116 @@ var _ = Describe("{SwapShareBackup}", func() {
 		wg.Wait()
 	})
 })
+
+// BackupRBACPermissionMatrix runs the default rbac matrix of PX-Backup: a user is created in Keycloak
+// for each role and access level, objects are shared with it and it runs every operation on them
+var _ = Describe("{BackupRBACPermissionMatrix}", func() {
+	var contexts []*scheduler.Context
+	var bkpNamespaces []string
+	var backupLocationName string
+	var backupLocationUID string
+	var cloudCredUID string
+	var credName string
+	var clusterUid string
+	var clusterStatus api.ClusterInfo_StatusInfo_Status
+	backupLocationMap := make(map[string]string)
+
+	JustBeforeEach(func() {
+		StartTorpedoTest("BackupRBACPermissionMatrix",
+			"Run the operations of the rbac matrix as users of each role and access level", nil, 0)
+		log.InfoD("Deploy applications")
+		contexts = make([]*scheduler.Context, 0)
+		bkpNamespaces = make([]string, 0)
+		for i := 0; i < Inst().GlobalScaleFactor; i++ {
+			taskName := fmt.Sprintf("%s-%d", taskNamePrefix, i)
+			appContexts := ScheduleApplications(taskName)
+			contexts = append(contexts, appContexts...)
+			for _, ctx := range appContexts {
+				ctx.ReadinessTimeout = appReadinessTimeout
+				namespace := GetAppNamespace(ctx, taskName)
+				bkpNamespaces = append(bkpNamespaces, namespace)
+			}
+		}
+	})
+	It("Run the rbac matrix and report it", func() {
+		provider := getProviders()[0]
+		Step("Validate applications", func() {
+			log.InfoD("Validate applications")
+			ValidateApplications(contexts)
+		})
+		Step("Adding Credentials and Registering Backup Location", func() {
+			log.InfoD("Creating cloud credentials and backup location")
+			cloudCredUID = uuid.New()
+			backupLocationUID = uuid.New()
+			credName = fmt.Sprintf("autogenerated-cred-%v", time.Now().Unix())
+			CreateCloudCredential(provider, credName, cloudCredUID, orgID)
+			backupLocationName = fmt.Sprintf("autogenerated-backup-location-%v", time.Now().Unix())
+			backupLocationMap[backupLocationUID] = backupLocationName
+			err := CreateBackupLocation(provider, backupLocationName, backupLocationUID, credName, cloudCredUID, getGlobalBucketName(provider), orgID, "")
+			dash.VerifyFatal(err, nil, fmt.Sprintf("Creating backup location %s", backupLocationName))
+		})
+		Step("Register source and destination cluster for backup", func() {
+			log.InfoD("Registering Source and Destination clusters and verifying the status")
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			err = CreateSourceAndDestClusters(orgID, "", "", ctx)
+			dash.VerifyFatal(err, nil, "Creating source and destination cluster")
+			clusterStatus, clusterUid = Inst().Backup.RegisterBackupCluster(orgID, SourceClusterName, "")
+			dash.VerifyFatal(clusterStatus, api.ClusterInfo_StatusInfo_Online, fmt.Sprintf("Verifying backup cluster %s status", SourceClusterName))
+		})
+		Step("Run the rbac matrix with Keycloak users", func() {
+			log.InfoD("Running the rbac matrix with Keycloak users")
+			ctx, err := backup.GetAdminCtxFromSecret()
+			log.FailOnError(err, "Fetching px-central-admin ctx")
+			p, err := backuplocation.Get(provider)
+			log.FailOnError(err, "Fetching backup location provider %s", provider)
+			credential, err := p.CloudCredential()
+			log.FailOnError(err, "Fetching cloud credential of provider %s", provider)
+			location, err := p.BackupLocation(getGlobalBucketName(provider))
+			log.FailOnError(err, "Fetching backup location of provider %s", provider)
+			engine := &backuprbac.Engine{
+				Driver: Inst().Backup,
+				Users:  &backuprbac.KeycloakUsers{Password: commonPassword},
+				Env: backuprbac.Env{
+					OrgID:             orgID,
+					Cluster:           SourceClusterName,
+					ClusterUID:        clusterUid,
+					BackupLocation:    backupLocationName,
+					BackupLocationUID: backupLocationUID,
+					Namespaces:        bkpNamespaces,
+					RestoreCluster:    destinationClusterName,
+					CloudCredential:   credential,
+					Location:          location,
+				},
+				// each user restores to its own registration of the destination cluster
+				Setup: func(ctx context.Context, user string) error {
+					return CreateSourceAndDestClusters(orgID, "", "", ctx)
+				},
+				Teardown: func(ctx context.Context, user string) error {
+					if err := DeleteCluster(destinationClusterName, orgID, ctx); err != nil {
+						return err
+					}
+					return DeleteCluster(SourceClusterName, orgID, ctx)
+				},
+			}
+			report, err := engine.Run(ctx, backuprbac.DefaultMatrix())
+			log.FailOnError(err, "Running the rbac matrix")
+			log.InfoD("RBAC matrix:\n%s", report.String())
+			dash.VerifyFatal(report.Err(), nil, "Verifying the outcomes of the rbac matrix")
+		})
+	})
+	JustAfterEach(func() {
+		defer EndPxBackupTorpedoTest(contexts)
+		log.InfoD("Deleting the deployed apps after the testcase")
+		for i := 0; i < len(contexts); i++ {
+			opts := make(map[string]bool)
+			opts[SkipClusterScopedObjects] = true
+			taskName := fmt.Sprintf("%s-%d", taskNamePrefix, i)
+			err := Inst().S.Destroy(contexts[i], opts)
+			dash.VerifySafely(err, nil, fmt.Sprintf("Verify destroying app %s, Err: %v", taskName, err))
+		}
+		ctx, err := backup.GetAdminCtxFromSecret()
+		log.FailOnError(err, "Fetching px-central-admin ctx")
+		CleanupCloudSettingsAndClusters(backupLocationMap, credName, cloudCredUID, ctx)
+	})
+})
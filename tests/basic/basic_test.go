@@ -56,11 +56,10 @@ var _ = BeforeSuite(func() {
 })
 
 var _ = AfterSuite(func() {
-	TestLogger = CreateLogger("SystemCheck.log")
+	StartTorpedoTest("SystemCheck", "validating system check and clean up", nil, 0)
 
 	defer dash.TestSetEnd()
-	defer CloseLogger(TestLogger)
-	defer dash.TestCaseEnd()
+	defer EndTorpedoTest()
 	// making sure validate clean up executed even if systemcheck failed
 	defer func() {
 		if wantAllAfterSuiteActions || wantAfterSuiteValidateCleanup {
@@ -68,8 +67,6 @@ var _ = AfterSuite(func() {
 		}
 	}()
 
-	log.SetTorpedoFileOutput(TestLogger)
-	dash.TestCaseBegin("System check", "validating system check and clean up", "", nil)
 	if wantAllAfterSuiteActions || wantAfterSuiteSystemCheck {
 		PerformSystemCheck()
 	}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +17,11 @@ import (
 	"github.com/portworx/torpedo/drivers/node"
 	"github.com/portworx/torpedo/drivers/scheduler"
 	k8s "github.com/portworx/torpedo/drivers/scheduler/k8s"
+	"github.com/portworx/torpedo/pkg/claims"
+	"github.com/portworx/torpedo/pkg/eventlog"
+	"github.com/portworx/torpedo/pkg/notification"
+	"github.com/portworx/torpedo/pkg/replay"
+	"github.com/portworx/torpedo/pkg/scenario"
 	. "github.com/portworx/torpedo/tests"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -31,9 +37,9 @@ const (
 var (
 	// Stores mapping between chaos level and its freq. Values are hardcoded
 	triggerInterval map[string]map[int]time.Duration
-	// Stores which are disruptive triggers. When disruptive triggers are happening in test,
-	// other triggers are allowed to happen only after existing triggers are complete.
-	disruptiveTriggers map[string]bool
+	// Stores the resources claimed by each trigger. Triggers run concurrently only when their
+	// claims do not conflict.
+	triggerClaims map[string][]claims.Claim
 
 	triggerFunctions     map[string]func(*[]*scheduler.Context, *chan *EventRecord)
 	emailTriggerFunction map[string]func()
@@ -50,7 +56,6 @@ var (
 
 var _ = Describe("{Longevity}", func() {
 	contexts := make([]*scheduler.Context, 0)
-	var triggerLock sync.Mutex
 	var emailTriggerLock sync.Mutex
 	var populateDone bool
 	triggerEventsChan := make(chan *EventRecord, 100)
@@ -65,42 +70,43 @@ var _ = Describe("{Longevity}", func() {
 		VolumeClone:      TriggerVolumeClone,
 		VolumeResize:     TriggerVolumeResize,
 		//EmailReporter:        TriggerEmailReporter,
-		AppTaskDown:            TriggerAppTaskDown,
-		AppTasksDown:           TriggerAppTasksDown,
-		AddDrive:               TriggerAddDrive,
-		CoreChecker:            TriggerCoreChecker,
-		CloudSnapShot:          TriggerCloudSnapShot,
-		LocalSnapShot:          TriggerLocalSnapShot,
-		DeleteLocalSnapShot:    TriggerDeleteLocalSnapShot,
-		PoolResizeDisk:         TriggerPoolResizeDisk,
-		PoolAddDisk:            TriggerPoolAddDisk,
-		UpgradeStork:           TriggerUpgradeStork,
-		VolumesDelete:          TriggerVolumeDelete,
-		UpgradeVolumeDriver:    TriggerUpgradeVolumeDriver,
-		AutoFsTrim:             TriggerAutoFsTrim,
-		UpdateVolume:           TriggerVolumeUpdate,
-		RestartManyVolDriver:   TriggerRestartManyVolDriver,
-		RebootManyNodes:        TriggerRebootManyNodes,
-		NodeDecommission:       TriggerNodeDecommission,
-		NodeRejoin:             TriggerNodeRejoin,
-		CsiSnapShot:            TriggerCsiSnapShot,
-		CsiSnapRestore:         TriggerCsiSnapRestore,
-		RelaxedReclaim:         TriggerRelaxedReclaim,
-		Trashcan:               TriggerTrashcan,
-		KVDBFailover:           TriggerKVDBFailover,
-		ValidateDeviceMapper:   TriggerValidateDeviceMapperCleanup,
-		AsyncDR:                TriggerAsyncDR,
-		AsyncDRVolumeOnly:      TriggerAsyncDRVolumeOnly,
-		StorkApplicationBackup: TriggerStorkApplicationBackup,
-		StorkAppBkpVolResize:   TriggerStorkAppBkpVolResize,
-		StorkAppBkpHaUpdate:    TriggerStorkAppBkpHaUpdate,
-		StorkAppBkpPxRestart:   TriggerStorkAppBkpPxRestart,
-		StorkAppBkpPoolResize:  TriggerStorkAppBkpPoolResize,
-		RestartKvdbVolDriver:   TriggerRestartKvdbVolDriver,
-		HAIncreaseAndReboot:    TriggerHAIncreaseAndReboot,
-		AddDiskAndReboot:       TriggerPoolAddDiskAndReboot,
-		ResizeDiskAndReboot:    TriggerPoolResizeDiskAndReboot,
-		AutopilotRebalance:     TriggerAutopilotPoolRebalance,
+		AppTaskDown:             TriggerAppTaskDown,
+		AppTasksDown:            TriggerAppTasksDown,
+		AddDrive:                TriggerAddDrive,
+		CoreChecker:             TriggerCoreChecker,
+		CloudSnapShot:           TriggerCloudSnapShot,
+		LocalSnapShot:           TriggerLocalSnapShot,
+		DeleteLocalSnapShot:     TriggerDeleteLocalSnapShot,
+		PoolResizeDisk:          TriggerPoolResizeDisk,
+		PoolAddDisk:             TriggerPoolAddDisk,
+		UpgradeStork:            TriggerUpgradeStork,
+		VolumesDelete:           TriggerVolumeDelete,
+		UpgradeVolumeDriver:     TriggerUpgradeVolumeDriver,
+		AutoFsTrim:              TriggerAutoFsTrim,
+		UpdateVolume:            TriggerVolumeUpdate,
+		RestartManyVolDriver:    TriggerRestartManyVolDriver,
+		RebootManyNodes:         TriggerRebootManyNodes,
+		NodeDecommission:        TriggerNodeDecommission,
+		NodeRejoin:              TriggerNodeRejoin,
+		CsiSnapShot:             TriggerCsiSnapShot,
+		CsiSnapRestore:          TriggerCsiSnapRestore,
+		RelaxedReclaim:          TriggerRelaxedReclaim,
+		Trashcan:                TriggerTrashcan,
+		KVDBFailover:            TriggerKVDBFailover,
+		ValidateDeviceMapper:    TriggerValidateDeviceMapperCleanup,
+		AsyncDR:                 TriggerAsyncDR,
+		AsyncDRVolumeOnly:       TriggerAsyncDRVolumeOnly,
+		AsyncDRFailoverFailback: TriggerAsyncDRFailoverFailback,
+		StorkApplicationBackup:  TriggerStorkApplicationBackup,
+		StorkAppBkpVolResize:    TriggerStorkAppBkpVolResize,
+		StorkAppBkpHaUpdate:     TriggerStorkAppBkpHaUpdate,
+		StorkAppBkpPxRestart:    TriggerStorkAppBkpPxRestart,
+		StorkAppBkpPoolResize:   TriggerStorkAppBkpPoolResize,
+		RestartKvdbVolDriver:    TriggerRestartKvdbVolDriver,
+		HAIncreaseAndReboot:     TriggerHAIncreaseAndReboot,
+		AddDiskAndReboot:        TriggerPoolAddDiskAndReboot,
+		ResizeDiskAndReboot:     TriggerPoolResizeDiskAndReboot,
+		AutopilotRebalance:      TriggerAutopilotPoolRebalance,
 	}
 	//Creating a distinct trigger to make sure email triggers at regular intervals
 	emailTriggerFunction = map[string]func(){
@@ -115,7 +121,7 @@ var _ = Describe("{Longevity}", func() {
 			StartTorpedoTest("PX-Longevity", "Validate PX longevity workflow", tags, 0)
 
 			populateIntervals()
-			populateDisruptiveTriggers()
+			populateTriggerClaims()
 			populateDone = true
 		}
 	})
@@ -125,13 +131,26 @@ var _ = Describe("{Longevity}", func() {
 		watchLog := fmt.Sprintf("Start watch on K8S configMap [%s/%s]",
 			configMapNS, testTriggersConfigMap)
 
-		Step(watchLog, func() {
-			log.InfoD(watchLog)
-			err := watchConfigMap()
-			if err != nil {
-				log.Fatalf(fmt.Sprintf("%v", err))
-			}
-		})
+		var longevityScenario *scenario.Scenario
+		if Inst().LongevityScenario != "" {
+			loadLog := fmt.Sprintf("Load longevity scenario [%s]", Inst().LongevityScenario)
+			Step(loadLog, func() {
+				log.InfoD(loadLog)
+				var err error
+				longevityScenario, err = loadScenario(Inst().LongevityScenario)
+				if err != nil {
+					log.Fatalf(fmt.Sprintf("%v", err))
+				}
+			})
+		} else {
+			Step(watchLog, func() {
+				log.InfoD(watchLog)
+				err := watchConfigMap()
+				if err != nil {
+					log.Fatalf(fmt.Sprintf("%v", err))
+				}
+			})
+		}
 
 		if pureTopologyEnabled {
 			var err error
@@ -144,33 +163,54 @@ var _ = Describe("{Longevity}", func() {
 
 		Inst().IsHyperConverged = hyperConvergedTypeEnabled
 
-		TriggerDeployNewApps(&contexts, &triggerEventsChan)
-
-		var wg sync.WaitGroup
-		Step("Register test triggers", func() {
-			for triggerType, triggerFunc := range triggerFunctions {
-				log.InfoD("Registering trigger: [%v]", triggerType)
-				go testTrigger(&wg, &contexts, triggerType, triggerFunc, &triggerLock, &triggerEventsChan)
-				wg.Add(1)
+		startEventLog()
+		stopNotifiers := make(chan struct{})
+		defer close(stopNotifiers)
+		go Notifiers.Run(time.Minute, stopNotifiers)
+		if Inst().ReplayJournal != "" {
+			replayLog := fmt.Sprintf("Replay events of journal [%s]", Inst().ReplayJournal)
+			Step(replayLog, func() {
+				log.InfoD(replayLog)
+				err := replayJournal(Inst().ReplayJournal, &contexts, &triggerEventsChan)
+				if err != nil {
+					log.Fatalf(fmt.Sprintf("%v", err))
+				}
+			})
+		} else {
+			startEventJournal()
+			TriggerDeployNewApps(&contexts, &triggerEventsChan)
+
+			if longevityScenario != nil {
+				go CollectEventRecords(&triggerEventsChan)
+				runScenario(longevityScenario, &contexts, &triggerEventsChan)
+			} else {
+				var wg sync.WaitGroup
+				Step("Register test triggers", func() {
+					for triggerType, triggerFunc := range triggerFunctions {
+						log.InfoD("Registering trigger: [%v]", triggerType)
+						go testTrigger(&wg, &contexts, triggerType, triggerFunc, &triggerEventsChan)
+						wg.Add(1)
+					}
+				})
+				log.InfoD("Finished registering test triggers")
+				if Inst().MinRunTimeMins != 0 {
+					log.InfoD("Longevity Tests  timeout set to %d  minutes", Inst().MinRunTimeMins)
+				}
+
+				Step("Register email trigger", func() {
+					for triggerType, triggerFunc := range emailTriggerFunction {
+						log.InfoD("Registering email trigger: [%v]", triggerType)
+						go emailEventTrigger(&wg, triggerType, triggerFunc, &emailTriggerLock)
+						wg.Add(1)
+					}
+				})
+				log.InfoD("Finished registering email trigger")
+
+				CollectEventRecords(&triggerEventsChan)
+				wg.Wait()
+				close(triggerEventsChan)
 			}
-		})
-		log.InfoD("Finished registering test triggers")
-		if Inst().MinRunTimeMins != 0 {
-			log.InfoD("Longevity Tests  timeout set to %d  minutes", Inst().MinRunTimeMins)
 		}
-
-		Step("Register email trigger", func() {
-			for triggerType, triggerFunc := range emailTriggerFunction {
-				log.InfoD("Registering email trigger: [%v]", triggerType)
-				go emailEventTrigger(&wg, triggerType, triggerFunc, &emailTriggerLock)
-				wg.Add(1)
-			}
-		})
-		log.InfoD("Finished registering email trigger")
-
-		CollectEventRecords(&triggerEventsChan)
-		wg.Wait()
-		close(triggerEventsChan)
 		Step("teardown all apps", func() {
 			for _, ctx := range contexts {
 				TearDownContext(ctx, nil)
@@ -180,6 +220,13 @@ var _ = Describe("{Longevity}", func() {
 	JustAfterEach(func() {
 		defer EndTorpedoTest()
 		AfterEachTest(contexts)
+		if EventJournal != nil {
+			EventJournal.Close()
+		}
+		if EventLog != nil {
+			EventLog.Close()
+		}
+		Notifiers.Close()
 	})
 })
 
@@ -187,7 +234,6 @@ func testTrigger(wg *sync.WaitGroup,
 	contexts *[]*scheduler.Context,
 	triggerType string,
 	triggerFunc func(*[]*scheduler.Context, *chan *EventRecord),
-	triggerLoc *sync.Mutex,
 	triggerEventsChan *chan *EventRecord) {
 	defer wg.Done()
 
@@ -211,30 +257,14 @@ func testTrigger(wg *sync.WaitGroup,
 		if isTriggerEnabled && time.Since(lastInvocationTime) > time.Duration(waitTime) {
 			// If trigger is not disabled and its right time to trigger,
 
-			log.Infof("Waiting for lock for trigger [%s]\n", triggerType)
-			triggerLoc.Lock()
-			log.Infof("Successfully taken lock for trigger [%s]\n", triggerType)
-			/* PTX-2667: check no other disruptive trigger is happening at same time
-			if isDisruptiveTrigger(triggerType) {
-			   // At a give point in time, only single disruptive trigger is allowed to run.
-			   // No other disruptive or non-disruptive trigger can run at this time.
-			   triggerLoc.Lock()
-			} else {
-			   // If trigger is non-disruptive then just check if no other disruptive trigger is running or not
-			   // and release the lock immidiately so that other non-disruptive triggers can happen.
-				triggerLoc.Lock()
-				log.Infof("===No other disruptive event happening. Able to take lock for [%s]\n", triggerType)
-				triggerLoc.Unlock()
-				log.Infof("===Releasing lock for non-disruptive event [%s]\n", triggerType)
-			}*/
-
-			triggerFunc(contexts, triggerEventsChan)
-			log.Infof("Trigger Function completed for [%s]\n", triggerType)
-
-			//if isDisruptiveTrigger(triggerType) {
-			triggerLoc.Unlock()
-			log.Infof("Successfully released lock for trigger [%s]\n", triggerType)
-			//}
+			// the claims are released even if the trigger panics
+			func() {
+				releaseClaims := acquireTriggerClaims(triggerType, claimsOf(triggerType))
+				defer releaseClaims()
+				triggerFunc(contexts, triggerEventsChan)
+				log.Infof("Trigger Function completed for [%s]\n", triggerType)
+			}()
+			log.Infof("Successfully released claims of trigger [%s]\n", triggerType)
 
 			lastInvocationTime = time.Now().Local()
 
@@ -286,6 +316,224 @@ func emailEventTrigger(wg *sync.WaitGroup,
 	}
 }
 
+// startEventJournal starts recording the events of the run to a replay journal in the log location
+func startEventJournal() {
+	path := filepath.Join(Inst().LogLoc, fmt.Sprintf("longevity-journal-%s.jsonl", Inst().InstanceID))
+	var err error
+	EventJournal, err = replay.CreateJournal(path, TriggerRand.Seed())
+	if err != nil {
+		log.Errorf("Failed to start replay journal, this run cannot be replayed: %v", err)
+		return
+	}
+	log.InfoD("Recording events with seed [%d] to replay journal [%s]", TriggerRand.Seed(), path)
+}
+
+// startEventLog starts recording the events of the run to a structured event log in the log location
+func startEventLog() {
+	path := filepath.Join(Inst().LogLoc, fmt.Sprintf("longevity-events-%s.jsonl", Inst().InstanceID))
+	var err error
+	EventLog, err = eventlog.NewWriter(path, Inst().InstanceID)
+	if err != nil {
+		log.Errorf("Failed to start event log: %v", err)
+		return
+	}
+	log.InfoD("Recording events to event log [%s]", path)
+}
+
+// replayJournal re-executes the events of a replay journal one at a time, in the order in which
+// they completed, with the seed of the journal so that the triggers make the same random choices
+func replayJournal(path string, contexts *[]*scheduler.Context, triggerEventsChan *chan *EventRecord) error {
+	entries, err := replay.ReadJournal(path)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("replay journal [%s] has no events", path)
+	}
+	for _, e := range entries {
+		if _, ok := triggerFunctions[e.Trigger]; !ok {
+			return fmt.Errorf("event [%d] of replay journal [%s] is of unknown trigger [%s]", e.Seq, path, e.Trigger)
+		}
+	}
+
+	TriggerRand = replay.NewSource(entries[0].Seed)
+	startEventJournal()
+	log.InfoD("Replaying %d events of journal [%s] with seed [%d]", len(entries), path, entries[0].Seed)
+
+	if ChaosMap == nil {
+		ChaosMap = map[string]int{}
+	}
+	if TriggerParams == nil {
+		TriggerParams = map[string]map[string]string{}
+	}
+	go CollectEventRecords(triggerEventsChan)
+	replayEventsChan := make(chan *EventRecord, 100)
+	for _, e := range entries {
+		ChaosMap[e.Trigger] = e.ChaosLevel
+		TriggerParams[e.Trigger] = e.Params
+		stepLog := fmt.Sprintf("replay event [%d/%d] of trigger [%s] with chaos level [%d]",
+			e.Seq, len(entries), e.Trigger, e.ChaosLevel)
+		Step(stepLog, func() {
+			log.InfoD(stepLog)
+			triggerFunctions[e.Trigger](contexts, &replayEventsChan)
+		})
+		// triggers send their event record before they return
+		for len(replayEventsChan) > 0 {
+			eventRecord := <-replayEventsChan
+			targets := eventRecord.TargetNames()
+			if strings.Join(targets, ",") != strings.Join(e.Targets, ",") {
+				log.Warnf("Event [%d] of trigger [%s] chose targets %v in the journal but %v in the replay",
+					e.Seq, e.Trigger, e.Targets, targets)
+			}
+			*triggerEventsChan <- eventRecord
+		}
+	}
+	close(*triggerEventsChan)
+	return nil
+}
+
+// loadScenario loads the longevity scenario file, validates it against the registered triggers
+// and populates the chaos levels, intervals and parameters of its triggers
+func loadScenario(path string) (*scenario.Scenario, error) {
+	s, err := scenario.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Validate(func(name string) bool {
+		_, isTrigger := triggerFunctions[name]
+		_, isEmailTrigger := emailTriggerFunction[name]
+		return isTrigger || isEmailTrigger
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string)
+	for k, v := range s.Settings {
+		settings[k] = v
+	}
+	if err = populateSettings(&settings); err != nil {
+		return nil, err
+	}
+
+	if s.Stop.Duration.Duration == 0 && Inst().MinRunTimeMins != 0 {
+		s.Stop.Duration.Duration = time.Duration(Inst().MinRunTimeMins) * time.Minute
+	}
+
+	ChaosMap = map[string]int{}
+	TriggerParams = map[string]map[string]string{}
+	RunningTriggers = map[string]time.Duration{}
+	for i := range s.Triggers {
+		t := &s.Triggers[i]
+		chaosLevel := s.ChaosLevelOf(t)
+		if chaosLevel == 0 {
+			chaosLevel = Inst().ChaosLevel
+		}
+		interval := t.Interval.Duration
+		if interval == 0 {
+			interval = triggerInterval[t.Name][chaosLevel]
+		}
+		if interval == 0 {
+			return nil, fmt.Errorf("trigger [%s] in scenario [%s] has no interval and none is defined for chaos level [%d]",
+				t.Name, s.Name, chaosLevel)
+		}
+		ChaosMap[t.Name] = chaosLevel
+		TriggerParams[t.Name] = t.Params
+		RunningTriggers[t.Name] = interval
+		if t.Name == BackupScheduleAll || t.Name == BackupScheduleScale {
+			SetScheduledBackupInterval(interval, t.Name)
+		}
+		// email triggers do not wait for other triggers unless the scenario says so
+		if _, isEmailTrigger := emailTriggerFunction[t.Name]; isEmailTrigger && t.Group == "" {
+			t.Group = EmailReporter
+		}
+	}
+	log.InfoD("Loaded longevity scenario [%s] with %d triggers", s.Name, len(s.Triggers))
+	return s, nil
+}
+
+// runScenario runs the triggers of the longevity scenario till all of them are done
+func runScenario(s *scenario.Scenario, contexts *[]*scheduler.Context, triggerEventsChan *chan *EventRecord) {
+	tracker := scenario.NewTracker(s, time.Now())
+	// Triggers report to their own channel so that failed events count towards the stop conditions
+	scenarioEventsChan := make(chan *EventRecord, 100)
+
+	var wg sync.WaitGroup
+	Step("Register scenario triggers", func() {
+		for _, t := range s.Triggers {
+			triggerFunc, ok := triggerFunctions[t.Name]
+			if !ok {
+				emailFunc := emailTriggerFunction[t.Name]
+				triggerFunc = func(*[]*scheduler.Context, *chan *EventRecord) {
+					emailFunc()
+				}
+			}
+			resourceClaims := claimsOf(t.Name)
+			if len(t.Claims) > 0 {
+				// validation made sure the claims of the scenario parse
+				resourceClaims, _ = t.ParseClaims()
+			}
+			log.InfoD("Registering trigger: [%v] with interval [%v] and claims %v", t.Name, RunningTriggers[t.Name], resourceClaims)
+			wg.Add(1)
+			go scenarioTrigger(&wg, contexts, t.Name, RunningTriggers[t.Name], resourceClaims, triggerFunc, tracker, &scenarioEventsChan)
+		}
+	})
+	log.InfoD("Finished registering scenario triggers")
+
+	go func() {
+		wg.Wait()
+		close(scenarioEventsChan)
+	}()
+	for eventRecord := range scenarioEventsChan {
+		if len(eventRecord.Outcome) > 0 {
+			tracker.RecordFailure()
+		}
+		*triggerEventsChan <- eventRecord
+	}
+	close(*triggerEventsChan)
+	log.InfoD("Longevity scenario [%s] completed", s.Name)
+}
+
+func scenarioTrigger(wg *sync.WaitGroup,
+	contexts *[]*scheduler.Context,
+	triggerType string,
+	interval time.Duration,
+	resourceClaims []claims.Claim,
+	triggerFunc func(*[]*scheduler.Context, *chan *EventRecord),
+	tracker *scenario.Tracker,
+	triggerEventsChan *chan *EventRecord) {
+	defer wg.Done()
+
+	groupLock := tracker.GroupLock(triggerType)
+	lastInvocationTime := time.Now().Local()
+
+	for !tracker.Done(triggerType, time.Now()) {
+		if time.Since(lastInvocationTime) > interval && tracker.Ready(triggerType, time.Now()) {
+			// the claims and the lock are released even if the trigger panics
+			func() {
+				log.Infof("Waiting for lock for trigger [%s]\n", triggerType)
+				groupLock.Lock()
+				defer groupLock.Unlock()
+				log.Infof("Successfully taken lock for trigger [%s]\n", triggerType)
+
+				// The scenario may have stopped while waiting for the lock
+				if tracker.Ready(triggerType, time.Now()) {
+					releaseClaims := acquireTriggerClaims(triggerType, resourceClaims)
+					defer releaseClaims()
+					triggerFunc(contexts, triggerEventsChan)
+					tracker.RecordRun(triggerType)
+					log.Infof("Trigger Function completed for [%s]\n", triggerType)
+				}
+			}()
+			log.Infof("Successfully released lock for trigger [%s]\n", triggerType)
+
+			lastInvocationTime = time.Now().Local()
+		}
+		time.Sleep(controlLoopSleepTime)
+	}
+	log.InfoD("Trigger [%s] is done after %d runs", triggerType, tracker.Runs(triggerType))
+}
+
 func watchConfigMap() error {
 	ChaosMap = map[string]int{}
 	cm, err := core.Instance().GetConfigMap(testTriggersConfigMap, configMapNS)
@@ -320,57 +568,116 @@ func watchConfigMap() error {
 	return nil
 }
 
-func populateDisruptiveTriggers() {
-	disruptiveTriggers = map[string]bool{
-		HAIncrease:                      false,
-		HADecrease:                      false,
-		RestartVolDriver:                false,
-		CrashVolDriver:                  false,
-		RebootNode:                      true,
-		CrashNode:                       true,
-		EmailReporter:                   false,
-		AppTaskDown:                     false,
-		DeployApps:                      false,
-		BackupAllApps:                   false,
-		BackupScheduleAll:               false,
-		BackupScheduleScale:             true,
-		BackupSpecificResource:          false,
-		BackupSpecificResourceOnCluster: false,
-		TestInspectBackup:               false,
-		TestInspectRestore:              false,
-		TestDeleteBackup:                false,
-		RestoreNamespace:                false,
-		BackupUsingLabelOnCluster:       false,
-		BackupRestartPX:                 false,
-		BackupRestartNode:               false,
-		BackupDeleteBackupPod:           false,
-		BackupScaleMongo:                false,
-		AppTasksDown:                    false,
-		RestartManyVolDriver:            true,
-		RebootManyNodes:                 true,
-		RestartKvdbVolDriver:            true,
-		NodeDecommission:                true,
-		CsiSnapShot:                     false,
-		CsiSnapRestore:                  false,
-		KVDBFailover:                    true,
-		HAIncreaseAndReboot:             true,
-		AddDiskAndReboot:                true,
-		ResizeDiskAndReboot:             true,
-		VolumeCreatePxRestart:           true,
+func populateTriggerClaims() {
+	var (
+		claimNodes        = claims.Claim{Resource: claims.Node, Instance: claims.AllInstances, Mode: claims.Exclusive}
+		claimAnyNode      = claims.Claim{Resource: claims.Node, Instance: claims.AnyInstance, Mode: claims.Exclusive}
+		useNodes          = claims.Claim{Resource: claims.Node, Instance: claims.AllInstances, Mode: claims.Shared}
+		claimPools        = claims.Claim{Resource: claims.Pool, Instance: claims.AllInstances, Mode: claims.Exclusive}
+		claimKvdbLeader   = claims.Claim{Resource: claims.KvdbLeader, Instance: claims.AllInstances, Mode: claims.Exclusive}
+		claimBackupServer = claims.Claim{Resource: claims.BackupServer, Instance: claims.AllInstances, Mode: claims.Exclusive}
+		useBackupServer   = claims.Claim{Resource: claims.BackupServer, Instance: claims.AllInstances, Mode: claims.Shared}
+		claimApps         = claims.Claim{Resource: claims.App, Instance: claims.AllInstances, Mode: claims.Exclusive}
+		useApps           = claims.Claim{Resource: claims.App, Instance: claims.AllInstances, Mode: claims.Shared}
+	)
+
+	triggerClaims = map[string][]claims.Claim{
+		DeployApps:    {claimApps},
+		VolumesDelete: {claimApps},
+		EmailReporter: {},
+		CoreChecker:   {useNodes},
+
+		// RebootNode and CrashNode claim each node while they disrupt it
+		RebootNode:            {claimAnyNode, useApps},
+		CrashNode:             {claimAnyNode, useApps},
+		RestartVolDriver:      {claimNodes, useApps},
+		CrashVolDriver:        {claimNodes, useApps},
+		RestartManyVolDriver:  {claimNodes, useApps},
+		RebootManyNodes:       {claimNodes, useApps},
+		VolumeCreatePxRestart: {claimNodes, useApps},
+		ValidateDeviceMapper:  {useNodes, useApps},
+		RestartKvdbVolDriver:  {claimKvdbLeader, claimNodes, useApps},
+		KVDBFailover:          {claimKvdbLeader, claimNodes, useApps},
+		NodeDecommission:      {claimNodes, claimPools, useApps},
+		NodeRejoin:            {claimNodes, claimPools, useApps},
+
+		PoolResizeDisk:      {claimPools, useApps},
+		PoolAddDisk:         {claimPools, useApps},
+		AddDrive:            {claimPools, useApps},
+		AutopilotRebalance:  {claimPools, useApps},
+		HAIncreaseAndReboot: {claimNodes, useApps},
+		AddDiskAndReboot:    {claimPools, claimNodes, useApps},
+		ResizeDiskAndReboot: {claimPools, claimNodes, useApps},
+
+		HAIncrease:              {useApps},
+		HADecrease:              {useApps},
+		AppTaskDown:             {useApps},
+		AppTasksDown:            {useApps},
+		VolumeClone:             {useApps},
+		VolumeResize:            {useApps},
+		UpdateVolume:            {useApps},
+		AutoFsTrim:              {useApps},
+		CloudSnapShot:           {useApps},
+		LocalSnapShot:           {useApps},
+		DeleteLocalSnapShot:     {useApps},
+		CsiSnapShot:             {useApps},
+		CsiSnapRestore:          {useApps},
+		RelaxedReclaim:          {useApps},
+		Trashcan:                {useApps},
+		AsyncDR:                 {useApps},
+		AsyncDRVolumeOnly:       {useApps},
+		AsyncDRFailoverFailback: {claimNodes, claimApps},
+		StorkApplicationBackup:  {useApps},
+		StorkAppBkpVolResize:    {useApps},
+		StorkAppBkpHaUpdate:     {useApps},
+		StorkAppBkpPxRestart:    {claimNodes, useApps},
+		StorkAppBkpPoolResize:   {claimPools, useApps},
+		UpgradeStork:            {claimNodes, claimApps},
+		UpgradeVolumeDriver:     {claimNodes, claimPools, claimKvdbLeader, claimApps},
+
+		BackupAllApps:                   {useBackupServer, useApps},
+		BackupScheduleAll:               {useBackupServer, useApps},
+		BackupScheduleScale:             {claimBackupServer, useApps},
+		BackupSpecificResource:          {useBackupServer, useApps},
+		BackupSpecificResourceOnCluster: {useBackupServer, useApps},
+		TestInspectBackup:               {useBackupServer},
+		TestInspectRestore:              {useBackupServer},
+		TestDeleteBackup:                {useBackupServer},
+		RestoreNamespace:                {useBackupServer, useApps},
+		BackupUsingLabelOnCluster:       {useBackupServer, useApps},
+		BackupRestartPX:                 {claimBackupServer, claimNodes, useApps},
+		BackupRestartNode:               {claimBackupServer, claimNodes, useApps},
+		BackupDeleteBackupPod:           {claimBackupServer},
+		BackupScaleMongo:                {claimBackupServer},
+	}
+}
+
+// claimsOf returns the claims of the given trigger. Triggers without declared claims are
+// assumed to disrupt everything.
+func claimsOf(triggerType string) []claims.Claim {
+	if resourceClaims, ok := triggerClaims[triggerType]; ok {
+		return resourceClaims
+	}
+	return []claims.Claim{
+		{Resource: claims.Node, Instance: claims.AllInstances, Mode: claims.Exclusive},
+		{Resource: claims.Pool, Instance: claims.AllInstances, Mode: claims.Exclusive},
+		{Resource: claims.App, Instance: claims.AllInstances, Mode: claims.Exclusive},
 	}
 }
 
-func isDisruptiveTrigger(triggerType string) bool {
-	return disruptiveTriggers[triggerType]
+// acquireTriggerClaims blocks till the claims of the trigger do not conflict with the claims of
+// running triggers and returns a function releasing them
+func acquireTriggerClaims(triggerType string, resourceClaims []claims.Claim) (release func()) {
+	if owners := TriggerClaims.Conflicts(triggerType, resourceClaims...); len(owners) > 0 {
+		log.Infof("Trigger [%s] waiting for conflicting claims of triggers %v\n", triggerType, owners)
+	}
+	release = TriggerClaims.Acquire(triggerType, resourceClaims...)
+	log.Infof("Successfully claimed %v for trigger [%s]\n", resourceClaims, triggerType)
+	return release
 }
 
 func populateDataFromConfigMap(configData *map[string]string) error {
-	setEmailRecipients(configData)
-	setEmailHost(configData)
-	setEmailSubject(configData)
-	setPureTopology(configData)
-	setHyperConvergedType(configData)
-	err := setSendGridEmailAPIKey(configData)
+	err := populateSettings(configData)
 	if err != nil {
 		return err
 	}
@@ -382,6 +689,19 @@ func populateDataFromConfigMap(configData *map[string]string) error {
 	return nil
 }
 
+// populateSettings sets the non trigger fields, which come from either the config map or the scenario settings
+func populateSettings(configData *map[string]string) error {
+	setEmailRecipients(configData)
+	setEmailHost(configData)
+	setEmailSubject(configData)
+	if err := setNotificationSinks(configData); err != nil {
+		return err
+	}
+	setPureTopology(configData)
+	setHyperConvergedType(configData)
+	return setSendGridEmailAPIKey(configData)
+}
+
 func setEmailRecipients(configData *map[string]string) {
 	// Get email recipients from configMap
 	if emailRecipients, ok := (*configData)[EmailRecipientsConfigMapField]; !ok {
@@ -414,6 +734,28 @@ func setEmailSubject(configData *map[string]string) {
 	}
 }
 
+// setNotificationSinks configures the notification sinks of the events, which are sent to no sink
+// when the field is missing
+func setNotificationSinks(configData *map[string]string) error {
+	var configs []notification.Config
+	if sinks, ok := (*configData)[NotificationSinksField]; ok {
+		var err error
+		configs, err = notification.ParseConfigs([]byte(sinks))
+		if err != nil {
+			return fmt.Errorf("Failed to parse [%s] field in config-map [%s] in namespace [%s]. Error:[%v]",
+				NotificationSinksField, testTriggersConfigMap, configMapNS, err)
+		}
+		delete(*configData, NotificationSinksField)
+	}
+	if err := Notifiers.Configure(EmailSubject, configs); err != nil {
+		return err
+	}
+	if len(configs) > 0 {
+		log.Infof("Sending events to %d notification sinks", len(configs))
+	}
+	return nil
+}
+
 // setPureTopology read the config map and set the pureTopologyEnabled field
 func setPureTopology(configData *map[string]string) {
 	// Set Pure Topology Enabled value from configMap
@@ -621,6 +963,7 @@ func populateIntervals() {
 	triggerInterval[ValidateDeviceMapper] = make(map[int]time.Duration)
 	triggerInterval[AsyncDR] = make(map[int]time.Duration)
 	triggerInterval[AsyncDRVolumeOnly] = make(map[int]time.Duration)
+	triggerInterval[AsyncDRFailoverFailback] = make(map[int]time.Duration)
 	triggerInterval[StorkApplicationBackup] = make(map[int]time.Duration)
 	triggerInterval[StorkAppBkpVolResize] = make(map[int]time.Duration)
 	triggerInterval[StorkAppBkpHaUpdate] = make(map[int]time.Duration)
@@ -775,6 +1118,17 @@ func populateIntervals() {
 	triggerInterval[AsyncDRVolumeOnly][2] = 24 * baseInterval
 	triggerInterval[AsyncDRVolumeOnly][1] = 27 * baseInterval
 
+	triggerInterval[AsyncDRFailoverFailback][10] = 1 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][9] = 3 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][8] = 6 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][7] = 9 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][6] = 12 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][5] = 15 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][4] = 18 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][3] = 21 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][2] = 24 * baseInterval
+	triggerInterval[AsyncDRFailoverFailback][1] = 27 * baseInterval
+
 	triggerInterval[StorkApplicationBackup][10] = 1 * baseInterval
 	triggerInterval[StorkApplicationBackup][9] = 3 * baseInterval
 	triggerInterval[StorkApplicationBackup][8] = 6 * baseInterval
@@ -1310,6 +1664,7 @@ func populateIntervals() {
 	triggerInterval[ValidateDeviceMapper][0] = 0
 	triggerInterval[AsyncDR][0] = 0
 	triggerInterval[AsyncDRVolumeOnly][0] = 0
+	triggerInterval[AsyncDRFailoverFailback][0] = 0
 	triggerInterval[StorkApplicationBackup][0] = 0
 	triggerInterval[StorkAppBkpVolResize][0] = 0
 	triggerInterval[StorkAppBkpHaUpdate][0] = 0
@@ -1415,7 +1415,7 @@ var _ = Describe("{AddDriveStoragelessAndResize}", func() {
 		if len(slNodes) == 0 {
 			dash.VerifyFatal(len(slNodes) > 0, true, "Storage less nodes found?")
 		}
-		slNode := GetRandomStorageLessNode(slNodes)
+		slNode := GetRandomStorageLessNode("AddDriveStoragelessAndResize", slNodes)
 		err := addCloudDrive(slNode, -1)
 		log.FailOnError(err, "error adding cloud drive")
 		err = Inst().V.RefreshDriverEndpoints()
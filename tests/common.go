@@ -12,9 +12,17 @@ import (
 	"regexp"
 
 	"github.com/portworx/torpedo/pkg/aetosutil"
+	"github.com/portworx/torpedo/pkg/backuplocation"
 	"github.com/portworx/torpedo/pkg/log"
+	"github.com/portworx/torpedo/pkg/publisher"
+	"github.com/portworx/torpedo/pkg/publisher/aetos"
+	"github.com/portworx/torpedo/pkg/publisher/jira"
+	"github.com/portworx/torpedo/pkg/publisher/testrail"
+	"github.com/portworx/torpedo/pkg/replay"
 	"github.com/portworx/torpedo/pkg/units"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -34,6 +42,7 @@ import (
 	storageapi "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	gcs "cloud.google.com/go/storage"
 	"github.com/Azure/azure-storage-blob-go/azblob"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -81,6 +90,8 @@ import (
 	_ "github.com/portworx/torpedo/drivers/node/ibm"
 	// import oracle driver to invoke it's init
 	_ "github.com/portworx/torpedo/drivers/node/oracle"
+	// import container driver to invoke it's init
+	_ "github.com/portworx/torpedo/drivers/node/container"
 
 	// import ssh driver to invoke it's init
 	_ "github.com/portworx/torpedo/drivers/node/ssh"
@@ -88,6 +99,7 @@ import (
 
 	// import scheduler drivers to invoke it's init
 	_ "github.com/portworx/torpedo/drivers/scheduler/dcos"
+	_ "github.com/portworx/torpedo/drivers/scheduler/fake"
 	"github.com/portworx/torpedo/drivers/scheduler/k8s"
 
 	// import scheduler drivers to invoke it's init
@@ -106,6 +118,8 @@ import (
 
 	// import generic csi driver to invoke it's init
 	_ "github.com/portworx/torpedo/drivers/volume/generic_csi"
+	// import simulated volume driver to invoke it's init
+	_ "github.com/portworx/torpedo/drivers/volume/simulator"
 
 	// import driver to invoke it's init
 	_ "github.com/portworx/torpedo/drivers/monitor/prometheus"
@@ -139,6 +153,9 @@ const (
 	minRunTimeMinsFlag                   = "minimun-runtime-mins"
 	chaosLevelFlag                       = "chaos-level"
 	hyperConvergedFlag                   = "hyper-converged"
+	longevityScenarioFlag                = "longevity-scenario"
+	seedFlag                             = "seed"
+	replayJournalFlag                    = "replay-journal"
 	storageUpgradeEndpointURLCliFlag     = "storage-upgrade-endpoint-url"
 	storageUpgradeEndpointVersionCliFlag = "storage-upgrade-endpoint-version"
 	upgradeStorageDriverEndpointListFlag = "upgrade-storage-driver-endpoint-list"
@@ -175,6 +192,9 @@ const (
 	jiraTokenFlag     = "jira-token"
 	jiraAccountIDFlag = "jira-account-id"
 
+	resultPublishersFlag      = "result-publishers"
+	resultPublisherParamsFlag = "result-publisher-params"
+
 	// Async DR
 	pairFileName           = "cluster-pair.yaml"
 	remotePairName         = "remoteclusterpair"
@@ -184,6 +204,9 @@ const (
 	migrationRetryTimeout  = 10 * time.Minute
 	migrationRetryInterval = 10 * time.Second
 	defaultClusterPairDir  = "cluster-pair"
+	// asyncDRScheduleInterval is the interval in minutes of the migration schedules of Async DR runbooks
+	asyncDRScheduleInterval = 5
+	asyncDRAdminNamespace   = "kube-system"
 
 	envSkipDiagCollection = "SKIP_DIAG_COLLECTION"
 
@@ -196,6 +219,7 @@ const (
 // Dashboard params
 const (
 	enableDashBoardFlag     = "enable-dash"
+	reportDirFlag           = "report-dir"
 	userFlag                = "user"
 	testTypeFlag            = "test-type"
 	testDescriptionFlag     = "test-desc"
@@ -280,6 +304,9 @@ var (
 	CurrentTestRailTestCaseId int
 )
 
+// currentTestCase is the test case started by StartTorpedoTest
+var currentTestCase *publisher.TestCase
+
 var (
 	errPureFileSnapshotNotSupported    = errors.New("snapshot feature is not supported for pure_file volumes")
 	errPureCloudsnapNotSupported       = errors.New("cloudsnap feature is not supported for pure volumes")
@@ -329,6 +356,9 @@ var (
 var (
 	jiraUserName string
 	jiraToken    string
+
+	resultPublishers      string
+	resultPublisherParams string
 )
 
 const (
@@ -408,15 +438,7 @@ func InitInstance() {
 		err = Inst().Backup.Init(Inst().S.String(), Inst().N.String(), Inst().V.String(), token)
 		log.FailOnError(err, "Error occured while Backup Driver Initialization")
 	}
-	SetupTestRail()
-
-	if jiraUserName != "" && jiraToken != "" {
-		log.Infof("Initializing JIRA connection")
-		jirautils.Init(jiraUserName, jiraToken)
-
-	} else {
-		log.Debugf("Not all information to connect to JIRA is provided.")
-	}
+	SetupResultPublishers()
 
 	pxVersion, err := Inst().V.GetDriverVersion()
 	log.FailOnError(err, "Error occured while getting PX version")
@@ -2011,28 +2033,26 @@ func ValidateVolumeParametersGetErr(volParam map[string]map[string]string) error
 
 // AfterEachTest runs collect support bundle after each test when it fails
 func AfterEachTest(contexts []*scheduler.Context, ids ...int) {
-	testStatus := "Pass"
+	testStatus := publisher.StatusPass
 	ginkgoTestDescr := ginkgo.CurrentGinkgoTestDescription()
 	if ginkgoTestDescr.Failed {
 		log.Infof(">>>> FAILED TEST: %s", ginkgoTestDescr.FullTestText)
 		CollectSupport()
 		DescribeNamespace(contexts)
-		testStatus = "Fail"
+		testStatus = publisher.StatusFail
 	}
 	if len(ids) >= 1 {
 		driverVersion, err := Inst().V.GetDriverVersion()
 		if err != nil {
 			log.Errorf("Error in getting driver version")
 		}
-		testrailObject := testrailuttils.Testrail{
+		publisher.PublishResult(&publisher.Result{
 			Status:          testStatus,
-			TestID:          ids[0],
+			TestRepoID:      ids[0],
 			RunID:           ids[1],
 			DriverVersion:   driverVersion,
 			PxBackupVersion: PxBackupVersion,
-		}
-		testrailuttils.AddTestEntry(testrailObject)
-		log.Infof("Testrail testrun url: %s/index.php?/runs/view/%d&group_by=cases:custom_automated&group_order=asc&group_id=%d", testRailHostname, ids[1], testrailuttils.PwxProjectID)
+		})
 	}
 }
 
@@ -2065,10 +2085,13 @@ func SetSourceKubeConfig() error {
 }
 
 // SetDestinationKubeConfig sets current context to the kubeconfig passed as destination to the torpedo test
-func SetDestinationKubeConfig() {
+func SetDestinationKubeConfig() error {
 	destClusterConfigPath, err := GetDestinationClusterConfigPath()
-	expect(err).NotTo(haveOccurred())
+	if err != nil {
+		return err
+	}
 	SetClusterContext(destClusterConfigPath)
+	return nil
 }
 
 // ScheduleValidateClusterPair Schedule a clusterpair by creating a yaml file and validate it
@@ -2083,7 +2106,9 @@ func ScheduleValidateClusterPair(ctx *scheduler.Context, skipStorage, resetConfi
 			return err
 		}
 	} else {
-		SetDestinationKubeConfig()
+		if err = SetDestinationKubeConfig(); err != nil {
+			return err
+		}
 		// get the kubeconfig path to get the correct pairing info
 		kubeConfigPath, err = GetDestinationClusterConfigPath()
 		if err != nil {
@@ -2110,7 +2135,9 @@ func ScheduleValidateClusterPair(ctx *scheduler.Context, skipStorage, resetConfi
 
 	// Set the correct cluster context to apply the cluster pair spec
 	if reverse {
-		SetDestinationKubeConfig()
+		if err = SetDestinationKubeConfig(); err != nil {
+			return err
+		}
 	} else {
 		SetSourceKubeConfig()
 	}
@@ -2173,7 +2200,9 @@ func CreateClusterPairFile(pairInfo map[string]string, skipStorage, resetConfig
 		SetSourceKubeConfig()
 	} else {
 		// Change kubeconfig to destination cluster config
-		SetDestinationKubeConfig()
+		if err = SetDestinationKubeConfig(); err != nil {
+			return err
+		}
 	}
 
 	if skipStorage {
@@ -2843,16 +2872,36 @@ func CreateSourceAndDestClusters(orgID string, cloudName string, uid string, ctx
 	return nil
 }
 
-// CreateBackupLocation creates backup location
+// CreateBackupLocation creates backup location of the provider
 func CreateBackupLocation(provider, name, uid, credName, credUID, bucketName, orgID string, encryptionKey string) error {
-	var err error
 	switch provider {
 	case drivers.ProviderAws:
-		err = CreateS3BackupLocation(name, uid, credName, credUID, bucketName, orgID, encryptionKey)
+		return CreateS3BackupLocation(name, uid, credName, credUID, bucketName, orgID, encryptionKey)
 	case drivers.ProviderAzure:
-		err = CreateAzureBackupLocation(name, uid, credName, CloudCredUID, bucketName, orgID)
+		return CreateAzureBackupLocation(name, uid, credName, CloudCredUID, bucketName, orgID)
 	}
-	return err
+	ctx, err := backup.GetAdminCtxFromSecret()
+	if err != nil {
+		return err
+	}
+	return createBackupLocationOfProvider(ctx, provider, backuplocation.Location{
+		Name:               name,
+		UID:                uid,
+		OrgID:              orgID,
+		Bucket:             bucketName,
+		EncryptionKey:      encryptionKey,
+		CloudCredential:    credName,
+		CloudCredentialUID: credUID,
+	})
+}
+
+// createBackupLocationOfProvider creates the backup location with the registered provider
+func createBackupLocationOfProvider(ctx context1.Context, provider string, location backuplocation.Location) error {
+	p, err := backuplocation.Get(provider)
+	if err != nil {
+		return err
+	}
+	return backuplocation.CreateBackupLocation(ctx, Inst().Backup, p, location)
 }
 
 // CreateCluster creates/registers cluster with px-backup
@@ -2897,270 +2946,84 @@ func CreateCluster(name string, kubeconfigPath string, orgID string, cloud_name
 func CreateCloudCredential(provider, name string, uid, orgID string) {
 	Step(fmt.Sprintf("Create cloud credential [%s] in org [%s]", name, orgID), func() {
 		log.Infof("Create credential name %s for org %s provider %s", name, orgID, provider)
-		backupDriver := Inst().Backup
-		switch provider {
-		case drivers.ProviderAws:
-			log.Infof("Create creds for aws")
-			id := os.Getenv("AWS_ACCESS_KEY_ID")
-			expect(id).NotTo(equal(""),
-				"AWS_ACCESS_KEY_ID Environment variable should not be empty")
-
-			secret := os.Getenv("AWS_SECRET_ACCESS_KEY")
-			expect(secret).NotTo(equal(""),
-				"AWS_SECRET_ACCESS_KEY Environment variable should not be empty")
-
-			credCreateRequest := &api.CloudCredentialCreateRequest{
-				CreateMetadata: &api.CreateMetadata{
-					Name:  name,
-					Uid:   uid,
-					OrgId: orgID,
-				},
-				CloudCredential: &api.CloudCredentialInfo{
-					Type: api.CloudCredentialInfo_AWS,
-					Config: &api.CloudCredentialInfo_AwsConfig{
-						AwsConfig: &api.AWSConfig{
-							AccessKey: id,
-							SecretKey: secret,
-						},
-					},
-				},
-			}
+		p, err := backuplocation.Get(provider)
+		expect(err).NotTo(haveOccurred(),
+			fmt.Sprintf("Failed to get backup location provider [%s]", provider))
 
-			ctx, err := backup.GetAdminCtxFromSecret()
-			log.FailOnError(err, fmt.Sprintf("Failed to fetch px-central-admin ctx: [%v]", err))
+		ctx, err := backup.GetAdminCtxFromSecret()
+		log.FailOnError(err, fmt.Sprintf("Failed to fetch px-central-admin ctx: [%v]", err))
 
-			_, err = backupDriver.CreateCloudCredential(ctx, credCreateRequest)
-			if err != nil && strings.Contains(err.Error(), "already exists") {
-				return
-			}
-			expect(err).NotTo(haveOccurred(),
-				fmt.Sprintf("Failed to create cloud credential [%s] in org [%s]", name, orgID))
-		// TODO: validate CreateCloudCredentialResponse also
-		case drivers.ProviderAzure:
-			log.Infof("Create creds for azure")
-			tenantID, clientID, clientSecret, subscriptionID, accountName, accountKey := GetAzureCredsFromEnv()
-			credCreateRequest := &api.CloudCredentialCreateRequest{
-				CreateMetadata: &api.CreateMetadata{
-					Name:  name,
-					Uid:   uid,
-					OrgId: orgID,
-				},
-				CloudCredential: &api.CloudCredentialInfo{
-					Type: api.CloudCredentialInfo_Azure,
-					Config: &api.CloudCredentialInfo_AzureConfig{
-						AzureConfig: &api.AzureConfig{
-							TenantId:       tenantID,
-							ClientId:       clientID,
-							ClientSecret:   clientSecret,
-							AccountName:    accountName,
-							AccountKey:     accountKey,
-							SubscriptionId: subscriptionID,
-						},
-					},
-				},
-			}
-			ctx, err := backup.GetAdminCtxFromSecret()
-			expect(err).NotTo(haveOccurred(),
-				fmt.Sprintf("Failed to fetch px-central-admin ctx: [%v]",
-					err))
-			_, err = backupDriver.CreateCloudCredential(ctx, credCreateRequest)
-			if err != nil && strings.Contains(err.Error(), "already exists") {
-				return
-			}
-			expect(err).NotTo(haveOccurred(),
-				fmt.Sprintf("Failed to create cloud credential [%s] in org [%s]", name, orgID))
-			// TODO: validate CreateCloudCredentialResponse also
+		err = backuplocation.CreateCloudCredential(ctx, Inst().Backup, p, name, uid, orgID)
+		if err != nil && strings.Contains(err.Error(), "already exists") {
+			return
 		}
+		expect(err).NotTo(haveOccurred(),
+			fmt.Sprintf("Failed to create cloud credential [%s] in org [%s]", name, orgID))
+		// TODO: validate CreateCloudCredentialResponse also
 	})
 }
 
-// CreateCloudCredential creates cloud credetials
+// CreateCloudCredentialNonAdminUser creates cloud credetials with the context of a non admin user
 func CreateCloudCredentialNonAdminUser(provider, name string, uid, orgID string, ctx context1.Context) error {
 	log.Infof("Create credential name %s for org %s provider %s", name, orgID, provider)
-	backupDriver := Inst().Backup
-	switch provider {
-	case drivers.ProviderAws:
-		log.Infof("Create creds for aws")
-		id := os.Getenv("AWS_ACCESS_KEY_ID")
-		if id == "" {
-			return fmt.Errorf("AWS_ACCESS_KEY_ID Environment variable should not be empty")
-		}
-		secret := os.Getenv("AWS_SECRET_ACCESS_KEY")
-		if secret == "" {
-			return fmt.Errorf("AWS_SECRET_ACCESS_KEY Environment variable should not be empty")
-		}
-		credCreateRequest := &api.CloudCredentialCreateRequest{
-			CreateMetadata: &api.CreateMetadata{
-				Name:  name,
-				Uid:   uid,
-				OrgId: orgID,
-			},
-			CloudCredential: &api.CloudCredentialInfo{
-				Type: api.CloudCredentialInfo_AWS,
-				Config: &api.CloudCredentialInfo_AwsConfig{
-					AwsConfig: &api.AWSConfig{
-						AccessKey: id,
-						SecretKey: secret,
-					},
-				},
-			},
-		}
-		_, err := backupDriver.CreateCloudCredential(ctx, credCreateRequest)
-		if err != nil && strings.Contains(err.Error(), "already exists") {
-			return nil
-		}
-		return err
-	// TODO: validate CreateCloudCredentialResponse also
-	case drivers.ProviderAzure:
-		log.Infof("Create creds for azure")
-		tenantID, clientID, clientSecret, subscriptionID, accountName, accountKey := GetAzureCredsFromEnv()
-		credCreateRequest := &api.CloudCredentialCreateRequest{
-			CreateMetadata: &api.CreateMetadata{
-				Name:  name,
-				Uid:   uid,
-				OrgId: orgID,
-			},
-			CloudCredential: &api.CloudCredentialInfo{
-				Type: api.CloudCredentialInfo_Azure,
-				Config: &api.CloudCredentialInfo_AzureConfig{
-					AzureConfig: &api.AzureConfig{
-						TenantId:       tenantID,
-						ClientId:       clientID,
-						ClientSecret:   clientSecret,
-						AccountName:    accountName,
-						AccountKey:     accountKey,
-						SubscriptionId: subscriptionID,
-					},
-				},
-			},
-		}
-
-		_, err := backupDriver.CreateCloudCredential(ctx, credCreateRequest)
-		if err != nil && strings.Contains(err.Error(), "already exists") {
-			return nil
-		}
+	p, err := backuplocation.Get(provider)
+	if err != nil {
 		return err
 	}
-	return nil
+	err = backuplocation.CreateCloudCredential(ctx, Inst().Backup, p, name, uid, orgID)
+	if err != nil && strings.Contains(err.Error(), "already exists") {
+		return nil
+	}
+	return err
 }
 
 // CreateS3BackupLocation creates backuplocation for S3
 func CreateS3BackupLocation(name string, uid, cloudCred string, cloudCredUID string, bucketName string, orgID string, encryptionKey string) error {
 	time.Sleep(60 * time.Second)
-	backupDriver := Inst().Backup
-	_, _, endpoint, region, disableSSLBool := s3utils.GetAWSDetailsFromEnv()
-	bLocationCreateReq := &api.BackupLocationCreateRequest{
-		CreateMetadata: &api.CreateMetadata{
-			Name:  name,
-			OrgId: orgID,
-			Uid:   uid,
-		},
-		BackupLocation: &api.BackupLocationInfo{
-			Path:          bucketName,
-			EncryptionKey: encryptionKey,
-			CloudCredentialRef: &api.ObjectRef{
-				Name: cloudCred,
-				Uid:  cloudCredUID,
-			},
-			Type: api.BackupLocationInfo_S3,
-			Config: &api.BackupLocationInfo_S3Config{
-				S3Config: &api.S3Config{
-					Endpoint:   endpoint,
-					Region:     region,
-					DisableSsl: disableSSLBool,
-				},
-			},
-		},
-	}
-
 	ctx, err := backup.GetAdminCtxFromSecret()
 	if err != nil {
 		return err
 	}
-
-	_, err = backupDriver.CreateBackupLocation(ctx, bLocationCreateReq)
-	if err != nil {
-		return fmt.Errorf("failed to create backup location: %v", err)
-	}
-	return nil
+	return CreateS3BackupLocationNonAdminUser(name, uid, cloudCred, cloudCredUID, bucketName, orgID, encryptionKey, ctx)
 }
 
 // CreateS3BackupLocationNonAdminUser creates backuplocation for S3
 func CreateS3BackupLocationNonAdminUser(name string, uid, cloudCred string, cloudCredUID string, bucketName string, orgID string, encryptionKey string, ctx context1.Context) error {
-	backupDriver := Inst().Backup
-	_, _, endpoint, region, disableSSLBool := s3utils.GetAWSDetailsFromEnv()
-	bLocationCreateReq := &api.BackupLocationCreateRequest{
-		CreateMetadata: &api.CreateMetadata{
-			Name:  name,
-			OrgId: orgID,
-			Uid:   uid,
-		},
-		BackupLocation: &api.BackupLocationInfo{
-			Path:          bucketName,
-			EncryptionKey: encryptionKey,
-			CloudCredentialRef: &api.ObjectRef{
-				Name: cloudCred,
-				Uid:  cloudCredUID,
-			},
-			Type: api.BackupLocationInfo_S3,
-			Config: &api.BackupLocationInfo_S3Config{
-				S3Config: &api.S3Config{
-					Endpoint:   endpoint,
-					Region:     region,
-					DisableSsl: disableSSLBool,
-				},
-			},
-		},
-	}
-
-	_, err := backupDriver.CreateBackupLocation(ctx, bLocationCreateReq)
-	if err != nil {
-		return err
-	}
-	return nil
+	return createBackupLocationOfProvider(ctx, drivers.ProviderAws, backuplocation.Location{
+		Name:               name,
+		UID:                uid,
+		OrgID:              orgID,
+		Bucket:             bucketName,
+		EncryptionKey:      encryptionKey,
+		CloudCredential:    cloudCred,
+		CloudCredentialUID: cloudCredUID,
+	})
 }
 
 // CreateAzureBackupLocation creates backuplocation for Azure
 func CreateAzureBackupLocation(name string, uid string, cloudCred string, cloudCredUID string, bucketName string, orgID string) error {
-	backupDriver := Inst().Backup
-	encryptionKey := "torpedo"
-	bLocationCreateReq := &api.BackupLocationCreateRequest{
-		CreateMetadata: &api.CreateMetadata{
-			Name:  name,
-			OrgId: orgID,
-			Uid:   uid,
-		},
-		BackupLocation: &api.BackupLocationInfo{
-			Path:          bucketName,
-			EncryptionKey: encryptionKey,
-			CloudCredentialRef: &api.ObjectRef{
-				Name: cloudCred,
-				Uid:  cloudCredUID,
-			},
-			Type: api.BackupLocationInfo_Azure,
-		},
-	}
 	ctx, err := backup.GetAdminCtxFromSecret()
 	if err != nil {
 		return err
 	}
-	_, err = backupDriver.CreateBackupLocation(ctx, bLocationCreateReq)
-	if err != nil {
-		return fmt.Errorf("failed to create backup location Error: %v", err)
-	}
-	return nil
+	return createBackupLocationOfProvider(ctx, drivers.ProviderAzure, backuplocation.Location{
+		Name:               name,
+		UID:                uid,
+		OrgID:              orgID,
+		Bucket:             bucketName,
+		EncryptionKey:      "torpedo",
+		CloudCredential:    cloudCred,
+		CloudCredentialUID: cloudCredUID,
+	})
 }
 
 // GetProvider validates and return object store provider
 func GetProvider() string {
+	providers := strings.Join(backuplocation.Names(), ", ")
 	provider, ok := os.LookupEnv("OBJECT_STORE_PROVIDER")
-	expect(ok).To(beTrue(), fmt.Sprintf("No environment variable 'PROVIDER' supplied. Valid values are: %s, %s, %s",
-		drivers.ProviderAws, drivers.ProviderAzure, drivers.ProviderGke))
-	switch provider {
-	case drivers.ProviderAws, drivers.ProviderAzure, drivers.ProviderGke:
-	default:
-		fail(fmt.Sprintf("Valid values for 'PROVIDER' environment variables are: %s, %s, %s",
-			drivers.ProviderAws, drivers.ProviderAzure, drivers.ProviderGke))
+	expect(ok).To(beTrue(), fmt.Sprintf("No environment variable 'PROVIDER' supplied. Valid values are: %s", providers))
+	if _, err := backuplocation.Get(provider); err != nil {
+		fail(fmt.Sprintf("Valid values for 'PROVIDER' environment variables are: %s", providers))
 	}
 	return provider
 }
@@ -3396,25 +3259,18 @@ func SetScheduledBackupInterval(interval time.Duration, triggerType string) {
 
 // DeleteS3Bucket deletes bucket in S3
 func DeleteS3Bucket(bucketName string) {
-	id, secret, endpoint, s3Region, disableSSLBool := s3utils.GetAWSDetailsFromEnv()
-	sess, err := session.NewSession(&aws.Config{
-		Endpoint:         aws.String(endpoint),
-		Credentials:      credentials.NewStaticCredentials(id, secret, ""),
-		Region:           aws.String(s3Region),
-		DisableSSL:       aws.Bool(disableSSLBool),
-		S3ForcePathStyle: aws.Bool(true),
-	},
-	)
-	expect(err).NotTo(haveOccurred(),
-		fmt.Sprintf("Failed to get S3 session to create bucket. Error: [%v]", err))
+	DeleteS3BucketOfProvider(drivers.ProviderAws, bucketName)
+}
 
-	S3Client := s3.New(sess)
+// DeleteS3BucketOfProvider deletes bucket in the S3 compatible object store of the provider
+func DeleteS3BucketOfProvider(provider string, bucketName string) {
+	S3Client := getS3Client(provider)
 
 	iter := s3manager.NewDeleteListIterator(S3Client, &s3.ListObjectsInput{
 		Bucket: aws.String(bucketName),
 	})
 
-	err = s3manager.NewBatchDeleteWithClient(S3Client).Delete(aws.BackgroundContext(), iter)
+	err := s3manager.NewBatchDeleteWithClient(S3Client).Delete(aws.BackgroundContext(), iter)
 	expect(err).NotTo(haveOccurred(),
 		fmt.Sprintf("Unable to delete objects from bucket %q, %v", bucketName, err))
 
@@ -3454,8 +3310,16 @@ func DeleteBucket(provider string, bucketName string) {
 		// TODO(stgleb): PTX-2359 Add DeleteAzureBucket
 		case drivers.ProviderAws:
 			DeleteS3Bucket(bucketName)
+		case backuplocation.ProviderS3ObjectLock:
+			DeleteS3BucketOfProvider(provider, bucketName)
 		case drivers.ProviderAzure:
 			DeleteAzureBucket(bucketName)
+		case drivers.ProviderGke:
+			DeleteGCPBucket(bucketName)
+		case backuplocation.ProviderNFS:
+			// Buckets of NFS are sub paths of its export, which PX-Backup manages
+		default:
+			fail(fmt.Sprintf("Deleting buckets of provider [%s] is not supported", provider))
 		}
 	})
 }
@@ -3562,6 +3426,7 @@ func HaIncreaseRebootTargetNode(event *EventRecord, ctx *scheduler.Context, v *v
 								log.Info("Waiting for 10 seconds for re-sync to initialize before target node reboot")
 								time.Sleep(10 * time.Second)
 
+								recordNodeTargets(event, newReplNode)
 								err = Inst().N.RebootNode(newReplNode, node.RebootNodeOpts{
 									Force: true,
 									ConnectionOpts: node.ConnectionOpts{
@@ -3649,6 +3514,7 @@ func HaIncreaseRebootSourceNode(event *EventRecord, ctx *scheduler.Context, v *v
 								//rebooting source nodes one by one
 								for _, nID := range replicaNodes {
 									replNodeToReboot := storageNodeMap[nID]
+									recordNodeTargets(event, replNodeToReboot)
 									err = Inst().N.RebootNode(replNodeToReboot, node.RebootNodeOpts{
 										Force: true,
 										ConnectionOpts: node.ConnectionOpts{
@@ -3730,28 +3596,30 @@ func CreateBucket(provider string, bucketName string) {
 		switch provider {
 		case drivers.ProviderAws:
 			CreateS3Bucket(bucketName, false, 0, "")
+		case backuplocation.ProviderS3ObjectLock:
+			CreateS3BucketOfProvider(provider, bucketName, false, 0, "")
 		case drivers.ProviderAzure:
 			CreateAzureBucket(bucketName)
+		case drivers.ProviderGke:
+			CreateGCPBucket(bucketName)
+		case backuplocation.ProviderNFS:
+			// Buckets of NFS are sub paths of its export, which PX-Backup manages
+		default:
+			fail(fmt.Sprintf("Creating buckets of provider [%s] is not supported", provider))
 		}
 	})
 }
 
 // CreateS3Bucket creates bucket in S3
 func CreateS3Bucket(bucketName string, objectLock bool, retainCount int64, objectLockMode string) error {
-	id, secret, endpoint, s3Region, disableSSLBool := s3utils.GetAWSDetailsFromEnv()
-	sess, err := session.NewSession(&aws.Config{
-		Endpoint:         aws.String(endpoint),
-		Credentials:      credentials.NewStaticCredentials(id, secret, ""),
-		Region:           aws.String(s3Region),
-		DisableSSL:       aws.Bool(disableSSLBool),
-		S3ForcePathStyle: aws.Bool(true),
-	},
-	)
-	expect(err).NotTo(haveOccurred(),
-		fmt.Sprintf("Failed to get S3 session to create bucket. Error: [%v]", err))
-
-	S3Client := s3.New(sess)
+	return CreateS3BucketOfProvider(drivers.ProviderAws, bucketName, objectLock, retainCount, objectLockMode)
+}
 
+// CreateS3BucketOfProvider creates bucket in the S3 compatible object store of the provider, with
+// its endpoint and credentials
+func CreateS3BucketOfProvider(provider string, bucketName string, objectLock bool, retainCount int64, objectLockMode string) error {
+	S3Client := getS3Client(provider)
+	var err error
 	if retainCount > 0 && objectLock == true {
 		// Create object locked bucket
 		_, err = S3Client.CreateBucket(&s3.CreateBucketInput{
@@ -3791,6 +3659,37 @@ func CreateS3Bucket(bucketName string, objectLock bool, retainCount int64, objec
 	return err
 }
 
+// getS3Client returns the client of the S3 compatible object store of the provider. The AWS
+// provider uses the S3 environment variables, other providers their own.
+func getS3Client(provider string) *s3.S3 {
+	id, secret, endpoint, s3Region, disableSSLBool := "", "", "", "", false
+	if provider == drivers.ProviderAws {
+		id, secret, endpoint, s3Region, disableSSLBool = s3utils.GetAWSDetailsFromEnv()
+	} else {
+		p, err := backuplocation.Get(provider)
+		expect(err).NotTo(haveOccurred(),
+			fmt.Sprintf("Failed to get backup location provider [%s]. Error: [%v]", provider, err))
+		s3Provider, ok := p.(backuplocation.S3Provider)
+		expect(ok).To(beTrue(),
+			fmt.Sprintf("Backup location provider [%s] is not an S3 provider", provider))
+		store, err := s3Provider.S3Store()
+		expect(err).NotTo(haveOccurred(),
+			fmt.Sprintf("Failed to get S3 store of provider [%s]. Error: [%v]", provider, err))
+		id, secret, endpoint, s3Region, disableSSLBool = store.AccessKeyID, store.SecretAccessKey, store.Endpoint, store.Region, store.DisableSSL
+	}
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Credentials:      credentials.NewStaticCredentials(id, secret, ""),
+		Region:           aws.String(s3Region),
+		DisableSSL:       aws.Bool(disableSSLBool),
+		S3ForcePathStyle: aws.Bool(true),
+	},
+	)
+	expect(err).NotTo(haveOccurred(),
+		fmt.Sprintf("Failed to get S3 session to create bucket. Error: [%v]", err))
+	return s3.New(sess)
+}
+
 // CreateAzureBucket creates bucket in Azure
 func CreateAzureBucket(bucketName string) {
 	// From the Azure portal, get your Storage account blob service URL endpoint.
@@ -3813,6 +3712,53 @@ func CreateAzureBucket(bucketName string) {
 		fmt.Sprintf("Failed to create container. Error: [%v]", err))
 }
 
+// getGCPClient returns the Google cloud storage client of the GCP provider and its project
+func getGCPClient(ctx context1.Context) (*gcs.Client, string) {
+	p, err := backuplocation.Get(drivers.ProviderGke)
+	expect(err).NotTo(haveOccurred(),
+		fmt.Sprintf("Failed to get backup location provider [%s]. Error: [%v]", drivers.ProviderGke, err))
+	store, err := p.(backuplocation.GoogleProvider).GoogleStore()
+	expect(err).NotTo(haveOccurred(),
+		fmt.Sprintf("Failed to get Google store of provider [%s]. Error: [%v]", drivers.ProviderGke, err))
+	client, err := gcs.NewClient(ctx, option.WithCredentialsJSON([]byte(store.AccountKey)))
+	expect(err).NotTo(haveOccurred(),
+		fmt.Sprintf("Failed to get Google cloud storage client. Error: [%v]", err))
+	return client, store.ProjectID
+}
+
+// CreateGCPBucket creates bucket in Google cloud storage
+func CreateGCPBucket(bucketName string) {
+	ctx := context1.Background()
+	client, projectID := getGCPClient(ctx)
+	defer client.Close()
+	err := client.Bucket(bucketName).Create(ctx, projectID, nil)
+	expect(err).NotTo(haveOccurred(),
+		fmt.Sprintf("Failed to create bucket [%v]. Error: [%v]", bucketName, err))
+}
+
+// DeleteGCPBucket deletes bucket in Google cloud storage with its objects
+func DeleteGCPBucket(bucketName string) {
+	ctx := context1.Background()
+	client, _ := getGCPClient(ctx)
+	defer client.Close()
+	bucket := client.Bucket(bucketName)
+	it := bucket.Objects(ctx, nil)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		expect(err).NotTo(haveOccurred(),
+			fmt.Sprintf("Unable to list objects of bucket %q, %v", bucketName, err))
+		err = bucket.Object(attrs.Name).Delete(ctx)
+		expect(err).NotTo(haveOccurred(),
+			fmt.Sprintf("Unable to delete object %q of bucket %q, %v", attrs.Name, bucketName, err))
+	}
+	err := bucket.Delete(ctx)
+	expect(err).NotTo(haveOccurred(),
+		fmt.Sprintf("Failed to delete bucket [%v]. Error: [%v]", bucketName, err))
+}
+
 func dumpKubeConfigs(configObject string, kubeconfigList []string) error {
 	log.Infof("dump kubeconfigs to file system")
 	cm, err := core.Instance().GetConfigMap(configObject, "default")
@@ -3896,6 +3842,9 @@ type Torpedo struct {
 	CsiGenericDriverConfigMap           string
 	HelmValuesConfigMap                 string
 	IsHyperConverged                    bool
+	LongevityScenario                   string
+	Seed                                int64
+	ReplayJournal                       string
 	Dash                                *aetosutil.Dashboard
 	JobName                             string
 	JobType                             string
@@ -3927,7 +3876,11 @@ func ParseFlags() {
 	var bundleLocation string
 	var customConfigPath string
 	var hyperConverged bool
+	var longevityScenario string
+	var seed int64
+	var replayJournal string
 	var enableDash bool
+	var reportDir string
 	var pxPodRestartCheck bool
 
 	// TODO: We rely on the customAppConfig map to be passed into k8s.go and stored there.
@@ -4002,9 +3955,15 @@ func ParseFlags() {
 	flag.StringVar(&testRailPassword, testRailPasswordFlag, "", "Password to be used for testrail update")
 	flag.StringVar(&jiraUserName, jiraUserNameFlag, "", "Username to be used for JIRA client")
 	flag.StringVar(&jiraToken, jiraTokenFlag, "", "API token for accessing the JIRA")
+	flag.StringVar(&resultPublishers, resultPublishersFlag, strings.Join([]string{aetos.Name, testrail.Name, jira.Name}, ","), "Comma separated list of the publishers to publish test results to")
+	flag.StringVar(&resultPublisherParams, resultPublisherParamsFlag, "", "Comma separated list of publisher.key=value parameters of the result publishers. Eg: testrail.milestone=2.13")
 	flag.StringVar(&jirautils.AccountID, jiraAccountIDFlag, "", "AccountID for issue assignment")
 	flag.BoolVar(&hyperConverged, hyperConvergedFlag, true, "To enable/disable hyper-converged type of deployment")
+	flag.StringVar(&longevityScenario, longevityScenarioFlag, "", "Path to a longevity scenario file. When set, it drives the longevity test instead of the longevity-triggers ConfigMap")
+	flag.Int64Var(&seed, seedFlag, 0, "Seed of the random choices made by test triggers. A random seed is used when not set")
+	flag.StringVar(&replayJournal, replayJournalFlag, "", "Path to the replay journal of a previous longevity run. When set, the longevity test re-executes its events in the same order")
 	flag.BoolVar(&enableDash, enableDashBoardFlag, true, "To enable/disable aetos dashboard reporting")
+	flag.StringVar(&reportDir, reportDirFlag, "", "Directory to write JUnit XML and JSON reports of the test results to, whether the aetos dashboard is enabled or not")
 	flag.StringVar(&user, userFlag, "nouser", "user name running the tests")
 	flag.StringVar(&testDescription, testDescriptionFlag, "Torpedo Workflows", "test suite description")
 	flag.StringVar(&testType, testTypeFlag, "system-test", "test types like system-test,functional,integration")
@@ -4104,6 +4063,21 @@ func ParseFlags() {
 		}
 
 		dash.IsEnabled = enableDash
+		if reportDir != "" {
+			reporter, err := aetosutil.NewFileReporter(reportDir)
+			if err != nil {
+				log.Errorf("Failed to create report directory, reports will not be written. Err: %v", err)
+			} else {
+				dash.AddReporter(reporter)
+				log.Infof("Writing test reports to %s", reportDir)
+			}
+		}
+		// result trackers are set up in BeforeSuite by SetupResultPublishers, only the dashboard here
+		if isResultPublisherSelected(aetos.Name) {
+			if err := publisher.Enable(aetos.Name, nil); err != nil {
+				log.Errorf("Failed to enable result publisher [%s]: %v", aetos.Name, err)
+			}
+		}
 		testSet := aetosutil.TestSet{
 			User:        user,
 			Product:     testProduct,
@@ -4174,6 +4148,10 @@ func ParseFlags() {
 
 		dash.TestSet = &testSet
 
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
 		once.Do(func() {
 			instance = &Torpedo{
 				InstanceID:                          time.Now().Format("01-02-15h04m05s"),
@@ -4215,6 +4193,9 @@ func ParseFlags() {
 				LicenseExpiryTimeoutHours:           licenseExpiryTimeoutHours,
 				MeteringIntervalMins:                meteringIntervalMins,
 				IsHyperConverged:                    hyperConverged,
+				LongevityScenario:                   longevityScenario,
+				Seed:                                seed,
+				ReplayJournal:                       replayJournal,
 				Dash:                                dash,
 				JobName:                             torpedoJobName,
 				JobType:                             torpedoJobType,
@@ -4222,6 +4203,8 @@ func ParseFlags() {
 			}
 		})
 	}
+	TriggerRand = replay.NewSource(seed)
+	log.Infof("Using random seed %d. Pass --%s=%d to make the same random choices again", seed, seedFlag, seed)
 	printFlags()
 }
 
@@ -4344,8 +4327,7 @@ func init() {
 
 // CreateJiraIssueWithLogs creates a jira issue and copy logs to nfs mount
 func CreateJiraIssueWithLogs(issueDescription, issueSummary string) {
-	issueKey, err := jirautils.CreateIssue(issueDescription, issueSummary)
-	if err == nil && issueKey != "" {
+	for _, issueKey := range publisher.CreateIssue(&publisher.Issue{Summary: issueSummary, Description: issueDescription}) {
 		collectAndCopyDiagsOnWorkerNodes(issueKey)
 		collectAndCopyStorkLogs(issueKey)
 		collectAndCopyOperatorLogs(issueKey)
@@ -4944,13 +4926,30 @@ func StartTorpedoTest(testName, testDescription string, tags map[string]string,
 	tags["storageProvisioner"] = Inst().Provisioner
 	tags["pureVolume"] = fmt.Sprintf("%t", Inst().PureVolumes)
 	tags["pureSANType"] = Inst().PureSANType
-	dash.TestCaseBegin(testName, testDescription, strconv.Itoa(testRepoID), tags)
+	currentTestCase = &publisher.TestCase{
+		Name:        testName,
+		Description: testDescription,
+		TestRepoID:  testRepoID,
+		Tags:        tags,
+	}
+	publisher.TestCaseBegin(currentTestCase)
 	if TestRailSetupSuccessful && testRepoID != 0 {
-		RunIdForSuite = testrailuttils.AddRunsToMilestone(testRepoID)
+		RunIdForSuite = currentTestCase.RunID
 		CurrentTestRailTestCaseId = testRepoID
 	}
 }
 
+// endTestCase notifies the result publishers that the current test case ends and returns it
+func endTestCase() *publisher.TestCase {
+	tc := currentTestCase
+	if tc == nil {
+		tc = &publisher.TestCase{}
+	}
+	currentTestCase = nil
+	publisher.TestCaseEnd(tc)
+	return tc
+}
+
 // enableAutoFSTrim on supported PX version.
 func EnableAutoFSTrim() {
 	nodes := node.GetWorkerNodes()
@@ -4987,15 +4986,15 @@ func EnableAutoFSTrim() {
 // EndTorpedoTest ends the logging for torpedo test
 func EndTorpedoTest() {
 	CloseLogger(TestLogger)
-	dash.TestCaseEnd()
+	endTestCase()
 }
 
 // EndPxBackupTorpedoTest ends the logging for Px Backup torpedo test and updates results in testrail
 func EndPxBackupTorpedoTest(contexts []*scheduler.Context) {
 	CloseLogger(TestLogger)
-	dash.TestCaseEnd()
-	if TestRailSetupSuccessful && CurrentTestRailTestCaseId != 0 && RunIdForSuite != 0 {
-		AfterEachTest(contexts, CurrentTestRailTestCaseId, RunIdForSuite)
+	// the run ID is only set when the test case was added to a test run
+	if tc := endTestCase(); tc.TestRepoID != 0 && tc.RunID != 0 {
+		AfterEachTest(contexts, tc.TestRepoID, tc.RunID)
 	}
 	ginkgoTestDescr := ginkgo.CurrentGinkgoTestDescription()
 	if ginkgoTestDescr.Failed {
@@ -5193,9 +5192,10 @@ func GetRandomNodeWithPoolIOs(contexts []*scheduler.Context) (node.Node, error)
 	return *n, err
 }
 
-func GetRandomStorageLessNode(slNodes []node.Node) node.Node {
-	// pick a random storageless node
-	randomIndex := rand.Intn(len(slNodes))
+// GetRandomStorageLessNode picks a random storageless node, drawn from the stream of the given
+// trigger or test
+func GetRandomStorageLessNode(stream string, slNodes []node.Node) node.Node {
+	randomIndex := TriggerRand.Intn(stream, len(slNodes))
 	for _, slNode := range slNodes {
 		if randomIndex == 0 {
 			return slNode
@@ -5409,6 +5409,7 @@ func IsEksCluster() bool {
  * If length is zero or negative or greater than the length of the input slice, it also returns an error.
  *
  * Parameters:
+ * - stream: the trigger or test whose stream of TriggerRand the items are drawn from.
  * - items: a slice of any type to select from.
  * - length: the number of items to select from the input slice.
  *
@@ -5416,7 +5417,7 @@ func IsEksCluster() bool {
  * - a new slice of type T with the selected items in random order.
  * - an error if the length parameter is zero or negative, or if it is greater than the length of the input slice.
  */
-func GetSubsetOfSlice[T any](items []T, length int) ([]T, error) {
+func GetSubsetOfSlice[T any](stream string, items []T, length int) ([]T, error) {
 	if length <= 0 {
 		return nil, fmt.Errorf("length must be greater than zero")
 	}
@@ -5424,13 +5425,7 @@ func GetSubsetOfSlice[T any](items []T, length int) ([]T, error) {
 		return nil, fmt.Errorf("length cannot be greater than the length of the input items")
 	}
 	randomItems := make([]T, length)
-	selected := make(map[int]bool)
-	for i := 0; i < length; i++ {
-		j := rand.Intn(len(items))
-		for selected[j] {
-			j = rand.Intn(len(items))
-		}
-		selected[j] = true
+	for i, j := range TriggerRand.Perm(stream, len(items))[:length] {
 		randomItems[i] = items[j]
 	}
 	return randomItems, nil
@@ -5853,21 +5848,74 @@ outer:
 	return "", fmt.Errorf("no pool with metadata in node [%s]", stNode.Name)
 }
 
-// SetupTestRail checks if the required parameters for testrail are passed, verifies connectivity and creates milestone if it does not exist
-func SetupTestRail() {
-	if testRailHostname != "" && testRailUsername != "" && testRailPassword != "" {
-		err := testrailuttils.Init(testRailHostname, testRailUsername, testRailPassword)
-		if err == nil {
-			if testrailuttils.MilestoneName == "" || testrailuttils.RunName == "" || testrailuttils.JobRunID == "" {
-				err = fmt.Errorf("not all details provided to update testrail")
-				log.FailOnError(err, "Error occurred while testrail initialization")
+// SetupResultPublishers enables the result publishers selected by the result-publishers flag. It
+// is called in BeforeSuite, as publishers connect to their trackers. Publishers whose parameters
+// are not provided or whose tracker cannot be reached are skipped, and misconfigured ones fail the suite.
+func SetupResultPublishers() {
+	params, err := publisher.ParseParams(resultPublisherParams)
+	log.FailOnError(err, "Error occurred while parsing result publisher parameters")
+	// the tracker flags are the defaults of the parameters of the built-in publishers
+	for name, defaults := range map[string]map[string]string{
+		testrail.Name: {
+			testrail.HostnameParam:        testRailHostname,
+			testrail.UsernameParam:        testRailUsername,
+			testrail.PasswordParam:        testRailPassword,
+			testrail.MilestoneParam:       testrailuttils.MilestoneName,
+			testrail.RunNameParam:         testrailuttils.RunName,
+			testrail.JobRunIDParam:        testrailuttils.JobRunID,
+			testrail.JenkinsBuildURLParam: testrailuttils.JenkinsBuildURL,
+		},
+		jira.Name: {
+			jira.UsernameParam:  jiraUserName,
+			jira.TokenParam:     jiraToken,
+			jira.AccountIDParam: jirautils.AccountID,
+		},
+	} {
+		if params[name] == nil {
+			params[name] = make(map[string]string)
+		}
+		for k, v := range defaults {
+			if _, ok := params[name][k]; !ok {
+				params[name][k] = v
 			}
-			testrailuttils.CreateMilestone()
-			TestRailSetupSuccessful = true
 		}
-	} else {
-		log.Debugf("Not all information to connect to testrail is provided, skipping updates to testrail")
 	}
+
+	for _, name := range strings.Split(resultPublishers, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		err := publisher.Enable(name, params[name])
+		switch err.(type) {
+		case nil:
+			log.Infof("Publishing results to [%s]", name)
+		case *publisher.ErrNotConfigured:
+			log.Debugf("Not all information to publish results to [%s] is provided, skipping updates: %v", name, err)
+		case *publisher.ErrUnavailable:
+			log.Errorf("Failed to connect to [%s], skipping updates: %v", name, err)
+		default:
+			log.FailOnError(err, "Error occurred while enabling result publisher [%s]", name)
+		}
+	}
+	TestRailSetupSuccessful = publisher.IsEnabled(testrail.Name)
+}
+
+// SetupTestRail sets up the result publishers, TestRail among them.
+//
+// Deprecated: use SetupResultPublishers.
+func SetupTestRail() {
+	SetupResultPublishers()
+}
+
+// isResultPublisherSelected returns whether the publisher is selected by the result-publishers flag
+func isResultPublisherSelected(name string) bool {
+	for _, selected := range strings.Split(resultPublishers, ",") {
+		if strings.TrimSpace(selected) == name {
+			return true
+		}
+	}
+	return false
 }
 
 // AsgKillNode terminates the given node
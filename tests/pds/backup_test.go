@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	pds "github.com/portworx/pds-api-go-client/pds/v1alpha1"
+	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
+	torpedoerrors "github.com/portworx/torpedo/pkg/errors"
+	"github.com/portworx/torpedo/pkg/log"
+	. "github.com/portworx/torpedo/tests"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	backupPolicySchedule  = "*/15 * * * *"
+	backupPolicyRetention = 3
+	workloadRunTime       = 2 * time.Minute
+)
+
+var _ = Describe("{BackupAndRestoreDataServices}", func() {
+	var (
+		backupCredential *pds.ModelsBackupCredentials
+		backupTarget     *pds.ModelsBackupTarget
+		backupPolicy     *pds.ModelsBackupPolicy
+	)
+
+	JustBeforeEach(func() {
+		StartTorpedoTest("PDS: BackupAndRestoreDataServices", "Back up data services ad-hoc and by policy, restore them to new deployments and verify their data", pdsLabels, 0)
+	})
+
+	It("has to back up data services, restore them to new deployments and verify the restored data", func() {
+		Step("Create the backup target and backup policy", func() {
+			config, err := pdslib.GetBackupTargetConfig(fmt.Sprintf("pds-qa-%s", pdslib.GetRandomString(6)))
+			log.FailOnError(err, "Error while getting the backup target config")
+			backupCredential, backupTarget, err = pdslib.CreateS3BackupTarget(tenantID, deploymentTargetID, config)
+			log.FailOnError(err, "Error while creating the backup target")
+			backupPolicy, err = pdslib.CreateBackupPolicy(tenantID, config.Name, backupPolicySchedule, backupPolicyRetention)
+			log.FailOnError(err, "Error while creating the backup policy")
+		})
+
+		for _, ds := range params.DataServiceToTest {
+			var sourceRows map[string]int64
+			Step("Deploy and validate data service", func() {
+				isDeploymentsDeleted = false
+				deployment, _, _, err = DeployandValidateDataServices(ds, namespace, tenantID, projectID)
+				log.FailOnError(err, "Error while deploying data services")
+			})
+
+			Step("Write data with the workload generator", func() {
+				writeWorkloadData(ds, deployment)
+				sourceRows = getDeploymentRowCounts(ds, deployment)
+			})
+
+			Step("Take an adhoc backup and restore it", func() {
+				backup, jobs, err := pdslib.CreateAdhocBackup(deployment.GetId(), backupTarget.GetId())
+				log.FailOnError(err, "Error while taking adhoc backup of deployment %v", deployment.GetName())
+				restoreAndVerify(ds, backup.GetId(), jobs[len(jobs)-1].GetName(), sourceRows)
+				err = pdslib.DeleteBackup(backup.GetId())
+				log.FailOnError(err, "Error while deleting adhoc backup %v", backup.GetId())
+			})
+
+			Step("Attach the backup policy and restore a scheduled backup", func() {
+				backup, err := pdslib.AttachBackupPolicy(deployment.GetId(), backupPolicy.GetId(), backupTarget.GetId())
+				log.FailOnError(err, "Error while attaching backup policy to deployment %v", deployment.GetName())
+				jobs, err := pdslib.WaitForBackupJobs(backup.GetId(), 1)
+				log.FailOnError(err, "Error while waiting for scheduled backup jobs of deployment %v", deployment.GetName())
+				restoreAndVerify(ds, backup.GetId(), jobs[len(jobs)-1].GetName(), sourceRows)
+				err = pdslib.DetachBackupPolicy(deployment.GetId())
+				log.FailOnError(err, "Error while detaching backup policy from deployment %v", deployment.GetName())
+				err = pdslib.DeleteBackup(backup.GetId())
+				log.FailOnError(err, "Error while deleting scheduled backup %v", backup.GetId())
+			})
+
+			Step("Delete the deployment", func() {
+				deleteDeployment(deployment)
+				isDeploymentsDeleted = true
+			})
+		}
+	})
+
+	JustAfterEach(func() {
+		defer EndTorpedoTest()
+
+		defer func() {
+			if backupPolicy != nil {
+				err := pdslib.DeleteBackupPolicy(backupPolicy.GetId())
+				log.FailOnError(err, "Error while deleting backup policy")
+			}
+			err := pdslib.DeleteS3BackupTarget(backupCredential, backupTarget)
+			log.FailOnError(err, "Error while deleting backup target")
+		}()
+
+		if !isDeploymentsDeleted && deployment != nil {
+			Step("Delete created deployments")
+			deleteDeployment(deployment)
+		}
+	})
+})
+
+// restoreAndVerify restores the backup job to a new deployment, verifies the restored deployment has the rows
+// of its source and writes to it
+func restoreAndVerify(ds PDSDataService, backupID, jobName string, sourceRows map[string]int64) {
+	name := fmt.Sprintf("%s-restored-%s", deploymentName, pdslib.GetRandomString(4))
+	restored, err := pdslib.RestoreBackup(backupID, jobName, name, deploymentTargetID, namespaceID, namespace)
+	if restored != nil {
+		defer deleteDeployment(restored)
+	}
+	log.FailOnError(err, "Error while restoring backup job %v", jobName)
+
+	if sourceRows != nil {
+		restoredRows := getDeploymentRowCounts(ds, restored)
+		dash.VerifyFatal(restoredRows, sourceRows,
+			fmt.Sprintf("Validating restored deployment %v has the rows of its source", restored.GetName()))
+	}
+
+	writeWorkloadData(ds, restored)
+}
+
+// writeWorkloadData runs the workload generator of the data service on the deployment for workloadRunTime
+// and deletes it. Data services without a workload generator are left as is.
+func writeWorkloadData(ds PDSDataService, deployment *pds.ModelsDeployment) {
+	if !Contains(dataServiceDeploymentWorkloads, ds.Name) && !Contains(dataServicePodWorkloads, ds.Name) {
+		log.Warnf("Data service %v has no workload generator", ds.Name)
+		return
+	}
+	var params pdslib.WorkloadGenerationParams
+	var workloadPod *corev1.Pod
+	var workloadDep *v1.Deployment
+	workloadPod, workloadDep, err = RunWorkloads(params, ds, deployment, namespace)
+	log.FailOnError(err, "Error while generating workloads for dataservice [%s]", ds.Name)
+	log.InfoD("Running workloads on deployment %v for %v", deployment.GetName(), workloadRunTime)
+	time.Sleep(workloadRunTime)
+	err = pdslib.ValidateDataServiceDeployment(deployment, namespace)
+	log.FailOnError(err, "Error while validating deployment %v with workloads", deployment.GetName())
+	if workloadDep != nil {
+		err = pdslib.DeleteK8sDeployments(workloadDep.Name, namespace)
+	} else if workloadPod != nil {
+		err = pdslib.DeleteK8sPods(workloadPod.Name, namespace)
+	}
+	log.FailOnError(err, "error deleting workload generating pods")
+}
+
+// getDeploymentRowCounts returns the rows of the tables of the deployment written by the workload generator.
+// Data services without a row count return nil.
+func getDeploymentRowCounts(ds PDSDataService, deployment *pds.ModelsDeployment) map[string]int64 {
+	rows, err := pdslib.GetDeploymentRowCounts(deployment, ds.Name, namespace)
+	var notFound *torpedoerrors.ErrNotFound
+	if errors.As(err, &notFound) {
+		log.Warnf("Data service %v has no row count, its restored data is not verified", ds.Name)
+		return nil
+	}
+	log.FailOnError(err, "Error while counting rows of deployment %v", deployment.GetName())
+	log.InfoD("Deployment %v has rows %v", deployment.GetName(), rows)
+	return rows
+}
+
+// deleteDeployment deletes the deployment and validates the response
+func deleteDeployment(deployment *pds.ModelsDeployment) {
+	resp, err := pdslib.DeleteDeployment(deployment.GetId())
+	log.FailOnError(err, "Error while deleting deployment %v", deployment.GetName())
+	dash.VerifyFatal(resp.StatusCode, http.StatusAccepted, "validating the status response")
+}
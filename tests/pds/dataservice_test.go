@@ -16,6 +16,7 @@ import (
 	"github.com/portworx/torpedo/drivers/node"
 	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
 	"github.com/portworx/torpedo/pkg/log"
+	"github.com/portworx/torpedo/pkg/pdsmatrix"
 	. "github.com/portworx/torpedo/tests"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -1387,3 +1388,87 @@ var _ = Describe("{AddAndValidateUserRoles}", func() {
 		EndTorpedoTest()
 	})
 })
+
+var _ = Describe("{DataServiceMatrix}", func() {
+	var (
+		matrixBackupCredential *pds.ModelsBackupCredentials
+		matrixBackupTarget     *pds.ModelsBackupTarget
+	)
+
+	JustBeforeEach(func() {
+		StartTorpedoTest("PDS: DataServiceMatrix", "Run the data services of the params through the matrix of templates and operations", pdsLabels, 0)
+	})
+
+	It("has to run every cell of the data service matrix and report the outcome of each cell", func() {
+		var matrix *pdsmatrix.Matrix
+		Step("Build the data service matrix from the params", func() {
+			matrix, err = pdsmatrix.FromParameters(params)
+			log.FailOnError(err, "Error while building the data service matrix")
+			log.InfoD("Data service matrix has %d cells", len(matrix.Cells()))
+		})
+
+		Step("Create the backup target of the backup and restore cells", func() {
+			config, err := pdslib.GetBackupTargetConfig(fmt.Sprintf("pds-matrix-%s", pdslib.GetRandomString(6)))
+			if err != nil {
+				log.Warnf("Backup and restore cells are skipped: %v", err)
+				return
+			}
+			matrixBackupCredential, matrixBackupTarget, err = pdslib.CreateS3BackupTarget(tenantID, deploymentTargetID, config)
+			log.FailOnError(err, "Error while creating the backup target")
+		})
+
+		Step("Run the cells of the data service matrix", func() {
+			engine := &pdsmatrix.Engine{
+				Runner: &pdsmatrix.LibRunner{
+					TenantID:           tenantID,
+					ProjectID:          projectID,
+					DeploymentTargetID: deploymentTargetID,
+					DNSZone:            dnsZone,
+					ServiceType:        serviceType,
+					Namespace:          namespace,
+					NamespaceID:        namespaceID,
+					DeploymentName:     deploymentName,
+					NodeDisruption:     rebootWorkerNodes,
+					BackupTargetID:     matrixBackupTarget.GetId(),
+				},
+			}
+			report, err := engine.Run(matrix)
+			log.FailOnError(err, "Error while running the data service matrix")
+			log.InfoD("Data service matrix report:\n%s", report)
+			dash.VerifyFatal(report.Err(), nil, "Validating all cells of the data service matrix passed")
+		})
+	})
+
+	JustAfterEach(func() {
+		defer EndTorpedoTest()
+
+		err := pdslib.DeleteS3BackupTarget(matrixBackupCredential, matrixBackupTarget)
+		log.FailOnError(err, "Error while deleting backup target")
+	})
+})
+
+// rebootWorkerNodes reboots the worker nodes in rolling fashion and waits for each one to be back up
+func rebootWorkerNodes(deployment *pds.ModelsDeployment) error {
+	for _, n := range node.GetWorkerNodes() {
+		log.InfoD("reboot node: %s", n.Name)
+		err := Inst().N.RebootNode(n, node.RebootNodeOpts{
+			Force: true,
+			ConnectionOpts: node.ConnectionOpts{
+				Timeout:         defaultCommandTimeout,
+				TimeBeforeRetry: defaultCommandRetry,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reboot node %s: %v", n.Name, err)
+		}
+		log.Infof("wait for node: %s to be back up", n.Name)
+		err = Inst().N.TestConnection(n, node.ConnectionOpts{
+			Timeout:         defaultTestConnectionTimeout,
+			TimeBeforeRetry: defaultWaitRebootRetry,
+		})
+		if err != nil {
+			return fmt.Errorf("node %s is not back up: %v", n.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	pdslib "github.com/portworx/torpedo/drivers/pds/lib"
+	torpedoerrors "github.com/portworx/torpedo/pkg/errors"
+	"github.com/portworx/torpedo/pkg/log"
+	"github.com/portworx/torpedo/pkg/pdsworkload"
+	. "github.com/portworx/torpedo/tests"
+)
+
+const (
+	chaosWorkloadDuration   = 20 * time.Minute
+	chaosWorkloadMinOps     = 1
+	chaosWorkloadMaxErrors  = 100
+	chaosWorkloadWaitBuffer = 10 * time.Minute
+)
+
+var _ = Describe("{WorkloadThroughputDuringRollingReboot}", func() {
+	JustBeforeEach(func() {
+		StartTorpedoTest("PDS: WorkloadThroughputDuringRollingReboot", "Run workloads on the data services while nodes reboot and verify their throughput", pdsLabels, 0)
+	})
+
+	It("has to sustain the throughput of the workloads of the data services while nodes reboot", func() {
+		for _, ds := range params.DataServiceToTest {
+			var workload pdsworkload.Workload
+			Step("Deploy and validate data service", func() {
+				isDeploymentsDeleted = false
+				deployment, _, _, err = DeployandValidateDataServices(ds, namespace, tenantID, projectID)
+				log.FailOnError(err, "Error while deploying data services")
+			})
+
+			Step("Start the workload of the data service", func() {
+				workload, err = pdsworkload.Get(pdsworkload.Spec{
+					DataService: ds.Name,
+					Deployment:  deployment,
+					Namespace:   namespace,
+					Duration:    chaosWorkloadDuration,
+				})
+				var notFound *torpedoerrors.ErrNotFound
+				if errors.As(err, &notFound) {
+					log.Warnf("Data service %v has no workload, rebooting nodes without one", ds.Name)
+					return
+				}
+				log.FailOnError(err, "Error while getting the workload of data service %v", ds.Name)
+				err = workload.Start()
+				log.FailOnError(err, "Error while starting workload %v", workload.Name())
+			})
+
+			Step("Reboot nodes", func() {
+				err = rebootWorkerNodes(deployment)
+				log.FailOnError(err, "Error while rebooting nodes")
+				err = pdslib.ValidateDataServiceDeployment(deployment, namespace)
+				log.FailOnError(err, "Error while validating deployment after nodes are up")
+			})
+
+			if workload != nil {
+				Step("Verify the throughput of the workload", func() {
+					// The workload pod may be on a rebooted node, its output is verified regardless
+					if err = workload.Wait(chaosWorkloadDuration + chaosWorkloadWaitBuffer); err != nil {
+						log.Warnf("Workload %v did not complete: %v", workload.Name(), err)
+					}
+					err = workload.Stop()
+					log.FailOnError(err, "Error while stopping workload %v", workload.Name())
+					result, err := workload.Result()
+					log.FailOnError(err, "Error while getting result of workload %v", workload.Name())
+					log.InfoD("Workload %v on data service %v: %v", workload.Name(), ds.Name, result)
+					err = result.Verify(pdsworkload.Criteria{MinOpsPerSec: chaosWorkloadMinOps, MaxErrors: chaosWorkloadMaxErrors})
+					dash.VerifyFatal(err, nil, fmt.Sprintf("Validating throughput of workload %v during node reboots", workload.Name()))
+				})
+			}
+
+			Step("Delete the deployment", func() {
+				deleteDeployment(deployment)
+				isDeploymentsDeleted = true
+			})
+		}
+	})
+
+	JustAfterEach(func() {
+		defer EndTorpedoTest()
+
+		if !isDeploymentsDeleted && deployment != nil {
+			Step("Delete created deployments")
+			deleteDeployment(deployment)
+		}
+	})
+})
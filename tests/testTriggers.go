@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"math"
-	"math/rand"
 	"os"
 	"os/exec"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
@@ -17,7 +17,12 @@ import (
 	apapi "github.com/libopenstorage/autopilot-api/pkg/apis/autopilot/v1alpha1"
 	"github.com/portworx/torpedo/pkg/applicationbackup"
 	"github.com/portworx/torpedo/pkg/aututils"
+	"github.com/portworx/torpedo/pkg/claims"
+	"github.com/portworx/torpedo/pkg/eventlog"
 	"github.com/portworx/torpedo/pkg/log"
+	"github.com/portworx/torpedo/pkg/notification"
+	"github.com/portworx/torpedo/pkg/publisher"
+	"github.com/portworx/torpedo/pkg/replay"
 	"github.com/portworx/torpedo/pkg/units"
 	"gopkg.in/natefinch/lumberjack.v2"
 
@@ -69,6 +74,9 @@ const (
 	EmailHostServerField = "emailHostServer"
 	// EmailSubjectFiled is field in configmap which stores the subject(optional)
 	EmailSubjectField = "emailSubject"
+	// NotificationSinksField is field in configmap which stores a YAML list of notification
+	// sinks, such as webhooks, receiving the events of the triggers (optional)
+	NotificationSinksField = "notificationSinks"
 )
 
 const (
@@ -110,6 +118,9 @@ const (
 
 var longevityLogger *lumberjack.Logger
 
+// longevityTestCase is the test case started by startLongevityTest
+var longevityTestCase *publisher.TestCase
+
 // EmailRecipients list of email IDs to send email to
 var EmailRecipients []string
 
@@ -125,6 +136,24 @@ var RunningTriggers map[string]time.Duration
 // ChaosMap stores mapping between test trigger and its chaos level.
 var ChaosMap map[string]int
 
+// TriggerParams stores the parameters of each test trigger declared in the longevity scenario
+var TriggerParams map[string]map[string]string
+
+// TriggerClaims keeps track of the resources claimed by running test triggers
+var TriggerClaims = claims.NewManager()
+
+// TriggerRand is the seeded source of all random choices made by test triggers
+var TriggerRand = replay.NewSource(time.Now().UnixNano())
+
+// EventJournal records the events of a longevity run so that it can be replayed
+var EventJournal *replay.Journal
+
+// EventLog records the events of a longevity run for post-processing
+var EventLog *eventlog.Writer
+
+// Notifiers sends the events of a longevity run to the configured notification sinks
+var Notifiers = notification.NewDispatcher("")
+
 // coresMap stores mapping between node name and cores generated.
 var coresMap map[string]string
 
@@ -199,6 +228,82 @@ type EventRecord struct {
 	Start   string
 	End     string
 	Outcome []error
+	// Targets of the trigger, recorded in the replay journal and the event log
+	Targets []eventlog.Target
+	// Phases are the steps taken by the trigger, recorded in the event log
+	Phases []eventlog.Phase
+	// Errors are the classified errors of Outcome, recorded in the event log
+	Errors []eventlog.Error
+}
+
+// eventRecordLock guards the outcome, targets, phases and errors of event records, which triggers
+// update from several goroutines
+var eventRecordLock sync.Mutex
+
+// TargetNames returns the targets of the event as kind/name
+func (e *EventRecord) TargetNames() []string {
+	eventRecordLock.Lock()
+	defer eventRecordLock.Unlock()
+	names := make([]string, 0, len(e.Targets))
+	for _, t := range e.Targets {
+		names = append(names, t.String())
+	}
+	return names
+}
+
+func (e *EventRecord) startPhase(name string) int {
+	eventRecordLock.Lock()
+	defer eventRecordLock.Unlock()
+	e.Phases = append(e.Phases, eventlog.Phase{
+		Name:  name,
+		Kind:  eventlog.PhaseKindOf(name),
+		Start: time.Now(),
+	})
+	return len(e.Phases) - 1
+}
+
+func (e *EventRecord) endPhase(i int) {
+	eventRecordLock.Lock()
+	defer eventRecordLock.Unlock()
+	e.Phases[i].End = time.Now()
+}
+
+func (e *EventRecord) recordOutcome(err error) {
+	eventRecordLock.Lock()
+	defer eventRecordLock.Unlock()
+	e.Outcome = append(e.Outcome, err)
+}
+
+func (e *EventRecord) recordError(err error) {
+	eventRecordLock.Lock()
+	defer eventRecordLock.Unlock()
+	eventErr := eventlog.Error{
+		Message: err.Error(),
+		Class:   eventlog.Classify(err),
+	}
+	// errors belong to the innermost phase which is still running
+	for i := len(e.Phases) - 1; i >= 0; i-- {
+		if e.Phases[i].End.IsZero() {
+			e.Phases[i].Failed = true
+			eventErr.Phase = e.Phases[i].Name
+			if eventErr.Class == eventlog.ClassUnknown && e.Phases[i].Kind == eventlog.PhaseValidation {
+				eventErr.Class = eventlog.ClassValidation
+			}
+			break
+		}
+	}
+	e.Errors = append(e.Errors, eventErr)
+}
+
+// eventStep runs a step of a trigger and records it as a phase of the event
+func eventStep(event *EventRecord, text string, callbacks ...func()) {
+	if len(callbacks) == 0 {
+		Step(text)
+		return
+	}
+	i := event.startPhase(text)
+	defer event.endPhase(i)
+	Step(text, callbacks...)
 }
 
 // eventRing is circular buffer to store
@@ -274,7 +379,8 @@ func UpdateOutcome(event *EventRecord, err error) {
 		dash.VerifySafely(err, nil, fmt.Sprintf("verify if error occured for event %s", event.Event.Type))
 		er := fmt.Errorf(err.Error() + "<br>")
 		Inst().M.IncrementGaugeMetricsUsingAdditionalLabel(FailedTestAlert, event.Event.Type, err.Error())
-		event.Outcome = append(event.Outcome, er)
+		event.recordOutcome(er)
+		event.recordError(err)
 		createLongevityJiraIssue(event, er)
 	}
 }
@@ -387,6 +493,8 @@ const (
 	AsyncDR = "asyncdr"
 	// AsyncDR Volume Only runs Async DR volume only migration between two clusters
 	AsyncDRVolumeOnly = "asyncdrvolumeonly"
+	// AsyncDRFailoverFailback runs Async DR failover and failback between two clusters
+	AsyncDRFailoverFailback = "asyncdrfailoverfailback"
 	// stork application backup runs stork backups for applications
 	StorkApplicationBackup = "storkapplicationbackup"
 	// stork application backup volume resize runs stork backups for applications and inject volume resize in between
@@ -433,7 +541,7 @@ func TriggerCoreChecker(contexts *[]*scheduler.Context, recordChan *chan *EventR
 	setMetrics(*event)
 
 	context("checking for core files...", func() {
-		Step("verifying if core files are present on each node", func() {
+		eventStep(event, "verifying if core files are present on each node", func() {
 			log.InfoD("verifying if core files are present on each node")
 			nodes := node.GetWorkerNodes()
 			dash.VerifyFatal(len(nodes) > 0, true, "Nodes registered?")
@@ -466,10 +574,17 @@ func TriggerCoreChecker(contexts *[]*scheduler.Context, recordChan *chan *EventR
 func startLongevityTest(testName string) {
 	longevityLogger = CreateLogger(fmt.Sprintf("%s-%s.log", testName, time.Now().Format(time.RFC3339)))
 	log.SetTorpedoFileOutput(longevityLogger)
-	dash.TestCaseBegin(testName, fmt.Sprintf("validating %s in longevity cluster", testName), "", nil)
+	longevityTestCase = &publisher.TestCase{
+		Name:        testName,
+		Description: fmt.Sprintf("validating %s in longevity cluster", testName),
+	}
+	publisher.TestCaseBegin(longevityTestCase)
 }
 func endLongevityTest() {
-	dash.TestCaseEnd()
+	if longevityTestCase != nil {
+		publisher.TestCaseEnd(longevityTestCase)
+		longevityTestCase = nil
+	}
 	CloseLogger(longevityLogger)
 }
 
@@ -495,24 +610,30 @@ func TriggerDeployNewApps(contexts *[]*scheduler.Context, recordChan *chan *Even
 	Inst().M.IncrementCounterMetric(TotalTriggerCount, event.Event.Type)
 	Inst().M.SetGaugeMetricWithNonDefaultLabels(FailedTestAlert, 0, event.Event.Type, "")
 
-	Step(fmt.Sprintf("Set throttle to re-sync"), func() {
+	eventStep(event, fmt.Sprintf("Set throttle to re-sync"), func() {
 		UpdateOutcome(event, updatePxRuntimeOpts())
 	})
 
 	errorChan := make(chan error, errorChannelSize)
 	labels := Inst().TopologyLabels
-	Step("Deploy applications", func() {
+	eventStep(event, "Deploy applications", func() {
 		if len(labels) > 0 {
 			for i := 0; i < Inst().GlobalScaleFactor; i++ {
 				newContexts := ScheduleAppsInTopologyEnabledCluster(
 					fmt.Sprintf("longevity-%d", i), labels, &errorChan,
 				)
 				*contexts = append(*contexts, newContexts...)
+				for _, ctx := range newContexts {
+					recordTargets(event, eventlog.TargetApp, ctx.App.Key)
+				}
 			}
 		} else {
 			for i := 0; i < Inst().GlobalScaleFactor; i++ {
 				newContexts := ScheduleApplications(fmt.Sprintf("longevity-%d", i), &errorChan)
 				*contexts = append(*contexts, newContexts...)
+				for _, ctx := range newContexts {
+					recordTargets(event, eventlog.TargetApp, ctx.App.Key)
+				}
 			}
 		}
 
@@ -555,13 +676,14 @@ func TriggerVolumeCreatePXRestart(contexts *[]*scheduler.Context, recordChan *ch
 	var createdVolIDs map[string]string
 	var err error
 	volCreateCount := 10
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 
 		stNodes := node.GetStorageNodes()
-		index := randIntn(1, len(stNodes))[0]
+		index := randIntn(VolumeCreatePxRestart, 1, len(stNodes))[0]
 
 		selectedNode := stNodes[index]
+		recordNodeTargets(event, selectedNode)
 
 		log.InfoD("Creating and attaching %d volumes on node %s", volCreateCount, selectedNode.Name)
 
@@ -578,7 +700,7 @@ func TriggerVolumeCreatePXRestart(contexts *[]*scheduler.Context, recordChan *ch
 		go func(appNode node.Node) {
 			defer wg.Done()
 			stepLog = fmt.Sprintf("restart volume driver %s on node: %s", Inst().V.String(), appNode.Name)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				err = Inst().V.RestartDriver(appNode, nil)
 				UpdateOutcome(event, err)
@@ -590,7 +712,7 @@ func TriggerVolumeCreatePXRestart(contexts *[]*scheduler.Context, recordChan *ch
 	})
 
 	stepLog = "Validate the created volumes"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		var cVol *opsapi.Volume
 		var err error
@@ -624,7 +746,7 @@ func TriggerVolumeCreatePXRestart(contexts *[]*scheduler.Context, recordChan *ch
 	})
 
 	stepLog = "Deleting the created volumes"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 
 		for vol := range createdVolIDs {
@@ -662,7 +784,7 @@ func TriggerHAIncreaseAndReboot(contexts *[]*scheduler.Context, recordChan *chan
 
 	//Reboot target node and source node while repl increase is in progress
 	stepLog := "get a volume to  increase replication factor and reboot source  and target node"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD("get a volume to  increase replication factor and reboot source  and target node")
 		storageNodeMap := make(map[string]node.Node)
 		storageNodes, err := GetStorageNodes()
@@ -676,7 +798,7 @@ func TriggerHAIncreaseAndReboot(contexts *[]*scheduler.Context, recordChan *chan
 			var appVolumes []*volume.Volume
 			var err error
 			stepLog = fmt.Sprintf("get volumes for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				appVolumes, err = Inst().S.GetVolumes(ctx)
 				UpdateOutcome(event, err)
@@ -723,6 +845,7 @@ func TriggerHAIncreaseAndReboot(contexts *[]*scheduler.Context, recordChan *chan
 					}
 
 					if err == nil {
+						recordTargets(event, eventlog.TargetVolume, v.Name)
 						HaIncreaseRebootTargetNode(event, ctx, v, storageNodeMap)
 						HaIncreaseRebootSourceNode(event, ctx, v, storageNodeMap)
 					}
@@ -755,14 +878,14 @@ func TriggerHAIncrease(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 
 	expReplMap := make(map[*volume.Volume]int64)
 	stepLog := "get volumes for all apps in test and increase replication factor"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		time.Sleep(10 * time.Minute)
 		for _, ctx := range *contexts {
 			var appVolumes []*volume.Volume
 			var err error
 			stepLog = fmt.Sprintf("get volumes for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				appVolumes, err = Inst().S.GetVolumes(ctx)
 				UpdateOutcome(event, err)
@@ -783,10 +906,11 @@ func TriggerHAIncrease(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 					log.Warnf("Repl increase on Pure DA Volume [%s] not supported.Skiping this operation", v.Name)
 					continue
 				}
+				recordTargets(event, eventlog.TargetVolume, v.Name)
 				MaxRF := Inst().V.GetMaxReplicationFactor()
 				stepLog = fmt.Sprintf("repl increase volume driver %s on app %s's volume: %v",
 					Inst().V.String(), ctx.App.Key, v)
-				Step(stepLog,
+				eventStep(event, stepLog,
 					func() {
 						log.InfoD(stepLog)
 						errExpected := false
@@ -841,7 +965,7 @@ func TriggerHAIncrease(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 					})
 				stepLog = fmt.Sprintf("validate successful repl increase on app %s's volume: %v",
 					ctx.App.Key, v)
-				Step(stepLog,
+				eventStep(event, stepLog,
 					func() {
 						newRepl, err := Inst().V.GetReplicationFactor(v)
 						UpdateOutcome(event, err)
@@ -856,7 +980,7 @@ func TriggerHAIncrease(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 			}
 			stepLog = fmt.Sprintf("validating context after increasing HA for app: %s",
 				ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				errorChan := make(chan error, errorChannelSize)
 				ctx.SkipVolumeValidation = true
@@ -902,13 +1026,13 @@ func TriggerHADecrease(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 
 	expReplMap := make(map[*volume.Volume]int64)
 	stepLog := "get volumes for all apps in test and decrease replication factor"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		for _, ctx := range *contexts {
 			var appVolumes []*volume.Volume
 			var err error
 			stepLog = fmt.Sprintf("get volumes for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				appVolumes, err = Inst().S.GetVolumes(ctx)
 				UpdateOutcome(event, err)
@@ -929,10 +1053,11 @@ func TriggerHADecrease(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 					log.Warnf("Repl decrease on Pure DA volume:[%s] not supported.Skipping repl decrease operation in pure volume", v.Name)
 					continue
 				}
+				recordTargets(event, eventlog.TargetVolume, v.Name)
 				MinRF := Inst().V.GetMinReplicationFactor()
 				stepLog = fmt.Sprintf("repl decrease volume driver %s on app %s's volume: %v",
 					Inst().V.String(), ctx.App.Key, v)
-				Step(stepLog,
+				eventStep(event, stepLog,
 					func() {
 						log.InfoD(stepLog)
 						errExpected := false
@@ -964,7 +1089,7 @@ func TriggerHADecrease(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 					})
 				stepLog = fmt.Sprintf("validate successful repl decrease on app %s's volume: %v",
 					ctx.App.Key, v)
-				Step(stepLog,
+				eventStep(event, stepLog,
 					func() {
 						log.InfoD(stepLog)
 						newRepl, err := Inst().V.GetReplicationFactor(v)
@@ -980,7 +1105,7 @@ func TriggerHADecrease(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 			}
 			stepLog = fmt.Sprintf("validating context after reducing HA for app: %s",
 				ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				errorChan := make(chan error, errorChannelSize)
 				ctx.SkipVolumeValidation = true
@@ -1022,8 +1147,9 @@ func TriggerAppTaskDown(contexts *[]*scheduler.Context, recordChan *chan *EventR
 	setMetrics(*event)
 	stepLog := ""
 	for _, ctx := range *contexts {
+		recordTargets(event, eventlog.TargetApp, ctx.App.Key)
 		stepLog = fmt.Sprintf("delete tasks for app: [%s]", ctx.App.Key)
-		Step(stepLog, func() {
+		eventStep(event, stepLog, func() {
 			log.InfoD(stepLog)
 			err := Inst().S.DeleteTasks(ctx, nil)
 			if err != nil {
@@ -1033,7 +1159,7 @@ func TriggerAppTaskDown(contexts *[]*scheduler.Context, recordChan *chan *EventR
 		})
 		stepLog = fmt.Sprintf("validating context after delete tasks for app: [%s]",
 			ctx.App.Key)
-		Step(stepLog, func() {
+		eventStep(event, stepLog, func() {
 			log.InfoD(stepLog)
 			errorChan := make(chan error, errorChannelSize)
 			ctx.SkipVolumeValidation = true
@@ -1066,12 +1192,13 @@ func TriggerCrashVolDriver(contexts *[]*scheduler.Context, recordChan *chan *Eve
 	}()
 	setMetrics(*event)
 	stepLog := "crash volume driver in all nodes"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		for _, appNode := range node.GetStorageDriverNodes() {
+			recordNodeTargets(event, appNode)
 			stepLog = fmt.Sprintf("crash volume driver %s on node: %v",
 				Inst().V.String(), appNode.Name)
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					taskStep := fmt.Sprintf("crash volume driver on node: %s",
@@ -1108,12 +1235,13 @@ func TriggerRestartVolDriver(contexts *[]*scheduler.Context, recordChan *chan *E
 	}()
 	setMetrics(*event)
 	stepLog := "get nodes bounce volume driver"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		for _, appNode := range node.GetStorageDriverNodes() {
+			recordNodeTargets(event, appNode)
 			stepLog = fmt.Sprintf("stop volume driver %s on node: %s",
 				Inst().V.String(), appNode.Name)
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					taskStep := fmt.Sprintf("stop volume driver on node: %s.",
@@ -1127,7 +1255,7 @@ func TriggerRestartVolDriver(contexts *[]*scheduler.Context, recordChan *chan *E
 				})
 			stepLog = fmt.Sprintf("starting volume %s driver on node %s",
 				Inst().V.String(), appNode.Name)
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					taskStep := fmt.Sprintf("starting volume driver on node: %s.",
@@ -1140,13 +1268,13 @@ func TriggerRestartVolDriver(contexts *[]*scheduler.Context, recordChan *chan *E
 					}
 				})
 
-			Step("Giving few seconds for volume driver to stabilize", func() {
+			eventStep(event, "Giving few seconds for volume driver to stabilize", func() {
 				time.Sleep(20 * time.Second)
 			})
 
 			for _, ctx := range *contexts {
 				stepLog = fmt.Sprintf("RestartVolDriver: validating app [%s]", ctx.App.Key)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					errorChan := make(chan error, errorChannelSize)
 					ctx.ReadinessTimeout = time.Minute * 10
@@ -1187,16 +1315,17 @@ func TriggerRestartManyVolDriver(contexts *[]*scheduler.Context, recordChan *cha
 	setMetrics(*event)
 
 	driverNodesToRestart := getNodesByChaosLevel(RestartManyVolDriver)
+	recordNodeTargets(event, driverNodesToRestart...)
 	var wg sync.WaitGroup
 	stepLog := "get nodes bounce volume driver"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		for _, appNode := range driverNodesToRestart {
 			wg.Add(1)
 			go func(appNode node.Node) {
 				defer wg.Done()
 				stepLog = fmt.Sprintf("stop volume driver %s on node: %s", Inst().V.String(), appNode.Name)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					taskStep := fmt.Sprintf("stop volume driver on node: %s.",
 						appNode.MgmtIp)
@@ -1210,7 +1339,7 @@ func TriggerRestartManyVolDriver(contexts *[]*scheduler.Context, recordChan *cha
 			}(appNode)
 		}
 
-		Step("wait all the storage drivers to be stopped", func() {
+		eventStep(event, "wait all the storage drivers to be stopped", func() {
 			wg.Wait()
 		})
 
@@ -1219,7 +1348,7 @@ func TriggerRestartManyVolDriver(contexts *[]*scheduler.Context, recordChan *cha
 			go func(appNode node.Node) {
 				defer wg.Done()
 				stepLog = fmt.Sprintf("starting volume %s driver on node %s", Inst().V.String(), appNode.Name)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					taskStep := fmt.Sprintf("starting volume driver on node: %s.",
 						appNode.MgmtIp)
@@ -1231,19 +1360,19 @@ func TriggerRestartManyVolDriver(contexts *[]*scheduler.Context, recordChan *cha
 					}
 				})
 
-				Step("Giving few seconds for volume driver to stabilize", func() {
+				eventStep(event, "Giving few seconds for volume driver to stabilize", func() {
 					time.Sleep(20 * time.Second)
 				})
 			}(appNode)
 		}
 		stepLog = "wait all the storage drivers to be up"
-		Step(stepLog, func() {
+		eventStep(event, stepLog, func() {
 			log.InfoD(stepLog)
 			wg.Wait()
 		})
 		for _, ctx := range *contexts {
 			stepLog = fmt.Sprintf("RestartVolDriver: validating app [%s]", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				errorChan := make(chan error, errorChannelSize)
 				ctx.ReadinessTimeout = time.Minute * 10
 				ValidateContext(ctx, &errorChan)
@@ -1282,11 +1411,12 @@ func TriggerRestartKvdbVolDriver(contexts *[]*scheduler.Context, recordChan *cha
 
 	setMetrics(*event)
 	stepLog := "get kvdb nodes bounce volume driver"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		for _, appNode := range node.GetMetadataNodes() {
+			recordNodeTargets(event, appNode)
 			stepLog = fmt.Sprintf("stop volume driver %s on node: %s",
 				Inst().V.String(), appNode.Name)
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					taskStep := fmt.Sprintf("stop volume driver on node: %s.",
@@ -1300,7 +1430,7 @@ func TriggerRestartKvdbVolDriver(contexts *[]*scheduler.Context, recordChan *cha
 				})
 			stepLog = fmt.Sprintf("starting volume %s driver on node %s",
 				Inst().V.String(), appNode.Name)
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					taskStep := fmt.Sprintf("starting volume driver on node: %s.",
@@ -1313,13 +1443,13 @@ func TriggerRestartKvdbVolDriver(contexts *[]*scheduler.Context, recordChan *cha
 					}
 				})
 
-			Step("Giving few seconds for volume driver to stabilize", func() {
+			eventStep(event, "Giving few seconds for volume driver to stabilize", func() {
 				time.Sleep(20 * time.Second)
 			})
 
 			for _, ctx := range *contexts {
 				stepLog = fmt.Sprintf("RestartVolDriver: validating app [%s]", ctx.App.Key)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					errorChan := make(chan error, errorChannelSize)
 					ctx.ReadinessTimeout = time.Minute * 10
@@ -1359,77 +1489,83 @@ func TriggerRebootNodes(contexts *[]*scheduler.Context, recordChan *chan *EventR
 
 	setMetrics(*event)
 	stepLog := "get all nodes and reboot one by one"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		nodesToReboot := node.GetWorkerNodes()
 
 		// Reboot node and check driver status
 		stepLog = fmt.Sprintf("reboot node one at a time from the node(s): %v", nodesToReboot)
-		Step(stepLog, func() {
+		eventStep(event, stepLog, func() {
 			// TODO: Below is the same code from existing nodeReboot test
 			log.InfoD(stepLog)
 			for _, n := range nodesToReboot {
 				if n.IsStorageDriverInstalled {
-					stepLog = fmt.Sprintf("reboot node: %s", n.Name)
-					Step(stepLog, func() {
-						taskStep := fmt.Sprintf("reboot node: %s.", n.MgmtIp)
-						event.Event.Type += "<br>" + taskStep
-						err := Inst().N.RebootNode(n, node.RebootNodeOpts{
-							Force: true,
-							ConnectionOpts: node.ConnectionOpts{
-								Timeout:         1 * time.Minute,
-								TimeBeforeRetry: 5 * time.Second,
-							},
+					// the node is released even if a step panics
+					func() {
+						releaseNode := claimNode(RebootNode, n)
+						defer releaseNode()
+						recordNodeTargets(event, n)
+						stepLog = fmt.Sprintf("reboot node: %s", n.Name)
+						eventStep(event, stepLog, func() {
+							taskStep := fmt.Sprintf("reboot node: %s.", n.MgmtIp)
+							event.Event.Type += "<br>" + taskStep
+							err := Inst().N.RebootNode(n, node.RebootNodeOpts{
+								Force: true,
+								ConnectionOpts: node.ConnectionOpts{
+									Timeout:         1 * time.Minute,
+									TimeBeforeRetry: 5 * time.Second,
+								},
+							})
+							if err != nil {
+								log.Errorf("Error while rebooting node %v, err: %v", n.Name, err.Error())
+							}
+							UpdateOutcome(event, err)
 						})
-						if err != nil {
-							log.Errorf("Error while rebooting node %v, err: %v", n.Name, err.Error())
-						}
-						UpdateOutcome(event, err)
-					})
-					stepLog = fmt.Sprintf("wait for node: %s to be back up", n.Name)
-					Step(stepLog, func() {
-						err := Inst().N.TestConnection(n, node.ConnectionOpts{
-							Timeout:         15 * time.Minute,
-							TimeBeforeRetry: 10 * time.Second,
+						stepLog = fmt.Sprintf("wait for node: %s to be back up", n.Name)
+						eventStep(event, stepLog, func() {
+							err := Inst().N.TestConnection(n, node.ConnectionOpts{
+								Timeout:         15 * time.Minute,
+								TimeBeforeRetry: 10 * time.Second,
+							})
+							if err != nil {
+								log.Errorf("Error while testing node status %v, err: %v", n.Name, err.Error())
+							}
+							UpdateOutcome(event, err)
 						})
-						if err != nil {
-							log.Errorf("Error while testing node status %v, err: %v", n.Name, err.Error())
-						}
-						UpdateOutcome(event, err)
-					})
-					stepLog = fmt.Sprintf("wait for volume driver to stop on node: %v", n.Name)
+						stepLog = fmt.Sprintf("wait for volume driver to stop on node: %v", n.Name)
 
-					Step(stepLog, func() {
-						err := Inst().V.WaitDriverDownOnNode(n)
-						UpdateOutcome(event, err)
-					})
-					stepLog = fmt.Sprintf("wait to scheduler: %s and volume driver: %s to start",
-						Inst().S.String(), Inst().V.String())
-					Step(stepLog, func() {
-						log.InfoD(stepLog)
-						err := Inst().S.IsNodeReady(n)
-						UpdateOutcome(event, err)
+						eventStep(event, stepLog, func() {
+							err := Inst().V.WaitDriverDownOnNode(n)
+							UpdateOutcome(event, err)
+						})
+						stepLog = fmt.Sprintf("wait to scheduler: %s and volume driver: %s to start",
+							Inst().S.String(), Inst().V.String())
+						eventStep(event, stepLog, func() {
+							log.InfoD(stepLog)
+							err := Inst().S.IsNodeReady(n)
+							UpdateOutcome(event, err)
 
-						err = Inst().V.WaitDriverUpOnNode(n, Inst().DriverStartTimeout)
-						UpdateOutcome(event, err)
-					})
+							err = Inst().V.WaitDriverUpOnNode(n, Inst().DriverStartTimeout)
+							UpdateOutcome(event, err)
+						})
 
-					Step("validate apps", func() {
-						for _, ctx := range *contexts {
-							stepLog = fmt.Sprintf("RebootNode: validating app [%s]", ctx.App.Key)
-							Step(stepLog, func() {
-								errorChan := make(chan error, errorChannelSize)
-								ValidateContext(ctx, &errorChan)
-								for err := range errorChan {
-									UpdateOutcome(event, err)
-								}
-								if strings.Contains(ctx.App.Key, fastpathAppName) {
-									err := ValidateFastpathVolume(ctx, opsapi.FastpathStatus_FASTPATH_ACTIVE)
-									UpdateOutcome(event, err)
-								}
-							})
-						}
-					})
+						eventStep(event, "validate apps", func() {
+							for _, ctx := range *contexts {
+								stepLog = fmt.Sprintf("RebootNode: validating app [%s]", ctx.App.Key)
+								eventStep(event, stepLog, func() {
+									errorChan := make(chan error, errorChannelSize)
+									ValidateContext(ctx, &errorChan)
+									for err := range errorChan {
+										UpdateOutcome(event, err)
+									}
+									if strings.Contains(ctx.App.Key, fastpathAppName) {
+										err := ValidateFastpathVolume(ctx, opsapi.FastpathStatus_FASTPATH_ACTIVE)
+										UpdateOutcome(event, err)
+									}
+								})
+							}
+						})
+					}()
 				}
 			}
 			updateMetrics(*event)
@@ -1458,12 +1594,13 @@ func TriggerRebootManyNodes(contexts *[]*scheduler.Context, recordChan *chan *Ev
 
 	setMetrics(*event)
 	stepLog := "get all nodes and reboot one by one"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		nodesToReboot := getNodesByChaosLevel(RebootManyNodes)
+		recordNodeTargets(event, nodesToReboot...)
 		// Reboot node and check driver status
 		stepLog = fmt.Sprintf("reboot the node(s): %v", nodesToReboot)
-		Step(stepLog, func() {
+		eventStep(event, stepLog, func() {
 			log.InfoD(stepLog)
 			var wg sync.WaitGroup
 			for _, n := range nodesToReboot {
@@ -1471,7 +1608,7 @@ func TriggerRebootManyNodes(contexts *[]*scheduler.Context, recordChan *chan *Ev
 				go func(n node.Node) {
 					defer wg.Done()
 					stepLog = fmt.Sprintf("reboot node: %s", n.Name)
-					Step(stepLog, func() {
+					eventStep(event, stepLog, func() {
 						log.InfoD(stepLog)
 						taskStep := fmt.Sprintf("reboot node: %s.", n.MgmtIp)
 						event.Event.Type += "<br>" + taskStep
@@ -1490,7 +1627,7 @@ func TriggerRebootManyNodes(contexts *[]*scheduler.Context, recordChan *chan *Ev
 				}(n)
 			}
 			stepLog = "wait all the nodes to be rebooted"
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				wg.Wait()
 			})
@@ -1500,7 +1637,7 @@ func TriggerRebootManyNodes(contexts *[]*scheduler.Context, recordChan *chan *Ev
 				go func(n node.Node) {
 					defer wg.Done()
 					stepLog = fmt.Sprintf("wait for node: %s to be back up", n.Name)
-					Step(stepLog, func() {
+					eventStep(event, stepLog, func() {
 						log.InfoD(stepLog)
 						err := Inst().N.TestConnection(n, node.ConnectionOpts{
 							Timeout:         15 * time.Minute,
@@ -1513,14 +1650,14 @@ func TriggerRebootManyNodes(contexts *[]*scheduler.Context, recordChan *chan *Ev
 					})
 
 					stepLog = fmt.Sprintf("wait for volume driver to stop on node: %v", n.Name)
-					Step(stepLog, func() {
+					eventStep(event, stepLog, func() {
 						log.InfoD(stepLog)
 						err := Inst().V.WaitDriverDownOnNode(n)
 						UpdateOutcome(event, err)
 					})
 					stepLog = fmt.Sprintf("wait to scheduler: %s and volume driver: %s to start",
 						Inst().S.String(), Inst().V.String())
-					Step(stepLog, func() {
+					eventStep(event, stepLog, func() {
 						log.InfoD(stepLog)
 						err := Inst().S.IsNodeReady(n)
 						UpdateOutcome(event, err)
@@ -1531,15 +1668,15 @@ func TriggerRebootManyNodes(contexts *[]*scheduler.Context, recordChan *chan *Ev
 				}(n)
 			}
 			stepLog = "wait all the nodes to be up"
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				wg.Wait()
 			})
 
-			Step("validate apps", func() {
+			eventStep(event, "validate apps", func() {
 				for _, ctx := range *contexts {
 					stepLog = fmt.Sprintf("RebootNode: validating app [%s]", ctx.App.Key)
-					Step(stepLog, func() {
+					eventStep(event, stepLog, func() {
 						log.InfoD(stepLog)
 						errorChan := make(chan error, errorChannelSize)
 						ValidateContext(ctx, &errorChan)
@@ -1558,23 +1695,9 @@ func TriggerRebootManyNodes(contexts *[]*scheduler.Context, recordChan *chan *Ev
 	})
 }
 
-func randIntn(n, maxNo int) []int {
-	if n > maxNo {
-		n = maxNo
-	}
-	rand.Seed(time.Now().UnixNano())
-	generated := make(map[int]bool)
-	for i := 0; i < n; i++ {
-		randUniq := rand.Intn(maxNo)
-		if !generated[randUniq] {
-			generated[randUniq] = true
-		}
-	}
-	generatedNs := make([]int, 0)
-	for key := range generated {
-		generatedNs = append(generatedNs, key)
-	}
-	return generatedNs
+// randIntn returns n distinct random numbers in [0, maxNo) drawn from the stream of the trigger
+func randIntn(triggerType string, n, maxNo int) []int {
+	return TriggerRand.Pick(triggerType, n, maxNo)
 }
 
 func getNodesByChaosLevel(triggerType string) []node.Node {
@@ -1585,7 +1708,7 @@ func getNodesByChaosLevel(triggerType string) []node.Node {
 	var nodeLen float32
 	switch t {
 	case 10:
-		index := randIntn(1, stNodesLen)[0]
+		index := randIntn(triggerType, 1, stNodesLen)[0]
 		return []node.Node{stNodes[index]}
 	case 9:
 		nodeLen = float32(stNodesLen) * 0.2
@@ -1606,7 +1729,7 @@ func getNodesByChaosLevel(triggerType string) []node.Node {
 	case 1:
 		return stNodes
 	}
-	generatedNodeIndexes := randIntn(int(nodeLen), stNodesLen)
+	generatedNodeIndexes := randIntn(triggerType, int(nodeLen), stNodesLen)
 	for i := 0; i < len(generatedNodeIndexes); i++ {
 		nodes = append(nodes, stNodes[generatedNodeIndexes[i]])
 	}
@@ -1632,68 +1755,74 @@ func TriggerCrashNodes(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 		*recordChan <- event
 	}()
 	stepLog := "get all nodes and crash one by one"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		nodesToCrash := node.GetWorkerNodes()
 
 		// Crash node and check driver status
 		stepLog = fmt.Sprintf("crash node one at a time from the node(s): %v", nodesToCrash)
-		Step(stepLog, func() {
+		eventStep(event, stepLog, func() {
 			// TODO: Below is the same code from existing nodeCrash test
 			log.InfoD(stepLog)
 			for _, n := range nodesToCrash {
 				if n.IsStorageDriverInstalled {
-					stepLog = fmt.Sprintf("crash node: %s", n.Name)
-					Step(stepLog, func() {
-						log.InfoD(stepLog)
-						taskStep := fmt.Sprintf("crash node: %s.", n.MgmtIp)
-						event.Event.Type += "<br>" + taskStep
-						err := Inst().N.CrashNode(n, node.CrashNodeOpts{
-							Force: true,
-							ConnectionOpts: node.ConnectionOpts{
-								Timeout:         1 * time.Minute,
-								TimeBeforeRetry: 5 * time.Second,
-							},
+					// the node is released even if a step panics
+					func() {
+						releaseNode := claimNode(CrashNode, n)
+						defer releaseNode()
+						recordNodeTargets(event, n)
+						stepLog = fmt.Sprintf("crash node: %s", n.Name)
+						eventStep(event, stepLog, func() {
+							log.InfoD(stepLog)
+							taskStep := fmt.Sprintf("crash node: %s.", n.MgmtIp)
+							event.Event.Type += "<br>" + taskStep
+							err := Inst().N.CrashNode(n, node.CrashNodeOpts{
+								Force: true,
+								ConnectionOpts: node.ConnectionOpts{
+									Timeout:         1 * time.Minute,
+									TimeBeforeRetry: 5 * time.Second,
+								},
+							})
+							UpdateOutcome(event, err)
 						})
-						UpdateOutcome(event, err)
-					})
-					stepLog = fmt.Sprintf("wait for node: %s to be back up", n.Name)
-					Step(stepLog, func() {
-						log.InfoD(stepLog)
-						err := Inst().N.TestConnection(n, node.ConnectionOpts{
-							Timeout:         15 * time.Minute,
-							TimeBeforeRetry: 10 * time.Second,
+						stepLog = fmt.Sprintf("wait for node: %s to be back up", n.Name)
+						eventStep(event, stepLog, func() {
+							log.InfoD(stepLog)
+							err := Inst().N.TestConnection(n, node.ConnectionOpts{
+								Timeout:         15 * time.Minute,
+								TimeBeforeRetry: 10 * time.Second,
+							})
+							UpdateOutcome(event, err)
 						})
-						UpdateOutcome(event, err)
-					})
-					stepLog = fmt.Sprintf("wait to scheduler: %s and volume driver: %s to start",
-						Inst().S.String(), Inst().V.String())
-					Step(stepLog, func() {
-						log.InfoD(stepLog)
-						err := Inst().S.IsNodeReady(n)
-						UpdateOutcome(event, err)
+						stepLog = fmt.Sprintf("wait to scheduler: %s and volume driver: %s to start",
+							Inst().S.String(), Inst().V.String())
+						eventStep(event, stepLog, func() {
+							log.InfoD(stepLog)
+							err := Inst().S.IsNodeReady(n)
+							UpdateOutcome(event, err)
 
-						err = Inst().V.WaitDriverUpOnNode(n, Inst().DriverStartTimeout)
-						UpdateOutcome(event, err)
-					})
+							err = Inst().V.WaitDriverUpOnNode(n, Inst().DriverStartTimeout)
+							UpdateOutcome(event, err)
+						})
 
-					Step("validate apps", func() {
-						for _, ctx := range *contexts {
-							stepLog = fmt.Sprintf("CrashNode: validating app [%s]", ctx.App.Key)
-							Step(stepLog, func() {
-								log.InfoD(stepLog)
-								errorChan := make(chan error, errorChannelSize)
-								ValidateContext(ctx, &errorChan)
-								for err := range errorChan {
-									UpdateOutcome(event, err)
-								}
-								if strings.Contains(ctx.App.Key, fastpathAppName) {
-									err := ValidateFastpathVolume(ctx, opsapi.FastpathStatus_FASTPATH_ACTIVE)
-									UpdateOutcome(event, err)
-								}
-							})
-						}
-					})
+						eventStep(event, "validate apps", func() {
+							for _, ctx := range *contexts {
+								stepLog = fmt.Sprintf("CrashNode: validating app [%s]", ctx.App.Key)
+								eventStep(event, stepLog, func() {
+									log.InfoD(stepLog)
+									errorChan := make(chan error, errorChannelSize)
+									ValidateContext(ctx, &errorChan)
+									for err := range errorChan {
+										UpdateOutcome(event, err)
+									}
+									if strings.Contains(ctx.App.Key, fastpathAppName) {
+										err := ValidateFastpathVolume(ctx, opsapi.FastpathStatus_FASTPATH_ACTIVE)
+										UpdateOutcome(event, err)
+									}
+								})
+							}
+						})
+					}()
 				}
 			}
 		})
@@ -1721,12 +1850,12 @@ func TriggerVolumeClone(contexts *[]*scheduler.Context, recordChan *chan *EventR
 	setMetrics(*event)
 	stepLog := "get volumes for all apps in test and clone them"
 
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		for _, ctx := range *contexts {
 			var appVolumes []*volume.Volume
 			var err error
 			stepLog := fmt.Sprintf("get volumes for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				appVolumes, err = Inst().S.GetVolumes(ctx)
 				UpdateOutcome(event, err)
@@ -1751,15 +1880,16 @@ func TriggerVolumeClone(contexts *[]*scheduler.Context, recordChan *chan *EventR
 					)
 					continue
 				}
+				recordTargets(event, eventlog.TargetVolume, vol.Name)
 				stepLog = fmt.Sprintf("Clone Volume %s", vol.Name)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					log.Infof("Calling CloneVolume()...")
 					clonedVolID, err = Inst().V.CloneVolume(vol.ID)
 					UpdateOutcome(event, err)
 				})
 				stepLog = fmt.Sprintf("Validate successful clone %s", clonedVolID)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					params := make(map[string]string)
 					if Inst().ConfigMap != "" {
@@ -1770,7 +1900,7 @@ func TriggerVolumeClone(contexts *[]*scheduler.Context, recordChan *chan *EventR
 					UpdateOutcome(event, err)
 				})
 				stepLog = fmt.Sprintf("cleanup the cloned volume %s", clonedVolID)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					err = Inst().V.DeleteVolume(clonedVolID)
 					UpdateOutcome(event, err)
@@ -1803,13 +1933,13 @@ func TriggerVolumeResize(contexts *[]*scheduler.Context, recordChan *chan *Event
 	Inst().M.IncrementGaugeMetric(TestRunningState, event.Event.Type)
 	Inst().M.IncrementCounterMetric(TotalTriggerCount, event.Event.Type)
 	stepLog := "get volumes for all apps in test and update size"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		for _, ctx := range *contexts {
 			var appVolumes []*volume.Volume
 			var err error
 			stepLog = fmt.Sprintf("get volumes for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				appVolumes, err = Inst().S.GetVolumes(ctx)
 				log.Infof("len of app volumes is : %v", len(appVolumes))
@@ -1818,21 +1948,31 @@ func TriggerVolumeResize(contexts *[]*scheduler.Context, recordChan *chan *Event
 					UpdateOutcome(event, fmt.Errorf("found no volumes for app %s", ctx.App.Key))
 				}
 			})
+			for _, v := range appVolumes {
+				recordTargets(event, eventlog.TargetVolume, v.Name)
+			}
 			var requestedVols []*volume.Volume
 			stepLog = fmt.Sprintf("increase volume size %s on app %s's volumes: %v",
 				Inst().V.String(), ctx.App.Key, appVolumes)
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
-					log.InfoD("increasing volume size")
-					requestedVols, err = Inst().S.ResizeVolume(ctx, Inst().ConfigMap)
-					if err != nil && !(strings.Contains(err.Error(), "only dynamically provisioned pvc can be resized")) {
-						UpdateOutcome(event, err)
+					// The scheduler grows the volumes by 1 GiB per resize
+					sizeIncrease := getTriggerIntParam(VolumeResize, "size-increase-gib", 1)
+					log.InfoD("increasing volume size by %d GiB", sizeIncrease)
+					for i := 0; i < sizeIncrease; i++ {
+						requestedVols, err = Inst().S.ResizeVolume(ctx, Inst().ConfigMap)
+						if err != nil {
+							if !strings.Contains(err.Error(), "only dynamically provisioned pvc can be resized") {
+								UpdateOutcome(event, err)
+							}
+							break
+						}
 					}
 				})
 			stepLog = fmt.Sprintf("validate successful volume size increase on app %s's volumes: %v",
 				ctx.App.Key, appVolumes)
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					for _, v := range requestedVols {
@@ -1873,22 +2013,22 @@ func TriggerLocalSnapShot(contexts *[]*scheduler.Context, recordChan *chan *Even
 
 	setMetrics(*event)
 	stepLog := "Create and Validate LocalSnapshots"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		for _, ctx := range *contexts {
 			var appVolumes []*volume.Volume
 			var err error
 			if strings.Contains(ctx.App.Key, "localsnap") {
-
+				recordTargets(event, eventlog.TargetApp, ctx.App.Key)
 				appNamespace := ctx.App.Key + "-" + ctx.UID
 				log.Infof("Namespace : %v", appNamespace)
 				stepLog = fmt.Sprintf("create schedule policy for %s app", ctx.App.Key)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					policyName := "localintervalpolicy"
 					schedPolicy, err := storkops.Instance().GetSchedulePolicy(policyName)
 					if err != nil {
-						retain := 2
+						retain := getTriggerIntParam(LocalSnapShot, "retain", 2)
 						interval := getCloudSnapInterval(LocalSnapShot)
 						log.InfoD("Creating a interval schedule policy %v with interval %v minutes", policyName, interval)
 						schedPolicy = &storkv1.SchedulePolicy{
@@ -1914,7 +2054,7 @@ func TriggerLocalSnapShot(contexts *[]*scheduler.Context, recordChan *chan *Even
 				log.Infof("Waiting for 2 mins for Snapshots to be completed")
 				time.Sleep(2 * time.Minute)
 				stepLog = fmt.Sprintf("get volumes for %s app", ctx.App.Key)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					appVolumes, err = Inst().S.GetVolumes(ctx)
 					UpdateOutcome(event, err)
@@ -2004,23 +2144,23 @@ func TriggerDeleteLocalSnapShot(contexts *[]*scheduler.Context, recordChan *chan
 
 	setMetrics(*event)
 	stepLog := "Delete Schedule Policy and LocalSnapshots and Validate"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		for _, ctx := range *contexts {
 			var appVolumes []*volume.Volume
 
 			if strings.Contains(ctx.App.Key, "localsnap") {
-
+				recordTargets(event, eventlog.TargetApp, ctx.App.Key)
 				appNamespace := ctx.App.Key + "-" + ctx.UID
 				log.Infof("Namespace : %v", appNamespace)
 				stepLog = fmt.Sprintf("delete schedule policy for %s app", ctx.App.Key)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					policyName := "localintervalpolicy"
 					err := storkops.Instance().DeleteSchedulePolicy(policyName)
 					UpdateOutcome(event, err)
 					stepLog = fmt.Sprintf("get volumes for %s app", ctx.App.Key)
-					Step(stepLog, func() {
+					eventStep(event, stepLog, func() {
 						log.InfoD(stepLog)
 						appVolumes, err = Inst().S.GetVolumes(ctx)
 						UpdateOutcome(event, err)
@@ -2100,22 +2240,22 @@ func TriggerCloudSnapShot(contexts *[]*scheduler.Context, recordChan *chan *Even
 	snapshotScheduleRetryInterval := 10 * time.Second
 	snapshotScheduleRetryTimeout := 3 * time.Minute
 	stepLog := "Validate Cloud Snaps"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		for _, ctx := range *contexts {
 			var appVolumes []*volume.Volume
 			var err error
 			if strings.Contains(ctx.App.Key, "cloudsnap") {
-
+				recordTargets(event, eventlog.TargetApp, ctx.App.Key)
 				appNamespace := ctx.App.Key + "-" + ctx.UID
 				log.Infof("Namespace : %v", appNamespace)
 				stepLog = fmt.Sprintf("create schedule policy for %s app", ctx.App.Key)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					policyName := "intervalpolicy"
 					schedPolicy, err := storkops.Instance().GetSchedulePolicy(policyName)
 					if err != nil {
-						retain := 2
+						retain := getTriggerIntParam(CloudSnapShot, "retain", 2)
 						interval := getCloudSnapInterval(CloudSnapShot)
 						log.InfoD("Creating a interval schedule policy %v with interval %v minutes", policyName, interval)
 						schedPolicy = &storkv1.SchedulePolicy{
@@ -2140,7 +2280,7 @@ func TriggerCloudSnapShot(contexts *[]*scheduler.Context, recordChan *chan *Even
 				})
 				stepLog = fmt.Sprintf("get volumes for %s app", ctx.App.Key)
 
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					appVolumes, err = Inst().S.GetVolumes(ctx)
 					UpdateOutcome(event, err)
@@ -2212,12 +2352,12 @@ func TriggerVolumeDelete(contexts *[]*scheduler.Context, recordChan *chan *Event
 
 	setMetrics(*event)
 	stepLog := "Validate Delete Volumes"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		opts := make(map[string]bool)
 
 		for _, ctx := range *contexts {
-
+			recordTargets(event, eventlog.TargetApp, ctx.App.Key)
 			opts[SkipClusterScopedObjects] = true
 			options := mapToVolumeOptions(opts)
 
@@ -2226,7 +2366,7 @@ func TriggerVolumeDelete(contexts *[]*scheduler.Context, recordChan *chan *Event
 
 			// Tear down application
 			stepLog = fmt.Sprintf("start destroying %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				err := Inst().S.Destroy(ctx, opts)
 				UpdateOutcome(event, err)
@@ -2379,9 +2519,120 @@ func CollectEventRecords(recordChan *chan *EventRecord) {
 	for eventRecord := range *recordChan {
 		eventRing.Value = eventRecord
 		eventRing = eventRing.Next()
+		journalEventRecord(eventRecord)
+		event := structuredEventOf(eventRecord)
+		logEvent(event)
+		Notifiers.Publish(event)
 	}
 }
 
+// structuredEventOf returns the event record as an event of the event log
+func structuredEventOf(eventRecord *EventRecord) eventlog.Event {
+	triggerType := triggerTypeOf(eventRecord)
+	eventRecordLock.Lock()
+	event := eventlog.Event{
+		ID:         eventRecord.Event.ID,
+		Trigger:    triggerType,
+		ChaosLevel: ChaosMap[triggerType],
+		Targets:    append([]eventlog.Target{}, eventRecord.Targets...),
+		Phases:     append([]eventlog.Phase{}, eventRecord.Phases...),
+		Errors:     append([]eventlog.Error{}, eventRecord.Errors...),
+	}
+	eventRecordLock.Unlock()
+	// start and end are only kept with a precision of seconds for the email report
+	event.Start, _ = time.Parse(time.RFC1123, eventRecord.Start)
+	event.End, _ = time.Parse(time.RFC1123, eventRecord.End)
+	return event
+}
+
+// logEvent appends the event to the event log, if one is being written
+func logEvent(event eventlog.Event) {
+	if EventLog == nil {
+		return
+	}
+	if err := EventLog.Write(event); err != nil {
+		log.Errorf("Failed to log event [%s] of trigger [%s]: %v", event.ID, event.Trigger, err)
+	}
+}
+
+// triggerTypeOf returns the trigger which generated the event record. Triggers append the
+// steps they took to the event type.
+func triggerTypeOf(eventRecord *EventRecord) string {
+	return strings.SplitN(eventRecord.Event.Type, "<br>", 2)[0]
+}
+
+// journalEventRecord appends the event record to the replay journal, if one is being recorded
+func journalEventRecord(eventRecord *EventRecord) {
+	if EventJournal == nil {
+		return
+	}
+	triggerType := triggerTypeOf(eventRecord)
+	entry := replay.Entry{
+		Trigger:    triggerType,
+		ID:         eventRecord.Event.ID,
+		Start:      eventRecord.Start,
+		End:        eventRecord.End,
+		ChaosLevel: ChaosMap[triggerType],
+		Params:     TriggerParams[triggerType],
+		Targets:    eventRecord.TargetNames(),
+	}
+	for _, err := range eventRecord.Outcome {
+		entry.Outcome = append(entry.Outcome, err.Error())
+	}
+	if err := EventJournal.Append(entry); err != nil {
+		log.Errorf("Failed to journal event [%s] of trigger [%s]: %v", eventRecord.Event.ID, triggerType, err)
+	}
+}
+
+// recordTargets records the resources targeted by a trigger
+func recordTargets(event *EventRecord, kind eventlog.TargetKind, names ...string) {
+	eventRecordLock.Lock()
+	defer eventRecordLock.Unlock()
+	for _, name := range names {
+		event.Targets = append(event.Targets, eventlog.Target{Kind: kind, Name: name})
+	}
+}
+
+// recordNodeTargets records the nodes targeted by a trigger
+func recordNodeTargets(event *EventRecord, nodes ...node.Node) {
+	for _, n := range nodes {
+		recordTargets(event, eventlog.TargetNode, n.Name)
+	}
+}
+
+// claimNode blocks till no other trigger claims the given node and claims it for the trigger.
+// Triggers which declare a claim on any node call it for each node they disrupt.
+func claimNode(triggerType string, n node.Node) (release func()) {
+	return TriggerClaims.Acquire(triggerType, claims.Claim{Resource: claims.Node, Instance: n.Name})
+}
+
+// GetTriggerParam returns the value of the given parameter of a test trigger, or defaultValue
+// if the longevity scenario does not set it. The parameters read by triggers are:
+//   - volumeResize: size-increase-gib, the GiB added to each volume, 1 by default
+//   - localSnapShot and cloudSnapShot: interval-minutes and retain, the interval and retention of
+//     the schedule policy of the snapshots, by default derived from the chaos level and 2
+func GetTriggerParam(triggerType, key, defaultValue string) string {
+	if value, ok := TriggerParams[triggerType][key]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// getTriggerIntParam returns the positive integer value of the given parameter of a test
+// trigger, or defaultValue if the longevity scenario does not set it or sets an invalid value
+func getTriggerIntParam(triggerType, key string, defaultValue int) int {
+	value := GetTriggerParam(triggerType, key, "")
+	if value == "" {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i <= 0 {
+		log.Errorf("Invalid value [%s] of parameter [%s] of trigger [%s], using [%d]", value, key, triggerType, defaultValue)
+		return defaultValue
+	}
+	return i
+}
+
 // TriggerEmailReporter sends email with all reported errors
 func TriggerEmailReporter() {
 	// emailRecords stores events to be notified
@@ -2487,7 +2738,7 @@ func TriggerBackupApps(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 
 	setMetrics(*event)
 
-	Step("Update admin secret", func() {
+	eventStep(event, "Update admin secret", func() {
 		err := backup.UpdatePxBackupAdminSecret()
 		ProcessErrorWithMessage(event, err, "Unable to update PxBackupAdminSecret")
 	})
@@ -2498,14 +2749,15 @@ func TriggerBackupApps(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 		namespace := ctx.GetID()
 		bkpNamespaces = append(bkpNamespaces, namespace)
 	}
-	Step("Backup all namespaces", func() {
+	recordTargets(event, eventlog.TargetNamespace, bkpNamespaces...)
+	eventStep(event, "Backup all namespaces", func() {
 		bkpNamespaceErrors := make(map[string]error)
 		sourceClusterConfigPath, err := GetSourceClusterConfigPath()
 		UpdateOutcome(event, err)
 		SetClusterContext(sourceClusterConfigPath)
 		for _, namespace := range bkpNamespaces {
 			backupName := fmt.Sprintf("%s-%s-%d", BackupNamePrefix, namespace, backupCounter)
-			Step(fmt.Sprintf("Create backup full name %s:%s:%s",
+			eventStep(event, fmt.Sprintf("Create backup full name %s:%s:%s",
 				SourceClusterName, namespace, backupName), func() {
 				err = CreateBackupGetErr(backupName,
 					SourceClusterName, backupLocationNameConst, BackupLocationUID,
@@ -2523,7 +2775,7 @@ func TriggerBackupApps(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 				log.Warnf("Skipping waiting for backup %s because %s", backupName, err)
 				continue
 			}
-			Step(fmt.Sprintf("Wait for backup %s to complete", backupName), func() {
+			eventStep(event, fmt.Sprintf("Wait for backup %s to complete", backupName), func() {
 				ctx, err := backup.GetPxCentralAdminCtx()
 				if err != nil {
 					log.Errorf("Failed to fetch px-central-admin ctx: [%v]", err)
@@ -2661,7 +2913,8 @@ func TriggerBackupSpecificResource(contexts *[]*scheduler.Context, recordChan *c
 		namespace := ctx.GetID()
 		bkpNamespaces = append(bkpNamespaces, namespace)
 	}
-	Step("Create config maps", func() {
+	recordTargets(event, eventlog.TargetNamespace, bkpNamespaces...)
+	eventStep(event, "Create config maps", func() {
 		configMapCount := 2
 		for _, namespace := range bkpNamespaces {
 			for i := 0; i < configMapCount; i++ {
@@ -2681,7 +2934,7 @@ func TriggerBackupSpecificResource(contexts *[]*scheduler.Context, recordChan *c
 		}
 	})
 	defer func() {
-		Step("Clean up config maps", func() {
+		eventStep(event, "Clean up config maps", func() {
 			for _, namespace := range bkpNamespaces {
 				for _, configName := range namespaceResourceMap[namespace] {
 					err := core.Instance().DeleteConfigMap(configName, namespace)
@@ -2693,7 +2946,7 @@ func TriggerBackupSpecificResource(contexts *[]*scheduler.Context, recordChan *c
 		*recordChan <- event
 	}()
 	bkpNames := make([]string, 0)
-	Step("Create backups", func() {
+	eventStep(event, "Create backups", func() {
 		for _, namespace := range bkpNamespaces {
 			backupName := fmt.Sprintf("%s-%s-%d", BackupNamePrefix, namespace, backupCounter)
 			bkpNames = append(bkpNames, namespace)
@@ -2716,7 +2969,7 @@ func TriggerBackupSpecificResource(contexts *[]*scheduler.Context, recordChan *c
 			log.Warnf("Skipping waiting for backup [%s] because [%s]", backupName, err)
 			continue
 		}
-		Step(fmt.Sprintf("Wait for backup [%s] to complete", backupName), func() {
+		eventStep(event, fmt.Sprintf("Wait for backup [%s] to complete", backupName), func() {
 			ctx, err := backup.GetPxCentralAdminCtx()
 			if err != nil {
 				bkpNamespaceErrors[namespace] = err
@@ -2736,7 +2989,7 @@ func TriggerBackupSpecificResource(contexts *[]*scheduler.Context, recordChan *c
 			}
 		})
 	}
-	Step("Check that only config maps are backed up", func() {
+	eventStep(event, "Check that only config maps are backed up", func() {
 		for _, namespace := range bkpNames {
 			backupName := fmt.Sprintf("%s-%s-%d", BackupNamePrefix, namespace, backupCounter)
 			err, ok := bkpNamespaceErrors[namespace]
@@ -3037,7 +3290,7 @@ func TriggerBackupSpecificResourceOnCluster(contexts *[]*scheduler.Context, reco
 	namespaces := make([]string, 0)
 	labelSelectors := make(map[string]string)
 	totalPVC := 0
-	Step("Backup all persistent volume claims on source cluster", func() {
+	eventStep(event, "Backup all persistent volume claims on source cluster", func() {
 		nsList, err := core.Instance().ListNamespaces(labelSelectors)
 		UpdateOutcome(event, err)
 		if err == nil {
@@ -3055,7 +3308,7 @@ func TriggerBackupSpecificResourceOnCluster(contexts *[]*scheduler.Context, reco
 	if err != nil {
 		return
 	}
-	Step("Wait for backup to complete", func() {
+	eventStep(event, "Wait for backup to complete", func() {
 		ctx, err := backup.GetPxCentralAdminCtx()
 		if err != nil {
 			ProcessErrorWithMessage(event, err, fmt.Sprintf("Failed to fetch px-central-admin ctx: [%v]", err))
@@ -3075,7 +3328,7 @@ func TriggerBackupSpecificResourceOnCluster(contexts *[]*scheduler.Context, reco
 	if err != nil {
 		return
 	}
-	Step("Check PVCs in backup", func() {
+	eventStep(event, "Check PVCs in backup", func() {
 		for _, ns := range namespaces {
 			pvcList, err := core.Instance().GetPersistentVolumeClaims(ns, labelSelectors)
 			UpdateOutcome(event, err)
@@ -3118,7 +3371,7 @@ func TriggerBackupByLabel(contexts *[]*scheduler.Context, recordChan *chan *Even
 	labelKey := "backup-by-label"
 	labelValue := uuid.New()
 	defer func() {
-		Step("Delete the temporary labels", func() {
+		eventStep(event, "Delete the temporary labels", func() {
 			nsList, err := core.Instance().ListNamespaces(nil)
 			UpdateOutcome(event, err)
 			for _, ns := range nsList.Items {
@@ -3173,7 +3426,7 @@ func TriggerBackupByLabel(contexts *[]*scheduler.Context, recordChan *chan *Even
 	namespaces := make([]string, 0)
 	labelSelectors := make(map[string]string)
 	labeledResources := make(map[string]bool)
-	Step("Add labels to random resources", func() {
+	eventStep(event, "Add labels to random resources", func() {
 		nsList, err := core.Instance().ListNamespaces(nil)
 		UpdateOutcome(event, err)
 		for _, ns := range nsList.Items {
@@ -3185,7 +3438,7 @@ func TriggerBackupByLabel(contexts *[]*scheduler.Context, recordChan *chan *Even
 				UpdateOutcome(event, err)
 				if err == nil {
 					// Randomly choose some pvcs to add labels to for backup
-					dice := rand.Intn(4)
+					dice := TriggerRand.Intn(BackupUsingLabelOnCluster, 4)
 					if dice == 1 {
 						err = AddLabelToResource(pvcPointer, labelKey, labelValue)
 						UpdateOutcome(event, err)
@@ -3203,7 +3456,7 @@ func TriggerBackupByLabel(contexts *[]*scheduler.Context, recordChan *chan *Even
 				UpdateOutcome(event, err)
 				if err == nil {
 					// Randomly choose some configmaps to add labels to for backup
-					dice := rand.Intn(4)
+					dice := TriggerRand.Intn(BackupUsingLabelOnCluster, 4)
 					if dice == 1 {
 						err = AddLabelToResource(cmPointer, labelKey, labelValue)
 						UpdateOutcome(event, err)
@@ -3221,7 +3474,7 @@ func TriggerBackupByLabel(contexts *[]*scheduler.Context, recordChan *chan *Even
 				UpdateOutcome(event, err)
 				if err == nil {
 					// Randomly choose some secrets to add labels to for backup
-					dice := rand.Intn(4)
+					dice := TriggerRand.Intn(BackupUsingLabelOnCluster, 4)
 					if dice == 1 {
 						err = AddLabelToResource(secretPointer, labelKey, labelValue)
 						UpdateOutcome(event, err)
@@ -3234,7 +3487,7 @@ func TriggerBackupByLabel(contexts *[]*scheduler.Context, recordChan *chan *Even
 			}
 		}
 	})
-	Step(fmt.Sprintf("Backup using label [%s=%s]", labelKey, labelValue), func() {
+	eventStep(event, fmt.Sprintf("Backup using label [%s=%s]", labelKey, labelValue), func() {
 		labelSelectors[labelKey] = labelValue
 		backupCreateRequest := GetBackupCreateRequest(backupName, SourceClusterName, backupLocationNameConst, BackupLocationUID,
 			namespaces, labelSelectors, OrgID)
@@ -3245,7 +3498,7 @@ func TriggerBackupByLabel(contexts *[]*scheduler.Context, recordChan *chan *Even
 			return
 		}
 	})
-	Step("Wait for backup to complete", func() {
+	eventStep(event, "Wait for backup to complete", func() {
 		ctx, err := backup.GetPxCentralAdminCtx()
 		if err != nil {
 			ProcessErrorWithMessage(event, err, fmt.Sprintf("Failed to fetch px-central-admin ctx: [%v]", err))
@@ -3263,7 +3516,7 @@ func TriggerBackupByLabel(contexts *[]*scheduler.Context, recordChan *chan *Even
 			}
 		}
 	})
-	Step("Check that we only backed up objects with specified labels", func() {
+	eventStep(event, "Check that we only backed up objects with specified labels", func() {
 		bkpInspectResp, err := InspectBackup(backupName)
 		UpdateOutcome(event, err)
 		if err != nil {
@@ -3313,6 +3566,7 @@ func TriggerScheduledBackupScale(contexts *[]*scheduler.Context, recordChan *cha
 		namespace := ctx.GetID()
 		bkpNamespaces = append(bkpNamespaces, namespace)
 	}
+	recordTargets(event, eventlog.TargetNamespace, bkpNamespaces...)
 
 	_, err = InspectScheduledBackup(backupScheduleScaleName, BackupScheduleScaleUID)
 	if ObjectExists(err) {
@@ -3327,7 +3581,7 @@ func TriggerScheduledBackupScale(contexts *[]*scheduler.Context, recordChan *cha
 	}
 
 	for _, ctx := range *contexts {
-		Step(fmt.Sprintf("scale up app: %s by %d ", ctx.App.Key, len(node.GetWorkerNodes())), func() {
+		eventStep(event, fmt.Sprintf("scale up app: %s by %d ", ctx.App.Key, len(node.GetWorkerNodes())), func() {
 			applicationScaleUpMap, err := Inst().S.GetScaleFactorMap(ctx)
 			UpdateOutcome(event, err)
 			for name, scale := range applicationScaleUpMap {
@@ -3337,7 +3591,7 @@ func TriggerScheduledBackupScale(contexts *[]*scheduler.Context, recordChan *cha
 			UpdateOutcome(event, err)
 		})
 
-		Step("Giving few seconds for scaled up applications to stabilize", func() {
+		eventStep(event, "Giving few seconds for scaled up applications to stabilize", func() {
 			time.Sleep(10 * time.Second)
 		})
 
@@ -3382,7 +3636,7 @@ func TriggerScheduledBackupScale(contexts *[]*scheduler.Context, recordChan *cha
 	}
 
 	for _, ctx := range *contexts {
-		Step(fmt.Sprintf("scale down app %s by %d", ctx.App.Key, len(node.GetWorkerNodes())), func() {
+		eventStep(event, fmt.Sprintf("scale down app %s by %d", ctx.App.Key, len(node.GetWorkerNodes())), func() {
 			applicationScaleDownMap, err := Inst().S.GetScaleFactorMap(ctx)
 			UpdateOutcome(event, err)
 			for name, scale := range applicationScaleDownMap {
@@ -3392,7 +3646,7 @@ func TriggerScheduledBackupScale(contexts *[]*scheduler.Context, recordChan *cha
 			UpdateOutcome(event, err)
 		})
 
-		Step("Giving few seconds for scaled down applications to stabilize", func() {
+		eventStep(event, "Giving few seconds for scaled down applications to stabilize", func() {
 			time.Sleep(10 * time.Second)
 		})
 
@@ -3457,7 +3711,7 @@ func TriggerBackupRestartPX(contexts *[]*scheduler.Context, recordChan *chan *Ev
 
 	setMetrics(*event)
 
-	Step("Update admin secret", func() {
+	eventStep(event, "Update admin secret", func() {
 		err := backup.UpdatePxBackupAdminSecret()
 		ProcessErrorWithMessage(event, err, "Unable to update PxBackupAdminSecret")
 	})
@@ -3471,11 +3725,12 @@ func TriggerBackupRestartPX(contexts *[]*scheduler.Context, recordChan *chan *Ev
 		namespace := ctx.GetID()
 		bkpNamespaces = append(bkpNamespaces, namespace)
 	}
-	nsIndex := rand.Intn(len(bkpNamespaces))
+	nsIndex := TriggerRand.Intn(BackupRestartPX, len(bkpNamespaces))
+	recordTargets(event, eventlog.TargetNamespace, bkpNamespaces[nsIndex])
 	backupName := fmt.Sprintf("%s-%s-%d", BackupNamePrefix, bkpNamespaces[nsIndex], backupCounter)
 	bkpError := false
-	Step("Backup a single namespace", func() {
-		Step(fmt.Sprintf("Create backup full name %s:%s:%s",
+	eventStep(event, "Backup a single namespace", func() {
+		eventStep(event, fmt.Sprintf("Create backup full name %s:%s:%s",
 			SourceClusterName, bkpNamespaces[nsIndex], backupName), func() {
 			err = CreateBackupGetErr(backupName,
 				SourceClusterName, backupLocationNameConst, BackupLocationUID,
@@ -3487,9 +3742,10 @@ func TriggerBackupRestartPX(contexts *[]*scheduler.Context, recordChan *chan *Ev
 		})
 	})
 
-	Step("Restart Portworx", func() {
+	eventStep(event, "Restart Portworx", func() {
 		nodes := node.GetStorageDriverNodes()
-		nodeIndex := rand.Intn(len(nodes))
+		nodeIndex := TriggerRand.Intn(BackupRestartPX, len(nodes))
+		recordNodeTargets(event, nodes[nodeIndex])
 		log.Infof("Stop volume driver [%s] on node: [%s]", Inst().V.String(), nodes[nodeIndex].Name)
 		StopVolDriverAndWait([]node.Node{nodes[nodeIndex]})
 		log.Infof("Starting volume driver [%s] on node [%s]", Inst().V.String(), nodes[nodeIndex].Name)
@@ -3498,7 +3754,7 @@ func TriggerBackupRestartPX(contexts *[]*scheduler.Context, recordChan *chan *Ev
 		time.Sleep(20 * time.Second)
 	})
 
-	Step("Wait for backup to complete", func() {
+	eventStep(event, "Wait for backup to complete", func() {
 		if bkpError {
 			log.Warnf("Skipping waiting for backup [%s] due to error", backupName)
 		} else {
@@ -3544,7 +3800,7 @@ func TriggerBackupRestartNode(contexts *[]*scheduler.Context, recordChan *chan *
 
 	setMetrics(*event)
 
-	Step("Update admin secret", func() {
+	eventStep(event, "Update admin secret", func() {
 		err := backup.UpdatePxBackupAdminSecret()
 		ProcessErrorWithMessage(event, err, "Unable to update PxBackupAdminSecret")
 	})
@@ -3559,11 +3815,12 @@ func TriggerBackupRestartNode(contexts *[]*scheduler.Context, recordChan *chan *
 		bkpNamespaces = append(bkpNamespaces, namespace)
 	}
 	// Choose a random namespace to back up
-	nsIndex := rand.Intn(len(bkpNamespaces))
+	nsIndex := TriggerRand.Intn(BackupRestartNode, len(bkpNamespaces))
+	recordTargets(event, eventlog.TargetNamespace, bkpNamespaces[nsIndex])
 	backupName := fmt.Sprintf("%s-%s-%d", BackupNamePrefix, bkpNamespaces[nsIndex], backupCounter)
 	bkpError := false
-	Step("Backup a single namespace", func() {
-		Step(fmt.Sprintf("Create backup full name %s:%s:%s",
+	eventStep(event, "Backup a single namespace", func() {
+		eventStep(event, fmt.Sprintf("Create backup full name %s:%s:%s",
 			SourceClusterName, bkpNamespaces[nsIndex], backupName), func() {
 			err = CreateBackupGetErr(backupName,
 				SourceClusterName, backupLocationNameConst, BackupLocationUID,
@@ -3575,11 +3832,12 @@ func TriggerBackupRestartNode(contexts *[]*scheduler.Context, recordChan *chan *
 		})
 	})
 
-	Step("Restart a Portworx node", func() {
+	eventStep(event, "Restart a Portworx node", func() {
 		nodes := node.GetStorageDriverNodes()
 		// Choose a random node to reboot
-		nodeIndex := rand.Intn(len(nodes))
-		Step(fmt.Sprintf("reboot node: %s", nodes[nodeIndex].Name), func() {
+		nodeIndex := TriggerRand.Intn(BackupRestartNode, len(nodes))
+		recordNodeTargets(event, nodes[nodeIndex])
+		eventStep(event, fmt.Sprintf("reboot node: %s", nodes[nodeIndex].Name), func() {
 			err := Inst().N.RebootNode(nodes[nodeIndex], node.RebootNodeOpts{
 				Force: true,
 				ConnectionOpts: node.ConnectionOpts{
@@ -3591,7 +3849,7 @@ func TriggerBackupRestartNode(contexts *[]*scheduler.Context, recordChan *chan *
 			UpdateOutcome(event, err)
 		})
 
-		Step(fmt.Sprintf("wait for node: [%s] to be back up", nodes[nodeIndex].Name), func() {
+		eventStep(event, fmt.Sprintf("wait for node: [%s] to be back up", nodes[nodeIndex].Name), func() {
 			err := Inst().N.TestConnection(nodes[nodeIndex], node.ConnectionOpts{
 				Timeout:         15 * time.Minute,
 				TimeBeforeRetry: 10 * time.Second,
@@ -3600,13 +3858,13 @@ func TriggerBackupRestartNode(contexts *[]*scheduler.Context, recordChan *chan *
 			UpdateOutcome(event, err)
 		})
 
-		Step(fmt.Sprintf("wait for volume driver to stop on node: [%v]", nodes[nodeIndex].Name), func() {
+		eventStep(event, fmt.Sprintf("wait for volume driver to stop on node: [%v]", nodes[nodeIndex].Name), func() {
 			err := Inst().V.WaitDriverDownOnNode(nodes[nodeIndex])
 			expect(err).NotTo(haveOccurred())
 			UpdateOutcome(event, err)
 		})
 
-		Step(fmt.Sprintf("wait to scheduler: [%s] and volume driver: [%s] to start",
+		eventStep(event, fmt.Sprintf("wait to scheduler: [%s] and volume driver: [%s] to start",
 			Inst().S.String(), Inst().V.String()), func() {
 
 			err := Inst().S.IsNodeReady(nodes[nodeIndex])
@@ -3618,7 +3876,7 @@ func TriggerBackupRestartNode(contexts *[]*scheduler.Context, recordChan *chan *
 			UpdateOutcome(event, err)
 		})
 
-		Step(fmt.Sprintf("wait for px-backup pods to come up on node: [%v]", nodes[nodeIndex].Name), func() {
+		eventStep(event, fmt.Sprintf("wait for px-backup pods to come up on node: [%v]", nodes[nodeIndex].Name), func() {
 			// should probably make px-backup namespace a global constant
 			timeout := 6 * time.Minute
 			t := func() (interface{}, bool, error) {
@@ -3640,7 +3898,7 @@ func TriggerBackupRestartNode(contexts *[]*scheduler.Context, recordChan *chan *
 		})
 	})
 
-	Step("Wait for backup to complete", func() {
+	eventStep(event, "Wait for backup to complete", func() {
 		if bkpError {
 			log.Warnf("Skipping waiting for backup [%s] due to error", backupName)
 		} else {
@@ -3686,7 +3944,7 @@ func TriggerBackupDeleteBackupPod(contexts *[]*scheduler.Context, recordChan *ch
 
 	setMetrics(*event)
 
-	Step("Update admin secret", func() {
+	eventStep(event, "Update admin secret", func() {
 		err := backup.UpdatePxBackupAdminSecret()
 		ProcessErrorWithMessage(event, err, "Unable to update PxBackupAdminSecret")
 	})
@@ -3697,8 +3955,8 @@ func TriggerBackupDeleteBackupPod(contexts *[]*scheduler.Context, recordChan *ch
 	SetClusterContext(sourceClusterConfigPath)
 
 	backupName := fmt.Sprintf("%s-%s-%d", BackupNamePrefix, "deleteBackupPod", backupCounter)
-	Step("Backup all namespaces", func() {
-		Step(fmt.Sprintf("Create backup full name %s:%s:%s",
+	eventStep(event, "Backup all namespaces", func() {
+		eventStep(event, fmt.Sprintf("Create backup full name %s:%s:%s",
 			SourceClusterName, "all", backupName), func() {
 			err = CreateBackupGetErr(backupName,
 				SourceClusterName, backupLocationNameConst, BackupLocationUID,
@@ -3707,7 +3965,8 @@ func TriggerBackupDeleteBackupPod(contexts *[]*scheduler.Context, recordChan *ch
 		})
 	})
 
-	Step("Delete px-backup pod", func() {
+	eventStep(event, "Delete px-backup pod", func() {
+		recordTargets(event, eventlog.TargetApp, pxbackupDeploymentName)
 		ctx := &scheduler.Context{
 			App: &spec.AppSpec{
 				SpecList: []interface{}{
@@ -3727,7 +3986,7 @@ func TriggerBackupDeleteBackupPod(contexts *[]*scheduler.Context, recordChan *ch
 		UpdateOutcome(event, err)
 	})
 
-	Step("Wait for backup to complete", func() {
+	eventStep(event, "Wait for backup to complete", func() {
 		ctx, err := backup.GetPxCentralAdminCtx()
 		if err != nil {
 			log.Errorf("Failed to fetch px-central-admin ctx: [%v]", err)
@@ -3769,7 +4028,7 @@ func TriggerBackupScaleMongo(contexts *[]*scheduler.Context, recordChan *chan *E
 
 	setMetrics(*event)
 
-	Step("Update admin secret", func() {
+	eventStep(event, "Update admin secret", func() {
 		err := backup.UpdatePxBackupAdminSecret()
 		ProcessErrorWithMessage(event, err, "Unable to update PxBackupAdminSecret")
 	})
@@ -3780,8 +4039,8 @@ func TriggerBackupScaleMongo(contexts *[]*scheduler.Context, recordChan *chan *E
 	SetClusterContext(sourceClusterConfigPath)
 
 	backupName := fmt.Sprintf("%s-%s-%d", BackupNamePrefix, "scaleMongo", backupCounter)
-	Step("Backup all namespaces", func() {
-		Step(fmt.Sprintf("Create backup full name %s:%s:%s",
+	eventStep(event, "Backup all namespaces", func() {
+		eventStep(event, fmt.Sprintf("Create backup full name %s:%s:%s",
 			SourceClusterName, "all", backupName), func() {
 			err = CreateBackupGetErr(backupName,
 				SourceClusterName, backupLocationNameConst, BackupLocationUID,
@@ -3790,7 +4049,7 @@ func TriggerBackupScaleMongo(contexts *[]*scheduler.Context, recordChan *chan *E
 		})
 	})
 
-	Step("Scale mongodb down to 0", func() {
+	eventStep(event, "Scale mongodb down to 0", func() {
 		ctx := &scheduler.Context{
 			App: &spec.AppSpec{
 				SpecList: []interface{}{
@@ -3808,12 +4067,12 @@ func TriggerBackupScaleMongo(contexts *[]*scheduler.Context, recordChan *chan *E
 		})
 		UpdateOutcome(event, err)
 
-		Step("Giving few seconds for scaled up applications to stabilize", func() {
+		eventStep(event, "Giving few seconds for scaled up applications to stabilize", func() {
 			time.Sleep(45 * time.Second)
 		})
 	})
 
-	Step("Scale mongodb up to 3", func() {
+	eventStep(event, "Scale mongodb up to 3", func() {
 		ctx := &scheduler.Context{
 			App: &spec.AppSpec{
 				SpecList: []interface{}{
@@ -3832,7 +4091,7 @@ func TriggerBackupScaleMongo(contexts *[]*scheduler.Context, recordChan *chan *E
 		UpdateOutcome(event, err)
 	})
 
-	Step("Wait for backup to complete", func() {
+	eventStep(event, "Wait for backup to complete", func() {
 		ctx, err := backup.GetPxCentralAdminCtx()
 		if err != nil {
 			log.Errorf("Failed to fetch px-central-admin ctx: [%v]", err)
@@ -3987,6 +4246,7 @@ func initiatePoolExpansion(event *EventRecord, wg *sync.WaitGroup, pool *opsapi.
 	}
 
 	if poolValidity {
+		recordTargets(event, eventlog.TargetPool, pool.Uuid)
 		initialPoolSize := pool.TotalSize / units.GiB
 
 		err = Inst().V.ResizeStoragePoolByPercentage(pool.Uuid, resizeOperationType, uint64(chaosLevel))
@@ -4003,6 +4263,7 @@ func initiatePoolExpansion(event *EventRecord, wg *sync.WaitGroup, pool *opsapi.
 					storageNode, err := GetNodeWithGivenPoolID(pool.Uuid)
 					log.Error(err.Error())
 					UpdateOutcome(event, err)
+					recordNodeTargets(event, *storageNode)
 					err = RebootNodeAndWait(*storageNode)
 					log.Error(err.Error())
 					UpdateOutcome(event, err)
@@ -4042,7 +4303,7 @@ func TriggerPoolResizeDisk(contexts *[]*scheduler.Context, recordChan *chan *Eve
 
 	chaosLevel := getPoolExpandPercentage(PoolResizeDisk)
 	stepLog := fmt.Sprintf("get storage pools and perform resize-disk by %v percentage on it ", chaosLevel)
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 
 		poolsToBeResized, err := getStoragePoolsToExpand()
 
@@ -4067,7 +4328,7 @@ func TriggerPoolResizeDisk(contexts *[]*scheduler.Context, recordChan *chan *Eve
 	})
 
 	stepLog = "validate all apps after pool resize using resize-disk operation"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		errorChan := make(chan error, errorChannelSize)
 		for _, ctx := range *contexts {
@@ -4105,7 +4366,7 @@ func TriggerPoolResizeDiskAndReboot(contexts *[]*scheduler.Context, recordChan *
 	chaosLevel := getPoolExpandPercentage(ResizeDiskAndReboot)
 
 	stepLog := fmt.Sprintf("get storage pools and perform resize-disk by %v percentage on it ", chaosLevel)
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		poolsToBeResized, err := getStoragePoolsToExpand()
 
@@ -4126,7 +4387,7 @@ func TriggerPoolResizeDiskAndReboot(contexts *[]*scheduler.Context, recordChan *
 	})
 
 	stepLog = "validate all apps after pool resize using resize-disk operation"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		errorChan := make(chan error, errorChannelSize)
 		for _, ctx := range *contexts {
@@ -4163,7 +4424,7 @@ func TriggerPoolAddDisk(contexts *[]*scheduler.Context, recordChan *chan *EventR
 
 	chaosLevel := getPoolExpandPercentage(PoolAddDisk)
 	stepLog := fmt.Sprintf("get storage pools and perform add-disk by %v percentage on it ", chaosLevel)
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		poolsToBeResized, err := getStoragePoolsToExpand()
 
@@ -4188,7 +4449,7 @@ func TriggerPoolAddDisk(contexts *[]*scheduler.Context, recordChan *chan *EventR
 
 	})
 	stepLog = "validate all apps after pool resize using add-disk operation"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		errorChan := make(chan error, errorChannelSize)
 		for _, ctx := range *contexts {
@@ -4225,7 +4486,7 @@ func TriggerPoolAddDiskAndReboot(contexts *[]*scheduler.Context, recordChan *cha
 
 	chaosLevel := getPoolExpandPercentage(AddDiskAndReboot)
 	stepLog := fmt.Sprintf("get storage pools and perform add-disk by %v percentage on it ", chaosLevel)
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		poolsToBeResized, err := getStoragePoolsToExpand()
 
@@ -4245,7 +4506,7 @@ func TriggerPoolAddDiskAndReboot(contexts *[]*scheduler.Context, recordChan *cha
 		}
 	})
 	stepLog = "validate all apps after pool resize using add-disk operation"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		errorChan := make(chan error, errorChannelSize)
 		for _, ctx := range *contexts {
@@ -4288,7 +4549,7 @@ func TriggerAutopilotPoolRebalance(contexts *[]*scheduler.Context, recordChan *c
 
 	if autoPilotLabelNode.Name == "" {
 
-		Step("Create autopilot rule", func() {
+		eventStep(event, "Create autopilot rule", func() {
 			log.InfoD("Creating autopilot rule ; %+v", apRule)
 
 			storageNodes := node.GetStorageDriverNodes()
@@ -4304,6 +4565,7 @@ func TriggerAutopilotPoolRebalance(contexts *[]*scheduler.Context, recordChan *c
 				}
 			}
 
+			recordNodeTargets(event, autoPilotLabelNode)
 			log.InfoD("Adding label %s to the node %s", poolLabel, autoPilotLabelNode.Name)
 			err := AddLabelsOnNode(autoPilotLabelNode, poolLabel)
 			UpdateOutcome(event, err)
@@ -4324,7 +4586,7 @@ func TriggerAutopilotPoolRebalance(contexts *[]*scheduler.Context, recordChan *c
 		})
 	} else {
 
-		Step("validate the  autopilot events", func() {
+		eventStep(event, "validate the  autopilot events", func() {
 			log.InfoD("validate the  autopilot events for %s", apRule.Name)
 			ruleEvents, err := core.Instance().ListEvents("", meta_v1.ListOptions{
 				FieldSelector: fmt.Sprintf("involvedObject.kind=AutopilotRule,involvedObject.name=%s", apRule.Name),
@@ -4348,7 +4610,7 @@ func TriggerAutopilotPoolRebalance(contexts *[]*scheduler.Context, recordChan *c
 
 		})
 
-		Step("validate Px on the rebalanced node", func() {
+		eventStep(event, "validate Px on the rebalanced node", func() {
 			log.InfoD("Validating PX on node : %s", autoPilotLabelNode.Name)
 			err := Inst().V.WaitDriverUpOnNode(autoPilotLabelNode, 1*time.Minute)
 			UpdateOutcome(event, err)
@@ -4386,7 +4648,7 @@ func TriggerUpgradeVolumeDriver(contexts *[]*scheduler.Context, recordChan *chan
 		}
 		for _, upgradeHop := range strings.Split(Inst().UpgradeStorageDriverEndpointList, ",") {
 			stepLog = "start the volume driver upgrade"
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				err := Inst().V.UpgradeDriver(upgradeHop)
 				if err != nil {
@@ -4396,7 +4658,7 @@ func TriggerUpgradeVolumeDriver(contexts *[]*scheduler.Context, recordChan *chan
 
 			})
 			stepLog = "validate all apps after upgrade"
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				errorChan := make(chan error, errorChannelSize)
 				for _, ctx := range *contexts {
@@ -4434,7 +4696,7 @@ func TriggerUpgradeStork(contexts *[]*scheduler.Context, recordChan *chan *Event
 
 	setMetrics(*event)
 	stepLog := "Upgrading stork to latest version based on the compatible PX and storage driver upgrade version "
-	Step(stepLog,
+	eventStep(event, stepLog,
 		func() {
 			if len(Inst().UpgradeStorageDriverEndpointList) == 0 {
 				log.Fatalf("Unable to perform volume driver upgrade hops, none were given")
@@ -4474,7 +4736,7 @@ func TriggerAutoFsTrim(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 	context(stepLog, func() {
 		log.InfoD(stepLog)
 		stepLog = "enable auto fstrim "
-		Step(stepLog,
+		eventStep(event, stepLog,
 			func() {
 				log.InfoD(stepLog)
 				if !isAutoFsTrimEnabled {
@@ -4497,13 +4759,13 @@ func TriggerAutoFsTrim(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 
 			})
 		stepLog = "Validate AutoFsTrim Status "
-		Step(stepLog,
+		eventStep(event, stepLog,
 			func() {
 				log.InfoD(stepLog)
 				validateAutoFsTrim(contexts, event)
 			})
 		stepLog = "Reboot attached node and validate AutoFsTrim Status "
-		Step(stepLog,
+		eventStep(event, stepLog,
 			func() {
 				log.InfoD(stepLog)
 				for _, ctx := range *contexts {
@@ -4514,6 +4776,8 @@ func TriggerAutoFsTrim(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 							n, err := Inst().V.GetNodeForVolume(vol, 1*time.Minute, 5*time.Second)
 							UpdateOutcome(event, err)
 							log.InfoD("volume %s is attached on node %s [%s]", vol.ID, n.SchedulerNodeName, n.Addresses[0])
+							recordTargets(event, eventlog.TargetVolume, vol.Name)
+							recordNodeTargets(event, *n)
 							err = Inst().S.DisableSchedulingOnNode(*n)
 							UpdateOutcome(event, err)
 
@@ -4580,7 +4844,7 @@ func TriggerVolumeUpdate(contexts *[]*scheduler.Context, recordChan *chan *Event
 	context(stepLog, func() {
 		log.InfoD(stepLog)
 		stepLog = "Update Io priority on volumes "
-		Step(stepLog,
+		eventStep(event, stepLog,
 			func() {
 				log.InfoD(stepLog)
 				updateIOPriorityOnVolumes(contexts, event)
@@ -4601,6 +4865,7 @@ func updateIOPriorityOnVolumes(contexts *[]*scheduler.Context, event *EventRecor
 			UpdateOutcome(event, fmt.Errorf("found no volumes for app "))
 		}
 		for _, v := range appVolumes {
+			recordTargets(event, eventlog.TargetVolume, v.Name)
 			log.InfoD("Getting info from volume: %s", v.ID)
 			appVol, err := Inst().V.InspectVolume(v.ID)
 			if err != nil {
@@ -4767,7 +5032,7 @@ func TriggerTrashcan(contexts *[]*scheduler.Context, recordChan *chan *EventReco
 		log.InfoD(stepLog)
 		if !isTrashcanEnabled {
 			stepLog = "enable trashcan"
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					currNode := node.GetWorkerNodes()[0]
@@ -4790,7 +5055,7 @@ func TriggerTrashcan(contexts *[]*scheduler.Context, recordChan *chan *EventReco
 			var err error
 			node := node.GetWorkerNodes()[0]
 			stepLog = "Validating trashcan"
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					trashcanVols, err = Inst().V.GetTrashCanVolumeIds(node)
@@ -4805,11 +5070,12 @@ func TriggerTrashcan(contexts *[]*scheduler.Context, recordChan *chan *EventReco
 					}
 				})
 			stepLog = "Validating trashcan restore"
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					if len(trashcanVols) != 0 {
 						volToRestore := trashcanVols[len(trashcanVols)-1]
+						recordTargets(event, eventlog.TargetVolume, volToRestore)
 						log.InfoD("Restoring vol [%v] from trashcan", volToRestore)
 						volName := fmt.Sprintf("%s-res", volToRestore[len(volToRestore)-4:])
 						pxctlCmdFull := fmt.Sprintf("v r %s --trashcan %s", volName, volToRestore)
@@ -4859,7 +5125,7 @@ func TriggerRelaxedReclaim(contexts *[]*scheduler.Context, recordChan *chan *Eve
 		log.InfoD(stepLog)
 		if !isRelaxedReclaimEnabled {
 			stepLog = "enable relaxed reclaim "
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					currNode := node.GetWorkerNodes()[0]
@@ -4879,7 +5145,7 @@ func TriggerRelaxedReclaim(contexts *[]*scheduler.Context, recordChan *chan *Eve
 				})
 		} else {
 			stepLog = "Validating relaxed reclaim "
-			Step(stepLog,
+			eventStep(event, stepLog,
 				func() {
 					log.InfoD(stepLog)
 					nodes := node.GetWorkerNodes()
@@ -4938,13 +5204,14 @@ func TriggerNodeDecommission(contexts *[]*scheduler.Context, recordChan *chan *E
 	var workerNodes []node.Node
 	var nodeToDecomm node.Node
 	stepLog := "Decommission a random node"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		workerNodes = node.GetWorkerNodes()
-		index := rand.Intn(len(workerNodes))
+		index := TriggerRand.Intn(NodeDecommission, len(workerNodes))
 		nodeToDecomm = workerNodes[index]
+		recordNodeTargets(event, nodeToDecomm)
 		stepLog = fmt.Sprintf("decommission node %s", nodeToDecomm.Name)
-		Step(stepLog, func() {
+		eventStep(event, stepLog, func() {
 			log.InfoD(stepLog)
 			err := Inst().S.PrepareNodeToDecommission(nodeToDecomm, Inst().Provisioner)
 			if err != nil {
@@ -4985,7 +5252,7 @@ func TriggerNodeDecommission(contexts *[]*scheduler.Context, recordChan *chan *E
 
 	for _, ctx := range *contexts {
 
-		Step(fmt.Sprintf("validating context after node: [%s] decommission",
+		eventStep(event, fmt.Sprintf("validating context after node: [%s] decommission",
 			nodeToDecomm.Name), func() {
 			errorChan := make(chan error, errorChannelSize)
 			ctx.SkipVolumeValidation = true
@@ -5025,12 +5292,13 @@ func TriggerNodeRejoin(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 	var decommissionedNodeName string
 
 	stepLog := "Rejoin the node"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		if decommissionedNode.Name != "" {
 			decommissionedNodeName = decommissionedNode.Name
+			recordNodeTargets(event, decommissionedNode)
 			stepLog = fmt.Sprintf("Rejoin node %s", decommissionedNode.Name)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				err := Inst().V.RejoinNode(&decommissionedNode)
 
@@ -5079,7 +5347,7 @@ func TriggerNodeRejoin(contexts *[]*scheduler.Context, recordChan *chan *EventRe
 
 	for _, ctx := range *contexts {
 
-		Step(fmt.Sprintf("validating context after node: [%s] rejoin",
+		eventStep(event, fmt.Sprintf("validating context after node: [%s] rejoin",
 			decommissionedNodeName), func() {
 			errorChan := make(chan error, errorChannelSize)
 			ctx.SkipVolumeValidation = true
@@ -5120,7 +5388,7 @@ func TriggerCsiSnapShot(contexts *[]*scheduler.Context, recordChan *chan *EventR
 	// Keeping retainSnapCount
 	retainSnapCount := DefaultSnapshotRetainCount
 	stepLog := "Create and Validate snapshots for FA DA volumes"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		if !isCsiVolumeSnapshotClassExist {
 			log.InfoD("Creating csi volume snapshot class")
@@ -5147,9 +5415,10 @@ func TriggerCsiSnapShot(contexts *[]*scheduler.Context, recordChan *chan *EventR
 		}
 		for _, ctx := range *contexts {
 			var volumeSnapshotMap map[string]*v1beta1.VolumeSnapshot
+			recordTargets(event, eventlog.TargetApp, ctx.App.Key)
 			var err error
 			stepLog = fmt.Sprintf("Deleting snapshots when retention count limit got exceeded for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				err = Inst().S.DeleteCsiSnapsForVolumes(ctx, retainSnapCount)
 				if err != nil {
 					log.Errorf("Snapshot delete is failing with error: [%v]", err)
@@ -5157,7 +5426,7 @@ func TriggerCsiSnapShot(contexts *[]*scheduler.Context, recordChan *chan *EventR
 				}
 			})
 			stepLog = fmt.Sprintf("Creating snapshots for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				volumeSnapshotMap, err = Inst().S.CreateCsiSnapsForVolumes(ctx, volSnapshotClass.Name)
 				if err != nil {
 					log.Errorf("Creating volume snapshot failed with error: [%v]", err)
@@ -5165,7 +5434,7 @@ func TriggerCsiSnapShot(contexts *[]*scheduler.Context, recordChan *chan *EventR
 				}
 			})
 			stepLog = fmt.Sprintf("Validate snapshot for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				if err = Inst().S.ValidateCsiSnapshots(ctx, volumeSnapshotMap); err != nil {
 					log.Errorf("Validating volume snapshot failed with error: [%v]", err)
@@ -5202,7 +5471,7 @@ func TriggerCsiSnapRestore(contexts *[]*scheduler.Context, recordChan *chan *Eve
 
 	var err error
 	stepLog := "Restore the Snapshot and Validate the PVC"
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		if !isCsiRestoreStorageClassExist {
 			var blockSc *storageapi.StorageClass
@@ -5221,8 +5490,9 @@ func TriggerCsiSnapRestore(contexts *[]*scheduler.Context, recordChan *chan *Eve
 		}
 		for _, ctx := range *contexts {
 			//var volumePVCMap map[string]v1.PersistentVolumeClaim
+			recordTargets(event, eventlog.TargetApp, ctx.App.Key)
 			stepLog = fmt.Sprintf("Restore and validate snapshot for %s app", ctx.App.Key)
-			Step(stepLog, func() {
+			eventStep(event, stepLog, func() {
 				log.InfoD(stepLog)
 				_, err = Inst().S.RestoreCsiSnapAndValidate(ctx, pureStorageClassMap)
 				if err != nil {
@@ -5293,8 +5563,7 @@ func getCloudSnapInterval(triggerType string) int {
 	case 10:
 		interval = 10
 	}
-	return interval
-
+	return getTriggerIntParam(triggerType, "interval-minutes", interval)
 }
 
 func createLongevityJiraIssue(event *EventRecord, err error) {
@@ -5376,7 +5645,7 @@ func TriggerKVDBFailover(contexts *[]*scheduler.Context, recordChan *chan *Event
 	context(stepLog, func() {
 		log.InfoD(stepLog)
 		stepLog = "Get KVDB nodes and perform failover"
-		Step(stepLog, func() {
+		eventStep(event, stepLog, func() {
 			log.InfoD(stepLog)
 			nodes := node.GetWorkerNodes()
 
@@ -5397,6 +5666,7 @@ func TriggerKVDBFailover(contexts *[]*scheduler.Context, recordChan *chan *Event
 
 				for id := range kvdbMembers {
 					kvdbNode := nodeMap[id]
+					recordNodeTargets(event, kvdbNode)
 					errorChan := make(chan error, errorChannelSize)
 					StopVolDriverAndWait([]node.Node{kvdbNode}, &errorChan)
 					for err := range errorChan {
@@ -5553,9 +5823,10 @@ func TriggerAppTasksDown(contexts *[]*scheduler.Context, recordChan *chan *Event
 	context(stepLog, func() {
 		log.InfoD(stepLog)
 		for _, ctx := range *contexts {
+			recordTargets(event, eventlog.TargetApp, ctx.App.Key)
 			for i := 0; i < chaosLevel; i++ {
 				stepLog = fmt.Sprintf("delete tasks for app: %s", ctx.App.Key)
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					err := Inst().S.DeleteTasks(ctx, nil)
 					if err != nil {
@@ -5564,7 +5835,7 @@ func TriggerAppTasksDown(contexts *[]*scheduler.Context, recordChan *chan *Event
 					UpdateOutcome(event, err)
 				})
 				stepLog = "validate all apps after deletion"
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					errorChan := make(chan error, errorChannelSize)
 					ctx.SkipVolumeValidation = true
@@ -5598,7 +5869,7 @@ func TriggerValidateDeviceMapperCleanup(contexts *[]*scheduler.Context, recordCh
 
 	log.InfoD("Validating the deviceMapper devices cleaned up or not")
 	stepLog := "Match the devicemapper devices in each node if it matches the expected count or not "
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 		log.InfoD(stepLog)
 		pureVolAttachedMap, err := Inst().V.GetNodePureVolumeAttachedCountMap()
 		if err != nil {
@@ -5657,7 +5928,7 @@ func TriggerAddDrive(contexts *[]*scheduler.Context, recordChan *chan *EventReco
 
 	setMetrics(*event)
 	stepLog := fmt.Sprintf("Perform add drive on all the worker nodes")
-	Step(stepLog, func() {
+	eventStep(event, stepLog, func() {
 
 		storageNodes := node.GetStorageNodes()
 
@@ -5672,6 +5943,7 @@ func TriggerAddDrive(contexts *[]*scheduler.Context, recordChan *chan *EventReco
 		}
 		if err == nil && !isCloudDrive {
 			for _, storageNode := range storageNodes {
+				recordNodeTargets(event, storageNode)
 				log.InfoD("Get Block drives to add for node %s", storageNode.Name)
 				blockDrives, err := Inst().N.GetBlockDrives(storageNode, systemOpts)
 				UpdateOutcome(event, err)
@@ -5696,7 +5968,7 @@ func TriggerAddDrive(contexts *[]*scheduler.Context, recordChan *chan *EventReco
 
 			for _, ctx := range *contexts {
 				stepLog = fmt.Sprintf("validating context after add drive on storage nodes")
-				Step(stepLog, func() {
+				eventStep(event, stepLog, func() {
 					log.InfoD(stepLog)
 					errorChan := make(chan error, errorChannelSize)
 					ctx.SkipVolumeValidation = true
@@ -5740,7 +6012,7 @@ func TriggerAsyncDR(contexts *[]*scheduler.Context, recordChan *chan *EventRecor
 		startApplicationsFlag = false
 	)
 
-	Step(fmt.Sprintf("Deploy applications for migration, with frequency: %v", chaosLevel), func() {
+	eventStep(event, fmt.Sprintf("Deploy applications for migration, with frequency: %v", chaosLevel), func() {
 
 		// Write kubeconfig files after reading from the config maps created by torpedo deploy script
 		err := asyncdr.WriteKubeconfigToFiles()
@@ -5763,9 +6035,10 @@ func TriggerAsyncDR(contexts *[]*scheduler.Context, recordChan *chan *EventRecor
 				// Override default App readiness time out of 5 mins with 10 mins
 				ctx.ReadinessTimeout = appReadinessTimeout
 				namespace := GetAppNamespace(ctx, taskName)
+				recordTargets(event, eventlog.TargetNamespace, namespace)
 				migrationNamespaces = append(migrationNamespaces, namespace)
 			}
-			Step("Create cluster pair between source and destination clusters", func() {
+			eventStep(event, "Create cluster pair between source and destination clusters", func() {
 				// Set cluster context to cluster where torpedo is running
 				ScheduleValidateClusterPair(appContexts[0], false, true, defaultClusterPairDir, false)
 			})
@@ -5830,7 +6103,7 @@ func TriggerAsyncDRVolumeOnly(contexts *[]*scheduler.Context, recordChan *chan *
 		startApplicationsFlag = false
 	)
 
-	Step(fmt.Sprintf("Deploy applications for volume-only migration, with frequency: %v", chaosLevel), func() {
+	eventStep(event, fmt.Sprintf("Deploy applications for volume-only migration, with frequency: %v", chaosLevel), func() {
 
 		// Write kubeconfig files after reading from the config maps created by torpedo deploy script
 		err := asyncdr.WriteKubeconfigToFiles()
@@ -5853,9 +6126,10 @@ func TriggerAsyncDRVolumeOnly(contexts *[]*scheduler.Context, recordChan *chan *
 				// Override default App readiness time out of 5 mins with 10 mins
 				ctx.ReadinessTimeout = appReadinessTimeout
 				namespace := GetAppNamespace(ctx, taskName)
+				recordTargets(event, eventlog.TargetNamespace, namespace)
 				migrationNamespaces = append(migrationNamespaces, namespace)
 			}
-			Step("Create cluster pair between source and destination clusters", func() {
+			eventStep(event, "Create cluster pair between source and destination clusters", func() {
 				// Set cluster context to cluster where torpedo is running
 				ScheduleValidateClusterPair(appContexts[0], false, true, defaultClusterPairDir, false)
 			})
@@ -5900,6 +6174,193 @@ func TriggerAsyncDRVolumeOnly(contexts *[]*scheduler.Context, recordChan *chan *
 	updateMetrics(*event)
 }
 
+// TriggerAsyncDRFailoverFailback runs the Async DR runbook of applications end to end: pairing the
+// clusters, scheduled migrations, planned failover to the destination and failback to the source,
+// then unplanned failover with the volume driver of the source stopped and failback once it is
+// back. Its applications are kept out of the shared contexts and destroyed at the end.
+func TriggerAsyncDRFailoverFailback(contexts *[]*scheduler.Context, recordChan *chan *EventRecord) {
+	defer endLongevityTest()
+	startLongevityTest(AsyncDRFailoverFailback)
+	defer ginkgo.GinkgoRecover()
+	log.InfoD("Async DR failover and failback triggered at: %v", time.Now())
+	event := &EventRecord{
+		Event: Event{
+			ID:   GenerateUUID(),
+			Type: AsyncDRFailoverFailback,
+		},
+		Start:   time.Now().Format(time.RFC1123),
+		Outcome: []error{},
+	}
+	defer func() {
+		event.End = time.Now().Format(time.RFC1123)
+		*recordChan <- event
+	}()
+
+	setMetrics(*event)
+
+	chaosLevel := ChaosMap[AsyncDRFailoverFailback]
+	var (
+		drContexts          []*scheduler.Context
+		migrationNamespaces []string
+		taskNamePrefix      = "adr-failover"
+		workflow            *asyncdr.Workflow
+	)
+
+	eventStep(event, fmt.Sprintf("Deploy applications for failover and failback, with frequency: %v", chaosLevel), func() {
+		// Write kubeconfig files after reading from the config maps created by torpedo deploy script
+		err := asyncdr.WriteKubeconfigToFiles()
+		if err != nil {
+			log.Errorf("Failed to write kubeconfig: %v", err)
+		}
+
+		err = SetSourceKubeConfig()
+		if err != nil {
+			log.Errorf("Failed to Set source kubeconfig: %v", err)
+		}
+		UpdateOutcome(event, err)
+		for i := 0; i < Inst().GlobalScaleFactor; i++ {
+			taskName := fmt.Sprintf("%s-%d-%s", taskNamePrefix, i, time.Now().Format("15h03m05s"))
+			log.InfoD("Task name %s\n", taskName)
+			appContexts := ScheduleApplications(taskName)
+			drContexts = append(drContexts, appContexts...)
+			ValidateApplications(drContexts)
+			for _, ctx := range appContexts {
+				// Override default App readiness time out of 5 mins with 10 mins
+				ctx.ReadinessTimeout = appReadinessTimeout
+				namespace := GetAppNamespace(ctx, taskName)
+				recordTargets(event, eventlog.TargetNamespace, namespace)
+				migrationNamespaces = append(migrationNamespaces, namespace)
+			}
+		}
+		log.InfoD("Failover Namespaces: %v", migrationNamespaces)
+	})
+	defer func() {
+		opts := map[string]bool{SkipClusterScopedObjects: true}
+		for _, ctx := range drContexts {
+			UpdateOutcome(event, Inst().S.Destroy(ctx, opts))
+		}
+	}()
+
+	sourceConfigPath, err := GetSourceClusterConfigPath()
+	if err != nil {
+		UpdateOutcome(event, err)
+		return
+	}
+	destinationConfigPath, err := GetDestinationClusterConfigPath()
+	if err != nil {
+		UpdateOutcome(event, err)
+		return
+	}
+	workflow = &asyncdr.Workflow{
+		Source:      asyncdr.Cluster{Name: "source", Kubeconfig: sourceConfigPath},
+		Destination: asyncdr.Cluster{Name: "destination", Kubeconfig: destinationConfigPath},
+		Namespaces:  migrationNamespaces,
+		Namespace:   asyncDRAdminNamespace,
+		Name:        fmt.Sprintf("%s-%s", taskNamePrefix, time.Now().Format("15h03m05s")),
+		Objectives: asyncdr.Objectives{
+			RPO: 2 * asyncDRScheduleInterval * time.Minute,
+			RTO: migrationRetryTimeout,
+		},
+		Use: func(c asyncdr.Cluster) error {
+			return SetClusterContext(c.Kubeconfig)
+		},
+		PairingInfo: func(remote asyncdr.Cluster, reverse bool) (map[string]string, error) {
+			return Inst().V.GetClusterPairingInfo(remote.Kubeconfig, "", IsEksCluster(), reverse)
+		},
+		Timeout:       migrationRetryTimeout,
+		RetryInterval: migrationRetryInterval,
+	}
+	defer func() {
+		if err := workflow.Cleanup(); err != nil {
+			log.Errorf("Failed to clean up Async DR workflow %s: %v", workflow.Name, err)
+		}
+		if err := SetSourceKubeConfig(); err != nil {
+			UpdateOutcome(event, err)
+		}
+	}()
+
+	eventStep(event, "Pair source and destination clusters", func() {
+		_, err := workflow.Pair()
+		UpdateOutcome(event, err)
+	})
+	if len(event.Outcome) > 0 {
+		updateMetrics(*event)
+		return
+	}
+
+	eventStep(event, "Migrate applications on a schedule", func() {
+		err := workflow.StartSchedule(asyncDRScheduleInterval)
+		UpdateOutcome(event, err)
+		if err != nil {
+			return
+		}
+		_, err = workflow.WaitForScheduledMigrations(2)
+		UpdateOutcome(event, err)
+	})
+
+	eventStep(event, "Suspend and resume the migration schedule", func() {
+		err := workflow.SuspendSchedule(true)
+		UpdateOutcome(event, err)
+		if err != nil {
+			return
+		}
+		UpdateOutcome(event, workflow.VerifyScheduleSuspended(asyncDRScheduleInterval*time.Minute))
+		UpdateOutcome(event, workflow.SuspendSchedule(false))
+	})
+
+	eventStep(event, "Fail over applications to the destination cluster", func() {
+		_, err := workflow.PlannedFailover()
+		UpdateOutcome(event, err)
+	})
+
+	eventStep(event, "Fail back applications to the source cluster", func() {
+		_, err := workflow.Failback()
+		UpdateOutcome(event, err)
+	})
+
+	eventStep(event, "Migrate applications on a schedule after failback", func() {
+		_, err := workflow.WaitForScheduledMigrations(1)
+		UpdateOutcome(event, err)
+	})
+
+	var outage time.Time
+	sourceNodes := node.GetStorageDriverNodes()
+	recordNodeTargets(event, sourceNodes...)
+	eventStep(event, "Take the source cluster down by stopping its volume driver", func() {
+		UpdateOutcome(event, SetSourceKubeConfig())
+		errorChan := make(chan error, errorChannelSize)
+		StopVolDriverAndWait(sourceNodes, &errorChan)
+		for err := range errorChan {
+			UpdateOutcome(event, err)
+		}
+		outage = time.Now()
+	})
+
+	eventStep(event, "Fail over applications to the destination cluster with the source down", func() {
+		_, err := workflow.UnplannedFailover(outage)
+		UpdateOutcome(event, err)
+	})
+
+	eventStep(event, "Bring the source cluster back by starting its volume driver", func() {
+		UpdateOutcome(event, SetSourceKubeConfig())
+		errorChan := make(chan error, errorChannelSize)
+		StartVolDriverAndWait(sourceNodes, &errorChan)
+		for err := range errorChan {
+			UpdateOutcome(event, err)
+		}
+	})
+
+	eventStep(event, "Fail back applications to the source cluster after the outage", func() {
+		_, err := workflow.Failback()
+		UpdateOutcome(event, err)
+	})
+
+	for _, report := range workflow.Reports {
+		log.InfoD("Async DR %s", report.String())
+	}
+	updateMetrics(*event)
+}
+
 func TriggerStorkApplicationBackup(contexts *[]*scheduler.Context, recordChan *chan *EventRecord) {
 	defer endLongevityTest()
 	startLongevityTest(StorkApplicationBackup)
@@ -5927,7 +6388,7 @@ func TriggerStorkApplicationBackup(contexts *[]*scheduler.Context, recordChan *c
 		taskNamePrefix   = "stork-app-backup"
 	)
 
-	Step(fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
+	eventStep(event, fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
 
 		// Write kubeconfig files after reading from the config maps created by torpedo deploy script
 		err := asyncdr.WriteKubeconfigToFiles()
@@ -5948,6 +6409,7 @@ func TriggerStorkApplicationBackup(contexts *[]*scheduler.Context, recordChan *c
 			ValidateApplications(*contexts)
 			for _, ctx := range appContexts {
 				namespace := GetAppNamespace(ctx, taskName)
+				recordTargets(event, eventlog.TargetNamespace, namespace)
 				backupNamespaces = append(backupNamespaces, namespace)
 			}
 		}
@@ -5972,11 +6434,123 @@ func TriggerStorkApplicationBackup(contexts *[]*scheduler.Context, recordChan *c
 				return
 			}
 			log.InfoD("backup successful, backup name - %v, backup location - %v", backupname, backuplocationname)
+			if err := validateStorkAppBackupAndRestore(event, backupname, currbkNamespace, timeout); err != nil {
+				UpdateOutcome(event, err)
+				return
+			}
+			if err := validateStorkAppClone(event, taskNamePrefix+"-clone", currbkNamespace, timeout); err != nil {
+				UpdateOutcome(event, err)
+			}
+			if err := validateStorkAppBackupSchedule(event, taskNamePrefix+"-schedule", currbkNamespace, currBackupLocation, timeout); err != nil {
+				UpdateOutcome(event, err)
+			}
 		}
 		updateMetrics(*event)
 	})
 }
 
+// validateStorkAppBackupAndRestore validates the resources and volumes of a completed stork backup
+// of a namespace, restores it in place and validates the restore. Stork only restores a backup to
+// another namespace from its admin namespace, so the restore replaces the applications of the
+// backed up namespace.
+func validateStorkAppBackupAndRestore(event *EventRecord, backupName, namespace string, timeout time.Duration) error {
+	expected, err := applicationbackup.ExpectedFromNamespaces([]string{namespace})
+	if err != nil {
+		return fmt.Errorf("getting expected content of backup failed with %v", err)
+	}
+	if err := applicationbackup.ValidateApplicationBackup(backupName, namespace, expected); err != nil {
+		return err
+	}
+	bkp, err := storkops.Instance().GetApplicationBackup(backupName, namespace)
+	if err != nil {
+		return fmt.Errorf("getting backup %s failed with %v", backupName, err)
+	}
+	restoreName := backupName + "-restore"
+	_, err = applicationbackup.CreateApplicationRestore(restoreName, bkp,
+		map[string]string{namespace: namespace}, storkv1.ApplicationRestoreReplacePolicyDelete)
+	if err != nil {
+		return fmt.Errorf("restore creation failed with %v", err)
+	}
+	defer func() {
+		if err := applicationbackup.DeleteApplicationRestore(restoreName, namespace); err != nil {
+			UpdateOutcome(event, fmt.Errorf("deleting restore %s failed with %v", restoreName, err))
+		}
+	}()
+	if _, err := applicationbackup.WaitForAppRestoreCompletion(restoreName, namespace, timeout); err != nil {
+		return fmt.Errorf("restore failed with %v", err)
+	}
+	if err := applicationbackup.ValidateApplicationRestore(restoreName, namespace); err != nil {
+		return err
+	}
+	log.InfoD("backup %v restored in namespace %v and validated", backupName, namespace)
+	return nil
+}
+
+// validateStorkAppClone clones the applications of a namespace to a new namespace and validates
+// the clone. Clones are created in the admin namespace of stork.
+func validateStorkAppClone(event *EventRecord, cloneName, namespace string, timeout time.Duration) error {
+	expected, err := applicationbackup.ExpectedFromNamespaces([]string{namespace})
+	if err != nil {
+		return fmt.Errorf("getting expected content of clone failed with %v", err)
+	}
+	cloneNamespace := namespace + "-clone"
+	_, err = applicationbackup.CreateApplicationClone(cloneName, asyncDRAdminNamespace, namespace, cloneNamespace,
+		storkv1.ApplicationCloneReplacePolicyDelete)
+	if err != nil {
+		return fmt.Errorf("clone creation failed with %v", err)
+	}
+	defer func() {
+		if err := applicationbackup.DeleteApplicationClone(cloneName, asyncDRAdminNamespace); err != nil {
+			UpdateOutcome(event, fmt.Errorf("deleting clone %s failed with %v", cloneName, err))
+		}
+		if err := core.Instance().DeleteNamespace(cloneNamespace); err != nil {
+			UpdateOutcome(event, fmt.Errorf("deleting cloned namespace %s failed with %v", cloneNamespace, err))
+		}
+	}()
+	if _, err := applicationbackup.WaitForAppCloneCompletion(cloneName, asyncDRAdminNamespace, timeout); err != nil {
+		return fmt.Errorf("clone failed with %v", err)
+	}
+	if err := applicationbackup.ValidateApplicationClone(cloneName, asyncDRAdminNamespace, expected); err != nil {
+		return err
+	}
+	log.InfoD("namespace %v cloned to namespace %v and validated", namespace, cloneNamespace)
+	return nil
+}
+
+// validateStorkAppBackupSchedule backs up a namespace on an interval schedule and validates the
+// first scheduled backup
+func validateStorkAppBackupSchedule(event *EventRecord, scheduleName, namespace string, backupLocation *storkv1.BackupLocation, timeout time.Duration) error {
+	expected, err := applicationbackup.ExpectedFromNamespaces([]string{namespace})
+	if err != nil {
+		return fmt.Errorf("getting expected content of scheduled backups failed with %v", err)
+	}
+	if _, err := applicationbackup.CreateIntervalSchedulePolicy(scheduleName, 1, 2); err != nil {
+		return fmt.Errorf("schedule policy creation failed with %v", err)
+	}
+	defer func() {
+		if err := storkops.Instance().DeleteSchedulePolicy(scheduleName); err != nil {
+			UpdateOutcome(event, fmt.Errorf("deleting schedule policy %s failed with %v", scheduleName, err))
+		}
+	}()
+	if _, err := applicationbackup.CreateApplicationBackupSchedule(scheduleName, namespace, scheduleName, backupLocation); err != nil {
+		return fmt.Errorf("backup schedule creation failed with %v", err)
+	}
+	defer func() {
+		if err := applicationbackup.DeleteApplicationBackupSchedule(scheduleName, namespace); err != nil {
+			UpdateOutcome(event, fmt.Errorf("deleting backup schedule %s failed with %v", scheduleName, err))
+		}
+	}()
+	backups, err := applicationbackup.WaitForScheduledAppBackups(scheduleName, namespace, storkv1.SchedulePolicyTypeInterval, 1, timeout)
+	if err != nil {
+		return fmt.Errorf("scheduled backup failed with %v", err)
+	}
+	if err := applicationbackup.ValidateApplicationBackup(backups[0].Name, namespace, expected); err != nil {
+		return err
+	}
+	log.InfoD("scheduled backup %v of namespace %v validated", backups[0].Name, namespace)
+	return nil
+}
+
 func TriggerStorkAppBkpVolResize(contexts *[]*scheduler.Context, recordChan *chan *EventRecord) {
 	defer endLongevityTest()
 	startLongevityTest(StorkAppBkpVolResize)
@@ -6005,7 +6579,7 @@ func TriggerStorkAppBkpVolResize(contexts *[]*scheduler.Context, recordChan *cha
 		requestedVols  []*volume.Volume
 	)
 
-	Step(fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
+	eventStep(event, fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
 
 		// Write kubeconfig files after reading from the config maps created by torpedo deploy script
 		err := asyncdr.WriteKubeconfigToFiles()
@@ -6027,6 +6601,7 @@ func TriggerStorkAppBkpVolResize(contexts *[]*scheduler.Context, recordChan *cha
 			ValidateApplications(*contexts)
 			for _, ctx := range appContexts {
 				currbkNamespace := GetAppNamespace(ctx, taskName)
+				recordTargets(event, eventlog.TargetNamespace, currbkNamespace)
 				log.Infof("Backup applications, present in namespaces - %v", currbkNamespace)
 				appVolumes, err = Inst().S.GetVolumes(ctx)
 				log.Infof("len of app volumes is : %v", len(appVolumes))
@@ -6052,6 +6627,9 @@ func TriggerStorkAppBkpVolResize(contexts *[]*scheduler.Context, recordChan *cha
 				if bkp_start_err == nil {
 					log.Info("Application backup is in progress, starting volume resize")
 					requestedVols, _ = Inst().S.ResizeVolume(ctx, "")
+					for _, v := range requestedVols {
+						recordTargets(event, eventlog.TargetVolume, v.Name)
+					}
 					log.Info("verify application backup successful")
 					bkp_comp_err := applicationbackup.WaitForAppBackupCompletion(backupname, currbkNamespace, timeout)
 					if bkp_comp_err != nil {
@@ -6066,6 +6644,10 @@ func TriggerStorkAppBkpVolResize(contexts *[]*scheduler.Context, recordChan *cha
 							UpdateOutcome(event, fmt.Errorf("volume resize failed for %v volume", v))
 						}
 					}
+					if err := validateStorkAppBackupAndRestore(event, backupname, currbkNamespace, timeout); err != nil {
+						UpdateOutcome(event, err)
+						return
+					}
 				} else {
 					UpdateOutcome(event, fmt.Errorf("backup start fail %v", bkp_start_err))
 					return
@@ -6104,7 +6686,7 @@ func TriggerStorkAppBkpHaUpdate(contexts *[]*scheduler.Context, recordChan *chan
 		appVolumes     []*volume.Volume
 	)
 
-	Step(fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
+	eventStep(event, fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
 
 		// Write kubeconfig files after reading from the config maps created by torpedo deploy script
 		err := asyncdr.WriteKubeconfigToFiles()
@@ -6126,6 +6708,7 @@ func TriggerStorkAppBkpHaUpdate(contexts *[]*scheduler.Context, recordChan *chan
 			ValidateApplications(*contexts)
 			for _, ctx := range appContexts {
 				currbkNamespace := GetAppNamespace(ctx, taskName)
+				recordTargets(event, eventlog.TargetNamespace, currbkNamespace)
 				log.Infof("Backup applications, present in namespaces - %v", currbkNamespace)
 				appVolumes, err = Inst().S.GetVolumes(ctx)
 				log.Infof("len of app volumes is : %v", len(appVolumes))
@@ -6152,6 +6735,7 @@ func TriggerStorkAppBkpHaUpdate(contexts *[]*scheduler.Context, recordChan *chan
 				if bkp_start_err == nil {
 					failure := false
 					for _, v := range appVolumes {
+						recordTargets(event, eventlog.TargetVolume, v.Name)
 						MaxRF := Inst().V.GetMaxReplicationFactor()
 						log.Infof("Maximum replication factor is: %v\n", MaxRF)
 						currAggr, err := Inst().V.GetAggregationLevel(v)
@@ -6205,6 +6789,10 @@ func TriggerStorkAppBkpHaUpdate(contexts *[]*scheduler.Context, recordChan *chan
 							UpdateOutcome(event, fmt.Errorf("backup completion failed with %v", bkp_comp_err))
 							return
 						}
+						if err := validateStorkAppBackupAndRestore(event, bkp.Name, bkp.Namespace, timeout); err != nil {
+							UpdateOutcome(event, err)
+							return
+						}
 						log.InfoD("backup successful and volume ha-update injected during backup successfully, backup name - %v, backup location - %v", backupname, backuplocationname)
 					}
 				} else {
@@ -6243,7 +6831,7 @@ func TriggerStorkAppBkpPxRestart(contexts *[]*scheduler.Context, recordChan *cha
 		taskNamePrefix = "stork-appbkp-pxrestart"
 	)
 
-	Step(fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
+	eventStep(event, fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
 
 		// Write kubeconfig files after reading from the config maps created by torpedo deploy script
 		err := asyncdr.WriteKubeconfigToFiles()
@@ -6265,6 +6853,7 @@ func TriggerStorkAppBkpPxRestart(contexts *[]*scheduler.Context, recordChan *cha
 			ValidateApplications(*contexts)
 			for _, ctx := range appContexts {
 				currbkNamespace := GetAppNamespace(ctx, taskName)
+				recordTargets(event, eventlog.TargetNamespace, currbkNamespace)
 				log.Infof("Backup applications, present in namespaces - %v", currbkNamespace)
 				log.Infof("Start backup application")
 				taskNamePrefix = taskNamePrefix + fmt.Sprintf("-%d", i)
@@ -6282,9 +6871,10 @@ func TriggerStorkAppBkpPxRestart(contexts *[]*scheduler.Context, recordChan *cha
 				}
 				bkp_start_err := applicationbackup.WaitForAppBackupToStart(bkp.Name, bkp.Namespace, timeout)
 				if bkp_start_err == nil {
-					Step("Restart Portworx", func() {
+					eventStep(event, "Restart Portworx", func() {
 						nodes := node.GetStorageDriverNodes()
-						nodeIndex := rand.Intn(len(nodes))
+						nodeIndex := TriggerRand.Intn(StorkAppBkpPxRestart, len(nodes))
+						recordNodeTargets(event, nodes[nodeIndex])
 						log.Infof("Stop volume driver [%s] on node: [%s]", Inst().V.String(), nodes[nodeIndex].Name)
 						StopVolDriverAndWait([]node.Node{nodes[nodeIndex]})
 						log.Infof("Starting volume driver [%s] on node [%s]", Inst().V.String(), nodes[nodeIndex].Name)
@@ -6301,6 +6891,10 @@ func TriggerStorkAppBkpPxRestart(contexts *[]*scheduler.Context, recordChan *cha
 					UpdateOutcome(event, fmt.Errorf("backup completion failed with %v", bkp_comp_err))
 					return
 				}
+				if err := validateStorkAppBackupAndRestore(event, bkp.Name, bkp.Namespace, timeout); err != nil {
+					UpdateOutcome(event, err)
+					return
+				}
 				log.InfoD("backup successful and px restart injected during backup successfully, backup name - %v, backup location - %v", bkp.Name, currBackupLocation.Name)
 			}
 		}
@@ -6334,7 +6928,7 @@ func TriggerStorkAppBkpPoolResize(contexts *[]*scheduler.Context, recordChan *ch
 		taskNamePrefix = "stork-appbkp-poolresize"
 	)
 
-	Step(fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
+	eventStep(event, fmt.Sprintf("Deploy applications for backup, with frequency: %v", chaosLevel), func() {
 
 		// Write kubeconfig files after reading from the config maps created by torpedo deploy script
 		err := asyncdr.WriteKubeconfigToFiles()
@@ -6356,6 +6950,7 @@ func TriggerStorkAppBkpPoolResize(contexts *[]*scheduler.Context, recordChan *ch
 			ValidateApplications(*contexts)
 			for _, ctx := range appContexts {
 				currbkNamespace := GetAppNamespace(ctx, taskName)
+				recordTargets(event, eventlog.TargetNamespace, currbkNamespace)
 				log.Infof("Backup applications, present in namespaces - %v", currbkNamespace)
 				log.Infof("Start backup application")
 				taskNamePrefix = taskNamePrefix + fmt.Sprintf("-%d", i)
@@ -6375,7 +6970,7 @@ func TriggerStorkAppBkpPoolResize(contexts *[]*scheduler.Context, recordChan *ch
 				if bkp_start_err == nil {
 					chaosLevel := getPoolExpandPercentage(StorkAppBkpPoolResize)
 					stepLog := fmt.Sprintf("get storage pools and perform resize-disk by %v percentage on it ", chaosLevel)
-					Step(stepLog, func() {
+					eventStep(event, stepLog, func() {
 						poolsToBeResized, err := getStoragePoolsToExpand()
 						if err != nil {
 							log.Error(err.Error())
@@ -6397,7 +6992,7 @@ func TriggerStorkAppBkpPoolResize(contexts *[]*scheduler.Context, recordChan *ch
 						wg.Wait()
 					})
 					stepLog = "validate all apps after pool resize using resize-disk operation"
-					Step(stepLog, func() {
+					eventStep(event, stepLog, func() {
 						log.InfoD(stepLog)
 						errorChan := make(chan error, errorChannelSize)
 						for _, ctx := range *contexts {
@@ -6418,6 +7013,10 @@ func TriggerStorkAppBkpPoolResize(contexts *[]*scheduler.Context, recordChan *ch
 					UpdateOutcome(event, fmt.Errorf("backup completion failed with %v", bkp_comp_err))
 					return
 				}
+				if err := validateStorkAppBackupAndRestore(event, bkp.Name, bkp.Namespace, timeout); err != nil {
+					UpdateOutcome(event, err)
+					return
+				}
 				log.InfoD("backup successful and pool resize injected during backup successfully, backup name - %v, backup location - %v", bkp.Name, currBackupLocation.Name)
 
 			}